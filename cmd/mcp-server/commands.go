@@ -0,0 +1,329 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/brduru/mcp-defect-dojo/internal/config"
+	"github.com/brduru/mcp-defect-dojo/internal/defectdojo"
+	"github.com/brduru/mcp-defect-dojo/pkg/mcpserver"
+	"github.com/brduru/mcp-defect-dojo/pkg/mockdojo"
+)
+
+// runToolsCommand implements `mcp-server tools [--json|--markdown]`: it prints
+// the tools the configured server registers, so operators can check what an
+// agent will be offered before wiring it up, or regenerate the tool reference
+// of the documentation. It returns the process exit code.
+func runToolsCommand(server *mcpserver.Server, args []string) int {
+	flags := flag.NewFlagSet("tools", flag.ContinueOnError)
+	asJSON := flags.Bool("json", false, "Print the tool definitions as JSON")
+	asMarkdown := flags.Bool("markdown", false, "Print the tool reference as Markdown")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	tools := server.Tools()
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(tools); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			return 1
+		}
+		return 0
+	}
+	if *asMarkdown {
+		printToolsMarkdown(os.Stdout, tools)
+		return 0
+	}
+	printTools(os.Stdout, tools)
+	return 0
+}
+
+// toolParameters returns the names of a tool's parameters, sorted
+func toolParameters(tool mcp.Tool) []string {
+	names := make([]string, 0, len(tool.InputSchema.Properties))
+	for name := range tool.InputSchema.Properties {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// printToolsMarkdown prints the tool reference as Markdown: one section per
+// tool with its access, description and a table of its parameters
+func printToolsMarkdown(w io.Writer, tools []mcp.Tool) {
+	fmt.Fprintf(w, "# Tool reference\n\n%d tools. Generated by `mcp-server tools --markdown`.\n", len(tools))
+	for _, tool := range tools {
+		access := "write"
+		if readOnly := tool.Annotations.ReadOnlyHint; readOnly != nil && *readOnly {
+			access = "read"
+		}
+		fmt.Fprintf(w, "\n## %s\n\n", tool.Name)
+		fmt.Fprintf(w, "Access: %s", access)
+		if hints := toolHints(tool.Annotations); len(hints) > 0 {
+			fmt.Fprintf(w, " (%s)", strings.Join(hints, ", "))
+		}
+		fmt.Fprintf(w, "\n\n%s\n", strings.TrimSpace(tool.Description))
+
+		names := toolParameters(tool)
+		if len(names) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "\n| Parameter | Type | Required | Description |\n|---|---|---|---|\n")
+		for _, name := range names {
+			schema, _ := tool.InputSchema.Properties[name].(map[string]any)
+			kind, _ := schema["type"].(string)
+			description, _ := schema["description"].(string)
+			required := ""
+			if slices.Contains(tool.InputSchema.Required, name) {
+				required = "yes"
+			}
+			fmt.Fprintf(w, "| `%s` | %s | %s | %s |\n", name, kind, required, markdownCell(description))
+		}
+	}
+}
+
+// markdownCell escapes text for a Markdown table cell
+func markdownCell(text string) string {
+	return strings.NewReplacer("|", "\\|", "\n", " ").Replace(text)
+}
+
+// printTools prints one block per tool: name and annotations, the first line
+// of its description, then its parameters with required ones marked
+func printTools(w io.Writer, tools []mcp.Tool) {
+	fmt.Fprintf(w, "%d tools registered\n", len(tools))
+	for _, tool := range tools {
+		fmt.Fprintf(w, "\n%s", tool.Name)
+		if hints := toolHints(tool.Annotations); len(hints) > 0 {
+			fmt.Fprintf(w, " [%s]", strings.Join(hints, ", "))
+		}
+		description, _, _ := strings.Cut(tool.Description, "\n")
+		fmt.Fprintf(w, "\n  %s\n", description)
+
+		for _, name := range toolParameters(tool) {
+			schema, _ := tool.InputSchema.Properties[name].(map[string]any)
+			kind, _ := schema["type"].(string)
+			if slices.Contains(tool.InputSchema.Required, name) {
+				kind += ", required"
+			}
+			fmt.Fprintf(w, "  - %s (%s)", name, kind)
+			if description, ok := schema["description"].(string); ok && description != "" {
+				fmt.Fprintf(w, ": %s", description)
+			}
+			fmt.Fprintln(w)
+		}
+	}
+}
+
+// toolHints lists the annotation hints a tool sets to true. The destructive
+// and idempotent hints only mean something for tools that write.
+func toolHints(annotations mcp.ToolAnnotation) []string {
+	readOnly := annotations.ReadOnlyHint != nil && *annotations.ReadOnlyHint
+	var hints []string
+	for _, hint := range []struct {
+		name  string
+		value *bool
+		write bool
+	}{
+		{"read-only", annotations.ReadOnlyHint, false},
+		{"destructive", annotations.DestructiveHint, true},
+		{"idempotent", annotations.IdempotentHint, true},
+		{"open-world", annotations.OpenWorldHint, false},
+	} {
+		if hint.value != nil && *hint.value && !(hint.write && readOnly) {
+			hints = append(hints, hint.name)
+		}
+	}
+	return hints
+}
+
+// toolArguments collects repeated --arg key=value flags
+type toolArguments []string
+
+func (a *toolArguments) String() string { return strings.Join(*a, " ") }
+
+func (a *toolArguments) Set(value string) error {
+	if !strings.Contains(value, "=") {
+		return fmt.Errorf("expected key=value, got %q", value)
+	}
+	*a = append(*a, value)
+	return nil
+}
+
+// runCallCommand implements `mcp-server call <tool> --arg key=value ...`: it
+// calls one tool through an in-process MCP client, prints the result and
+// returns the process exit code (1 if the call failed).
+func runCallCommand(server *mcpserver.Server, args []string) int {
+	var tool string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		tool, args = args[0], args[1:]
+	}
+	flags := flag.NewFlagSet("call", flag.ContinueOnError)
+	var rawArgs toolArguments
+	flags.Var(&rawArgs, "arg", "Tool argument as key=value (repeatable); JSON values such as 42, true or [1,2] are decoded unless the parameter is a string")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if tool == "" {
+		tool = flags.Arg(0)
+	}
+	if tool == "" {
+		fmt.Fprintln(os.Stderr, "usage: mcp-server call <tool> [--arg key=value ...]")
+		return 2
+	}
+
+	index := slices.IndexFunc(server.Tools(), func(t mcp.Tool) bool { return t.Name == tool })
+	if index < 0 {
+		fmt.Fprintf(os.Stderr, "❌ Unknown tool %q (run `mcp-server tools` to list them)\n", tool)
+		return 2
+	}
+	arguments := parseToolArguments(server.Tools()[index].InputSchema, rawArgs)
+
+	ctx := context.Background()
+	mcpClient, err := server.NewInProcessClient(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 1
+	}
+	defer mcpClient.Close()
+
+	result, err := mcpClient.CallTool(ctx, mcp.CallToolRequest{
+		Params: mcp.CallToolParams{Name: tool, Arguments: arguments},
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 1
+	}
+	for _, content := range result.Content {
+		if text, ok := content.(mcp.TextContent); ok {
+			fmt.Println(text.Text)
+			continue
+		}
+		encoded, _ := json.MarshalIndent(content, "", "  ")
+		fmt.Println(string(encoded))
+	}
+	if result.IsError {
+		return 1
+	}
+	return 0
+}
+
+// parseToolArguments turns key=value pairs into tool arguments. Values of
+// string parameters are kept verbatim; other values are decoded as JSON when
+// possible, so finding_id=42 is a number and tags=["a","b"] an array.
+func parseToolArguments(schema mcp.ToolInputSchema, pairs []string) map[string]any {
+	arguments := make(map[string]any, len(pairs))
+	for _, pair := range pairs {
+		key, value, _ := strings.Cut(pair, "=")
+		property, _ := schema.Properties[key].(map[string]any)
+		var decoded any
+		if property["type"] != "string" && json.Unmarshal([]byte(value), &decoded) == nil {
+			arguments[key] = decoded
+		} else {
+			arguments[key] = value
+		}
+	}
+	return arguments
+}
+
+// ANSI colors of doctor check statuses
+var doctorColors = map[defectdojo.CheckStatus]string{
+	defectdojo.CheckPass: "\033[32m", // green
+	defectdojo.CheckWarn: "\033[33m", // yellow
+	defectdojo.CheckFail: "\033[31m", // red
+	defectdojo.CheckSkip: "\033[90m", // grey
+}
+
+// runDoctorCommand implements `mcp-server doctor [--no-color]`: it diagnoses
+// the DefectDojo connection with remediation hints and returns the process
+// exit code (1 if a check failed). Colors are used on terminals unless
+// NO_COLOR is set.
+func runDoctorCommand(cfg *config.Config, args []string) int {
+	flags := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	noColor := flags.Bool("no-color", false, "Print the report without colors")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if cfg.DefectDojo.Mode == defectdojo.ModeOffline {
+		fmt.Println("DEFECTDOJO_MODE=offline serves fixture data; there is no DefectDojo connection to diagnose")
+		return 0
+	}
+
+	doctor := defectdojo.NewHTTPClient(&cfg.DefectDojo)
+	report := doctor.Doctor(context.Background())
+	doctor.Close()
+	color := !*noColor && os.Getenv("NO_COLOR") == "" && isTerminal(os.Stdout)
+
+	counts := map[defectdojo.CheckStatus]int{}
+	for _, check := range report.Checks {
+		counts[check.Status]++
+		status := strings.ToUpper(string(check.Status))
+		if color {
+			status = doctorColors[check.Status] + status + "\033[0m"
+		}
+		fmt.Printf("[%s] %s: %s\n", status, check.Name, check.Detail)
+		if check.Hint != "" {
+			fmt.Printf("       → %s\n", check.Hint)
+		}
+	}
+	fmt.Printf("\n%d passed, %d warnings, %d failed, %d skipped\n",
+		counts[defectdojo.CheckPass], counts[defectdojo.CheckWarn], counts[defectdojo.CheckFail], counts[defectdojo.CheckSkip])
+	if !report.OK() {
+		return 1
+	}
+	return 0
+}
+
+// isTerminal reports whether f is a character device such as a terminal
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// runMockDojoCommand implements `mcp-server mockdojo`: it serves a fake
+// DefectDojo API with the demo fixtures for local development until
+// interrupted. It returns the process exit code.
+func runMockDojoCommand(args []string) int {
+	flags := flag.NewFlagSet("mockdojo", flag.ContinueOnError)
+	listen := flags.String("listen", "localhost:8080", "Address to serve the fake DefectDojo API on")
+	fixtures := flags.String("fixtures", "", "Fixture directory (default: built-in demo data)")
+	apiKey := flags.String("api-key", "", "API token clients must send (default: accept any request)")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	dojo, err := mockdojo.New(mockdojo.Options{FixturesDir: *fixtures, APIKey: *apiKey})
+	if err != nil {
+		log.Printf("❌ Failed to load fixtures: %v", err)
+		return 1
+	}
+	httpServer := &http.Server{Addr: *listen, Handler: dojo, ReadHeaderTimeout: 5 * time.Second}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		_ = httpServer.Close()
+	}()
+
+	log.Printf("🧪 Fake DefectDojo %s listening on http://%s (set DEFECTDOJO_URL to it)", mockdojo.Version, *listen)
+	if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		log.Printf("❌ Fake DefectDojo error: %v", err)
+		return 1
+	}
+	return 0
+}
@@ -1,13 +1,98 @@
 // Package main provides the standalone DefectDojo MCP server binary.
 //
-// This server communicates via stdio (standard input/output) for subprocess usage,
-// making it compatible with MCP clients that spawn server processes.
+// By default the server communicates via stdio (standard input/output) for
+// subprocess usage, making it compatible with MCP clients that spawn server
+// processes. With --transport http or sse it listens for MCP clients over HTTP
+// instead, for running under systemd or Kubernetes.
+//
+// Command line flags:
+//   - --config: YAML configuration file (see README); environment variables override it
+//   - --transport: stdio, http (streamable HTTP at /mcp) or sse (/sse and /message) (default: stdio)
+//   - --listen: Address of the http and sse transports (default: localhost:8000)
+//   - --read-only: Register only tools that do not change DefectDojo
+//   - --health-port: Serve /healthz, /readyz and /metrics on this port
+//
+// Flags take precedence over environment variables, which take precedence over
+// the configuration file. Per-product API tokens (defectdojo.credentials),
+// further instances probed by the health check (defectdojo.instances) and the
+// bearer tokens and roles of HTTP clients (auth) can only be set in the
+// configuration file.
 //
 // Configuration is done via environment variables for DefectDojo connection:
 //   - DEFECTDOJO_URL: DefectDojo instance URL (default: http://localhost:8080)
+//   - DEFECTDOJO_UI_URL: Web UI URL for finding, product and engagement links (default: DEFECTDOJO_URL)
 //   - DEFECTDOJO_API_KEY: DefectDojo API token for authentication
-//   - DEFECTDOJO_API_VERSION: API version to use (default: v2)
-//   - LOG_LEVEL: Logging level - debug, info, warn, error (default: info)
+//   - DEFECTDOJO_API_VERSION: API version to use (default: v2); the server refuses to start with a version it or the instance does not support
+//   - DEFECTDOJO_MODE: live, offline (fixture data), record or replay (recorded traffic) (default: live)
+//   - DEFECTDOJO_FIXTURES_DIR: Offline mode fixture directory (default: built-in demo data)
+//   - DEFECTDOJO_CASSETTE_DIR: Directory of recorded traffic for record/replay modes (default: cassettes)
+//   - ERROR_DETAIL: full (DefectDojo error bodies in tool results) or sanitized (logged, summarized with the request ID) (default: full)
+//   - MCP_TRANSPORT: stdio, http or sse (or --transport)
+//   - MCP_LISTEN: Address of the http and sse transports (or --listen)
+//   - READ_ONLY: Register only tools that do not change DefectDojo (true/false, or --read-only)
+//   - LOG_LEVEL: Logging level - trace, debug, info, warn, error (default: info)
+//   - LOG_DUMP_FILE: Destination for trace-level DefectDojo traffic dumps (default: stderr)
+//   - AUDIT_LOG_FILE: Append-only JSON lines audit trail for write operations
+//   - OTEL_TRACING_ENABLED: Export OpenTelemetry spans via OTLP/HTTP (true/false)
+//   - HEALTH_PORT: Serve /healthz, /readyz and /metrics on this port (or --health-port)
+//   - MAX_TOOL_TIMEOUT: Upper bound for per-call timeout_seconds arguments and client deadline hints (default: 5m)
+//   - REFERENCE_CACHE_TTL: How long product, engagement and test names are cached (default: 10m, 0 = disabled)
+//   - IDEMPOTENCY_WINDOW: How long a retried write call returns the earlier result instead of writing again (default: 2m, 0 = disabled)
+//   - TOOL_ERRORS: strict (failed tool calls are JSON-RPC errors) or lenient (error tool results) (default: strict)
+//   - WORKER_POOL_SIZE: DefectDojo requests bulk and batch operations run at once, across all calls (default: 16)
+//   - OUTPUT_MAX_FIELD_CHARS: Truncate long finding text sections (default: 2000, 0 = unlimited)
+//   - OUTPUT_DETAIL_LEVEL: Default findings list detail - summary, normal, full (default: normal)
+//   - OUTPUT_MAX_DESCRIPTION_CHARS: Truncate descriptions in findings lists (default: 300, 0 = unlimited)
+//   - OUTPUT_LIST_LIMIT: Default number of findings per list page (default: 10)
+//   - OUTPUT_MAX_LIST_LIMIT: Largest limit a tool call may request (default: 100)
+//   - OUTPUT_FORMAT: Default findings output format - text, markdown, json (default: text)
+//   - OUTPUT_LANGUAGE: Language of findings output labels and headings - en, pt, es (default: en)
+//   - OUTPUT_EXPORT_CHUNK_SIZE: Findings per export_findings chunk resource (default: 1000, max 5000)
+//   - OUTPUT_EXPORT_MEMORY_MB: Megabytes of export chunks kept in memory before the rest spill to disk (default: 32)
+//   - OUTPUT_EXPORT_SPILL_DIR: Directory of export spill files (default: system temporary directory)
+//   - OUTPUT_MAX_RESULT_KB: Summarize tool results larger than this many KiB, linking the full result (default: 100, 0 = never)
+//   - OUTPUT_SANITIZE_CONTENT: Neutralize instructions embedded in finding titles and descriptions (default: false)
+//   - OUTPUT_HTML_CONTENT: HTML in finding descriptions and other text sections: markdown, text or raw (default: markdown)
+//   - OUTPUT_SEVERITY_LABELS: Organization severity scale, e.g. Critical=P1,High=P2 (shown in output, accepted in filters)
+//   - WRITE_POLICY_FILE: JSON file of rules checked before every write operation
+//   - REQUIRE_APPROVAL: Queue write operations until a human approves them (true/false)
+//   - APPROVAL_PORT: Serve the approval endpoints (/actions) on this port
+//   - APPROVAL_HOST: Address the approval endpoints listen on (default: 127.0.0.1)
+//   - APPROVAL_TOKEN: Bearer token reviewers must send to the approval endpoints (required unless APPROVAL_HOST is loopback)
+//   - WEBHOOK_PORT: Receive DefectDojo webhook notifications on this port (POST /webhook)
+//   - WEBHOOK_SECRET: Shared secret DefectDojo must send in the Authorization header
+//   - WEBHOOK_BUFFER_SIZE: Number of recent webhook events kept (default: 100)
+//   - POLL_INTERVAL: Poll for new and changed findings in the background, e.g. 15m (default: on demand)
+//   - POLL_QUERY: Saved query selecting the polled findings (default: active findings)
+//   - CVE_ENRICHMENT: Add EPSS and CISA KEV data to CVE findings - off, live, offline (default: off)
+//   - CVE_ENRICHMENT_DATA_DIR: Offline enrichment directory with known_exploited_vulnerabilities.json and/or epss_scores.csv[.gz]
+//   - CVE_ENRICHMENT_CACHE_TTL: How long live EPSS and KEV lookups are cached (default: 24h)
+//   - PRIORITY_WEIGHTS: prioritize_findings factor weights, e.g. severity=40,epss=30 (default: severity=30,cvss=20,epss=20,age=10,sla=10,criticality=10)
+//   - PRIORITY_CRITICAL_TAGS: Comma-separated product tags that mark business-critical products
+//   - ISSUE_TRACKER: File issues from findings in github or gitlab (default: disabled)
+//   - ISSUE_TRACKER_TOKEN: GitHub or GitLab token allowed to create issues
+//   - ISSUE_TRACKER_REPOSITORY: GitHub owner/name or GitLab project path
+//   - ISSUE_TRACKER_URL: API base URL for GitHub Enterprise or self-managed GitLab
+//   - ISSUE_TRACKER_LABELS: Comma-separated labels added to every issue
+//   - NOTIFY_WEBHOOKS_FILE: JSON file of webhooks told about every write, with optional templates
+//   - NOTIFY_WEBHOOK_URL: Slack or Teams incoming webhook told about every write
+//   - NOTIFY_WEBHOOK_FORMAT: Payload format of NOTIFY_WEBHOOK_URL - slack, teams, json (default: slack)
+//   - SAVED_QUERIES_FILE: JSON file of named findings queries for run_saved_query
+//   - RATE_LIMIT: Tool calls each MCP session may make per minute (0 = unlimited, the default)
+//   - RATE_LIMIT_BURST: Tool calls a session may make at once within RATE_LIMIT (default: 10)
+//   - TOOL_BUDGET: Default budget of each tool call, e.g. "max_pages=50,max_findings=5000,max_concurrency=4" (default: unlimited)
+//   - DEBUG_LISTEN: Serve pprof profiles and runtime statistics on this host:port, e.g. localhost:6060 (off by default)
+//   - STARTUP_CHECK: Log a connectivity and permission self-check at startup (true/false)
+//
+// Run with --version to print build metadata (add --json for machine-readable output).
+//
+// Run with --check to print the self-check report and exit non-zero on failure.
+//
+// Subcommands inspect the configured server instead of serving it:
+//   - tools [--json]: Print the registered tools with their parameters and annotations
+//   - call <tool> [--arg key=value ...]: Call one tool, print its result and exit
+//   - doctor [--no-color]: Diagnose the DefectDojo connection with remediation hints
+//   - mockdojo [--listen addr] [--fixtures dir] [--api-key key]: Serve a fake DefectDojo API for local development
 //
 // Server identity (name, version, instructions) is fixed and cannot be overridden.
 //
@@ -22,35 +107,94 @@
 //   - get_defectdojo_findings: Query vulnerability findings
 //   - get_finding_detail: Get detailed finding information
 //   - mark_finding_false_positive: Mark findings as false positives
+//   - clear_false_positive: Reverse a false positive decision
+//   - change_finding_severity: Re-grade a finding with a justification
+//   - assign_finding: Assign a finding to a DefectDojo user
+//   - add_note_to_findings: Add the same note to several findings at once
+//   - invalidate_reference_cache: Drop cached reference data
+//   - list_saved_queries: List operator-defined findings queries
+//   - run_saved_query: Run a saved findings query by name
+//   - list_pending_actions: List writes waiting for human approval
+//   - get_recent_events: Read DefectDojo webhook notifications
+//   - get_new_findings_since_last_check: Digest of new and changed findings
+//   - create_product: Create a product to onboard an application
+//   - create_engagement: Create an engagement in a product
+//   - import_sarif: Import a SARIF report, one test per scanner run
+//   - find_sbom_component_findings: Find open findings for SBOM components
+//   - prioritize_findings: Rank open findings by remediation priority
+//   - summarize_security_posture: Portfolio posture summary for executive reports
+//   - get_mttr_metrics: Mean, median and p90 time to remediate per product and severity
+//   - create_issue_from_finding: File a GitHub or GitLab issue for a finding
+//   - get_expiring_risk_acceptances: Risk acceptances expiring soon, with their findings
+//   - get_engagement_overview: Engagements in progress and upcoming, with open finding counts
+//   - check_engagement_readiness: Pass/fail checklist before closing an engagement
+//   - get_findings_by_host: Active findings grouped by endpoint host
+//   - get_stale_findings: Active findings open for N days or more, in age buckets
+//   - get_import_summary: What the last scan import into a test changed, with anomaly flags
+//   - get_defectdojo_system_info: Instance settings, SLA deadlines and announcement, with guidance
+//   - get_server_stats: Tool call counts, error rates and latency
+//   - pin_findings, get_pinned_findings, clear_pins: Per-session working set of findings
+//   - save_query_result: Save a findings query result as a session resource
+//   - export_findings: Export a large findings query as chunked session resources
+//
+// And MCP resources:
+//   - defectdojo://engagement/{id}/report: An engagement with its tests and findings
+//   - defectdojo://product/{id}/attack-surface: A product's endpoints, technologies and open findings per endpoint
+//   - defectdojo://session/result/{name}: A findings query result saved in the session
+//   - defectdojo://session/export/{name}/{chunk}: One chunk of a findings export
 package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
+	"net"
+	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/brduru/mcp-defect-dojo/internal/config"
+	"github.com/brduru/mcp-defect-dojo/internal/defectdojo"
+	"github.com/brduru/mcp-defect-dojo/internal/telemetry"
 	"github.com/brduru/mcp-defect-dojo/pkg/mcpserver"
 )
 
-// Version information - set at build time
-var (
-	version = "dev"
-	commit  = "unknown"
-	date    = "unknown"
-)
+// apiVersionProbeTimeout bounds the startup check of the DefectDojo API version
+const apiVersionProbeTimeout = 10 * time.Second
 
 func main() {
 	// Parse command line flags
 	var showVersion = flag.Bool("version", false, "Show version information")
+	var versionJSON = flag.Bool("json", false, "With --version, print build metadata as JSON")
+	var runCheck = flag.Bool("check", false, "Verify DefectDojo connectivity, authentication and permissions, then exit")
+	var healthPort = flag.Int("health-port", 0, "Serve /healthz, /readyz and /metrics on this port (overrides HEALTH_PORT)")
+	var configFile = flag.String("config", "", "YAML configuration file; environment variables and flags override it")
+	var transport = flag.String("transport", config.TransportStdio, "MCP transport: stdio, http or sse (overrides MCP_TRANSPORT)")
+	var listen = flag.String("listen", "localhost:8000", "Address the http and sse transports listen on, e.g. :8081 (overrides MCP_LISTEN)")
+	var readOnly = flag.Bool("read-only", false, "Register only tools that do not change DefectDojo (overrides READ_ONLY)")
 	flag.Parse()
 
 	if *showVersion {
-		fmt.Printf("mcp-defect-dojo %s\n", version)
-		fmt.Printf("Commit: %s\n", commit)
-		fmt.Printf("Build Date: %s\n", date)
+		info := mcpserver.BuildInfo()
+		if *versionJSON {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(info); err != nil {
+				log.Fatalf("❌ %v", err)
+			}
+			os.Exit(0)
+		}
+		fmt.Printf("mcp-defect-dojo %s\n", info.Version)
+		fmt.Printf("Commit: %s\n", info.Commit)
+		fmt.Printf("Build Date: %s\n", info.Date)
+		fmt.Printf("Go: %s, mcp-go: %s, MCP protocol: %s\n", info.GoVersion, info.MCPGoVersion, strings.Join(info.SupportedProtocolVersions, ", "))
 		os.Exit(0)
 	}
 
@@ -58,45 +202,334 @@ func main() {
 	log.SetOutput(os.Stderr)
 	log.SetFlags(log.LstdFlags | log.Lshortfile)
 
-	// Load configuration from YAML file with environment variable overrides
+	// The fake DefectDojo needs none of the server configuration
+	if flag.Arg(0) == "mockdojo" {
+		os.Exit(runMockDojoCommand(flag.Args()[1:]))
+	}
+
+	// Load configuration from the YAML file with environment variable overrides
 	cfg := config.Load()
+	if *configFile != "" {
+		loaded, err := config.LoadFile(*configFile)
+		if err != nil {
+			log.Fatalf("❌ Failed to load configuration: %v", err)
+		}
+		cfg = loaded
+		log.Printf("⚙️  Configuration loaded from %s", *configFile)
+	}
 
-	// Convert to mcpserver.Config format
-	mcpConfig := &mcpserver.Config{
-		DefectDojo: mcpserver.DefectDojoConfig{
-			BaseURL:        cfg.DefectDojo.BaseURL,
-			APIKey:         cfg.DefectDojo.APIKey,
-			APIVersion:     cfg.DefectDojo.APIVersion,
-			RequestTimeout: cfg.DefectDojo.RequestTimeout,
-		},
-		Server: mcpserver.ServerConfig{
-			Name:         cfg.Server.Name,
-			Version:      cfg.Server.Version,
-			Instructions: cfg.Server.Instructions,
-		},
-		Logging: mcpserver.LoggingConfig{
-			Level:  cfg.Logging.Level,
-			Format: cfg.Logging.Format,
-		},
+	// Explicitly set flags win over both
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "health-port":
+			cfg.Server.HealthPort = *healthPort
+		case "transport":
+			cfg.Server.Transport = strings.ToLower(*transport)
+		case "listen":
+			cfg.Server.Listen = *listen
+		case "read-only":
+			cfg.Server.ReadOnly = *readOnly
+		}
+	})
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("❌ Invalid configuration: %v", err)
+	}
+	if err := defectdojo.CheckAPIVersion(cfg.DefectDojo.APIVersion); err != nil {
+		log.Fatalf("❌ Invalid configuration: %v", err)
+	}
+
+	// Offline mode must not silently degrade: fail fast on broken fixtures
+	if cfg.DefectDojo.Mode == defectdojo.ModeOffline {
+		fixtures, err := defectdojo.NewFixtureClient(cfg.DefectDojo.FixturesDir)
+		if err != nil {
+			log.Fatalf("❌ Failed to load offline fixtures: %v", err)
+		}
+		_, message := fixtures.HealthCheck(context.Background())
+		log.Printf("📦 %s", message)
+		if *runCheck {
+			fmt.Println(message)
+			os.Exit(0)
+		}
+	}
+
+	// Saved queries are vetted by the operator: reject a broken file at startup
+	var savedQueries map[string]mcpserver.SavedQuery
+	if cfg.Queries.FilePath != "" {
+		queries, err := mcpserver.LoadSavedQueries(cfg.Queries.FilePath)
+		if err != nil {
+			log.Fatalf("❌ Failed to load saved queries: %v", err)
+		}
+		savedQueries = queries
+		log.Printf("🔖 Loaded %d saved queries from %s", len(queries), cfg.Queries.FilePath)
+	}
+	if _, ok := savedQueries[cfg.Polling.Query]; cfg.Polling.Query != "" && !ok {
+		log.Fatalf("❌ POLL_QUERY %q is not a saved query (set SAVED_QUERIES_FILE)", cfg.Polling.Query)
 	}
 
+	// A broken write policy must never mean unrestricted writes: fail fast
+	var policy *mcpserver.WritePolicy
+	if cfg.Policy.FilePath != "" {
+		rules, err := mcpserver.LoadWritePolicy(cfg.Policy.FilePath)
+		if err != nil {
+			log.Fatalf("❌ Failed to load write policy: %v", err)
+		}
+		policy = rules
+		log.Printf("🛡️  Write policy loaded from %s", cfg.Policy.FilePath)
+	}
+
+	// Notification webhooks are configured by the operator: reject a broken file at startup
+	var webhooks []mcpserver.NotificationWebhook
+	if cfg.Notify.FilePath != "" {
+		loaded, err := mcpserver.LoadNotificationWebhooks(cfg.Notify.FilePath)
+		if err != nil {
+			log.Fatalf("❌ Failed to load notification webhooks: %v", err)
+		}
+		webhooks = loaded
+		log.Printf("🔔 Loaded %d notification webhooks from %s", len(loaded), cfg.Notify.FilePath)
+	}
+	if cfg.Notify.WebhookURL != "" {
+		webhooks = append(webhooks, mcpserver.NotificationWebhook{URL: cfg.Notify.WebhookURL, Format: cfg.Notify.WebhookFormat})
+	}
+
+	// Offline enrichment data is shipped by the operator: reject a broken dataset at startup
+	var cveDataset *mcpserver.CVEDataset
+	if cfg.Enrichment.Mode == mcpserver.EnrichmentOffline {
+		if cfg.Enrichment.DataDir == "" {
+			log.Fatalf("❌ CVE_ENRICHMENT=offline requires CVE_ENRICHMENT_DATA_DIR")
+		}
+		dataset, err := mcpserver.LoadCVEDataset(cfg.Enrichment.DataDir)
+		if err != nil {
+			log.Fatalf("❌ Failed to load CVE enrichment data: %v", err)
+		}
+		cveDataset = dataset
+		log.Printf("🎯 CVE enrichment data loaded from %s", cfg.Enrichment.DataDir)
+	}
+
+	// One-shot diagnosis for operators
+	if *runCheck {
+		checker := defectdojo.NewHTTPClient(&cfg.DefectDojo)
+		report := checker.SelfCheck(context.Background())
+		checker.Close()
+		fmt.Print(report.String())
+		if !report.OK() {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	// Install tracing before any tool call can start spans
+	shutdownTracing, err := telemetry.Setup(context.Background(), cfg.Tracing, cfg.Server.Name, cfg.Server.Version)
+	if err != nil {
+		log.Fatalf("❌ Failed to set up tracing: %v", err)
+	}
+	stopTracing := func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			log.Printf("⚠️  Tracing shutdown error: %v", err)
+		}
+	}
+	defer stopTracing()
+
+	mcpConfig := serverConfig(cfg, operatorFiles{
+		savedQueries: savedQueries,
+		policy:       policy,
+		webhooks:     webhooks,
+		cveDataset:   cveDataset,
+	})
+
 	// Create MCP server instance
-	server := mcpserver.NewServer(mcpConfig)
+	server, err := mcpserver.NewServerWithConfig(mcpConfig)
+	if err != nil {
+		log.Fatalf("❌ Failed to create server: %v", err)
+	}
+	defer server.Close()
+
+	// os.Exit skips deferred calls: close the server and flush traces first
+	exit := func(code int) {
+		server.Close()
+		stopTracing()
+		os.Exit(code)
+	}
+
+	// Subcommands inspect or exercise the configured server instead of serving it
+	switch command := flag.Arg(0); command {
+	case "":
+	case "tools":
+		exit(runToolsCommand(server, flag.Args()[1:]))
+	case "call":
+		exit(runCallCommand(server, flag.Args()[1:]))
+	case "doctor":
+		exit(runDoctorCommand(cfg, flag.Args()[1:]))
+	default:
+		log.Printf("❌ Unknown command %q (available: tools, call, doctor, mockdojo)", command)
+		exit(1)
+	}
 
 	// Log startup information to stderr (stdout is reserved for MCP protocol)
 	log.Printf("🚀 Starting %s %s", cfg.Server.Name, cfg.Server.Version)
 	log.Printf("🔗 DefectDojo URL: %s", cfg.DefectDojo.BaseURL)
-	if cfg.DefectDojo.APIKey != "" {
+	switch {
+	case len(cfg.DefectDojo.Credentials) > 0:
+		log.Printf("🔑 Using %d per-product API credentials", len(cfg.DefectDojo.Credentials))
+	case cfg.DefectDojo.APIKey != "":
 		log.Printf("🔑 Using API key authentication")
-	} else {
+	default:
 		log.Printf("⚠️  No API key configured - using anonymous access")
 	}
-	log.Printf("📡 MCP server ready for stdio communication")
+	if len(cfg.DefectDojo.Instances) > 0 {
+		log.Printf("🩺 Health check also probes %d further instances", len(cfg.DefectDojo.Instances))
+	}
+	if cfg.Audit.FilePath != "" {
+		log.Printf("📝 Audit log: %s", cfg.Audit.FilePath)
+	}
+	if cfg.Tracing.Enabled {
+		log.Printf("🔭 OpenTelemetry tracing enabled")
+	}
+	if cfg.Server.ReadOnly {
+		log.Printf("🔒 Read-only mode: write tools are disabled")
+	}
+
+	// Serve orchestration probes alongside the MCP transport
+	if cfg.Server.HealthPort != 0 {
+		healthServer := &http.Server{
+			Addr:              fmt.Sprintf(":%d", cfg.Server.HealthPort),
+			Handler:           server.HealthHandler(),
+			ReadHeaderTimeout: 5 * time.Second,
+		}
+		go func() {
+			if err := healthServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Printf("❌ Health endpoint error: %v", err)
+			}
+		}()
+		defer healthServer.Close()
+		log.Printf("🩺 Health endpoints on :%d (/healthz, /readyz, /metrics)", cfg.Server.HealthPort)
+	}
 
-	// Start the stdio server
-	if err := server.Run(context.Background()); err != nil {
+	// Profile the server on an operator-only listener
+	if cfg.Server.DebugListen != "" {
+		debugServer := &http.Server{
+			Addr:              cfg.Server.DebugListen,
+			Handler:           server.DebugHandler(),
+			ReadHeaderTimeout: 5 * time.Second,
+		}
+		go func() {
+			if err := debugServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Printf("❌ Debug endpoint error: %v", err)
+			}
+		}()
+		defer debugServer.Close()
+		log.Printf("🐞 Debug endpoints on %s (/debug/pprof/, /debug/runtime)", cfg.Server.DebugListen)
+	}
+
+	// Reviewers approve or reject queued writes over HTTP; besides approval
+	// mode, the write policy may queue some writes (downgrade_approval)
+	if cfg.Approval.Required || cfg.Approval.Port != 0 {
+		if cfg.Approval.Port == 0 {
+			log.Printf("⚠️  Approval mode without APPROVAL_PORT: queued writes can only be approved by an embedding application")
+		} else {
+			addr := net.JoinHostPort(cfg.Approval.Host, strconv.Itoa(cfg.Approval.Port))
+			approvalServer := &http.Server{
+				Addr:              addr,
+				Handler:           server.ApprovalHandler(),
+				ReadHeaderTimeout: 5 * time.Second,
+			}
+			go func() {
+				if err := approvalServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+					log.Printf("❌ Approval endpoint error: %v", err)
+				}
+			}()
+			defer approvalServer.Close()
+			if cfg.Approval.Token == "" {
+				log.Printf("⚠️  Approval endpoints on %s accept unauthenticated requests (set APPROVAL_TOKEN)", addr)
+			}
+			if cfg.Approval.Required {
+				log.Printf("🙋 Writes require approval; review them on %s (/actions)", addr)
+			} else {
+				log.Printf("🙋 Writes held by the write policy can be reviewed on %s (/actions)", addr)
+			}
+		}
+	}
+
+	// Receive DefectDojo notifications so agents don't have to poll
+	if cfg.Webhook.Port != 0 {
+		webhookServer := &http.Server{
+			Addr:              fmt.Sprintf(":%d", cfg.Webhook.Port),
+			Handler:           server.WebhookHandler(),
+			ReadHeaderTimeout: 5 * time.Second,
+		}
+		go func() {
+			if err := webhookServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Printf("❌ Webhook endpoint error: %v", err)
+			}
+		}()
+		defer webhookServer.Close()
+		if cfg.Webhook.Secret == "" {
+			log.Printf("⚠️  Webhook listener on :%d accepts unauthenticated requests (set WEBHOOK_SECRET)", cfg.Webhook.Port)
+		} else {
+			log.Printf("📬 DefectDojo webhooks on :%d (/webhook)", cfg.Webhook.Port)
+		}
+	}
+
+	// Refuse an API version the instance does not serve. An unreachable
+	// instance only warns: it may come up after the server does.
+	if cfg.DefectDojo.Mode == "" || cfg.DefectDojo.Mode == defectdojo.ModeLive {
+		ctx, cancel := context.WithTimeout(context.Background(), apiVersionProbeTimeout)
+		probe := defectdojo.NewHTTPClient(&cfg.DefectDojo)
+		served, err := probe.NegotiateAPIVersion(ctx)
+		probe.Close()
+		cancel()
+		var unsupported *defectdojo.UnsupportedAPIVersionError
+		switch {
+		case errors.As(err, &unsupported):
+			log.Printf("❌ %v", err)
+			exit(1)
+		case err != nil:
+			log.Printf("⚠️  Could not check the DefectDojo API version: %v", err)
+		default:
+			log.Printf("🔌 DefectDojo serves API %s", strings.Join(served, ", "))
+		}
+	}
+
+	// Surface misconfiguration before the first tool call does
+	if cfg.Server.StartupCheck {
+		checker := defectdojo.NewHTTPClient(&cfg.DefectDojo)
+		report := checker.SelfCheck(context.Background())
+		checker.Close()
+		for _, line := range strings.Split(strings.TrimSpace(report.String()), "\n") {
+			log.Printf("🩺 %s", line)
+		}
+		if !report.OK() {
+			log.Printf("⚠️  Startup self-check failed; tools will error until the problems above are fixed")
+		}
+	}
+
+	if cfg.Logging.IsTraceMode() {
+		log.Printf("🔬 Dumping DefectDojo traffic (toggle with SIGUSR1)")
+	}
+	watchTrafficDumpToggle(server)
+
+	// Keep the findings digest current between agent runs
+	pollCtx, stopPolling := context.WithCancel(context.Background())
+	defer stopPolling()
+	server.StartPolling(pollCtx)
+	if cfg.Polling.Interval > 0 {
+		log.Printf("🔁 Polling findings every %s", cfg.Polling.Interval)
+	}
+
+	switch cfg.Server.Transport {
+	case config.TransportHTTP:
+		log.Printf("📡 MCP server listening on %s (streamable HTTP at /mcp)", cfg.Server.Listen)
+	case config.TransportSSE:
+		log.Printf("📡 MCP server listening on %s (SSE at /sse, messages at /message)", cfg.Server.Listen)
+	default:
+		log.Printf("📡 MCP server ready for stdio communication")
+	}
+
+	// Serve until the client disconnects or the service manager stops us
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := server.Serve(ctx, cfg.Server.Transport, cfg.Server.Listen); err != nil {
 		log.Printf("❌ MCP server error: %v", err)
-		os.Exit(1)
+		exit(1)
 	}
 
 	log.Printf("✅ MCP server shutdown complete")
@@ -118,3 +551,139 @@ func getEnvWithDefault(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// operatorFiles holds what main loaded from the files named in the configuration
+type operatorFiles struct {
+	savedQueries map[string]mcpserver.SavedQuery
+	policy       *mcpserver.WritePolicy
+	webhooks     []mcpserver.NotificationWebhook
+	cveDataset   *mcpserver.CVEDataset
+}
+
+// serverConfig converts the loaded configuration into the mcpserver.Config
+// the server is built from
+func serverConfig(cfg *config.Config, files operatorFiles) *mcpserver.Config {
+	var credentials []mcpserver.Credential
+	for _, credential := range cfg.DefectDojo.Credentials {
+		credentials = append(credentials, mcpserver.Credential(credential))
+	}
+	var instances []mcpserver.Instance
+	for _, instance := range cfg.DefectDojo.Instances {
+		instances = append(instances, mcpserver.Instance(instance))
+	}
+	var authTokens []mcpserver.AuthToken
+	for _, token := range cfg.Auth.Tokens {
+		authTokens = append(authTokens, mcpserver.AuthToken(token))
+	}
+	toolBudgets := map[string]mcpserver.ToolBudget{}
+	for tool, budget := range cfg.Budgets.Tools {
+		toolBudgets[tool] = mcpserver.ToolBudget(budget)
+	}
+	maxResultBytes := -1 // max_result_kb: 0 turns summarizing off
+	if cfg.Output.MaxResultKB > 0 {
+		maxResultBytes = cfg.Output.MaxResultKB << 10
+	}
+
+	return &mcpserver.Config{
+		DefectDojo: mcpserver.DefectDojoConfig{
+			BaseURL:        cfg.DefectDojo.BaseURL,
+			UIBaseURL:      cfg.DefectDojo.UIBaseURL,
+			APIKey:         cfg.DefectDojo.APIKey,
+			APIVersion:     cfg.DefectDojo.APIVersion,
+			RequestTimeout: cfg.DefectDojo.RequestTimeout,
+			Mode:           cfg.DefectDojo.Mode,
+			FixturesDir:    cfg.DefectDojo.FixturesDir,
+			CassetteDir:    cfg.DefectDojo.CassetteDir,
+			ErrorDetail:    cfg.DefectDojo.ErrorDetail,
+			Credentials:    credentials,
+			Instances:      instances,
+		},
+		Server: mcpserver.ServerConfig{
+			Name:           cfg.Server.Name,
+			Version:        cfg.Server.Version,
+			Instructions:   cfg.Server.Instructions,
+			MaxToolTimeout: cfg.Server.MaxToolTimeout,
+			ReadOnly:       cfg.Server.ReadOnly,
+
+			ReferenceCacheTTL: cfg.Server.ReferenceCacheTTL,
+			IdempotencyWindow: cfg.Server.IdempotencyWindow,
+			WorkerPoolSize:    cfg.Server.WorkerPoolSize,
+			ToolErrors:        cfg.Server.ToolErrors,
+			RateLimit:         cfg.Server.RateLimit,
+			RateLimitBurst:    cfg.Server.RateLimitBurst,
+		},
+		Logging: mcpserver.LoggingConfig{
+			Level:    cfg.Logging.Level,
+			Format:   cfg.Logging.Format,
+			DumpFile: cfg.Logging.DumpFile,
+		},
+		Audit: mcpserver.AuditConfig{
+			FilePath: cfg.Audit.FilePath,
+		},
+		Output: mcpserver.OutputConfig{
+			MaxFieldChars:       cfg.Output.MaxFieldChars,
+			DetailLevel:         cfg.Output.DetailLevel,
+			MaxDescriptionChars: cfg.Output.MaxDescriptionChars,
+			ListLimit:           cfg.Output.ListLimit,
+			MaxListLimit:        cfg.Output.MaxListLimit,
+			Format:              cfg.Output.Format,
+			Language:            cfg.Output.Language,
+			ExportChunkSize:     cfg.Output.ExportChunkSize,
+			ExportMemoryBytes:   int64(cfg.Output.ExportMemoryMB) << 20,
+			ExportSpillDir:      cfg.Output.ExportSpillDir,
+			MaxResultBytes:      maxResultBytes,
+			SanitizeContent:     cfg.Output.SanitizeContent,
+			HTMLContent:         cfg.Output.HTMLContent,
+			SeverityLabels:      cfg.Output.SeverityLabels,
+		},
+		Queries: mcpserver.QueriesConfig{
+			FilePath: cfg.Queries.FilePath,
+			Saved:    files.savedQueries,
+		},
+		Policy: mcpserver.PolicyConfig{
+			FilePath: cfg.Policy.FilePath,
+			Rules:    files.policy,
+		},
+		Approval: mcpserver.ApprovalConfig{
+			Required: cfg.Approval.Required,
+			Token:    cfg.Approval.Token,
+		},
+		Webhook: mcpserver.WebhookConfig{
+			Secret:     cfg.Webhook.Secret,
+			BufferSize: cfg.Webhook.BufferSize,
+		},
+		Polling: mcpserver.PollingConfig{
+			Interval: cfg.Polling.Interval,
+			Query:    cfg.Polling.Query,
+		},
+		Enrichment: mcpserver.EnrichmentConfig{
+			Mode:     cfg.Enrichment.Mode,
+			DataDir:  cfg.Enrichment.DataDir,
+			Dataset:  files.cveDataset,
+			CacheTTL: cfg.Enrichment.CacheTTL,
+		},
+		Priority: mcpserver.PriorityConfig{
+			Weights:      mcpserver.PriorityWeights(cfg.Priority.Weights),
+			CriticalTags: cfg.Priority.CriticalTags,
+		},
+		IssueTracker: mcpserver.IssueTrackerConfig{
+			Provider:   cfg.Issues.Provider,
+			Token:      cfg.Issues.Token,
+			Repository: cfg.Issues.Repository,
+			APIURL:     cfg.Issues.APIURL,
+			Labels:     cfg.Issues.Labels,
+		},
+		Notification: mcpserver.NotificationConfig{
+			FilePath: cfg.Notify.FilePath,
+			Webhooks: files.webhooks,
+		},
+		Auth: mcpserver.AuthConfig{
+			Tokens: authTokens,
+			Roles:  cfg.Auth.Roles,
+		},
+		Budgets: mcpserver.BudgetsConfig{
+			Default: mcpserver.ToolBudget(cfg.Budgets.Default),
+			Tools:   toolBudgets,
+		},
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/brduru/mcp-defect-dojo/internal/config"
+	"github.com/brduru/mcp-defect-dojo/pkg/mcpserver"
+)
+
+func TestServerConfigApprovalToken(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.DefectDojo.Mode = "offline"
+	cfg.Approval.Required = true
+	cfg.Approval.Host = "0.0.0.0"
+	cfg.Approval.Port = 8090
+	cfg.Approval.Token = "approver-secret"
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	server, err := mcpserver.NewServerWithConfig(serverConfig(cfg, operatorFiles{}))
+	if err != nil {
+		t.Fatalf("NewServerWithConfig() error = %v", err)
+	}
+	defer server.Close()
+	handler := server.ApprovalHandler()
+
+	for _, authorization := range []string{"", "Bearer wrong"} {
+		req := httptest.NewRequest(http.MethodPost, "/actions/1/approve", nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("Authorization %q: expected 401, got %d", authorization, rec.Code)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/actions", nil)
+	req.Header.Set("Authorization", "Bearer approver-secret")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected the approval token to be accepted, got %d", rec.Code)
+	}
+}
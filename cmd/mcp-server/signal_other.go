@@ -0,0 +1,9 @@
+//go:build windows
+
+package main
+
+import "github.com/brduru/mcp-defect-dojo/pkg/mcpserver"
+
+// watchTrafficDumpToggle is a no-op where SIGUSR1 is unavailable;
+// use LOG_LEVEL=trace to enable traffic dumps at startup instead.
+func watchTrafficDumpToggle(server *mcpserver.Server) {}
@@ -0,0 +1,27 @@
+//go:build !windows
+
+package main
+
+import (
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/brduru/mcp-defect-dojo/pkg/mcpserver"
+)
+
+// watchTrafficDumpToggle flips DefectDojo traffic dumping on every SIGUSR1,
+// so a running server can be diagnosed without a restart.
+func watchTrafficDumpToggle(server *mcpserver.Server) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGUSR1)
+
+	go func() {
+		for range signals {
+			enabled := !server.TrafficDumpEnabled()
+			server.SetTrafficDump(enabled)
+			log.Printf("🔬 DefectDojo traffic dump enabled: %t", enabled)
+		}
+	}()
+}
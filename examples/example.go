@@ -123,32 +123,16 @@ func runEmbeddedExample() error {
 
 	log.Printf("  ✅ Created embedded MCP server")
 
-	// Create in-process client
-	mcpClient, err := client.NewInProcessClient(server.GetMCPServer())
+	// Create an in-process client, already initialized
+	mcpClient, err := server.NewInProcessClient(ctx)
 	if err != nil {
+		log.Printf("  ⚠️ Client initialization error: %v", err)
 		return err
 	}
+	defer mcpClient.Close()
 
 	log.Printf("  ✅ Created in-process client")
 
-	// Initialize the client
-	initRequest := mcp.InitializeRequest{
-		Params: mcp.InitializeParams{
-			ProtocolVersion: "2024-11-05",
-			Capabilities:    mcp.ClientCapabilities{},
-			ClientInfo: mcp.Implementation{
-				Name:    "mcp-defectdojo-example",
-				Version: "1.0.0",
-			},
-		},
-	}
-
-	_, err = mcpClient.Initialize(ctx, initRequest)
-	if err != nil {
-		log.Printf("  ⚠️ Client initialization error: %v", err)
-		return err
-	}
-
 	// Test health check tool
 	result, err := mcpClient.CallTool(ctx, mcp.CallToolRequest{
 		Params: mcp.CallToolParams{
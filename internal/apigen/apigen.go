@@ -0,0 +1,437 @@
+// Package apigen generates the low-level DefectDojo API calls of
+// internal/defectdojo from an OpenAPI document, in practice a hand-trimmed
+// excerpt of DefectDojo's schema: one HTTPClient method per selected
+// operation, a parameters struct per operation taking query parameters, and
+// a struct per request or response schema that the configuration does not
+// map to an existing type. The hand-written Client methods wrap these calls,
+// so covering a new endpoint means copying its operation into the excerpt,
+// selecting it and regenerating instead of writing structs and URLs by hand.
+// The checked-in excerpt maps every schema to pkg/types, so today only the
+// calls and their parameters structs are generated.
+package apigen
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+)
+
+// Document is the part of an OpenAPI 3 document the generator reads
+type Document struct {
+	Paths      map[string]map[string]json.RawMessage `json:"paths"`
+	Components struct {
+		Schemas map[string]*Schema `json:"schemas"`
+	} `json:"components"`
+}
+
+// Operation is one method of a path
+type Operation struct {
+	OperationID string      `json:"operationId"`
+	Summary     string      `json:"summary"`
+	Description string      `json:"description"`
+	Parameters  []Parameter `json:"parameters"`
+	RequestBody *struct {
+		Content map[string]struct {
+			Schema *Schema `json:"schema"`
+		} `json:"content"`
+	} `json:"requestBody"`
+	Responses map[string]struct {
+		Content map[string]struct {
+			Schema *Schema `json:"schema"`
+		} `json:"content"`
+	} `json:"responses"`
+}
+
+// Parameter is a path or query parameter of an operation
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Required    bool    `json:"required"`
+	Description string  `json:"description"`
+	Schema      *Schema `json:"schema"`
+}
+
+// Schema is a JSON schema, as far as the generator understands it
+type Schema struct {
+	Ref         string             `json:"$ref"`
+	Type        string             `json:"type"`
+	Format      string             `json:"format"`
+	Description string             `json:"description"`
+	Nullable    bool               `json:"nullable"`
+	Items       *Schema            `json:"items"`
+	Properties  map[string]*Schema `json:"properties"`
+	Required    []string           `json:"required"`
+}
+
+// Load reads an OpenAPI document and a generator configuration, both JSON
+func Load(documentPath, configPath string) (*Document, Config, error) {
+	var doc Document
+	var config Config
+	for path, into := range map[string]any{documentPath: &doc, configPath: &config} {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, Config{}, err
+		}
+		if err := json.Unmarshal(data, into); err != nil {
+			return nil, Config{}, fmt.Errorf("parsing %s: %w", path, err)
+		}
+	}
+	return &doc, config, nil
+}
+
+// Config selects what to generate
+type Config struct {
+	Package    string            `json:"package"`
+	Imports    []string          `json:"imports"`     // Packages the mapped types live in
+	PathPrefix string            `json:"path_prefix"` // Stripped from paths, which the client prefixes with its API base path
+	Types      map[string]string `json:"types"`       // Schema names mapped to existing Go types
+	Operations []string          `json:"operations"`  // Operation IDs to generate, in output order
+}
+
+// httpMethods are the keys of a path item that name operations
+var httpMethods = []string{"get", "put", "post", "delete", "patch"}
+
+// generator accumulates the output of one Generate call
+type generator struct {
+	doc     *Document
+	config  Config
+	out     bytes.Buffer
+	structs map[string]bool // Generated schema structs, by schema name
+	pending []string        // Schemas referenced but not generated yet
+	query   bool            // Whether any operation takes query parameters
+}
+
+// Generate returns the formatted Go source of the configured operations
+func Generate(doc *Document, config Config) ([]byte, error) {
+	g := &generator{doc: doc, config: config, structs: map[string]bool{}}
+	operations := g.operations()
+	for _, id := range config.Operations {
+		op, ok := operations[id]
+		if !ok {
+			return nil, fmt.Errorf("operation %s is not in the schema", id)
+		}
+		if err := g.operation(op); err != nil {
+			return nil, fmt.Errorf("operation %s: %w", id, err)
+		}
+	}
+	for len(g.pending) > 0 {
+		name := g.pending[0]
+		g.pending = g.pending[1:]
+		if err := g.schemaStruct(name); err != nil {
+			return nil, fmt.Errorf("schema %s: %w", name, err)
+		}
+	}
+
+	var header bytes.Buffer
+	fmt.Fprintf(&header, "// Code generated by apigen; DO NOT EDIT.\n\npackage %s\n\nimport (\n\t\"context\"\n", config.Package)
+	if g.query {
+		header.WriteString("\t\"net/url\"\n\t\"strconv\"\n")
+	}
+	header.WriteString("\n")
+	for _, path := range config.Imports {
+		fmt.Fprintf(&header, "\t%q\n", path)
+	}
+	header.WriteString(")\n")
+	source := append(header.Bytes(), g.out.Bytes()...)
+	formatted, err := format.Source(source)
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %w", err)
+	}
+	return formatted, nil
+}
+
+// located is an operation with the method and path it was declared under
+type located struct {
+	Operation
+	method, path string
+}
+
+// operations indexes the document's operations by ID
+func (g *generator) operations() map[string]located {
+	result := map[string]located{}
+	for path, item := range g.doc.Paths {
+		for _, method := range httpMethods {
+			raw, ok := item[method]
+			if !ok {
+				continue
+			}
+			var op Operation
+			if json.Unmarshal(raw, &op) == nil && op.OperationID != "" {
+				result[op.OperationID] = located{op, strings.ToUpper(method), path}
+			}
+		}
+	}
+	return result
+}
+
+// operation writes the method calling op, and its parameters struct if any
+func (g *generator) operation(op located) error {
+	name := lowerCamel(op.OperationID)
+	template, ok := strings.CutPrefix(op.path, g.config.PathPrefix)
+	if !ok {
+		return fmt.Errorf("path %s is outside %s", op.path, g.config.PathPrefix)
+	}
+	path := template
+
+	var args, pathArgs []string
+	var query []Parameter
+	for _, param := range op.Parameters {
+		switch param.In {
+		case "path":
+			kind, verb, err := g.scalar(param.Schema)
+			if err != nil {
+				return fmt.Errorf("path parameter %s: %w", param.Name, err)
+			}
+			arg := lowerCamel(param.Name)
+			path = strings.ReplaceAll(path, "{"+param.Name+"}", verb)
+			args = append(args, arg+" "+kind)
+			pathArgs = append(pathArgs, arg)
+		case "query":
+			query = append(query, param)
+		}
+	}
+	slices.SortFunc(query, func(a, b Parameter) int { return strings.Compare(a.Name, b.Name) })
+
+	var payload string
+	if op.RequestBody != nil {
+		content, ok := op.RequestBody.Content["application/json"]
+		if !ok {
+			return fmt.Errorf("request body is not JSON")
+		}
+		kind, err := g.goType(content.Schema)
+		if err != nil {
+			return fmt.Errorf("request body: %w", err)
+		}
+		args = append(args, "body "+kind)
+		payload = "body"
+	}
+
+	result, err := g.response(op.Operation)
+	if err != nil {
+		return err
+	}
+
+	if len(query) > 0 {
+		g.query = true
+		if err := g.paramsStruct(name, query); err != nil {
+			return err
+		}
+		args = append(args, "params "+name+"Params")
+	}
+
+	summary := strings.TrimSpace(cmp.Or(op.Summary, op.Description))
+	fmt.Fprintf(&g.out, "\n// %s calls %s %s", name, op.method, template)
+	if summary != "" {
+		fmt.Fprintf(&g.out, ": %s", firstLine(summary))
+	}
+	fmt.Fprintf(&g.out, "\nfunc (c *HTTPClient) %s(%s) ", name, strings.Join(append([]string{"ctx context.Context"}, args...), ", "))
+
+	url := fmt.Sprintf("c.apiURL(%q%s)", path, prefixed(", ", strings.Join(pathArgs, ", ")))
+	if len(query) > 0 {
+		url = fmt.Sprintf("withQuery(%s, params.values())", url)
+	}
+	payload = cmp.Or(payload, "nil")
+	if result == "" {
+		fmt.Fprintf(&g.out, "error {\n\treturn c.doJSON(ctx, %q, %s, %s, nil)\n}\n", op.method, url, payload)
+		return nil
+	}
+	fmt.Fprintf(&g.out, "(*%s, error) {\n\tvar out %s\n", result, result)
+	fmt.Fprintf(&g.out, "\tif err := c.doJSON(ctx, %q, %s, %s, &out); err != nil {\n\t\treturn nil, err\n\t}\n\treturn &out, nil\n}\n", op.method, url, payload)
+	return nil
+}
+
+// response returns the Go type of op's first successful JSON response, or
+// "" for none
+func (g *generator) response(op Operation) (string, error) {
+	for _, status := range slices.Sorted(maps.Keys(op.Responses)) {
+		if !strings.HasPrefix(status, "2") {
+			continue
+		}
+		content, ok := op.Responses[status].Content["application/json"]
+		if !ok || content.Schema == nil {
+			return "", nil
+		}
+		kind, err := g.goType(content.Schema)
+		if err != nil {
+			return "", fmt.Errorf("response %s: %w", status, err)
+		}
+		return kind, nil
+	}
+	return "", fmt.Errorf("no successful response")
+}
+
+// paramsStruct writes the struct of an operation's query parameters, whose
+// unset (nil) fields are left out of the query
+func (g *generator) paramsStruct(name string, query []Parameter) error {
+	var fields, values strings.Builder
+	for _, param := range query {
+		kind, _, err := g.scalar(param.Schema)
+		if err != nil {
+			return fmt.Errorf("query parameter %s: %w", param.Name, err)
+		}
+		field := upperCamel(param.Name)
+		fmt.Fprintf(&fields, "\t%s *%s", field, kind)
+		if param.Description != "" {
+			fmt.Fprintf(&fields, " // %s", firstLine(param.Description))
+		}
+		fields.WriteString("\n")
+
+		var encoded string
+		switch kind {
+		case "int":
+			encoded = "strconv.Itoa(*p." + field + ")"
+		case "bool":
+			encoded = "strconv.FormatBool(*p." + field + ")"
+		case "float64":
+			encoded = "strconv.FormatFloat(*p." + field + ", 'f', -1, 64)"
+		default:
+			encoded = "*p." + field
+		}
+		fmt.Fprintf(&values, "\tif p.%s != nil {\n\t\tvalues.Set(%q, %s)\n\t}\n", field, param.Name, encoded)
+	}
+	fmt.Fprintf(&g.out, "\n// %sParams are the query parameters of %s\ntype %sParams struct {\n%s}\n", name, name, name, fields.String())
+	fmt.Fprintf(&g.out, "\n// values encodes the parameters that are set\nfunc (p %sParams) values() url.Values {\n\tvalues := url.Values{}\n%s\treturn values\n}\n", name, values.String())
+	return nil
+}
+
+// schemaStruct writes the struct of a component schema
+func (g *generator) schemaStruct(name string) error {
+	schema, ok := g.doc.Components.Schemas[name]
+	if !ok {
+		return fmt.Errorf("not in the schema")
+	}
+	fmt.Fprintf(&g.out, "\n// %s is the %s schema", structName(name), name)
+	if schema.Description != "" {
+		fmt.Fprintf(&g.out, ": %s", firstLine(schema.Description))
+	}
+	fmt.Fprintf(&g.out, "\ntype %s struct {\n", structName(name))
+	for _, property := range slices.Sorted(maps.Keys(schema.Properties)) {
+		field := schema.Properties[property]
+		kind, err := g.goType(field)
+		if err != nil {
+			return fmt.Errorf("property %s: %w", property, err)
+		}
+		required := slices.Contains(schema.Required, property)
+		tag := property
+		if !required {
+			tag += ",omitempty"
+		}
+		// Pointers tell null from zero values, and leave optional objects out
+		if (field.Nullable || field.Ref != "" && !required) && !strings.HasPrefix(kind, "[]") && !strings.HasPrefix(kind, "map[") {
+			kind = "*" + kind
+		}
+		fmt.Fprintf(&g.out, "\t%s %s `json:%q`", upperCamel(property), kind, tag)
+		if field.Description != "" {
+			fmt.Fprintf(&g.out, " // %s", firstLine(field.Description))
+		}
+		g.out.WriteString("\n")
+	}
+	g.out.WriteString("}\n")
+	return nil
+}
+
+// goType returns the Go type of a schema: a mapped or generated type for a
+// reference, a Go type for anything else
+func (g *generator) goType(schema *Schema) (string, error) {
+	if schema == nil {
+		return "", fmt.Errorf("missing schema")
+	}
+	if schema.Ref != "" {
+		name, ok := strings.CutPrefix(schema.Ref, "#/components/schemas/")
+		if !ok {
+			return "", fmt.Errorf("unsupported reference %s", schema.Ref)
+		}
+		if mapped, ok := g.config.Types[name]; ok {
+			return mapped, nil
+		}
+		if !g.structs[name] {
+			g.structs[name] = true
+			g.pending = append(g.pending, name)
+		}
+		return structName(name), nil
+	}
+	switch schema.Type {
+	case "array":
+		item, err := g.goType(schema.Items)
+		if err != nil {
+			return "", err
+		}
+		return "[]" + item, nil
+	case "object":
+		return "map[string]any", nil
+	}
+	kind, _, err := g.scalar(schema)
+	return kind, err
+}
+
+// scalar returns the Go type and format verb of a scalar schema
+func (g *generator) scalar(schema *Schema) (kind, verb string, err error) {
+	if schema == nil {
+		return "", "", fmt.Errorf("missing schema")
+	}
+	switch schema.Type {
+	case "integer":
+		if schema.Format == "int64" {
+			return "int64", "%d", nil
+		}
+		return "int", "%d", nil
+	case "number":
+		return "float64", "%g", nil
+	case "boolean":
+		return "bool", "%t", nil
+	case "string":
+		return "string", "%s", nil
+	}
+	return "", "", fmt.Errorf("unsupported type %q", schema.Type)
+}
+
+// structName is the Go name of a generated schema struct, e.g. apiTestType for Test_Type
+func structName(schema string) string {
+	return "api" + upperCamel(schema)
+}
+
+// upperCamel converts a snake_case name to an exported Go identifier
+func upperCamel(name string) string {
+	var result strings.Builder
+	for part := range strings.FieldsFuncSeq(name, func(r rune) bool { return r == '_' || r == '-' || r == '.' }) {
+		if initialism := strings.ToUpper(part); initialisms[initialism] {
+			result.WriteString(initialism)
+			continue
+		}
+		result.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return result.String()
+}
+
+// lowerCamel converts a snake_case name to an unexported Go identifier
+func lowerCamel(name string) string {
+	first, rest, _ := strings.Cut(name, "_")
+	if initialisms[strings.ToUpper(first)] {
+		return strings.ToLower(first) + upperCamel(rest)
+	}
+	camel := upperCamel(name)
+	return strings.ToLower(camel[:1]) + camel[1:]
+}
+
+// initialisms are name parts written in upper case, as Go style asks
+var initialisms = map[string]bool{"ID": true, "URL": true, "API": true, "CVE": true, "CWE": true, "SLA": true, "HTTP": true}
+
+// firstLine returns the first line of text
+func firstLine(text string) string {
+	line, _, _ := strings.Cut(text, "\n")
+	return strings.TrimSpace(line)
+}
+
+// prefixed returns prefix+text, or nothing for empty text
+func prefixed(prefix, text string) string {
+	if text == "" {
+		return ""
+	}
+	return prefix + text
+}
@@ -0,0 +1,113 @@
+package apigen
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	var doc Document
+	err := json.Unmarshal([]byte(`{
+		"paths": {
+			"/api/v2/notes/{id}/": {
+				"parameters": [],
+				"get": {
+					"operationId": "notes_retrieve",
+					"summary": "Retrieve a note",
+					"parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "integer"}}],
+					"responses": {"200": {"content": {"application/json": {"schema": {"$ref": "#/components/schemas/Note"}}}}}
+				},
+				"delete": {
+					"operationId": "notes_destroy",
+					"parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "integer"}}],
+					"responses": {"204": {"description": "No response body"}}
+				}
+			}
+		},
+		"components": {"schemas": {
+			"Note": {"type": "object", "required": ["id", "entry"], "properties": {
+				"id": {"type": "integer"},
+				"entry": {"type": "string", "description": "Note text"},
+				"author": {"$ref": "#/components/schemas/User"},
+				"edited": {"type": "string", "format": "date-time", "nullable": true}
+			}},
+			"User": {"type": "object", "properties": {"user_id": {"type": "integer"}}}
+		}}
+	}`), &doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	source, err := Generate(&doc, Config{Package: "defectdojo", PathPrefix: "/api/v2", Operations: []string{"notes_retrieve", "notes_destroy"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Field alignment depends on the longest name, so compare single-spaced code
+	generated := strings.Join(strings.Fields(string(source)), " ")
+	for _, want := range []string{
+		"// notesRetrieve calls GET /notes/{id}/: Retrieve a note",
+		"func (c *HTTPClient) notesRetrieve(ctx context.Context, id int) (*apiNote, error) {",
+		`c.doJSON(ctx, "GET", c.apiURL("/notes/%d/", id), nil, &out)`,
+		"func (c *HTTPClient) notesDestroy(ctx context.Context, id int) error {",
+		"type apiNote struct {",
+		"Author *apiUser `json:\"author,omitempty\"`",
+		"Edited *string `json:\"edited,omitempty\"`",
+		"Entry string `json:\"entry\"` // Note text",
+		"type apiUser struct {",
+		"UserID int `json:\"user_id,omitempty\"`",
+	} {
+		if !strings.Contains(generated, want) {
+			t.Errorf("generated code lacks %q:\n%s", want, source)
+		}
+	}
+	if strings.Contains(string(source), "net/url") {
+		t.Error("net/url imported without query parameters")
+	}
+
+	if _, err := Generate(&doc, Config{Package: "defectdojo", PathPrefix: "/api/v2", Operations: []string{"notes_list"}}); err == nil {
+		t.Error("expected an error for an operation missing from the schema")
+	}
+	if _, err := Generate(&doc, Config{Package: "defectdojo", PathPrefix: "/api/v3", Operations: []string{"notes_retrieve"}}); err == nil {
+		t.Error("expected an error for a path outside the prefix")
+	}
+}
+
+func TestNames(t *testing.T) {
+	for name, want := range map[string][2]string{
+		"tests_retrieve":     {"TestsRetrieve", "testsRetrieve"},
+		"Test_Type":          {"TestType", "testType"},
+		"id":                 {"ID", "id"},
+		"cve_id":             {"CVEID", "cveID"},
+		"product_types_list": {"ProductTypesList", "productTypesList"},
+	} {
+		if got := upperCamel(name); got != want[0] {
+			t.Errorf("upperCamel(%q) = %q, want %q", name, got, want[0])
+		}
+		if got := lowerCamel(name); got != want[1] {
+			t.Errorf("lowerCamel(%q) = %q, want %q", name, got, want[1])
+		}
+	}
+}
+
+// TestGeneratedClientUpToDate fails when internal/defectdojo/api_gen.go does
+// not match its schema and configuration; run go generate ./internal/defectdojo
+func TestGeneratedClientUpToDate(t *testing.T) {
+	doc, config, err := Load("../defectdojo/openapi/schema.json", "../defectdojo/openapi/operations.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := Generate(doc, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile("../defectdojo/api_gen.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Error("api_gen.go is out of date, run go generate ./internal/defectdojo")
+	}
+}
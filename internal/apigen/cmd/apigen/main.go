@@ -0,0 +1,37 @@
+// Command apigen regenerates the low-level DefectDojo API calls:
+//
+//	go run ./internal/apigen/cmd/apigen -schema schema.json -config operations.json -out api_gen.go
+//
+// It is run by go generate in internal/defectdojo.
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"github.com/brduru/mcp-defect-dojo/internal/apigen"
+)
+
+func main() {
+	schema := flag.String("schema", "", "OpenAPI document (JSON), e.g. saved from /api/v2/oa3/schema/?format=json")
+	config := flag.String("config", "", "Generator configuration (JSON)")
+	out := flag.String("out", "", "Go file to write")
+	flag.Parse()
+	if *schema == "" || *config == "" || *out == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	doc, cfg, err := apigen.Load(*schema, *config)
+	if err != nil {
+		log.Fatal(err)
+	}
+	source, err := apigen.Generate(doc, cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*out, source, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
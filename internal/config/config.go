@@ -1,39 +1,231 @@
 package config
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
 	"os"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
+
+	"gopkg.in/yaml.v3"
 )
 
 // Config holds application configuration
 type Config struct {
-	DefectDojo DefectDojoConfig
-	Server     ServerConfig
-	Logging    LoggingConfig
+	DefectDojo DefectDojoConfig   `yaml:"defectdojo"`
+	Server     ServerConfig       `yaml:"server"`
+	Logging    LoggingConfig      `yaml:"logging"`
+	Audit      AuditConfig        `yaml:"audit"`
+	Tracing    TracingConfig      `yaml:"tracing"`
+	Output     OutputConfig       `yaml:"output"`
+	Queries    QueriesConfig      `yaml:"queries"`
+	Policy     PolicyConfig       `yaml:"policy"`
+	Approval   ApprovalConfig     `yaml:"approval"`
+	Webhook    WebhookConfig      `yaml:"webhook"`
+	Polling    PollingConfig      `yaml:"polling"`
+	Enrichment EnrichmentConfig   `yaml:"enrichment"`
+	Priority   PriorityConfig     `yaml:"priority"`
+	Issues     IssueTrackerConfig `yaml:"issue_tracker"`
+	Notify     NotificationConfig `yaml:"notify"`
+	Auth       AuthConfig         `yaml:"auth"`
+	Budgets    BudgetsConfig      `yaml:"budgets"`
 }
 
 // DefectDojoConfig contains DefectDojo API configuration
 type DefectDojoConfig struct {
-	BaseURL        string
-	APIKey         string
-	APIVersion     string
-	RequestTimeout time.Duration
+	BaseURL        string        `yaml:"url"`
+	UIBaseURL      string        `yaml:"ui_url"` // Web UI base URL for links, when it differs from BaseURL (empty = BaseURL)
+	APIKey         string        `yaml:"api_key"`
+	APIVersion     string        `yaml:"api_version"`
+	RequestTimeout time.Duration `yaml:"request_timeout"`
+	DumpTraffic    bool          `yaml:"-"`            // Dump sanitized requests/responses (enabled by LOG_LEVEL=trace)
+	DumpFile       string        `yaml:"-"`            // Destination for traffic dumps (empty = stderr)
+	Mode           string        `yaml:"mode"`         // "live" (default), "offline" to serve fixtures, "record" or "replay" for cassettes
+	FixturesDir    string        `yaml:"fixtures_dir"` // Fixture directory for offline mode (empty = built-in demo data)
+	CassetteDir    string        `yaml:"cassette_dir"` // Recorded traffic directory for record and replay modes
+	ErrorDetail    string        `yaml:"error_detail"` // "full" (default) passes DefectDojo error bodies to tool results, "sanitized" logs them and returns a summary
+
+	Credentials []CredentialConfig `yaml:"credentials"` // API tokens used only for some products (config file only)
+	Instances   []InstanceConfig   `yaml:"instances"`   // Further instances the health check probes (config file only)
+}
+
+// CredentialConfig is an API token used for the products it covers: those
+// listed, and every product of the listed product types
+type CredentialConfig struct {
+	Name         string `yaml:"name"`          // Names the credential in errors and logs
+	APIKey       string `yaml:"api_key"`       // DefectDojo API token
+	Products     []int  `yaml:"products"`      // Product IDs
+	ProductTypes []int  `yaml:"product_types"` // Product type IDs
+}
+
+// InstanceConfig is a further DefectDojo instance, e.g. staging, that the
+// health check probes next to the configured one. Tools only use the latter.
+type InstanceConfig struct {
+	Name    string `yaml:"name"`    // Names the instance in the health check
+	BaseURL string `yaml:"url"`     // DefectDojo instance URL
+	APIKey  string `yaml:"api_key"` // DefectDojo API token of the instance
 }
 
 // ServerConfig contains MCP server configuration
 type ServerConfig struct {
-	Name         string
-	Version      string
-	Instructions string
-	Host         string
-	Port         int
-	Transport    string // "stdio", "http"
+	Name         string `yaml:"-"`
+	Version      string `yaml:"-"`
+	Instructions string `yaml:"-"`
+	Transport    string `yaml:"transport"`     // "stdio", "http" (streamable HTTP) or "sse"
+	Listen       string `yaml:"listen"`        // host:port the HTTP transports listen on
+	ReadOnly     bool   `yaml:"read_only"`     // Register only tools that do not change DefectDojo
+	HealthPort   int    `yaml:"health_port"`   // Port serving /healthz and /readyz (0 = disabled)
+	StartupCheck bool   `yaml:"startup_check"` // Run the DefectDojo self-check before serving
+
+	MaxToolTimeout    time.Duration `yaml:"max_tool_timeout"`    // Upper bound for per-call timeout_seconds overrides
+	ReferenceCacheTTL time.Duration `yaml:"reference_cache_ttl"` // How long reference data (products, tests, ...) is cached (negative = disabled)
+	IdempotencyWindow time.Duration `yaml:"idempotency_window"`  // How long a repeated write call returns the earlier result (negative = disabled)
+	WorkerPoolSize    int           `yaml:"worker_pool_size"`    // DefectDojo requests fan-out operations run at once, across all calls
+	ToolErrors        string        `yaml:"tool_errors"`         // "strict" reports failed tool calls as JSON-RPC errors, "lenient" as error tool results
+	DebugListen       string        `yaml:"debug_listen"`        // host:port serving pprof profiles and runtime statistics (empty = disabled)
+	RateLimit         int           `yaml:"rate_limit"`          // Tool calls each MCP session may make per minute (0 = unlimited)
+	RateLimitBurst    int           `yaml:"rate_limit_burst"`    // Tool calls a session may make at once within the rate limit
 }
 
 // LoggingConfig contains logging configuration
 type LoggingConfig struct {
-	Level  string
-	Format string
+	Level    string `yaml:"level"`
+	Format   string `yaml:"format"`
+	DumpFile string `yaml:"dump_file"` // Destination for trace-level traffic dumps (empty = stderr)
+}
+
+// AuditConfig contains audit trail configuration for mutating operations
+type AuditConfig struct {
+	FilePath string `yaml:"file"` // JSON lines file receiving one record per write tool call (empty = disabled)
+}
+
+// TracingConfig contains OpenTelemetry tracing configuration
+type TracingConfig struct {
+	Enabled  bool   `yaml:"enabled"`  // Export spans via OTLP/HTTP
+	Endpoint string `yaml:"endpoint"` // OTLP endpoint URL (empty = OTEL_EXPORTER_OTLP_* defaults)
+}
+
+// OutputConfig contains defaults for tool output size
+type OutputConfig struct {
+	MaxFieldChars       int    `yaml:"max_field_chars"`       // Truncate long finding text sections (0 = unlimited)
+	DetailLevel         string `yaml:"detail_level"`          // Default findings list detail: summary, normal or full
+	MaxDescriptionChars int    `yaml:"max_description_chars"` // Truncate descriptions in findings lists (0 = unlimited)
+	ListLimit           int    `yaml:"list_limit"`            // Default number of findings per list page
+	MaxListLimit        int    `yaml:"max_list_limit"`        // Largest limit a tool call may request
+	Format              string `yaml:"format"`                // Default tool output format: text, markdown or json
+	Language            string `yaml:"language"`              // Language of output labels and headings: en, pt or es
+	ExportChunkSize     int    `yaml:"export_chunk_size"`     // Findings per export_findings chunk resource
+	ExportMemoryMB      int    `yaml:"export_memory_mb"`      // Export chunks kept in memory before the rest spill to disk
+	ExportSpillDir      string `yaml:"export_spill_dir"`      // Directory of export spill files (empty = system temporary directory)
+	MaxResultKB         int    `yaml:"max_result_kb"`         // Summarize tool results larger than this, linking the full result (0 = never)
+	SanitizeContent     bool   `yaml:"sanitize_content"`      // Neutralize instructions embedded in finding text from scanners
+	HTMLContent         string `yaml:"html_content"`          // HTML in finding text sections: markdown, text or raw
+
+	SeverityLabels map[string]string `yaml:"severity_labels"` // Organization label by DefectDojo severity, e.g. Critical: P1
+}
+
+// QueriesConfig contains operator-defined saved findings queries
+type QueriesConfig struct {
+	FilePath string `yaml:"file"` // JSON file of named queries for run_saved_query (empty = none)
+}
+
+// PolicyConfig contains the rules checked before every write operation
+type PolicyConfig struct {
+	FilePath string `yaml:"file"` // JSON file of write policy rules (empty = no restrictions)
+}
+
+// ApprovalConfig contains the human approval queue for write operations
+type ApprovalConfig struct {
+	Required bool   `yaml:"required"` // Queue write tool calls until a human approves them
+	Port     int    `yaml:"port"`     // Port serving the approval HTTP endpoints (0 = disabled)
+	Host     string `yaml:"host"`     // Address the approval endpoints listen on (default: 127.0.0.1)
+	Token    string `yaml:"token"`    // Bearer token reviewers must send (required unless Host is a loopback address)
+}
+
+// WebhookConfig contains the DefectDojo webhook notification listener
+type WebhookConfig struct {
+	Port       int    `yaml:"port"`        // Port receiving DefectDojo webhooks (0 = disabled)
+	Secret     string `yaml:"secret"`      // Shared secret expected in the Authorization header (empty = unauthenticated)
+	BufferSize int    `yaml:"buffer_size"` // Number of recent events kept for get_recent_events
+}
+
+// PollingConfig contains the background findings poller
+type PollingConfig struct {
+	Interval time.Duration `yaml:"interval"` // Time between polls (0 = poll only when the digest tool is called)
+	Query    string        `yaml:"query"`    // Saved query selecting the watched findings (empty = active findings)
+}
+
+// EnrichmentConfig contains the EPSS and CISA KEV enrichment of CVE findings
+type EnrichmentConfig struct {
+	Mode     string        `yaml:"mode"`      // "off" (default), "live" to query FIRST and CISA, "offline" to read DataDir
+	DataDir  string        `yaml:"data_dir"`  // Directory of downloaded EPSS and KEV files for offline mode
+	CacheTTL time.Duration `yaml:"cache_ttl"` // How long live EPSS scores and the KEV catalog are cached
+}
+
+// PriorityConfig contains the remediation priority formula of prioritize_findings
+type PriorityConfig struct {
+	Weights      PriorityWeights `yaml:"weights"`
+	CriticalTags []string        `yaml:"critical_tags"` // Product tags marking business-critical products
+}
+
+// IssueTrackerConfig contains the GitHub or GitLab project that
+// create_issue_from_finding files issues in
+type IssueTrackerConfig struct {
+	Provider   string   `yaml:"provider"`   // "github" or "gitlab" (empty = disabled)
+	Token      string   `yaml:"token"`      // API token allowed to create issues
+	Repository string   `yaml:"repository"` // GitHub owner/name or GitLab project path or ID
+	APIURL     string   `yaml:"url"`        // API base URL for GitHub Enterprise or self-managed GitLab
+	Labels     []string `yaml:"labels"`     // Labels added to every issue
+}
+
+// NotificationConfig contains the webhooks told about every write
+type NotificationConfig struct {
+	FilePath      string `yaml:"webhooks_file"`  // JSON file of webhooks with optional templates
+	WebhookURL    string `yaml:"webhook_url"`    // Single webhook, in addition to FilePath
+	WebhookFormat string `yaml:"webhook_format"` // Payload format of WebhookURL: "slack", "teams" or "json"
+}
+
+// AuthConfig contains the clients of the HTTP transports and the tools
+// each role may call
+type AuthConfig struct {
+	Tokens []AuthTokenConfig   `yaml:"tokens"` // Accepted bearer tokens (empty = unauthenticated)
+	Roles  map[string][]string `yaml:"roles"`  // Tools of custom roles, or of replaced viewer, triager and admin roles
+}
+
+// AuthTokenConfig is one client of the HTTP transports
+type AuthTokenConfig struct {
+	Name  string `yaml:"name"`  // Caller identity in audit records
+	Token string `yaml:"token"` // Bearer token the client sends
+	Role  string `yaml:"role"`  // Role deciding the tools the client may call
+}
+
+// BudgetsConfig contains what one tool call may read from DefectDojo
+type BudgetsConfig struct {
+	Default ToolBudget            `yaml:"default"` // Budget of every tool, and of the limits a tool leaves unset
+	Tools   map[string]ToolBudget `yaml:"tools"`   // Budgets by tool name
+}
+
+// ToolBudget contains the limits of one tool call (0 = unlimited)
+type ToolBudget struct {
+	MaxPages       int `yaml:"max_pages"`       // DefectDojo list requests
+	MaxFindings    int `yaml:"max_findings"`    // Findings read from findings lists
+	MaxConcurrency int `yaml:"max_concurrency"` // DefectDojo requests run at once in fan-out operations
+}
+
+// PriorityWeights contains the relative weight of each remediation priority factor
+type PriorityWeights struct {
+	Severity    float64 `yaml:"severity"`
+	CVSS        float64 `yaml:"cvss"`
+	EPSS        float64 `yaml:"epss"`
+	Age         float64 `yaml:"age"`
+	SLA         float64 `yaml:"sla"`
+	Criticality float64 `yaml:"criticality"`
 }
 
 // DefaultConfig returns default configuration
@@ -44,19 +236,54 @@ func DefaultConfig() *Config {
 			APIKey:         "",
 			APIVersion:     "v2",
 			RequestTimeout: 30 * time.Second,
+			Mode:           "live",
+			CassetteDir:    "cassettes",
+			ErrorDetail:    "full",
 		},
 		Server: ServerConfig{
 			Name:         "mcp-defect-dojo-server",
 			Version:      "0.4.0",
 			Instructions: "MCP server for DefectDojo integration. Provides tools to query vulnerability findings and manage security data.",
-			Host:         "localhost",
-			Port:         8000,
 			Transport:    "stdio", // Default to stdio for subprocess usage
+			Listen:       "localhost:8000",
+
+			MaxToolTimeout:    5 * time.Minute,
+			ReferenceCacheTTL: 10 * time.Minute,
+			IdempotencyWindow: 2 * time.Minute,
+			WorkerPoolSize:    16,
+			ToolErrors:        "strict",
+			RateLimitBurst:    10,
 		},
 		Logging: LoggingConfig{
 			Level:  "info",
 			Format: "text",
 		},
+		Output: OutputConfig{
+			MaxFieldChars:       2000,
+			DetailLevel:         "normal",
+			MaxDescriptionChars: 300,
+			ListLimit:           10,
+			MaxListLimit:        100,
+			Format:              "text",
+			Language:            "en",
+			ExportChunkSize:     1000,
+			ExportMemoryMB:      32,
+			MaxResultKB:         100,
+			HTMLContent:         "markdown",
+		},
+		Approval: ApprovalConfig{
+			Host: "127.0.0.1",
+		},
+		Webhook: WebhookConfig{
+			BufferSize: 100,
+		},
+		Enrichment: EnrichmentConfig{
+			Mode:     "off",
+			CacheTTL: 24 * time.Hour,
+		},
+		Priority: PriorityConfig{
+			Weights: PriorityWeights{Severity: 30, CVSS: 20, EPSS: 20, Age: 10, SLA: 10, Criticality: 10},
+		},
 	}
 }
 
@@ -67,21 +294,173 @@ func (c *DefectDojoConfig) GetTimeout() string {
 
 // GetAPIBasePath returns the full API base path
 func (c *DefectDojoConfig) GetAPIBasePath() string {
-	version := c.APIVersion
+	version := strings.Trim(c.APIVersion, "/ ")
 	if version == "" {
 		version = "v2" // Default to v2
 	}
 	return "/api/" + version
 }
 
+// apiSuffix matches an API path accidentally included in the base URL
+var apiSuffix = regexp.MustCompile(`/api/v\d+$`)
+
+// NormalizeBaseURL cleans up a DefectDojo base URL so API paths can be appended.
+// Surrounding whitespace and trailing slashes are removed, a missing scheme
+// defaults to https, and an accidental /api/<version> suffix is dropped.
+// Subpath deployments (https://host/defectdojo/) keep their path.
+// Unparseable values are returned trimmed so validation can report them.
+func NormalizeBaseURL(raw string) string {
+	value := strings.TrimSpace(raw)
+	if value == "" {
+		return ""
+	}
+	if !strings.Contains(value, "://") {
+		value = "https://" + value
+	}
+
+	parsed, err := url.Parse(value)
+	if err != nil {
+		return strings.TrimRight(value, "/")
+	}
+	parsed.Path = apiSuffix.ReplaceAllString(strings.TrimRight(parsed.Path, "/"), "")
+	parsed.RawPath = ""
+	return strings.TrimRight(parsed.String(), "/")
+}
+
 // IsDebugMode checks if debug logging is enabled
 func (c *LoggingConfig) IsDebugMode() bool {
-	return c.Level == "debug"
+	return c.Level == "debug" || c.IsTraceMode()
+}
+
+// IsTraceMode checks if trace logging (DefectDojo traffic dumps) is enabled
+func (c *LoggingConfig) IsTraceMode() bool {
+	return c.Level == "trace"
 }
 
+// Transports the server can be reached over
+const (
+	TransportStdio = "stdio"
+	TransportHTTP  = "http"
+	TransportSSE   = "sse"
+)
+
 // Validate validates the configuration
 func (c *Config) Validate() error {
-	// Add validation logic here if needed
+	switch c.Server.Transport {
+	case TransportStdio:
+	case TransportHTTP, TransportSSE:
+		if _, _, err := net.SplitHostPort(c.Server.Listen); err != nil {
+			return fmt.Errorf("invalid listen address %q: %w", c.Server.Listen, err)
+		}
+	default:
+		return fmt.Errorf("unknown transport %q (must be stdio, http or sse)", c.Server.Transport)
+	}
+	if c.Server.DebugListen != "" {
+		if _, _, err := net.SplitHostPort(c.Server.DebugListen); err != nil {
+			return fmt.Errorf("invalid debug_listen address %q: %w", c.Server.DebugListen, err)
+		}
+	}
+	if c.Approval.Port != 0 && c.Approval.Token == "" && !isLoopback(c.Approval.Host) {
+		return fmt.Errorf("approval endpoints on %q need an approval token: anyone reaching them could approve held writes", c.Approval.Host)
+	}
+	switch c.Server.ToolErrors {
+	case "", "strict", "lenient":
+	default:
+		return fmt.Errorf("unknown tool_errors %q (must be strict or lenient)", c.Server.ToolErrors)
+	}
+	switch c.DefectDojo.ErrorDetail {
+	case "", "full", "sanitized":
+	default:
+		return fmt.Errorf("unknown error_detail %q (must be full or sanitized)", c.DefectDojo.ErrorDetail)
+	}
+	if err := c.Budgets.Default.validate(); err != nil {
+		return fmt.Errorf("invalid default budget: %w", err)
+	}
+	for tool, budget := range c.Budgets.Tools {
+		if err := budget.validate(); err != nil {
+			return fmt.Errorf("invalid budget of %s: %w", tool, err)
+		}
+	}
+	if err := ValidateCredentials(c.DefectDojo.Credentials); err != nil {
+		return err
+	}
+	return ValidateInstances(c.DefectDojo.Instances)
+}
+
+// isLoopback reports whether host only accepts local connections
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// validate rejects negative limits
+func (b ToolBudget) validate() error {
+	if b.MaxPages < 0 || b.MaxFindings < 0 || b.MaxConcurrency < 0 {
+		return errors.New("limits must not be negative")
+	}
+	return nil
+}
+
+// ValidateCredentials checks that every credential has a unique name, a
+// token and a scope, and that no product or product type is covered twice,
+// so the token for a product is never ambiguous
+func ValidateCredentials(credentials []CredentialConfig) error {
+	names := map[string]bool{}
+	products := map[int]string{}
+	productTypes := map[int]string{}
+	for i, credential := range credentials {
+		switch {
+		case credential.Name == "":
+			return fmt.Errorf("credential %d has no name", i+1)
+		case names[credential.Name]:
+			return fmt.Errorf("credential %q is defined twice", credential.Name)
+		case credential.APIKey == "":
+			return fmt.Errorf("credential %q has no api_key", credential.Name)
+		case len(credential.Products) == 0 && len(credential.ProductTypes) == 0:
+			return fmt.Errorf("credential %q lists no products or product_types", credential.Name)
+		}
+		names[credential.Name] = true
+		for _, scope := range []struct {
+			kind  string
+			ids   []int
+			owner map[int]string
+		}{
+			{"product", credential.Products, products},
+			{"product type", credential.ProductTypes, productTypes},
+		} {
+			for _, id := range scope.ids {
+				if other, ok := scope.owner[id]; ok {
+					return fmt.Errorf("%s %d is covered by both credential %q and %q", scope.kind, id, other, credential.Name)
+				}
+				scope.owner[id] = credential.Name
+			}
+		}
+	}
+	return nil
+}
+
+// ValidateInstances checks that every further instance has a unique name
+// other than "default", which names the configured instance, an http(s) URL
+// and a token
+func ValidateInstances(instances []InstanceConfig) error {
+	names := map[string]bool{"default": true}
+	for i, instance := range instances {
+		u, err := url.Parse(instance.BaseURL)
+		switch {
+		case instance.Name == "":
+			return fmt.Errorf("instance %d has no name", i+1)
+		case names[instance.Name]:
+			return fmt.Errorf("instance name %q is already used", instance.Name)
+		case err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "":
+			return fmt.Errorf("instance %q has no valid url", instance.Name)
+		case instance.APIKey == "":
+			return fmt.Errorf("instance %q has no api_key", instance.Name)
+		}
+		names[instance.Name] = true
+	}
 	return nil
 }
 
@@ -90,10 +469,48 @@ func (c *Config) Validate() error {
 func Load() *Config {
 	// Start with default configuration (fixed server identity)
 	config := DefaultConfig()
+	applyEnvironment(config)
+	return config
+}
+
+// LoadFile loads configuration from a YAML file on top of the defaults, then
+// applies environment variable overrides, so the environment wins over the
+// file. Unknown keys are rejected; server identity cannot be set.
+func LoadFile(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading config file: %w", err)
+	}
+	config := DefaultConfig()
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(config); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("parsing config file %s: %w", path, err)
+	}
+	config.DefectDojo.BaseURL = NormalizeBaseURL(config.DefectDojo.BaseURL)
+	config.DefectDojo.UIBaseURL = NormalizeBaseURL(config.DefectDojo.UIBaseURL)
+	for i := range config.DefectDojo.Instances {
+		config.DefectDojo.Instances[i].BaseURL = NormalizeBaseURL(config.DefectDojo.Instances[i].BaseURL)
+	}
+	if config.Server.ReferenceCacheTTL <= 0 {
+		config.Server.ReferenceCacheTTL = -1 // 0 disables caching, as with REFERENCE_CACHE_TTL
+	}
+	if config.Server.IdempotencyWindow <= 0 {
+		config.Server.IdempotencyWindow = -1 // 0 disables duplicate detection, as with IDEMPOTENCY_WINDOW
+	}
+
+	applyEnvironment(config)
+	return config, nil
+}
 
+// applyEnvironment overrides configuration with environment variables
+func applyEnvironment(config *Config) {
 	// Override ONLY DefectDojo settings with environment variables
 	if val := os.Getenv("DEFECTDOJO_URL"); val != "" {
-		config.DefectDojo.BaseURL = val
+		config.DefectDojo.BaseURL = NormalizeBaseURL(val)
+	}
+	if val := os.Getenv("DEFECTDOJO_UI_URL"); val != "" {
+		config.DefectDojo.UIBaseURL = NormalizeBaseURL(val)
 	}
 	if val := os.Getenv("DEFECTDOJO_API_KEY"); val != "" {
 		config.DefectDojo.APIKey = val
@@ -101,6 +518,21 @@ func Load() *Config {
 	if val := os.Getenv("DEFECTDOJO_API_VERSION"); val != "" {
 		config.DefectDojo.APIVersion = val
 	}
+	if val := os.Getenv("DEFECTDOJO_MODE"); val != "" {
+		config.DefectDojo.Mode = strings.ToLower(val)
+	}
+	if val := os.Getenv("DEFECTDOJO_FIXTURES_DIR"); val != "" {
+		config.DefectDojo.FixturesDir = val
+	}
+	if val := os.Getenv("DEFECTDOJO_CASSETTE_DIR"); val != "" {
+		config.DefectDojo.CassetteDir = val
+	}
+	if val := os.Getenv("ERROR_DETAIL"); val != "" {
+		switch detail := strings.ToLower(val); detail {
+		case "full", "sanitized":
+			config.DefectDojo.ErrorDetail = detail
+		}
+	}
 
 	// Logging can be overridden for debugging
 	if val := os.Getenv("LOG_LEVEL"); val != "" {
@@ -109,9 +541,313 @@ func Load() *Config {
 	if val := os.Getenv("LOG_FORMAT"); val != "" {
 		config.Logging.Format = val
 	}
+	if val := os.Getenv("LOG_DUMP_FILE"); val != "" {
+		config.Logging.DumpFile = val
+	}
+	config.DefectDojo.DumpTraffic = config.Logging.IsTraceMode()
+	config.DefectDojo.DumpFile = config.Logging.DumpFile
+
+	// Audit trail for write operations
+	if val := os.Getenv("AUDIT_LOG_FILE"); val != "" {
+		config.Audit.FilePath = val
+	}
+
+	// Rules restricting what agents may change
+	if val := os.Getenv("WRITE_POLICY_FILE"); val != "" {
+		config.Policy.FilePath = val
+	}
+
+	// Human in the loop for agent-initiated writes
+	if val := os.Getenv("REQUIRE_APPROVAL"); val != "" {
+		config.Approval.Required = val == "true" || val == "1"
+	}
+	if val := os.Getenv("APPROVAL_PORT"); val != "" {
+		if port, err := strconv.Atoi(val); err == nil {
+			config.Approval.Port = port
+		}
+	}
+	if val := os.Getenv("APPROVAL_HOST"); val != "" {
+		config.Approval.Host = val
+	}
+	if val := os.Getenv("APPROVAL_TOKEN"); val != "" {
+		config.Approval.Token = val
+	}
+
+	// DefectDojo webhook notifications
+	if val := os.Getenv("WEBHOOK_PORT"); val != "" {
+		if port, err := strconv.Atoi(val); err == nil {
+			config.Webhook.Port = port
+		}
+	}
+	if val := os.Getenv("WEBHOOK_SECRET"); val != "" {
+		config.Webhook.Secret = val
+	}
+	if val := os.Getenv("WEBHOOK_BUFFER_SIZE"); val != "" {
+		if n, err := strconv.Atoi(val); err == nil && n > 0 {
+			config.Webhook.BufferSize = n
+		}
+	}
+
+	// Background polling for the "what changed" digest
+	if val := os.Getenv("POLL_INTERVAL"); val != "" {
+		if interval, err := time.ParseDuration(val); err == nil && interval >= 0 {
+			config.Polling.Interval = interval
+		}
+	}
+	if val := os.Getenv("POLL_QUERY"); val != "" {
+		config.Polling.Query = val
+	}
+
+	// Remediation priority formula, e.g. PRIORITY_WEIGHTS="severity=40,epss=30"
+	if val := os.Getenv("PRIORITY_WEIGHTS"); val != "" {
+		weights := &config.Priority.Weights
+		fields := map[string]*float64{
+			"severity": &weights.Severity, "cvss": &weights.CVSS, "epss": &weights.EPSS,
+			"age": &weights.Age, "sla": &weights.SLA, "criticality": &weights.Criticality,
+		}
+		for _, pair := range strings.Split(val, ",") {
+			name, value, _ := strings.Cut(pair, "=")
+			field, known := fields[strings.ToLower(strings.TrimSpace(name))]
+			if weight, err := strconv.ParseFloat(strings.TrimSpace(value), 64); known && err == nil && weight >= 0 {
+				*field = weight
+			}
+		}
+	}
+	if val := os.Getenv("PRIORITY_CRITICAL_TAGS"); val != "" {
+		config.Priority.CriticalTags = nil
+		for _, tag := range strings.Split(val, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				config.Priority.CriticalTags = append(config.Priority.CriticalTags, tag)
+			}
+		}
+	}
+
+	// EPSS and CISA KEV enrichment of CVE findings
+	if val := os.Getenv("CVE_ENRICHMENT"); val != "" {
+		config.Enrichment.Mode = strings.ToLower(val)
+	}
+	if val := os.Getenv("CVE_ENRICHMENT_DATA_DIR"); val != "" {
+		config.Enrichment.DataDir = val
+	}
+	if val := os.Getenv("CVE_ENRICHMENT_CACHE_TTL"); val != "" {
+		if ttl, err := time.ParseDuration(val); err == nil {
+			config.Enrichment.CacheTTL = ttl
+		}
+	}
+
+	// GitHub or GitLab issue hand-off
+	if val := os.Getenv("ISSUE_TRACKER"); val != "" {
+		config.Issues.Provider = strings.ToLower(val)
+	}
+	if val := os.Getenv("ISSUE_TRACKER_TOKEN"); val != "" {
+		config.Issues.Token = val
+	}
+	if val := os.Getenv("ISSUE_TRACKER_REPOSITORY"); val != "" {
+		config.Issues.Repository = val
+	}
+	if val := os.Getenv("ISSUE_TRACKER_URL"); val != "" {
+		config.Issues.APIURL = val
+	}
+	if val := os.Getenv("ISSUE_TRACKER_LABELS"); val != "" {
+		for _, label := range strings.Split(val, ",") {
+			if label = strings.TrimSpace(label); label != "" {
+				config.Issues.Labels = append(config.Issues.Labels, label)
+			}
+		}
+	}
+
+	// Slack/Teams notifications about writes
+	if val := os.Getenv("NOTIFY_WEBHOOKS_FILE"); val != "" {
+		config.Notify.FilePath = val
+	}
+	if val := os.Getenv("NOTIFY_WEBHOOK_URL"); val != "" {
+		config.Notify.WebhookURL = val
+	}
+	if val := os.Getenv("NOTIFY_WEBHOOK_FORMAT"); val != "" {
+		config.Notify.WebhookFormat = strings.ToLower(val)
+	}
+
+	// MCP transport
+	if val := os.Getenv("MCP_TRANSPORT"); val != "" {
+		config.Server.Transport = strings.ToLower(val)
+	}
+	if val := os.Getenv("MCP_LISTEN"); val != "" {
+		config.Server.Listen = val
+	}
+	if val := os.Getenv("READ_ONLY"); val != "" {
+		config.Server.ReadOnly = val == "true" || val == "1"
+	}
+
+	// Health probe listener for container orchestration
+	if val := os.Getenv("HEALTH_PORT"); val != "" {
+		if port, err := strconv.Atoi(val); err == nil {
+			config.Server.HealthPort = port
+		}
+	}
+
+	// Profiling listener, off unless asked for
+	if val := os.Getenv("DEBUG_LISTEN"); val != "" {
+		config.Server.DebugListen = val
+	}
+
+	if val := os.Getenv("STARTUP_CHECK"); val != "" {
+		config.Server.StartupCheck = val == "true" || val == "1"
+	}
+
+	if val := os.Getenv("MAX_TOOL_TIMEOUT"); val != "" {
+		if timeout, err := time.ParseDuration(val); err == nil {
+			config.Server.MaxToolTimeout = timeout
+		}
+	}
+
+	if val := os.Getenv("REFERENCE_CACHE_TTL"); val != "" {
+		if ttl, err := time.ParseDuration(val); err == nil {
+			if ttl <= 0 {
+				ttl = -1 // "0" disables caching rather than selecting the default
+			}
+			config.Server.ReferenceCacheTTL = ttl
+		}
+	}
+
+	if val := os.Getenv("IDEMPOTENCY_WINDOW"); val != "" {
+		if window, err := time.ParseDuration(val); err == nil {
+			if window <= 0 {
+				window = -1 // "0" disables duplicate detection rather than selecting the default
+			}
+			config.Server.IdempotencyWindow = window
+		}
+	}
+
+	if val := os.Getenv("TOOL_ERRORS"); val != "" {
+		switch mode := strings.ToLower(val); mode {
+		case "strict", "lenient":
+			config.Server.ToolErrors = mode
+		}
+	}
+
+	// Per-session tool call rate limit
+	if val := os.Getenv("RATE_LIMIT"); val != "" {
+		if limit, err := strconv.Atoi(val); err == nil && limit >= 0 {
+			config.Server.RateLimit = limit
+		}
+	}
+	if val := os.Getenv("RATE_LIMIT_BURST"); val != "" {
+		if burst, err := strconv.Atoi(val); err == nil && burst > 0 {
+			config.Server.RateLimitBurst = burst
+		}
+	}
+
+	// Default tool call budget, e.g. TOOL_BUDGET="max_pages=50,max_findings=5000"
+	if val := os.Getenv("TOOL_BUDGET"); val != "" {
+		budget := &config.Budgets.Default
+		fields := map[string]*int{
+			"max_pages": &budget.MaxPages, "max_findings": &budget.MaxFindings, "max_concurrency": &budget.MaxConcurrency,
+		}
+		for _, pair := range strings.Split(val, ",") {
+			name, value, _ := strings.Cut(pair, "=")
+			field, known := fields[strings.ToLower(strings.TrimSpace(name))]
+			if limit, err := strconv.Atoi(strings.TrimSpace(value)); known && err == nil && limit >= 0 {
+				*field = limit
+			}
+		}
+	}
+
+	if val := os.Getenv("WORKER_POOL_SIZE"); val != "" {
+		if size, err := strconv.Atoi(val); err == nil && size > 0 {
+			config.Server.WorkerPoolSize = size
+		}
+	}
+
+	// Output size
+	if val := os.Getenv("OUTPUT_MAX_FIELD_CHARS"); val != "" {
+		if n, err := strconv.Atoi(val); err == nil && n >= 0 {
+			config.Output.MaxFieldChars = n
+		}
+	}
+	if val := os.Getenv("OUTPUT_DETAIL_LEVEL"); val != "" {
+		switch level := strings.ToLower(val); level {
+		case "summary", "normal", "full":
+			config.Output.DetailLevel = level
+		}
+	}
+	if val := os.Getenv("OUTPUT_MAX_DESCRIPTION_CHARS"); val != "" {
+		if n, err := strconv.Atoi(val); err == nil && n >= 0 {
+			config.Output.MaxDescriptionChars = n
+		}
+	}
+	if val := os.Getenv("OUTPUT_FORMAT"); val != "" {
+		switch format := strings.ToLower(val); format {
+		case "text", "markdown", "json":
+			config.Output.Format = format
+		}
+	}
+	if val := os.Getenv("OUTPUT_HTML_CONTENT"); val != "" {
+		switch mode := strings.ToLower(val); mode {
+		case "markdown", "text", "raw":
+			config.Output.HTMLContent = mode
+		}
+	}
+	if val := os.Getenv("OUTPUT_LANGUAGE"); val != "" {
+		switch language := strings.ToLower(val); language {
+		case "en", "pt", "es":
+			config.Output.Language = language
+		}
+	}
+	if val := os.Getenv("OUTPUT_LIST_LIMIT"); val != "" {
+		if n, err := strconv.Atoi(val); err == nil && n > 0 {
+			config.Output.ListLimit = n
+		}
+	}
+	if val := os.Getenv("OUTPUT_MAX_LIST_LIMIT"); val != "" {
+		if n, err := strconv.Atoi(val); err == nil && n > 0 {
+			config.Output.MaxListLimit = n
+		}
+	}
+	if val := os.Getenv("OUTPUT_EXPORT_CHUNK_SIZE"); val != "" {
+		if n, err := strconv.Atoi(val); err == nil && n > 0 && n <= 5000 {
+			config.Output.ExportChunkSize = n
+		}
+	}
+	if val := os.Getenv("OUTPUT_EXPORT_MEMORY_MB"); val != "" {
+		if n, err := strconv.Atoi(val); err == nil && n > 0 {
+			config.Output.ExportMemoryMB = n
+		}
+	}
+	if val := os.Getenv("OUTPUT_EXPORT_SPILL_DIR"); val != "" {
+		config.Output.ExportSpillDir = val
+	}
+	if val := os.Getenv("OUTPUT_SANITIZE_CONTENT"); val != "" {
+		config.Output.SanitizeContent = val == "true" || val == "1"
+	}
+	if val := os.Getenv("OUTPUT_MAX_RESULT_KB"); val != "" {
+		if n, err := strconv.Atoi(val); err == nil && n >= 0 {
+			config.Output.MaxResultKB = n
+		}
+	}
+	// Organization severity scale, e.g. OUTPUT_SEVERITY_LABELS="Critical=P1,High=P2"
+	if val := os.Getenv("OUTPUT_SEVERITY_LABELS"); val != "" {
+		config.Output.SeverityLabels = map[string]string{}
+		for _, pair := range strings.Split(val, ",") {
+			severity, label, _ := strings.Cut(pair, "=")
+			if severity, label = strings.TrimSpace(severity), strings.TrimSpace(label); severity != "" && label != "" {
+				config.Output.SeverityLabels[severity] = label
+			}
+		}
+	}
+
+	// Vetted findings queries exposed to agents by name
+	if val := os.Getenv("SAVED_QUERIES_FILE"); val != "" {
+		config.Queries.FilePath = val
+	}
+
+	// Tracing export (standard OTEL_EXPORTER_OTLP_* variables are also honored)
+	if val := os.Getenv("OTEL_TRACING_ENABLED"); val != "" {
+		config.Tracing.Enabled = val == "true" || val == "1"
+	}
+	if val := os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"); val != "" {
+		config.Tracing.Endpoint = val
+	}
 
 	// Server identity (name, version, instructions) should NOT be overrideable
 	// These are part of the library's identity and should remain consistent
-
-	return config
 }
@@ -2,6 +2,8 @@ package config
 
 import (
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -59,6 +61,7 @@ func TestIsDebugMode(t *testing.T) {
 		expected bool
 	}{
 		{"debug", true},
+		{"trace", true}, // trace implies debug
 		{"info", false},
 		{"warn", false},
 		{"error", false},
@@ -76,7 +79,407 @@ func TestIsDebugMode(t *testing.T) {
 	}
 }
 
+func TestTraceModeEnablesTrafficDump(t *testing.T) {
+	t.Setenv("LOG_LEVEL", "trace")
+	t.Setenv("LOG_DUMP_FILE", "/tmp/dojo-dump.log")
+
+	cfg := Load()
+	if !cfg.Logging.IsTraceMode() {
+		t.Error("expected trace mode")
+	}
+	if !cfg.DefectDojo.DumpTraffic {
+		t.Error("expected traffic dump to be enabled in trace mode")
+	}
+	if cfg.DefectDojo.DumpFile != "/tmp/dojo-dump.log" {
+		t.Errorf("expected dump file to be propagated, got %q", cfg.DefectDojo.DumpFile)
+	}
+}
+
 // TestLoadWithEnvironment tests the configuration loading with environment variables
+func TestOutputMaxFieldChars(t *testing.T) {
+	if got := DefaultConfig().Output.MaxFieldChars; got != 2000 {
+		t.Errorf("Expected default MaxFieldChars 2000, got %d", got)
+	}
+
+	t.Setenv("OUTPUT_MAX_FIELD_CHARS", "0")
+	if got := Load().Output.MaxFieldChars; got != 0 {
+		t.Errorf("Expected MaxFieldChars 0 from environment, got %d", got)
+	}
+
+	t.Setenv("OUTPUT_MAX_FIELD_CHARS", "-5")
+	if got := Load().Output.MaxFieldChars; got != 2000 {
+		t.Errorf("Expected invalid value to keep default, got %d", got)
+	}
+}
+
+func TestOutputListDefaults(t *testing.T) {
+	output := DefaultConfig().Output
+	if output.DetailLevel != "normal" || output.MaxDescriptionChars != 300 || output.ListLimit != 10 || output.MaxListLimit != 100 || output.Format != "text" || output.Language != "en" || output.MaxResultKB != 100 {
+		t.Errorf("unexpected output defaults: %+v", output)
+	}
+
+	t.Setenv("OUTPUT_DETAIL_LEVEL", "Summary")
+	t.Setenv("OUTPUT_MAX_DESCRIPTION_CHARS", "0")
+	t.Setenv("OUTPUT_LIST_LIMIT", "25")
+	t.Setenv("OUTPUT_MAX_LIST_LIMIT", "500")
+	t.Setenv("OUTPUT_FORMAT", "Markdown")
+	t.Setenv("OUTPUT_LANGUAGE", "PT")
+	t.Setenv("OUTPUT_EXPORT_CHUNK_SIZE", "250")
+	t.Setenv("OUTPUT_EXPORT_MEMORY_MB", "64")
+	t.Setenv("OUTPUT_EXPORT_SPILL_DIR", "/var/tmp")
+	t.Setenv("OUTPUT_MAX_RESULT_KB", "0")
+	t.Setenv("OUTPUT_SANITIZE_CONTENT", "true")
+	t.Setenv("OUTPUT_HTML_CONTENT", "Raw")
+	output = Load().Output
+	if output.MaxResultKB != 0 || !output.SanitizeContent || output.HTMLContent != "raw" {
+		t.Errorf("expected OUTPUT_MAX_RESULT_KB=0 to disable summarizing and sanitizing on, got %+v", output)
+	}
+	if output.DetailLevel != "summary" || output.MaxDescriptionChars != 0 || output.ListLimit != 25 || output.MaxListLimit != 500 || output.Format != "markdown" || output.Language != "pt" || output.ExportChunkSize != 250 || output.ExportMemoryMB != 64 || output.ExportSpillDir != "/var/tmp" {
+		t.Errorf("environment overrides not applied: %+v", output)
+	}
+
+	t.Setenv("OUTPUT_DETAIL_LEVEL", "verbose")
+	t.Setenv("OUTPUT_LIST_LIMIT", "0")
+	t.Setenv("OUTPUT_MAX_LIST_LIMIT", "-5")
+	t.Setenv("OUTPUT_LANGUAGE", "fr")
+	t.Setenv("OUTPUT_EXPORT_CHUNK_SIZE", "10000")
+	output = Load().Output
+	if output.DetailLevel != "normal" || output.ListLimit != 10 || output.MaxListLimit != 100 || output.Language != "en" || output.ExportChunkSize != 1000 {
+		t.Errorf("expected invalid values to keep defaults, got %+v", output)
+	}
+}
+
+func TestOutputSeverityLabels(t *testing.T) {
+	if labels := Load().Output.SeverityLabels; labels != nil {
+		t.Errorf("Expected no severity labels by default, got %v", labels)
+	}
+
+	t.Setenv("OUTPUT_SEVERITY_LABELS", "Critical=P1, High = P2,Medium,=P9")
+	labels := Load().Output.SeverityLabels
+	if len(labels) != 2 || labels["Critical"] != "P1" || labels["High"] != "P2" {
+		t.Errorf("SeverityLabels = %v (incomplete entries ignored)", labels)
+	}
+}
+
+func TestReferenceCacheTTL(t *testing.T) {
+	if got := DefaultConfig().Server.ReferenceCacheTTL; got != 10*time.Minute {
+		t.Errorf("Expected default ReferenceCacheTTL 10m, got %v", got)
+	}
+
+	t.Setenv("REFERENCE_CACHE_TTL", "1h")
+	if got := Load().Server.ReferenceCacheTTL; got != time.Hour {
+		t.Errorf("Expected ReferenceCacheTTL 1h from environment, got %v", got)
+	}
+
+	t.Setenv("REFERENCE_CACHE_TTL", "0")
+	if got := Load().Server.ReferenceCacheTTL; got >= 0 {
+		t.Errorf("Expected 0 to disable the cache with a negative TTL, got %v", got)
+	}
+}
+
+func TestIdempotencyWindow(t *testing.T) {
+	if got := DefaultConfig().Server.IdempotencyWindow; got != 2*time.Minute {
+		t.Errorf("Expected default IdempotencyWindow 2m, got %v", got)
+	}
+
+	t.Setenv("IDEMPOTENCY_WINDOW", "30s")
+	if got := Load().Server.IdempotencyWindow; got != 30*time.Second {
+		t.Errorf("Expected IdempotencyWindow 30s from environment, got %v", got)
+	}
+
+	t.Setenv("IDEMPOTENCY_WINDOW", "0")
+	if got := Load().Server.IdempotencyWindow; got >= 0 {
+		t.Errorf("Expected 0 to disable duplicate detection with a negative window, got %v", got)
+	}
+}
+
+func TestWorkerPoolSize(t *testing.T) {
+	if got := DefaultConfig().Server.WorkerPoolSize; got != 16 {
+		t.Errorf("Expected default WorkerPoolSize 16, got %d", got)
+	}
+
+	t.Setenv("WORKER_POOL_SIZE", "4")
+	if got := Load().Server.WorkerPoolSize; got != 4 {
+		t.Errorf("Expected WorkerPoolSize 4 from environment, got %d", got)
+	}
+
+	t.Setenv("WORKER_POOL_SIZE", "0")
+	if got := Load().Server.WorkerPoolSize; got != 16 {
+		t.Errorf("Expected an invalid size to keep the default, got %d", got)
+	}
+}
+
+func TestDebugListen(t *testing.T) {
+	if got := DefaultConfig().Server.DebugListen; got != "" {
+		t.Errorf("Expected the debug listener off by default, got %q", got)
+	}
+
+	t.Setenv("DEBUG_LISTEN", "localhost:6060")
+	cfg := Load()
+	if got := cfg.Server.DebugListen; got != "localhost:6060" {
+		t.Errorf("Expected DebugListen from environment, got %q", got)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected a valid configuration, got %v", err)
+	}
+
+	cfg.Server.DebugListen = "6060"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected an address without a port to be rejected")
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	cfg := DefaultConfig()
+	if cfg.Server.RateLimit != 0 || cfg.Server.RateLimitBurst != 10 {
+		t.Errorf("Expected no rate limit and a burst of 10 by default, got %d and %d", cfg.Server.RateLimit, cfg.Server.RateLimitBurst)
+	}
+
+	t.Setenv("RATE_LIMIT", "120")
+	t.Setenv("RATE_LIMIT_BURST", "20")
+	cfg = Load()
+	if cfg.Server.RateLimit != 120 || cfg.Server.RateLimitBurst != 20 {
+		t.Errorf("Expected a limit of 120 with a burst of 20 from environment, got %d and %d", cfg.Server.RateLimit, cfg.Server.RateLimitBurst)
+	}
+
+	t.Setenv("RATE_LIMIT_BURST", "0")
+	if got := Load().Server.RateLimitBurst; got != 10 {
+		t.Errorf("Expected an invalid burst to keep the default, got %d", got)
+	}
+}
+
+func TestBudgets(t *testing.T) {
+	if got := DefaultConfig().Budgets; got.Default != (ToolBudget{}) || len(got.Tools) != 0 {
+		t.Errorf("Expected no budgets by default, got %+v", got)
+	}
+
+	t.Setenv("TOOL_BUDGET", "max_pages=50, max_findings=5000,max_concurrency=-1,unknown=3")
+	if got := Load().Budgets.Default; got != (ToolBudget{MaxPages: 50, MaxFindings: 5000}) {
+		t.Errorf("Expected the default budget from environment, ignoring invalid entries, got %+v", got)
+	}
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := "budgets:\n  tools:\n    summarize_security_posture:\n      max_pages: 200\n      max_concurrency: 2\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+	if got := cfg.Budgets.Tools["summarize_security_posture"]; got != (ToolBudget{MaxPages: 200, MaxConcurrency: 2}) {
+		t.Errorf("Expected the tool budget from the file, got %+v", got)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+
+	cfg.Budgets.Tools["list_findings"] = ToolBudget{MaxFindings: -1}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "list_findings") {
+		t.Errorf("Expected a negative limit to be rejected, got %v", err)
+	}
+}
+
+func TestErrorDetail(t *testing.T) {
+	if got := DefaultConfig().DefectDojo.ErrorDetail; got != "full" {
+		t.Errorf("Expected default ErrorDetail full, got %q", got)
+	}
+
+	t.Setenv("ERROR_DETAIL", "Sanitized")
+	if got := Load().DefectDojo.ErrorDetail; got != "sanitized" {
+		t.Errorf("Expected ErrorDetail sanitized from environment, got %q", got)
+	}
+
+	t.Setenv("ERROR_DETAIL", "verbose")
+	if got := Load().DefectDojo.ErrorDetail; got != "full" {
+		t.Errorf("Expected an unknown value to keep the default, got %q", got)
+	}
+
+	cfg := DefaultConfig()
+	cfg.DefectDojo.ErrorDetail = "verbose"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "error_detail") {
+		t.Errorf("Expected an unknown error_detail to be rejected, got %v", err)
+	}
+}
+
+func TestToolErrors(t *testing.T) {
+	if got := DefaultConfig().Server.ToolErrors; got != "strict" {
+		t.Errorf("Expected default ToolErrors strict, got %q", got)
+	}
+
+	t.Setenv("TOOL_ERRORS", "LENIENT")
+	if got := Load().Server.ToolErrors; got != "lenient" {
+		t.Errorf("Expected ToolErrors lenient from environment, got %q", got)
+	}
+
+	cfg := DefaultConfig()
+	cfg.Server.ToolErrors = "quiet"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "tool_errors") {
+		t.Errorf("Expected an unknown tool_errors to be rejected, got %v", err)
+	}
+}
+
+func TestOfflineMode(t *testing.T) {
+	if got := DefaultConfig().DefectDojo.Mode; got != "live" {
+		t.Errorf("Expected default mode live, got %q", got)
+	}
+
+	t.Setenv("DEFECTDOJO_MODE", "Offline")
+	t.Setenv("DEFECTDOJO_FIXTURES_DIR", "/srv/fixtures")
+	cfg := Load()
+	if cfg.DefectDojo.Mode != "offline" || cfg.DefectDojo.FixturesDir != "/srv/fixtures" {
+		t.Errorf("Expected offline mode with fixtures dir, got %q and %q", cfg.DefectDojo.Mode, cfg.DefectDojo.FixturesDir)
+	}
+}
+
+func TestCassetteDir(t *testing.T) {
+	if got := DefaultConfig().DefectDojo.CassetteDir; got != "cassettes" {
+		t.Errorf("Expected default cassette dir, got %q", got)
+	}
+
+	t.Setenv("DEFECTDOJO_MODE", "replay")
+	t.Setenv("DEFECTDOJO_CASSETTE_DIR", "testdata/session1")
+	cfg := Load()
+	if cfg.DefectDojo.Mode != "replay" || cfg.DefectDojo.CassetteDir != "testdata/session1" {
+		t.Errorf("Expected replay mode with cassette dir, got %q and %q", cfg.DefectDojo.Mode, cfg.DefectDojo.CassetteDir)
+	}
+}
+
+func TestSavedQueriesFile(t *testing.T) {
+	if got := DefaultConfig().Queries.FilePath; got != "" {
+		t.Errorf("Expected no saved queries file by default, got %q", got)
+	}
+
+	t.Setenv("SAVED_QUERIES_FILE", "/etc/mcp/queries.json")
+	if got := Load().Queries.FilePath; got != "/etc/mcp/queries.json" {
+		t.Errorf("Expected saved queries file from environment, got %q", got)
+	}
+}
+
+func TestWritePolicyFile(t *testing.T) {
+	if got := DefaultConfig().Policy.FilePath; got != "" {
+		t.Errorf("Expected no write policy by default, got %q", got)
+	}
+
+	t.Setenv("WRITE_POLICY_FILE", "/etc/mcp/policy.json")
+	if got := Load().Policy.FilePath; got != "/etc/mcp/policy.json" {
+		t.Errorf("Expected write policy file from environment, got %q", got)
+	}
+}
+
+func TestApprovalConfig(t *testing.T) {
+	if approval := DefaultConfig().Approval; approval.Required || approval.Port != 0 || approval.Host != "127.0.0.1" {
+		t.Errorf("Expected approval mode off by default, got %+v", approval)
+	}
+
+	t.Setenv("REQUIRE_APPROVAL", "true")
+	t.Setenv("APPROVAL_PORT", "8090")
+	t.Setenv("APPROVAL_TOKEN", "reviewers")
+	if approval := Load().Approval; !approval.Required || approval.Port != 8090 || approval.Token != "reviewers" {
+		t.Errorf("Expected approval settings from environment, got %+v", approval)
+	}
+
+	cfg := DefaultConfig()
+	cfg.Approval.Port = 8090
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected loopback approval endpoints without a token to be valid, got %v", err)
+	}
+	cfg.Approval.Host = "0.0.0.0"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "need an approval token") {
+		t.Errorf("Expected exposed approval endpoints without a token to be refused, got %v", err)
+	}
+	cfg.Approval.Token = "reviewers"
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected exposed approval endpoints with a token to be valid, got %v", err)
+	}
+}
+
+func TestWebhookConfig(t *testing.T) {
+	if webhook := DefaultConfig().Webhook; webhook.Port != 0 || webhook.BufferSize != 100 {
+		t.Errorf("Unexpected webhook defaults: %+v", webhook)
+	}
+
+	t.Setenv("WEBHOOK_PORT", "8091")
+	t.Setenv("WEBHOOK_SECRET", "s3cret")
+	t.Setenv("WEBHOOK_BUFFER_SIZE", "500")
+	if webhook := Load().Webhook; webhook.Port != 8091 || webhook.Secret != "s3cret" || webhook.BufferSize != 500 {
+		t.Errorf("Expected webhook settings from environment, got %+v", webhook)
+	}
+
+	t.Setenv("WEBHOOK_BUFFER_SIZE", "0")
+	if got := Load().Webhook.BufferSize; got != 100 {
+		t.Errorf("Expected invalid buffer size to keep the default, got %d", got)
+	}
+}
+
+func TestPollingConfig(t *testing.T) {
+	if polling := DefaultConfig().Polling; polling.Interval != 0 || polling.Query != "" {
+		t.Errorf("Expected polling off by default, got %+v", polling)
+	}
+
+	t.Setenv("POLL_INTERVAL", "15m")
+	t.Setenv("POLL_QUERY", "crown-jewels-crit")
+	if polling := Load().Polling; polling.Interval != 15*time.Minute || polling.Query != "crown-jewels-crit" {
+		t.Errorf("Expected polling settings from environment, got %+v", polling)
+	}
+
+	t.Setenv("POLL_INTERVAL", "-1m")
+	if got := Load().Polling.Interval; got != 0 {
+		t.Errorf("Expected negative interval to be ignored, got %v", got)
+	}
+}
+
+func TestEnrichmentConfig(t *testing.T) {
+	if enrichment := Load().Enrichment; enrichment.Mode != "off" || enrichment.CacheTTL != 24*time.Hour {
+		t.Errorf("Expected enrichment off by default, got %+v", enrichment)
+	}
+
+	t.Setenv("CVE_ENRICHMENT", "Offline")
+	t.Setenv("CVE_ENRICHMENT_DATA_DIR", "/var/lib/cve-data")
+	t.Setenv("CVE_ENRICHMENT_CACHE_TTL", "6h")
+	enrichment := Load().Enrichment
+	if enrichment.Mode != "offline" || enrichment.DataDir != "/var/lib/cve-data" || enrichment.CacheTTL != 6*time.Hour {
+		t.Errorf("Expected enrichment settings from environment, got %+v", enrichment)
+	}
+}
+
+func TestNormalizeBaseURL(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"https://defectdojo.company.com", "https://defectdojo.company.com"},
+		{"https://defectdojo.company.com/", "https://defectdojo.company.com"},
+		{"  https://defectdojo.company.com//  ", "https://defectdojo.company.com"},
+		{"https://host/defectdojo/", "https://host/defectdojo"},
+		{"defectdojo.company.com", "https://defectdojo.company.com"},
+		{"localhost:8080", "https://localhost:8080"},
+		{"http://localhost:8080", "http://localhost:8080"},
+		{"https://host/defectdojo/api/v2/", "https://host/defectdojo"},
+		{"https://host/api/v2", "https://host"},
+		{"https://host/apis/v2", "https://host/apis/v2"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeBaseURL(tt.input); got != tt.want {
+			t.Errorf("NormalizeBaseURL(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestLoadNormalizesBaseURL(t *testing.T) {
+	t.Setenv("DEFECTDOJO_URL", "dojo.example.com/sub/")
+	t.Setenv("DEFECTDOJO_UI_URL", "https://dojo.example.com/")
+	cfg := Load()
+	if got := cfg.DefectDojo.BaseURL; got != "https://dojo.example.com/sub" {
+		t.Errorf("Expected normalized BaseURL, got %q", got)
+	}
+	if got := cfg.DefectDojo.UIBaseURL; got != "https://dojo.example.com" {
+		t.Errorf("Expected normalized UIBaseURL, got %q", got)
+	}
+}
+
 func TestLoadWithEnvironment(t *testing.T) {
 	// Save original environment
 	originalEnv := make(map[string]string)
@@ -137,3 +540,217 @@ func BenchmarkConfigDefault(b *testing.B) {
 		_ = DefaultConfig()
 	}
 }
+
+func TestPriorityConfig(t *testing.T) {
+	if weights := Load().Priority.Weights; weights.Severity != 30 || weights.EPSS != 20 || weights.Criticality != 10 {
+		t.Errorf("Expected default priority weights, got %+v", weights)
+	}
+
+	t.Setenv("PRIORITY_WEIGHTS", "severity=50, EPSS=25,age=-1,bogus=3,sla")
+	t.Setenv("PRIORITY_CRITICAL_TAGS", "production, pci,")
+	priority := Load().Priority
+	want := PriorityWeights{Severity: 50, CVSS: 20, EPSS: 25, Age: 10, SLA: 10, Criticality: 10}
+	if priority.Weights != want {
+		t.Errorf("Weights = %+v, want %+v (invalid entries ignored)", priority.Weights, want)
+	}
+	if len(priority.CriticalTags) != 2 || priority.CriticalTags[0] != "production" || priority.CriticalTags[1] != "pci" {
+		t.Errorf("CriticalTags = %q", priority.CriticalTags)
+	}
+}
+
+func TestIssueTrackerConfig(t *testing.T) {
+	if issues := Load().Issues; issues.Provider != "" {
+		t.Errorf("Expected the issue tracker disabled by default, got %+v", issues)
+	}
+
+	t.Setenv("ISSUE_TRACKER", "GitLab")
+	t.Setenv("ISSUE_TRACKER_TOKEN", "glpat-secret")
+	t.Setenv("ISSUE_TRACKER_REPOSITORY", "security/payments-api")
+	t.Setenv("ISSUE_TRACKER_URL", "https://gitlab.example.com/api/v4")
+	t.Setenv("ISSUE_TRACKER_LABELS", "security, defectdojo,")
+	issues := Load().Issues
+	if issues.Provider != "gitlab" || issues.Token != "glpat-secret" || issues.Repository != "security/payments-api" || issues.APIURL != "https://gitlab.example.com/api/v4" {
+		t.Errorf("Unexpected issue tracker config %+v", issues)
+	}
+	if len(issues.Labels) != 2 || issues.Labels[0] != "security" || issues.Labels[1] != "defectdojo" {
+		t.Errorf("Labels = %q", issues.Labels)
+	}
+}
+
+func TestNotificationConfig(t *testing.T) {
+	t.Setenv("NOTIFY_WEBHOOKS_FILE", "/etc/mcp/webhooks.json")
+	t.Setenv("NOTIFY_WEBHOOK_URL", "https://hooks.slack.com/services/T0/B0/x")
+	t.Setenv("NOTIFY_WEBHOOK_FORMAT", "Teams")
+	notify := Load().Notify
+	if notify.FilePath != "/etc/mcp/webhooks.json" || notify.WebhookURL != "https://hooks.slack.com/services/T0/B0/x" || notify.WebhookFormat != "teams" {
+		t.Errorf("Unexpected notification config %+v", notify)
+	}
+}
+
+func TestTransportConfig(t *testing.T) {
+	if server := Load().Server; server.Transport != TransportStdio || server.Listen != "localhost:8000" || server.ReadOnly {
+		t.Errorf("Unexpected default server config %+v", server)
+	}
+	t.Setenv("MCP_TRANSPORT", "HTTP")
+	t.Setenv("MCP_LISTEN", ":8081")
+	t.Setenv("READ_ONLY", "true")
+	cfg := Load()
+	if cfg.Server.Transport != TransportHTTP || cfg.Server.Listen != ":8081" || !cfg.Server.ReadOnly {
+		t.Errorf("Unexpected server config %+v", cfg.Server)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name      string
+		transport string
+		listen    string
+		wantErr   bool
+	}{
+		{"stdio ignores listen", TransportStdio, "", false},
+		{"http", TransportHTTP, ":8081", false},
+		{"sse", TransportSSE, "0.0.0.0:9000", false},
+		{"missing port", TransportHTTP, "localhost", true},
+		{"unknown transport", "websocket", ":8081", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.Server.Transport = tt.transport
+			cfg.Server.Listen = tt.listen
+			if err := cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateCredentials(t *testing.T) {
+	tests := []struct {
+		name        string
+		credentials []CredentialConfig
+		wantErr     string
+	}{
+		{"none", nil, ""},
+		{"valid", []CredentialConfig{{Name: "payments", APIKey: "a", Products: []int{1, 2}}, {Name: "identity", APIKey: "b", ProductTypes: []int{3}}}, ""},
+		{"missing name", []CredentialConfig{{APIKey: "a", Products: []int{1}}}, "credential 1 has no name"},
+		{"duplicate name", []CredentialConfig{{Name: "x", APIKey: "a", Products: []int{1}}, {Name: "x", APIKey: "b", Products: []int{2}}}, `credential "x" is defined twice`},
+		{"missing token", []CredentialConfig{{Name: "x", Products: []int{1}}}, `credential "x" has no api_key`},
+		{"no scope", []CredentialConfig{{Name: "x", APIKey: "a"}}, `credential "x" lists no products or product_types`},
+		{"overlap", []CredentialConfig{{Name: "x", APIKey: "a", ProductTypes: []int{4}}, {Name: "y", APIKey: "b", ProductTypes: []int{4}}}, `product type 4 is covered by both credential "x" and "y"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateCredentials(tt.credentials)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ValidateCredentials() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ValidateCredentials() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateInstances(t *testing.T) {
+	tests := []struct {
+		name      string
+		instances []InstanceConfig
+		wantErr   string
+	}{
+		{"valid", []InstanceConfig{{Name: "staging", BaseURL: "https://dojo-staging.example.com", APIKey: "a"}}, ""},
+		{"missing name", []InstanceConfig{{BaseURL: "https://dojo-staging.example.com", APIKey: "a"}}, "instance 1 has no name"},
+		{"default name", []InstanceConfig{{Name: "default", BaseURL: "https://dojo-staging.example.com", APIKey: "a"}}, `instance name "default" is already used`},
+		{"duplicate name", []InstanceConfig{{Name: "x", BaseURL: "https://a.example.com", APIKey: "a"}, {Name: "x", BaseURL: "https://b.example.com", APIKey: "b"}}, `instance name "x" is already used`},
+		{"missing url", []InstanceConfig{{Name: "x", APIKey: "a"}}, `instance "x" has no valid url`},
+		{"missing token", []InstanceConfig{{Name: "x", BaseURL: "https://a.example.com"}}, `instance "x" has no api_key`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateInstances(tt.instances)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ValidateInstances() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ValidateInstances() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := `defectdojo:
+  url: dojo.example.com/
+  api_key: file-key
+  credentials:
+    - name: payments
+      api_key: payments-key
+      products: [12, 14]
+      product_types: [3]
+  instances:
+    - name: staging
+      url: dojo-staging.example.com/
+      api_key: staging-key
+server:
+  transport: sse
+  listen: ":9000"
+  reference_cache_ttl: 0s
+output:
+  list_limit: 25
+polling:
+  interval: 15m
+priority:
+  critical_tags: [pci, tier-0]
+`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("DEFECTDOJO_API_KEY", "env-key")
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+	if cfg.DefectDojo.BaseURL != "https://dojo.example.com" {
+		t.Errorf("BaseURL = %q", cfg.DefectDojo.BaseURL)
+	}
+	if cfg.DefectDojo.APIKey != "env-key" {
+		t.Errorf("Expected the environment to override the file, got APIKey %q", cfg.DefectDojo.APIKey)
+	}
+	if credentials := cfg.DefectDojo.Credentials; len(credentials) != 1 || credentials[0].Name != "payments" || len(credentials[0].Products) != 2 || credentials[0].ProductTypes[0] != 3 {
+		t.Errorf("Unexpected credentials %+v", credentials)
+	}
+	if instances := cfg.DefectDojo.Instances; len(instances) != 1 || instances[0].Name != "staging" || instances[0].BaseURL != "https://dojo-staging.example.com" || instances[0].APIKey != "staging-key" {
+		t.Errorf("Unexpected instances %+v", instances)
+	}
+	if cfg.Server.Transport != TransportSSE || cfg.Server.Listen != ":9000" || cfg.Server.ReferenceCacheTTL >= 0 {
+		t.Errorf("Unexpected server config %+v", cfg.Server)
+	}
+	if cfg.Output.ListLimit != 25 || cfg.Polling.Interval != 15*time.Minute || len(cfg.Priority.CriticalTags) != 2 {
+		t.Errorf("Unexpected config %+v %+v %+v", cfg.Output, cfg.Polling, cfg.Priority)
+	}
+	if cfg.Server.Name != "mcp-defect-dojo-server" || cfg.Output.MaxFieldChars != DefaultConfig().Output.MaxFieldChars {
+		t.Errorf("Expected defaults for unset keys, got %+v", cfg)
+	}
+
+	if err := os.WriteFile(path, []byte("server:\n  prot: 80\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadFile(path); err == nil {
+		t.Error("Expected an error for an unknown key")
+	}
+	if _, err := LoadFile(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+}
@@ -0,0 +1,232 @@
+// Code generated by apigen; DO NOT EDIT.
+
+package defectdojo
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+// testsRetrieve calls GET /tests/{id}/
+func (c *HTTPClient) testsRetrieve(ctx context.Context, id int) (*types.Test, error) {
+	var out types.Test
+	if err := c.doJSON(ctx, "GET", c.apiURL("/tests/%d/", id), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// testsListParams are the query parameters of testsList
+type testsListParams struct {
+	Engagement *int
+	Limit      *int    // Number of results to return per page.
+	Offset     *int    // The initial index from which to return the results.
+	Ordering   *string // Which field to use when ordering the results.
+}
+
+// values encodes the parameters that are set
+func (p testsListParams) values() url.Values {
+	values := url.Values{}
+	if p.Engagement != nil {
+		values.Set("engagement", strconv.Itoa(*p.Engagement))
+	}
+	if p.Limit != nil {
+		values.Set("limit", strconv.Itoa(*p.Limit))
+	}
+	if p.Offset != nil {
+		values.Set("offset", strconv.Itoa(*p.Offset))
+	}
+	if p.Ordering != nil {
+		values.Set("ordering", *p.Ordering)
+	}
+	return values
+}
+
+// testsList calls GET /tests/
+func (c *HTTPClient) testsList(ctx context.Context, params testsListParams) (*types.TestsResponse, error) {
+	var out types.TestsResponse
+	if err := c.doJSON(ctx, "GET", withQuery(c.apiURL("/tests/"), params.values()), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// testTypesRetrieve calls GET /test_types/{id}/
+func (c *HTTPClient) testTypesRetrieve(ctx context.Context, id int) (*types.TestType, error) {
+	var out types.TestType
+	if err := c.doJSON(ctx, "GET", c.apiURL("/test_types/%d/", id), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// testImportsListParams are the query parameters of testImportsList
+type testImportsListParams struct {
+	Limit  *int    // Number of results to return per page.
+	O      *string // Ordering
+	Offset *int    // The initial index from which to return the results.
+	Test   *int
+}
+
+// values encodes the parameters that are set
+func (p testImportsListParams) values() url.Values {
+	values := url.Values{}
+	if p.Limit != nil {
+		values.Set("limit", strconv.Itoa(*p.Limit))
+	}
+	if p.O != nil {
+		values.Set("o", *p.O)
+	}
+	if p.Offset != nil {
+		values.Set("offset", strconv.Itoa(*p.Offset))
+	}
+	if p.Test != nil {
+		values.Set("test", strconv.Itoa(*p.Test))
+	}
+	return values
+}
+
+// testImportsList calls GET /test_imports/
+func (c *HTTPClient) testImportsList(ctx context.Context, params testImportsListParams) (*types.TestImportsResponse, error) {
+	var out types.TestImportsResponse
+	if err := c.doJSON(ctx, "GET", withQuery(c.apiURL("/test_imports/"), params.values()), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// engagementsRetrieve calls GET /engagements/{id}/
+func (c *HTTPClient) engagementsRetrieve(ctx context.Context, id int) (*types.Engagement, error) {
+	var out types.Engagement
+	if err := c.doJSON(ctx, "GET", c.apiURL("/engagements/%d/", id), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// engagementsCreate calls POST /engagements/
+func (c *HTTPClient) engagementsCreate(ctx context.Context, body types.CreateEngagementRequest) (*types.Engagement, error) {
+	var out types.Engagement
+	if err := c.doJSON(ctx, "POST", c.apiURL("/engagements/"), body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// productsRetrieve calls GET /products/{id}/
+func (c *HTTPClient) productsRetrieve(ctx context.Context, id int) (*types.Product, error) {
+	var out types.Product
+	if err := c.doJSON(ctx, "GET", c.apiURL("/products/%d/", id), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// productsListParams are the query parameters of productsList
+type productsListParams struct {
+	Limit    *int    // Number of results to return per page.
+	Offset   *int    // The initial index from which to return the results.
+	Ordering *string // Which field to use when ordering the results.
+}
+
+// values encodes the parameters that are set
+func (p productsListParams) values() url.Values {
+	values := url.Values{}
+	if p.Limit != nil {
+		values.Set("limit", strconv.Itoa(*p.Limit))
+	}
+	if p.Offset != nil {
+		values.Set("offset", strconv.Itoa(*p.Offset))
+	}
+	if p.Ordering != nil {
+		values.Set("ordering", *p.Ordering)
+	}
+	return values
+}
+
+// productsList calls GET /products/
+func (c *HTTPClient) productsList(ctx context.Context, params productsListParams) (*types.ProductsResponse, error) {
+	var out types.ProductsResponse
+	if err := c.doJSON(ctx, "GET", withQuery(c.apiURL("/products/"), params.values()), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// productsCreate calls POST /products/
+func (c *HTTPClient) productsCreate(ctx context.Context, body types.CreateProductRequest) (*types.Product, error) {
+	var out types.Product
+	if err := c.doJSON(ctx, "POST", c.apiURL("/products/"), body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// productTypesListParams are the query parameters of productTypesList
+type productTypesListParams struct {
+	Limit    *int    // Number of results to return per page.
+	Offset   *int    // The initial index from which to return the results.
+	Ordering *string // Which field to use when ordering the results.
+}
+
+// values encodes the parameters that are set
+func (p productTypesListParams) values() url.Values {
+	values := url.Values{}
+	if p.Limit != nil {
+		values.Set("limit", strconv.Itoa(*p.Limit))
+	}
+	if p.Offset != nil {
+		values.Set("offset", strconv.Itoa(*p.Offset))
+	}
+	if p.Ordering != nil {
+		values.Set("ordering", *p.Ordering)
+	}
+	return values
+}
+
+// productTypesList calls GET /product_types/
+func (c *HTTPClient) productTypesList(ctx context.Context, params productTypesListParams) (*types.ProductTypesResponse, error) {
+	var out types.ProductTypesResponse
+	if err := c.doJSON(ctx, "GET", withQuery(c.apiURL("/product_types/"), params.values()), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// usersListParams are the query parameters of usersList
+type usersListParams struct {
+	Limit    *int    // Number of results to return per page.
+	Offset   *int    // The initial index from which to return the results.
+	Ordering *string // Which field to use when ordering the results.
+	Username *string
+}
+
+// values encodes the parameters that are set
+func (p usersListParams) values() url.Values {
+	values := url.Values{}
+	if p.Limit != nil {
+		values.Set("limit", strconv.Itoa(*p.Limit))
+	}
+	if p.Offset != nil {
+		values.Set("offset", strconv.Itoa(*p.Offset))
+	}
+	if p.Ordering != nil {
+		values.Set("ordering", *p.Ordering)
+	}
+	if p.Username != nil {
+		values.Set("username", *p.Username)
+	}
+	return values
+}
+
+// usersList calls GET /users/
+func (c *HTTPClient) usersList(ctx context.Context, params usersListParams) (*types.UsersResponse, error) {
+	var out types.UsersResponse
+	if err := c.doJSON(ctx, "GET", withQuery(c.apiURL("/users/"), params.values()), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
@@ -0,0 +1,114 @@
+package defectdojo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// apiStrategy holds what differs between DefectDojo API versions: where
+// resources live. Supporting a new API version, such as a future v3, means
+// adding its strategy to apiStrategies.
+type apiStrategy struct {
+	version string
+	// path returns the API path of a resource path, e.g. /findings/1/
+	path func(resource string) string
+}
+
+// apiStrategies are the API versions the client speaks, by name
+var apiStrategies = map[string]apiStrategy{
+	"v2": {version: "v2", path: func(resource string) string { return "/api/v2" + resource }},
+}
+
+// probedAPIVersions are the versions DetectAPIVersions looks for, including
+// versions the client cannot speak yet so operators learn when they appear
+var probedAPIVersions = []string{"v2", "v3"}
+
+// SupportedAPIVersions returns the API versions the client speaks, sorted
+func SupportedAPIVersions() []string {
+	return slices.Sorted(maps.Keys(apiStrategies))
+}
+
+// UnsupportedAPIVersionError is returned for an API version the client does
+// not speak, or, with Served set, one the instance does not serve
+type UnsupportedAPIVersionError struct {
+	Version string
+	Served  []string // Versions the instance serves, when it was asked
+}
+
+func (e *UnsupportedAPIVersionError) Error() string {
+	if e.Served != nil {
+		return fmt.Sprintf("DefectDojo does not serve API %s (it serves %s); set DEFECTDOJO_API_VERSION to one of them", e.Version, joinVersions(e.Served))
+	}
+	return fmt.Sprintf("unsupported DefectDojo API version %q (supported: %s)", e.Version, strings.Join(SupportedAPIVersions(), ", "))
+}
+
+// joinVersions lists versions, or says there are none
+func joinVersions(versions []string) string {
+	if len(versions) == 0 {
+		return "no known version"
+	}
+	return strings.Join(versions, ", ")
+}
+
+// CheckAPIVersion returns an *UnsupportedAPIVersionError unless the client
+// speaks version; an empty version selects the default, v2
+func CheckAPIVersion(version string) error {
+	_, err := lookupAPIStrategy(version)
+	return err
+}
+
+// lookupAPIStrategy returns the strategy of a configured API version
+func lookupAPIStrategy(version string) (apiStrategy, error) {
+	version = strings.ToLower(strings.Trim(version, "/ "))
+	if version == "" {
+		version = "v2"
+	}
+	strategy, ok := apiStrategies[version]
+	if !ok {
+		return apiStrategy{}, &UnsupportedAPIVersionError{Version: version}
+	}
+	return strategy, nil
+}
+
+// DetectAPIVersions asks the instance which API versions it serves by
+// requesting each version's root: any answer but 404, including 401 and 403,
+// means the version is there. It fails only if DefectDojo cannot be reached.
+func (c *HTTPClient) DetectAPIVersions(ctx context.Context) ([]string, error) {
+	var served []string
+	for _, version := range probedAPIVersions {
+		err := c.doJSON(ctx, "GET", c.config.BaseURL+"/api/"+version+"/", nil, nil)
+		var apiErr *APIError
+		switch {
+		case err == nil:
+		case errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound:
+			continue
+		case errors.As(err, &apiErr):
+		default:
+			return nil, err
+		}
+		served = append(served, version)
+	}
+	return served, nil
+}
+
+// NegotiateAPIVersion checks, at startup, that the instance serves the
+// configured API version. It returns the served versions, and an
+// *UnsupportedAPIVersionError if the configured one is not among them.
+func (c *HTTPClient) NegotiateAPIVersion(ctx context.Context) ([]string, error) {
+	if c.apiErr != nil {
+		return nil, c.apiErr
+	}
+	served, err := c.DetectAPIVersions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("detecting DefectDojo API versions: %w", err)
+	}
+	if !slices.Contains(served, c.api.version) {
+		return served, &UnsupportedAPIVersionError{Version: c.api.version, Served: served}
+	}
+	return served, nil
+}
@@ -0,0 +1,94 @@
+package defectdojo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/brduru/mcp-defect-dojo/internal/config"
+)
+
+func TestCheckAPIVersion(t *testing.T) {
+	for version, ok := range map[string]bool{"v2": true, "": true, "/v2/": true, "V2": true, "v1": false, "v99": false, "2": false} {
+		err := CheckAPIVersion(version)
+		if (err == nil) != ok {
+			t.Errorf("CheckAPIVersion(%q) = %v", version, err)
+		}
+		var unsupported *UnsupportedAPIVersionError
+		if err != nil && !errors.As(err, &unsupported) {
+			t.Errorf("CheckAPIVersion(%q) returned %T", version, err)
+		}
+	}
+}
+
+func TestUnsupportedAPIVersionFailsRequests(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(&config.DefectDojoConfig{BaseURL: server.URL, APIVersion: "v99", RequestTimeout: time.Second})
+	_, err := client.GetFindingDetail(context.Background(), 1)
+	var unsupported *UnsupportedAPIVersionError
+	if !errors.As(err, &unsupported) || !strings.Contains(err.Error(), `"v99"`) {
+		t.Fatalf("expected an unsupported version error, got %v", err)
+	}
+	if health := client.CheckHealth(context.Background()); health.Reachable || health.Error == "" {
+		t.Errorf("expected an unhealthy status, got %+v", health)
+	}
+	if requests.Load() != 0 {
+		t.Errorf("expected no request to DefectDojo, got %d", requests.Load())
+	}
+}
+
+func TestNegotiateAPIVersion(t *testing.T) {
+	tests := []struct {
+		name       string
+		serves     []string
+		wantServed []string
+		wantErr    bool
+	}{
+		{name: "v2 served", serves: []string{"v2"}, wantServed: []string{"v2"}},
+		{name: "v2 and v3 served", serves: []string{"v2", "v3"}, wantServed: []string{"v2", "v3"}},
+		{name: "v2 missing", serves: []string{"v3"}, wantServed: []string{"v3"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				version, _, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/api/"), "/")
+				if !slices.Contains(tt.serves, version) {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				// Anonymous requests are rejected, which still proves the version is served
+				w.WriteHeader(http.StatusUnauthorized)
+			}))
+			defer server.Close()
+
+			client := NewHTTPClient(&config.DefectDojoConfig{BaseURL: server.URL, APIVersion: "v2", RequestTimeout: time.Second})
+			served, err := client.NegotiateAPIVersion(context.Background())
+			if !slices.Equal(served, tt.wantServed) {
+				t.Errorf("served = %v, want %v", served, tt.wantServed)
+			}
+			var unsupported *UnsupportedAPIVersionError
+			if tt.wantErr != errors.As(err, &unsupported) {
+				t.Errorf("unexpected error %v", err)
+			}
+			if tt.wantErr && !strings.Contains(err.Error(), "serves v3") {
+				t.Errorf("error does not list the served versions: %v", err)
+			}
+		})
+	}
+
+	client := NewHTTPClient(&config.DefectDojoConfig{BaseURL: "http://127.0.0.1:1", APIVersion: "v2", RequestTimeout: time.Second})
+	if _, err := client.NegotiateAPIVersion(context.Background()); err == nil || errors.As(err, new(*UnsupportedAPIVersionError)) {
+		t.Errorf("expected a connection error, got %v", err)
+	}
+}
@@ -0,0 +1,229 @@
+package defectdojo
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Modes recording DefectDojo traffic to a cassette directory or replaying it
+const (
+	ModeRecord = "record" // Talk to DefectDojo and save every response to the cassette
+	ModeReplay = "replay" // Serve responses from the cassette without network access
+)
+
+// interaction is one recorded DefectDojo exchange, stored as a JSON file.
+// Only what replay needs is kept: request headers (and with them the API
+// token) are never written to disk.
+type interaction struct {
+	Method      string          `json:"method"`
+	URL         string          `json:"url"` // Path and query, without scheme and host
+	RequestBody json.RawMessage `json:"request_body,omitempty"`
+	Status      int             `json:"status"`
+	ContentType string          `json:"content_type,omitempty"`
+	Body        json.RawMessage `json:"body,omitempty"`
+	RawBody     string          `json:"raw_body,omitempty"` // Non-JSON response bodies
+}
+
+// cassetteKey identifies equivalent requests regardless of host, header
+// values or query parameter order, so cassettes recorded against one
+// instance replay against any base URL.
+func cassetteKey(req *http.Request, body []byte) (name, url string) {
+	url = req.URL.EscapedPath()
+	if query := req.URL.Query().Encode(); query != "" {
+		url += "?" + query
+	}
+	sum := sha256.Sum256([]byte(req.Method + " " + url + "\n" + string(body)))
+	return fmt.Sprintf("%s%s_%s", req.Method, unsafePathChars.ReplaceAllString(req.URL.Path, "_"), hex.EncodeToString(sum[:4])), url
+}
+
+var unsafePathChars = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+// readRequestBody returns the request body without consuming it
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.GetBody == nil {
+		return nil, nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	return io.ReadAll(body)
+}
+
+// recordTransport forwards requests and saves each response to the cassette
+// directory. Repeated identical requests are numbered in order so replay can
+// reproduce state changes (e.g. a finding before and after an update).
+type recordTransport struct {
+	next http.RoundTripper
+	dir  string
+
+	mu    sync.Mutex
+	count map[string]int
+}
+
+func newRecordTransport(next http.RoundTripper, dir string) *recordTransport {
+	return &recordTransport{next: next, dir: dir, count: map[string]int{}}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *recordTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := readRequestBody(req)
+	if err != nil {
+		return nil, fmt.Errorf("recording request body: %w", err)
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Buffer the response so it can be saved and still decoded by the caller
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	name, url := cassetteKey(req, reqBody)
+	record := interaction{
+		Method:      req.Method,
+		URL:         url,
+		Status:      resp.StatusCode,
+		ContentType: resp.Header.Get("Content-Type"),
+	}
+	if json.Valid(reqBody) {
+		record.RequestBody = reqBody
+	}
+	if json.Valid(respBody) {
+		record.Body = respBody
+	} else {
+		record.RawBody = string(respBody)
+	}
+
+	t.mu.Lock()
+	n := t.count[name]
+	t.count[name]++
+	t.mu.Unlock()
+
+	if err := t.save(fmt.Sprintf("%s_%d.json", name, n), record); err != nil {
+		return nil, fmt.Errorf("recording %s %s: %w", req.Method, url, err)
+	}
+	return resp, nil
+}
+
+func (t *recordTransport) save(file string, record interaction) error {
+	if err := os.MkdirAll(t.dir, 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(t.dir, file), data, 0o600)
+}
+
+// replayTransport answers requests from a cassette directory without any
+// network access. The n-th identical request receives the n-th recorded
+// response; once recordings run out the last one is repeated. Requests that
+// were never recorded fail with an error naming the missing request.
+type replayTransport struct {
+	dir string
+
+	mu    sync.Mutex
+	count map[string]int
+}
+
+func newReplayTransport(dir string) *replayTransport {
+	return &replayTransport{dir: dir, count: map[string]int{}}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := readRequestBody(req)
+	if err != nil {
+		return nil, fmt.Errorf("replaying request body: %w", err)
+	}
+	name, url := cassetteKey(req, reqBody)
+
+	t.mu.Lock()
+	n := t.count[name]
+	t.count[name]++
+	t.mu.Unlock()
+
+	record, err := t.load(name, n)
+	if err != nil {
+		return nil, fmt.Errorf("replaying %s %s: %w", req.Method, url, err)
+	}
+
+	body := []byte(record.Body)
+	if record.Body == nil {
+		body = []byte(record.RawBody)
+	}
+	header := http.Header{}
+	if record.ContentType != "" {
+		header.Set("Content-Type", record.ContentType)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", record.Status, http.StatusText(record.Status)),
+		StatusCode:    record.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// load reads the n-th recording for name, falling back to the latest one
+func (t *replayTransport) load(name string, n int) (*interaction, error) {
+	for ; n >= 0; n-- {
+		data, err := os.ReadFile(filepath.Join(t.dir, fmt.Sprintf("%s_%d.json", name, n)))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		var record interaction
+		if err := json.Unmarshal(data, &record); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", name, err)
+		}
+		return &record, nil
+	}
+	return nil, fmt.Errorf("no recorded response in %s (record it with DEFECTDOJO_MODE=%s)", t.dir, ModeRecord)
+}
+
+// defaultCassetteDir is used by record and replay modes when no directory is configured
+const defaultCassetteDir = "cassettes"
+
+// cassetteTransport returns the base transport for the configured mode,
+// sending live requests through network
+func cassetteTransport(mode, dir string, network http.RoundTripper) http.RoundTripper {
+	if dir == "" {
+		dir = defaultCassetteDir
+	}
+	switch strings.ToLower(mode) {
+	case ModeRecord:
+		return newRecordTransport(network, dir)
+	case ModeReplay:
+		return newReplayTransport(dir)
+	default:
+		return network
+	}
+}
@@ -0,0 +1,96 @@
+package defectdojo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brduru/mcp-defect-dojo/internal/config"
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+func TestRecordReplay(t *testing.T) {
+	dir := t.TempDir()
+
+	falseP := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/api/v2/findings/":
+			json.NewEncoder(w).Encode(types.FindingsResponse{Count: 1, Results: []types.Finding{{ID: 1, Title: "XSS", Severity: "High"}}})
+		case r.Method == "GET" && r.URL.Path == "/api/v2/findings/1/":
+			json.NewEncoder(w).Encode(types.Finding{ID: 1, Title: "XSS", FalseP: falseP})
+		case r.Method == "PATCH" && r.URL.Path == "/api/v2/findings/1/":
+			falseP = true
+			json.NewEncoder(w).Encode(types.Finding{ID: 1, Title: "XSS", FalseP: true})
+		case r.Method == "POST" && r.URL.Path == "/api/v2/findings/1/notes/":
+			json.NewEncoder(w).Encode(types.Note{ID: 5, Entry: "note"})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"detail":"Not found."}`))
+		}
+	}))
+
+	ctx := context.Background()
+	filter := types.FindingsFilter{Limit: 10, Severity: "High"}
+	fpRequest := types.FalsePositiveRequest{IsFalsePositive: true, Justification: "test data"}
+
+	recorder := NewHTTPClient(&config.DefectDojoConfig{BaseURL: server.URL, APIKey: "secret-token", RequestTimeout: 5 * time.Second, Mode: ModeRecord, CassetteDir: dir})
+	if _, err := recorder.GetFindings(ctx, filter); err != nil {
+		t.Fatalf("recording findings: %v", err)
+	}
+	if before, err := recorder.GetFindingDetail(ctx, 1); err != nil || before.FalseP {
+		t.Fatalf("recording detail before update: %+v, %v", before, err)
+	}
+	if _, err := recorder.MarkFalsePositive(ctx, 1, fpRequest); err != nil {
+		t.Fatalf("recording update: %v", err)
+	}
+	if _, err := recorder.GetFindingDetail(ctx, 1); err != nil {
+		t.Fatalf("recording detail after update: %v", err)
+	}
+	if _, err := recorder.GetProduct(ctx, 42); err == nil {
+		t.Fatal("expected recorded 404")
+	}
+	server.Close()
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	for _, file := range files {
+		data, _ := os.ReadFile(file)
+		if strings.Contains(string(data), "secret-token") {
+			t.Errorf("cassette %s contains the API token", filepath.Base(file))
+		}
+	}
+
+	// Replay against an unreachable host: everything must come from the cassette
+	replayer := NewHTTPClient(&config.DefectDojoConfig{BaseURL: "https://dd.invalid", RequestTimeout: 5 * time.Second, Mode: ModeReplay, CassetteDir: dir})
+	findings, err := replayer.GetFindings(ctx, filter)
+	if err != nil || findings.Count != 1 || findings.Results[0].Title != "XSS" {
+		t.Fatalf("replayed findings = %+v, %v", findings, err)
+	}
+	if before, err := replayer.GetFindingDetail(ctx, 1); err != nil || before.FalseP {
+		t.Errorf("expected first replayed detail before the update, got %+v, %v", before, err)
+	}
+	if _, err := replayer.MarkFalsePositive(ctx, 1, fpRequest); err != nil {
+		t.Errorf("replaying update: %v", err)
+	}
+	for range 2 {
+		if after, err := replayer.GetFindingDetail(ctx, 1); err != nil || !after.FalseP {
+			t.Errorf("expected replayed detail after the update, got %+v, %v", after, err)
+		}
+	}
+
+	var apiErr *APIError
+	if _, err := replayer.GetProduct(ctx, 42); err == nil || !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("expected replayed 404, got %v", err)
+	}
+	if _, err := replayer.GetFindings(ctx, types.FindingsFilter{Limit: 10, Severity: "Low"}); err == nil || !strings.Contains(err.Error(), "no recorded response") {
+		t.Errorf("expected unrecorded request to fail, got %v", err)
+	}
+}
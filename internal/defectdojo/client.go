@@ -3,52 +3,201 @@ package defectdojo
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log"
+	"maps"
+	"mime/multipart"
 	"net/http"
 	"net/url"
+	"os"
+	"slices"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/brduru/mcp-defect-dojo/internal/config"
+	"github.com/brduru/mcp-defect-dojo/internal/requestid"
 	"github.com/brduru/mcp-defect-dojo/pkg/types"
 )
 
+// The low-level calls of the endpoints listed in openapi/operations.json are
+// generated from openapi/schema.json, a hand-trimmed excerpt of DefectDojo's
+// OpenAPI schema; the methods below wrap them. Findings, notes and imports
+// are not generated.
+//go:generate go run ../apigen/cmd/apigen -schema openapi/schema.json -config openapi/operations.json -out api_gen.go
+
 // Client interface for DefectDojo API operations
 type Client interface {
 	GetFindings(ctx context.Context, filter types.FindingsFilter) (*types.FindingsResponse, error)
 	GetFindingDetail(ctx context.Context, findingID int) (*types.Finding, error)
+	GetFindingDetailPrefetch(ctx context.Context, findingID int, prefetch []string) (*types.FindingDetail, error)
 	MarkFalsePositive(ctx context.Context, findingID int, request types.FalsePositiveRequest) (*types.FalsePositiveResponse, error)
+	ChangeSeverity(ctx context.Context, findingID int, request types.SeverityChangeRequest) (*types.SeverityChangeResponse, error)
+	AddFindingNote(ctx context.Context, findingID int, entry string) (*types.Note, error)
+	GetFindingMetadata(ctx context.Context, findingID int) ([]types.FindingMetadata, error)
+	AddFindingMetadata(ctx context.Context, findingID int, metadata types.FindingMetadata) (*types.FindingMetadata, error)
+	ImportScan(ctx context.Context, request types.ImportScanRequest) (*types.ImportScanResponse, error)
+	GetTest(ctx context.Context, testID int) (*types.Test, error)
+	GetTestType(ctx context.Context, testTypeID int) (*types.TestType, error)
+	GetEngagement(ctx context.Context, engagementID int) (*types.Engagement, error)
+	ListEngagements(ctx context.Context, filter types.EngagementsFilter) (*types.EngagementsResponse, error)
+	GetProduct(ctx context.Context, productID int) (*types.Product, error)
+	ListProducts(ctx context.Context, limit, offset int) (*types.ProductsResponse, error)
+	ListProductTypes(ctx context.Context, limit, offset int) (*types.ProductTypesResponse, error)
+	CreateProduct(ctx context.Context, request types.CreateProductRequest) (*types.Product, error)
+	ListUsers(ctx context.Context, username string, limit, offset int) (*types.UsersResponse, error)
+	AssignFinding(ctx context.Context, findingID int, userIDs []int) (*types.Finding, error)
+	CloseFinding(ctx context.Context, findingID int, request types.CloseFindingRequest) (*types.Finding, error)
+	CreateEngagement(ctx context.Context, request types.CreateEngagementRequest) (*types.Engagement, error)
+	ListTests(ctx context.Context, engagementID, limit, offset int) (*types.TestsResponse, error)
+	ListTestImports(ctx context.Context, testID, limit, offset int) (*types.TestImportsResponse, error)
+	ListDevelopmentEnvironments(ctx context.Context, limit, offset int) (*types.DevelopmentEnvironmentsResponse, error)
+	ListRiskAcceptances(ctx context.Context, limit, offset int) (*types.RiskAcceptancesResponse, error)
+	ListEndpointStatuses(ctx context.Context, filter types.EndpointStatusFilter) (*types.EndpointStatusesResponse, error)
+	ListEndpoints(ctx context.Context, productID, limit, offset int) (*types.EndpointsResponse, error)
+	ListTechnologies(ctx context.Context, productID, limit, offset int) (*types.TechnologiesResponse, error)
+	GetSystemSettings(ctx context.Context) (*types.SystemSettings, error)
+	ListSLAConfigurations(ctx context.Context, limit, offset int) (*types.SLAConfigurationsResponse, error)
+	GetAnnouncement(ctx context.Context) (*types.Announcement, error)
 	HealthCheck(ctx context.Context) (bool, string)
+	CheckHealth(ctx context.Context) *types.HealthStatus
+	Version(ctx context.Context) string
+	Supports(ctx context.Context, feature Feature) error
+}
+
+// APIError is returned when DefectDojo answers with a non-success status code.
+// Callers can use errors.As to inspect the status, e.g. for audit records.
+type APIError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API request failed with status %d: %s", e.StatusCode, e.Body)
+}
+
+// ConflictError is returned when a write names an IfUnmodifiedSince time and
+// the finding was modified after it, e.g. by a human triaging it meanwhile.
+type ConflictError struct {
+	FindingID int
+	Modified  time.Time // When the finding was last modified
+	Expected  time.Time // The IfUnmodifiedSince time of the write
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("conflict: finding %d was modified at %s, after %s; re-read it before changing it",
+		e.FindingID, e.Modified.UTC().Format(time.RFC3339), e.Expected.UTC().Format(time.RFC3339))
+}
+
+// errVerifiedFalsePositive is returned when marking a finding as false
+// positive while setting verified=true, which DefectDojo rejects.
+var errVerifiedFalsePositive = errors.New("a false positive finding cannot be verified")
+
+// CheckUnmodified returns a ConflictError if finding was modified after
+// since. DefectDojo reports sub-second modification times while tool output
+// shows whole seconds, so the comparison is to the second. Tools updating a
+// finding they read themselves use it directly.
+func CheckUnmodified(finding *types.Finding, since time.Time) error {
+	if since.IsZero() || !finding.Modified.Truncate(time.Second).After(since) {
+		return nil
+	}
+	return &ConflictError{FindingID: finding.ID, Modified: finding.Modified, Expected: since}
 }
 
 // HTTPClient implements the Client interface using HTTP requests
 type HTTPClient struct {
-	config     *config.DefectDojoConfig
-	httpClient *http.Client
+	config      *config.DefectDojoConfig
+	httpClient  *http.Client
+	dump        *dumpTransport
+	compression *compressionTransport
+	api         apiStrategy // Path building of the configured API version
+	apiErr      error       // Why the configured API version cannot be used, failing every request
+
+	versionMu        sync.Mutex
+	version          string
+	versionCheckedAt time.Time
 }
 
 // NewHTTPClient creates a new DefectDojo HTTP client
 func NewHTTPClient(cfg *config.DefectDojoConfig) *HTTPClient {
+	compression := &compressionTransport{next: http.DefaultTransport}
+	dump := &dumpTransport{next: cassetteTransport(cfg.Mode, cfg.CassetteDir, compression), out: os.Stderr}
+	if cfg.DumpFile != "" {
+		f, err := os.OpenFile(cfg.DumpFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+		if err != nil {
+			log.Printf("traffic dump: cannot open %s, using stderr: %v", cfg.DumpFile, err)
+		} else {
+			dump.out, dump.file = f, f
+		}
+	}
+	dump.enabled.Store(cfg.DumpTraffic)
+
+	// Normalize a copy so callers' configs are left untouched
+	normalized := *cfg
+	normalized.BaseURL = config.NormalizeBaseURL(cfg.BaseURL)
+
+	api, apiErr := lookupAPIStrategy(cfg.APIVersion)
+
 	return &HTTPClient{
-		config: cfg,
+		config: &normalized,
+		api:    api,
+		apiErr: apiErr,
+		// No http.Client timeout: RequestTimeout is applied as a default
+		// context deadline so callers can request longer deadlines per call
 		httpClient: &http.Client{
-			Timeout: cfg.RequestTimeout,
+			Transport: &tracingTransport{next: dump},
 		},
+		dump:        dump,
+		compression: compression,
 	}
 }
 
+// SetTrafficDump enables or disables dumping of DefectDojo requests and responses at runtime
+func (c *HTTPClient) SetTrafficDump(enabled bool) {
+	c.dump.enabled.Store(enabled)
+}
+
+// TrafficDumpEnabled reports whether traffic dumping is currently active
+func (c *HTTPClient) TrafficDumpEnabled() bool {
+	return c.dump.enabled.Load()
+}
+
+// Close closes the traffic dump file, if the client opened one. Later dumps
+// go to stderr.
+func (c *HTTPClient) Close() error {
+	c.dump.mu.Lock()
+	defer c.dump.mu.Unlock()
+	if c.dump.file == nil {
+		return nil
+	}
+	err := c.dump.file.Close()
+	c.dump.out, c.dump.file = os.Stderr, nil
+	return err
+}
+
+// TransferStats reports how many response bytes DefectDojo sent over the
+// network and how many they decompressed to
+func (c *HTTPClient) TransferStats() TransferStats {
+	return c.compression.Stats()
+}
+
 // GetFindings retrieves findings from DefectDojo API with filtering
 func (c *HTTPClient) GetFindings(ctx context.Context, filter types.FindingsFilter) (*types.FindingsResponse, error) {
-	apiURL := fmt.Sprintf("%s%s/findings/", c.config.BaseURL, c.config.GetAPIBasePath())
+	apiURL := c.apiURL("/findings/")
 
 	// Build query parameters
 	params := url.Values{}
 	params.Add("limit", strconv.Itoa(filter.Limit))
 	params.Add("offset", strconv.Itoa(filter.Offset))
 
-	if filter.ActiveOnly {
-		params.Add("active", "true")
+	if active := filter.ActiveFilter(); active != nil {
+		params.Add("active", strconv.FormatBool(*active))
 	}
 	if filter.Severity != "" {
 		params.Add("severity", filter.Severity)
@@ -59,139 +208,769 @@ func (c *HTTPClient) GetFindings(ctx context.Context, filter types.FindingsFilte
 	if filter.Test != nil {
 		params.Add("test", strconv.Itoa(*filter.Test))
 	}
+	if filter.Product != nil {
+		params.Add("test__engagement__product", strconv.Itoa(*filter.Product))
+	}
+	if filter.Engagement != nil {
+		params.Add("test__engagement", strconv.Itoa(*filter.Engagement))
+	}
+	if filter.Environment != nil {
+		params.Add("test__environment", strconv.Itoa(*filter.Environment))
+	}
+	if filter.ComponentName != "" {
+		params.Add("component_name", filter.ComponentName)
+	}
+	if filter.ComponentVersion != "" {
+		params.Add("component_version", filter.ComponentVersion)
+	}
+	if len(filter.Tags) > 0 {
+		params.Add("tags", strings.Join(filter.Tags, ","))
+	}
+	if len(filter.NotTags) > 0 {
+		params.Add("not_tags", strings.Join(filter.NotTags, ","))
+	}
+	for _, reporter := range filter.Reporter {
+		params.Add("reporter", strconv.Itoa(reporter))
+	}
+	for _, reviewer := range filter.Reviewers {
+		params.Add("reviewers", strconv.Itoa(reviewer))
+	}
+	for _, foundBy := range filter.FoundBy {
+		params.Add("found_by", strconv.Itoa(foundBy))
+	}
+	if filter.Ordering != "" {
+		params.Add("ordering", strings.ReplaceAll(filter.Ordering, " ", ""))
+	}
+	if filter.RiskAccepted != nil {
+		params.Add("risk_accepted", strconv.FormatBool(*filter.RiskAccepted))
+	}
+	if filter.IsMitigated != nil {
+		params.Add("is_mitigated", strconv.FormatBool(*filter.IsMitigated))
+	}
+	if filter.Duplicate != nil {
+		params.Add("duplicate", strconv.FormatBool(*filter.Duplicate))
+	}
+	if !filter.DiscoveredAfter.IsZero() {
+		params.Add("discovered_after", filter.DiscoveredAfter.Format(time.DateOnly))
+	}
+	if !filter.DiscoveredBefore.IsZero() {
+		params.Add("discovered_before", filter.DiscoveredBefore.Format(time.DateOnly))
+	}
+	if !filter.MitigatedAfter.IsZero() {
+		params.Add("mitigated_after", filter.MitigatedAfter.Format(time.DateOnly))
+	}
+	if !filter.MitigatedBefore.IsZero() {
+		params.Add("mitigated_before", filter.MitigatedBefore.Format(time.DateOnly))
+	}
+	if len(filter.Prefetch) > 0 {
+		params.Add("prefetch", strings.Join(filter.Prefetch, ","))
+	}
+	if filter.RelatedFields {
+		params.Add("related_fields", "true")
+	}
 
 	fullURL := fmt.Sprintf("%s?%s", apiURL, params.Encode())
 
-	req, err := http.NewRequestWithContext(ctx, "GET", fullURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("creating request: %w", err)
+	var findings types.FindingsResponse
+	if err := c.doJSON(ctx, "GET", fullURL, nil, &findings); err != nil {
+		return nil, err
 	}
 
-	c.setHeaders(req)
+	return &findings, nil
+}
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("HTTP request failed: %w", err)
+// GetFindingDetail retrieves a specific finding by ID
+func (c *HTTPClient) GetFindingDetail(ctx context.Context, findingID int) (*types.Finding, error) {
+	apiURL := c.apiURL("/findings/%d/", findingID)
+
+	var finding types.Finding
+	if err := c.doJSON(ctx, "GET", apiURL, nil, &finding); err != nil {
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+	return &finding, nil
+}
+
+// GetFindingDetailPrefetch retrieves a finding together with the related
+// objects named in prefetch (types.PrefetchTest, ...), in a single request
+func (c *HTTPClient) GetFindingDetailPrefetch(ctx context.Context, findingID int, prefetch []string) (*types.FindingDetail, error) {
+	apiURL := c.apiURL("/findings/%d/", findingID)
+	if len(prefetch) > 0 {
+		apiURL += "?" + url.Values{"prefetch": {strings.Join(prefetch, ",")}}.Encode()
 	}
 
-	var findings types.FindingsResponse
-	if err := json.NewDecoder(resp.Body).Decode(&findings); err != nil {
-		return nil, fmt.Errorf("decoding response: %w", err)
+	var detail types.FindingDetail
+	if err := c.doJSON(ctx, "GET", apiURL, nil, &detail); err != nil {
+		return nil, err
 	}
+	return &detail, nil
+}
 
-	return &findings, nil
+// GetTest retrieves a test by ID
+func (c *HTTPClient) GetTest(ctx context.Context, testID int) (*types.Test, error) {
+	return c.testsRetrieve(ctx, testID)
 }
 
-// GetFindingDetail retrieves a specific finding by ID
-func (c *HTTPClient) GetFindingDetail(ctx context.Context, findingID int) (*types.Finding, error) {
-	apiURL := fmt.Sprintf("%s%s/findings/%d/", c.config.BaseURL, c.config.GetAPIBasePath(), findingID)
+// GetTestType retrieves a test type (scanner) by ID
+func (c *HTTPClient) GetTestType(ctx context.Context, testTypeID int) (*types.TestType, error) {
+	return c.testTypesRetrieve(ctx, testTypeID)
+}
 
-	req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("creating request: %w", err)
+// GetEngagement retrieves an engagement by ID
+func (c *HTTPClient) GetEngagement(ctx context.Context, engagementID int) (*types.Engagement, error) {
+	return c.engagementsRetrieve(ctx, engagementID)
+}
+
+// GetProduct retrieves a product by ID
+func (c *HTTPClient) GetProduct(ctx context.Context, productID int) (*types.Product, error) {
+	return c.productsRetrieve(ctx, productID)
+}
+
+// ListProducts retrieves a page of products, ordered by ID
+func (c *HTTPClient) ListProducts(ctx context.Context, limit, offset int) (*types.ProductsResponse, error) {
+	return c.productsList(ctx, productsListParams{Limit: &limit, Offset: &offset, Ordering: ptr("id")})
+}
+
+// ListProductTypes retrieves a page of product types, ordered by ID
+func (c *HTTPClient) ListProductTypes(ctx context.Context, limit, offset int) (*types.ProductTypesResponse, error) {
+	return c.productTypesList(ctx, productTypesListParams{Limit: &limit, Offset: &offset, Ordering: ptr("id")})
+}
+
+// ListUsers retrieves a page of users, only the one with this exact login
+// name when username is set
+func (c *HTTPClient) ListUsers(ctx context.Context, username string, limit, offset int) (*types.UsersResponse, error) {
+	params := usersListParams{Limit: &limit, Offset: &offset, Ordering: ptr("id")}
+	if username != "" {
+		params.Username = &username
 	}
+	return c.usersList(ctx, params)
+}
 
-	c.setHeaders(req)
+// CreateProduct creates a product. DefectDojo rejects a name that is already taken.
+func (c *HTTPClient) CreateProduct(ctx context.Context, request types.CreateProductRequest) (*types.Product, error) {
+	return c.productsCreate(ctx, request)
+}
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("HTTP request failed: %w", err)
+// CreateEngagement creates an engagement in a product
+func (c *HTTPClient) CreateEngagement(ctx context.Context, request types.CreateEngagementRequest) (*types.Engagement, error) {
+	return c.engagementsCreate(ctx, request)
+}
+
+// ListTests retrieves a page of an engagement's tests, ordered by ID
+func (c *HTTPClient) ListTests(ctx context.Context, engagementID, limit, offset int) (*types.TestsResponse, error) {
+	return c.testsList(ctx, testsListParams{Engagement: &engagementID, Limit: &limit, Offset: &offset, Ordering: ptr("id")})
+}
+
+// ListTestImports retrieves a page of a test's import history, newest first.
+// DefectDojo only keeps it when import history tracking is enabled, the default.
+func (c *HTTPClient) ListTestImports(ctx context.Context, testID, limit, offset int) (*types.TestImportsResponse, error) {
+	return c.testImportsList(ctx, testImportsListParams{Test: &testID, Limit: &limit, Offset: &offset, O: ptr("-id")})
+}
+
+// ListEngagements retrieves a page of engagements, ordered by target start date
+func (c *HTTPClient) ListEngagements(ctx context.Context, filter types.EngagementsFilter) (*types.EngagementsResponse, error) {
+	params := url.Values{}
+	params.Add("limit", strconv.Itoa(filter.Limit))
+	params.Add("offset", strconv.Itoa(filter.Offset))
+	params.Add("o", "target_start")
+	if filter.Product != nil {
+		params.Add("product", strconv.Itoa(*filter.Product))
+	}
+	if filter.Active != nil {
+		params.Add("active", strconv.FormatBool(*filter.Active))
+	}
+	if len(filter.Prefetch) > 0 {
+		params.Add("prefetch", strings.Join(filter.Prefetch, ","))
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+	var engagements types.EngagementsResponse
+	if err := c.doJSON(ctx, "GET", fmt.Sprintf("%s?%s", c.apiURL("/engagements/"), params.Encode()), nil, &engagements); err != nil {
+		return nil, err
 	}
+	return &engagements, nil
+}
 
-	var finding types.Finding
-	if err := json.NewDecoder(resp.Body).Decode(&finding); err != nil {
-		return nil, fmt.Errorf("decoding response: %w", err)
+// ListRiskAcceptances retrieves a page of risk acceptances. DefectDojo cannot
+// filter or order them by expiration date, so callers page through all of them.
+func (c *HTTPClient) ListRiskAcceptances(ctx context.Context, limit, offset int) (*types.RiskAcceptancesResponse, error) {
+	params := url.Values{}
+	params.Add("limit", strconv.Itoa(limit))
+	params.Add("offset", strconv.Itoa(offset))
+
+	var acceptances types.RiskAcceptancesResponse
+	if err := c.doJSON(ctx, "GET", fmt.Sprintf("%s?%s", c.apiURL("/risk_acceptance/"), params.Encode()), nil, &acceptances); err != nil {
+		return nil, err
 	}
+	return &acceptances, nil
+}
 
-	return &finding, nil
+// ListEndpointStatuses retrieves a page of endpoint statuses, the links
+// between findings and the endpoints they affect, in ID order.
+func (c *HTTPClient) ListEndpointStatuses(ctx context.Context, filter types.EndpointStatusFilter) (*types.EndpointStatusesResponse, error) {
+	params := url.Values{}
+	params.Add("limit", strconv.Itoa(filter.Limit))
+	params.Add("offset", strconv.Itoa(filter.Offset))
+	params.Add("o", "id")
+	for name, value := range map[string]*bool{
+		"mitigated":      filter.Mitigated,
+		"false_positive": filter.FalsePositive,
+		"out_of_scope":   filter.OutOfScope,
+		"risk_accepted":  filter.RiskAccepted,
+	} {
+		if value != nil {
+			params.Add(name, strconv.FormatBool(*value))
+		}
+	}
+	if len(filter.Prefetch) > 0 {
+		params.Add("prefetch", strings.Join(filter.Prefetch, ","))
+	}
+
+	var statuses types.EndpointStatusesResponse
+	if err := c.doJSON(ctx, "GET", fmt.Sprintf("%s?%s", c.apiURL("/endpoint_status/"), params.Encode()), nil, &statuses); err != nil {
+		return nil, err
+	}
+	return &statuses, nil
+}
+
+// ListEndpoints retrieves a page of a product's endpoints, ordered by ID
+func (c *HTTPClient) ListEndpoints(ctx context.Context, productID, limit, offset int) (*types.EndpointsResponse, error) {
+	params := url.Values{}
+	params.Add("product", strconv.Itoa(productID))
+	params.Add("limit", strconv.Itoa(limit))
+	params.Add("offset", strconv.Itoa(offset))
+	params.Add("o", "id")
+
+	var endpoints types.EndpointsResponse
+	if err := c.doJSON(ctx, "GET", fmt.Sprintf("%s?%s", c.apiURL("/endpoints/"), params.Encode()), nil, &endpoints); err != nil {
+		return nil, err
+	}
+	return &endpoints, nil
+}
+
+// ListTechnologies retrieves a page of the technologies detected in a product
+func (c *HTTPClient) ListTechnologies(ctx context.Context, productID, limit, offset int) (*types.TechnologiesResponse, error) {
+	params := url.Values{}
+	params.Add("product", strconv.Itoa(productID))
+	params.Add("limit", strconv.Itoa(limit))
+	params.Add("offset", strconv.Itoa(offset))
+
+	var technologies types.TechnologiesResponse
+	if err := c.doJSON(ctx, "GET", fmt.Sprintf("%s?%s", c.apiURL("/technologies/"), params.Encode()), nil, &technologies); err != nil {
+		return nil, err
+	}
+	return &technologies, nil
 }
 
-// MarkFalsePositive marks a finding as false positive with justification
+// MarkFalsePositive sets or clears a finding's false positive flag.
+// It PATCHes false_p = request.IsFalsePositive (plus active=false when marking
+// with AlsoDeactivate, active=true when clearing with Reactivate, and verified
+// when requested), then records the justification and notes as a finding note.
+// The response reflects the finding state returned by DefectDojo.
+//
+// With IfUnmodifiedSince set, the finding is read first and a ConflictError
+// returned if it changed since. DefectDojo has no conditional update, so a
+// change landing between the read and the PATCH is not detected.
 func (c *HTTPClient) MarkFalsePositive(ctx context.Context, findingID int, request types.FalsePositiveRequest) (*types.FalsePositiveResponse, error) {
-	apiURL := fmt.Sprintf("%s%s/findings/%d/", c.config.BaseURL, c.config.GetAPIBasePath(), findingID)
+	apiURL := c.apiURL("/findings/%d/", findingID)
+
+	if request.IsFalsePositive && request.Verified != nil && *request.Verified {
+		return nil, errVerifiedFalsePositive
+	}
+
+	if !request.IfUnmodifiedSince.IsZero() {
+		current, err := c.GetFindingDetail(ctx, findingID)
+		if err != nil {
+			return nil, fmt.Errorf("checking finding %d for concurrent changes: %w", findingID, err)
+		}
+		if err := CheckUnmodified(current, request.IfUnmodifiedSince); err != nil {
+			return nil, err
+		}
+	}
 
-	// Prepare the request payload
 	payload := map[string]interface{}{
-		"false_p":       true,
-		"justification": request.Justification,
+		"false_p": request.IsFalsePositive,
+	}
+	if request.IsFalsePositive && request.AlsoDeactivate {
+		payload["active"] = false
+	}
+	if !request.IsFalsePositive && request.Reactivate {
+		payload["active"] = true
+	}
+	if request.Verified != nil {
+		payload["verified"] = *request.Verified
+	}
+
+	var finding types.Finding
+	if err := c.doJSON(ctx, "PATCH", apiURL, payload, &finding); err != nil {
+		return nil, err
+	}
+
+	action := "marked as false positive"
+	if !request.IsFalsePositive {
+		action = "cleared as false positive"
+	}
+
+	response := &types.FalsePositiveResponse{
+		ID:            finding.ID,
+		FalseP:        finding.FalseP,
+		Active:        finding.Active,
+		Verified:      finding.Verified,
+		Justification: request.Justification,
+		Notes:         request.Notes,
+		Message:       "Finding successfully " + action,
 	}
 
-	// Add notes if provided
+	if entry := falsePositiveNote(request); entry != "" {
+		note, err := c.AddFindingNote(ctx, findingID, entry)
+		if err != nil {
+			return nil, fmt.Errorf("finding %d was %s, but recording the justification note failed: %w", findingID, action, err)
+		}
+		response.NoteID = note.ID
+	}
+
+	return response, nil
+}
+
+// falsePositiveNote builds the note text recording why the false positive flag changed
+func falsePositiveNote(request types.FalsePositiveRequest) string {
+	var parts []string
+	if request.Justification != "" {
+		label := "False positive justification: "
+		if !request.IsFalsePositive {
+			label = "False positive cleared: "
+		}
+		parts = append(parts, label+request.Justification)
+	}
 	if request.Notes != "" {
-		payload["notes"] = request.Notes
+		parts = append(parts, "Notes: "+request.Notes)
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// AssignFinding replaces the users a finding is assigned to, its reviewers in
+// DefectDojo; an empty list unassigns it. It returns the updated finding.
+func (c *HTTPClient) AssignFinding(ctx context.Context, findingID int, userIDs []int) (*types.Finding, error) {
+	if userIDs == nil {
+		userIDs = []int{}
+	}
+	var finding types.Finding
+	if err := c.doJSON(ctx, "PATCH", c.apiURL("/findings/%d/", findingID), map[string]interface{}{"reviewers": userIDs}, &finding); err != nil {
+		return nil, err
+	}
+	return &finding, nil
+}
+
+// CloseFinding closes a finding as mitigated through DefectDojo's close
+// action, which also records the note. It returns the closed finding.
+func (c *HTTPClient) CloseFinding(ctx context.Context, findingID int, request types.CloseFindingRequest) (*types.Finding, error) {
+	mitigated := request.Mitigated
+	if mitigated.IsZero() {
+		mitigated = time.Now()
+	}
+	payload := map[string]interface{}{
+		"is_mitigated": true,
+		"mitigated":    mitigated.UTC().Format(time.RFC3339),
+		"false_p":      false,
+		"out_of_scope": false,
+		"duplicate":    false,
 	}
+	if request.Note != "" {
+		payload["note"] = request.Note
+	}
+	if err := c.doJSON(ctx, "POST", c.apiURL("/findings/%d/close/", findingID), payload, nil); err != nil {
+		return nil, err
+	}
+	return c.GetFindingDetail(ctx, findingID)
+}
 
-	jsonData, err := json.Marshal(payload)
+// ChangeSeverity re-grades a finding. It reads the finding to learn its
+// current severity (returning a ConflictError if it changed after
+// IfUnmodifiedSince), PATCHes severity and numerical_severity, then records
+// the justification as a finding note.
+func (c *HTTPClient) ChangeSeverity(ctx context.Context, findingID int, request types.SeverityChangeRequest) (*types.SeverityChangeResponse, error) {
+	if !types.IsValidSeverity(request.Severity) {
+		return nil, fmt.Errorf("invalid severity %q (must be one of %s)", request.Severity, strings.Join(types.ValidSeverities(), ", "))
+	}
+	current, err := c.GetFindingDetail(ctx, findingID)
 	if err != nil {
-		return nil, fmt.Errorf("marshaling request: %w", err)
+		return nil, fmt.Errorf("reading finding %d: %w", findingID, err)
+	}
+	if err := CheckUnmodified(current, request.IfUnmodifiedSince); err != nil {
+		return nil, err
+	}
+
+	payload := map[string]interface{}{
+		"severity":           request.Severity,
+		"numerical_severity": types.NumericalSeverity(request.Severity),
+	}
+	var finding types.Finding
+	if err := c.doJSON(ctx, "PATCH", c.apiURL("/findings/%d/", findingID), payload, &finding); err != nil {
+		return nil, err
+	}
+
+	response := &types.SeverityChangeResponse{
+		ID:                finding.ID,
+		PreviousSeverity:  current.Severity,
+		Severity:          finding.Severity,
+		NumericalSeverity: finding.NumericalSeverity,
+		Justification:     request.Justification,
+		Message:           "Finding severity successfully changed",
+	}
+	if request.Justification != "" {
+		note, err := c.AddFindingNote(ctx, findingID, severityChangeNote(current.Severity, request))
+		if err != nil {
+			return nil, fmt.Errorf("finding %d was re-graded to %s, but recording the justification note failed: %w", findingID, request.Severity, err)
+		}
+		response.NoteID = note.ID
+	}
+	return response, nil
+}
+
+// severityChangeNote builds the note text recording why the severity changed
+func severityChangeNote(previous string, request types.SeverityChangeRequest) string {
+	return fmt.Sprintf("Severity changed from %s to %s: %s", previous, request.Severity, request.Justification)
+}
+
+// AddFindingNote adds a public note to a finding
+func (c *HTTPClient) AddFindingNote(ctx context.Context, findingID int, entry string) (*types.Note, error) {
+	apiURL := c.apiURL("/findings/%d/notes/", findingID)
+
+	payload := map[string]interface{}{
+		"entry":   entry,
+		"private": false,
+	}
+
+	var note types.Note
+	if err := c.doJSON(ctx, "POST", apiURL, payload, &note); err != nil {
+		return nil, err
+	}
+
+	return &note, nil
+}
+
+// GetFindingMetadata returns the custom metadata attached to a finding
+func (c *HTTPClient) GetFindingMetadata(ctx context.Context, findingID int) ([]types.FindingMetadata, error) {
+	var metadata []types.FindingMetadata
+	if err := c.doJSON(ctx, "GET", c.apiURL("/findings/%d/metadata/", findingID), nil, &metadata); err != nil {
+		return nil, err
+	}
+	return metadata, nil
+}
+
+// AddFindingMetadata attaches a name/value pair to a finding. DefectDojo
+// rejects a name the finding already has.
+func (c *HTTPClient) AddFindingMetadata(ctx context.Context, findingID int, metadata types.FindingMetadata) (*types.FindingMetadata, error) {
+	payload := map[string]string{
+		"name":  metadata.Name,
+		"value": metadata.Value,
+	}
+
+	var created types.FindingMetadata
+	if err := c.doJSON(ctx, "POST", c.apiURL("/findings/%d/metadata/", findingID), payload, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// ImportScan uploads a scan report to /import-scan/, creating a new test.
+// The multipart boundary is derived from the report content so identical
+// imports produce identical requests, as record and replay modes require.
+func (c *HTTPClient) ImportScan(ctx context.Context, request types.ImportScanRequest) (*types.ImportScanResponse, error) {
+	fields := map[string]string{
+		"scan_type":        request.ScanType,
+		"test_title":       request.TestTitle,
+		"minimum_severity": request.MinimumSeverity,
+	}
+	if request.Engagement != 0 {
+		fields["engagement"] = strconv.Itoa(request.Engagement)
+	} else {
+		fields["product_name"] = request.ProductName
+		fields["engagement_name"] = request.EngagementName
+		fields["auto_create_context"] = strconv.FormatBool(request.AutoCreateContext)
+	}
+	if request.Active != nil {
+		fields["active"] = strconv.FormatBool(*request.Active)
+	}
+	if request.Verified != nil {
+		fields["verified"] = strconv.FormatBool(*request.Verified)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "PATCH", apiURL, bytes.NewBuffer(jsonData))
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	sum := sha256.Sum256(request.File)
+	if err := writer.SetBoundary("mcp-defect-dojo-" + hex.EncodeToString(sum[:16])); err != nil {
+		return nil, fmt.Errorf("building import request: %w", err)
+	}
+	for _, name := range slices.Sorted(maps.Keys(fields)) {
+		if fields[name] == "" {
+			continue
+		}
+		if err := writer.WriteField(name, fields[name]); err != nil {
+			return nil, fmt.Errorf("building import request: %w", err)
+		}
+	}
+	file, err := writer.CreateFormFile("file", request.FileName)
+	if err == nil {
+		_, err = file.Write(request.File)
+	}
+	if err == nil {
+		err = writer.Close()
+	}
 	if err != nil {
-		return nil, fmt.Errorf("creating request: %w", err)
+		return nil, fmt.Errorf("building import request: %w", err)
+	}
+
+	var response types.ImportScanResponse
+	if err := c.do(ctx, "POST", c.apiURL("/import-scan/"), writer.FormDataContentType(), &body, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// GetSystemSettings retrieves the instance-wide settings. DefectDojo keeps a
+// single settings object and only shows it to superusers.
+func (c *HTTPClient) GetSystemSettings(ctx context.Context) (*types.SystemSettings, error) {
+	var settings types.SystemSettingsResponse
+	if err := c.doJSON(ctx, "GET", c.apiURL("/system_settings/"), nil, &settings); err != nil {
+		return nil, err
+	}
+	if len(settings.Results) == 0 {
+		return nil, errors.New("DefectDojo returned no system settings")
+	}
+	return &settings.Results[0], nil
+}
+
+// ListDevelopmentEnvironments retrieves a page of development environments, ordered by ID
+func (c *HTTPClient) ListDevelopmentEnvironments(ctx context.Context, limit, offset int) (*types.DevelopmentEnvironmentsResponse, error) {
+	params := url.Values{}
+	params.Add("limit", strconv.Itoa(limit))
+	params.Add("offset", strconv.Itoa(offset))
+	params.Add("ordering", "id")
+
+	var environments types.DevelopmentEnvironmentsResponse
+	if err := c.doJSON(ctx, "GET", fmt.Sprintf("%s?%s", c.apiURL("/development_environments/"), params.Encode()), nil, &environments); err != nil {
+		return nil, err
+	}
+	return &environments, nil
+}
+
+// ListSLAConfigurations retrieves a page of SLA configurations, ordered by ID
+func (c *HTTPClient) ListSLAConfigurations(ctx context.Context, limit, offset int) (*types.SLAConfigurationsResponse, error) {
+	params := url.Values{}
+	params.Add("limit", strconv.Itoa(limit))
+	params.Add("offset", strconv.Itoa(offset))
+	params.Add("ordering", "id")
+
+	var configurations types.SLAConfigurationsResponse
+	if err := c.doJSON(ctx, "GET", fmt.Sprintf("%s?%s", c.apiURL("/sla_configurations/"), params.Encode()), nil, &configurations); err != nil {
+		return nil, err
+	}
+	return &configurations, nil
+}
+
+// GetAnnouncement retrieves the banner shown to all users, or nil when none is set
+func (c *HTTPClient) GetAnnouncement(ctx context.Context) (*types.Announcement, error) {
+	var announcements types.AnnouncementsResponse
+	if err := c.doJSON(ctx, "GET", c.apiURL("/announcements/"), nil, &announcements); err != nil {
+		return nil, err
+	}
+	if len(announcements.Results) == 0 {
+		return nil, nil
+	}
+	return &announcements.Results[0], nil
+}
+
+// GetUserProfile retrieves the profile of the user owning the configured API key
+func (c *HTTPClient) GetUserProfile(ctx context.Context) (*types.UserProfile, error) {
+	apiURL := c.apiURL("/user_profile/")
+
+	var profile types.UserProfile
+	if err := c.doJSON(ctx, "GET", apiURL, nil, &profile); err != nil {
+		return nil, err
+	}
+
+	return &profile, nil
+}
+
+// GetVersion detects the DefectDojo release version.
+// DefectDojo publishes its release number as info.version of the OpenAPI schema.
+func (c *HTTPClient) GetVersion(ctx context.Context) (string, error) {
+	apiURL := c.apiURL("/oa3/schema/?format=json")
+
+	var schema struct {
+		Info struct {
+			Version string `json:"version"`
+		} `json:"info"`
+	}
+	if err := c.doJSON(ctx, "GET", apiURL, nil, &schema); err != nil {
+		return "", err
+	}
+	if schema.Info.Version == "" {
+		return "", fmt.Errorf("version not reported by API schema")
+	}
+
+	return strings.TrimPrefix(schema.Info.Version, "v"), nil
+}
+
+// HealthCheck verifies DefectDojo connectivity and authentication.
+// It reports the outcome of CheckHealth as a healthy flag and a message.
+func (c *HTTPClient) HealthCheck(ctx context.Context) (bool, string) {
+	status := c.CheckHealth(ctx)
+	if !status.Healthy() {
+		return false, status.Error
+	}
+	return true, fmt.Sprintf("Successfully connected to DefectDojo at %s\nAPI Version: %s\nAuthenticated as: %s\nLatency: %s",
+		status.URL, c.config.APIVersion, status.User, status.Latency.Round(time.Millisecond))
+}
+
+// CheckHealth probes DefectDojo in two cheap steps: a HEAD request on the API
+// root to check reachability and measure latency (no schema payload is
+// transferred), then the small /user_profile/ endpoint to verify the token.
+// The detected version is included when available.
+func (c *HTTPClient) CheckHealth(ctx context.Context) *types.HealthStatus {
+	status := &types.HealthStatus{URL: c.config.BaseURL}
+	if c.apiErr != nil {
+		status.Error = c.apiErr.Error()
+		return status
 	}
 
+	ctx, cancel := c.withDefaultTimeout(ctx)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "HEAD", c.apiURL("/"), nil)
+	if err != nil {
+		status.Error = fmt.Sprintf("Failed to create request: %v", err)
+		return status
+	}
 	c.setHeaders(req)
 
+	start := time.Now()
 	resp, err := c.httpClient.Do(req)
+	status.Latency = time.Since(start)
 	if err != nil {
-		return nil, fmt.Errorf("HTTP request failed: %w", err)
+		status.Error = fmt.Sprintf("Connection failed to %s: %v", c.config.BaseURL, err)
+		return status
 	}
-	defer resp.Body.Close()
+	resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+	// Any answer other than a server error or a missing API means DefectDojo is up;
+	// 401/403 are expected here and resolved by the authenticated check below
+	if resp.StatusCode >= 500 || resp.StatusCode == http.StatusNotFound {
+		status.Error = fmt.Sprintf("DefectDojo responded with status %d at %s", resp.StatusCode, c.apiURL("/"))
+		return status
 	}
+	status.Reachable = true
 
-	var finding types.Finding
-	if err := json.NewDecoder(resp.Body).Decode(&finding); err != nil {
-		return nil, fmt.Errorf("decoding response: %w", err)
+	profile, err := c.GetUserProfile(ctx)
+	if err != nil {
+		status.Error = fmt.Sprintf("Authentication failed: %v", err)
+		return status
 	}
+	status.Authenticated = true
+	status.User = profile.User.Username
+	status.Version = c.Version(ctx)
 
-	return &types.FalsePositiveResponse{
-		ID:      finding.ID,
-		FalseP:  finding.FalseP,
-		Message: "Finding successfully marked as false positive",
-	}, nil
+	return status
 }
 
-// HealthCheck verifies DefectDojo connectivity
-func (c *HTTPClient) HealthCheck(ctx context.Context) (bool, string) {
-	apiURL := fmt.Sprintf("%s%s/", c.config.BaseURL, c.config.GetAPIBasePath())
+// doJSON performs an API request with an optional JSON payload and decodes
+// the JSON response into out. Any 2xx status is treated as success; other
+// statuses are returned as *APIError.
+func (c *HTTPClient) doJSON(ctx context.Context, method, apiURL string, payload any, out any) error {
+	var body io.Reader
+	if payload != nil {
+		jsonData, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("marshaling request: %w", err)
+		}
+		body = bytes.NewBuffer(jsonData)
+	}
+	return c.do(ctx, method, apiURL, "application/json", body, out)
+}
+
+// do performs an API request with a body of the given content type and
+// decodes the JSON response into out, like doJSON.
+func (c *HTTPClient) do(ctx context.Context, method, apiURL, contentType string, body io.Reader, out any) error {
+	if c.apiErr != nil {
+		return c.apiErr
+	}
+	ctx, cancel := c.withDefaultTimeout(ctx)
+	defer cancel()
 
-	req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
+	req, err := http.NewRequestWithContext(ctx, method, apiURL, body)
 	if err != nil {
-		return false, fmt.Sprintf("Failed to create request: %v", err)
+		return fmt.Errorf("creating request: %w", err)
 	}
 
 	c.setHeaders(req)
+	req.Header.Set("Content-Type", contentType)
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return false, fmt.Sprintf("Connection failed to %s: %v", c.config.BaseURL, err)
+		return fmt.Errorf("HTTP request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode == http.StatusOK {
-		return true, fmt.Sprintf("Successfully connected to DefectDojo at %s\nAPI Version: %s\nStatus Code: %d",
-			c.config.BaseURL, c.config.APIVersion, resp.StatusCode)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return c.apiError(ctx, resp.StatusCode, respBody)
+	}
+
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decoding response: %w", err)
+	}
+	return nil
+}
+
+// withDefaultTimeout applies the configured RequestTimeout unless the caller
+// already set a deadline (e.g. a per-tool-call timeout override)
+func (c *HTTPClient) withDefaultTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok || c.config.RequestTimeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, c.config.RequestTimeout)
+}
+
+// apiURL builds an absolute API URL from a path relative to the API base of
+// the configured version, e.g. c.apiURL("/findings/%d/", id)
+func (c *HTTPClient) apiURL(format string, args ...any) string {
+	resource := fmt.Sprintf(format, args...)
+	if c.apiErr != nil {
+		// Requests fail before they are sent; keep URLs in messages recognizable
+		return c.config.BaseURL + c.config.GetAPIBasePath() + resource
 	}
+	return c.config.BaseURL + c.api.path(resource)
+}
+
+// withQuery appends the encoded query parameters to an API URL, if any
+func withQuery(apiURL string, params url.Values) string {
+	if len(params) == 0 {
+		return apiURL
+	}
+	return apiURL + "?" + params.Encode()
+}
 
-	body, _ := io.ReadAll(resp.Body)
-	return false, fmt.Sprintf("DefectDojo responded with status %d: %s", resp.StatusCode, string(body))
+// ptr returns a pointer to value, for the optional fields of generated parameters
+func ptr[T any](value T) *T {
+	return &value
+}
+
+// apiKeyContextKey carries a per-call API token override
+type apiKeyContextKey struct{}
+
+// WithAPIKey returns a context whose requests authenticate with apiKey
+// instead of the configured API key, e.g. a token scoped to one product
+func WithAPIKey(ctx context.Context, apiKey string) context.Context {
+	return context.WithValue(ctx, apiKeyContextKey{}, apiKey)
+}
+
+// APIKeyFromContext returns the API token set by WithAPIKey, if any
+func APIKeyFromContext(ctx context.Context) (string, bool) {
+	apiKey, ok := ctx.Value(apiKeyContextKey{}).(string)
+	return apiKey, ok
 }
 
 // setHeaders sets common headers for API requests
@@ -199,7 +978,15 @@ func (c *HTTPClient) setHeaders(req *http.Request) {
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("Content-Type", "application/json")
 
-	if c.config.APIKey != "" {
-		req.Header.Set("Authorization", "Token "+c.config.APIKey)
+	apiKey := c.config.APIKey
+	if key, ok := APIKeyFromContext(req.Context()); ok {
+		apiKey = key
+	}
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Token "+apiKey)
+	}
+
+	if id := requestid.FromContext(req.Context()); id != "" {
+		req.Header.Set(requestid.Header, id)
 	}
 }
@@ -3,13 +3,18 @@ package defectdojo
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"slices"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/brduru/mcp-defect-dojo/internal/config"
+	"github.com/brduru/mcp-defect-dojo/internal/requestid"
 	"github.com/brduru/mcp-defect-dojo/pkg/types"
 )
 
@@ -44,19 +49,19 @@ func TestHTTPClient_HealthCheck(t *testing.T) {
 		{
 			name: "healthy server",
 			serverResponse: func(w http.ResponseWriter, r *http.Request) {
-				if r.Method != "GET" {
-					t.Errorf("Expected GET request, got %s", r.Method)
-				}
 				if !strings.Contains(r.URL.Path, "/api/v2/") {
 					t.Errorf("Expected API v2 path, got %s", r.URL.Path)
 				}
+				if r.URL.Path == "/api/v2/" && r.Method != "HEAD" {
+					t.Errorf("Expected HEAD request on API root, got %s", r.Method)
+				}
 				w.WriteHeader(http.StatusOK)
 				json.NewEncoder(w).Encode(map[string]interface{}{
-					"status": "ok",
+					"user": map[string]interface{}{"username": "admin"},
 				})
 			},
 			expectedHealth: true,
-			expectedMsg:    "",
+			expectedMsg:    "Authenticated as: admin",
 		},
 		{
 			name: "server error",
@@ -102,6 +107,69 @@ func TestHTTPClient_HealthCheck(t *testing.T) {
 	}
 }
 
+func TestHTTPClient_CheckHealth(t *testing.T) {
+	tests := []struct {
+		name              string
+		rootCode          int
+		profileCode       int
+		wantReachable     bool
+		wantAuthenticated bool
+		wantErr           string
+	}{
+		{"healthy", http.StatusOK, http.StatusOK, true, true, ""},
+		{"root requires auth", http.StatusUnauthorized, http.StatusOK, true, true, ""},
+		{"rejected token", http.StatusUnauthorized, http.StatusUnauthorized, true, false, "Authentication failed"},
+		{"server error", http.StatusBadGateway, http.StatusOK, false, false, "status 502"},
+		{"not DefectDojo", http.StatusNotFound, http.StatusOK, false, false, "status 404"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/api/v2/":
+					if r.Method != "HEAD" {
+						t.Errorf("Expected HEAD request on API root, got %s", r.Method)
+					}
+					w.WriteHeader(tt.rootCode)
+				case "/api/v2/user_profile/":
+					w.WriteHeader(tt.profileCode)
+					json.NewEncoder(w).Encode(types.UserProfile{User: types.User{Username: "bot"}})
+				case "/api/v2/oa3/schema/":
+					json.NewEncoder(w).Encode(map[string]any{"info": map[string]any{"version": "2.38.1"}})
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+
+			client := NewHTTPClient(&config.DefectDojoConfig{
+				BaseURL:        server.URL,
+				APIKey:         "test-key",
+				APIVersion:     "v2",
+				RequestTimeout: 5 * time.Second,
+			})
+
+			status := client.CheckHealth(context.Background())
+			if status.Reachable != tt.wantReachable || status.Authenticated != tt.wantAuthenticated {
+				t.Errorf("reachable=%v authenticated=%v, want %v/%v (error %q)",
+					status.Reachable, status.Authenticated, tt.wantReachable, tt.wantAuthenticated, status.Error)
+			}
+			if tt.wantErr != "" && !strings.Contains(status.Error, tt.wantErr) {
+				t.Errorf("Expected error to contain %q, got %q", tt.wantErr, status.Error)
+			}
+			if status.Healthy() {
+				if status.User != "bot" || status.Version != "2.38.1" {
+					t.Errorf("Expected user bot and version 2.38.1, got %q and %q", status.User, status.Version)
+				}
+				if status.Latency <= 0 {
+					t.Errorf("Expected latency to be measured")
+				}
+			}
+		})
+	}
+}
+
 func TestHTTPClient_GetFindings(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -215,6 +283,192 @@ func TestHTTPClient_GetFindings(t *testing.T) {
 	}
 }
 
+func TestHTTPClient_GetFindingsStatusFilters(t *testing.T) {
+	var query map[string][]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		json.NewEncoder(w).Encode(types.FindingsResponse{})
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(&config.DefectDojoConfig{BaseURL: server.URL, APIVersion: "v2"})
+	yes, no := true, false
+
+	if _, err := client.GetFindings(context.Background(), types.FindingsFilter{Limit: 10}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, param := range []string{"active", "risk_accepted", "is_mitigated", "duplicate"} {
+		if _, ok := query[param]; ok {
+			t.Errorf("Expected no %s parameter when filter is unset", param)
+		}
+	}
+
+	filter := types.FindingsFilter{Limit: 10, Active: &no, RiskAccepted: &yes, IsMitigated: &no, Duplicate: &no, Ordering: "numerical_severity, -date"}
+	if _, err := client.GetFindings(context.Background(), filter); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for param, want := range map[string]string{"active": "false", "risk_accepted": "true", "is_mitigated": "false", "duplicate": "false", "ordering": "numerical_severity,-date"} {
+		if got := query[param]; len(got) != 1 || got[0] != want {
+			t.Errorf("Expected %s=%s, got %v", param, want, got)
+		}
+	}
+}
+
+func TestHTTPClient_GetFindingsTagFilters(t *testing.T) {
+	var query map[string][]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		json.NewEncoder(w).Encode(types.FindingsResponse{})
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(&config.DefectDojoConfig{BaseURL: server.URL, APIVersion: "v2"})
+	product, engagement, environment := 4, 8, 3
+	filter := types.FindingsFilter{
+		Limit:       10,
+		Tags:        []string{"triage", "pci"},
+		NotTags:     []string{"wontfix"},
+		Reporter:    []int{3, 7},
+		FoundBy:     []int{12},
+		Product:     &product,
+		Engagement:  &engagement,
+		Environment: &environment,
+
+		ComponentName:    "lodash",
+		ComponentVersion: "4.17.15",
+
+		DiscoveredAfter:  time.Date(2026, 10, 9, 0, 0, 0, 0, time.UTC),
+		DiscoveredBefore: time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC),
+		MitigatedAfter:   time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC),
+		MitigatedBefore:  time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC),
+
+		RelatedFields: true,
+	}
+	if _, err := client.GetFindings(context.Background(), filter); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := map[string]string{"tags": "triage,pci", "not_tags": "wontfix", "reporter": "3,7", "found_by": "12", "test__engagement__product": "4", "test__engagement": "8", "test__environment": "3", "component_name": "lodash", "component_version": "4.17.15", "discovered_after": "2026-10-09", "discovered_before": "2026-10-16", "mitigated_after": "2026-10-02", "mitigated_before": "2026-10-12", "related_fields": "true"}
+	for param, value := range want {
+		if got := strings.Join(query[param], ","); got != value {
+			t.Errorf("Expected %s=%s, got %q", param, value, got)
+		}
+	}
+}
+
+func TestHTTPClient_ReferenceLookups(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v2/tests/5/":
+			json.NewEncoder(w).Encode(map[string]any{"id": 5, "title": "ZAP Scan", "engagement": 8, "test_type": 3})
+		case "/api/v2/test_types/3/":
+			json.NewEncoder(w).Encode(map[string]any{"id": 3, "name": "ZAP Scan"})
+		case "/api/v2/engagements/8/":
+			json.NewEncoder(w).Encode(map[string]any{"id": 8, "name": "Q3 Pentest", "product": 2})
+		case "/api/v2/products/2/":
+			json.NewEncoder(w).Encode(map[string]any{"id": 2, "name": "Payments API"})
+		case "/api/v2/products/":
+			if r.URL.Query().Get("offset") != "20" || r.URL.Query().Get("limit") != "10" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]any{"count": 21, "results": []map[string]any{{"id": 2, "name": "Payments API", "tags": []string{"pci"}}}})
+		case "/api/v2/tests/":
+			if r.URL.Query().Get("engagement") != "8" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]any{"count": 1, "results": []map[string]any{{"id": 5, "title": "ZAP Scan", "engagement": 8, "test_type": 3}}})
+		case "/api/v2/engagements/":
+			query := r.URL.Query()
+			if query.Get("active") != "true" || query.Get("product") != "2" || query.Get("prefetch") != "lead" || query.Get("o") != "target_start" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.Write([]byte(`{"count": 1, "next": null, "results": [{"id": 8, "name": "Q3 Pentest", "product": 2, "lead": 3, "active": true, "target_start": "2026-07-01"}],
+				"prefetch": {"lead": {"3": {"id": 3, "username": "dana"}}}}`))
+		case "/api/v2/risk_acceptance/":
+			w.Write([]byte(`{"count": 2, "next": null, "results": [
+				{"id": 3, "name": "Legacy TLS", "accepted_findings": [12, 13], "decision": "A", "owner": 4, "expiration_date": "2026-11-01T00:00:00Z", "reactivate_expired": true},
+				{"id": 4, "name": "Forever", "accepted_findings": [14], "decision": "T", "expiration_date": null}]}`))
+		case "/api/v2/endpoints/", "/api/v2/technologies/":
+			if r.URL.Query().Get("product") != "2" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			if r.URL.Path == "/api/v2/endpoints/" {
+				w.Write([]byte(`{"count": 1, "next": null, "results": [{"id": 4, "protocol": "https", "host": "pay.example.com", "port": 443, "product": 2}]}`))
+			} else {
+				w.Write([]byte(`{"count": 1, "next": null, "results": [{"id": 1, "name": "nginx", "version": "1.25.3", "confidence": 100, "product": 2}]}`))
+			}
+		case "/api/v2/endpoint_status/":
+			query := r.URL.Query()
+			if query.Get("mitigated") != "false" || query.Get("risk_accepted") != "" || query.Get("prefetch") != "endpoint,finding" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.Write([]byte(`{"count": 1, "next": null, "results": [{"id": 9, "endpoint": 4, "finding": 12, "mitigated": false}],
+				"prefetch": {"endpoint": {"4": {"id": 4, "host": "pay.example.com"}}, "finding": {"12": {"id": 12, "severity": "High", "active": true}}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(&config.DefectDojoConfig{BaseURL: server.URL, APIKey: "test-key", APIVersion: "v2", RequestTimeout: 5 * time.Second})
+	ctx := context.Background()
+
+	test, err := client.GetTest(ctx, 5)
+	if err != nil || test.Engagement != 8 || test.Title != "ZAP Scan" {
+		t.Fatalf("GetTest = %+v, %v", test, err)
+	}
+	testType, err := client.GetTestType(ctx, test.TestType)
+	if err != nil || testType.Name != "ZAP Scan" {
+		t.Fatalf("GetTestType = %+v, %v", testType, err)
+	}
+	engagement, err := client.GetEngagement(ctx, test.Engagement)
+	if err != nil || engagement.Name != "Q3 Pentest" || engagement.Product != 2 {
+		t.Fatalf("GetEngagement = %+v, %v", engagement, err)
+	}
+	product, err := client.GetProduct(ctx, engagement.Product)
+	if err != nil || product.Name != "Payments API" {
+		t.Fatalf("GetProduct = %+v, %v", product, err)
+	}
+	if _, err := client.GetProduct(ctx, 99); err == nil {
+		t.Error("expected error for missing product")
+	}
+	products, err := client.ListProducts(ctx, 10, 20)
+	if err != nil || products.Count != 21 || len(products.Results) != 1 || products.Results[0].Tags[0] != "pci" {
+		t.Fatalf("ListProducts = %+v, %v", products, err)
+	}
+	tests, err := client.ListTests(ctx, 8, 100, 0)
+	if err != nil || tests.Count != 1 || tests.Results[0].ID != 5 {
+		t.Fatalf("ListTests = %+v, %v", tests, err)
+	}
+	active, productID := true, 2
+	engagements, err := client.ListEngagements(ctx, types.EngagementsFilter{Product: &productID, Active: &active, Limit: 100, Prefetch: []string{types.PrefetchLead}})
+	if err != nil || len(engagements.Results) != 1 || engagements.Results[0].Lead != 3 || engagements.Prefetch.Leads[3].Username != "dana" {
+		t.Fatalf("ListEngagements = %+v, %v", engagements, err)
+	}
+	risks, err := client.ListRiskAcceptances(ctx, 100, 0)
+	if err != nil || len(risks.Results) != 2 || risks.Results[0].ExpirationDate.Day() != 1 || !risks.Results[1].ExpirationDate.IsZero() {
+		t.Fatalf("ListRiskAcceptances = %+v, %v", risks, err)
+	}
+	endpoints, err := client.ListEndpoints(ctx, 2, 100, 0)
+	if err != nil || len(endpoints.Results) != 1 || endpoints.Results[0].String() != "https://pay.example.com:443" {
+		t.Fatalf("ListEndpoints = %+v, %v", endpoints, err)
+	}
+	technologies, err := client.ListTechnologies(ctx, 2, 100, 0)
+	if err != nil || len(technologies.Results) != 1 || technologies.Results[0].Name != "nginx" || technologies.Results[0].Confidence != 100 {
+		t.Fatalf("ListTechnologies = %+v, %v", technologies, err)
+	}
+	mitigated := false
+	statuses, err := client.ListEndpointStatuses(ctx, types.EndpointStatusFilter{Mitigated: &mitigated, Limit: 100, Prefetch: []string{types.PrefetchEndpoint, types.PrefetchFinding}})
+	if err != nil || len(statuses.Results) != 1 || statuses.Prefetch.Endpoints[4].Host != "pay.example.com" || statuses.Prefetch.Findings[12].Severity != "High" {
+		t.Fatalf("ListEndpointStatuses = %+v, %v", statuses, err)
+	}
+}
+
 func TestHTTPClient_GetFindingDetail(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -298,6 +552,44 @@ func TestHTTPClient_GetFindingDetail(t *testing.T) {
 	}
 }
 
+func TestHTTPClient_Prefetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("prefetch"); got != "test,notes" {
+			t.Errorf("prefetch = %q, want test,notes", got)
+		}
+		prefetch := `"prefetch":{"test":{"7":{"id":7,"title":"Nightly ZAP","engagement":3}},"notes":{"11":{"id":11,"entry":"Confirmed in staging"}}}`
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasSuffix(r.URL.Path, "/findings/") {
+			w.Write([]byte(`{"count":1,"results":[{"id":5,"title":"XSS","test":7,"notes":[11]}],` + prefetch + `}`))
+			return
+		}
+		w.Write([]byte(`{"id":5,"title":"XSS","test":7,"notes":[11],"date":"2024-03-01",` + prefetch + `}`))
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(&config.DefectDojoConfig{BaseURL: server.URL, APIKey: "key", APIVersion: "v2", RequestTimeout: 5 * time.Second})
+	prefetch := []string{types.PrefetchTest, types.PrefetchNotes}
+
+	page, err := client.GetFindings(context.Background(), types.FindingsFilter{Limit: 1, Prefetch: prefetch})
+	if err != nil {
+		t.Fatalf("GetFindings() error = %v", err)
+	}
+	if page.Prefetch == nil || page.Prefetch.Tests[7].Title != "Nightly ZAP" || page.Prefetch.Notes[11].Entry != "Confirmed in staging" {
+		t.Errorf("unexpected prefetched objects %+v", page.Prefetch)
+	}
+
+	detail, err := client.GetFindingDetailPrefetch(context.Background(), 5, prefetch)
+	if err != nil {
+		t.Fatalf("GetFindingDetailPrefetch() error = %v", err)
+	}
+	if detail.Title != "XSS" || detail.Date.IsZero() || len(detail.Notes) != 1 {
+		t.Errorf("finding fields lost: %+v", detail.Finding)
+	}
+	if detail.Prefetch == nil || detail.Prefetch.Tests[detail.Test].Engagement != 3 || detail.Prefetch.Notes[detail.Notes[0]].Entry != "Confirmed in staging" {
+		t.Errorf("unexpected prefetched objects %+v", detail.Prefetch)
+	}
+}
+
 func TestHTTPClient_MarkFalsePositive(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -315,31 +607,88 @@ func TestHTTPClient_MarkFalsePositive(t *testing.T) {
 				Notes:           "Confirmed with security team",
 			},
 			serverResponse: func(w http.ResponseWriter, r *http.Request) {
-				if r.Method != "PATCH" {
-					t.Errorf("Expected PATCH request, got %s", r.Method)
-				}
-
-				// Verify request body
-				var reqBody types.FalsePositiveRequest
+				var reqBody map[string]any
 				json.NewDecoder(r.Body).Decode(&reqBody)
+				w.Header().Set("Content-Type", "application/json")
 
-				if !reqBody.IsFalsePositive {
-					t.Error("Expected IsFalsePositive to be true")
+				switch {
+				case r.Method == "PATCH" && r.URL.Path == "/api/v2/findings/456/":
+					if reqBody["false_p"] != true {
+						t.Errorf("Expected false_p=true, got %v", reqBody["false_p"])
+					}
+					if _, ok := reqBody["justification"]; ok {
+						t.Error("justification is not a finding field and must not be PATCHed")
+					}
+					if _, ok := reqBody["active"]; ok {
+						t.Error("active must not change without AlsoDeactivate")
+					}
+					json.NewEncoder(w).Encode(types.Finding{ID: 456, FalseP: true, Active: true})
+				case r.Method == "POST" && r.URL.Path == "/api/v2/findings/456/notes/":
+					entry, _ := reqBody["entry"].(string)
+					if !strings.Contains(entry, "This is a test environment") || !strings.Contains(entry, "Confirmed with security team") {
+						t.Errorf("Expected justification and notes in note entry, got %q", entry)
+					}
+					w.WriteHeader(http.StatusCreated)
+					json.NewEncoder(w).Encode(types.Note{ID: 99, Entry: entry})
+				default:
+					t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+					w.WriteHeader(http.StatusNotFound)
 				}
-
-				response := types.FalsePositiveResponse{
-					ID:            456,
-					FalseP:        true,
-					Justification: reqBody.Justification,
-					Notes:         reqBody.Notes,
-					Message:       "Successfully marked as false positive",
+			},
+			expectError: false,
+		},
+		{
+			name:      "deactivate and unverify",
+			findingID: 456,
+			request: types.FalsePositiveRequest{
+				IsFalsePositive: true,
+				Justification:   "Scanner misread",
+				AlsoDeactivate:  true,
+				Verified:        func() *bool { v := false; return &v }(),
+			},
+			serverResponse: func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == "POST" {
+					json.NewEncoder(w).Encode(types.Note{ID: 100})
+					return
 				}
-
-				w.Header().Set("Content-Type", "application/json")
-				json.NewEncoder(w).Encode(response)
+				var reqBody map[string]any
+				json.NewDecoder(r.Body).Decode(&reqBody)
+				if reqBody["active"] != false || reqBody["verified"] != false {
+					t.Errorf("Expected active=false and verified=false, got %v", reqBody)
+				}
+				json.NewEncoder(w).Encode(types.Finding{ID: 456, FalseP: true})
 			},
 			expectError: false,
 		},
+		{
+			name:      "verified false positive is refused",
+			findingID: 456,
+			request: types.FalsePositiveRequest{
+				IsFalsePositive: true,
+				Justification:   "Scanner misread",
+				Verified:        func() *bool { v := true; return &v }(),
+			},
+			serverResponse: func(w http.ResponseWriter, r *http.Request) {
+				t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+			},
+			expectError: true,
+		},
+		{
+			name:      "note failure is reported",
+			findingID: 456,
+			request: types.FalsePositiveRequest{
+				IsFalsePositive: true,
+				Justification:   "Test",
+			},
+			serverResponse: func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == "POST" {
+					w.WriteHeader(http.StatusForbidden)
+					return
+				}
+				json.NewEncoder(w).Encode(types.Finding{ID: 456, FalseP: true})
+			},
+			expectError: true,
+		},
 		{
 			name:      "server error",
 			findingID: 456,
@@ -392,6 +741,375 @@ func TestHTTPClient_MarkFalsePositive(t *testing.T) {
 	}
 }
 
+func TestHTTPClient_MarkFalsePositiveConflict(t *testing.T) {
+	modified := time.Date(2024, 6, 3, 14, 5, 9, 482000000, time.UTC)
+	patches := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet:
+			json.NewEncoder(w).Encode(types.Finding{ID: 1, Modified: modified})
+		case r.Method == http.MethodPatch:
+			patches++
+			json.NewEncoder(w).Encode(types.Finding{ID: 1, FalseP: true})
+		default:
+			json.NewEncoder(w).Encode(types.Note{ID: 9})
+		}
+	}))
+	defer server.Close()
+	client := NewHTTPClient(&config.DefectDojoConfig{BaseURL: server.URL, APIKey: "key", APIVersion: "v2", RequestTimeout: 5 * time.Second})
+
+	// Tool output shows whole seconds, which must not count as a change
+	request := types.FalsePositiveRequest{IsFalsePositive: true, Justification: "test code", IfUnmodifiedSince: modified.Truncate(time.Second)}
+	if _, err := client.MarkFalsePositive(context.Background(), 1, request); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	request.IfUnmodifiedSince = modified.Add(-time.Minute)
+	_, err := client.MarkFalsePositive(context.Background(), 1, request)
+	var conflict *ConflictError
+	if !errors.As(err, &conflict) || !conflict.Modified.Equal(modified) {
+		t.Fatalf("expected a ConflictError, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "modified at 2024-06-03T14:05:09Z") {
+		t.Errorf("unexpected message %q", err)
+	}
+	if patches != 1 {
+		t.Errorf("expected the conflicting write not to PATCH, got %d patches", patches)
+	}
+}
+
+func TestHTTPClient_ClearFalsePositive(t *testing.T) {
+	var patch map[string]any
+	var noteEntry string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		if r.Method == "POST" {
+			noteEntry, _ = body["entry"].(string)
+			json.NewEncoder(w).Encode(types.Note{ID: 5})
+			return
+		}
+		patch = body
+		json.NewEncoder(w).Encode(types.Finding{ID: 321, FalseP: false, Active: true})
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(&config.DefectDojoConfig{BaseURL: server.URL, APIVersion: "v2"})
+	response, err := client.MarkFalsePositive(context.Background(), 321, types.FalsePositiveRequest{
+		IsFalsePositive: false,
+		Justification:   "Exploit confirmed",
+		Reactivate:      true,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if patch["false_p"] != false || patch["active"] != true {
+		t.Errorf("Expected false_p=false and active=true, got %v", patch)
+	}
+	if !strings.Contains(noteEntry, "False positive cleared: Exploit confirmed") {
+		t.Errorf("Unexpected note entry %q", noteEntry)
+	}
+	if response.FalseP || !response.Active || response.NoteID != 5 {
+		t.Errorf("Unexpected response %+v", response)
+	}
+}
+
+func TestHTTPClient_FindingMetadata(t *testing.T) {
+	var posted map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v2/findings/42/metadata/" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		if r.Method == "POST" {
+			json.NewDecoder(r.Body).Decode(&posted)
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(types.FindingMetadata{ID: 9, Name: posted["name"], Value: posted["value"]})
+			return
+		}
+		json.NewEncoder(w).Encode([]types.FindingMetadata{{ID: 3, Name: "owner", Value: "payments-team"}})
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(&config.DefectDojoConfig{BaseURL: server.URL, APIVersion: "v2"})
+	metadata, err := client.GetFindingMetadata(context.Background(), 42)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(metadata) != 1 || metadata[0].Name != "owner" || metadata[0].Value != "payments-team" {
+		t.Errorf("Unexpected metadata %+v", metadata)
+	}
+
+	created, err := client.AddFindingMetadata(context.Background(), 42, types.FindingMetadata{Name: "issue_url", Value: "https://github.com/acme/api/issues/7"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if posted["name"] != "issue_url" || posted["value"] != "https://github.com/acme/api/issues/7" || created.ID != 9 {
+		t.Errorf("Unexpected request %v or response %+v", posted, created)
+	}
+}
+
+func TestHTTPClient_Onboarding(t *testing.T) {
+	posted := map[string]map[string]any{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "GET /api/v2/product_types/":
+			w.Write([]byte(`{"count": 1, "next": null, "results": [{"id": 4, "name": "Web Applications"}]}`))
+		case "POST /api/v2/products/":
+			var body map[string]any
+			json.NewDecoder(r.Body).Decode(&body)
+			posted["product"] = body
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id": 7, "name": "Billing Service", "prod_type": 4, "tags": ["team-billing"]}`))
+		case "POST /api/v2/engagements/":
+			var body map[string]any
+			json.NewDecoder(r.Body).Decode(&body)
+			posted["engagement"] = body
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id": 30, "name": "CI scans", "product": 7, "active": true}`))
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(&config.DefectDojoConfig{BaseURL: server.URL, APIVersion: "v2"})
+	ctx := context.Background()
+
+	productTypes, err := client.ListProductTypes(ctx, 100, 0)
+	if err != nil || len(productTypes.Results) != 1 || productTypes.Results[0].Name != "Web Applications" {
+		t.Fatalf("ListProductTypes = %+v, %v", productTypes, err)
+	}
+	product, err := client.CreateProduct(ctx, types.CreateProductRequest{Name: "Billing Service", Description: "Invoices", ProductType: 4, Tags: []string{"team-billing"}})
+	if err != nil || product.ID != 7 || product.ProductType != 4 {
+		t.Fatalf("CreateProduct = %+v, %v", product, err)
+	}
+	if body := posted["product"]; body["prod_type"] != 4.0 || body["description"] != "Invoices" {
+		t.Errorf("Unexpected product request %v", body)
+	}
+	engagement, err := client.CreateEngagement(ctx, types.CreateEngagementRequest{Product: 7, Name: "CI scans", EngagementType: "CI/CD", Status: "In Progress", TargetStart: "2026-03-01", TargetEnd: "2026-03-08"})
+	if err != nil || engagement.ID != 30 || !engagement.Active {
+		t.Fatalf("CreateEngagement = %+v, %v", engagement, err)
+	}
+	want := map[string]any{"product": 7.0, "name": "CI scans", "engagement_type": "CI/CD", "status": "In Progress", "target_start": "2026-03-01", "target_end": "2026-03-08"}
+	for field, value := range want {
+		if got := posted["engagement"][field]; got != value {
+			t.Errorf("Expected engagement field %s=%v, got %v", field, value, got)
+		}
+	}
+}
+
+func TestHTTPClient_Assignment(t *testing.T) {
+	var query url.Values
+	var patch map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "GET /api/v2/users/":
+			query = r.URL.Query()
+			w.Write([]byte(`{"count": 1, "next": null, "results": [{"id": 4, "username": "dana", "is_active": true}]}`))
+		case "PATCH /api/v2/findings/12/":
+			json.NewDecoder(r.Body).Decode(&patch)
+			w.Write([]byte(`{"id": 12, "reviewers": [4]}`))
+		case "GET /api/v2/findings/":
+			query = r.URL.Query()
+			w.Write([]byte(`{"count": 0, "results": []}`))
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(&config.DefectDojoConfig{BaseURL: server.URL, APIVersion: "v2"})
+	ctx := context.Background()
+
+	users, err := client.ListUsers(ctx, "dana", 1, 0)
+	if err != nil || users.Results[0].ID != 4 {
+		t.Fatalf("ListUsers() = %+v, %v", users, err)
+	}
+	if query.Get("username") != "dana" || query.Get("limit") != "1" {
+		t.Errorf("Unexpected users query %v", query)
+	}
+
+	finding, err := client.AssignFinding(ctx, 12, nil)
+	if err != nil || finding.Reviewers[0] != 4 {
+		t.Fatalf("AssignFinding() = %+v, %v", finding, err)
+	}
+	if reviewers, ok := patch["reviewers"].([]any); !ok || len(reviewers) != 0 {
+		t.Errorf("Expected unassigning to send an empty reviewers list, got %v", patch)
+	}
+
+	if _, err := client.GetFindings(ctx, types.FindingsFilter{Reviewers: []int{4, 9}}); err != nil {
+		t.Fatalf("GetFindings() error = %v", err)
+	}
+	if got := query["reviewers"]; !slices.Equal(got, []string{"4", "9"}) {
+		t.Errorf("Expected reviewers=4&reviewers=9, got %v", got)
+	}
+}
+
+func TestHTTPClient_ChangeSeverity(t *testing.T) {
+	var patch map[string]any
+	var note string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "GET /api/v2/findings/12/":
+			w.Write([]byte(`{"id": 12, "severity": "Critical", "numerical_severity": "S0", "modified": "2026-03-01T10:00:00Z"}`))
+		case "PATCH /api/v2/findings/12/":
+			json.NewDecoder(r.Body).Decode(&patch)
+			w.Write([]byte(`{"id": 12, "severity": "Medium", "numerical_severity": "S2"}`))
+		case "POST /api/v2/findings/12/notes/":
+			var body struct {
+				Entry string `json:"entry"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			note = body.Entry
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id": 88, "entry": "x"}`))
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(&config.DefectDojoConfig{BaseURL: server.URL, APIVersion: "v2"})
+	ctx := context.Background()
+
+	response, err := client.ChangeSeverity(ctx, 12, types.SeverityChangeRequest{Severity: "Medium", Justification: "Behind the VPN"})
+	if err != nil {
+		t.Fatalf("ChangeSeverity() error = %v", err)
+	}
+	if response.PreviousSeverity != "Critical" || response.Severity != "Medium" || response.NumericalSeverity != "S2" || response.NoteID != 88 {
+		t.Errorf("Unexpected response %+v", response)
+	}
+	if patch["severity"] != "Medium" || patch["numerical_severity"] != "S2" {
+		t.Errorf("Unexpected PATCH body %v", patch)
+	}
+	if note != "Severity changed from Critical to Medium: Behind the VPN" {
+		t.Errorf("Unexpected note %q", note)
+	}
+
+	patch = nil
+	stale := types.SeverityChangeRequest{Severity: "Low", Justification: "x", IfUnmodifiedSince: time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)}
+	var conflict *ConflictError
+	if _, err := client.ChangeSeverity(ctx, 12, stale); !errors.As(err, &conflict) {
+		t.Errorf("Expected a ConflictError, got %v", err)
+	}
+	if _, err := client.ChangeSeverity(ctx, 12, types.SeverityChangeRequest{Severity: "Urgent"}); err == nil {
+		t.Error("Expected an invalid severity to be refused")
+	}
+	if patch != nil {
+		t.Errorf("Expected no PATCH for refused changes, got %v", patch)
+	}
+}
+
+func TestHTTPClient_ImportScan(t *testing.T) {
+	var fields map[string][]string
+	var file, contentType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/api/v2/import-scan/" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		contentType = r.Header.Get("Content-Type")
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Fatalf("Expected a multipart body: %v", err)
+		}
+		fields = r.MultipartForm.Value
+		if upload, ok := r.MultipartForm.File["file"]; ok {
+			f, _ := upload[0].Open()
+			data, _ := io.ReadAll(f)
+			file = string(data)
+		}
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, `{"test_id": 55, "engagement_id": 10, "product_id": 1, "statistics": {"after": {"high": {"total": 2, "active": 2}, "total": {"total": 2, "active": 2}}}}`)
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(&config.DefectDojoConfig{BaseURL: server.URL, APIVersion: "v2"})
+	verified := true
+	request := types.ImportScanRequest{
+		ScanType:        "SARIF",
+		Engagement:      10,
+		TestTitle:       "Semgrep",
+		MinimumSeverity: "Low",
+		Verified:        &verified,
+		FileName:        "run-1.sarif",
+		File:            []byte(`{"version": "2.1.0", "runs": []}`),
+	}
+	response, err := client.ImportScan(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := map[string]string{"scan_type": "SARIF", "engagement": "10", "test_title": "Semgrep", "minimum_severity": "Low", "verified": "true"}
+	for name, value := range want {
+		if got := fields[name]; len(got) != 1 || got[0] != value {
+			t.Errorf("Expected field %s=%s, got %v", name, value, got)
+		}
+	}
+	for _, name := range []string{"active", "product_name", "auto_create_context"} {
+		if _, ok := fields[name]; ok {
+			t.Errorf("Expected field %s to be omitted", name)
+		}
+	}
+	if file != string(request.File) {
+		t.Errorf("Unexpected file content %q", file)
+	}
+	if response.Test != 55 || response.Statistics.After == nil || response.Statistics.After.High.Total != 2 {
+		t.Errorf("Unexpected response %+v", response)
+	}
+
+	// Identical imports must produce identical requests for record/replay
+	firstContentType := contentType
+	if _, err := client.ImportScan(context.Background(), request); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if contentType != firstContentType {
+		t.Errorf("Expected a stable multipart boundary, got %q then %q", firstContentType, contentType)
+	}
+}
+
+func TestHTTPClient_BaseURLNormalization(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		json.NewEncoder(w).Encode(types.Finding{ID: 1})
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name       string
+		baseURL    string
+		apiVersion string
+		wantPath   string
+	}{
+		{"trailing slash", server.URL + "/", "v2", "/api/v2/findings/1/"},
+		{"subpath", server.URL + "/defectdojo", "v2", "/defectdojo/api/v2/findings/1/"},
+		{"subpath with trailing slash", server.URL + "/defectdojo/", "v2", "/defectdojo/api/v2/findings/1/"},
+		{"api path included", server.URL + "/defectdojo/api/v2/", "v2", "/defectdojo/api/v2/findings/1/"},
+		{"slashed version", server.URL, "/v2/", "/api/v2/findings/1/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paths = nil
+			cfg := &config.DefectDojoConfig{BaseURL: tt.baseURL, APIVersion: tt.apiVersion}
+			client := NewHTTPClient(cfg)
+			if _, err := client.GetFindingDetail(context.Background(), 1); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(paths) != 1 || paths[0] != tt.wantPath {
+				t.Errorf("Expected request to %s, got %v", tt.wantPath, paths)
+			}
+			if cfg.BaseURL != tt.baseURL {
+				t.Errorf("Caller's config was modified: %q", cfg.BaseURL)
+			}
+		})
+	}
+}
+
 func TestHTTPClient_ContextCancellation(t *testing.T) {
 	// Test that context cancellation is properly handled
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -420,6 +1138,50 @@ func TestHTTPClient_ContextCancellation(t *testing.T) {
 	}
 }
 
+func TestHTTPClient_RequestTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		json.NewEncoder(w).Encode(types.FindingsResponse{})
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(&config.DefectDojoConfig{
+		BaseURL:        server.URL,
+		APIVersion:     "v2",
+		RequestTimeout: 50 * time.Millisecond,
+	})
+
+	// Without a caller deadline the configured RequestTimeout applies
+	if _, err := client.GetFindings(context.Background(), types.FindingsFilter{Limit: 1}); err == nil {
+		t.Error("expected request timeout error")
+	}
+
+	// A caller-supplied deadline takes precedence, allowing longer calls
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if _, err := client.GetFindings(ctx, types.FindingsFilter{Limit: 1}); err != nil {
+		t.Errorf("expected caller deadline to override RequestTimeout, got %v", err)
+	}
+}
+
+func TestHTTPClient_RequestIDHeader(t *testing.T) {
+	var got string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get(requestid.Header)
+		json.NewEncoder(w).Encode(types.Finding{ID: 1})
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(&config.DefectDojoConfig{BaseURL: server.URL, APIVersion: "v2", RequestTimeout: 5 * time.Second})
+	ctx := requestid.WithID(context.Background(), "abc123")
+	if _, err := client.GetFindingDetail(ctx, 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "abc123" {
+		t.Errorf("expected X-Request-ID abc123, got %q", got)
+	}
+}
+
 func TestHTTPClient_AuthenticationHeaders(t *testing.T) {
 	expectedAPIKey := "test-api-key-123"
 
@@ -450,3 +1212,91 @@ func TestHTTPClient_AuthenticationHeaders(t *testing.T) {
 		t.Errorf("Unexpected error: %v", err)
 	}
 }
+
+func TestHTTPClient_APIKeyOverride(t *testing.T) {
+	var got []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(types.Product{ID: 1})
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(&config.DefectDojoConfig{BaseURL: server.URL, APIKey: "default-key", APIVersion: "v2", RequestTimeout: 5 * time.Second})
+	for _, ctx := range []context.Context{context.Background(), WithAPIKey(context.Background(), "team-key"), WithAPIKey(context.Background(), "")} {
+		if _, err := client.GetProduct(ctx, 1); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if want := []string{"Token default-key", "Token team-key", ""}; !slices.Equal(got, want) {
+		t.Errorf("expected Authorization headers %q, got %q", want, got)
+	}
+}
+
+func TestHTTPClient_ListTestImports(t *testing.T) {
+	var query url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v2/test_imports/" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		query = r.URL.Query()
+		w.Write([]byte(`{"count": 1, "next": null, "results": [{"id": 8, "test": 101, "type": "reimport",
+			"test_import_finding_action_set": [{"finding": 3, "action": "U"}, {"finding": 6, "action": "C"}]}]}`))
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(&config.DefectDojoConfig{BaseURL: server.URL, APIVersion: "v2"})
+	imports, err := client.ListTestImports(context.Background(), 101, 5, 0)
+	if err != nil {
+		t.Fatalf("ListTestImports() error = %v", err)
+	}
+	if len(imports.Results) != 1 || imports.Results[0].Count(types.ImportActionClosed) != 1 {
+		t.Errorf("Unexpected imports %+v", imports)
+	}
+	if query.Get("test") != "101" || query.Get("limit") != "5" || query.Get("o") != "-id" {
+		t.Errorf("Unexpected test imports query %v", query)
+	}
+}
+
+func TestHTTPClient_SystemInfo(t *testing.T) {
+	announcement := `{"count": 1, "results": [{"id": 1, "message": "Upgrade on Saturday", "style": "warning", "dismissable": true}]}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v2/system_settings/":
+			w.Write([]byte(`{"count": 1, "results": [{"enable_deduplication": true, "max_dupes": 5, "disclaimer": "Internal use only"}]}`))
+		case "/api/v2/sla_configurations/":
+			w.Write([]byte(`{"count": 1, "next": null, "results": [{"id": 1, "name": "Default", "critical": 7, "high": 30, "medium": 90, "low": 120}]}`))
+		case "/api/v2/development_environments/":
+			w.Write([]byte(`{"count": 2, "next": null, "results": [{"id": 1, "name": "Development"}, {"id": 3, "name": "Production"}]}`))
+		case "/api/v2/announcements/":
+			w.Write([]byte(announcement))
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(&config.DefectDojoConfig{BaseURL: server.URL, APIVersion: "v2"})
+	ctx := context.Background()
+
+	settings, err := client.GetSystemSettings(ctx)
+	if err != nil || !settings.EnableDeduplication || settings.MaxDupes == nil || *settings.MaxDupes != 5 || settings.Disclaimer != "Internal use only" {
+		t.Errorf("GetSystemSettings() = %+v, %v", settings, err)
+	}
+	slas, err := client.ListSLAConfigurations(ctx, 25, 0)
+	if err != nil || len(slas.Results) != 1 || slas.Results[0].High != 30 {
+		t.Errorf("ListSLAConfigurations() = %+v, %v", slas, err)
+	}
+	if envs, err := client.ListDevelopmentEnvironments(ctx, 25, 0); err != nil || len(envs.Results) != 2 || envs.Results[1].Name != "Production" {
+		t.Errorf("ListDevelopmentEnvironments() = %+v, %v", envs, err)
+	}
+	if banner, err := client.GetAnnouncement(ctx); err != nil || banner == nil || banner.Message != "Upgrade on Saturday" {
+		t.Errorf("GetAnnouncement() = %+v, %v", banner, err)
+	}
+
+	announcement = `{"count": 0, "results": []}`
+	if banner, err := client.GetAnnouncement(ctx); err != nil || banner != nil {
+		t.Errorf("expected no announcement, got %+v, %v", banner, err)
+	}
+}
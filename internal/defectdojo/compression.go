@@ -0,0 +1,124 @@
+package defectdojo
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// TransferStats measures DefectDojo response bodies since the client was created
+type TransferStats struct {
+	Responses           int64 `json:"responses"`            // Response bodies read
+	CompressedResponses int64 `json:"compressed_responses"` // Of which gzip-encoded
+	WireBytes           int64 `json:"wire_bytes"`           // Bytes received over the network
+	DecodedBytes        int64 `json:"decoded_bytes"`        // Bytes after decompression
+}
+
+// Ratio returns how many times smaller the transfer was thanks to compression,
+// or 1 if nothing was received
+func (s TransferStats) Ratio() float64 {
+	if s.WireBytes == 0 {
+		return 1
+	}
+	return float64(s.DecodedBytes) / float64(s.WireBytes)
+}
+
+// compressionTransport asks DefectDojo for gzip-encoded responses and
+// decompresses them itself, counting the bytes before and after so the gain
+// can be measured. It sits below the traffic dump and cassette recorder, which
+// therefore see plain bodies.
+type compressionTransport struct {
+	next http.RoundTripper
+
+	responses           atomic.Int64
+	compressedResponses atomic.Int64
+	wireBytes           atomic.Int64
+	decodedBytes        atomic.Int64
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *compressionTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("Accept-Encoding") != "" || req.Header.Get("Range") != "" {
+		return t.next.RoundTrip(req)
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set("Accept-Encoding", "gzip")
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	wire := &countingReader{r: resp.Body}
+	body := &measuredBody{transport: t, wire: wire, decoded: wire, closer: resp.Body}
+	if strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") && resp.StatusCode != http.StatusNoContent && req.Method != http.MethodHead {
+		gz, err := gzip.NewReader(wire)
+		if err != nil {
+			resp.Body.Close()
+			return nil, fmt.Errorf("decompressing response: %w", err)
+		}
+		body.decoded = &countingReader{r: gz}
+		body.gzip = true
+		resp.Header.Del("Content-Encoding")
+		resp.Header.Del("Content-Length")
+		resp.ContentLength = -1
+		resp.Uncompressed = true
+	}
+	resp.Body = body
+	return resp, nil
+}
+
+// Stats returns the transfer measurements so far
+func (t *compressionTransport) Stats() TransferStats {
+	return TransferStats{
+		Responses:           t.responses.Load(),
+		CompressedResponses: t.compressedResponses.Load(),
+		WireBytes:           t.wireBytes.Load(),
+		DecodedBytes:        t.decodedBytes.Load(),
+	}
+}
+
+// measuredBody is a response body that adds its byte counts to the transport's
+// statistics when closed
+type measuredBody struct {
+	transport *compressionTransport
+	wire      *countingReader
+	decoded   io.Reader // wire, or a counting gzip reader over it
+	closer    io.Closer
+	gzip      bool
+	once      sync.Once
+}
+
+func (b *measuredBody) Read(p []byte) (int, error) {
+	return b.decoded.Read(p)
+}
+
+func (b *measuredBody) Close() error {
+	b.once.Do(func() {
+		decoded := b.wire.n
+		if b.gzip {
+			decoded = b.decoded.(*countingReader).n
+			b.transport.compressedResponses.Add(1)
+		}
+		b.transport.responses.Add(1)
+		b.transport.wireBytes.Add(b.wire.n)
+		b.transport.decodedBytes.Add(decoded)
+	})
+	return b.closer.Close()
+}
+
+// countingReader counts the bytes read through it
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
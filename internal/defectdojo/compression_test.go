@@ -0,0 +1,71 @@
+package defectdojo
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brduru/mcp-defect-dojo/internal/config"
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+func TestHTTPClient_Compression(t *testing.T) {
+	title := strings.Repeat("SQL injection in login form ", 200)
+	body := `{"count":1,"results":[{"id":1,"title":"` + title + `"}]}`
+	var acceptEncoding string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		acceptEncoding = r.Header.Get("Accept-Encoding")
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("severity") == "Low" {
+			w.Write([]byte(body)) // A server that ignores Accept-Encoding
+			return
+		}
+		w.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		gz.Write([]byte(body))
+		gz.Close()
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(&config.DefectDojoConfig{
+		BaseURL:        server.URL,
+		APIKey:         "key",
+		APIVersion:     "v2",
+		RequestTimeout: 5 * time.Second,
+		DumpTraffic:    true,
+	})
+	var dump bytes.Buffer
+	client.dump.out = &dump
+
+	response, err := client.GetFindings(context.Background(), types.FindingsFilter{Limit: 1, Severity: "High"})
+	if err != nil {
+		t.Fatalf("GetFindings() error = %v", err)
+	}
+	if acceptEncoding != "gzip" {
+		t.Errorf("Accept-Encoding = %q, want gzip", acceptEncoding)
+	}
+	if len(response.Results) != 1 || response.Results[0].Title != title {
+		t.Fatal("compressed response was not decoded")
+	}
+	if !strings.Contains(dump.String(), "SQL injection in login form") {
+		t.Error("traffic dump should show the decompressed body")
+	}
+
+	stats := client.TransferStats()
+	if stats.Responses != 1 || stats.CompressedResponses != 1 || stats.DecodedBytes != int64(len(body)) || stats.Ratio() < 10 {
+		t.Errorf("unexpected transfer stats %+v (ratio %.1f)", stats, stats.Ratio())
+	}
+
+	if _, err := client.GetFindings(context.Background(), types.FindingsFilter{Limit: 1, Severity: "Low"}); err != nil {
+		t.Fatalf("GetFindings() error = %v", err)
+	}
+	stats = client.TransferStats()
+	if stats.Responses != 2 || stats.CompressedResponses != 1 || stats.DecodedBytes != 2*int64(len(body)) {
+		t.Errorf("unexpected transfer stats after a plain response %+v", stats)
+	}
+}
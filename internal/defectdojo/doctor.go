@@ -0,0 +1,166 @@
+package defectdojo
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// Doctor thresholds
+const (
+	doctorLatencySamples = 3
+	slowLatency          = time.Second      // Median API latency worth a warning
+	clockSkewWarn        = 30 * time.Second // Skew worth a warning
+	clockSkewFail        = 5 * time.Minute  // Skew that breaks time-based filters
+)
+
+// remediations are the fixes suggested for failed or warning checks, by check name
+var remediations = map[string]string{
+	"URL":              "Set DEFECTDOJO_URL to the DefectDojo base URL, e.g. https://defectdojo.example.com",
+	"Connectivity":     "Check DNS, proxies and firewall rules between this host and DefectDojo, e.g. with curl from this host",
+	"API version":      "Set DEFECTDOJO_API_VERSION to a version both this server and DefectDojo support, normally v2",
+	"API path":         "Set DEFECTDOJO_URL to the base URL without /api/v2 and DEFECTDOJO_API_VERSION to v2",
+	"Authentication":   "Create a token in DefectDojo (user menu > API v2 Key) and set DEFECTDOJO_API_KEY",
+	"Version":          "Allow the token to read /api/v2/oa3/schema/; without a version, version-gated tools assume the newest release",
+	"Write permission": "Give the token's user a Writer, Maintainer or Owner role where agents may write, or run with --read-only",
+	"Latency":          "Check DefectDojo's load and the network path; pass timeout_seconds to tools that run large queries",
+	"Clock skew":       "Synchronize this host's clock with NTP; findings digests, SLA ages and audit timestamps rely on it",
+}
+
+// Doctor runs SelfCheck, then, unless the URL or API version is unusable,
+// verifies the API path, measures API latency and compares the local clock
+// with DefectDojo's Date headers. Every failed or warning check carries a
+// remediation hint.
+func (c *HTTPClient) Doctor(ctx context.Context) *SelfCheckReport {
+	report := c.SelfCheck(ctx)
+	if report.Checks[0].Status == CheckFail || report.Checks[1].Status == CheckFail {
+		report.Checks = append(report.Checks, skippedChecks("API path", "Latency", "Clock skew")...)
+	} else {
+		report.Checks = append(report.Checks, c.doctorProbes(ctx)...)
+	}
+
+	for i, check := range report.Checks {
+		if check.Status == CheckFail || check.Status == CheckWarn {
+			report.Checks[i].Hint = remediations[check.Name]
+		}
+	}
+	return report
+}
+
+// doctorProbes requests the API root a few times to check the API path,
+// latency and clock skew
+func (c *HTTPClient) doctorProbes(ctx context.Context) []CheckResult {
+	var latencies []time.Duration
+	var skew time.Duration
+	status := 0
+	for range doctorLatencySamples {
+		probe, err := c.probeAPIRoot(ctx)
+		if err != nil {
+			// Unreachable: Connectivity already reports it, or Authentication does without a key
+			results := skippedChecks("API path", "Latency", "Clock skew")
+			results[0].Detail = fmt.Sprintf("cannot request %s: %v", c.apiURL("/"), err)
+			return results
+		}
+		status = probe.status
+		latencies = append(latencies, probe.latency)
+		skew = probe.skew
+	}
+
+	var results []CheckResult
+	// 401 and 403 still prove the API lives here; the token is checked separately
+	switch status {
+	case http.StatusOK, http.StatusUnauthorized, http.StatusForbidden:
+		results = append(results, CheckResult{Name: "API path", Status: CheckPass, Detail: c.apiURL("/") + " is a DefectDojo API"})
+	default:
+		results = append(results, CheckResult{Name: "API path", Status: CheckFail, Detail: fmt.Sprintf("%s answered with status %d", c.apiURL("/"), status)})
+	}
+
+	slices.Sort(latencies)
+	median := latencies[len(latencies)/2]
+	detail := fmt.Sprintf("median %s over %d requests (min %s, max %s)", median.Round(time.Millisecond), len(latencies),
+		latencies[0].Round(time.Millisecond), latencies[len(latencies)-1].Round(time.Millisecond))
+	if median > slowLatency {
+		results = append(results, CheckResult{Name: "Latency", Status: CheckWarn, Detail: detail + "; tool calls will be slow"})
+	} else {
+		results = append(results, CheckResult{Name: "Latency", Status: CheckPass, Detail: detail})
+	}
+
+	results = append(results, clockSkewCheck(skew))
+	return results
+}
+
+// skippedChecks marks checks skipped because a prerequisite failed
+func skippedChecks(names ...string) []CheckResult {
+	results := make([]CheckResult, 0, len(names))
+	for _, name := range names {
+		results = append(results, CheckResult{Name: name, Status: CheckSkip, Detail: "skipped due to previous failure"})
+	}
+	return results
+}
+
+// clockSkewCheck grades the difference between the local and DefectDojo clocks.
+// Date headers have one-second resolution, so smaller skews are not reported.
+func clockSkewCheck(skew time.Duration) CheckResult {
+	if skew == clockSkewUnknown {
+		return CheckResult{Name: "Clock skew", Status: CheckWarn, Detail: "DefectDojo sent no usable Date header"}
+	}
+	magnitude := skew.Abs().Round(time.Second)
+	direction := "ahead of"
+	if skew < 0 {
+		direction = "behind"
+	}
+	switch {
+	case magnitude > clockSkewFail:
+		return CheckResult{Name: "Clock skew", Status: CheckFail, Detail: fmt.Sprintf("local clock is %s %s DefectDojo", magnitude, direction)}
+	case magnitude > clockSkewWarn:
+		return CheckResult{Name: "Clock skew", Status: CheckWarn, Detail: fmt.Sprintf("local clock is %s %s DefectDojo", magnitude, direction)}
+	case magnitude >= 2*time.Second:
+		return CheckResult{Name: "Clock skew", Status: CheckPass, Detail: fmt.Sprintf("local clock is %s %s DefectDojo", magnitude, direction)}
+	default:
+		return CheckResult{Name: "Clock skew", Status: CheckPass, Detail: "local clock matches DefectDojo"}
+	}
+}
+
+// clockSkewUnknown marks a response without a usable Date header
+const clockSkewUnknown = time.Duration(-1 << 63)
+
+// rootProbe is the outcome of one API root request
+type rootProbe struct {
+	status  int
+	latency time.Duration
+	skew    time.Duration // Local clock minus DefectDojo's, or clockSkewUnknown
+}
+
+// probeAPIRoot requests the API root and times it. The skew compares the
+// Date header with the local time halfway through the request.
+func (c *HTTPClient) probeAPIRoot(ctx context.Context) (rootProbe, error) {
+	if c.apiErr != nil {
+		return rootProbe{}, c.apiErr
+	}
+	ctx, cancel := c.withDefaultTimeout(ctx)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", c.apiURL("/"), nil)
+	if err != nil {
+		return rootProbe{}, err
+	}
+	c.setHeaders(req)
+
+	start := time.Now()
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return rootProbe{}, err
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	latency := time.Since(start)
+
+	probe := rootProbe{status: resp.StatusCode, latency: latency, skew: clockSkewUnknown}
+	if date, err := http.ParseTime(resp.Header.Get("Date")); err == nil {
+		probe.skew = start.Add(latency / 2).Sub(date)
+	}
+	return probe, nil
+}
@@ -0,0 +1,113 @@
+package defectdojo
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/brduru/mcp-defect-dojo/internal/config"
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+func TestHTTPClient_Doctor(t *testing.T) {
+	tests := []struct {
+		name       string
+		apiVersion string
+		clock      time.Duration // DefectDojo's clock offset from ours
+		wantOK     bool
+		wantStatus map[string]CheckStatus
+	}{
+		{
+			name:       "healthy instance",
+			apiVersion: "v2",
+			wantOK:     true,
+			wantStatus: map[string]CheckStatus{"API path": CheckPass, "Latency": CheckPass, "Clock skew": CheckPass},
+		},
+		{
+			name:       "clock drift warns",
+			apiVersion: "v2",
+			clock:      -2 * time.Minute,
+			wantOK:     true,
+			wantStatus: map[string]CheckStatus{"Clock skew": CheckWarn},
+		},
+		{
+			name:       "large clock drift fails",
+			apiVersion: "v2",
+			clock:      time.Hour,
+			wantOK:     false,
+			wantStatus: map[string]CheckStatus{"Clock skew": CheckFail},
+		},
+		{
+			name:       "unsupported API version",
+			apiVersion: "v1",
+			wantOK:     false,
+			wantStatus: map[string]CheckStatus{"API version": CheckFail, "Connectivity": CheckSkip, "API path": CheckSkip},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Date", time.Now().Add(tt.clock).UTC().Format(http.TimeFormat))
+				switch r.URL.Path {
+				case "/api/v2/":
+					json.NewEncoder(w).Encode(map[string]string{"findings": "/api/v2/findings/"})
+				case "/api/v2/user_profile/":
+					json.NewEncoder(w).Encode(types.UserProfile{User: types.User{Username: "admin", IsSuperuser: true}})
+				case "/api/v2/oa3/schema/":
+					json.NewEncoder(w).Encode(map[string]any{"info": map[string]any{"version": "2.38.1"}})
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+
+			client := NewHTTPClient(&config.DefectDojoConfig{
+				BaseURL:        server.URL,
+				APIKey:         "key",
+				APIVersion:     tt.apiVersion,
+				RequestTimeout: 5 * time.Second,
+			})
+
+			report := client.Doctor(context.Background())
+			if report.OK() != tt.wantOK {
+				t.Errorf("OK() = %v, want %v\n%s", report.OK(), tt.wantOK, report)
+			}
+			got := map[string]CheckResult{}
+			for _, check := range report.Checks {
+				got[check.Name] = check
+			}
+			for name, want := range tt.wantStatus {
+				if got[name].Status != want {
+					t.Errorf("check %q = %q, want %q\n%s", name, got[name].Status, want, report)
+				}
+				if (want == CheckFail || want == CheckWarn) && got[name].Hint == "" {
+					t.Errorf("check %q has no remediation hint", name)
+				}
+			}
+		})
+	}
+}
+
+func TestHTTPClient_DoctorUnreachable(t *testing.T) {
+	client := NewHTTPClient(&config.DefectDojoConfig{BaseURL: "http://127.0.0.1:1", APIKey: "key", APIVersion: "v2", RequestTimeout: time.Second})
+	report := client.Doctor(context.Background())
+	if report.OK() {
+		t.Fatalf("expected a failing report\n%s", report)
+	}
+	for _, check := range report.Checks {
+		switch check.Name {
+		case "Connectivity":
+			if check.Status != CheckFail || check.Hint == "" {
+				t.Errorf("unexpected connectivity check %+v", check)
+			}
+		case "API path", "Latency", "Clock skew":
+			if check.Status != CheckSkip {
+				t.Errorf("expected %s to be skipped, got %+v", check.Name, check)
+			}
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package defectdojo
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/brduru/mcp-defect-dojo/internal/logfields"
+)
+
+// maxDumpBodyBytes caps how much of each request/response body is dumped
+const maxDumpBodyBytes = 4096
+
+// sensitiveHeaders are redacted from traffic dumps
+var sensitiveHeaders = map[string]bool{
+	"Authorization": true,
+	"Cookie":        true,
+	"Set-Cookie":    true,
+	"X-Api-Key":     true,
+}
+
+// dumpTransport writes sanitized DefectDojo requests and responses to a writer.
+// Dumping can be switched on and off at runtime without recreating the client.
+type dumpTransport struct {
+	next    http.RoundTripper
+	enabled atomic.Bool
+
+	mu   sync.Mutex
+	out  io.Writer
+	file *os.File // The DumpFile opened for out, closed by HTTPClient.Close
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *dumpTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !t.enabled.Load() {
+		return t.next.RoundTrip(req)
+	}
+
+	var reqBody []byte
+	if req.Body != nil && req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			reqBody, _ = io.ReadAll(body)
+			body.Close()
+		}
+	}
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	elapsed := time.Since(start)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- DefectDojo %s %s", req.Method, req.URL.Redacted())
+	if fields := logfields.FromContext(req.Context()).String(); fields != "" {
+		fmt.Fprintf(&b, " [%s]", fields)
+	}
+	b.WriteString("\n")
+	writeHeaders(&b, req.Header)
+	writeBody(&b, reqBody)
+
+	if err != nil {
+		fmt.Fprintf(&b, "<<< error after %s: %v\n", elapsed.Round(time.Millisecond), err)
+		t.write(b.String())
+		return nil, err
+	}
+
+	// Buffer the response so it can be dumped and still decoded by the caller
+	respBody, readErr := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	fmt.Fprintf(&b, "<<< %s in %s\n", resp.Status, elapsed.Round(time.Millisecond))
+	writeHeaders(&b, resp.Header)
+	writeBody(&b, respBody)
+	t.write(b.String())
+
+	if readErr != nil {
+		return nil, readErr
+	}
+	return resp, nil
+}
+
+func (t *dumpTransport) write(s string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	io.WriteString(t.out, s)
+}
+
+// writeHeaders writes headers in a stable order with credentials redacted
+func writeHeaders(b *strings.Builder, header http.Header) {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		value := strings.Join(header[name], ", ")
+		if sensitiveHeaders[http.CanonicalHeaderKey(name)] {
+			value = "[REDACTED]"
+		}
+		fmt.Fprintf(b, "    %s: %s\n", name, value)
+	}
+}
+
+// writeBody writes a body truncated to maxDumpBodyBytes
+func writeBody(b *strings.Builder, body []byte) {
+	if len(body) == 0 {
+		return
+	}
+	if len(body) > maxDumpBodyBytes {
+		fmt.Fprintf(b, "%s\n... [truncated %d bytes]\n", body[:maxDumpBodyBytes], len(body)-maxDumpBodyBytes)
+		return
+	}
+	fmt.Fprintf(b, "%s\n", body)
+}
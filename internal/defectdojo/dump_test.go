@@ -0,0 +1,101 @@
+package defectdojo
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brduru/mcp-defect-dojo/internal/config"
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+func TestHTTPClient_TrafficDump(t *testing.T) {
+	longTitle := strings.Repeat("x", maxDumpBodyBytes+100)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"count":1,"results":[{"id":1,"title":"` + longTitle + `"}]}`))
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(&config.DefectDojoConfig{
+		BaseURL:        server.URL,
+		APIKey:         "super-secret-key",
+		APIVersion:     "v2",
+		RequestTimeout: 5 * time.Second,
+		DumpTraffic:    true,
+	})
+	var out bytes.Buffer
+	client.dump.out = &out
+
+	response, err := client.GetFindings(context.Background(), types.FindingsFilter{Limit: 1, Severity: "High"})
+	if err != nil {
+		t.Fatalf("GetFindings() error = %v", err)
+	}
+	if len(response.Results) != 1 || response.Results[0].Title != longTitle {
+		t.Error("response body was not preserved after dumping")
+	}
+
+	dump := out.String()
+	for _, want := range []string{"GET", "severity=High", "200 OK", "[REDACTED]", "[truncated"} {
+		if !strings.Contains(dump, want) {
+			t.Errorf("expected dump to contain %q:\n%s", want, dump)
+		}
+	}
+	if strings.Contains(dump, "super-secret-key") {
+		t.Error("API key leaked into traffic dump")
+	}
+
+	t.Run("toggle off at runtime", func(t *testing.T) {
+		out.Reset()
+		client.SetTrafficDump(false)
+		if client.TrafficDumpEnabled() {
+			t.Error("expected dump to be disabled")
+		}
+		if _, err := client.GetFindings(context.Background(), types.FindingsFilter{Limit: 1}); err != nil {
+			t.Fatalf("GetFindings() error = %v", err)
+		}
+		if out.Len() != 0 {
+			t.Errorf("expected no dump output, got %q", out.String())
+		}
+	})
+}
+
+func TestHTTPClient_DumpFile(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"count":0,"results":[]}`))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "dojo-traffic.log")
+	client := NewHTTPClient(&config.DefectDojoConfig{
+		BaseURL:        server.URL,
+		APIKey:         "test-key",
+		APIVersion:     "v2",
+		RequestTimeout: 5 * time.Second,
+		DumpTraffic:    true,
+		DumpFile:       path,
+	})
+	if _, err := client.GetFindings(context.Background(), types.FindingsFilter{Limit: 1}); err != nil {
+		t.Fatalf("GetFindings() error = %v", err)
+	}
+	file := client.dump.file
+	if err := client.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if file == nil || file.Close() == nil {
+		t.Error("expected Close to close the dump file")
+	}
+	if dump, err := os.ReadFile(path); err != nil || !strings.Contains(string(dump), "200 OK") {
+		t.Errorf("expected the dump in %s, got %q, %v", path, dump, err)
+	}
+	if err := client.Close(); err != nil {
+		t.Errorf("expected a second Close to do nothing, got %v", err)
+	}
+}
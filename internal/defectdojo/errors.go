@@ -0,0 +1,99 @@
+package defectdojo
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/brduru/mcp-defect-dojo/internal/logfields"
+	"github.com/brduru/mcp-defect-dojo/internal/requestid"
+)
+
+// Error detail levels of failed DefectDojo responses
+const (
+	ErrorDetailFull      = "full"      // Return response bodies as they are (the default)
+	ErrorDetailSanitized = "sanitized" // Log bodies under the request ID and return a summary
+)
+
+// Sanitized error summary sizing
+const (
+	maxErrorMessages  = 5    // Validation messages kept per summary
+	maxErrorMessage   = 200  // Characters kept per validation message
+	maxLoggedErrorLen = 4096 // Characters of a withheld body written to the log
+)
+
+// apiError builds the error of a failed response. With sanitized error
+// details, the body, which can name internal hosts or carry a stack trace,
+// is logged under the call's request ID, and the error holds a summary for
+// agents instead: the status, plus DefectDojo's validation messages for
+// client errors, which say what to fix without revealing the server.
+func (c *HTTPClient) apiError(ctx context.Context, status int, body []byte) *APIError {
+	if c.config.ErrorDetail != ErrorDetailSanitized {
+		return &APIError{StatusCode: status, Body: string(body)}
+	}
+	id := requestid.FromContext(ctx)
+	logged := string(body)
+	if len(logged) > maxLoggedErrorLen {
+		logged = logged[:maxLoggedErrorLen] + "..."
+	}
+	logfields.Printf(ctx, "DefectDojo error response %d: %s", status, logged)
+
+	summary := cmp.Or(http.StatusText(status), "Error")
+	if status < http.StatusInternalServerError {
+		if messages := validationMessages(body); len(messages) > 0 {
+			summary += ": " + strings.Join(messages, "; ")
+		}
+	}
+	if id == "" {
+		return &APIError{StatusCode: status, Body: summary + " (details withheld, see the server log)"}
+	}
+	return &APIError{StatusCode: status, Body: fmt.Sprintf("%s (details withheld, see the server log for request_id %s)", summary, id)}
+}
+
+// validationMessages extracts the messages of a Django REST framework error
+// body: {"detail": "..."}, {"field": ["...", ...]} or ["...", ...]. Anything
+// else yields none.
+func validationMessages(body []byte) []string {
+	var messages []string
+	add := func(field string, value any) {
+		var texts []string
+		switch value := value.(type) {
+		case string:
+			texts = []string{value}
+		case []any:
+			for _, item := range value {
+				if text, ok := item.(string); ok {
+					texts = append(texts, text)
+				}
+			}
+		}
+		for _, text := range texts {
+			if len(text) > maxErrorMessage {
+				text = text[:maxErrorMessage] + "..."
+			}
+			if field != "" && field != "detail" && field != "non_field_errors" {
+				text = field + ": " + text
+			}
+			messages = append(messages, text)
+		}
+	}
+
+	var decoded any
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		return nil
+	}
+	switch decoded := decoded.(type) {
+	case map[string]any:
+		for _, field := range slices.Sorted(maps.Keys(decoded)) {
+			add(field, decoded[field])
+		}
+	case []any:
+		add("", decoded)
+	}
+	return messages[:min(len(messages), maxErrorMessages)]
+}
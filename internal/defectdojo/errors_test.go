@@ -0,0 +1,78 @@
+package defectdojo
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brduru/mcp-defect-dojo/internal/config"
+	"github.com/brduru/mcp-defect-dojo/internal/requestid"
+)
+
+func TestErrorDetail(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v2/findings/1/":
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte("<html>Traceback at db-internal.corp:5432</html>"))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"entry":["This field may not be blank."],"non_field_errors":["Finding is closed."]}`))
+		}
+	}))
+	defer server.Close()
+	ctx := requestid.WithID(context.Background(), "abc123")
+
+	full := NewHTTPClient(&config.DefectDojoConfig{BaseURL: server.URL, APIVersion: "v2", RequestTimeout: 5 * time.Second})
+	_, err := full.GetFindingDetail(ctx, 1)
+	if err == nil || !strings.Contains(err.Error(), "db-internal.corp") {
+		t.Errorf("expected the full body by default, got %v", err)
+	}
+
+	var logged bytes.Buffer
+	defer log.SetOutput(log.Writer())
+	log.SetOutput(&logged)
+	sanitized := NewHTTPClient(&config.DefectDojoConfig{BaseURL: server.URL, APIVersion: "v2", RequestTimeout: 5 * time.Second, ErrorDetail: ErrorDetailSanitized})
+
+	_, err = sanitized.GetFindingDetail(ctx, 1)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("expected an APIError with the status, got %v", err)
+	}
+	if want := "Internal Server Error (details withheld, see the server log for request_id abc123)"; apiErr.Body != want {
+		t.Errorf("Body = %q, want %q", apiErr.Body, want)
+	}
+	if !strings.Contains(logged.String(), "<html>Traceback at db-internal.corp:5432</html> [request_id=abc123]") {
+		t.Errorf("expected the body in the log, got %q", logged.String())
+	}
+
+	_, err = sanitized.AddFindingNote(context.Background(), 2, "")
+	if want := "Bad Request: entry: This field may not be blank.; Finding is closed. (details withheld, see the server log)"; !errors.As(err, &apiErr) || apiErr.Body != want {
+		t.Errorf("expected the validation messages, got %v", err)
+	}
+}
+
+func TestValidationMessages(t *testing.T) {
+	tests := []struct {
+		body string
+		want []string
+	}{
+		{`{"detail":"Not found."}`, []string{"Not found."}},
+		{`["Duplicate finding."]`, []string{"Duplicate finding."}},
+		{`{"name":["already exists."],"prod_type":["Invalid pk \"3\"."]}`, []string{"name: already exists.", `prod_type: Invalid pk "3".`}},
+		{`{"nested":{"field":"ignored"}}`, nil},
+		{`<html>Server Error</html>`, nil},
+	}
+	for _, tt := range tests {
+		got := validationMessages([]byte(tt.body))
+		if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("validationMessages(%s) = %q, want %q", tt.body, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,1109 @@
+package defectdojo
+
+import (
+	"cmp"
+	"context"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/brduru/mcp-defect-dojo/internal/config"
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+// Connection modes selected by DefectDojoConfig.Mode
+const (
+	ModeLive    = "live"    // Talk to a DefectDojo instance over HTTP
+	ModeOffline = "offline" // Serve canned data from fixture files
+)
+
+// NewClient returns the client selected by cfg.Mode: a FixtureClient in
+// offline mode, an HTTPClient otherwise. In record and replay modes the
+// HTTPClient saves responses to, or serves them from, cfg.CassetteDir.
+func NewClient(cfg *config.DefectDojoConfig) (Client, error) {
+	if cfg.Mode == ModeOffline {
+		return NewFixtureClient(cfg.FixturesDir)
+	}
+	return NewHTTPClient(cfg), nil
+}
+
+// demoFixtures is the dataset served in offline mode when no directory is configured
+//
+//go:embed fixtures/demo/*.json
+var demoFixtures embed.FS
+
+// FixtureClient implements Client from JSON fixture files instead of a live
+// instance, for demos, prompt development and CI tests of downstream agents.
+//
+// A fixture directory holds findings.json plus optional tests.json,
+// test_types.json, engagements.json, products.json, product_types.json,
+// endpoints.json, technologies.json, notes.json, risk_acceptances.json,
+// users.json, test_imports.json, development_environments.json,
+// system_settings.json, sla_configurations.json and announcements.json.
+// Each file contains either a JSON array of objects or a DefectDojo list
+// response ({"count": ..., "results": [...]}), so captured API output can be
+// used as is.
+//
+// Findings are filtered, ordered and paginated in memory. Writes such as
+// MarkFalsePositive change the in-memory copy only.
+type FixtureClient struct {
+	source string
+
+	mu          sync.Mutex
+	findings    []types.Finding
+	tests       map[int]types.Test
+	testTypes   map[int]types.TestType
+	engagements map[int]types.Engagement
+	products    map[int]types.Product
+	prodTypes   map[int]types.ProductType
+	endpoints   map[int]types.Endpoint
+	techs       map[int]types.Technology
+	notes       map[int]types.Note
+	risks       map[int]types.RiskAcceptance
+	users       map[int]types.User
+	imports     map[int]types.TestImport
+	envs        map[int]types.DevelopmentEnvironment
+	settings    []types.SystemSettings
+	slas        map[int]types.SLAConfiguration
+	banners     []types.Announcement
+	metadata    map[int][]types.FindingMetadata
+	nextNoteID  int
+	nextMetaID  int
+}
+
+// NewFixtureClient loads fixtures from dir, or the built-in demo dataset when dir is empty.
+func NewFixtureClient(dir string) (*FixtureClient, error) {
+	fsys, source := fs.FS(nil), dir
+	if dir == "" {
+		sub, err := fs.Sub(demoFixtures, "fixtures/demo")
+		if err != nil {
+			return nil, err
+		}
+		fsys, source = sub, "built-in demo data"
+	} else {
+		fsys = os.DirFS(dir)
+	}
+
+	c := &FixtureClient{source: source, metadata: map[int][]types.FindingMetadata{}, nextNoteID: 1, nextMetaID: 1}
+	if err := loadFixture(fsys, "findings.json", true, &c.findings); err != nil {
+		return nil, err
+	}
+
+	var tests []types.Test
+	var testTypes []types.TestType
+	var engagements []types.Engagement
+	var products []types.Product
+	var prodTypes []types.ProductType
+	var endpoints []types.Endpoint
+	var techs []types.Technology
+	var notes []types.Note
+	var risks []types.RiskAcceptance
+	var users []types.User
+	var imports []types.TestImport
+	var envs []types.DevelopmentEnvironment
+	var slas []types.SLAConfiguration
+	if err := errors.Join(
+		loadFixture(fsys, "tests.json", false, &tests),
+		loadFixture(fsys, "test_types.json", false, &testTypes),
+		loadFixture(fsys, "engagements.json", false, &engagements),
+		loadFixture(fsys, "products.json", false, &products),
+		loadFixture(fsys, "product_types.json", false, &prodTypes),
+		loadFixture(fsys, "endpoints.json", false, &endpoints),
+		loadFixture(fsys, "technologies.json", false, &techs),
+		loadFixture(fsys, "notes.json", false, &notes),
+		loadFixture(fsys, "risk_acceptances.json", false, &risks),
+		loadFixture(fsys, "users.json", false, &users),
+		loadFixture(fsys, "test_imports.json", false, &imports),
+		loadFixture(fsys, "development_environments.json", false, &envs),
+		loadFixture(fsys, "system_settings.json", false, &c.settings),
+		loadFixture(fsys, "sla_configurations.json", false, &slas),
+		loadFixture(fsys, "announcements.json", false, &c.banners),
+	); err != nil {
+		return nil, err
+	}
+	c.tests = indexByID(tests, func(t types.Test) int { return t.ID })
+	c.testTypes = indexByID(testTypes, func(t types.TestType) int { return t.ID })
+	c.engagements = indexByID(engagements, func(e types.Engagement) int { return e.ID })
+	c.products = indexByID(products, func(p types.Product) int { return p.ID })
+	c.prodTypes = indexByID(prodTypes, func(t types.ProductType) int { return t.ID })
+	c.endpoints = indexByID(endpoints, func(e types.Endpoint) int { return e.ID })
+	c.techs = indexByID(techs, func(t types.Technology) int { return t.ID })
+	c.notes = indexByID(notes, func(n types.Note) int { return n.ID })
+	c.risks = indexByID(risks, func(r types.RiskAcceptance) int { return r.ID })
+	c.users = indexByID(users, func(u types.User) int { return u.ID })
+	c.imports = indexByID(imports, func(i types.TestImport) int { return i.ID })
+	c.envs = indexByID(envs, func(e types.DevelopmentEnvironment) int { return e.ID })
+	c.slas = indexByID(slas, func(s types.SLAConfiguration) int { return s.ID })
+
+	return c, nil
+}
+
+// loadFixture decodes a fixture file holding an array or a paginated list response
+func loadFixture[T any](fsys fs.FS, name string, required bool, out *[]T) error {
+	data, err := fs.ReadFile(fsys, name)
+	if errors.Is(err, fs.ErrNotExist) && !required {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("reading fixture %s: %w", name, err)
+	}
+
+	if trimmed := strings.TrimSpace(string(data)); strings.HasPrefix(trimmed, "{") {
+		var page struct {
+			Results []T `json:"results"`
+		}
+		if err := json.Unmarshal(data, &page); err != nil {
+			return fmt.Errorf("parsing fixture %s: %w", name, err)
+		}
+		*out = page.Results
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("parsing fixture %s: %w", name, err)
+	}
+	return nil
+}
+
+func indexByID[T any](items []T, id func(T) int) map[int]T {
+	index := make(map[int]T, len(items))
+	for _, item := range items {
+		index[id(item)] = item
+	}
+	return index
+}
+
+// notFound mirrors DefectDojo's response for a missing object
+func notFound() error {
+	return &APIError{StatusCode: http.StatusNotFound, Body: `{"detail":"Not found."}`}
+}
+
+// GetFindings filters, orders and paginates the fixture findings
+func (c *FixtureClient) GetFindings(ctx context.Context, filter types.FindingsFilter) (*types.FindingsResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var matched []types.Finding
+	for _, finding := range c.findings {
+		if c.matches(&finding, filter) {
+			matched = append(matched, finding)
+		}
+	}
+	if filter.Ordering != "" {
+		slices.SortStableFunc(matched, orderingFunc(filter.Ordering))
+	}
+
+	response := &types.FindingsResponse{Count: len(matched), Results: []types.Finding{}}
+	start := min(filter.Offset, len(matched))
+	end := len(matched)
+	if filter.Limit > 0 {
+		end = min(start+filter.Limit, len(matched))
+	}
+	response.Results = append(response.Results, matched[start:end]...)
+	if end < len(matched) {
+		next := fmt.Sprintf("fixture:///findings/?limit=%d&offset=%d", filter.Limit, end)
+		response.Next = &next
+	}
+	if len(filter.Prefetch) > 0 {
+		response.Prefetch = c.prefetch(response.Results, filter.Prefetch)
+	}
+	if filter.RelatedFields {
+		for i := range response.Results {
+			response.Results[i].RelatedFields = c.relatedFields(&response.Results[i])
+		}
+	}
+	return response, nil
+}
+
+// relatedFields nests the names of a finding's related fixture objects, like
+// DefectDojo's related_fields parameter. Missing objects are left out.
+func (c *FixtureClient) relatedFields(finding *types.Finding) *types.RelatedFields {
+	related := &types.RelatedFields{}
+	if user, ok := c.users[finding.Reporter]; ok {
+		related.Reporter = &types.RelatedUser{ID: user.ID, Username: user.Username}
+	}
+	test, ok := c.tests[finding.Test]
+	if !ok {
+		return related
+	}
+	related.Test = &types.RelatedTest{ID: test.ID, Title: test.Title}
+	if testType, ok := c.testTypes[test.TestType]; ok {
+		related.Test.TestType = &testType
+	}
+	if environment, ok := c.envs[test.Environment]; ok {
+		related.Test.Environment = &environment
+	}
+	if engagement, ok := c.engagements[test.Engagement]; ok {
+		related.Test.Engagement = &types.RelatedEngagement{ID: engagement.ID, Name: engagement.Name}
+		if product, ok := c.products[engagement.Product]; ok {
+			related.Test.Engagement.Product = &types.RelatedProduct{ID: product.ID, Name: product.Name}
+		}
+	}
+	return related
+}
+
+// prefetch collects the related fixture objects of findings, like
+// DefectDojo's prefetch parameter. Unknown names and missing objects are ignored.
+func (c *FixtureClient) prefetch(findings []types.Finding, names []string) *types.Prefetched {
+	prefetched := &types.Prefetched{}
+	for _, finding := range findings {
+		for _, name := range names {
+			switch name {
+			case types.PrefetchTest:
+				if test, ok := c.tests[finding.Test]; ok {
+					if prefetched.Tests == nil {
+						prefetched.Tests = map[int]types.Test{}
+					}
+					prefetched.Tests[test.ID] = test
+				}
+			case types.PrefetchEndpoints:
+				for _, id := range finding.Endpoints {
+					if endpoint, ok := c.endpoints[id]; ok {
+						if prefetched.Endpoints == nil {
+							prefetched.Endpoints = map[int]types.Endpoint{}
+						}
+						prefetched.Endpoints[id] = endpoint
+					}
+				}
+			case types.PrefetchNotes:
+				for _, id := range finding.Notes {
+					if note, ok := c.notes[id]; ok {
+						if prefetched.Notes == nil {
+							prefetched.Notes = map[int]types.Note{}
+						}
+						prefetched.Notes[id] = note
+					}
+				}
+			}
+		}
+	}
+	return prefetched
+}
+
+// matches applies the subset of DefectDojo's finding filters exposed by FindingsFilter
+func (c *FixtureClient) matches(finding *types.Finding, filter types.FindingsFilter) bool {
+	boolMatches := func(want *bool, got bool) bool { return want == nil || *want == got }
+
+	switch {
+	case !boolMatches(filter.ActiveFilter(), finding.Active),
+		!boolMatches(filter.Verified, finding.Verified),
+		!boolMatches(filter.RiskAccepted, finding.RiskAccepted),
+		!boolMatches(filter.IsMitigated, finding.IsMitigated),
+		!boolMatches(filter.Duplicate, finding.Duplicate),
+		filter.Severity != "" && !strings.EqualFold(filter.Severity, finding.Severity),
+		filter.Test != nil && *filter.Test != finding.Test,
+		!containsFold(finding.ComponentName, filter.ComponentName),
+		!containsFold(finding.ComponentVersion, filter.ComponentVersion),
+		!filter.DiscoveredAfter.IsZero() && !finding.Date.After(filter.DiscoveredAfter),
+		!filter.DiscoveredBefore.IsZero() && !finding.Date.Before(filter.DiscoveredBefore),
+		!filter.MitigatedAfter.IsZero() && !finding.Mitigated.After(filter.MitigatedAfter),
+		!filter.MitigatedBefore.IsZero() && (finding.Mitigated.IsZero() || !finding.Mitigated.Before(filter.MitigatedBefore)),
+		len(filter.Reporter) > 0 && !slices.Contains(filter.Reporter, finding.Reporter),
+		len(filter.Reviewers) > 0 && !slices.ContainsFunc(filter.Reviewers, func(id int) bool { return slices.Contains(finding.Reviewers, id) }),
+		len(filter.Tags) > 0 && !slices.ContainsFunc(filter.Tags, func(tag string) bool { return slices.Contains(finding.Tags, tag) }),
+		slices.ContainsFunc(filter.NotTags, func(tag string) bool { return slices.Contains(finding.Tags, tag) }):
+		return false
+	}
+	if len(filter.FoundBy) > 0 && !slices.Contains(filter.FoundBy, c.tests[finding.Test].TestType) {
+		return false
+	}
+	if filter.Product != nil && c.engagements[c.tests[finding.Test].Engagement].Product != *filter.Product {
+		return false
+	}
+	if filter.Engagement != nil && c.tests[finding.Test].Engagement != *filter.Engagement {
+		return false
+	}
+	if filter.Environment != nil && c.tests[finding.Test].Environment != *filter.Environment {
+		return false
+	}
+	return true
+}
+
+// containsFold reports whether substr is within s, ignoring case, like DefectDojo's icontains lookups
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+// orderingFunc compares findings by a DefectDojo ordering expression such as
+// "numerical_severity,-date". Unknown fields are ignored.
+func orderingFunc(ordering string) func(a, b types.Finding) int {
+	return func(a, b types.Finding) int {
+		for _, field := range strings.Split(strings.ReplaceAll(ordering, " ", ""), ",") {
+			descending := strings.HasPrefix(field, "-")
+			var result int
+			switch strings.TrimPrefix(field, "-") {
+			case "id":
+				result = cmp.Compare(a.ID, b.ID)
+			case "title":
+				result = cmp.Compare(a.Title, b.Title)
+			case "date":
+				result = a.Date.Compare(b.Date)
+			case "created":
+				result = a.Created.Compare(b.Created)
+			case "mitigated":
+				result = a.Mitigated.Compare(b.Mitigated)
+			case "numerical_severity":
+				result = cmp.Compare(a.NumericalSeverity, b.NumericalSeverity)
+			case "cwe":
+				result = cmp.Compare(a.CWE, b.CWE)
+			case "component_name":
+				result = cmp.Compare(a.ComponentName, b.ComponentName)
+			case "active":
+				result = compareBool(a.Active, b.Active)
+			case "verified":
+				result = compareBool(a.Verified, b.Verified)
+			}
+			if descending {
+				result = -result
+			}
+			if result != 0 {
+				return result
+			}
+		}
+		return 0
+	}
+}
+
+func compareBool(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case b:
+		return -1
+	default:
+		return 1
+	}
+}
+
+// GetFindingDetail returns a fixture finding by ID
+func (c *FixtureClient) GetFindingDetail(ctx context.Context, findingID int) (*types.Finding, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, finding := range c.findings {
+		if finding.ID == findingID {
+			return &finding, nil
+		}
+	}
+	return nil, notFound()
+}
+
+// GetFindingDetailPrefetch returns a fixture finding with its related fixture objects
+func (c *FixtureClient) GetFindingDetailPrefetch(ctx context.Context, findingID int, prefetch []string) (*types.FindingDetail, error) {
+	finding, err := c.GetFindingDetail(ctx, findingID)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	detail := &types.FindingDetail{Finding: *finding}
+	if len(prefetch) > 0 {
+		detail.Prefetch = c.prefetch([]types.Finding{*finding}, prefetch)
+	}
+	return detail, nil
+}
+
+// MarkFalsePositive updates the in-memory finding the same way HTTPClient
+// updates a live one, including its modification time; the justification
+// note is counted but not stored.
+func (c *FixtureClient) MarkFalsePositive(ctx context.Context, findingID int, request types.FalsePositiveRequest) (*types.FalsePositiveResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	i := slices.IndexFunc(c.findings, func(f types.Finding) bool { return f.ID == findingID })
+	if i < 0 {
+		return nil, notFound()
+	}
+	if request.IsFalsePositive && request.Verified != nil && *request.Verified {
+		return nil, errVerifiedFalsePositive
+	}
+
+	finding := &c.findings[i]
+	if err := CheckUnmodified(finding, request.IfUnmodifiedSince); err != nil {
+		return nil, err
+	}
+	finding.Modified = time.Now().UTC()
+	finding.FalseP = request.IsFalsePositive
+	if request.IsFalsePositive && request.AlsoDeactivate {
+		finding.Active = false
+	}
+	if !request.IsFalsePositive && request.Reactivate {
+		finding.Active = true
+	}
+	if request.Verified != nil {
+		finding.Verified = *request.Verified
+	}
+
+	action := "marked"
+	if !request.IsFalsePositive {
+		action = "cleared"
+	}
+	noteID := c.nextNoteID
+	c.nextNoteID++
+
+	return &types.FalsePositiveResponse{
+		ID:            finding.ID,
+		FalseP:        finding.FalseP,
+		Active:        finding.Active,
+		Verified:      finding.Verified,
+		Justification: request.Justification,
+		Notes:         request.Notes,
+		NoteID:        noteID,
+		Message:       fmt.Sprintf("Finding successfully %s as false positive (offline fixtures, not persisted)", action),
+	}, nil
+}
+
+// AddFindingNote adds a public note to the in-memory finding; it is then
+// returned by the notes prefetch like a note loaded from notes.json
+func (c *FixtureClient) AddFindingNote(ctx context.Context, findingID int, entry string) (*types.Note, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	i := slices.IndexFunc(c.findings, func(f types.Finding) bool { return f.ID == findingID })
+	if i < 0 {
+		return nil, notFound()
+	}
+	note := c.addNote(i, entry)
+	return &note, nil
+}
+
+// addNote stores a note on the i-th finding; the caller holds c.mu
+func (c *FixtureClient) addNote(i int, entry string) types.Note {
+	// Skip IDs taken by notes.json
+	for c.notes[c.nextNoteID].ID != 0 {
+		c.nextNoteID++
+	}
+	note := types.Note{ID: c.nextNoteID, Entry: entry, Date: time.Now().UTC()}
+	c.nextNoteID++
+	c.notes[note.ID] = note
+	c.findings[i].Notes = append(c.findings[i].Notes, note.ID)
+	return note
+}
+
+// ChangeSeverity re-grades the in-memory finding and records the
+// justification as a note, like HTTPClient does for a live one
+func (c *FixtureClient) ChangeSeverity(ctx context.Context, findingID int, request types.SeverityChangeRequest) (*types.SeverityChangeResponse, error) {
+	if !types.IsValidSeverity(request.Severity) {
+		return nil, fmt.Errorf("invalid severity %q (must be one of %s)", request.Severity, strings.Join(types.ValidSeverities(), ", "))
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	i := slices.IndexFunc(c.findings, func(f types.Finding) bool { return f.ID == findingID })
+	if i < 0 {
+		return nil, notFound()
+	}
+	finding := &c.findings[i]
+	if err := CheckUnmodified(finding, request.IfUnmodifiedSince); err != nil {
+		return nil, err
+	}
+	response := &types.SeverityChangeResponse{
+		ID:                finding.ID,
+		PreviousSeverity:  finding.Severity,
+		Severity:          request.Severity,
+		NumericalSeverity: types.NumericalSeverity(request.Severity),
+		Justification:     request.Justification,
+		Message:           "Finding severity successfully changed (offline fixtures, not persisted)",
+	}
+	finding.Modified = time.Now().UTC()
+	finding.Severity, finding.NumericalSeverity = response.Severity, response.NumericalSeverity
+
+	if request.Justification != "" {
+		response.NoteID = c.addNote(i, severityChangeNote(response.PreviousSeverity, request)).ID
+	}
+	return response, nil
+}
+
+// GetFindingMetadata returns the metadata added to a fixture finding since startup
+func (c *FixtureClient) GetFindingMetadata(ctx context.Context, findingID int) ([]types.FindingMetadata, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !slices.ContainsFunc(c.findings, func(f types.Finding) bool { return f.ID == findingID }) {
+		return nil, notFound()
+	}
+	return slices.Clone(c.metadata[findingID]), nil
+}
+
+// AddFindingMetadata attaches metadata to the in-memory finding, rejecting
+// duplicate names like DefectDojo does
+func (c *FixtureClient) AddFindingMetadata(ctx context.Context, findingID int, metadata types.FindingMetadata) (*types.FindingMetadata, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !slices.ContainsFunc(c.findings, func(f types.Finding) bool { return f.ID == findingID }) {
+		return nil, notFound()
+	}
+	if slices.ContainsFunc(c.metadata[findingID], func(m types.FindingMetadata) bool { return m.Name == metadata.Name }) {
+		return nil, &APIError{StatusCode: http.StatusBadRequest, Body: fmt.Sprintf(`{"name":["metadata %q already exists on this finding"]}`, metadata.Name)}
+	}
+	metadata.ID = c.nextMetaID
+	c.nextMetaID++
+	c.metadata[findingID] = append(c.metadata[findingID], metadata)
+	return &metadata, nil
+}
+
+// ImportScan is not supported: fixture findings are not parsed from scan reports
+func (c *FixtureClient) ImportScan(ctx context.Context, request types.ImportScanRequest) (*types.ImportScanResponse, error) {
+	return nil, &APIError{StatusCode: http.StatusNotImplemented, Body: "scan import is not available in offline mode"}
+}
+
+// GetTest returns a fixture test by ID
+func (c *FixtureClient) GetTest(ctx context.Context, testID int) (*types.Test, error) {
+	return lookup(c, c.tests, testID)
+}
+
+// GetTestType returns a fixture test type by ID
+func (c *FixtureClient) GetTestType(ctx context.Context, testTypeID int) (*types.TestType, error) {
+	return lookup(c, c.testTypes, testTypeID)
+}
+
+// GetEngagement returns a fixture engagement by ID
+func (c *FixtureClient) GetEngagement(ctx context.Context, engagementID int) (*types.Engagement, error) {
+	return lookup(c, c.engagements, engagementID)
+}
+
+// ListEngagements filters and pages through the fixture engagements, ordered
+// by target start date and then ID like HTTPClient orders them
+func (c *FixtureClient) ListEngagements(ctx context.Context, filter types.EngagementsFilter) (*types.EngagementsResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var engagements []types.Engagement
+	for _, engagement := range c.engagements {
+		if filter.Product != nil && engagement.Product != *filter.Product {
+			continue
+		}
+		if filter.Active != nil && engagement.Active != *filter.Active {
+			continue
+		}
+		engagements = append(engagements, engagement)
+	}
+	slices.SortFunc(engagements, func(a, b types.Engagement) int {
+		return cmp.Or(cmp.Compare(a.TargetStart, b.TargetStart), cmp.Compare(a.ID, b.ID))
+	})
+
+	response := &types.EngagementsResponse{Count: len(engagements), Results: []types.Engagement{}}
+	start := min(filter.Offset, len(engagements))
+	end := len(engagements)
+	if filter.Limit > 0 {
+		end = min(start+filter.Limit, len(engagements))
+	}
+	response.Results = append(response.Results, engagements[start:end]...)
+	if end < len(engagements) {
+		next := fmt.Sprintf("fixture:///engagements/?limit=%d&offset=%d", filter.Limit, end)
+		response.Next = &next
+	}
+	if slices.Contains(filter.Prefetch, types.PrefetchLead) {
+		response.Prefetch = &types.Prefetched{Leads: map[int]types.User{}}
+		for _, engagement := range response.Results {
+			if user, ok := c.users[engagement.Lead]; ok {
+				response.Prefetch.Leads[user.ID] = user
+			}
+		}
+	}
+	return response, nil
+}
+
+// GetProduct returns a fixture product by ID
+func (c *FixtureClient) GetProduct(ctx context.Context, productID int) (*types.Product, error) {
+	return lookup(c, c.products, productID)
+}
+
+// ListProducts pages through the fixture products in ID order
+func (c *FixtureClient) ListProducts(ctx context.Context, limit, offset int) (*types.ProductsResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ids := slices.Sorted(maps.Keys(c.products))
+	response := &types.ProductsResponse{Count: len(ids), Results: []types.Product{}}
+	start := min(offset, len(ids))
+	end := len(ids)
+	if limit > 0 {
+		end = min(start+limit, len(ids))
+	}
+	for _, id := range ids[start:end] {
+		response.Results = append(response.Results, c.products[id])
+	}
+	if end < len(ids) {
+		next := fmt.Sprintf("fixture:///products/?limit=%d&offset=%d", limit, end)
+		response.Next = &next
+	}
+	return response, nil
+}
+
+// ListRiskAcceptances pages through the fixture risk acceptances in ID order
+func (c *FixtureClient) ListRiskAcceptances(ctx context.Context, limit, offset int) (*types.RiskAcceptancesResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ids := slices.Sorted(maps.Keys(c.risks))
+	response := &types.RiskAcceptancesResponse{Count: len(ids), Results: []types.RiskAcceptance{}}
+	start := min(offset, len(ids))
+	end := len(ids)
+	if limit > 0 {
+		end = min(start+limit, len(ids))
+	}
+	for _, id := range ids[start:end] {
+		response.Results = append(response.Results, c.risks[id])
+	}
+	if end < len(ids) {
+		next := fmt.Sprintf("fixture:///risk_acceptance/?limit=%d&offset=%d", limit, end)
+		response.Next = &next
+	}
+	return response, nil
+}
+
+// ListEndpointStatuses pages through endpoint statuses derived from the
+// fixture findings: one per finding and affected endpoint, carrying the
+// finding's mitigated, false positive, out of scope and risk accepted flags.
+// Status IDs are assigned in finding ID order.
+func (c *FixtureClient) ListEndpointStatuses(ctx context.Context, filter types.EndpointStatusFilter) (*types.EndpointStatusesResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	findings := slices.SortedFunc(slices.Values(c.findings), func(a, b types.Finding) int { return cmp.Compare(a.ID, b.ID) })
+	matches := func(want *bool, value bool) bool { return want == nil || *want == value }
+	var statuses []types.EndpointStatus
+	id := 0
+	for _, finding := range findings {
+		for _, endpoint := range finding.Endpoints {
+			id++
+			status := types.EndpointStatus{
+				ID:            id,
+				Endpoint:      endpoint,
+				Finding:       finding.ID,
+				Mitigated:     finding.IsMitigated,
+				FalsePositive: finding.FalseP,
+				OutOfScope:    finding.OutOfScope,
+				RiskAccepted:  finding.RiskAccepted,
+				Date:          finding.Date,
+			}
+			if matches(filter.Mitigated, status.Mitigated) && matches(filter.FalsePositive, status.FalsePositive) &&
+				matches(filter.OutOfScope, status.OutOfScope) && matches(filter.RiskAccepted, status.RiskAccepted) {
+				statuses = append(statuses, status)
+			}
+		}
+	}
+
+	response := &types.EndpointStatusesResponse{Count: len(statuses), Results: []types.EndpointStatus{}}
+	start := min(filter.Offset, len(statuses))
+	end := len(statuses)
+	if filter.Limit > 0 {
+		end = min(start+filter.Limit, len(statuses))
+	}
+	response.Results = append(response.Results, statuses[start:end]...)
+	if end < len(statuses) {
+		next := fmt.Sprintf("fixture:///endpoint_status/?limit=%d&offset=%d", filter.Limit, end)
+		response.Next = &next
+	}
+	if len(filter.Prefetch) > 0 {
+		response.Prefetch = &types.EndpointStatusPrefetch{}
+		for _, status := range response.Results {
+			if endpoint, ok := c.endpoints[status.Endpoint]; ok && slices.Contains(filter.Prefetch, types.PrefetchEndpoint) {
+				if response.Prefetch.Endpoints == nil {
+					response.Prefetch.Endpoints = map[int]types.Endpoint{}
+				}
+				response.Prefetch.Endpoints[endpoint.ID] = endpoint
+			}
+			if i := slices.IndexFunc(findings, func(f types.Finding) bool { return f.ID == status.Finding }); i >= 0 && slices.Contains(filter.Prefetch, types.PrefetchFinding) {
+				if response.Prefetch.Findings == nil {
+					response.Prefetch.Findings = map[int]types.Finding{}
+				}
+				response.Prefetch.Findings[status.Finding] = findings[i]
+			}
+		}
+	}
+	return response, nil
+}
+
+// ListEndpoints pages through a product's fixture endpoints in ID order
+func (c *FixtureClient) ListEndpoints(ctx context.Context, productID, limit, offset int) (*types.EndpointsResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var endpoints []types.Endpoint
+	for _, id := range slices.Sorted(maps.Keys(c.endpoints)) {
+		if c.endpoints[id].Product == productID {
+			endpoints = append(endpoints, c.endpoints[id])
+		}
+	}
+	response := &types.EndpointsResponse{Count: len(endpoints), Results: []types.Endpoint{}}
+	start := min(offset, len(endpoints))
+	end := len(endpoints)
+	if limit > 0 {
+		end = min(start+limit, len(endpoints))
+	}
+	response.Results = append(response.Results, endpoints[start:end]...)
+	if end < len(endpoints) {
+		next := fmt.Sprintf("fixture:///endpoints/?product=%d&limit=%d&offset=%d", productID, limit, end)
+		response.Next = &next
+	}
+	return response, nil
+}
+
+// ListTechnologies pages through a product's fixture technologies in ID order
+func (c *FixtureClient) ListTechnologies(ctx context.Context, productID, limit, offset int) (*types.TechnologiesResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var techs []types.Technology
+	for _, id := range slices.Sorted(maps.Keys(c.techs)) {
+		if c.techs[id].Product == productID {
+			techs = append(techs, c.techs[id])
+		}
+	}
+	response := &types.TechnologiesResponse{Count: len(techs), Results: []types.Technology{}}
+	start := min(offset, len(techs))
+	end := len(techs)
+	if limit > 0 {
+		end = min(start+limit, len(techs))
+	}
+	response.Results = append(response.Results, techs[start:end]...)
+	if end < len(techs) {
+		next := fmt.Sprintf("fixture:///technologies/?product=%d&limit=%d&offset=%d", productID, limit, end)
+		response.Next = &next
+	}
+	return response, nil
+}
+
+// ListProductTypes pages through the fixture product types in ID order
+func (c *FixtureClient) ListProductTypes(ctx context.Context, limit, offset int) (*types.ProductTypesResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ids := slices.Sorted(maps.Keys(c.prodTypes))
+	response := &types.ProductTypesResponse{Count: len(ids), Results: []types.ProductType{}}
+	start := min(offset, len(ids))
+	end := len(ids)
+	if limit > 0 {
+		end = min(start+limit, len(ids))
+	}
+	for _, id := range ids[start:end] {
+		response.Results = append(response.Results, c.prodTypes[id])
+	}
+	if end < len(ids) {
+		next := fmt.Sprintf("fixture:///product_types/?limit=%d&offset=%d", limit, end)
+		response.Next = &next
+	}
+	return response, nil
+}
+
+// ListUsers pages through the fixture users, only the one with this exact
+// login name when username is set
+func (c *FixtureClient) ListUsers(ctx context.Context, username string, limit, offset int) (*types.UsersResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var users []types.User
+	for _, id := range slices.Sorted(maps.Keys(c.users)) {
+		if username == "" || c.users[id].Username == username {
+			users = append(users, c.users[id])
+		}
+	}
+	response := &types.UsersResponse{Count: len(users), Results: []types.User{}}
+	start := min(offset, len(users))
+	end := len(users)
+	if limit > 0 {
+		end = min(start+limit, len(users))
+	}
+	response.Results = append(response.Results, users[start:end]...)
+	if end < len(users) {
+		next := fmt.Sprintf("fixture:///users/?limit=%d&offset=%d", limit, end)
+		response.Next = &next
+	}
+	return response, nil
+}
+
+// AssignFinding replaces the reviewers of the in-memory finding, rejecting
+// unknown users like DefectDojo does
+func (c *FixtureClient) AssignFinding(ctx context.Context, findingID int, userIDs []int) (*types.Finding, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	i := slices.IndexFunc(c.findings, func(f types.Finding) bool { return f.ID == findingID })
+	if i < 0 {
+		return nil, notFound()
+	}
+	for _, id := range userIDs {
+		if _, ok := c.users[id]; !ok {
+			return nil, &APIError{StatusCode: http.StatusBadRequest, Body: fmt.Sprintf(`{"reviewers":["Invalid pk \"%d\" - object does not exist."]}`, id)}
+		}
+	}
+	finding := &c.findings[i]
+	finding.Reviewers = slices.Clone(userIDs)
+	finding.Modified = time.Now().UTC()
+	result := *finding
+	return &result, nil
+}
+
+// CloseFinding closes the in-memory finding as mitigated and records the
+// note, like DefectDojo's close action
+func (c *FixtureClient) CloseFinding(ctx context.Context, findingID int, request types.CloseFindingRequest) (*types.Finding, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	i := slices.IndexFunc(c.findings, func(f types.Finding) bool { return f.ID == findingID })
+	if i < 0 {
+		return nil, notFound()
+	}
+	now := time.Now().UTC()
+	finding := &c.findings[i]
+	finding.Active = false
+	finding.IsMitigated = true
+	finding.Mitigated = request.Mitigated.UTC()
+	if request.Mitigated.IsZero() {
+		finding.Mitigated = now
+	}
+	finding.Modified = now
+	if request.Note != "" {
+		c.addNote(i, request.Note)
+	}
+	result := *finding
+	return &result, nil
+}
+
+// CreateProduct adds a product to the in-memory fixtures, rejecting a taken
+// name or an unknown product type like DefectDojo does
+func (c *FixtureClient) CreateProduct(ctx context.Context, request types.CreateProductRequest) (*types.Product, error) {
+	if err := requireFields(map[string]string{"name": request.Name, "description": request.Description}); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, product := range c.products {
+		if product.Name == request.Name {
+			return nil, &APIError{StatusCode: http.StatusBadRequest, Body: `{"name":["product with this name already exists."]}`}
+		}
+	}
+	if _, ok := c.prodTypes[request.ProductType]; !ok {
+		return nil, &APIError{StatusCode: http.StatusBadRequest, Body: fmt.Sprintf(`{"prod_type":["Invalid pk \"%d\" - object does not exist."]}`, request.ProductType)}
+	}
+	product := types.Product{
+		ID:          nextID(c.products),
+		Name:        request.Name,
+		Description: request.Description,
+		ProductType: request.ProductType,
+		Tags:        slices.Clone(request.Tags),
+	}
+	c.products[product.ID] = product
+	return &product, nil
+}
+
+// CreateEngagement adds an active engagement to an existing fixture product
+func (c *FixtureClient) CreateEngagement(ctx context.Context, request types.CreateEngagementRequest) (*types.Engagement, error) {
+	if err := requireFields(map[string]string{"name": request.Name, "target_start": request.TargetStart, "target_end": request.TargetEnd}); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.products[request.Product]; !ok {
+		return nil, &APIError{StatusCode: http.StatusBadRequest, Body: fmt.Sprintf(`{"product":["Invalid pk \"%d\" - object does not exist."]}`, request.Product)}
+	}
+	engagement := types.Engagement{
+		ID:             nextID(c.engagements),
+		Name:           request.Name,
+		Product:        request.Product,
+		Description:    request.Description,
+		Status:         request.Status,
+		EngagementType: request.EngagementType,
+		TargetStart:    request.TargetStart,
+		TargetEnd:      request.TargetEnd,
+		Active:         true,
+	}
+	c.engagements[engagement.ID] = engagement
+	return &engagement, nil
+}
+
+// requireFields mirrors DefectDojo's response when required fields are empty
+func requireFields(fields map[string]string) error {
+	missing := map[string][]string{}
+	for name, value := range fields {
+		if value == "" {
+			missing[name] = []string{"This field is required."}
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	body, _ := json.Marshal(missing)
+	return &APIError{StatusCode: http.StatusBadRequest, Body: string(body)}
+}
+
+// nextID returns an ID above every ID in index
+func nextID[T any](index map[int]T) int {
+	if len(index) == 0 {
+		return 1
+	}
+	return slices.Max(slices.Collect(maps.Keys(index))) + 1
+}
+
+// ListTests pages through the fixture tests of an engagement in ID order
+func (c *FixtureClient) ListTests(ctx context.Context, engagementID, limit, offset int) (*types.TestsResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var tests []types.Test
+	for _, id := range slices.Sorted(maps.Keys(c.tests)) {
+		if c.tests[id].Engagement == engagementID {
+			tests = append(tests, c.tests[id])
+		}
+	}
+	response := &types.TestsResponse{Count: len(tests), Results: []types.Test{}}
+	start := min(offset, len(tests))
+	end := len(tests)
+	if limit > 0 {
+		end = min(start+limit, len(tests))
+	}
+	response.Results = append(response.Results, tests[start:end]...)
+	if end < len(tests) {
+		next := fmt.Sprintf("fixture:///tests/?engagement=%d&limit=%d&offset=%d", engagementID, limit, end)
+		response.Next = &next
+	}
+	return response, nil
+}
+
+// ListTestImports pages through the fixture import history of a test, newest first
+func (c *FixtureClient) ListTestImports(ctx context.Context, testID, limit, offset int) (*types.TestImportsResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var imports []types.TestImport
+	for _, id := range slices.Backward(slices.Sorted(maps.Keys(c.imports))) {
+		if c.imports[id].Test == testID {
+			imports = append(imports, c.imports[id])
+		}
+	}
+	response := &types.TestImportsResponse{Count: len(imports), Results: []types.TestImport{}}
+	start := min(offset, len(imports))
+	end := len(imports)
+	if limit > 0 {
+		end = min(start+limit, len(imports))
+	}
+	response.Results = append(response.Results, imports[start:end]...)
+	if end < len(imports) {
+		next := fmt.Sprintf("fixture:///test_imports/?test=%d&limit=%d&offset=%d", testID, limit, end)
+		response.Next = &next
+	}
+	return response, nil
+}
+
+// GetSystemSettings returns the fixture system settings, answering 404 when
+// the fixtures have none
+func (c *FixtureClient) GetSystemSettings(ctx context.Context) (*types.SystemSettings, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.settings) == 0 {
+		return nil, notFound()
+	}
+	settings := c.settings[0]
+	return &settings, nil
+}
+
+// ListDevelopmentEnvironments pages through the fixture development environments in ID order
+func (c *FixtureClient) ListDevelopmentEnvironments(ctx context.Context, limit, offset int) (*types.DevelopmentEnvironmentsResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ids := slices.Sorted(maps.Keys(c.envs))
+	response := &types.DevelopmentEnvironmentsResponse{Count: len(ids), Results: []types.DevelopmentEnvironment{}}
+	start := min(offset, len(ids))
+	end := len(ids)
+	if limit > 0 {
+		end = min(start+limit, len(ids))
+	}
+	for _, id := range ids[start:end] {
+		response.Results = append(response.Results, c.envs[id])
+	}
+	if end < len(ids) {
+		next := fmt.Sprintf("fixture:///development_environments/?limit=%d&offset=%d", limit, end)
+		response.Next = &next
+	}
+	return response, nil
+}
+
+// ListSLAConfigurations pages through the fixture SLA configurations in ID order
+func (c *FixtureClient) ListSLAConfigurations(ctx context.Context, limit, offset int) (*types.SLAConfigurationsResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ids := slices.Sorted(maps.Keys(c.slas))
+	response := &types.SLAConfigurationsResponse{Count: len(ids), Results: []types.SLAConfiguration{}}
+	start := min(offset, len(ids))
+	end := len(ids)
+	if limit > 0 {
+		end = min(start+limit, len(ids))
+	}
+	for _, id := range ids[start:end] {
+		response.Results = append(response.Results, c.slas[id])
+	}
+	if end < len(ids) {
+		next := fmt.Sprintf("fixture:///sla_configurations/?limit=%d&offset=%d", limit, end)
+		response.Next = &next
+	}
+	return response, nil
+}
+
+// GetAnnouncement returns the fixture announcement, or nil when there is none
+func (c *FixtureClient) GetAnnouncement(ctx context.Context) (*types.Announcement, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.banners) == 0 {
+		return nil, nil
+	}
+	announcement := c.banners[0]
+	return &announcement, nil
+}
+
+func lookup[T any](c *FixtureClient, index map[int]T, id int) (*T, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	item, ok := index[id]
+	if !ok {
+		return nil, notFound()
+	}
+	return &item, nil
+}
+
+// HealthCheck always reports healthy: fixtures are loaded at construction
+func (c *FixtureClient) HealthCheck(ctx context.Context) (bool, string) {
+	return true, fmt.Sprintf("Offline mode: serving %d fixture findings from %s", len(c.findings), c.source)
+}
+
+// CheckHealth reports the fixture source as a reachable, authenticated instance
+func (c *FixtureClient) CheckHealth(ctx context.Context) *types.HealthStatus {
+	return &types.HealthStatus{
+		URL:           "fixture://" + c.source,
+		Reachable:     true,
+		Authenticated: true,
+		User:          "offline",
+		Version:       "offline fixtures",
+	}
+}
+
+// Version is unknown in offline mode, so no feature is gated
+func (c *FixtureClient) Version(ctx context.Context) string {
+	return ""
+}
+
+// Supports reports every feature as available in offline mode
+func (c *FixtureClient) Supports(ctx context.Context, feature Feature) error {
+	return nil
+}
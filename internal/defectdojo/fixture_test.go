@@ -0,0 +1,462 @@
+package defectdojo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brduru/mcp-defect-dojo/internal/config"
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+func TestFixtureClient_DemoData(t *testing.T) {
+	client, err := NewFixtureClient("")
+	if err != nil {
+		t.Fatalf("loading demo fixtures: %v", err)
+	}
+	ctx := context.Background()
+
+	active, product, engagement, staging := true, 2, 10, 2
+	tests := []struct {
+		name    string
+		filter  types.FindingsFilter
+		wantIDs []int
+	}{
+		{"all", types.FindingsFilter{Ordering: "id"}, []int{1, 2, 3, 4, 5, 6, 7}},
+		{"active high", types.FindingsFilter{Active: &active, Severity: "high", Ordering: "id"}, []int{2, 3}},
+		{"tags", types.FindingsFilter{Tags: []string{"secrets", "pci"}, Ordering: "id"}, []int{1, 3}},
+		{"not tags", types.FindingsFilter{Active: &active, NotTags: []string{"external"}, Ordering: "id"}, []int{3, 4, 5}},
+		{"found by test type", types.FindingsFilter{FoundBy: []int{7}, Ordering: "id"}, []int{3, 6}},
+		{"product", types.FindingsFilter{Product: &product, Ordering: "id"}, []int{3, 4, 6}},
+		{"engagement", types.FindingsFilter{Engagement: &engagement, Ordering: "id"}, []int{1, 2, 5, 7}},
+		{"environment", types.FindingsFilter{Environment: &staging}, []int{4}},
+		{"component", types.FindingsFilter{ComponentName: "LODASH", ComponentVersion: "4.17"}, []int{4}},
+		{"discovered after", types.FindingsFilter{DiscoveredAfter: time.Date(2026, 8, 1, 0, 0, 0, 0, time.UTC), Ordering: "id"}, []int{3, 4, 6}},
+		{"discovered before", types.FindingsFilter{DiscoveredBefore: time.Date(2026, 8, 1, 0, 0, 0, 0, time.UTC), Ordering: "id"}, []int{1, 2, 5, 7}},
+		{"mitigated after", types.FindingsFilter{MitigatedAfter: time.Date(2026, 7, 15, 0, 0, 0, 0, time.UTC)}, []int{7}},
+		{"mitigated before", types.FindingsFilter{MitigatedBefore: time.Date(2026, 8, 1, 0, 0, 0, 0, time.UTC)}, []int{7}},
+		{"most severe first", types.FindingsFilter{Active: &active, Ordering: "numerical_severity,-id"}, []int{1, 3, 2, 4, 5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, err := client.GetFindings(ctx, tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var ids []int
+			for _, f := range response.Results {
+				ids = append(ids, f.ID)
+			}
+			if len(ids) != len(tt.wantIDs) || response.Count != len(tt.wantIDs) {
+				t.Fatalf("got IDs %v (count %d), want %v", ids, response.Count, tt.wantIDs)
+			}
+			for i := range ids {
+				if ids[i] != tt.wantIDs[i] {
+					t.Fatalf("got IDs %v, want %v", ids, tt.wantIDs)
+				}
+			}
+		})
+	}
+
+	t.Run("pagination", func(t *testing.T) {
+		page, err := client.GetFindings(ctx, types.FindingsFilter{Limit: 3, Offset: 3})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if page.Count != 7 || len(page.Results) != 3 || page.Next == nil || *page.Next != "fixture:///findings/?limit=3&offset=6" {
+			t.Errorf("unexpected page: count=%d results=%d next=%v", page.Count, len(page.Results), page.Next)
+		}
+		last, _ := client.GetFindings(ctx, types.FindingsFilter{Limit: 3, Offset: 6})
+		if len(last.Results) != 1 || last.Next != nil {
+			t.Errorf("expected a final page of one without next, got %d results, next=%v", len(last.Results), last.Next)
+		}
+	})
+
+	t.Run("related fields", func(t *testing.T) {
+		page, err := client.GetFindings(ctx, types.FindingsFilter{Engagement: &engagement, Limit: 1, Ordering: "id", RelatedFields: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		related := page.Results[0].RelatedFields
+		if related == nil || related.Test == nil || related.Test.Engagement == nil || related.Test.Engagement.Product == nil {
+			t.Fatalf("expected nested test, engagement and product, got %+v", related)
+		}
+		if related.Test.Engagement.Name != "Q3 Pentest" || related.Test.Engagement.Product.Name != "Payments API" || related.Test.Environment.Name != "Production" {
+			t.Errorf("unexpected related names %+v", related.Test)
+		}
+		if name := page.Results[0].ReporterName(); name != "dana" {
+			t.Errorf("ReporterName() = %q, want dana", name)
+		}
+		plain, _ := client.GetFindings(ctx, types.FindingsFilter{Limit: 1})
+		if plain.Results[0].RelatedFields != nil {
+			t.Errorf("expected no related fields unless requested, got %+v", plain.Results[0].RelatedFields)
+		}
+	})
+
+	t.Run("reference lookups", func(t *testing.T) {
+		test, err := client.GetTest(ctx, 100)
+		if err != nil || test.Engagement != 10 {
+			t.Fatalf("GetTest = %+v, %v", test, err)
+		}
+		product, err := client.GetProduct(ctx, 1)
+		if err != nil || product.Name != "Payments API" {
+			t.Fatalf("GetProduct = %+v, %v", product, err)
+		}
+		products, err := client.ListProducts(ctx, 1, 1)
+		if err != nil || products.Count != 2 || len(products.Results) != 1 || products.Results[0].Name != "Customer Portal" || products.Next != nil {
+			t.Fatalf("ListProducts = %+v, %v", products, err)
+		}
+		tests, err := client.ListTests(ctx, 11, 0, 0)
+		if err != nil || tests.Count != 2 || tests.Results[0].ID != 101 || tests.Results[1].ID != 102 {
+			t.Fatalf("ListTests = %+v, %v", tests, err)
+		}
+		var apiErr *APIError
+		if _, err := client.GetFindingDetail(ctx, 999); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+			t.Errorf("expected 404 APIError for a missing finding, got %v", err)
+		}
+	})
+}
+
+func TestFixtureClient_MarkFalsePositive(t *testing.T) {
+	client, err := NewFixtureClient("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	response, err := client.MarkFalsePositive(ctx, 2, types.FalsePositiveRequest{IsFalsePositive: true, Justification: "encoded by framework", AlsoDeactivate: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !response.FalseP || response.Active || response.NoteID == 0 {
+		t.Errorf("unexpected response: %+v", response)
+	}
+
+	finding, _ := client.GetFindingDetail(ctx, 2)
+	if !finding.FalseP || finding.Active {
+		t.Errorf("expected in-memory finding updated, got false_p=%v active=%v", finding.FalseP, finding.Active)
+	}
+
+	// The write moved the modification time, so a write based on the old one conflicts
+	stale := types.FalsePositiveRequest{IsFalsePositive: false, Justification: "oops", IfUnmodifiedSince: time.Now().Add(-time.Hour)}
+	var conflict *ConflictError
+	if _, err := client.MarkFalsePositive(ctx, 2, stale); !errors.As(err, &conflict) {
+		t.Errorf("expected a conflict, got %v", err)
+	}
+
+	// A fresh client starts from the fixtures again
+	fresh, _ := NewFixtureClient("")
+	if original, _ := fresh.GetFindingDetail(ctx, 2); original.FalseP {
+		t.Error("expected fixture data to be unchanged by writes")
+	}
+}
+
+func TestFixtureClient_FindingMetadata(t *testing.T) {
+	client, err := NewFixtureClient("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	issue := types.FindingMetadata{Name: "issue_url", Value: "https://github.com/acme/api/issues/7"}
+	if created, err := client.AddFindingMetadata(ctx, 1, issue); err != nil || created.ID == 0 {
+		t.Fatalf("AddFindingMetadata() = %+v, %v", created, err)
+	}
+	if metadata, _ := client.GetFindingMetadata(ctx, 1); len(metadata) != 1 || metadata[0].Value != issue.Value {
+		t.Errorf("unexpected metadata: %+v", metadata)
+	}
+
+	var apiErr *APIError
+	if _, err := client.AddFindingMetadata(ctx, 1, issue); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected a duplicate name to be rejected, got %v", err)
+	}
+	if _, err := client.GetFindingMetadata(ctx, 999); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("expected a missing finding to be reported, got %v", err)
+	}
+}
+
+func TestFixtureClient_ChangeSeverity(t *testing.T) {
+	client, err := NewFixtureClient("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	response, err := client.ChangeSeverity(ctx, 1, types.SeverityChangeRequest{Severity: "High", Justification: "WAF blocks the payload"})
+	if err != nil {
+		t.Fatalf("ChangeSeverity() error = %v", err)
+	}
+	if response.PreviousSeverity != "Critical" || response.Severity != "High" || response.NumericalSeverity != "S1" || response.NoteID == 0 {
+		t.Errorf("unexpected response %+v", response)
+	}
+	detail, err := client.GetFindingDetailPrefetch(ctx, 1, []string{types.PrefetchNotes})
+	if err != nil {
+		t.Fatalf("GetFindingDetailPrefetch() error = %v", err)
+	}
+	if detail.Severity != "High" || detail.NumericalSeverity != "S1" || detail.Prefetch.Notes[response.NoteID].Entry != "Severity changed from Critical to High: WAF blocks the payload" {
+		t.Errorf("unexpected finding after the change: %+v", detail)
+	}
+
+	var conflict *ConflictError
+	if _, err := client.ChangeSeverity(ctx, 1, types.SeverityChangeRequest{Severity: "Low", IfUnmodifiedSince: time.Now().Add(-time.Hour)}); !errors.As(err, &conflict) {
+		t.Errorf("expected a ConflictError, got %v", err)
+	}
+	if _, err := client.ChangeSeverity(ctx, 1, types.SeverityChangeRequest{Severity: "Severe"}); err == nil {
+		t.Error("expected an invalid severity to be refused")
+	}
+}
+
+func TestFixtureClient_AssignFinding(t *testing.T) {
+	client, err := NewFixtureClient("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	users, err := client.ListUsers(ctx, "ci-bot", 10, 0)
+	if err != nil || users.Count != 1 || users.Results[0].ID != 2 {
+		t.Fatalf("ListUsers() = %+v, %v", users, err)
+	}
+	if all, _ := client.ListUsers(ctx, "", 1, 0); all.Count != 2 || all.Next == nil {
+		t.Errorf("expected two users paged one at a time, got %+v", all)
+	}
+
+	finding, err := client.AssignFinding(ctx, 4, []int{2})
+	if err != nil || !slices.Equal(finding.Reviewers, []int{2}) {
+		t.Fatalf("AssignFinding() = %+v, %v", finding, err)
+	}
+	assigned, _ := client.GetFindings(ctx, types.FindingsFilter{Reviewers: []int{2}})
+	if assigned.Count != 1 || assigned.Results[0].ID != 4 {
+		t.Errorf("expected only finding 4 assigned to user 2, got %+v", assigned.Results)
+	}
+
+	var apiErr *APIError
+	if _, err := client.AssignFinding(ctx, 4, []int{42}); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400 for an unknown user, got %v", err)
+	}
+}
+
+func TestFixtureClient_CloseFinding(t *testing.T) {
+	client, err := NewFixtureClient("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	mitigated := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	finding, err := client.CloseFinding(ctx, 4, types.CloseFindingRequest{Note: "Fixed in release 2.4", Mitigated: mitigated})
+	if err != nil || finding.Active || !finding.IsMitigated || !finding.Mitigated.Equal(mitigated) || len(finding.Notes) != 1 {
+		t.Fatalf("CloseFinding() = %+v, %v", finding, err)
+	}
+	if open, _ := client.GetFindings(ctx, types.FindingsFilter{Active: ptr(true)}); slices.ContainsFunc(open.Results, func(f types.Finding) bool { return f.ID == 4 }) {
+		t.Error("expected the closed finding to leave the active findings")
+	}
+
+	if _, err := client.CloseFinding(ctx, 999, types.CloseFindingRequest{}); err == nil {
+		t.Error("expected an unknown finding to fail")
+	}
+}
+
+func TestFixtureClient_ListTestImports(t *testing.T) {
+	client, err := NewFixtureClient("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	imports, err := client.ListTestImports(context.Background(), 100, 1, 0)
+	if err != nil {
+		t.Fatalf("ListTestImports() error = %v", err)
+	}
+	if imports.Count != 2 || imports.Next == nil || imports.Results[0].ID != 4 {
+		t.Errorf("expected the newest of two imports first, got %+v", imports)
+	}
+	if closed := imports.Results[0].Count(types.ImportActionClosed); closed != 1 {
+		t.Errorf("expected the reimport to close one finding, got %d", closed)
+	}
+	if none, _ := client.ListTestImports(context.Background(), 999, 10, 0); none.Count != 0 || none.Results == nil {
+		t.Errorf("expected an empty history for an unknown test, got %+v", none)
+	}
+}
+
+func TestFixtureClient_SystemInfo(t *testing.T) {
+	client, err := NewFixtureClient("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	if settings, err := client.GetSystemSettings(ctx); err != nil || !settings.FalsePositiveHistory {
+		t.Errorf("GetSystemSettings() = %+v, %v", settings, err)
+	}
+	if slas, err := client.ListSLAConfigurations(ctx, 10, 0); err != nil || slas.Count != 2 || slas.Results[1].Name != "Internet-facing" {
+		t.Errorf("ListSLAConfigurations() = %+v, %v", slas, err)
+	}
+	if envs, err := client.ListDevelopmentEnvironments(ctx, 2, 0); err != nil || envs.Count != 3 || envs.Next == nil || envs.Results[1].Name != "Staging" {
+		t.Errorf("ListDevelopmentEnvironments() = %+v, %v", envs, err)
+	}
+	if announcement, err := client.GetAnnouncement(ctx); err != nil || announcement == nil {
+		t.Errorf("GetAnnouncement() = %+v, %v", announcement, err)
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "findings.json"), []byte(`[]`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	bare, err := NewFixtureClient(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var apiErr *APIError
+	if _, err := bare.GetSystemSettings(ctx); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404 without system settings fixtures, got %v", err)
+	}
+	if announcement, err := bare.GetAnnouncement(ctx); err != nil || announcement != nil {
+		t.Errorf("expected no announcement, got %+v, %v", announcement, err)
+	}
+}
+
+func TestFixtureClient_AddFindingNote(t *testing.T) {
+	client, _ := NewFixtureClient("")
+	ctx := context.Background()
+
+	note, err := client.AddFindingNote(ctx, 2, "tracked in INC-1234")
+	if err != nil || note.ID == 0 || note.Date.IsZero() {
+		t.Fatalf("AddFindingNote() = %+v, %v", note, err)
+	}
+	detail, err := client.GetFindingDetailPrefetch(ctx, 2, []string{types.PrefetchNotes})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := detail.Prefetch.Notes[note.ID]; !slices.Contains(detail.Notes, note.ID) || got.Entry != "tracked in INC-1234" {
+		t.Errorf("expected the note on the finding and in the prefetch, got %+v", detail)
+	}
+
+	var apiErr *APIError
+	if _, err := client.AddFindingNote(ctx, 999, "x"); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("expected a missing finding to be reported, got %v", err)
+	}
+}
+
+func TestFixtureClient_CreateProduct(t *testing.T) {
+	client, _ := NewFixtureClient("")
+	ctx := context.Background()
+
+	product, err := client.CreateProduct(ctx, types.CreateProductRequest{Name: "Billing Service", Description: "Invoices", ProductType: 2})
+	if err != nil || product.ID != 3 {
+		t.Fatalf("CreateProduct() = %+v, %v", product, err)
+	}
+	engagement, err := client.CreateEngagement(ctx, types.CreateEngagementRequest{Product: product.ID, Name: "CI", TargetStart: "2026-03-01", TargetEnd: "2026-03-08"})
+	if err != nil || engagement.ID != 13 || !engagement.Active {
+		t.Fatalf("CreateEngagement() = %+v, %v", engagement, err)
+	}
+	if got, err := client.GetEngagement(ctx, engagement.ID); err != nil || got.Product != product.ID {
+		t.Errorf("GetEngagement() = %+v, %v", got, err)
+	}
+
+	for name, request := range map[string]types.CreateProductRequest{
+		"taken name":           {Name: "Payments API", Description: "x", ProductType: 1},
+		"unknown product type": {Name: "New App", Description: "x", ProductType: 9},
+		"missing description":  {Name: "New App", ProductType: 1},
+	} {
+		var apiErr *APIError
+		if _, err := client.CreateProduct(ctx, request); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %v", name, err)
+		}
+	}
+	var apiErr *APIError
+	if _, err := client.CreateEngagement(ctx, types.CreateEngagementRequest{Product: 999, Name: "CI", TargetStart: "2026-03-01", TargetEnd: "2026-03-08"}); !errors.As(err, &apiErr) || !strings.Contains(apiErr.Body, "product") {
+		t.Errorf("expected a missing product to be reported, got %v", err)
+	}
+}
+
+func TestFixtureClient_ListEndpointStatuses(t *testing.T) {
+	client, _ := NewFixtureClient("")
+	ctx := context.Background()
+
+	all, err := client.ListEndpointStatuses(ctx, types.EndpointStatusFilter{})
+	if err != nil || all.Count != 5 {
+		t.Fatalf("ListEndpointStatuses() = %+v, %v", all, err)
+	}
+	open := false
+	statuses, err := client.ListEndpointStatuses(ctx, types.EndpointStatusFilter{FalsePositive: &open, RiskAccepted: &open, Limit: 2, Prefetch: []string{types.PrefetchEndpoint, types.PrefetchFinding}})
+	if err != nil || statuses.Count != 3 || len(statuses.Results) != 2 || statuses.Next == nil {
+		t.Fatalf("ListEndpointStatuses() = %+v, %v", statuses, err)
+	}
+	first := statuses.Results[0]
+	if first.Finding != 1 || first.Endpoint != 4 || statuses.Prefetch.Endpoints[4].Host != "pay.example.com" || statuses.Prefetch.Findings[1].Severity != "Critical" {
+		t.Errorf("unexpected first status %+v with prefetch %+v", first, statuses.Prefetch)
+	}
+}
+
+func TestFixtureClient_ProductInventory(t *testing.T) {
+	client, _ := NewFixtureClient("")
+	ctx := context.Background()
+
+	endpoints, err := client.ListEndpoints(ctx, 1, 1, 0)
+	if err != nil || endpoints.Count != 2 || len(endpoints.Results) != 1 || endpoints.Results[0].ID != 4 || endpoints.Next == nil {
+		t.Errorf("ListEndpoints() = %+v, %v", endpoints, err)
+	}
+	technologies, err := client.ListTechnologies(ctx, 2, 100, 0)
+	if err != nil || technologies.Count != 1 || technologies.Results[0].Name != "React" || technologies.Next != nil {
+		t.Errorf("ListTechnologies() = %+v, %v", technologies, err)
+	}
+	if endpoints, err := client.ListEndpoints(ctx, 999, 100, 0); err != nil || endpoints.Count != 0 {
+		t.Errorf("expected no endpoints for an unknown product, got %+v, %v", endpoints, err)
+	}
+}
+
+func TestFixtureClient_Directory(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := NewFixtureClient(dir); err == nil {
+		t.Fatal("expected an error without findings.json")
+	}
+
+	// Captured API list responses are accepted as is
+	write("findings.json", `{"count": 1, "next": null, "results": [{"id": 9, "title": "From capture", "severity": "Low", "test": 1, "date": "2026-01-02"}]}`)
+	client, err := NewFixtureClient(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	finding, err := client.GetFindingDetail(context.Background(), 9)
+	if err != nil || finding.Title != "From capture" || finding.Date.IsZero() {
+		t.Fatalf("GetFindingDetail = %+v, %v", finding, err)
+	}
+	if _, err := client.GetTest(context.Background(), 1); err == nil {
+		t.Error("expected missing optional fixtures to yield not found")
+	}
+
+	write("products.json", `[{"id": 1, "name": broken}]`)
+	if _, err := NewFixtureClient(dir); err == nil {
+		t.Error("expected malformed fixture to be rejected")
+	}
+}
+
+func TestNewClientMode(t *testing.T) {
+	if _, ok := mustNewClient(t, &config.DefectDojoConfig{Mode: ModeOffline}).(*FixtureClient); !ok {
+		t.Error("expected a FixtureClient in offline mode")
+	}
+	if _, ok := mustNewClient(t, &config.DefectDojoConfig{BaseURL: "http://localhost:8080"}).(*HTTPClient); !ok {
+		t.Error("expected an HTTPClient by default")
+	}
+}
+
+func mustNewClient(t *testing.T, cfg *config.DefectDojoConfig) Client {
+	t.Helper()
+	client, err := NewClient(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
@@ -0,0 +1,169 @@
+package defectdojo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+// CheckStatus is the outcome of a single self-check step
+type CheckStatus string
+
+// Self-check outcomes
+const (
+	CheckPass CheckStatus = "pass"
+	CheckWarn CheckStatus = "warn"
+	CheckFail CheckStatus = "fail"
+	CheckSkip CheckStatus = "skip"
+)
+
+// CheckResult describes one self-check step
+type CheckResult struct {
+	Name   string      // Short check name, e.g. "Authentication"
+	Status CheckStatus // Outcome
+	Detail string      // Human readable diagnosis
+	Hint   string      // Suggested fix for failures and warnings (Doctor only)
+}
+
+// SelfCheckReport summarizes the startup self-check
+type SelfCheckReport struct {
+	Checks  []CheckResult
+	Version string // Detected DefectDojo version (empty if unknown)
+}
+
+// OK reports whether no check failed
+func (r *SelfCheckReport) OK() bool {
+	for _, check := range r.Checks {
+		if check.Status == CheckFail {
+			return false
+		}
+	}
+	return true
+}
+
+// String renders the report as one line per check
+func (r *SelfCheckReport) String() string {
+	var b strings.Builder
+	for _, check := range r.Checks {
+		fmt.Fprintf(&b, "%s %s: %s\n", statusIcon(check.Status), check.Name, check.Detail)
+		if check.Hint != "" {
+			fmt.Fprintf(&b, "   → %s\n", check.Hint)
+		}
+	}
+	return b.String()
+}
+
+func statusIcon(status CheckStatus) string {
+	switch status {
+	case CheckPass:
+		return "✅"
+	case CheckWarn:
+		return "⚠️ "
+	case CheckFail:
+		return "❌"
+	default:
+		return "⏭️ "
+	}
+}
+
+// SelfCheck verifies the configured URL, connectivity, authentication,
+// DefectDojo version and write permission of the API token. Later steps are
+// skipped when an earlier prerequisite fails, so the first failure is the
+// one to fix.
+func (c *HTTPClient) SelfCheck(ctx context.Context) *SelfCheckReport {
+	report := &SelfCheckReport{}
+	add := func(name string, status CheckStatus, detail string) {
+		report.Checks = append(report.Checks, CheckResult{Name: name, Status: status, Detail: detail})
+	}
+	skipRest := func(names ...string) {
+		for _, name := range names {
+			add(name, CheckSkip, "skipped due to previous failure")
+		}
+	}
+
+	// URL
+	parsed, err := url.Parse(c.config.BaseURL)
+	if err != nil || parsed.Host == "" || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		add("URL", CheckFail, fmt.Sprintf("%q is not a valid http(s) URL; set DEFECTDOJO_URL", c.config.BaseURL))
+		skipRest("API version", "Connectivity", "Authentication", "Version", "Write permission")
+		return report
+	}
+	add("URL", CheckPass, c.config.BaseURL)
+
+	// API version
+	if c.apiErr != nil {
+		add("API version", CheckFail, c.apiErr.Error())
+		skipRest("Connectivity", "Authentication", "Version", "Write permission")
+		return report
+	}
+	add("API version", CheckPass, "API "+c.api.version)
+
+	// Connectivity and authentication
+	if c.config.APIKey == "" {
+		add("Connectivity", CheckSkip, "no API key configured")
+		add("Authentication", CheckFail, "DEFECTDOJO_API_KEY is not set")
+		skipRest("Version", "Write permission")
+		return report
+	}
+
+	profile, err := c.GetUserProfile(ctx)
+	var apiErr *APIError
+	switch {
+	case err == nil:
+		add("Connectivity", CheckPass, "DefectDojo API is reachable")
+		add("Authentication", CheckPass, fmt.Sprintf("authenticated as %q", profile.User.Username))
+	case errors.As(err, &apiErr) && (apiErr.StatusCode == http.StatusUnauthorized || apiErr.StatusCode == http.StatusForbidden):
+		add("Connectivity", CheckPass, "DefectDojo API is reachable")
+		add("Authentication", CheckFail, fmt.Sprintf("API key rejected (status %d); generate a new token in DefectDojo under API v2 Key", apiErr.StatusCode))
+		skipRest("Version", "Write permission")
+		return report
+	case errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound:
+		add("Connectivity", CheckFail, fmt.Sprintf("%s is not a DefectDojo API (404); check the URL and API version", c.apiURL("/")))
+		skipRest("Authentication", "Version", "Write permission")
+		return report
+	default:
+		add("Connectivity", CheckFail, fmt.Sprintf("cannot reach DefectDojo: %v", err))
+		skipRest("Authentication", "Version", "Write permission")
+		return report
+	}
+
+	// Version
+	if version, err := c.GetVersion(ctx); err != nil {
+		add("Version", CheckWarn, fmt.Sprintf("could not detect DefectDojo version: %v", err))
+	} else {
+		report.Version = version
+		add("Version", CheckPass, "DefectDojo "+version)
+	}
+
+	// Write permission
+	status, detail := writePermission(profile)
+	add("Write permission", status, detail)
+
+	return report
+}
+
+// writePermission derives whether the token can modify findings from the user's roles
+func writePermission(profile *types.UserProfile) (CheckStatus, string) {
+	if profile.User.IsSuperuser {
+		return CheckPass, "superuser; all write tools are available"
+	}
+	if profile.GlobalRole != nil && profile.GlobalRole.Role != nil && types.CanWriteRole(*profile.GlobalRole.Role) {
+		return CheckPass, "global role permits editing findings"
+	}
+
+	writable := 0
+	for _, member := range profile.ProductMembers {
+		if types.CanWriteRole(member.Role) {
+			writable++
+		}
+	}
+	if writable > 0 {
+		return CheckWarn, fmt.Sprintf("write access limited to %d product(s); write tools fail elsewhere", writable)
+	}
+	return CheckWarn, "token is read-only; write tools such as mark_finding_false_positive will fail"
+}
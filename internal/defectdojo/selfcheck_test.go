@@ -0,0 +1,116 @@
+package defectdojo
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/brduru/mcp-defect-dojo/internal/config"
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+func TestHTTPClient_SelfCheck(t *testing.T) {
+	reader := types.RoleReader
+	tests := []struct {
+		name        string
+		apiKey      string
+		baseURL     string // overrides the test server URL when set
+		profileCode int
+		profile     types.UserProfile
+		wantOK      bool
+		wantStatus  map[string]CheckStatus
+	}{
+		{
+			name:        "superuser passes all checks",
+			apiKey:      "key",
+			profileCode: http.StatusOK,
+			profile:     types.UserProfile{User: types.User{Username: "admin", IsSuperuser: true}},
+			wantOK:      true,
+			wantStatus: map[string]CheckStatus{
+				"Authentication":   CheckPass,
+				"Version":          CheckPass,
+				"Write permission": CheckPass,
+			},
+		},
+		{
+			name:        "read-only token warns",
+			apiKey:      "key",
+			profileCode: http.StatusOK,
+			profile:     types.UserProfile{User: types.User{Username: "bot"}, GlobalRole: &types.GlobalRole{Role: &reader}},
+			wantOK:      true,
+			wantStatus:  map[string]CheckStatus{"Write permission": CheckWarn},
+		},
+		{
+			name:        "rejected token fails authentication",
+			apiKey:      "bad",
+			profileCode: http.StatusUnauthorized,
+			wantOK:      false,
+			wantStatus: map[string]CheckStatus{
+				"Connectivity":   CheckPass,
+				"Authentication": CheckFail,
+				"Version":        CheckSkip,
+			},
+		},
+		{
+			name:       "missing API key",
+			wantOK:     false,
+			wantStatus: map[string]CheckStatus{"Authentication": CheckFail},
+		},
+		{
+			name:       "invalid URL",
+			apiKey:     "key",
+			baseURL:    "ftp://defectdojo.local",
+			wantOK:     false,
+			wantStatus: map[string]CheckStatus{"URL": CheckFail, "Connectivity": CheckSkip},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/api/v2/user_profile/":
+					w.WriteHeader(tt.profileCode)
+					json.NewEncoder(w).Encode(tt.profile)
+				case "/api/v2/oa3/schema/":
+					json.NewEncoder(w).Encode(map[string]any{"info": map[string]any{"version": "2.38.1"}})
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+
+			baseURL := server.URL
+			if tt.baseURL != "" {
+				baseURL = tt.baseURL
+			}
+			client := NewHTTPClient(&config.DefectDojoConfig{
+				BaseURL:        baseURL,
+				APIKey:         tt.apiKey,
+				APIVersion:     "v2",
+				RequestTimeout: 5 * time.Second,
+			})
+
+			report := client.SelfCheck(context.Background())
+			if report.OK() != tt.wantOK {
+				t.Errorf("OK() = %v, want %v\n%s", report.OK(), tt.wantOK, report)
+			}
+
+			got := map[string]CheckStatus{}
+			for _, check := range report.Checks {
+				got[check.Name] = check.Status
+			}
+			for name, want := range tt.wantStatus {
+				if got[name] != want {
+					t.Errorf("check %q = %q, want %q\n%s", name, got[name], want, report)
+				}
+			}
+			if tt.wantStatus["Version"] == CheckPass && report.Version != "2.38.1" {
+				t.Errorf("expected version 2.38.1, got %q", report.Version)
+			}
+		})
+	}
+}
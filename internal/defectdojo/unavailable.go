@@ -0,0 +1,190 @@
+package defectdojo
+
+import (
+	"context"
+
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+// UnavailableClient implements Client for a DefectDojo that cannot be used,
+// e.g. when offline fixtures fail to load: every call fails with the error
+// that prevented creating the real client, so it is reported to the caller
+// instead of falling back to other data.
+type UnavailableClient struct {
+	Err error
+}
+
+// GetFindings fails with the client's error
+func (c UnavailableClient) GetFindings(context.Context, types.FindingsFilter) (*types.FindingsResponse, error) {
+	return nil, c.Err
+}
+
+// GetFindingDetail fails with the client's error
+func (c UnavailableClient) GetFindingDetail(context.Context, int) (*types.Finding, error) {
+	return nil, c.Err
+}
+
+// GetFindingDetailPrefetch fails with the client's error
+func (c UnavailableClient) GetFindingDetailPrefetch(context.Context, int, []string) (*types.FindingDetail, error) {
+	return nil, c.Err
+}
+
+// MarkFalsePositive fails with the client's error
+func (c UnavailableClient) MarkFalsePositive(context.Context, int, types.FalsePositiveRequest) (*types.FalsePositiveResponse, error) {
+	return nil, c.Err
+}
+
+// ChangeSeverity fails with the client's error
+func (c UnavailableClient) ChangeSeverity(context.Context, int, types.SeverityChangeRequest) (*types.SeverityChangeResponse, error) {
+	return nil, c.Err
+}
+
+// AddFindingNote fails with the client's error
+func (c UnavailableClient) AddFindingNote(context.Context, int, string) (*types.Note, error) {
+	return nil, c.Err
+}
+
+// GetFindingMetadata fails with the client's error
+func (c UnavailableClient) GetFindingMetadata(context.Context, int) ([]types.FindingMetadata, error) {
+	return nil, c.Err
+}
+
+// AddFindingMetadata fails with the client's error
+func (c UnavailableClient) AddFindingMetadata(context.Context, int, types.FindingMetadata) (*types.FindingMetadata, error) {
+	return nil, c.Err
+}
+
+// ImportScan fails with the client's error
+func (c UnavailableClient) ImportScan(context.Context, types.ImportScanRequest) (*types.ImportScanResponse, error) {
+	return nil, c.Err
+}
+
+// GetTest fails with the client's error
+func (c UnavailableClient) GetTest(context.Context, int) (*types.Test, error) {
+	return nil, c.Err
+}
+
+// GetTestType fails with the client's error
+func (c UnavailableClient) GetTestType(context.Context, int) (*types.TestType, error) {
+	return nil, c.Err
+}
+
+// GetEngagement fails with the client's error
+func (c UnavailableClient) GetEngagement(context.Context, int) (*types.Engagement, error) {
+	return nil, c.Err
+}
+
+// ListEngagements fails with the client's error
+func (c UnavailableClient) ListEngagements(context.Context, types.EngagementsFilter) (*types.EngagementsResponse, error) {
+	return nil, c.Err
+}
+
+// GetProduct fails with the client's error
+func (c UnavailableClient) GetProduct(context.Context, int) (*types.Product, error) {
+	return nil, c.Err
+}
+
+// ListProducts fails with the client's error
+func (c UnavailableClient) ListProducts(context.Context, int, int) (*types.ProductsResponse, error) {
+	return nil, c.Err
+}
+
+// ListProductTypes fails with the client's error
+func (c UnavailableClient) ListProductTypes(context.Context, int, int) (*types.ProductTypesResponse, error) {
+	return nil, c.Err
+}
+
+// CreateProduct fails with the client's error
+func (c UnavailableClient) CreateProduct(context.Context, types.CreateProductRequest) (*types.Product, error) {
+	return nil, c.Err
+}
+
+// ListUsers fails with the client's error
+func (c UnavailableClient) ListUsers(context.Context, string, int, int) (*types.UsersResponse, error) {
+	return nil, c.Err
+}
+
+// AssignFinding fails with the client's error
+func (c UnavailableClient) AssignFinding(context.Context, int, []int) (*types.Finding, error) {
+	return nil, c.Err
+}
+
+// CloseFinding fails with the client's error
+func (c UnavailableClient) CloseFinding(context.Context, int, types.CloseFindingRequest) (*types.Finding, error) {
+	return nil, c.Err
+}
+
+// CreateEngagement fails with the client's error
+func (c UnavailableClient) CreateEngagement(context.Context, types.CreateEngagementRequest) (*types.Engagement, error) {
+	return nil, c.Err
+}
+
+// ListTests fails with the client's error
+func (c UnavailableClient) ListTests(context.Context, int, int, int) (*types.TestsResponse, error) {
+	return nil, c.Err
+}
+
+// ListTestImports fails with the client's error
+func (c UnavailableClient) ListTestImports(context.Context, int, int, int) (*types.TestImportsResponse, error) {
+	return nil, c.Err
+}
+
+// ListDevelopmentEnvironments fails with the client's error
+func (c UnavailableClient) ListDevelopmentEnvironments(context.Context, int, int) (*types.DevelopmentEnvironmentsResponse, error) {
+	return nil, c.Err
+}
+
+// ListRiskAcceptances fails with the client's error
+func (c UnavailableClient) ListRiskAcceptances(context.Context, int, int) (*types.RiskAcceptancesResponse, error) {
+	return nil, c.Err
+}
+
+// ListEndpointStatuses fails with the client's error
+func (c UnavailableClient) ListEndpointStatuses(context.Context, types.EndpointStatusFilter) (*types.EndpointStatusesResponse, error) {
+	return nil, c.Err
+}
+
+// ListEndpoints fails with the client's error
+func (c UnavailableClient) ListEndpoints(context.Context, int, int, int) (*types.EndpointsResponse, error) {
+	return nil, c.Err
+}
+
+// ListTechnologies fails with the client's error
+func (c UnavailableClient) ListTechnologies(context.Context, int, int, int) (*types.TechnologiesResponse, error) {
+	return nil, c.Err
+}
+
+// GetSystemSettings fails with the client's error
+func (c UnavailableClient) GetSystemSettings(context.Context) (*types.SystemSettings, error) {
+	return nil, c.Err
+}
+
+// ListSLAConfigurations fails with the client's error
+func (c UnavailableClient) ListSLAConfigurations(context.Context, int, int) (*types.SLAConfigurationsResponse, error) {
+	return nil, c.Err
+}
+
+// GetAnnouncement fails with the client's error
+func (c UnavailableClient) GetAnnouncement(context.Context) (*types.Announcement, error) {
+	return nil, c.Err
+}
+
+// HealthCheck fails with the client's error
+func (c UnavailableClient) HealthCheck(context.Context) (bool, string) {
+	return false, c.Err.Error()
+}
+
+// CheckHealth fails with the client's error
+func (c UnavailableClient) CheckHealth(context.Context) *types.HealthStatus {
+	return &types.HealthStatus{Error: c.Err.Error()}
+}
+
+// Version fails with the client's error
+func (c UnavailableClient) Version(context.Context) string {
+	return ""
+}
+
+// Supports fails with the client's error
+func (c UnavailableClient) Supports(context.Context, Feature) error {
+	return c.Err
+}
@@ -0,0 +1,110 @@
+package defectdojo
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// versionRetryInterval limits how often a failed version detection is retried
+const versionRetryInterval = time.Minute
+
+// Feature names a DefectDojo capability that is only available in newer releases
+type Feature string
+
+// Known version-dependent features
+const (
+	FeatureVulnerabilityIDs Feature = "vulnerability_ids" // Finding.vulnerability_ids replaced the single cve field
+	FeatureRelatedFields    Feature = "related_fields"    // Findings nest their test, engagement and product names on request
+)
+
+// featureMinVersions maps each feature to the first DefectDojo release providing it
+var featureMinVersions = map[Feature]string{
+	FeatureVulnerabilityIDs: "2.7.0",
+	FeatureRelatedFields:    "2.0.0",
+}
+
+// UnsupportedFeatureError is returned when the connected DefectDojo release is
+// too old for the requested operation.
+type UnsupportedFeatureError struct {
+	Feature  Feature
+	Required string // Minimum DefectDojo version
+	Detected string // Version reported by the instance
+}
+
+func (e *UnsupportedFeatureError) Error() string {
+	return fmt.Sprintf("%s requires DefectDojo >= %s (connected instance runs %s)", e.Feature, e.Required, e.Detected)
+}
+
+// CompareVersions compares dotted numeric versions such as "2.38.1".
+// It returns -1, 0 or 1. Missing components count as zero and any
+// non-numeric suffix (e.g. "-dev") is ignored.
+func CompareVersions(a, b string) int {
+	pa, pb := versionParts(a), versionParts(b)
+	for len(pa) < len(pb) {
+		pa = append(pa, 0)
+	}
+	for len(pb) < len(pa) {
+		pb = append(pb, 0)
+	}
+	for i := range pa {
+		if pa[i] != pb[i] {
+			if pa[i] < pb[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+func versionParts(version string) []int {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if i := strings.IndexAny(version, "-+ "); i >= 0 {
+		version = version[:i]
+	}
+	var parts []int
+	for _, field := range strings.Split(version, ".") {
+		n, err := strconv.Atoi(field)
+		if err != nil {
+			break
+		}
+		parts = append(parts, n)
+	}
+	return parts
+}
+
+// Version returns the DefectDojo release version, detecting it on first use.
+// Detection failures are retried at most once per versionRetryInterval; an
+// empty string means the version is unknown.
+func (c *HTTPClient) Version(ctx context.Context) string {
+	c.versionMu.Lock()
+	defer c.versionMu.Unlock()
+
+	if c.version != "" || time.Since(c.versionCheckedAt) < versionRetryInterval {
+		return c.version
+	}
+
+	c.versionCheckedAt = time.Now()
+	if version, err := c.GetVersion(ctx); err == nil {
+		c.version = version
+	}
+	return c.version
+}
+
+// Supports checks whether the connected DefectDojo release provides a feature.
+// When the version cannot be determined the feature is assumed available, so
+// that gating never blocks an instance that merely hides its version.
+func (c *HTTPClient) Supports(ctx context.Context, feature Feature) error {
+	required, ok := featureMinVersions[feature]
+	if !ok {
+		return nil
+	}
+	detected := c.Version(ctx)
+	if detected == "" || CompareVersions(detected, required) >= 0 {
+		return nil
+	}
+	return &UnsupportedFeatureError{Feature: feature, Required: required, Detected: detected}
+}
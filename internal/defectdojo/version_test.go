@@ -0,0 +1,87 @@
+package defectdojo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/brduru/mcp-defect-dojo/internal/config"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"2.38.1", "2.38.1", 0},
+		{"2.7.0", "2.38.0", -1},
+		{"2.38.0", "2.7", 1},
+		{"v2.10", "2.10.0", 0},
+		{"2.30.0-dev", "2.30.0", 0},
+		{"1.15.1", "2.0.0", -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+"_vs_"+tt.b, func(t *testing.T) {
+			if got := CompareVersions(tt.a, tt.b); got != tt.want {
+				t.Errorf("CompareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTTPClient_Supports(t *testing.T) {
+	tests := []struct {
+		name        string
+		version     string // empty = schema endpoint unavailable
+		wantErr     bool
+		wantVersion string
+	}{
+		{"new release", "2.38.0", false, "2.38.0"},
+		{"old release", "2.6.3", true, "2.6.3"},
+		{"unknown version", "", false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var schemaCalls int
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				schemaCalls++
+				if tt.version == "" {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				json.NewEncoder(w).Encode(map[string]any{"info": map[string]any{"version": tt.version}})
+			}))
+			defer server.Close()
+
+			client := NewHTTPClient(&config.DefectDojoConfig{
+				BaseURL:        server.URL,
+				APIVersion:     "v2",
+				RequestTimeout: 5 * time.Second,
+			})
+
+			err := client.Supports(context.Background(), FeatureVulnerabilityIDs)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Supports() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var unsupported *UnsupportedFeatureError
+			if tt.wantErr && !errors.As(err, &unsupported) {
+				t.Errorf("expected UnsupportedFeatureError, got %T", err)
+			}
+
+			// Version is detected once and cached (failures are rate limited)
+			_ = client.Version(context.Background())
+			if schemaCalls != 1 {
+				t.Errorf("expected 1 version detection request, got %d", schemaCalls)
+			}
+			if got := client.Version(context.Background()); got != tt.wantVersion {
+				t.Errorf("Version() = %q, want %q", got, tt.wantVersion)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+// Package logfields carries the fields identifying a tool call through
+// contexts: the tool, the MCP session, the DefectDojo instance and the
+// request ID assigned by the requestid package.
+//
+// Log lines written with Printf end with these fields, e.g.
+// "[tool=get_finding_detail session=4f1c instance=default request_id=9a2e]",
+// so logs of deployments serving several sessions or instances can be
+// filtered by any of them.
+package logfields
+
+import (
+	"cmp"
+	"context"
+	"log"
+	"strings"
+
+	"github.com/brduru/mcp-defect-dojo/internal/requestid"
+)
+
+// Fields identify the tool call a log line belongs to; empty fields are unknown
+type Fields struct {
+	Tool      string
+	Session   string
+	Instance  string
+	RequestID string
+}
+
+type contextKey struct{}
+
+// With returns a context carrying fields, keeping the fields of ctx that
+// fields leaves empty
+func With(ctx context.Context, fields Fields) context.Context {
+	current := FromContext(ctx)
+	return context.WithValue(ctx, contextKey{}, Fields{
+		Tool:      cmp.Or(fields.Tool, current.Tool),
+		Session:   cmp.Or(fields.Session, current.Session),
+		Instance:  cmp.Or(fields.Instance, current.Instance),
+		RequestID: cmp.Or(fields.RequestID, current.RequestID),
+	})
+}
+
+// FromContext returns the fields of ctx. The request ID defaults to the one
+// set with requestid.WithID.
+func FromContext(ctx context.Context) Fields {
+	fields, _ := ctx.Value(contextKey{}).(Fields)
+	fields.RequestID = cmp.Or(fields.RequestID, requestid.FromContext(ctx))
+	return fields
+}
+
+// String renders the known fields as key=value pairs, in a fixed order
+func (f Fields) String() string {
+	var pairs []string
+	for _, field := range []struct{ key, value string }{
+		{"tool", f.Tool},
+		{"session", f.Session},
+		{"instance", f.Instance},
+		{"request_id", f.RequestID},
+	} {
+		if field.value != "" {
+			pairs = append(pairs, field.key+"="+field.value)
+		}
+	}
+	return strings.Join(pairs, " ")
+}
+
+// Printf logs like log.Printf, followed by the fields of ctx if it has any
+func Printf(ctx context.Context, format string, args ...any) {
+	if fields := FromContext(ctx).String(); fields != "" {
+		format += " [" + strings.ReplaceAll(fields, "%", "%%") + "]"
+	}
+	log.Printf(format, args...)
+}
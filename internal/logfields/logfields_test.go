@@ -0,0 +1,42 @@
+package logfields
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/brduru/mcp-defect-dojo/internal/requestid"
+)
+
+func TestWith(t *testing.T) {
+	ctx := requestid.WithID(context.Background(), "abc")
+	ctx = With(ctx, Fields{Tool: "get_finding_detail", Session: "s1"})
+	ctx = With(ctx, Fields{Instance: "default"})
+
+	want := Fields{Tool: "get_finding_detail", Session: "s1", Instance: "default", RequestID: "abc"}
+	if got := FromContext(ctx); got != want {
+		t.Errorf("FromContext() = %+v, want %+v", got, want)
+	}
+	if got := FromContext(ctx).String(); got != "tool=get_finding_detail session=s1 instance=default request_id=abc" {
+		t.Errorf("String() = %q", got)
+	}
+	if got := FromContext(context.Background()).String(); got != "" {
+		t.Errorf("expected no fields, got %q", got)
+	}
+}
+
+func TestPrintf(t *testing.T) {
+	var out bytes.Buffer
+	defer log.SetOutput(log.Writer())
+	defer log.SetFlags(log.Flags())
+	log.SetOutput(&out)
+	log.SetFlags(0)
+
+	Printf(With(context.Background(), Fields{Tool: "export_findings", Session: "50%"}), "exported %d findings", 3)
+	Printf(context.Background(), "no call")
+	if got := out.String(); got != "exported 3 findings [tool=export_findings session=50%]\nno call\n" {
+		t.Errorf("unexpected log output %q", strings.TrimSpace(got))
+	}
+}
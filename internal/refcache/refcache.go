@@ -0,0 +1,129 @@
+// Package refcache caches rarely-changing DefectDojo reference data.
+//
+// Products, engagements, tests, test types and users change far less often
+// than findings, yet enrichment and validation look them up on nearly every
+// tool call. A Cache holds them in memory for a TTL under namespaced keys
+// ("product:7", "test_types") so each distinct object costs one API request
+// per TTL, and operators can invalidate a namespace when they know it changed.
+package refcache
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cache is a concurrency-safe in-memory store with per-entry expiry.
+// The zero value is not usable; create caches with New.
+type Cache struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu        sync.Mutex
+	entries   map[string]entry
+	nextSweep time.Time // When store next removes expired entries
+}
+
+type entry struct {
+	value     any
+	expiresAt time.Time
+}
+
+// New creates a cache whose entries expire after ttl.
+// A non-positive ttl disables caching: every Get calls its loader.
+func New(ttl time.Duration) *Cache {
+	return &Cache{ttl: ttl, now: time.Now, entries: map[string]entry{}}
+}
+
+// Get returns the value cached under key, calling load on a miss or after expiry.
+// Errors are not cached. The lock is not held while loading, so concurrent
+// misses for the same key may both call load; the last result wins.
+func Get[T any](c *Cache, key string, load func() (T, error)) (T, error) {
+	c.mu.Lock()
+	cached, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && c.now().Before(cached.expiresAt) {
+		if value, ok := cached.value.(T); ok {
+			return value, nil
+		}
+	}
+
+	value, err := load()
+	if err != nil {
+		return value, err
+	}
+
+	c.store(key, value)
+	return value, nil
+}
+
+// Peek returns the fresh value cached under key without loading it on a miss.
+// Callers that load many keys in one request use Peek and Put instead of Get.
+func Peek[T any](c *Cache, key string) (T, bool) {
+	c.mu.Lock()
+	cached, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && c.now().Before(cached.expiresAt) {
+		if value, ok := cached.value.(T); ok {
+			return value, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+// Put caches value under key for the cache TTL. It does nothing when caching is disabled.
+func (c *Cache) Put(key string, value any) {
+	c.store(key, value)
+}
+
+// store caches value under key for the cache TTL. Keys such as CVE IDs are
+// rarely asked for twice, so at most once per TTL it also removes every
+// expired entry, bounding the cache to what was stored in about two TTLs.
+func (c *Cache) store(key string, value any) {
+	if c.ttl <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	if !now.Before(c.nextSweep) {
+		for key, cached := range c.entries {
+			if !now.Before(cached.expiresAt) {
+				delete(c.entries, key)
+			}
+		}
+		c.nextSweep = now.Add(c.ttl)
+	}
+	c.entries[key] = entry{value: value, expiresAt: now.Add(c.ttl)}
+}
+
+// Invalidate removes every entry in the namespace and returns how many were removed.
+// An entry belongs to namespace "product" if its key is "product" or starts
+// with "product:". An empty namespace clears the whole cache.
+func (c *Cache) Invalidate(namespace string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	removed := 0
+	for key := range c.entries {
+		if namespace == "" || key == namespace || strings.HasPrefix(key, namespace+":") {
+			delete(c.entries, key)
+			removed++
+		}
+	}
+	return removed
+}
+
+// Len returns the number of cached entries, including expired ones not yet removed.
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+// TTL returns how long entries stay cached.
+func (c *Cache) TTL() time.Duration {
+	return c.ttl
+}
@@ -0,0 +1,140 @@
+package refcache
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestGetCachesUntilExpiry(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache := New(time.Minute)
+	cache.now = func() time.Time { return now }
+
+	loads := 0
+	load := func() (string, error) {
+		loads++
+		return "Payments API", nil
+	}
+
+	for range 3 {
+		if got, err := Get(cache, "product:7", load); err != nil || got != "Payments API" {
+			t.Fatalf("Get = %q, %v", got, err)
+		}
+	}
+	if loads != 1 {
+		t.Errorf("expected one load while fresh, got %d", loads)
+	}
+
+	now = now.Add(time.Minute)
+	if _, err := Get(cache, "product:7", load); err != nil {
+		t.Fatal(err)
+	}
+	if loads != 2 {
+		t.Errorf("expected reload after expiry, got %d loads", loads)
+	}
+}
+
+func TestGetDoesNotCacheErrors(t *testing.T) {
+	cache := New(time.Minute)
+	loads := 0
+	load := func() (int, error) {
+		loads++
+		if loads == 1 {
+			return 0, errors.New("temporarily unavailable")
+		}
+		return 42, nil
+	}
+
+	if _, err := Get(cache, "test:1", load); err == nil {
+		t.Fatal("expected first load error")
+	}
+	if got, err := Get(cache, "test:1", load); err != nil || got != 42 {
+		t.Errorf("Get = %d, %v; want 42 after retry", got, err)
+	}
+}
+
+func TestDisabledCache(t *testing.T) {
+	cache := New(0)
+	loads := 0
+	for range 2 {
+		Get(cache, "users", func() ([]string, error) {
+			loads++
+			return []string{"admin"}, nil
+		})
+	}
+	if loads != 2 || cache.Len() != 0 {
+		t.Errorf("expected no caching with zero TTL, got %d loads and %d entries", loads, cache.Len())
+	}
+}
+
+func TestInvalidate(t *testing.T) {
+	cache := New(time.Minute)
+	for _, key := range []string{"product:1", "product:2", "products", "test:1", "test_types"} {
+		Get(cache, key, func() (bool, error) { return true, nil })
+	}
+
+	if removed := cache.Invalidate("product"); removed != 2 {
+		t.Errorf("Invalidate(product) removed %d, want 2", removed)
+	}
+	if removed := cache.Invalidate("test_types"); removed != 1 {
+		t.Errorf("Invalidate(test_types) removed %d, want 1", removed)
+	}
+	if removed := cache.Invalidate(""); removed != 2 {
+		t.Errorf("Invalidate(\"\") removed %d, want 2", removed)
+	}
+	if cache.Len() != 0 {
+		t.Errorf("expected empty cache, got %d entries", cache.Len())
+	}
+}
+
+func TestPeekAndPut(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache := New(time.Minute)
+	cache.now = func() time.Time { return now }
+
+	if _, ok := Peek[float64](cache, "epss:CVE-2021-44228"); ok {
+		t.Fatal("expected a miss before Put")
+	}
+	cache.Put("epss:CVE-2021-44228", 0.97)
+	if got, ok := Peek[float64](cache, "epss:CVE-2021-44228"); !ok || got != 0.97 {
+		t.Errorf("Peek = %v, %v", got, ok)
+	}
+	if _, ok := Peek[string](cache, "epss:CVE-2021-44228"); ok {
+		t.Error("expected a type mismatch to miss")
+	}
+
+	now = now.Add(time.Minute)
+	if _, ok := Peek[float64](cache, "epss:CVE-2021-44228"); ok {
+		t.Error("expected a miss after expiry")
+	}
+
+	disabled := New(0)
+	disabled.Put("kev", true)
+	if disabled.Len() != 0 {
+		t.Error("expected Put to do nothing with caching disabled")
+	}
+}
+
+func TestPutRemovesExpiredEntries(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache := New(time.Minute)
+	cache.now = func() time.Time { return now }
+
+	for _, cve := range []string{"CVE-2021-44228", "CVE-2022-22965", "CVE-2023-4966"} {
+		cache.Put("epss:"+cve, 0.9)
+	}
+	now = now.Add(30 * time.Second)
+	cache.Put("epss:CVE-2024-3400", 0.9)
+	if cache.Len() != 4 {
+		t.Errorf("expected fresh entries kept, got %d entries", cache.Len())
+	}
+
+	now = now.Add(time.Minute)
+	if _, err := Get(cache, "kev", func() (bool, error) { return true, nil }); err != nil {
+		t.Fatal(err)
+	}
+	if cache.Len() != 1 {
+		t.Errorf("expected expired entries removed when storing, got %d entries", cache.Len())
+	}
+}
@@ -0,0 +1,35 @@
+// Package requestid carries per-tool-call correlation IDs through contexts.
+//
+// The MCP server assigns an ID to every tool invocation; the DefectDojo client
+// forwards it as the X-Request-ID header, and logs, audit records and error
+// messages include it so operators can correlate all three.
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// Header is the HTTP header used to propagate request IDs to DefectDojo
+const Header = "X-Request-ID"
+
+type contextKey struct{}
+
+// New returns a random 16 character hexadecimal request ID
+func New() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// WithID returns a context carrying the request ID
+func WithID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the request ID stored in ctx, or an empty string
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
@@ -0,0 +1,154 @@
+// Package dojoclient is a typed Go facade over the DefectDojo operations
+// embedding services need most, for callers in the same process as the MCP
+// server that have no use for MCP messages.
+//
+// A Client either shares the DefectDojo client of an MCP server, through
+// mcpserver.Server.DojoClient, or connects on its own with New. Its calls go
+// straight to DefectDojo: the MCP server's write policy, approval queue,
+// audit trail and notifications do not apply to them.
+//
+// Example:
+//
+//	dojo := server.DojoClient()
+//	critical, err := dojo.FindCriticalFindings(ctx, dojoclient.CriticalFindingsQuery{Product: 3})
+//	if err != nil {
+//		return err
+//	}
+//	for _, finding := range critical {
+//		log.Printf("%d %s", finding.ID, finding.Title)
+//	}
+package dojoclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/brduru/mcp-defect-dojo/internal/config"
+	"github.com/brduru/mcp-defect-dojo/internal/defectdojo"
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+// Page size and default result limit of FindCriticalFindings
+const (
+	findingsPageSize        = 100
+	defaultCriticalFindings = 100
+)
+
+// API is the part of the DefectDojo client the facade calls. The MCP
+// server's client implements it; tests can pass a fake to FromAPI.
+type API interface {
+	GetFindings(ctx context.Context, filter types.FindingsFilter) (*types.FindingsResponse, error)
+	CloseFinding(ctx context.Context, findingID int, request types.CloseFindingRequest) (*types.Finding, error)
+	ImportScan(ctx context.Context, request types.ImportScanRequest) (*types.ImportScanResponse, error)
+}
+
+// Config contains the DefectDojo connection of a Client created with New.
+type Config struct {
+	BaseURL        string        // DefectDojo instance URL (default: http://localhost:8080)
+	APIKey         string        // DefectDojo API token
+	APIVersion     string        // DefectDojo API version (default: v2)
+	RequestTimeout time.Duration // HTTP request timeout (default: 30s)
+}
+
+// Client calls DefectDojo with typed requests and results.
+type Client struct {
+	api API
+}
+
+// New connects a Client to DefectDojo over HTTP.
+func New(cfg Config) *Client {
+	ddConfig := config.DefaultConfig().DefectDojo
+	if cfg.BaseURL != "" {
+		ddConfig.BaseURL = config.NormalizeBaseURL(cfg.BaseURL)
+	}
+	ddConfig.APIKey = cfg.APIKey
+	if cfg.APIVersion != "" {
+		ddConfig.APIVersion = cfg.APIVersion
+	}
+	if cfg.RequestTimeout > 0 {
+		ddConfig.RequestTimeout = cfg.RequestTimeout
+	}
+	return FromAPI(defectdojo.NewHTTPClient(&ddConfig))
+}
+
+// FromAPI returns a Client calling api, e.g. the DefectDojo client of an MCP
+// server or a fake in tests.
+func FromAPI(api API) *Client {
+	return &Client{api: api}
+}
+
+// CriticalFindingsQuery selects the findings of FindCriticalFindings.
+type CriticalFindingsQuery struct {
+	Product    int  // Only findings of this product (0 = all products)
+	Engagement int  // Only findings of this engagement (0 = all engagements)
+	Verified   bool // Only verified findings
+	Limit      int  // Most findings returned (default: 100)
+}
+
+// FindCriticalFindings returns the active Critical findings the query
+// selects, newest first, paging through DefectDojo up to query.Limit.
+func (c *Client) FindCriticalFindings(ctx context.Context, query CriticalFindingsQuery) ([]types.Finding, error) {
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultCriticalFindings
+	}
+	active := true
+	filter := types.FindingsFilter{Active: &active, Severity: "Critical", Ordering: "-date"}
+	if query.Product > 0 {
+		filter.Product = &query.Product
+	}
+	if query.Engagement > 0 {
+		filter.Engagement = &query.Engagement
+	}
+	if query.Verified {
+		filter.Verified = &query.Verified
+	}
+
+	findings := []types.Finding{}
+	for len(findings) < limit {
+		filter.Limit = min(findingsPageSize, limit-len(findings))
+		filter.Offset = len(findings)
+		page, err := c.api.GetFindings(ctx, filter)
+		if err != nil {
+			return nil, fmt.Errorf("listing critical findings: %w", err)
+		}
+		findings = append(findings, page.Results...)
+		if page.Next == nil || len(page.Results) == 0 {
+			break
+		}
+	}
+	return findings, nil
+}
+
+// CloseFinding closes a finding as mitigated now, recording note on it if
+// set, and returns the closed finding.
+func (c *Client) CloseFinding(ctx context.Context, findingID int, note string) (*types.Finding, error) {
+	if findingID <= 0 {
+		return nil, fmt.Errorf("invalid finding ID %d", findingID)
+	}
+	finding, err := c.api.CloseFinding(ctx, findingID, types.CloseFindingRequest{Note: note})
+	if err != nil {
+		return nil, fmt.Errorf("closing finding %d: %w", findingID, err)
+	}
+	return finding, nil
+}
+
+// ImportScan uploads a scan report, creating a new test, and returns the
+// test with its finding counts.
+func (c *Client) ImportScan(ctx context.Context, request types.ImportScanRequest) (*types.ImportScanResponse, error) {
+	switch {
+	case request.ScanType == "":
+		return nil, errors.New("scan type is required")
+	case len(request.File) == 0:
+		return nil, errors.New("scan report is empty")
+	case request.Engagement == 0 && (request.ProductName == "" || request.EngagementName == ""):
+		return nil, errors.New("an engagement ID, or a product and engagement name, is required")
+	}
+	response, err := c.api.ImportScan(ctx, request)
+	if err != nil {
+		return nil, fmt.Errorf("importing %s scan: %w", request.ScanType, err)
+	}
+	return response, nil
+}
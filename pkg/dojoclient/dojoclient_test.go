@@ -0,0 +1,124 @@
+package dojoclient
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/brduru/mcp-defect-dojo/pkg/mockdojo"
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+// fakeAPI serves findings from memory and records the calls it gets
+type fakeAPI struct {
+	findings []types.Finding
+	filters  []types.FindingsFilter
+	closed   map[int]types.CloseFindingRequest
+	imported []types.ImportScanRequest
+}
+
+func (f *fakeAPI) GetFindings(ctx context.Context, filter types.FindingsFilter) (*types.FindingsResponse, error) {
+	f.filters = append(f.filters, filter)
+	end := min(filter.Offset+filter.Limit, len(f.findings))
+	response := &types.FindingsResponse{Count: len(f.findings), Results: f.findings[filter.Offset:end]}
+	if end < len(f.findings) {
+		next := "next"
+		response.Next = &next
+	}
+	return response, nil
+}
+
+func (f *fakeAPI) CloseFinding(ctx context.Context, findingID int, request types.CloseFindingRequest) (*types.Finding, error) {
+	if findingID == 404 {
+		return nil, errors.New("not found")
+	}
+	f.closed[findingID] = request
+	return &types.Finding{ID: findingID, IsMitigated: true}, nil
+}
+
+func (f *fakeAPI) ImportScan(ctx context.Context, request types.ImportScanRequest) (*types.ImportScanResponse, error) {
+	f.imported = append(f.imported, request)
+	return &types.ImportScanResponse{Test: 7, Engagement: request.Engagement}, nil
+}
+
+func TestFindCriticalFindings(t *testing.T) {
+	api := &fakeAPI{findings: make([]types.Finding, 250)}
+	client := FromAPI(api)
+	ctx := context.Background()
+
+	findings, err := client.FindCriticalFindings(ctx, CriticalFindingsQuery{Product: 3, Limit: 150})
+	if err != nil || len(findings) != 150 {
+		t.Fatalf("FindCriticalFindings() = %d findings, %v", len(findings), err)
+	}
+	if len(api.filters) != 2 || api.filters[1].Offset != 100 || api.filters[1].Limit != 50 {
+		t.Errorf("expected two pages of 100 and 50 findings, got %+v", api.filters)
+	}
+	filter := api.filters[0]
+	if filter.Severity != "Critical" || !*filter.Active || *filter.Product != 3 || filter.Engagement != nil || filter.Verified != nil {
+		t.Errorf("unexpected filter %+v", filter)
+	}
+
+	api.filters = nil
+	if findings, _ := client.FindCriticalFindings(ctx, CriticalFindingsQuery{Limit: 1000}); len(findings) != 250 || len(api.filters) != 3 {
+		t.Errorf("expected all 250 findings in 3 pages, got %d in %d", len(findings), len(api.filters))
+	}
+}
+
+func TestCloseFindingAndImportScan(t *testing.T) {
+	api := &fakeAPI{closed: map[int]types.CloseFindingRequest{}}
+	client := FromAPI(api)
+	ctx := context.Background()
+
+	finding, err := client.CloseFinding(ctx, 12, "Fixed in 2.4")
+	if err != nil || !finding.IsMitigated || api.closed[12].Note != "Fixed in 2.4" {
+		t.Fatalf("CloseFinding() = %+v, %v", finding, err)
+	}
+	if _, err := client.CloseFinding(ctx, 404, ""); err == nil || !strings.Contains(err.Error(), "closing finding 404") {
+		t.Errorf("expected the failure to name the finding, got %v", err)
+	}
+	if _, err := client.CloseFinding(ctx, 0, ""); err == nil {
+		t.Error("expected an invalid finding ID to be rejected")
+	}
+
+	response, err := client.ImportScan(ctx, types.ImportScanRequest{ScanType: "SARIF", Engagement: 4, File: []byte("{}")})
+	if err != nil || response.Test != 7 {
+		t.Fatalf("ImportScan() = %+v, %v", response, err)
+	}
+	for _, request := range []types.ImportScanRequest{
+		{Engagement: 4, File: []byte("{}")},
+		{ScanType: "SARIF", Engagement: 4},
+		{ScanType: "SARIF", ProductName: "Portal", File: []byte("{}")},
+	} {
+		if _, err := client.ImportScan(ctx, request); err == nil {
+			t.Errorf("expected %+v to be rejected", request)
+		}
+	}
+	if len(api.imported) != 1 {
+		t.Errorf("expected invalid imports not to reach DefectDojo, got %d imports", len(api.imported))
+	}
+}
+
+func TestNew(t *testing.T) {
+	dojo, err := mockdojo.New(mockdojo.Options{APIKey: "test-key"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(dojo)
+	defer ts.Close()
+
+	client := New(Config{BaseURL: ts.URL, APIKey: "test-key"})
+	ctx := context.Background()
+	critical, err := client.FindCriticalFindings(ctx, CriticalFindingsQuery{})
+	if err != nil || len(critical) == 0 {
+		t.Fatalf("FindCriticalFindings() = %+v, %v", critical, err)
+	}
+	closed, err := client.CloseFinding(ctx, critical[0].ID, "Fixed")
+	if err != nil || closed.Active || !closed.IsMitigated {
+		t.Fatalf("CloseFinding() = %+v, %v", closed, err)
+	}
+	if after, _ := client.FindCriticalFindings(ctx, CriticalFindingsQuery{}); len(after) != len(critical)-1 {
+		t.Errorf("expected the closed finding to leave the critical findings, got %d of %d", len(after), len(critical))
+	}
+}
@@ -0,0 +1,396 @@
+package mcpserver
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/brduru/mcp-defect-dojo/internal/logfields"
+	"github.com/brduru/mcp-defect-dojo/internal/requestid"
+)
+
+// Pending action states
+const (
+	ActionPending  = "pending"  // Waiting for a human decision
+	ActionApproved = "approved" // Approved and applied successfully
+	ActionRejected = "rejected" // Rejected; never applied
+	ActionFailed   = "failed"   // Approved, but applying it returned an error
+)
+
+// actionStatuses returns the states accepted by list_pending_actions
+func actionStatuses() []string {
+	return []string{ActionPending, ActionApproved, ActionRejected, ActionFailed}
+}
+
+// PendingAction is a write tool call held back for human approval.
+type PendingAction struct {
+	ID        int            `json:"id"`
+	Tool      string         `json:"tool"`                 // Write tool the agent called
+	Arguments map[string]any `json:"arguments"`            // Arguments as sent by the agent
+	Caller    string         `json:"caller,omitempty"`     // Authenticated caller identity, if known
+	RequestID string         `json:"request_id,omitempty"` // Correlation ID of the original call
+	Status    string         `json:"status"`               // pending, approved, rejected or failed
+	CreatedAt time.Time      `json:"created_at"`
+	DecidedAt time.Time      `json:"decided_at,omitzero"`
+	Reason    string         `json:"reason,omitempty"` // Why the action was rejected
+	Result    string         `json:"result,omitempty"` // Tool output once applied
+	Error     string         `json:"error,omitempty"`  // Tool error if applying failed
+}
+
+// maxDecidedActions bounds the decided actions kept for list_pending_actions;
+// the oldest are dropped first. Pending actions are always kept.
+const maxDecidedActions = 500
+
+// Errors returned when deciding on a pending action
+var (
+	ErrActionNotFound   = errors.New("pending action not found")
+	ErrActionNotPending = errors.New("action has already been decided")
+)
+
+// approvalQueue holds write calls until they are approved or rejected.
+// Approved calls run the rest of the tool chain (audit, policy, handler),
+// so what is applied is audited and policy-checked at approval time.
+type approvalQueue struct {
+	mu         sync.Mutex
+	nextID     int
+	actions    []*queuedAction
+	maxDecided int // Decided actions kept, see maxDecidedActions
+}
+
+type queuedAction struct {
+	PendingAction
+	run     server.ToolHandlerFunc
+	req     mcp.CallToolRequest
+	timeout time.Duration // The call's timeout_seconds, applied again when it runs (0 = none)
+}
+
+func newApprovalQueue() *approvalQueue {
+	return &approvalQueue{nextID: 1, maxDecided: maxDecidedActions}
+}
+
+// prune drops the oldest decided actions beyond maxDecided. Callers hold q.mu.
+func (q *approvalQueue) prune() {
+	decided := 0
+	for _, action := range q.actions {
+		if action.Status != ActionPending {
+			decided++
+		}
+	}
+	excess := decided - q.maxDecided
+	if excess <= 0 {
+		return
+	}
+	kept := q.actions[:0]
+	for _, action := range q.actions {
+		if excess > 0 && action.Status != ActionPending {
+			excess--
+			continue
+		}
+		kept = append(kept, action)
+	}
+	clear(q.actions[len(kept):])
+	q.actions = kept
+}
+
+// enqueue records a write call and returns its pending action
+func (q *approvalQueue) enqueue(ctx context.Context, request mcp.CallToolRequest, run server.ToolHandlerFunc) PendingAction {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	action := &queuedAction{
+		PendingAction: PendingAction{
+			ID:        q.nextID,
+			Tool:      request.Params.Name,
+			Arguments: request.GetArguments(),
+			Caller:    CallerIdentityFromContext(ctx),
+			RequestID: RequestIDFromContext(ctx),
+			Status:    ActionPending,
+			CreatedAt: time.Now().UTC(),
+		},
+		run:     run,
+		req:     request,
+		timeout: time.Duration(request.GetFloat(timeoutArgument, 0) * float64(time.Second)),
+	}
+	q.nextID++
+	q.actions = append(q.actions, action)
+	return action.PendingAction
+}
+
+// list returns actions in the given status, or all actions when status is empty
+func (q *approvalQueue) list(status string) []PendingAction {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	actions := []PendingAction{}
+	for _, action := range q.actions {
+		if status == "" || action.Status == status {
+			actions = append(actions, action.PendingAction)
+		}
+	}
+	return actions
+}
+
+// decide marks a pending action as taken and returns it. Only one caller can
+// decide an action, so concurrent approvals never apply a write twice.
+func (q *approvalQueue) decide(id int, status string) (*queuedAction, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, action := range q.actions {
+		if action.ID != id {
+			continue
+		}
+		if action.Status != ActionPending {
+			return nil, fmt.Errorf("action %d is %s: %w", id, action.Status, ErrActionNotPending)
+		}
+		action.Status = status
+		action.DecidedAt = time.Now().UTC()
+		q.prune()
+		return action, nil
+	}
+	return nil, fmt.Errorf("action %d: %w", id, ErrActionNotFound)
+}
+
+// approve applies a pending action on behalf of its original caller. It runs
+// under the original call's request ID and timeout_seconds, so audit records
+// and DefectDojo's X-Request-ID match the queued action.
+func (q *approvalQueue) approve(ctx context.Context, id int) (PendingAction, error) {
+	action, err := q.decide(id, ActionApproved)
+	if err != nil {
+		return PendingAction{}, err
+	}
+
+	if action.Caller != "" {
+		ctx = WithCallerIdentity(ctx, action.Caller)
+	}
+	if action.RequestID != "" {
+		ctx = requestid.WithID(ctx, action.RequestID)
+		ctx = logfields.With(ctx, logfields.Fields{Tool: action.Tool, RequestID: action.RequestID})
+	}
+	if action.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, action.timeout)
+		defer cancel()
+	}
+	result, runErr := action.run(ctx, action.req)
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	switch {
+	case runErr != nil:
+		action.Status, action.Error = ActionFailed, runErr.Error()
+	case result != nil && result.IsError:
+		action.Status, action.Error = ActionFailed, toolResultText(result)
+	default:
+		action.Result = toolResultText(result)
+	}
+	return action.PendingAction, nil
+}
+
+// reject discards a pending action
+func (q *approvalQueue) reject(id int, reason string) (PendingAction, error) {
+	action, err := q.decide(id, ActionRejected)
+	if err != nil {
+		return PendingAction{}, err
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	action.Reason = reason
+	return action.PendingAction, nil
+}
+
+// toolResultText joins the text content of a tool result
+func toolResultText(result *mcp.CallToolResult) string {
+	if result == nil {
+		return ""
+	}
+	var text strings.Builder
+	for _, content := range result.Content {
+		if tc, ok := content.(mcp.TextContent); ok {
+			text.WriteString(tc.Text)
+		}
+	}
+	return text.String()
+}
+
+// approvalHold decides whether a write call must wait for a human, returning
+// why it does or "" to run it right away
+type approvalHold func(ctx context.Context, request mcp.CallToolRequest) (string, error)
+
+// approvalMiddleware queues write tool calls instead of running them: all of
+// them when hold is nil, otherwise those hold selects. The agent is told the
+// change is pending; a human applies it later through ApproveAction or the
+// approval HTTP endpoints.
+func approvalMiddleware(queue *approvalQueue, hold approvalHold) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if !writeTools[request.Params.Name] {
+				return next(ctx, request)
+			}
+			var reason string
+			if hold != nil {
+				var err error
+				if reason, err = hold(ctx, request); err != nil {
+					return nil, err
+				}
+				if reason == "" {
+					return next(ctx, request)
+				}
+			}
+			action := queue.enqueue(ctx, request, next)
+			message := fmt.Sprintf("Queued for human approval as pending action #%d (%s). Nothing has been changed yet; check list_pending_actions for the decision.", action.ID, action.Tool)
+			if reason != "" {
+				message = fmt.Sprintf("Queued for human approval as pending action #%d (%s) because %s. Nothing has been changed yet; check list_pending_actions for the decision.", action.ID, action.Tool, reason)
+			}
+			return mcp.NewToolResultText(message), nil
+		}
+	}
+}
+
+// listPendingActions handles list_pending_actions
+func (s *Server) listPendingActions(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if s.approvals == nil {
+		return mcp.NewToolResultText("Approval mode is disabled: write tools apply changes immediately"), nil
+	}
+
+	status := strings.ToLower(request.GetString("status", ActionPending))
+	actions := s.approvals.list(status)
+	if len(actions) == 0 {
+		return mcp.NewToolResultText(fmt.Sprintf("No %s actions", status)), nil
+	}
+
+	result := fmt.Sprintf("%d %s actions:\n", len(actions), status)
+	for _, action := range actions {
+		arguments, err := json.Marshal(action.Arguments)
+		if err != nil {
+			return nil, fmt.Errorf("error formatting action %d: %w", action.ID, err)
+		}
+		result += fmt.Sprintf("\n#%d %s %s (queued %s)\n", action.ID, action.Tool, arguments, action.CreatedAt.Format(time.RFC3339))
+		switch {
+		case action.Reason != "":
+			result += fmt.Sprintf("  Rejected: %s\n", action.Reason)
+		case action.Error != "":
+			result += fmt.Sprintf("  Failed: %s\n", action.Error)
+		}
+	}
+	return mcp.NewToolResultText(result), nil
+}
+
+// PendingActions returns every write held for approval, in the order it was
+// queued, including decided ones. It returns nil when approval mode is off.
+func (s *Server) PendingActions() []PendingAction {
+	if s.approvals == nil {
+		return nil
+	}
+	return s.approvals.list("")
+}
+
+// ApproveAction applies a pending write. The call runs as its original caller
+// and is audited and policy-checked like a direct call; the returned action
+// carries the tool output or error.
+func (s *Server) ApproveAction(ctx context.Context, id int) (PendingAction, error) {
+	if s.approvals == nil {
+		return PendingAction{}, fmt.Errorf("action %d: %w", id, ErrActionNotFound)
+	}
+	return s.approvals.approve(ctx, id)
+}
+
+// RejectAction discards a pending write without applying it.
+func (s *Server) RejectAction(id int, reason string) (PendingAction, error) {
+	if s.approvals == nil {
+		return PendingAction{}, fmt.Errorf("action %d: %w", id, ErrActionNotFound)
+	}
+	return s.approvals.reject(id, reason)
+}
+
+// ApprovalHandler returns an http.Handler for humans reviewing queued writes:
+//
+//   - GET /actions?status=pending: list actions as JSON (default: all)
+//   - POST /actions/{id}/approve: apply the action
+//   - POST /actions/{id}/reject: discard it; optional JSON body {"reason": "..."}
+//
+// Decisions answer with the updated action. When ApprovalConfig.Token is set,
+// every request must carry it in the Authorization header as "Bearer <token>";
+// without it the handler is unauthenticated and must only be reachable by
+// reviewers, never by the agent.
+func (s *Server) ApprovalHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /actions", func(w http.ResponseWriter, r *http.Request) {
+		actions := s.PendingActions()
+		if status := r.URL.Query().Get("status"); status != "" && s.approvals != nil {
+			actions = s.approvals.list(status)
+		}
+		writeJSON(w, http.StatusOK, actions)
+	})
+	mux.HandleFunc("POST /actions/{id}/approve", func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid action id"})
+			return
+		}
+		action, err := s.ApproveAction(r.Context(), id)
+		writeDecision(w, action, err)
+	})
+	mux.HandleFunc("POST /actions/{id}/reject", func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid action id"})
+			return
+		}
+		var body struct {
+			Reason string `json:"reason"`
+		}
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid JSON body: " + err.Error()})
+				return
+			}
+		}
+		action, err := s.RejectAction(id, body.Reason)
+		writeDecision(w, action, err)
+	})
+
+	token := s.config.Approval.Token
+	if token == "" {
+		return mux
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, bearer := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !bearer || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "missing or invalid approval token"})
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// writeDecision answers an approve or reject request
+func writeDecision(w http.ResponseWriter, action PendingAction, err error) {
+	switch {
+	case errors.Is(err, ErrActionNotFound):
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, ErrActionNotPending):
+		writeJSON(w, http.StatusConflict, map[string]string{"error": err.Error()})
+	case err != nil:
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	default:
+		writeJSON(w, http.StatusOK, action)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
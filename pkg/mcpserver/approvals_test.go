@@ -0,0 +1,246 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/brduru/mcp-defect-dojo/internal/requestid"
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+func TestApprovalQueue(t *testing.T) {
+	var applied []int
+	var records []AuditRecord
+	mock := &MockDefectDojoClient{
+		MarkFalsePositiveFunc: func(ctx context.Context, findingID int, request types.FalsePositiveRequest) (*types.FalsePositiveResponse, error) {
+			applied = append(applied, findingID)
+			return &types.FalsePositiveResponse{ID: findingID, FalseP: true, Justification: request.Justification}, nil
+		},
+	}
+	audit := AuditLoggerFunc(func(ctx context.Context, record AuditRecord) error {
+		records = append(records, record)
+		return nil
+	})
+	s := newServer(&Config{Approval: ApprovalConfig{Required: true}, Audit: AuditConfig{Logger: audit}}, mock)
+
+	result, err := callTool(t, s, "mark_finding_false_positive", map[string]any{"finding_id": 42, "justification": "Test fixture"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := resultText(result); !strings.Contains(text, "pending action #1") {
+		t.Errorf("expected the write to be queued, got: %s", text)
+	}
+	if len(applied) != 0 || len(records) != 0 {
+		t.Fatalf("expected nothing applied or audited before approval, got %v and %d records", applied, len(records))
+	}
+
+	result, err = callTool(t, s, "list_pending_actions", map[string]any{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := resultText(result); !strings.Contains(text, `#1 mark_finding_false_positive {"finding_id":42,"justification":"Test fixture"}`) {
+		t.Errorf("expected the pending action to be listed, got: %s", text)
+	}
+
+	action, err := s.ApproveAction(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("ApproveAction() error = %v", err)
+	}
+	if action.Status != ActionApproved || !strings.Contains(action.Result, "Successfully marked finding 42") {
+		t.Errorf("unexpected approved action: %+v", action)
+	}
+	if len(applied) != 1 || applied[0] != 42 {
+		t.Errorf("expected finding 42 to be applied once, got %v", applied)
+	}
+	if len(records) != 1 || !records[0].Success || records[0].FindingID != 42 {
+		t.Errorf("expected the applied write to be audited, got %+v", records)
+	}
+
+	if _, err := s.ApproveAction(context.Background(), 1); !errors.Is(err, ErrActionNotPending) {
+		t.Errorf("expected a second approval to be refused, got %v", err)
+	}
+	if _, err := s.RejectAction(7, "no"); !errors.Is(err, ErrActionNotFound) {
+		t.Errorf("expected unknown action to be reported, got %v", err)
+	}
+
+	if _, err := callTool(t, s, "get_finding_detail", map[string]any{"finding_id": 42}); err != nil {
+		t.Errorf("expected reads to bypass the queue, got %v", err)
+	}
+}
+
+func TestApprovalKeepsRequestIDAndTimeout(t *testing.T) {
+	var appliedID string
+	var deadline time.Time
+	var records []AuditRecord
+	mock := &MockDefectDojoClient{
+		MarkFalsePositiveFunc: func(ctx context.Context, findingID int, request types.FalsePositiveRequest) (*types.FalsePositiveResponse, error) {
+			appliedID = requestid.FromContext(ctx)
+			deadline, _ = ctx.Deadline()
+			return &types.FalsePositiveResponse{ID: findingID, FalseP: true}, nil
+		},
+	}
+	audit := AuditLoggerFunc(func(ctx context.Context, record AuditRecord) error {
+		records = append(records, record)
+		return nil
+	})
+	s := newServer(&Config{Approval: ApprovalConfig{Required: true}, Audit: AuditConfig{Logger: audit}}, mock)
+
+	if _, err := callTool(t, s, "mark_finding_false_positive", map[string]any{"finding_id": 42, "justification": "Test fixture", "timeout_seconds": 30}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	queued := s.PendingActions()[0]
+	if queued.RequestID == "" {
+		t.Fatal("expected the queued action to record the request ID")
+	}
+
+	if _, err := s.ApproveAction(context.Background(), queued.ID); err != nil {
+		t.Fatalf("ApproveAction() error = %v", err)
+	}
+	if appliedID != queued.RequestID {
+		t.Errorf("expected the write to carry request ID %q, got %q", queued.RequestID, appliedID)
+	}
+	if len(records) != 1 || records[0].RequestID != queued.RequestID {
+		t.Errorf("expected the audit record to carry request ID %q, got %+v", queued.RequestID, records)
+	}
+	if deadline.IsZero() || deadline.After(time.Now().Add(30*time.Second)) {
+		t.Errorf("expected the 30s timeout_seconds to bound the write, got deadline %v", deadline)
+	}
+}
+
+func TestApprovalHandler(t *testing.T) {
+	var applied int
+	mock := &MockDefectDojoClient{
+		MarkFalsePositiveFunc: func(ctx context.Context, findingID int, request types.FalsePositiveRequest) (*types.FalsePositiveResponse, error) {
+			applied++
+			return &types.FalsePositiveResponse{ID: findingID}, nil
+		},
+	}
+	s := newServer(&Config{Approval: ApprovalConfig{Required: true}}, mock)
+	for _, id := range []int{5, 6} {
+		if _, err := callTool(t, s, "clear_false_positive", map[string]any{"finding_id": id, "justification": "Confirmed"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	handler := s.ApprovalHandler()
+	serve := func(method, path, body string) (*httptest.ResponseRecorder, PendingAction) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+		var action PendingAction
+		json.Unmarshal(rec.Body.Bytes(), &action)
+		return rec, action
+	}
+
+	rec, _ := serve(http.MethodGet, "/actions?status=pending", "")
+	var pending []PendingAction
+	if err := json.Unmarshal(rec.Body.Bytes(), &pending); err != nil || len(pending) != 2 {
+		t.Fatalf("expected two pending actions, got %s (%v)", rec.Body, err)
+	}
+
+	rec, action := serve(http.MethodPost, "/actions/1/approve", "")
+	if rec.Code != http.StatusOK || action.Status != ActionApproved || applied != 1 {
+		t.Errorf("expected action 1 to be applied, got %d %+v", rec.Code, action)
+	}
+
+	rec, action = serve(http.MethodPost, "/actions/2/reject", `{"reason": "Scanner is right"}`)
+	if rec.Code != http.StatusOK || action.Status != ActionRejected || action.Reason != "Scanner is right" || applied != 1 {
+		t.Errorf("expected action 2 to be rejected, got %d %+v", rec.Code, action)
+	}
+
+	if rec, _ := serve(http.MethodPost, "/actions/2/approve", ""); rec.Code != http.StatusConflict {
+		t.Errorf("expected 409 for a decided action, got %d", rec.Code)
+	}
+	if rec, _ := serve(http.MethodPost, "/actions/9/approve", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown action, got %d", rec.Code)
+	}
+
+	result, err := callTool(t, s, "list_pending_actions", map[string]any{"status": "rejected"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := resultText(result); !strings.Contains(text, "Rejected: Scanner is right") {
+		t.Errorf("expected the rejection reason, got: %s", text)
+	}
+}
+
+func TestListPendingActionsWithoutApprovalMode(t *testing.T) {
+	s := newServer(&Config{}, &MockDefectDojoClient{})
+
+	result, err := callTool(t, s, "list_pending_actions", map[string]any{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := resultText(result); !strings.Contains(text, "Approval mode is disabled") {
+		t.Errorf("unexpected result: %s", text)
+	}
+	if actions := s.PendingActions(); actions != nil {
+		t.Errorf("expected no actions, got %v", actions)
+	}
+}
+
+func TestApprovalHandlerToken(t *testing.T) {
+	s := newServer(&Config{Approval: ApprovalConfig{Required: true, Token: "reviewers"}}, &MockDefectDojoClient{})
+	if _, err := callTool(t, s, "clear_false_positive", map[string]any{"finding_id": 5, "justification": "Confirmed"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	handler := s.ApprovalHandler()
+	for _, authorization := range []string{"", "reviewers", "Bearer agent"} {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/actions/1/approve", nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		handler.ServeHTTP(rec, req)
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("Authorization %q: expected 401, got %d", authorization, rec.Code)
+		}
+	}
+	if actions := s.PendingActions(); actions[0].Status != ActionPending {
+		t.Fatalf("expected the action to stay pending, got %+v", actions[0])
+	}
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/actions/1/approve", nil)
+	req.Header.Set("Authorization", "Bearer reviewers")
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected the reviewer token to be accepted, got %d: %s", rec.Code, rec.Body)
+	}
+}
+
+func TestApprovalQueuePrunesDecidedActions(t *testing.T) {
+	queue := newApprovalQueue()
+	queue.maxDecided = 2
+	run := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("done"), nil
+	}
+	for range 5 {
+		queue.enqueue(context.Background(), mcp.CallToolRequest{}, run)
+	}
+	for _, id := range []int{1, 2, 4} {
+		if _, err := queue.reject(id, "no"); err != nil {
+			t.Fatalf("reject(%d) error = %v", id, err)
+		}
+	}
+	if _, err := queue.approve(context.Background(), 5); err != nil {
+		t.Fatalf("approve(5) error = %v", err)
+	}
+
+	var ids []int
+	for _, action := range queue.list("") {
+		ids = append(ids, action.ID)
+	}
+	if !slices.Equal(ids, []int{3, 4, 5}) {
+		t.Errorf("expected the pending action and the two latest decisions, got %v", ids)
+	}
+}
@@ -0,0 +1,135 @@
+package mcpserver
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/brduru/mcp-defect-dojo/internal/defectdojo"
+	"github.com/brduru/mcp-defect-dojo/internal/refcache"
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+// assigneeMe names the user owning the API token of the call
+const assigneeMe = "me"
+
+// resolveUser finds a DefectDojo user by exact login name, or the API token
+// owner for "me". Users are kept in the reference cache, the token owner
+// under a hash of the token, as per-product credentials belong to other users.
+func (s *Server) resolveUser(ctx context.Context, username string) (*types.User, error) {
+	username = strings.TrimSpace(username)
+	if username == assigneeMe {
+		apiKey, _ := defectdojo.APIKeyFromContext(ctx)
+		token := sha256.Sum256([]byte(apiKey))
+		return refcache.Get(s.refs, refUser+":@token:"+hex.EncodeToString(token[:8]), func() (*types.User, error) {
+			status := s.ddClient.CheckHealth(ctx)
+			if status.User == "" {
+				return nil, fmt.Errorf("cannot tell which DefectDojo user owns the API token: %s", status.Error)
+			}
+			return s.lookupUser(ctx, status.User)
+		})
+	}
+	return refcache.Get(s.refs, refUser+":"+username, func() (*types.User, error) {
+		return s.lookupUser(ctx, username)
+	})
+}
+
+// lookupUser queries the users API for one login name
+func (s *Server) lookupUser(ctx context.Context, username string) (*types.User, error) {
+	response, err := s.ddClient.ListUsers(ctx, username, 1, 0)
+	if err != nil {
+		return nil, fmt.Errorf("error looking up user %q: %w", username, err)
+	}
+	if len(response.Results) == 0 {
+		return nil, fmt.Errorf("unknown DefectDojo user %q: use the exact login name", username)
+	}
+	return &response.Results[0], nil
+}
+
+// assigneeFilter narrows a findings filter to the findings assigned to the
+// user named by the assigned_to argument, if any
+func (s *Server) assigneeFilter(ctx context.Context, request mcp.CallToolRequest, filter *types.FindingsFilter) error {
+	username := request.GetString("assigned_to", "")
+	if username == "" {
+		return nil
+	}
+	user, err := s.resolveUser(ctx, username)
+	if err != nil {
+		return fmt.Errorf("invalid assigned_to: %w", err)
+	}
+	filter.Reviewers = []int{user.ID}
+	return nil
+}
+
+// assignFinding handles assign_finding. DefectDojo has no single owner field,
+// so the assignees are the finding's reviewers: by default the user replaces
+// them, with keep_existing the user joins them. Like the client's updates,
+// the if_unmodified_since check cannot see a change landing between the
+// read and the PATCH.
+func (s *Server) assignFinding(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	findingID, err := request.RequireInt("finding_id")
+	if err != nil {
+		return nil, fmt.Errorf("invalid finding_id: %w", err)
+	}
+	username, err := request.RequireString("username")
+	if err != nil {
+		return nil, fmt.Errorf("invalid username: %w", err)
+	}
+	ifUnmodifiedSince, err := optionalTimestamp(request, "if_unmodified_since")
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.resolveUser(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsActive {
+		return nil, fmt.Errorf("DefectDojo user %s is deactivated and cannot be assigned findings", user.DisplayName())
+	}
+
+	current, err := s.ddClient.GetFindingDetail(ctx, findingID)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving finding %d: %w", findingID, err)
+	}
+	if err := defectdojo.CheckUnmodified(current, ifUnmodifiedSince); err != nil {
+		return nil, err
+	}
+	assignees := []int{user.ID}
+	if request.GetBool("keep_existing", false) {
+		assignees = append(slices.Clone(current.Reviewers), user.ID)
+		slices.Sort(assignees)
+		assignees = slices.Compact(assignees)
+	}
+
+	finding, err := s.ddClient.AssignFinding(ctx, findingID, assignees)
+	if err != nil {
+		return nil, fmt.Errorf("error assigning finding %d to %s: %w", findingID, user.Username, err)
+	}
+
+	result := fmt.Sprintf("Assigned finding %d (%s) to %s\n", finding.ID, s.sanitizedText(cmp.Or(finding.Title, current.Title)), user.DisplayName())
+	if others := slices.DeleteFunc(slices.Clone(finding.Reviewers), func(id int) bool { return id == user.ID }); len(others) > 0 {
+		result += fmt.Sprintf("Also assigned: %s\n", formatUserIDs(others))
+	}
+	if previous := slices.DeleteFunc(slices.Clone(current.Reviewers), func(id int) bool { return slices.Contains(finding.Reviewers, id) }); len(previous) > 0 {
+		result += fmt.Sprintf("No longer assigned: %s\n", formatUserIDs(previous))
+	}
+	if link := s.links.finding(finding.ID); link != "" {
+		result += link + "\n"
+	}
+	return mcp.NewToolResultText(result), nil
+}
+
+// formatUserIDs renders user IDs the way finding output shows them, e.g. "user 1, user 4"
+func formatUserIDs(ids []int) string {
+	users := make([]string, len(ids))
+	for i, id := range ids {
+		users[i] = fmt.Sprintf("user %d", id)
+	}
+	return strings.Join(users, ", ")
+}
@@ -0,0 +1,156 @@
+package mcpserver
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brduru/mcp-defect-dojo/internal/defectdojo"
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+func TestAssignFinding(t *testing.T) {
+	fixtures, err := defectdojo.NewFixtureClient("")
+	if err != nil {
+		t.Fatalf("NewFixtureClient() error = %v", err)
+	}
+	s := newServer(&Config{}, fixtures)
+
+	result, err := callTool(t, s, "assign_finding", map[string]any{"finding_id": 3, "username": "dana"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := resultText(result); !strings.Contains(text, "Assigned finding 3 (Hardcoded database password) to Dana Whitfield (dana)") {
+		t.Errorf("unexpected output: %s", text)
+	}
+
+	result, err = callTool(t, s, "assign_finding", map[string]any{"finding_id": 3, "username": "ci-bot", "keep_existing": true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := resultText(result); !strings.Contains(text, "to CI Bot (ci-bot)\nAlso assigned: user 1\n") {
+		t.Errorf("expected dana to stay assigned, got: %s", text)
+	}
+	finding, _ := fixtures.GetFindingDetail(context.Background(), 3)
+	if !slices.Equal(finding.Reviewers, []int{1, 2}) {
+		t.Errorf("expected reviewers [1 2], got %v", finding.Reviewers)
+	}
+
+	// Differs from the first call, so it is not replayed as a duplicate
+	result, err = callTool(t, s, "assign_finding", map[string]any{"finding_id": 3, "username": "dana", "keep_existing": false})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := resultText(result); !strings.Contains(text, "No longer assigned: user 2") {
+		t.Errorf("expected ci-bot to be replaced, got: %s", text)
+	}
+
+	result, err = callTool(t, s, "get_defectdojo_findings", map[string]any{"assigned_to": "dana", "format": "json"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := resultText(result); !strings.Contains(text, `"id": 3,`) || strings.Contains(text, `"id": 1,`) {
+		t.Errorf("expected only finding 3 assigned to dana, got: %s", text)
+	}
+
+	if _, err := callTool(t, s, "assign_finding", map[string]any{"finding_id": 3, "username": "Dana"}); err == nil || !strings.Contains(err.Error(), `unknown DefectDojo user "Dana"`) {
+		t.Errorf("expected login names to match exactly, got %v", err)
+	}
+	if _, err := callTool(t, s, "get_defectdojo_findings", map[string]any{"assigned_to": "nobody"}); err == nil || !strings.Contains(err.Error(), "invalid assigned_to") {
+		t.Errorf("expected an unknown assignee to be reported, got %v", err)
+	}
+	if _, err := callTool(t, s, "assign_finding", map[string]any{"finding_id": 999, "username": "dana"}); err == nil || !strings.Contains(err.Error(), "error retrieving finding 999") {
+		t.Errorf("expected a missing finding to be reported, got %v", err)
+	}
+}
+
+func TestAssignFindingIfUnmodifiedSince(t *testing.T) {
+	fixtures, err := defectdojo.NewFixtureClient("")
+	if err != nil {
+		t.Fatalf("NewFixtureClient() error = %v", err)
+	}
+	s := newServer(&Config{}, fixtures)
+	finding, _ := fixtures.GetFindingDetail(context.Background(), 3)
+
+	stale := finding.Modified.Add(-time.Hour).Format(time.RFC3339)
+	if _, err := callTool(t, s, "assign_finding", map[string]any{"finding_id": 3, "username": "dana", "if_unmodified_since": stale}); err == nil || !strings.Contains(err.Error(), "conflict") {
+		t.Errorf("expected a conflict for a finding changed since, got %v", err)
+	}
+	if finding, _ := fixtures.GetFindingDetail(context.Background(), 3); len(finding.Reviewers) != 0 {
+		t.Errorf("expected the conflicting assignment not to be made, got reviewers %v", finding.Reviewers)
+	}
+
+	current := finding.Modified.Format(time.RFC3339)
+	if _, err := callTool(t, s, "assign_finding", map[string]any{"finding_id": 3, "username": "dana", "if_unmodified_since": current}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestAssignFindingUsers(t *testing.T) {
+	var lookups []string
+	mock := &MockDefectDojoClient{
+		ListUsersFunc: func(ctx context.Context, username string, limit, offset int) (*types.UsersResponse, error) {
+			lookups = append(lookups, username)
+			users := map[string]types.User{
+				"dana":   {ID: 1, Username: "dana", IsActive: true},
+				"former": {ID: 5, Username: "former"},
+				"ci-bot": {ID: 2, Username: "ci-bot", IsActive: true},
+			}
+			if user, ok := users[username]; ok {
+				return &types.UsersResponse{Count: 1, Results: []types.User{user}}, nil
+			}
+			return &types.UsersResponse{Results: []types.User{}}, nil
+		},
+		CheckHealthFunc: func(ctx context.Context) *types.HealthStatus {
+			return &types.HealthStatus{Reachable: true, Authenticated: true, User: "ci-bot"}
+		},
+	}
+	s := newServer(&Config{}, mock)
+
+	for _, id := range []int{7, 8} {
+		result, err := callTool(t, s, "assign_finding", map[string]any{"finding_id": id, "username": "me"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if text := resultText(result); !strings.Contains(text, "to ci-bot") {
+			t.Errorf("expected \"me\" to be the token owner, got: %s", text)
+		}
+	}
+	if !slices.Equal(lookups, []string{"ci-bot"}) {
+		t.Errorf("expected one cached lookup of the token owner, got %v", lookups)
+	}
+
+	if _, err := callTool(t, s, "assign_finding", map[string]any{"finding_id": 7, "username": "former"}); err == nil || !strings.Contains(err.Error(), "deactivated") {
+		t.Errorf("expected deactivated users to be refused, got %v", err)
+	}
+}
+
+func TestResolveUserMePerCredential(t *testing.T) {
+	owners := map[string]string{"": "ci-bot", "payments-key": "payments-bot", "identity-key": "identity-bot"}
+	mock := &MockDefectDojoClient{
+		ListUsersFunc: func(ctx context.Context, username string, limit, offset int) (*types.UsersResponse, error) {
+			return &types.UsersResponse{Count: 1, Results: []types.User{{ID: len(username), Username: username, IsActive: true}}}, nil
+		},
+		CheckHealthFunc: func(ctx context.Context) *types.HealthStatus {
+			key, _ := defectdojo.APIKeyFromContext(ctx)
+			return &types.HealthStatus{Reachable: true, Authenticated: true, User: owners[key]}
+		},
+	}
+	s := newServer(&Config{}, mock)
+
+	for _, key := range []string{"payments-key", "identity-key", "", "payments-key"} {
+		ctx := context.Background()
+		if key != "" {
+			ctx = defectdojo.WithAPIKey(ctx, key)
+		}
+		user, err := s.resolveUser(ctx, assigneeMe)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if user.Username != owners[key] {
+			t.Errorf("token %q: expected \"me\" to be %s, got %s", key, owners[key], user.Username)
+		}
+	}
+}
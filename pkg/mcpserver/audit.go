@@ -0,0 +1,147 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/brduru/mcp-defect-dojo/internal/defectdojo"
+	"github.com/brduru/mcp-defect-dojo/internal/logfields"
+)
+
+// AuditRecord describes a single mutating tool call.
+// One record is emitted for every write tool invocation, whether it succeeded or not.
+type AuditRecord struct {
+	Timestamp time.Time      `json:"timestamp"`            // When the tool call completed (UTC)
+	Tool      string         `json:"tool"`                 // MCP tool name
+	FindingID int            `json:"finding_id,omitempty"` // Target finding, when the tool operates on one
+	Arguments map[string]any `json:"arguments"`            // Raw tool arguments as sent by the agent
+	Caller    string         `json:"caller,omitempty"`     // Authenticated caller identity, if known
+	RequestID string         `json:"request_id,omitempty"` // Correlation ID shared with logs and DefectDojo (X-Request-ID)
+	Status    int            `json:"status"`               // DefectDojo HTTP status (0 = request never completed)
+	Success   bool           `json:"success"`              // Whether the operation succeeded
+	Error     string         `json:"error,omitempty"`      // Error message for failed operations
+}
+
+// AuditLogger receives audit records for mutating operations.
+// Implementations must be safe for concurrent use.
+type AuditLogger interface {
+	LogAudit(ctx context.Context, record AuditRecord) error
+}
+
+// AuditLoggerFunc adapts an ordinary function to the AuditLogger interface.
+type AuditLoggerFunc func(ctx context.Context, record AuditRecord) error
+
+// LogAudit calls f(ctx, record).
+func (f AuditLoggerFunc) LogAudit(ctx context.Context, record AuditRecord) error {
+	return f(ctx, record)
+}
+
+// FileAuditLogger appends audit records to a JSON lines file.
+// The file is opened in append-only mode for every record, so external log
+// rotation works without signalling the server.
+type FileAuditLogger struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileAuditLogger creates an audit logger writing to the given path.
+func NewFileAuditLogger(path string) *FileAuditLogger {
+	return &FileAuditLogger{path: path}
+}
+
+// LogAudit appends the record as a single JSON line.
+func (l *FileAuditLogger) LogAudit(ctx context.Context, record AuditRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("marshaling audit record: %w", err)
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("opening audit log: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(line); err != nil {
+		return fmt.Errorf("writing audit log: %w", err)
+	}
+	return nil
+}
+
+type callerIdentityKey struct{}
+
+// WithCallerIdentity returns a context carrying the authenticated caller identity.
+// Transports that authenticate their clients use this so that audit records
+// can attribute changes to a specific caller.
+func WithCallerIdentity(ctx context.Context, identity string) context.Context {
+	return context.WithValue(ctx, callerIdentityKey{}, identity)
+}
+
+// CallerIdentityFromContext returns the caller identity stored by WithCallerIdentity.
+func CallerIdentityFromContext(ctx context.Context) string {
+	identity, _ := ctx.Value(callerIdentityKey{}).(string)
+	return identity
+}
+
+// auditMiddleware emits an audit record after every write tool call.
+func auditMiddleware(logger AuditLogger) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if !writeTools[request.Params.Name] {
+				return next(ctx, request)
+			}
+
+			result, err := next(ctx, request)
+
+			record := newAuditRecord(ctx, request, err)
+			if logErr := logger.LogAudit(ctx, record); logErr != nil {
+				logfields.Printf(ctx, "audit: failed to record the call: %v", logErr)
+			}
+
+			return result, err
+		}
+	}
+}
+
+// newAuditRecord describes a completed write tool call
+func newAuditRecord(ctx context.Context, request mcp.CallToolRequest, err error) AuditRecord {
+	record := AuditRecord{
+		Timestamp: time.Now().UTC(),
+		Tool:      request.Params.Name,
+		FindingID: request.GetInt("finding_id", 0),
+		Arguments: request.GetArguments(),
+		Caller:    CallerIdentityFromContext(ctx),
+		RequestID: RequestIDFromContext(ctx),
+		Status:    auditStatus(err),
+		Success:   err == nil,
+	}
+	if err != nil {
+		record.Error = err.Error()
+	}
+	return record
+}
+
+// auditStatus derives the DefectDojo response status from a tool error.
+func auditStatus(err error) int {
+	if err == nil {
+		return http.StatusOK
+	}
+	var apiErr *defectdojo.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode
+	}
+	return 0
+}
@@ -0,0 +1,120 @@
+package mcpserver
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/brduru/mcp-defect-dojo/internal/defectdojo"
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+func TestAuditMiddleware(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		records []AuditRecord
+	)
+	logger := AuditLoggerFunc(func(ctx context.Context, record AuditRecord) error {
+		mu.Lock()
+		defer mu.Unlock()
+		records = append(records, record)
+		return nil
+	})
+
+	mock := &MockDefectDojoClient{}
+	s := newServer(&Config{Audit: AuditConfig{Logger: logger}}, mock)
+
+	t.Run("read tools are not audited", func(t *testing.T) {
+		if _, err := callTool(t, s, "get_finding_detail", map[string]any{"finding_id": 1}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(records) != 0 {
+			t.Errorf("expected no audit records, got %d", len(records))
+		}
+	})
+
+	t.Run("successful write is audited", func(t *testing.T) {
+		args := map[string]any{"finding_id": 42, "justification": "test data"}
+		ctx := WithCallerIdentity(context.Background(), "alice")
+		if _, err := callToolWithContext(t, ctx, s, "mark_finding_false_positive", args); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(records) != 1 {
+			t.Fatalf("expected 1 audit record, got %d", len(records))
+		}
+		rec := records[0]
+		if rec.Tool != "mark_finding_false_positive" || rec.FindingID != 42 {
+			t.Errorf("unexpected record: %+v", rec)
+		}
+		if !rec.Success || rec.Status != 200 {
+			t.Errorf("expected success with status 200, got %+v", rec)
+		}
+		if rec.Caller != "alice" {
+			t.Errorf("expected caller alice, got %q", rec.Caller)
+		}
+		if rec.Arguments["justification"] != "test data" {
+			t.Errorf("expected arguments to be recorded, got %v", rec.Arguments)
+		}
+	})
+
+	t.Run("failed write records API status", func(t *testing.T) {
+		records = nil
+		mock.MarkFalsePositiveFunc = func(ctx context.Context, findingID int, request types.FalsePositiveRequest) (*types.FalsePositiveResponse, error) {
+			return nil, &defectdojo.APIError{StatusCode: 403, Body: "forbidden"}
+		}
+		_, _ = callTool(t, s, "mark_finding_false_positive", map[string]any{"finding_id": 7, "justification": "x"})
+		if len(records) != 1 {
+			t.Fatalf("expected 1 audit record, got %d", len(records))
+		}
+		if records[0].Success || records[0].Status != 403 || records[0].Error == "" {
+			t.Errorf("expected failed record with status 403, got %+v", records[0])
+		}
+	})
+}
+
+func TestCallerIdentity(t *testing.T) {
+	if got := CallerIdentityFromContext(context.Background()); got != "" {
+		t.Errorf("expected empty identity, got %q", got)
+	}
+	ctx := WithCallerIdentity(context.Background(), "agent-1")
+	if got := CallerIdentityFromContext(ctx); got != "agent-1" {
+		t.Errorf("expected agent-1, got %q", got)
+	}
+}
+
+func TestFileAuditLogger(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	logger := NewFileAuditLogger(path)
+
+	for i := 1; i <= 2; i++ {
+		if err := logger.LogAudit(context.Background(), AuditRecord{Tool: "mark_finding_false_positive", FindingID: i}); err != nil {
+			t.Fatalf("LogAudit() error = %v", err)
+		}
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("opening audit log: %v", err)
+	}
+	defer f.Close()
+
+	var lines int
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var rec AuditRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			t.Fatalf("invalid JSON line %q: %v", scanner.Text(), err)
+		}
+		lines++
+		if rec.FindingID != lines {
+			t.Errorf("line %d: expected finding %d, got %d", lines, lines, rec.FindingID)
+		}
+	}
+	if lines != 2 {
+		t.Errorf("expected 2 lines, got %d", lines)
+	}
+}
@@ -0,0 +1,243 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+// Budget limits, as named in BudgetError
+const (
+	budgetMaxPages    = "max_pages"
+	budgetMaxFindings = "max_findings"
+)
+
+// narrowingArguments are the tool arguments that shrink the DefectDojo query
+// behind a call, most selective first. A budget error suggests those of the
+// tool the call did not pass.
+var narrowingArguments = []string{
+	"engagement_id", "test_id", "test", "product_id", "product", "product_type", "product_tags",
+	"severity", "min_severity", "minimum_severity", "tags", "max_findings",
+}
+
+// BudgetError reports a tool call stopped because it would read more from
+// DefectDojo than its ToolBudget allows. Its message is meant for the agent:
+// it names the exceeded limit and how to narrow the call, so the agent
+// retries with a filter rather than unchanged.
+type BudgetError struct {
+	Tool  string `json:"tool"`           // Stopped tool call
+	Limit string `json:"limit"`          // Exceeded limit: "max_pages" or "max_findings"
+	Max   int    `json:"max"`            // Configured value of the limit
+	Hint  string `json:"hint,omitempty"` // How to narrow the call
+}
+
+// Error implements error
+func (e *BudgetError) Error() string {
+	detail, _ := json.Marshal(e)
+	what := "pages of DefectDojo results"
+	if e.Limit == budgetMaxFindings {
+		what = "findings"
+	}
+	message := fmt.Sprintf("budget exceeded: %s would read more than %d %s", e.Tool, e.Max, what)
+	if e.Hint != "" {
+		message += ". " + e.Hint
+	}
+	return fmt.Sprintf("%s %s", message, detail)
+}
+
+// toolBudgets resolves the budget of each tool from a BudgetsConfig
+type toolBudgets struct {
+	config BudgetsConfig
+	tools  map[string]mcp.Tool // Definitions, for the narrowing hint
+}
+
+func newToolBudgets(cfg BudgetsConfig) *toolBudgets {
+	if cfg.Default == (ToolBudget{}) && len(cfg.Tools) == 0 {
+		return nil
+	}
+	tools := map[string]mcp.Tool{}
+	for _, tool := range ToolDefinitions() {
+		tools[tool.Name] = tool
+	}
+	names := slices.Sorted(maps.Keys(cfg.Tools))
+	for _, name := range names {
+		if _, ok := tools[name]; !ok {
+			log.Printf("⚠️  Budget of unknown tool %q ignored", name)
+		}
+	}
+	return &toolBudgets{config: cfg, tools: tools}
+}
+
+// budget returns the tool's budget: its own limits, and the default ones
+// for the limits it leaves unset
+func (b *toolBudgets) budget(tool string) ToolBudget {
+	budget := b.config.Tools[tool]
+	if budget.MaxPages == 0 {
+		budget.MaxPages = b.config.Default.MaxPages
+	}
+	if budget.MaxFindings == 0 {
+		budget.MaxFindings = b.config.Default.MaxFindings
+	}
+	if budget.MaxConcurrency == 0 {
+		budget.MaxConcurrency = b.config.Default.MaxConcurrency
+	}
+	return budget
+}
+
+// hint tells how to narrow a call of the tool: with the narrowing arguments
+// it accepts but the call did not pass
+func (b *toolBudgets) hint(request mcp.CallToolRequest) string {
+	tool, ok := b.tools[request.Params.Name]
+	if !ok {
+		return ""
+	}
+	passed := request.GetArguments()
+	var unused []string
+	for _, name := range narrowingArguments {
+		if _, accepted := tool.InputSchema.Properties[name]; !accepted {
+			continue
+		}
+		if _, set := passed[name]; !set {
+			unused = append(unused, name)
+		}
+	}
+	switch len(unused) {
+	case 0:
+		return "Narrow the filter arguments, or ask the operator to raise the budget."
+	case 1:
+		return fmt.Sprintf("Narrow the call with %s, or ask the operator to raise the budget.", unused[0])
+	}
+	return fmt.Sprintf("Narrow the call with %s or %s, or ask the operator to raise the budget.", strings.Join(unused[:len(unused)-1], ", "), unused[len(unused)-1])
+}
+
+// budgetMeter tallies what one tool call read from DefectDojo against its
+// budget. A nil meter allows everything, so code paths outside tool calls,
+// such as the poller, need no budget.
+type budgetMeter struct {
+	tool   string
+	budget ToolBudget
+	hint   string
+
+	mu       sync.Mutex
+	pages    int
+	findings int
+	exceeded *BudgetError // First exceeded limit; every later spend fails with it
+}
+
+type budgetMeterKey struct{}
+
+// budgetFrom returns the meter of the tool call running under ctx, if any
+func budgetFrom(ctx context.Context) *budgetMeter {
+	meter, _ := ctx.Value(budgetMeterKey{}).(*budgetMeter)
+	return meter
+}
+
+// spendPage charges a DefectDojo list request about to be made
+func (m *budgetMeter) spendPage() error {
+	if m == nil {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.exceeded == nil && m.budget.MaxPages > 0 && m.pages >= m.budget.MaxPages {
+		m.exceed(budgetMaxPages, m.budget.MaxPages)
+	}
+	if m.exceeded != nil {
+		return m.exceeded
+	}
+	m.pages++
+	return nil
+}
+
+// spendFindings charges n findings read from a list response
+func (m *budgetMeter) spendFindings(n int) error {
+	if m == nil {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.findings += n
+	if m.exceeded == nil && m.budget.MaxFindings > 0 && m.findings > m.budget.MaxFindings {
+		m.exceed(budgetMaxFindings, m.budget.MaxFindings)
+	}
+	if m.exceeded != nil {
+		return m.exceeded
+	}
+	return nil
+}
+
+// exceed records the first exceeded limit; the caller holds m.mu
+func (m *budgetMeter) exceed(limit string, max int) {
+	m.exceeded = &BudgetError{Tool: m.tool, Limit: limit, Max: max, Hint: m.hint}
+}
+
+// err returns the exceeded limit, if any
+func (m *budgetMeter) err() error {
+	if m == nil {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.exceeded == nil {
+		return nil
+	}
+	return m.exceeded
+}
+
+// maxConcurrency returns how many worker pool tasks the call may run at once (0 = the pool's size)
+func (m *budgetMeter) maxConcurrency() int {
+	if m == nil {
+		return 0
+	}
+	return m.budget.MaxConcurrency
+}
+
+// findingsPage reads one page of findings, charging it to the call's budget
+// and sanitizing their text when configured
+func (s *Server) findingsPage(ctx context.Context, filter types.FindingsFilter) (*types.FindingsResponse, error) {
+	meter := budgetFrom(ctx)
+	if err := meter.spendPage(); err != nil {
+		return nil, err
+	}
+	response, err := s.ddClient.GetFindings(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if err := meter.spendFindings(len(response.Results)); err != nil {
+		return nil, err
+	}
+	sanitized := *response
+	sanitized.Results = s.sanitizeFindings(response.Results)
+	return &sanitized, nil
+}
+
+// budgetMiddleware meters each call against its tool's budget. Once a limit
+// is exceeded every further DefectDojo page fails, and the call fails with
+// the BudgetError even when the tool would have reported the failed pages as
+// partial results: a truncated aggregate would read as a complete one.
+func budgetMiddleware(budgets *toolBudgets) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			budget := budgets.budget(request.Params.Name)
+			if budget == (ToolBudget{}) {
+				return next(ctx, request)
+			}
+			meter := &budgetMeter{tool: request.Params.Name, budget: budget, hint: budgets.hint(request)}
+			result, err := next(context.WithValue(ctx, budgetMeterKey{}, meter), request)
+			if exceeded := meter.err(); exceeded != nil {
+				return nil, exceeded
+			}
+			return result, err
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package mcpserver
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+func TestBudgetMeter(t *testing.T) {
+	var unmetered *budgetMeter
+	if unmetered.spendPage() != nil || unmetered.spendFindings(1000) != nil || unmetered.err() != nil {
+		t.Error("expected a nil meter to allow everything")
+	}
+
+	meter := &budgetMeter{tool: toolGetFindings, budget: ToolBudget{MaxPages: 2, MaxFindings: 15}, hint: "Narrow the call with product."}
+	for range 2 {
+		if err := meter.spendPage(); err != nil {
+			t.Fatalf("page within budget refused: %v", err)
+		}
+	}
+	if err := meter.spendFindings(15); err != nil {
+		t.Fatalf("findings within budget refused: %v", err)
+	}
+
+	err := meter.spendPage()
+	var budgetErr *BudgetError
+	if !errors.As(err, &budgetErr) || budgetErr.Limit != budgetMaxPages || budgetErr.Max != 2 {
+		t.Fatalf("expected the third page to exceed max_pages, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "would read more than 2 pages of DefectDojo results. Narrow the call with product.") {
+		t.Errorf("unexpected message %q", err)
+	}
+	if meter.spendFindings(0) != err || meter.err() != err {
+		t.Error("expected later spends to fail with the first exceeded limit")
+	}
+}
+
+func TestBudgetHint(t *testing.T) {
+	budgets := newToolBudgets(BudgetsConfig{Default: ToolBudget{MaxPages: 1}})
+	request := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: toolGetFindings, Arguments: map[string]any{"product": "Portal", "limit": 10}}}
+	if hint := budgets.hint(request); hint != "Narrow the call with test, severity, min_severity or tags, or ask the operator to raise the budget." {
+		t.Errorf("unexpected hint %q", hint)
+	}
+	if hint := budgets.hint(mcp.CallToolRequest{Params: mcp.CallToolParams{Name: toolHealthCheck}}); hint != "Narrow the filter arguments, or ask the operator to raise the budget." {
+		t.Errorf("unexpected hint for a tool without filters %q", hint)
+	}
+
+	budgets = newToolBudgets(BudgetsConfig{
+		Default: ToolBudget{MaxPages: 10, MaxConcurrency: 4},
+		Tools:   map[string]ToolBudget{toolSummarizePosture: {MaxPages: 100}},
+	})
+	if got := budgets.budget(toolSummarizePosture); got != (ToolBudget{MaxPages: 100, MaxConcurrency: 4}) {
+		t.Errorf("expected the tool budget over the default one, got %+v", got)
+	}
+	if newToolBudgets(BudgetsConfig{}) != nil {
+		t.Error("expected no budgets without configuration")
+	}
+}
+
+func TestBudgetMiddleware(t *testing.T) {
+	mock := &MockDefectDojoClient{
+		ListProductsFunc: func(ctx context.Context, limit, offset int) (*types.ProductsResponse, error) {
+			products := []types.Product{{ID: 1, Name: "Portal"}, {ID: 2, Name: "Payments"}, {ID: 3, Name: "Mobile"}}
+			return &types.ProductsResponse{Count: len(products), Results: products}, nil
+		},
+		GetFindingsFunc: func(ctx context.Context, filter types.FindingsFilter) (*types.FindingsResponse, error) {
+			results := make([]types.Finding, min(filter.Limit, 20))
+			for i := range results {
+				results[i] = types.Finding{ID: i + 1, Title: "Finding", Severity: "High"}
+			}
+			return &types.FindingsResponse{Count: 20, Results: results}, nil
+		},
+	}
+	s := newServer(&Config{Budgets: BudgetsConfig{
+		Tools: map[string]ToolBudget{
+			toolSummarizePosture: {MaxPages: 5},
+			toolGetFindings:      {MaxFindings: 15},
+		},
+	}}, mock)
+
+	// Product errors are partial results; the budget still fails the call
+	_, err := callTool(t, s, toolSummarizePosture, nil)
+	if err == nil || !strings.Contains(err.Error(), "budget exceeded: summarize_security_posture would read more than 5 pages") || !strings.Contains(err.Error(), "Narrow the call with product_tags") {
+		t.Errorf("expected the posture summary to exceed its budget, got %v", err)
+	}
+
+	_, err = callTool(t, s, toolGetFindings, map[string]any{"limit": 20})
+	if err == nil || !strings.Contains(err.Error(), "would read more than 15 findings") {
+		t.Errorf("expected the findings list to exceed its budget, got %v", err)
+	}
+	if _, err := callTool(t, s, toolGetFindings, map[string]any{"limit": 10}); err != nil {
+		t.Errorf("expected a call within budget to succeed, got %v", err)
+	}
+}
+
+func TestBudgetConcurrency(t *testing.T) {
+	pool := newWorkerPool(8)
+	meter := &budgetMeter{budget: ToolBudget{MaxConcurrency: 2}}
+	ctx := context.WithValue(context.Background(), budgetMeterKey{}, meter)
+
+	var mu sync.Mutex
+	running, peak := 0, 0
+	pool.run(ctx, "test", 10, func(int) {
+		mu.Lock()
+		running++
+		peak = max(peak, running)
+		mu.Unlock()
+		time.Sleep(time.Millisecond)
+		mu.Lock()
+		running--
+		mu.Unlock()
+	})
+	if peak > 2 {
+		t.Errorf("expected at most 2 tasks at once, got %d", peak)
+	}
+}
@@ -0,0 +1,288 @@
+package mcpserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/brduru/mcp-defect-dojo/internal/config"
+	"github.com/brduru/mcp-defect-dojo/internal/defectdojo"
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+// ruleCredentialScope is the PolicyError rule of calls no single credential covers
+const ruleCredentialScope = "credential_scope"
+
+// localTools never call DefectDojo, so they run without a credential
+var localTools = map[string]bool{
+	toolInvalidateCache:    true,
+	toolListSavedQueries:   true,
+	toolListPendingActions: true,
+	toolGetRecentEvents:    true,
+	toolServerStats:        true,
+	toolGetPinned:          true,
+	toolClearPins:          true,
+}
+
+// credentialScopes chooses the API token of each tool call from the products
+// the call names
+type credentialScopes struct {
+	defaultKey  string // Token of calls naming no product (empty = refuse them)
+	credentials []Credential
+	err         error // Set when the credentials are invalid; every call is then refused
+}
+
+// credentialChosenKey marks a context whose credential was already chosen
+type credentialChosenKey struct{}
+
+// newCredentialScopes returns the scopes of the configured credentials, or
+// nil when there are none. Invalid credentials refuse every call rather than
+// falling back to the default token.
+func newCredentialScopes(cfg DefectDojoConfig) *credentialScopes {
+	if len(cfg.Credentials) == 0 {
+		return nil
+	}
+	scopes := &credentialScopes{defaultKey: cfg.APIKey, credentials: cfg.Credentials}
+	credentials := make([]config.CredentialConfig, 0, len(cfg.Credentials))
+	for _, credential := range cfg.Credentials {
+		credentials = append(credentials, config.CredentialConfig(credential))
+	}
+	if err := config.ValidateCredentials(credentials); err != nil {
+		log.Printf("⚠️  Per-product credentials unusable, all DefectDojo calls will be refused: %v", err)
+		scopes.err = err
+	}
+	return scopes
+}
+
+// credentialMiddleware runs each tool call with the token of the products it
+// names. It is installed outside and inside approvals, since approved writes
+// run later under the approver's context; a call whose credential was chosen
+// already passes straight through.
+func credentialMiddleware(scopes *credentialScopes, ddClient defectdojo.Client) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if localTools[request.Params.Name] || ctx.Value(credentialChosenKey{}) != nil {
+				return next(ctx, request)
+			}
+			credential, err := scopes.choose(ctx, ddClient, request)
+			if err != nil {
+				return nil, err
+			}
+			ctx = context.WithValue(ctx, credentialChosenKey{}, credential.Name)
+			return next(defectdojo.WithAPIKey(ctx, credential.APIKey), request)
+		}
+	}
+}
+
+// choose returns the credential covering every product the call names. A
+// call spanning products of different credentials is refused, as is one
+// naming a product no credential covers, even when a default token is
+// configured. Calls naming no product use the default token, or are refused
+// without one; the health check then falls back to the first credential, so
+// connectivity can be checked without a default token.
+func (c *credentialScopes) choose(ctx context.Context, ddClient defectdojo.Client, request mcp.CallToolRequest) (Credential, error) {
+	tool := request.Params.Name
+	if c.err != nil {
+		return Credential{}, &PolicyError{Rule: ruleCredentialScope, Tool: tool, Reason: "the per-product credentials could not be loaded, so all DefectDojo calls are disabled"}
+	}
+	products, err := c.callProducts(ctx, ddClient, request)
+	if err != nil {
+		return Credential{}, err
+	}
+	if len(products) == 0 {
+		switch {
+		case c.defaultKey != "":
+			return Credential{APIKey: c.defaultKey}, nil
+		case tool == toolHealthCheck:
+			return c.credentials[0], nil
+		}
+		return Credential{}, &PolicyError{Rule: ruleCredentialScope, Tool: tool, Reason: "the call names no product, so no credential can be chosen; pass a product, engagement, test or finding"}
+	}
+	return c.chooseFor(tool, products)
+}
+
+// chooseFor returns the credential covering every product, refusing products
+// no credential covers and products of different credentials
+func (c *credentialScopes) chooseFor(tool string, products []*types.Product) (Credential, error) {
+	var chosen Credential
+	var chosenFor *types.Product
+	for _, product := range products {
+		credential, ok := c.covering(product)
+		if !ok {
+			return Credential{}, &PolicyError{Rule: ruleCredentialScope, Tool: tool, Reason: fmt.Sprintf("no credential covers product %q (ID %d)", product.Name, product.ID)}
+		}
+		if chosenFor == nil {
+			chosen, chosenFor = credential, product
+			continue
+		}
+		if credential.Name != chosen.Name {
+			return Credential{}, &PolicyError{Rule: ruleCredentialScope, Tool: tool, Reason: fmt.Sprintf("the call spans product %q of credential %q and product %q of credential %q; make one call per product",
+				chosenFor.Name, chosen.Name, product.Name, credential.Name)}
+		}
+	}
+	return chosen, nil
+}
+
+// productContext returns ctx with the token of the product a resource reads,
+// chosen as for tool calls, so resources cannot read products no credential
+// covers. Without per-product credentials ctx is returned unchanged.
+func (s *Server) productContext(ctx context.Context, uri, what string, resolve func(ctx context.Context) (*types.Product, error)) (context.Context, error) {
+	if s.scopes == nil {
+		return ctx, nil
+	}
+	if s.scopes.err != nil {
+		return nil, &PolicyError{Rule: ruleCredentialScope, Tool: uri, Reason: "the per-product credentials could not be loaded, so all DefectDojo calls are disabled"}
+	}
+	var product *types.Product
+	err := s.scopes.lookup(ctx, func(ctx context.Context) error {
+		var err error
+		product, err = resolve(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot choose a credential for %s: %w", what, err)
+	}
+	credential, err := s.scopes.chooseFor(uri, []*types.Product{product})
+	if err != nil {
+		return nil, err
+	}
+	return defectdojo.WithAPIKey(ctx, credential.APIKey), nil
+}
+
+// covering returns the credential listing the product, or else its product type
+func (c *credentialScopes) covering(product *types.Product) (Credential, bool) {
+	for _, credential := range c.credentials {
+		if slices.Contains(credential.Products, product.ID) {
+			return credential, true
+		}
+	}
+	for _, credential := range c.credentials {
+		if product.ProductType != 0 && slices.Contains(credential.ProductTypes, product.ProductType) {
+			return credential, true
+		}
+	}
+	return Credential{}, false
+}
+
+// callProducts resolves the products a call names through its finding_id,
+// finding_ids, test_id, test, engagement_id, product_id and product
+// arguments. create_product is attributed to a product of its product type;
+// imports must name an engagement_id, as a product name may not exist yet.
+func (c *credentialScopes) callProducts(ctx context.Context, ddClient defectdojo.Client, request mcp.CallToolRequest) ([]*types.Product, error) {
+	tool := request.Params.Name
+	var products []*types.Product
+	add := func(what string, resolve func(ctx context.Context) (*types.Product, error)) error {
+		var product *types.Product
+		err := c.lookup(ctx, func(ctx context.Context) error {
+			var err error
+			product, err = resolve(ctx)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("cannot choose a credential for %s: %w", what, err)
+		}
+		for _, known := range products {
+			if known.ID == product.ID {
+				return nil
+			}
+		}
+		products = append(products, product)
+		return nil
+	}
+
+	for _, id := range policyFindingIDs(request) {
+		if err := add(fmt.Sprintf("finding %d", id), func(ctx context.Context) (*types.Product, error) {
+			finding, err := ddClient.GetFindingDetail(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			return findingProduct(ctx, ddClient, finding)
+		}); err != nil {
+			return nil, err
+		}
+	}
+	for _, argument := range []string{"test_id", "test"} {
+		if id := request.GetInt(argument, 0); id != 0 {
+			if err := add(fmt.Sprintf("test %d", id), func(ctx context.Context) (*types.Product, error) {
+				return testProduct(ctx, ddClient, id)
+			}); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if id := request.GetInt("engagement_id", 0); id != 0 {
+		if err := add(fmt.Sprintf("engagement %d", id), func(ctx context.Context) (*types.Product, error) {
+			engagement, err := ddClient.GetEngagement(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			return ddClient.GetProduct(ctx, engagement.Product)
+		}); err != nil {
+			return nil, err
+		}
+	} else if tool == toolImportSARIF {
+		return nil, &PolicyError{Rule: ruleCredentialScope, Tool: tool, Reason: "imports must target an engagement_id so the credential of its product can be chosen"}
+	}
+	for _, argument := range []string{"product_id", "product"} {
+		if id := request.GetInt(argument, 0); id != 0 {
+			if err := add(fmt.Sprintf("product %d", id), func(ctx context.Context) (*types.Product, error) {
+				return ddClient.GetProduct(ctx, id)
+			}); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if value := request.GetString("product_type", ""); tool == toolCreateProduct && value != "" {
+		var productType *types.ProductType
+		err := c.lookup(ctx, func(ctx context.Context) error {
+			var err error
+			productType, err = resolveProductType(ctx, ddClient, value)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("cannot choose a credential for the new product: %w", err)
+		}
+		products = append(products, &types.Product{Name: request.GetString("name", ""), ProductType: productType.ID})
+	}
+	return products, nil
+}
+
+// lookup runs fn with the default token, then with each credential's token,
+// until one may read what fn looks up: DefectDojo answers 404 or 403 to a
+// token limited to other products.
+func (c *credentialScopes) lookup(ctx context.Context, fn func(ctx context.Context) error) error {
+	var keys []string
+	if c.defaultKey != "" {
+		keys = append(keys, c.defaultKey)
+	}
+	for _, credential := range c.credentials {
+		keys = append(keys, credential.APIKey)
+	}
+	var err error
+	for _, key := range keys {
+		if err = fn(defectdojo.WithAPIKey(ctx, key)); err == nil || !isAccessDenied(err) {
+			return err
+		}
+	}
+	return err
+}
+
+// isAccessDenied reports whether DefectDojo refused or hid an object from the token
+func isAccessDenied(err error) bool {
+	var apiErr *defectdojo.APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	switch apiErr.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,259 @@
+package mcpserver
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/brduru/mcp-defect-dojo/internal/defectdojo"
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+// scopedDojo mocks an instance where finding, test and engagement N belong to
+// product N. The payments token may also read the legacy product, which no
+// credential covers, so the server must refuse it by itself.
+func scopedDojo() (*MockDefectDojoClient, func() []string) {
+	products := map[int]types.Product{
+		1: {ID: 1, Name: "Payments API", ProductType: 10},
+		2: {ID: 2, Name: "Checkout", ProductType: 10},
+		3: {ID: 3, Name: "Login", ProductType: 20},
+		4: {ID: 4, Name: "Legacy", ProductType: 30},
+	}
+	visible := map[string][]int{"payments-key": {1, 2, 4}, "identity-key": {3}, "admin-key": {1, 2, 3, 4}}
+	notFound := &defectdojo.APIError{StatusCode: http.StatusNotFound, Body: `{"detail":"Not found."}`}
+	sees := func(ctx context.Context, productID int) bool {
+		key, _ := defectdojo.APIKeyFromContext(ctx)
+		return slices.Contains(visible[key], productID)
+	}
+
+	var mu sync.Mutex
+	var used []string
+	record := func(ctx context.Context) {
+		key, _ := defectdojo.APIKeyFromContext(ctx)
+		mu.Lock()
+		defer mu.Unlock()
+		used = append(used, key)
+	}
+	mock := &MockDefectDojoClient{
+		GetFindingDetailFunc: func(ctx context.Context, findingID int) (*types.Finding, error) {
+			if !sees(ctx, findingID) {
+				return nil, notFound
+			}
+			return &types.Finding{ID: findingID, Title: "Finding", Severity: "High", Test: findingID}, nil
+		},
+		GetTestFunc: func(ctx context.Context, testID int) (*types.Test, error) {
+			if !sees(ctx, testID) {
+				return nil, notFound
+			}
+			return &types.Test{ID: testID, Engagement: testID}, nil
+		},
+		GetEngagementFunc: func(ctx context.Context, engagementID int) (*types.Engagement, error) {
+			if !sees(ctx, engagementID) {
+				return nil, notFound
+			}
+			return &types.Engagement{ID: engagementID, Product: engagementID}, nil
+		},
+		GetProductFunc: func(ctx context.Context, productID int) (*types.Product, error) {
+			product, ok := products[productID]
+			if !ok || !sees(ctx, productID) {
+				return nil, notFound
+			}
+			return &product, nil
+		},
+		GetFindingsFunc: func(ctx context.Context, filter types.FindingsFilter) (*types.FindingsResponse, error) {
+			record(ctx)
+			return &types.FindingsResponse{Results: []types.Finding{}}, nil
+		},
+		AddFindingNoteFunc: func(ctx context.Context, findingID int, entry string) (*types.Note, error) {
+			record(ctx)
+			return &types.Note{ID: findingID, Entry: entry}, nil
+		},
+		CheckHealthFunc: func(ctx context.Context) *types.HealthStatus {
+			record(ctx)
+			return &types.HealthStatus{Reachable: true, Authenticated: true}
+		},
+	}
+	return mock, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		defer func() { used = nil }()
+		return used
+	}
+}
+
+// teamCredentials covers the payments product type and the login product
+var teamCredentials = []Credential{
+	{Name: "payments", APIKey: "payments-key", ProductTypes: []int{10}},
+	{Name: "identity", APIKey: "identity-key", Products: []int{3}},
+}
+
+func TestCredentialScoping(t *testing.T) {
+	mock, used := scopedDojo()
+	s := newServer(&Config{DefectDojo: DefectDojoConfig{Credentials: teamCredentials}}, mock)
+
+	tests := []struct {
+		name    string
+		tool    string
+		args    map[string]any
+		wantKey string
+		wantErr string
+	}{
+		{"product filter", toolGetFindings, map[string]any{"product": 3}, "identity-key", ""},
+		{"test filter", toolGetFindings, map[string]any{"test": 2}, "payments-key", ""},
+		{"findings of one team", toolAddNote, map[string]any{"finding_ids": []int{1, 2}, "note": "tracked"}, "payments-key", ""},
+		{"health check", toolHealthCheck, map[string]any{}, "payments-key", ""},
+		{"cross-product", toolAddNote, map[string]any{"finding_ids": []int{1, 3}, "note": "tracked"}, "", `spans product "Payments API" of credential "payments" and product "Login" of credential "identity"`},
+		{"uncovered product", toolGetFindings, map[string]any{"product": 4}, "", `no credential covers product "Legacy" (ID 4)`},
+		{"no product", toolGetFindings, map[string]any{}, "", "the call names no product"},
+		{"unknown finding", toolAddNote, map[string]any{"finding_ids": []int{7}, "note": "tracked"}, "", "cannot choose a credential for finding 7"},
+		{"import by product name", toolImportSARIF, map[string]any{"product_name": "Login", "engagement_name": "CI", "sarif": "{}"}, "", "imports must target an engagement_id"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := callTool(t, s, tt.tool, tt.args)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				if err != nil && !strings.Contains(err.Error(), "cannot choose") && !strings.Contains(err.Error(), ruleCredentialScope) {
+					t.Errorf("expected a %s policy violation, got %v", ruleCredentialScope, err)
+				}
+				if keys := used(); len(keys) != 0 {
+					t.Errorf("expected DefectDojo not to be called, got calls with %q", keys)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, key := range used() {
+				if key != tt.wantKey {
+					t.Errorf("expected every call to use %q, got %q", tt.wantKey, key)
+				}
+			}
+		})
+	}
+
+	if _, err := callTool(t, s, toolServerStats, map[string]any{}); err != nil {
+		t.Errorf("expected local tools to run without a credential, got %v", err)
+	}
+}
+
+func TestCredentialScopingDefaultKey(t *testing.T) {
+	mock, used := scopedDojo()
+	s := newServer(&Config{DefectDojo: DefectDojoConfig{APIKey: "admin-key", Credentials: teamCredentials}}, mock)
+
+	for _, tt := range []struct {
+		args    map[string]any
+		wantKey string
+	}{
+		{map[string]any{}, "admin-key"},
+		{map[string]any{"product": 1}, "payments-key"},
+	} {
+		if _, err := callTool(t, s, toolGetFindings, tt.args); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if keys := used(); !slices.Equal(keys, []string{tt.wantKey}) {
+			t.Errorf("%v: expected a call with %q, got %q", tt.args, tt.wantKey, keys)
+		}
+	}
+	if _, err := callTool(t, s, toolGetFindings, map[string]any{"product": 4}); err == nil || !strings.Contains(err.Error(), `no credential covers product "Legacy"`) {
+		t.Errorf("expected the default api_key not to serve an uncovered product, got %v", err)
+	}
+	if keys := used(); len(keys) != 0 {
+		t.Errorf("expected no findings request for an uncovered product, got %q", keys)
+	}
+}
+
+func TestCredentialScopingApproval(t *testing.T) {
+	mock, used := scopedDojo()
+	s := newServer(&Config{DefectDojo: DefectDojoConfig{Credentials: teamCredentials}, Approval: ApprovalConfig{Required: true}}, mock)
+
+	result, err := callTool(t, s, toolAddNote, map[string]any{"finding_ids": []int{3}, "note": "tracked"})
+	if err != nil || !strings.Contains(resultText(result), "Queued for human approval") {
+		t.Fatalf("expected the note to be queued, got %v %v", result, err)
+	}
+	if _, err := s.ApproveAction(context.Background(), 1); err != nil {
+		t.Fatalf("ApproveAction() error = %v", err)
+	}
+	if keys := used(); !slices.Equal(keys, []string{"identity-key"}) {
+		t.Errorf("expected the approved note to use identity-key, got %q", keys)
+	}
+}
+
+func TestCredentialScopingResources(t *testing.T) {
+	mock, used := scopedDojo()
+	s := newServer(&Config{DefectDojo: DefectDojoConfig{APIKey: "admin-key", Credentials: teamCredentials}}, mock)
+
+	for uri, wantKey := range map[string]string{
+		"defectdojo://engagement/3/report":      "identity-key",
+		"defectdojo://product/1/attack-surface": "payments-key",
+	} {
+		if _, err := readResource(t, s, uri); err != nil {
+			t.Fatalf("%s: unexpected error: %v", uri, err)
+		}
+		if keys := used(); len(keys) == 0 || slices.ContainsFunc(keys, func(key string) bool { return key != wantKey }) {
+			t.Errorf("%s: expected every findings request to use %q, got %q", uri, wantKey, keys)
+		}
+	}
+	for _, uri := range []string{"defectdojo://engagement/4/report", "defectdojo://product/4/attack-surface"} {
+		if _, err := readResource(t, s, uri); err == nil || !strings.Contains(err.Error(), `no credential covers product "Legacy"`) {
+			t.Errorf("%s: expected the default api_key not to serve an uncovered product, got %v", uri, err)
+		}
+	}
+	if keys := used(); len(keys) != 0 {
+		t.Errorf("expected no findings request for an uncovered product, got %q", keys)
+	}
+}
+
+func TestCredentialScopingPoller(t *testing.T) {
+	mock, used := scopedDojo()
+	queries := QueriesConfig{Saved: map[string]SavedQuery{
+		"login":  {Arguments: map[string]any{"product": 3}},
+		"legacy": {Arguments: map[string]any{"product": 4}},
+		"all":    {Arguments: map[string]any{"severity": "High"}},
+	}}
+	for _, tt := range []struct {
+		query   string
+		apiKey  string
+		wantKey string
+		wantErr string
+	}{
+		{"login", "admin-key", "identity-key", ""},
+		{"all", "admin-key", "admin-key", ""},
+		{"legacy", "admin-key", "", `no credential covers product "Legacy"`},
+		{"all", "", "", "the call names no product"},
+	} {
+		s := newServer(&Config{DefectDojo: DefectDojoConfig{APIKey: tt.apiKey, Credentials: teamCredentials}, Queries: queries, Polling: PollingConfig{Query: tt.query}}, mock)
+		err := s.poller.poll(context.Background())
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s: expected error containing %q, got %v", tt.query, tt.wantErr, err)
+			}
+		} else if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.query, err)
+		}
+		var want []string
+		if tt.wantKey != "" {
+			want = []string{tt.wantKey}
+		}
+		if keys := used(); !slices.Equal(keys, want) {
+			t.Errorf("%s with api_key %q: expected findings requests with %q, got %q", tt.query, tt.apiKey, tt.wantKey, keys)
+		}
+	}
+}
+
+func TestInvalidCredentials(t *testing.T) {
+	mock, used := scopedDojo()
+	s := newServer(&Config{DefectDojo: DefectDojoConfig{APIKey: "admin-key", Credentials: []Credential{{Name: "payments", Products: []int{1}}}}}, mock)
+
+	if _, err := callTool(t, s, toolGetFindings, map[string]any{}); err == nil || !strings.Contains(err.Error(), "all DefectDojo calls are disabled") {
+		t.Errorf("expected invalid credentials to refuse every call, got %v", err)
+	}
+	if keys := used(); len(keys) != 0 {
+		t.Errorf("expected DefectDojo not to be called, got calls with %q", keys)
+	}
+}
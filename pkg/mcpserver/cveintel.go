@@ -0,0 +1,441 @@
+package mcpserver
+
+import (
+	"cmp"
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/brduru/mcp-defect-dojo/internal/refcache"
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+// CVE enrichment modes
+const (
+	EnrichmentOff     = "off"     // No enrichment (default)
+	EnrichmentLive    = "live"    // Query the FIRST EPSS API and the CISA KEV feed
+	EnrichmentOffline = "offline" // Read downloaded EPSS and KEV files, see LoadCVEDataset
+)
+
+// Public exploitation data sources
+const (
+	defaultEPSSURL = "https://api.first.org/data/v1/epss"
+	defaultKEVURL  = "https://www.cisa.gov/sites/default/files/feeds/known_exploited_vulnerabilities.json"
+)
+
+// Offline dataset file names, as published by FIRST and CISA
+const (
+	kevFileName  = "known_exploited_vulnerabilities.json"
+	epssFileName = "epss_scores.csv"
+)
+
+// Live lookup sizing
+const (
+	defaultEnrichmentCacheTTL = 24 * time.Hour   // EPSS scores and the KEV catalog are published daily
+	enrichmentRequestTimeout  = 15 * time.Second // Per request to FIRST or CISA
+	epssBatchSize             = 100              // CVEs per EPSS API request
+)
+
+// CVEIntel is the exploitation data known for one CVE. Fields are nil when
+// the source has no data, e.g. a CVE newer than the last EPSS run.
+type CVEIntel struct {
+	CVE        string    `json:"cve"`
+	EPSS       *float64  `json:"epss,omitempty"`       // Probability of exploitation in the next 30 days (0-1)
+	Percentile *float64  `json:"percentile,omitempty"` // EPSS percentile among all scored CVEs (0-1)
+	KEV        *KEVEntry `json:"kev,omitempty"`        // Set when the CVE is in the CISA KEV catalog
+}
+
+// KEVEntry is a CVE's listing in the CISA Known Exploited Vulnerabilities catalog
+type KEVEntry struct {
+	DateAdded       string `json:"date_added"`
+	DueDate         string `json:"due_date,omitempty"`
+	RequiredAction  string `json:"required_action,omitempty"`
+	KnownRansomware bool   `json:"known_ransomware,omitempty"`
+}
+
+// epssScore is one CVE's EPSS result. Unscored CVEs are cached too, so they
+// are not requested again within the cache TTL.
+type epssScore struct {
+	scored     bool
+	score      float64
+	percentile float64
+}
+
+// cveIntelSource looks up exploitation data for CVE IDs. Lookups may return
+// partial data together with an error.
+type cveIntelSource interface {
+	lookup(ctx context.Context, cves []string) (map[string]CVEIntel, error)
+}
+
+// CVEDataset is an offline copy of the EPSS scores and the KEV catalog,
+// for deployments without internet access. Create it with LoadCVEDataset.
+type CVEDataset struct {
+	epss map[string]epssScore
+	kev  map[string]KEVEntry
+}
+
+// LoadCVEDataset reads the exploitation data files in dir:
+//   - known_exploited_vulnerabilities.json, the CISA KEV catalog feed
+//   - epss_scores.csv or epss_scores.csv.gz, the FIRST daily EPSS export
+//
+// At least one of the files must exist; the other source is then reported as unknown.
+func LoadCVEDataset(dir string) (*CVEDataset, error) {
+	dataset := &CVEDataset{}
+	found := false
+
+	if file, err := os.Open(filepath.Join(dir, kevFileName)); err == nil {
+		dataset.kev, err = parseKEVCatalog(file)
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", kevFileName, err)
+		}
+		found = true
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("reading CVE dataset: %w", err)
+	}
+
+	for _, name := range []string{epssFileName, epssFileName + ".gz"} {
+		file, err := os.Open(filepath.Join(dir, name))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading CVE dataset: %w", err)
+		}
+		var reader io.Reader = file
+		if strings.HasSuffix(name, ".gz") {
+			gz, err := gzip.NewReader(file)
+			if err != nil {
+				file.Close()
+				return nil, fmt.Errorf("parsing %s: %w", name, err)
+			}
+			reader = gz
+		}
+		dataset.epss, err = parseEPSSCSV(reader)
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", name, err)
+		}
+		found = true
+		break
+	}
+
+	if !found {
+		return nil, fmt.Errorf("no CVE data in %s: expected %s and/or %s", dir, kevFileName, epssFileName)
+	}
+	return dataset, nil
+}
+
+// lookup answers from the loaded files
+func (d *CVEDataset) lookup(ctx context.Context, cves []string) (map[string]CVEIntel, error) {
+	results := map[string]CVEIntel{}
+	for _, cve := range cves {
+		intel := CVEIntel{CVE: cve}
+		if score, ok := d.epss[cve]; ok && score.scored {
+			intel.EPSS, intel.Percentile = &score.score, &score.percentile
+		}
+		if entry, ok := d.kev[cve]; ok {
+			intel.KEV = &entry
+		}
+		if intel.EPSS != nil || intel.KEV != nil {
+			results[cve] = intel
+		}
+	}
+	return results, nil
+}
+
+// parseKEVCatalog decodes the CISA KEV JSON feed into entries by CVE ID
+func parseKEVCatalog(r io.Reader) (map[string]KEVEntry, error) {
+	var catalog struct {
+		Vulnerabilities []struct {
+			CVEID                      string `json:"cveID"`
+			DateAdded                  string `json:"dateAdded"`
+			DueDate                    string `json:"dueDate"`
+			RequiredAction             string `json:"requiredAction"`
+			KnownRansomwareCampaignUse string `json:"knownRansomwareCampaignUse"`
+		} `json:"vulnerabilities"`
+	}
+	if err := json.NewDecoder(r).Decode(&catalog); err != nil {
+		return nil, err
+	}
+	if catalog.Vulnerabilities == nil {
+		return nil, fmt.Errorf("no vulnerabilities array")
+	}
+	entries := make(map[string]KEVEntry, len(catalog.Vulnerabilities))
+	for _, v := range catalog.Vulnerabilities {
+		entries[strings.ToUpper(v.CVEID)] = KEVEntry{
+			DateAdded:       v.DateAdded,
+			DueDate:         v.DueDate,
+			RequiredAction:  v.RequiredAction,
+			KnownRansomware: strings.EqualFold(v.KnownRansomwareCampaignUse, "Known"),
+		}
+	}
+	return entries, nil
+}
+
+// parseEPSSCSV decodes a FIRST EPSS export: an optional "#model_version"
+// comment line, a cve,epss,percentile header, then one row per CVE
+func parseEPSSCSV(r io.Reader) (map[string]epssScore, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading header: %w", err)
+	}
+	if len(header) < 3 || header[0] != "cve" || header[1] != "epss" || header[2] != "percentile" {
+		return nil, fmt.Errorf("expected a cve,epss,percentile header, got %q", strings.Join(header, ","))
+	}
+
+	scores := map[string]epssScore{}
+	for row := 1; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return scores, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(record) < 3 {
+			return nil, fmt.Errorf("row %d: expected 3 columns", row)
+		}
+		score, err := parseEPSSValues(record[1], record[2])
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", row, err)
+		}
+		scores[strings.ToUpper(record[0])] = score
+	}
+}
+
+// parseEPSSValues parses an EPSS score and percentile, both between 0 and 1
+func parseEPSSValues(epss, percentile string) (epssScore, error) {
+	score := epssScore{scored: true}
+	var err error
+	if score.score, err = strconv.ParseFloat(epss, 64); err != nil || score.score < 0 || score.score > 1 {
+		return epssScore{}, fmt.Errorf("invalid EPSS score %q", epss)
+	}
+	if score.percentile, err = strconv.ParseFloat(percentile, 64); err != nil || score.percentile < 0 || score.percentile > 1 {
+		return epssScore{}, fmt.Errorf("invalid EPSS percentile %q", percentile)
+	}
+	return score, nil
+}
+
+// liveCVEIntel queries FIRST and CISA, caching EPSS scores per CVE and the
+// KEV catalog as a whole
+type liveCVEIntel struct {
+	client  *http.Client
+	epssURL string
+	kevURL  string
+	cache   *refcache.Cache
+}
+
+// lookup fetches missing EPSS scores in batches and the KEV catalog when it
+// is not cached. A failing source leaves its fields unset and is reported in
+// the error, while the other source's data is still returned.
+func (l *liveCVEIntel) lookup(ctx context.Context, cves []string) (map[string]CVEIntel, error) {
+	var errs []error
+	scores := map[string]epssScore{}
+	var missing []string
+	for _, cve := range cves {
+		if score, ok := refcache.Peek[epssScore](l.cache, "epss:"+cve); ok {
+			scores[cve] = score
+		} else {
+			missing = append(missing, cve)
+		}
+	}
+	for batch := range slices.Chunk(missing, epssBatchSize) {
+		fetched, err := l.fetchEPSS(ctx, batch)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("EPSS: %w", err))
+			break
+		}
+		for _, cve := range batch {
+			scores[cve] = fetched[cve] // Unscored CVEs are cached as such
+			l.cache.Put("epss:"+cve, fetched[cve])
+		}
+	}
+
+	kev, err := refcache.Get(l.cache, "kev", func() (map[string]KEVEntry, error) {
+		return l.fetchKEV(ctx)
+	})
+	if err != nil {
+		errs = append(errs, fmt.Errorf("CISA KEV: %w", err))
+	}
+
+	dataset := &CVEDataset{epss: scores, kev: kev}
+	results, _ := dataset.lookup(ctx, cves)
+	return results, errors.Join(errs...)
+}
+
+// fetchEPSS requests the scores of up to epssBatchSize CVEs
+func (l *liveCVEIntel) fetchEPSS(ctx context.Context, cves []string) (map[string]epssScore, error) {
+	var response struct {
+		Data []struct {
+			CVE        string `json:"cve"`
+			EPSS       string `json:"epss"`
+			Percentile string `json:"percentile"`
+		} `json:"data"`
+	}
+	endpoint := l.epssURL + "?" + url.Values{"cve": {strings.Join(cves, ",")}}.Encode()
+	if err := l.getJSON(ctx, endpoint, &response); err != nil {
+		return nil, err
+	}
+	scores := map[string]epssScore{}
+	for _, row := range response.Data {
+		score, err := parseEPSSValues(row.EPSS, row.Percentile)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", row.CVE, err)
+		}
+		scores[strings.ToUpper(row.CVE)] = score
+	}
+	return scores, nil
+}
+
+// fetchKEV downloads the KEV catalog
+func (l *liveCVEIntel) fetchKEV(ctx context.Context) (map[string]KEVEntry, error) {
+	var catalog map[string]KEVEntry
+	err := l.get(ctx, l.kevURL, func(body io.Reader) error {
+		var err error
+		catalog, err = parseKEVCatalog(body)
+		return err
+	})
+	return catalog, err
+}
+
+// getJSON decodes the JSON response of a GET request into out
+func (l *liveCVEIntel) getJSON(ctx context.Context, endpoint string, out any) error {
+	return l.get(ctx, endpoint, func(body io.Reader) error {
+		return json.NewDecoder(body).Decode(out)
+	})
+}
+
+// get performs a GET request and hands a successful response body to decode
+func (l *liveCVEIntel) get(ctx context.Context, endpoint string, decode func(io.Reader) error) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned HTTP %d", req.URL.Host, resp.StatusCode)
+	}
+	if err := decode(resp.Body); err != nil {
+		return fmt.Errorf("decoding response from %s: %w", req.URL.Host, err)
+	}
+	return nil
+}
+
+// newCVEIntelSource builds the enrichment source for the configured mode,
+// or nil when enrichment is off
+func newCVEIntelSource(cfg EnrichmentConfig) (cveIntelSource, error) {
+	switch cfg.Mode {
+	case "", EnrichmentOff:
+		return nil, nil
+	case EnrichmentOffline:
+		if cfg.Dataset != nil {
+			return cfg.Dataset, nil
+		}
+		if cfg.DataDir == "" {
+			return nil, fmt.Errorf("offline enrichment needs a data directory")
+		}
+		dataset, err := LoadCVEDataset(cfg.DataDir)
+		if err != nil {
+			return nil, err // Not a nil *CVEDataset, which would be a non-nil source
+		}
+		return dataset, nil
+	case EnrichmentLive:
+		ttl := cfg.CacheTTL
+		if ttl == 0 {
+			ttl = defaultEnrichmentCacheTTL
+		}
+		source := &liveCVEIntel{
+			client:  &http.Client{Timeout: enrichmentRequestTimeout},
+			epssURL: cmp.Or(cfg.EPSSURL, defaultEPSSURL),
+			kevURL:  cmp.Or(cfg.KEVURL, defaultKEVURL),
+			cache:   refcache.New(ttl),
+		}
+		return source, nil
+	default:
+		return nil, fmt.Errorf("unknown enrichment mode %q (must be off, live or offline)", cfg.Mode)
+	}
+}
+
+// findingCVEs returns the distinct CVE IDs among the findings' vulnerability IDs, upper-cased
+func findingCVEs(findings ...types.Finding) []string {
+	var cves []string
+	for _, finding := range findings {
+		for _, id := range finding.VulnerabilityIDList() {
+			id = strings.ToUpper(strings.TrimSpace(id))
+			if strings.HasPrefix(id, "CVE-") && !slices.Contains(cves, id) {
+				cves = append(cves, id)
+			}
+		}
+	}
+	return cves
+}
+
+// lookupCVEIntel returns the exploitation data for the findings' CVEs, or
+// nil when enrichment is off or none of them has CVEs
+func (s *Server) lookupCVEIntel(ctx context.Context, findings ...types.Finding) (map[string]CVEIntel, error) {
+	if s.intel == nil {
+		return nil, nil
+	}
+	cves := findingCVEs(findings...)
+	if len(cves) == 0 {
+		return nil, nil
+	}
+	return s.intel.lookup(ctx, cves)
+}
+
+// findingIntel returns the exploitation data of one finding's CVEs, in vulnerability ID order
+func findingIntel(finding *types.Finding, intel map[string]CVEIntel) []CVEIntel {
+	var result []CVEIntel
+	for _, cve := range findingCVEs(*finding) {
+		if data, ok := intel[cve]; ok {
+			result = append(result, data)
+		}
+	}
+	return result
+}
+
+// formatExploitation renders one "Exploitation (CVE-…): …" line per enriched
+// CVE of the finding, or "" if there is no data
+func formatExploitation(finding *types.Finding, intel map[string]CVEIntel) string {
+	var result string
+	for _, data := range findingIntel(finding, intel) {
+		var parts []string
+		if data.EPSS != nil {
+			parts = append(parts, fmt.Sprintf("EPSS %.1f%%, percentile %.1f%%", *data.EPSS*100, *data.Percentile*100))
+		}
+		if data.KEV != nil {
+			kev := "CISA KEV since " + data.KEV.DateAdded
+			if data.KEV.DueDate != "" {
+				kev += ", due " + data.KEV.DueDate
+			}
+			if data.KEV.KnownRansomware {
+				kev += ", used in ransomware campaigns"
+			}
+			parts = append(parts, kev)
+		}
+		result += fmt.Sprintf("Exploitation (%s): %s\n", data.CVE, strings.Join(parts, ", "))
+	}
+	return result
+}
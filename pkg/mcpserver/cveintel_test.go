@@ -0,0 +1,218 @@
+package mcpserver
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/brduru/mcp-defect-dojo/internal/defectdojo"
+)
+
+const testKEVCatalog = `{
+	"title": "CISA Catalog of Known Exploited Vulnerabilities",
+	"vulnerabilities": [
+		{"cveID": "CVE-2021-44228", "dateAdded": "2021-12-10", "dueDate": "2021-12-24", "requiredAction": "Apply updates", "knownRansomwareCampaignUse": "Known"},
+		{"cveID": "CVE-2020-8203", "dateAdded": "2023-01-05", "dueDate": "2023-01-26", "requiredAction": "Apply updates", "knownRansomwareCampaignUse": "Unknown"}
+	]
+}`
+
+const testEPSSCSV = `#model_version:v2025.03.14,score_date:2026-10-15T12:55:00Z
+cve,epss,percentile
+CVE-2021-44228,0.94358,0.99961
+CVE-2020-8203,0.01234,0.78500
+`
+
+// writeCVEDataset writes the given offline enrichment files to a temp directory
+func writeCVEDataset(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if strings.HasSuffix(name, ".gz") {
+			file, err := os.Create(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			gz := gzip.NewWriter(file)
+			gz.Write([]byte(content))
+			gz.Close()
+			file.Close()
+			continue
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoadCVEDataset(t *testing.T) {
+	dataset, err := LoadCVEDataset(writeCVEDataset(t, map[string]string{
+		kevFileName:          testKEVCatalog,
+		epssFileName + ".gz": testEPSSCSV,
+	}))
+	if err != nil {
+		t.Fatalf("LoadCVEDataset() error = %v", err)
+	}
+	intel, err := dataset.lookup(context.Background(), []string{"CVE-2021-44228", "CVE-2020-8203", "CVE-2099-0001"})
+	if err != nil {
+		t.Fatalf("lookup() error = %v", err)
+	}
+	log4shell := intel["CVE-2021-44228"]
+	if log4shell.EPSS == nil || *log4shell.EPSS != 0.94358 || *log4shell.Percentile != 0.99961 {
+		t.Errorf("unexpected EPSS data: %+v", log4shell)
+	}
+	if log4shell.KEV == nil || log4shell.KEV.DateAdded != "2021-12-10" || !log4shell.KEV.KnownRansomware {
+		t.Errorf("unexpected KEV data: %+v", log4shell.KEV)
+	}
+	if kev := intel["CVE-2020-8203"].KEV; kev == nil || kev.KnownRansomware {
+		t.Errorf("unexpected KEV data: %+v", kev)
+	}
+	if _, ok := intel["CVE-2099-0001"]; ok {
+		t.Error("expected unknown CVEs to be left out")
+	}
+
+	// Either file alone is enough
+	if _, err := LoadCVEDataset(writeCVEDataset(t, map[string]string{epssFileName: testEPSSCSV})); err != nil {
+		t.Errorf("expected an EPSS-only dataset to load, got %v", err)
+	}
+
+	for name, files := range map[string]map[string]string{
+		"empty directory": {},
+		"bad KEV":         {kevFileName: `{"title": "no vulnerabilities"}`},
+		"bad EPSS header": {epssFileName: "id,score\nCVE-2021-44228,0.9\n"},
+		"bad EPSS score":  {epssFileName: "cve,epss,percentile\nCVE-2021-44228,high,0.9\n"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := LoadCVEDataset(writeCVEDataset(t, files)); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestLiveCVEIntel(t *testing.T) {
+	var epssRequests, kevRequests atomic.Int32
+	var kevDown atomic.Bool
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/epss":
+			epssRequests.Add(1)
+			var data []map[string]string
+			for _, cve := range strings.Split(r.URL.Query().Get("cve"), ",") {
+				if cve == "CVE-2021-44228" {
+					data = append(data, map[string]string{"cve": cve, "epss": "0.943580000", "percentile": "0.999610000"})
+				}
+			}
+			json.NewEncoder(w).Encode(map[string]any{"status": "OK", "data": data})
+		case "/kev.json":
+			kevRequests.Add(1)
+			if kevDown.Load() {
+				http.Error(w, "maintenance", http.StatusServiceUnavailable)
+				return
+			}
+			w.Write([]byte(testKEVCatalog))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer api.Close()
+
+	source, err := newCVEIntelSource(EnrichmentConfig{Mode: EnrichmentLive, EPSSURL: api.URL + "/epss", KEVURL: api.URL + "/kev.json"})
+	if err != nil {
+		t.Fatalf("newCVEIntelSource() error = %v", err)
+	}
+	ctx := context.Background()
+	cves := []string{"CVE-2021-44228", "CVE-2099-0001"}
+
+	intel, err := source.lookup(ctx, cves)
+	if err != nil {
+		t.Fatalf("lookup() error = %v", err)
+	}
+	if data := intel["CVE-2021-44228"]; data.EPSS == nil || *data.EPSS != 0.94358 || data.KEV == nil {
+		t.Errorf("unexpected data: %+v", data)
+	}
+	if _, ok := intel["CVE-2099-0001"]; ok {
+		t.Error("expected the unscored CVE to be left out")
+	}
+
+	// Scored and unscored CVEs and the catalog are all cached
+	if _, err := source.lookup(ctx, cves); err != nil {
+		t.Fatal(err)
+	}
+	if epssRequests.Load() != 1 || kevRequests.Load() != 1 {
+		t.Errorf("expected one request per source, got %d EPSS and %d KEV", epssRequests.Load(), kevRequests.Load())
+	}
+
+	// A failing source is reported while the other still answers
+	source, _ = newCVEIntelSource(EnrichmentConfig{Mode: EnrichmentLive, CacheTTL: -1, EPSSURL: api.URL + "/epss", KEVURL: api.URL + "/kev.json"})
+	kevDown.Store(true)
+	intel, err = source.lookup(ctx, cves)
+	if err == nil || !strings.Contains(err.Error(), "CISA KEV") {
+		t.Errorf("expected the KEV failure to be reported, got %v", err)
+	}
+	if data := intel["CVE-2021-44228"]; data.EPSS == nil || data.KEV != nil {
+		t.Errorf("expected EPSS data only, got %+v", data)
+	}
+}
+
+func TestNewCVEIntelSource(t *testing.T) {
+	if source, err := newCVEIntelSource(EnrichmentConfig{}); source != nil || err != nil {
+		t.Errorf("expected enrichment off by default, got %v, %v", source, err)
+	}
+	if source, err := newCVEIntelSource(EnrichmentConfig{Mode: EnrichmentOffline, DataDir: t.TempDir()}); source != nil || err == nil {
+		t.Errorf("expected an empty data directory to be rejected, got %v, %v", source, err)
+	}
+	if _, err := newCVEIntelSource(EnrichmentConfig{Mode: "sometimes"}); err == nil {
+		t.Error("expected an unknown mode to be rejected")
+	}
+}
+
+func TestFindingDetailEnrichment(t *testing.T) {
+	fixtures, err := defectdojo.NewFixtureClient("")
+	if err != nil {
+		t.Fatalf("NewFixtureClient() error = %v", err)
+	}
+	dataset, err := LoadCVEDataset(writeCVEDataset(t, map[string]string{kevFileName: testKEVCatalog, epssFileName: testEPSSCSV}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := newServer(&Config{Enrichment: EnrichmentConfig{Mode: EnrichmentOffline, Dataset: dataset}}, fixtures)
+
+	result, err := callTool(t, s, "get_finding_detail", map[string]any{"finding_id": 4})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "Exploitation (CVE-2020-8203): EPSS 1.2%, percentile 78.5%, CISA KEV since 2023-01-05, due 2023-01-26"
+	if text := resultText(result); !strings.Contains(text, want) {
+		t.Errorf("expected %q in:\n%s", want, text)
+	}
+
+	result, err = callTool(t, s, "get_finding_detail", map[string]any{"finding_id": 4, "format": "json"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var output struct {
+		ID           int        `json:"id"`
+		Exploitation []CVEIntel `json:"exploitation"`
+	}
+	if err := json.Unmarshal([]byte(resultText(result)), &output); err != nil {
+		t.Fatalf("invalid JSON output: %v", err)
+	}
+	if output.ID != 4 || len(output.Exploitation) != 1 || output.Exploitation[0].KEV == nil {
+		t.Errorf("unexpected JSON output: %+v", output)
+	}
+
+	// Without enrichment nothing changes
+	result, _ = callTool(t, newServer(&Config{}, fixtures), "get_finding_detail", map[string]any{"finding_id": 4})
+	if strings.Contains(resultText(result), "Exploitation") {
+		t.Error("expected no exploitation data with enrichment off")
+	}
+}
@@ -0,0 +1,109 @@
+package mcpserver
+
+import (
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"time"
+)
+
+// runtimeStats is the /debug/runtime document: what the process holds in
+// memory and in the server's caches, to tell a leak from a busy server
+type runtimeStats struct {
+	Uptime     string `json:"uptime"`
+	Goroutines int    `json:"goroutines"`
+	GOMAXPROCS int    `json:"gomaxprocs"`
+	Memory     struct {
+		HeapAllocBytes uint64  `json:"heap_alloc_bytes"` // Live and not yet collected heap objects
+		HeapInuseBytes uint64  `json:"heap_inuse_bytes"`
+		HeapObjects    uint64  `json:"heap_objects"`
+		SysBytes       uint64  `json:"sys_bytes"` // Memory obtained from the OS
+		NumGC          uint32  `json:"num_gc"`
+		LastGCPauseMS  float64 `json:"last_gc_pause_ms"`
+	} `json:"memory"`
+	Caches struct {
+		ReferenceEntries  int   `json:"reference_entries"`   // Cached products, tests and other reference data
+		Sessions          int   `json:"sessions"`            // MCP sessions with state
+		PinnedFindings    int   `json:"pinned_findings"`     // Across all sessions
+		SavedResults      int   `json:"saved_results"`       // Across all sessions
+		Exports           int   `json:"exports"`             // Findings exports across all sessions
+		ExportChunks      int   `json:"export_chunks"`       // Chunks of those exports
+		SpilledChunks     int   `json:"spilled_chunks"`      // Chunks written to disk
+		FullResults       int   `json:"full_results"`        // Full texts of summarized tool results
+		ExportMemoryBytes int64 `json:"export_memory_bytes"` // Chunk bytes held in memory, within the export budget
+		Events            int   `json:"events"`              // Buffered DefectDojo webhook events
+	} `json:"caches"`
+	WorkerPool []workerPoolStats `json:"worker_pool"`
+}
+
+// runtimeStats takes a snapshot of the process and cache sizes
+func (s *Server) runtimeStats() runtimeStats {
+	var stats runtimeStats
+	stats.Uptime = time.Since(s.stats.started).Round(time.Second).String()
+	stats.Goroutines = runtime.NumGoroutine()
+	stats.GOMAXPROCS = runtime.GOMAXPROCS(0)
+
+	var memory runtime.MemStats
+	runtime.ReadMemStats(&memory)
+	stats.Memory.HeapAllocBytes = memory.HeapAlloc
+	stats.Memory.HeapInuseBytes = memory.HeapInuse
+	stats.Memory.HeapObjects = memory.HeapObjects
+	stats.Memory.SysBytes = memory.Sys
+	stats.Memory.NumGC = memory.NumGC
+	if memory.NumGC > 0 {
+		stats.Memory.LastGCPauseMS = milliseconds(time.Duration(memory.PauseNs[(memory.NumGC+255)%256]))
+	}
+
+	caches := &stats.Caches
+	caches.ReferenceEntries = s.refs.Len()
+	s.sessions.mu.Lock()
+	caches.Sessions = len(s.sessions.sessions)
+	for _, state := range s.sessions.sessions {
+		caches.PinnedFindings += len(state.pins)
+		caches.SavedResults += len(state.results)
+		caches.Exports += len(state.exports)
+		caches.FullResults += len(state.fullResults)
+		for _, export := range state.exports {
+			caches.ExportChunks += export.chunks.len()
+			caches.SpilledChunks += export.chunks.spilled()
+		}
+	}
+	s.sessions.mu.Unlock()
+	s.exports.mu.Lock()
+	caches.ExportMemoryBytes = s.exports.used
+	s.exports.mu.Unlock()
+	s.events.mu.Lock()
+	caches.Events = len(s.events.events)
+	s.events.mu.Unlock()
+
+	stats.WorkerPool = s.workers.snapshot()
+	return stats
+}
+
+// DebugHandler returns an http.Handler for profiling the server:
+//
+//   - /debug/pprof/: the net/http/pprof profiles (heap, goroutine, CPU
+//     profile, trace, ...), for go tool pprof
+//   - /debug/runtime: goroutines, heap statistics, cache and session state
+//     sizes and worker pool usage as JSON
+//
+// Profiles reveal memory contents and cost CPU, so serve it on a listener
+// only operators can reach, such as localhost.
+func (s *Server) DebugHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.HandleFunc("/debug/runtime", func(w http.ResponseWriter, r *http.Request) {
+		output, err := marshalOutput(s.runtimeStats())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(output + "\n"))
+	})
+	return mux
+}
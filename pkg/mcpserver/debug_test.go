@@ -0,0 +1,39 @@
+package mcpserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDebugHandler(t *testing.T) {
+	s := newServer(&Config{}, &MockDefectDojoClient{})
+	if _, err := callTool(t, s, "pin_findings", map[string]any{"finding_ids": []any{1, 2}}); err != nil {
+		t.Fatal(err)
+	}
+	handler := s.DebugHandler()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/runtime", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	var stats runtimeStats
+	if err := json.Unmarshal(rec.Body.Bytes(), &stats); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, rec.Body)
+	}
+	if stats.Goroutines == 0 || stats.Memory.HeapAllocBytes == 0 {
+		t.Errorf("expected process statistics, got %+v", stats)
+	}
+	if stats.Caches.Sessions != 1 || stats.Caches.PinnedFindings != 2 {
+		t.Errorf("expected one session with 2 pins, got %+v", stats.Caches)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/pprof/", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "goroutine") {
+		t.Errorf("expected the pprof index, got %d", rec.Code)
+	}
+}
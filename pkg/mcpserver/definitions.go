@@ -0,0 +1,608 @@
+package mcpserver
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+// Tool names
+const (
+	toolHealthCheck         = "defectdojo_health_check"
+	toolGetFindings         = "get_defectdojo_findings"
+	toolFindingDetail       = "get_finding_detail"
+	toolMarkFalsePositive   = "mark_finding_false_positive"
+	toolClearFalsePositive  = "clear_false_positive"
+	toolChangeSeverity      = "change_finding_severity"
+	toolAssignFinding       = "assign_finding"
+	toolAddNote             = "add_note_to_findings"
+	toolInvalidateCache     = "invalidate_reference_cache"
+	toolListSavedQueries    = "list_saved_queries"
+	toolRunSavedQuery       = "run_saved_query"
+	toolListPendingActions  = "list_pending_actions"
+	toolGetRecentEvents     = "get_recent_events"
+	toolGetNewFindings      = "get_new_findings_since_last_check"
+	toolCreateProduct       = "create_product"
+	toolCreateEngagement    = "create_engagement"
+	toolImportSARIF         = "import_sarif"
+	toolSBOMComponents      = "find_sbom_component_findings"
+	toolPrioritizeFindings  = "prioritize_findings"
+	toolSummarizePosture    = "summarize_security_posture"
+	toolMTTRMetrics         = "get_mttr_metrics"
+	toolCreateIssue         = "create_issue_from_finding"
+	toolExpiringRisks       = "get_expiring_risk_acceptances"
+	toolEngagementOverview  = "get_engagement_overview"
+	toolEngagementReadiness = "check_engagement_readiness"
+	toolFindingsByHost      = "get_findings_by_host"
+	toolStaleFindings       = "get_stale_findings"
+	toolImportSummary       = "get_import_summary"
+	toolSystemInfo          = "get_defectdojo_system_info"
+	toolServerStats         = "get_server_stats"
+	toolPinFindings         = "pin_findings"
+	toolGetPinned           = "get_pinned_findings"
+	toolClearPins           = "clear_pins"
+	toolSaveQueryResult     = "save_query_result"
+	toolExportFindings      = "export_findings"
+)
+
+// withFormatArgument adds the optional output format argument shared by the read tools.
+func withFormatArgument() mcp.ToolOption {
+	return mcp.WithString("format", mcp.Enum(outputFormats()...), mcp.Description("Output format: text, markdown for a findings table and structured sections, or json for raw data with a pagination cursor (default: server setting, usually text)"))
+}
+
+// withLanguageArgument adds the optional output language argument shared by the findings read tools.
+func withLanguageArgument() mcp.ToolOption {
+	return mcp.WithString("language", mcp.Enum(outputLanguages()...), mcp.Description("Language of labels and headings in text and markdown output: en, pt (Portuguese) or es (Spanish). Finding data is not translated (default: server setting, usually en)"))
+}
+
+// withIncludeContextArgument adds the optional include_context argument shared by the read tools.
+func withIncludeContextArgument() mcp.ToolOption {
+	return mcp.WithBoolean("include_context", mcp.Description("Resolve and show each finding's product and engagement names (tests are prefetched with the findings, other lookups cached; default: false)"))
+}
+
+// withIfUnmodifiedSinceArgument adds the optional concurrency check shared by the finding update tools.
+func withIfUnmodifiedSinceArgument() mcp.ToolOption {
+	return mcp.WithString("if_unmodified_since", mcp.MinLength(1), mcp.Description("The finding's Modified timestamp from when you read it (RFC 3339). The update fails with a conflict if someone changed the finding since; re-read it and decide again"))
+}
+
+// severityEnum restricts a string parameter to DefectDojo's severity levels.
+// Matching is case-insensitive; handlers normalize with severityArgument, and
+// addTool adds the configured organization labels to the enum.
+func severityEnum() mcp.PropertyOption {
+	return mcp.Enum(types.ValidSeverities()...)
+}
+
+// healthCheckTool defines defectdojo_health_check
+func healthCheckTool() mcp.Tool {
+	return mcp.NewTool(toolHealthCheck,
+		mcp.WithDescription("Check if DefectDojo instance is reachable and accepts the API token. Reports latency, the authenticated user and the DefectDojo version; when further instances are configured, probes them all concurrently and reports one row per instance"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithOpenWorldHintAnnotation(true),
+		withTimeoutArgument(),
+	)
+}
+
+// findingsTool defines get_defectdojo_findings
+func findingsTool() mcp.Tool {
+	return mcp.NewTool(toolGetFindings,
+		mcp.WithDescription("Retrieve vulnerability findings from DefectDojo instance with optional filtering. Every response reports has_more and next_offset; pass next_offset as offset to fetch the next page"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithOpenWorldHintAnnotation(true),
+		mcp.WithNumber("limit", integer(), mcp.Min(1), mcp.Description("Number of findings to retrieve (default: server setting, usually 10)")),
+		mcp.WithNumber("offset", integer(), mcp.Min(0), mcp.Description("Offset for pagination (default: 0)")),
+		mcp.WithBoolean("active", mcp.Description("Filter by active status: true = open findings, false = closed findings; overrides active_only")),
+		mcp.WithBoolean("active_only", mcp.Description("Deprecated, use active. Filter only active findings; false returns both active and inactive findings (default: true)")),
+		mcp.WithString("severity", severityEnum(), mcp.Description("Filter by severity (Critical, High, Medium, Low, Info; case-insensitive)")),
+		mcp.WithString("min_severity", severityEnum(), mcp.Description("Only return findings at or above this severity, most severe first (e.g. High = High and Critical; case-insensitive)")),
+		mcp.WithNumber("test", integer(), mcp.Min(1), mcp.Description("Filter by test ID")),
+		mcp.WithNumber("product", integer(), mcp.Min(1), mcp.Description("Filter by product ID")),
+		mcp.WithString("sort_by", mcp.Description(fmt.Sprintf("Sort order: comma-separated fields, prefix with '-' for descending (e.g. '-date', 'numerical_severity' = most severe first). Allowed fields: %s", strings.Join(types.OrderingFields(), ", ")))),
+		mcp.WithBoolean("risk_accepted", mcp.Description("Filter by risk acceptance (omit for all)")),
+		mcp.WithBoolean("is_mitigated", mcp.Description("Filter by mitigation status (omit for all)")),
+		mcp.WithBoolean("duplicate", mcp.Description("Filter by duplicate status (omit for all)")),
+		mcp.WithArray("tags", mcp.WithStringItems(), mcp.Description("Only findings carrying any of these tags")),
+		mcp.WithArray("not_tags", mcp.WithStringItems(), mcp.Description("Exclude findings carrying any of these tags")),
+		mcp.WithArray("reporter", mcp.WithNumberItems(integer(), mcp.Min(1)), mcp.Description("Only findings reported by these user IDs")),
+		mcp.WithString("assigned_to", mcp.MinLength(1), mcp.Description("Only findings assigned to this user: a DefectDojo login name, or \"me\" for the user owning the server's API token")),
+		mcp.WithString("environment", mcp.MinLength(1), mcp.Description("Only findings of tests run in this development environment, by name (e.g. Production, Staging; case-insensitive) or ID")),
+		mcp.WithArray("found_by", mcp.WithNumberItems(integer(), mcp.Min(1)), mcp.Description("Only findings found by these test type (scanner) IDs")),
+		mcp.WithString("detail_level", mcp.Enum(detailLevels()...), mcp.Description("How much to show per finding: summary = one line, normal = status and shortened description, full = adds location, dates and SLA (default: server setting, usually normal)")),
+		mcp.WithNumber("max_description_chars", integer(), mcp.Min(0), mcp.Description("Truncate each finding's description to this many characters; 0 = no limit (default: server setting)")),
+		mcp.WithBoolean("group_repeated", mcp.Description("Collapse findings of the page with the same title and component into one entry with an occurrence count and their IDs, e.g. one issue reported on 200 endpoints (default: false)")),
+		withFormatArgument(),
+		withLanguageArgument(),
+		withIncludeContextArgument(),
+		withTimeoutArgument(),
+	)
+}
+
+// findingDetailTool defines get_finding_detail
+func findingDetailTool() mcp.Tool {
+	return mcp.NewTool(toolFindingDetail,
+		mcp.WithDescription("Get detailed information about a specific finding by ID"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithOpenWorldHintAnnotation(true),
+		mcp.WithNumber("finding_id", mcp.Required(), integer(), mcp.Min(1), mcp.Description("The ID of the finding to retrieve")),
+		mcp.WithNumber("max_field_chars", integer(), mcp.Description("Truncate long text sections (description, mitigation, ...) to this many characters; 0 = no limit (default: server setting)"), mcp.Min(0)),
+		withFormatArgument(),
+		withLanguageArgument(),
+		withIncludeContextArgument(),
+		withTimeoutArgument(),
+	)
+}
+
+// markFalsePositiveTool defines mark_finding_false_positive
+func markFalsePositiveTool() mcp.Tool {
+	return mcp.NewTool(toolMarkFalsePositive,
+		mcp.WithDescription("Mark a finding as false positive. The justification and optional notes are added to the finding as a note"),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithIdempotentHintAnnotation(true),
+		mcp.WithOpenWorldHintAnnotation(true),
+		mcp.WithNumber("finding_id", mcp.Required(), integer(), mcp.Min(1), mcp.Description("The ID of the finding to mark as false positive")),
+		mcp.WithString("justification", mcp.Required(), mcp.MinLength(1), mcp.Description("Justification for marking as false positive")),
+		mcp.WithString("notes", mcp.Description("Optional additional notes or comments")),
+		mcp.WithBoolean("also_deactivate", mcp.Description("Also set the finding inactive, closing it (default: false)")),
+		mcp.WithBoolean("verified", mcp.Description("Pass false to clear the finding's verified flag; DefectDojo refuses verified false positives (omit to leave unchanged)")),
+		withIfUnmodifiedSinceArgument(),
+		withTimeoutArgument(),
+	)
+}
+
+// clearFalsePositiveTool defines clear_false_positive
+func clearFalsePositiveTool() mcp.Tool {
+	return mcp.NewTool(toolClearFalsePositive,
+		mcp.WithDescription("Reverse a false positive decision: clear the finding's false positive flag. The reason and optional notes are added to the finding as a note"),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithIdempotentHintAnnotation(true),
+		mcp.WithOpenWorldHintAnnotation(true),
+		mcp.WithNumber("finding_id", mcp.Required(), integer(), mcp.Min(1), mcp.Description("The ID of the finding to clear")),
+		mcp.WithString("justification", mcp.Required(), mcp.MinLength(1), mcp.Description("Why the finding is not a false positive after all")),
+		mcp.WithString("notes", mcp.Description("Optional additional notes or comments")),
+		mcp.WithBoolean("reactivate", mcp.Description("Also set the finding active again (default: true)")),
+		mcp.WithBoolean("verified", mcp.Description("Set the finding's verified flag (omit to leave unchanged)")),
+		withIfUnmodifiedSinceArgument(),
+		withTimeoutArgument(),
+	)
+}
+
+// changeSeverityTool defines change_finding_severity
+func changeSeverityTool() mcp.Tool {
+	return mcp.NewTool(toolChangeSeverity,
+		mcp.WithDescription("Re-grade a finding, e.g. lower a scanner's Critical to Medium when the vulnerable code is unreachable. The justification is added to the finding as a note. The write policy may hold some changes, such as downgrades of Critical findings, for human approval"),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithIdempotentHintAnnotation(true),
+		mcp.WithOpenWorldHintAnnotation(true),
+		mcp.WithNumber("finding_id", mcp.Required(), integer(), mcp.Min(1), mcp.Description("The ID of the finding to re-grade")),
+		mcp.WithString("severity", mcp.Required(), severityEnum(), mcp.Description("The new severity (Critical, High, Medium, Low, Info; case-insensitive)")),
+		mcp.WithString("justification", mcp.Required(), mcp.MinLength(1), mcp.Description("Why the finding deserves the new severity, e.g. the mitigating or aggravating context")),
+		withIfUnmodifiedSinceArgument(),
+		withTimeoutArgument(),
+	)
+}
+
+// assignFindingTool defines assign_finding
+func assignFindingTool() mcp.Tool {
+	return mcp.NewTool(toolAssignFinding,
+		mcp.WithDescription("Assign a finding to a DefectDojo user, who becomes responsible for following it up. The assignees are stored as the finding's reviewers; find a user's findings with get_defectdojo_findings assigned_to"),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithIdempotentHintAnnotation(true),
+		mcp.WithOpenWorldHintAnnotation(true),
+		mcp.WithNumber("finding_id", mcp.Required(), integer(), mcp.Min(1), mcp.Description("The ID of the finding to assign")),
+		mcp.WithString("username", mcp.Required(), mcp.MinLength(1), mcp.Description("DefectDojo login name of the new assignee, or \"me\" for the user owning the server's API token")),
+		mcp.WithBoolean("keep_existing", mcp.Description("Add the user to the current assignees instead of replacing them (default: false)")),
+		withIfUnmodifiedSinceArgument(),
+		withTimeoutArgument(),
+	)
+}
+
+// addNoteTool defines add_note_to_findings
+func addNoteTool() mcp.Tool {
+	return mcp.NewTool(toolAddNote,
+		mcp.WithDescription("Add the same note to several findings at once, e.g. \"tracked in INC-1234\". Notes are posted concurrently; the result lists the note added to each finding, and on partial failure which findings did and did not get it"),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithIdempotentHintAnnotation(false),
+		mcp.WithOpenWorldHintAnnotation(true),
+		mcp.WithArray("finding_ids", mcp.MinItems(1), mcp.MaxItems(maxNoteFindings), mcp.WithNumberItems(integer(), mcp.Min(1)), mcp.Description("IDs of the findings to add the note to (or pass saved_result)")),
+		withSavedResultArgument(),
+		mcp.WithString("note", mcp.Required(), mcp.MinLength(1), mcp.Description("The note text")),
+		withTimeoutArgument(),
+	)
+}
+
+// invalidateCacheTool defines invalidate_reference_cache
+func invalidateCacheTool() mcp.Tool {
+	return mcp.NewTool(toolInvalidateCache,
+		mcp.WithDescription("Clear cached reference data (product, engagement, test and scanner names, and users looked up by login name) so changes made in DefectDojo show up immediately instead of after the cache TTL"),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithIdempotentHintAnnotation(true),
+		mcp.WithOpenWorldHintAnnotation(false),
+		mcp.WithString("kind", mcp.Enum(referenceKinds()...), mcp.Description("Only invalidate this kind of reference data (omit to clear everything)")),
+	)
+}
+
+// listSavedQueriesTool defines list_saved_queries
+func listSavedQueriesTool() mcp.Tool {
+	return mcp.NewTool(toolListSavedQueries,
+		mcp.WithDescription("List the saved findings queries configured by the operator, with their descriptions and filters. Prefer running one of these with run_saved_query over building filters by hand"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithOpenWorldHintAnnotation(false),
+	)
+}
+
+// runSavedQueryTool defines run_saved_query. Its filters come from the saved
+// query; only paging and output arguments of get_defectdojo_findings can be
+// overridden, and they share that tool's schema.
+func runSavedQueryTool() mcp.Tool {
+	tool := mcp.NewTool(toolRunSavedQuery,
+		mcp.WithDescription("Run a saved findings query by name (see list_saved_queries). The filters are fixed by the operator; paging and output options can be overridden. Responses report has_more and next_offset like get_defectdojo_findings"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithOpenWorldHintAnnotation(true),
+		mcp.WithString("name", mcp.Required(), mcp.MinLength(1), mcp.Description("Name of the saved query to run")),
+		withTimeoutArgument(),
+	)
+	findings := findingsTool().InputSchema.Properties
+	for _, name := range savedQueryOverrides() {
+		tool.InputSchema.Properties[name] = findings[name]
+	}
+	return tool
+}
+
+// listPendingActionsTool defines list_pending_actions
+func listPendingActionsTool() mcp.Tool {
+	return mcp.NewTool(toolListPendingActions,
+		mcp.WithDescription("List write operations queued for human approval and their outcome. In approval mode, false positive changes are only applied after a human approves them"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithOpenWorldHintAnnotation(false),
+		mcp.WithString("status", mcp.Enum(actionStatuses()...), mcp.Description("Only list actions in this state (default: pending)")),
+	)
+}
+
+// recentEventsTool defines get_recent_events
+func recentEventsTool() mcp.Tool {
+	return mcp.NewTool(toolGetRecentEvents,
+		mcp.WithDescription("Get recent DefectDojo notifications received by webhook (e.g. scan_added with new findings, engagement_closed), oldest first. Pass the reported since_id on the next call to see only newer events instead of polling findings"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithOpenWorldHintAnnotation(false),
+		mcp.WithNumber("since_id", integer(), mcp.Min(0), mcp.Description("Only return events with a higher id (default: 0 = all kept events)")),
+		mcp.WithString("type", mcp.Description("Only return events of this DefectDojo event type, e.g. scan_added")),
+		mcp.WithNumber("limit", integer(), mcp.Min(1), mcp.Description("Maximum number of events to return (default: 20)")),
+	)
+}
+
+// newFindingsTool defines get_new_findings_since_last_check
+func newFindingsTool() mcp.Tool {
+	tool := mcp.NewTool(toolGetNewFindings,
+		mcp.WithDescription("List findings that appeared or changed (status, severity, modification) since the previous call of this tool. The server keeps the watermark, so periodic agents get a cheap \"what changed\" digest; the first call only starts watching"),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithIdempotentHintAnnotation(false),
+		mcp.WithOpenWorldHintAnnotation(true),
+		withTimeoutArgument(),
+	)
+	findings := findingsTool().InputSchema.Properties
+	tool.InputSchema.Properties["detail_level"] = findings["detail_level"]
+	tool.InputSchema.Properties["language"] = findings["language"]
+	return tool
+}
+
+// createProductTool defines create_product
+func createProductTool() mcp.Tool {
+	return mcp.NewTool(toolCreateProduct,
+		mcp.WithDescription("Create a DefectDojo product for a new application. This is the first step of onboarding: then create an engagement in it with create_engagement and import scan results into that engagement with import_sarif"),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithIdempotentHintAnnotation(false),
+		mcp.WithOpenWorldHintAnnotation(true),
+		mcp.WithString("name", mcp.Required(), mcp.MinLength(1), mcp.Description("Product name; must not already exist")),
+		mcp.WithString("product_type", mcp.Required(), mcp.MinLength(1), mcp.Description("Product type name or ID, e.g. \"Web Applications\". An unknown type fails with the list of available ones")),
+		mcp.WithString("description", mcp.Required(), mcp.MinLength(1), mcp.Description("What the application is and does")),
+		mcp.WithArray("tags", mcp.WithStringItems(), mcp.Description("Tags for the product, e.g. team or business unit")),
+		withTimeoutArgument(),
+	)
+}
+
+// createEngagementTool defines create_engagement
+func createEngagementTool() mcp.Tool {
+	return mcp.NewTool(toolCreateEngagement,
+		mcp.WithDescription("Create an engagement in a product to import scan results into with import_sarif. The status is Not Started for a future start date, otherwise In Progress"),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithIdempotentHintAnnotation(false),
+		mcp.WithOpenWorldHintAnnotation(true),
+		mcp.WithNumber("product_id", mcp.Required(), integer(), mcp.Min(1), mcp.Description("Product to create the engagement in")),
+		mcp.WithString("name", mcp.Required(), mcp.MinLength(1), mcp.Description("Engagement name, e.g. \"CI scans\" or \"Q3 Pentest\"")),
+		mcp.WithString("description", mcp.Description("What the engagement covers")),
+		mcp.WithString("engagement_type", mcp.Enum(engagementTypeInteractive, engagementTypeCICD), mcp.Description("Interactive for manual testing, CI/CD for pipeline scans (default: Interactive)")),
+		mcp.WithString("target_start", mcp.Description("Start date as YYYY-MM-DD (default: today)")),
+		mcp.WithString("target_end", mcp.Description("End date as YYYY-MM-DD (default: a week after target_start)")),
+		withTimeoutArgument(),
+	)
+}
+
+// importSARIFTool defines import_sarif
+func importSARIFTool() mcp.Tool {
+	return mcp.NewTool(toolImportSARIF,
+		mcp.WithDescription("Import a SARIF 2.1.0 report into DefectDojo. Each run (scanner) in the report becomes its own test, and the response reports per-run import statistics. Target an existing engagement by engagement_id, or by product_name and engagement_name"),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithIdempotentHintAnnotation(false),
+		mcp.WithOpenWorldHintAnnotation(true),
+		mcp.WithString("sarif", mcp.Required(), mcp.MinLength(1), mcp.Description("The SARIF report as JSON text")),
+		mcp.WithNumber("engagement_id", integer(), mcp.Min(1), mcp.Description("Engagement to import into")),
+		mcp.WithString("product_name", mcp.Description("Product to import into, with engagement_name, when engagement_id is not given")),
+		mcp.WithString("engagement_name", mcp.Description("Engagement to import into, with product_name, when engagement_id is not given")),
+		mcp.WithBoolean("auto_create_context", mcp.Description("Create the named product and engagement if they do not exist (default: false)")),
+		mcp.WithString("test_title", mcp.Description("Title of the created tests (default: each run's scanner name)")),
+		mcp.WithString("minimum_severity", severityEnum(), mcp.Description("Skip results below this severity (default: import everything)")),
+		mcp.WithBoolean("active", mcp.Description("Mark imported findings active (default: DefectDojo setting, usually true)")),
+		mcp.WithBoolean("verified", mcp.Description("Mark imported findings verified (default: DefectDojo setting, usually false)")),
+		withTimeoutArgument(),
+	)
+}
+
+// sbomComponentsTool defines find_sbom_component_findings
+func sbomComponentsTool() mcp.Tool {
+	return mcp.NewTool(toolSBOMComponents,
+		mcp.WithDescription("Cross-reference a CycloneDX SBOM or a list of package URLs with DefectDojo findings by component name and version, and report which components have open findings"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithOpenWorldHintAnnotation(true),
+		mcp.WithString("sbom", mcp.Description("CycloneDX SBOM as JSON text")),
+		mcp.WithArray("purls", mcp.WithStringItems(), mcp.Description("Package URLs to look up, e.g. pkg:npm/lodash@4.17.15 (in addition to, or instead of, sbom)")),
+		mcp.WithNumber("product", integer(), mcp.Min(1), mcp.Description("Only consider findings of this product ID")),
+		mcp.WithBoolean("include_closed", mcp.Description("Also match closed findings (default: false = open findings only)")),
+		withTimeoutArgument(),
+	)
+}
+
+// prioritizeFindingsTool defines prioritize_findings
+func prioritizeFindingsTool() mcp.Tool {
+	options := []mcp.ToolOption{
+		mcp.WithDescription("Rank open findings by remediation priority. Each finding gets a 0-100 score from weighted factors: severity, CVSS, EPSS and CISA KEV (with CVE enrichment), age, SLA pressure and product criticality; the ranking shows every score's breakdown"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithOpenWorldHintAnnotation(true),
+		mcp.WithNumber("product", integer(), mcp.Min(1), mcp.Description("Only rank findings of this product ID")),
+		mcp.WithString("min_severity", severityEnum(), mcp.Description("Only rank findings at or above this severity (case-insensitive)")),
+		mcp.WithNumber("limit", integer(), mcp.Min(1), mcp.Max(maxPrioritizedShown), mcp.Description(fmt.Sprintf("Number of top-ranked findings to list (default: %d)", defaultPrioritizedShown))),
+		mcp.WithArray("critical_tags", mcp.WithStringItems(), mcp.Description("Product tags that mark business-critical products (default: server setting)")),
+	}
+	for _, factor := range priorityFactors() {
+		options = append(options, mcp.WithNumber(factor+"_weight", mcp.Min(0), mcp.Description(fmt.Sprintf("Relative weight of the %s factor (default: server setting)", factor))))
+	}
+	options = append(options, withTimeoutArgument())
+	return mcp.NewTool(toolPrioritizeFindings, options...)
+}
+
+// summarizePostureTool defines summarize_security_posture
+func summarizePostureTool() mcp.Tool {
+	return mcp.NewTool(toolSummarizePosture,
+		mcp.WithDescription("Summarize the security posture across products as a JSON object for an executive report: open findings by severity with a compact histogram per product and overall, SLA breaches, findings discovered and mitigated in the last 7 days, and the most exposed products. All aggregation happens server-side"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithOpenWorldHintAnnotation(true),
+		mcp.WithArray("product_tags", mcp.WithStringItems(), mcp.Description("Only summarize products with any of these tags (default: all products)")),
+		mcp.WithNumber("top_products", integer(), mcp.Min(1), mcp.Max(maxPostureTopN), mcp.Description(fmt.Sprintf("Number of most exposed products to list individually (default: %d)", defaultPostureTopN))),
+		withTimeoutArgument(),
+	)
+}
+
+// mttrMetricsTool defines get_mttr_metrics
+func mttrMetricsTool() mcp.Tool {
+	return mcp.NewTool(toolMTTRMetrics,
+		mcp.WithDescription("Measure mean time to remediate as a JSON object: the mean, median and 90th percentile of days from creation to mitigation of the findings mitigated in a date range, per severity, per product and overall. All aggregation happens server-side"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithOpenWorldHintAnnotation(true),
+		mcp.WithString("start_date", mcp.Description(fmt.Sprintf("First day of mitigations to measure, YYYY-MM-DD (default: %d days before end_date)", defaultMTTRDays))),
+		mcp.WithString("end_date", mcp.Description("Last day of mitigations to measure, YYYY-MM-DD (default: today)")),
+		mcp.WithNumber("product", integer(), mcp.Min(1), mcp.Description("Only measure this product ID (default: all products)")),
+		mcp.WithArray("product_tags", mcp.WithStringItems(), mcp.Description("Only measure products with any of these tags (default: all products)")),
+		mcp.WithString("severity", severityEnum(), mcp.Description("Only measure findings of this severity (default: all)")),
+		withTimeoutArgument(),
+	)
+}
+
+// createIssueTool defines create_issue_from_finding
+func createIssueTool() mcp.Tool {
+	return mcp.NewTool(toolCreateIssue,
+		mcp.WithDescription("File a GitHub or GitLab issue for a finding, pre-populated with its title, severity, description, remediation guidance and a link back to DefectDojo. The issue URL is stored as finding metadata; a finding that already has an issue is not filed again"),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithIdempotentHintAnnotation(true),
+		mcp.WithOpenWorldHintAnnotation(true),
+		mcp.WithNumber("finding_id", mcp.Required(), integer(), mcp.Min(1), mcp.Description("ID of the finding to file an issue for")),
+		mcp.WithArray("labels", mcp.WithStringItems(), mcp.Description("Issue labels, in addition to the server's configured labels")),
+		withTimeoutArgument(),
+	)
+}
+
+// expiringRisksTool defines get_expiring_risk_acceptances
+func expiringRisksTool() mcp.Tool {
+	return mcp.NewTool(toolExpiringRisks,
+		mcp.WithDescription("List risk acceptances expiring within the next N days, soonest first, with their decision, owner, what happens on expiry and the accepted findings, so owners can be asked to renew or fix them in time"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithOpenWorldHintAnnotation(true),
+		mcp.WithNumber("days", integer(), mcp.Min(1), mcp.Max(maxRiskExpiryDays), mcp.Description("How many days ahead to look (default: 30)")),
+		mcp.WithBoolean("include_expired", mcp.Description("Also list acceptances that have expired but whose expiry DefectDojo has not handled yet (default: false)")),
+		mcp.WithString("format", mcp.Enum(formatText, formatJSON), mcp.Description("Output format: text or json (default: server setting, usually text)")),
+		withTimeoutArgument(),
+	)
+}
+
+// engagementOverviewTool defines get_engagement_overview
+func engagementOverviewTool() mcp.Tool {
+	return mcp.NewTool(toolEngagementOverview,
+		mcp.WithDescription("Overview of the testing schedule: engagements in progress and those starting soon across products, with their lead, target dates, status and open finding counts by severity. Engagements past their target end date are flagged as overdue"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithOpenWorldHintAnnotation(true),
+		mcp.WithNumber("days_ahead", integer(), mcp.Min(0), mcp.Max(maxUpcomingDays), mcp.Description("List engagements starting within this many days (default: 30)")),
+		mcp.WithNumber("product", integer(), mcp.Min(1), mcp.Description("Only engagements of this product ID (default: all products)")),
+		mcp.WithString("format", mcp.Enum(formatText, formatJSON), mcp.Description("Output format: text or json (default: server setting, usually text)")),
+		withTimeoutArgument(),
+	)
+}
+
+// engagementReadinessTool defines check_engagement_readiness
+func engagementReadinessTool() mcp.Tool {
+	return mcp.NewTool(toolEngagementReadiness,
+		mcp.WithDescription("Check whether an engagement is ready to close: it has tests, a scan was imported into every test, no active Critical finding is unverified, and every active finding is verified or risk accepted. Returns a pass/fail checklist with the offending tests and findings to relay before closing the engagement"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithOpenWorldHintAnnotation(true),
+		mcp.WithNumber("engagement_id", mcp.Required(), integer(), mcp.Min(1), mcp.Description("ID of the engagement to check")),
+		mcp.WithString("format", mcp.Enum(formatText, formatJSON), mcp.Description("Output format: text or json (default: server setting, usually text)")),
+		withTimeoutArgument(),
+	)
+}
+
+// findingsByHostTool defines get_findings_by_host
+func findingsByHostTool() mcp.Tool {
+	return mcp.NewTool(toolFindingsByHost,
+		mcp.WithDescription("Group active findings by affected host (from DefectDojo endpoints) with per-host severity counts, most affected hosts first. Use it to hand infrastructure teams a per-server work list; findings without endpoints, e.g. from code scanners, are not included"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithOpenWorldHintAnnotation(true),
+		mcp.WithNumber("product", integer(), mcp.Min(1), mcp.Description("Only hosts of this product ID (default: all products)")),
+		mcp.WithString("min_severity", severityEnum(), mcp.Description("Only count findings at or above this severity (default: all)")),
+		mcp.WithNumber("max_hosts", integer(), mcp.Min(1), mcp.Max(maxHostLimit), mcp.Description("Maximum number of hosts to list (default: 25)")),
+		mcp.WithString("format", mcp.Enum(formatText, formatJSON), mcp.Description("Output format: text or json (default: server setting, usually text)")),
+		withTimeoutArgument(),
+	)
+}
+
+// staleFindingsTool defines get_stale_findings
+func staleFindingsTool() mcp.Tool {
+	return mcp.NewTool(toolStaleFindings,
+		mcp.WithDescription("Count active findings open for at least N days in age buckets (30-59, 60-89, 90-179 and 180+ days) with the most severe findings of each bucket. Counts come from DefectDojo, so they cover every finding; use it to answer what has been open forever"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithOpenWorldHintAnnotation(true),
+		mcp.WithNumber("days", integer(), mcp.Min(1), mcp.Max(maxStaleDays), mcp.Description(fmt.Sprintf("Minimum age in days; the first bucket starts here (default: %d)", defaultStaleDays))),
+		mcp.WithNumber("product", integer(), mcp.Min(1), mcp.Description("Only findings of this product ID (default: all products)")),
+		mcp.WithString("severity", severityEnum(), mcp.Description("Only findings of this severity (default: all)")),
+		mcp.WithNumber("max_per_bucket", integer(), mcp.Min(1), mcp.Max(maxStalePerBucket), mcp.Description(fmt.Sprintf("Findings listed per bucket, most severe and oldest first (default: %d)", defaultStalePerBucket))),
+		mcp.WithString("format", mcp.Enum(formatText, formatJSON), mcp.Description("Output format: text or json (default: server setting, usually text)")),
+		withTimeoutArgument(),
+	)
+}
+
+// importSummaryTool defines get_import_summary
+func importSummaryTool() mcp.Tool {
+	return mcp.NewTool(toolImportSummary,
+		mcp.WithDescription("Report how many findings the last scan import into a test created, closed, reactivated and left untouched, compared with earlier imports from DefectDojo's import history. Flags anomalies such as an empty report from a scanner that usually finds hundreds, or a reimport closing most findings; use it to sanity-check CI imports"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithOpenWorldHintAnnotation(true),
+		mcp.WithNumber("test_id", mcp.Required(), integer(), mcp.Min(1), mcp.Description("ID of the test the scan was imported into")),
+		mcp.WithString("format", mcp.Enum(formatText, formatJSON), mcp.Description("Output format: text or json (default: server setting, usually text)")),
+		withTimeoutArgument(),
+	)
+}
+
+// systemInfoTool defines get_defectdojo_system_info
+func systemInfoTool() mcp.Tool {
+	return mcp.NewTool(toolSystemInfo,
+		mcp.WithDescription("Read how this DefectDojo instance is configured: deduplication, false positive history, finding SLAs and the SLA configurations' deadlines, risk acceptance, disclaimers and the current announcement, with what each means for triage advice. Call it once before advising on duplicates, deadlines or notes; system settings need a superuser token"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithOpenWorldHintAnnotation(true),
+		mcp.WithString("format", mcp.Enum(formatText, formatJSON), mcp.Description("Output format: text or json (default: server setting, usually text)")),
+		withTimeoutArgument(),
+	)
+}
+
+// serverStatsTool defines get_server_stats
+func serverStatsTool() mcp.Tool {
+	return mcp.NewTool(toolServerStats,
+		mcp.WithDescription("Report how often each tool was called since the server started, how many calls failed and their median latency, and how much response compression saves on DefectDojo traffic. Useful to spot tools that keep failing or are slow"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithOpenWorldHintAnnotation(false),
+		mcp.WithString("format", mcp.Enum(formatText, formatJSON), mcp.Description("Output format: text or json (default: server setting, usually text)")),
+	)
+}
+
+// pinFindingsTool defines pin_findings
+func pinFindingsTool() mcp.Tool {
+	return mcp.NewTool(toolPinFindings,
+		mcp.WithDescription("Pin findings to this conversation's working set, so later requests like \"close the first three of those\" can refer to them by position. The working set lasts as long as the MCP session; the result lists every pinned finding by position"),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithIdempotentHintAnnotation(true),
+		mcp.WithOpenWorldHintAnnotation(true),
+		mcp.WithArray("finding_ids", mcp.MinItems(1), mcp.MaxItems(maxPinnedFindings), mcp.WithNumberItems(integer(), mcp.Min(1)), mcp.Description("IDs of the findings to pin, in the order they should be numbered (or pass saved_result)")),
+		withSavedResultArgument(),
+		mcp.WithBoolean("replace", mcp.Description("Replace the working set instead of adding to it (default: false)")),
+		mcp.WithString("format", mcp.Enum(formatText, formatJSON), mcp.Description("Output format: text or json (default: server setting, usually text)")),
+		withTimeoutArgument(),
+	)
+}
+
+// pinnedFindingsTool defines get_pinned_findings
+func pinnedFindingsTool() mcp.Tool {
+	return mcp.NewTool(toolGetPinned,
+		mcp.WithDescription("List the findings pinned to this conversation's working set by position, with their severity, title and status as of when they were pinned. Use it to resolve references like \"the second one\" to finding IDs"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithOpenWorldHintAnnotation(false),
+		mcp.WithString("format", mcp.Enum(formatText, formatJSON), mcp.Description("Output format: text or json (default: server setting, usually text)")),
+	)
+}
+
+// clearPinsTool defines clear_pins
+func clearPinsTool() mcp.Tool {
+	return mcp.NewTool(toolClearPins,
+		mcp.WithDescription("Unpin findings from this conversation's working set, or empty it. The remaining findings are renumbered; DefectDojo is not changed"),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithIdempotentHintAnnotation(true),
+		mcp.WithOpenWorldHintAnnotation(false),
+		mcp.WithArray("finding_ids", mcp.WithNumberItems(integer(), mcp.Min(1)), mcp.Description("IDs of the findings to unpin (omit to unpin every finding)")),
+		mcp.WithString("format", mcp.Enum(formatText, formatJSON), mcp.Description("Output format: text or json (default: server setting, usually text)")),
+	)
+}
+
+// saveQueryResultTool defines save_query_result. Its filters share the schema
+// of get_defectdojo_findings.
+func saveQueryResultTool() mcp.Tool {
+	tool := mcp.NewTool(toolSaveQueryResult,
+		mcp.WithDescription("Run a findings query and save the matching findings under a name in this session, as the resource defectdojo://session/result/{name}. Pass the name as saved_result to add_note_to_findings or pin_findings instead of repeating hundreds of finding IDs"),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithIdempotentHintAnnotation(true),
+		mcp.WithOpenWorldHintAnnotation(true),
+		mcp.WithString("name", mcp.Required(), mcp.MinLength(1), mcp.MaxLength(64), mcp.Description("Name of the saved result: letters, digits, '.', '_' or '-'. Saving under an existing name replaces that result")),
+		mcp.WithBoolean("active", mcp.Description("Filter by active status: true = open findings, false = closed findings (default: open findings)")),
+		mcp.WithNumber("max_findings", integer(), mcp.Min(1), mcp.Max(maxSavedResultFindings), mcp.Description(fmt.Sprintf("Save at most this many findings, in query order (default: %d)", maxSavedResultFindings))),
+		withTimeoutArgument(),
+	)
+	findings := findingsTool().InputSchema.Properties
+	for _, name := range savedResultFilters() {
+		if _, own := tool.InputSchema.Properties[name]; !own {
+			tool.InputSchema.Properties[name] = findings[name]
+		}
+	}
+	return tool
+}
+
+// exportFindingsTool defines export_findings
+func exportFindingsTool() mcp.Tool {
+	tool := mcp.NewTool(toolExportFindings,
+		mcp.WithDescription(fmt.Sprintf("Export every finding matching a query, up to %d, as JSON split into numbered chunk resources (defectdojo://session/export/{name}/{chunk}) of this session. Returns links to the chunks rather than the findings, so large exports fit clients' message size limits; read them one at a time", maxExportFindings)),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithIdempotentHintAnnotation(true),
+		mcp.WithOpenWorldHintAnnotation(true),
+		mcp.WithString("name", mcp.Required(), mcp.MinLength(1), mcp.MaxLength(64), mcp.Description("Name of the export: letters, digits, '.', '_' or '-'. Exporting under an existing name replaces that export")),
+		mcp.WithBoolean("active", mcp.Description("Filter by active status: true = open findings, false = closed findings (default: open findings)")),
+		mcp.WithNumber("chunk_size", integer(), mcp.Min(1), mcp.Max(maxExportChunkSize), mcp.Description("Findings per chunk (default: server setting, usually 1000)")),
+		mcp.WithNumber("max_findings", integer(), mcp.Min(1), mcp.Max(maxExportFindings), mcp.Description(fmt.Sprintf("Export at most this many findings, in query order (default: %d)", maxExportFindings))),
+		withTimeoutArgument(),
+	)
+	findings := findingsTool().InputSchema.Properties
+	for _, name := range savedResultFilters() {
+		if _, own := tool.InputSchema.Properties[name]; !own {
+			tool.InputSchema.Properties[name] = findings[name]
+		}
+	}
+	return tool
+}
@@ -0,0 +1,95 @@
+package mcpserver
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+func TestToolDefinitions(t *testing.T) {
+	for _, tool := range ToolDefinitions() {
+		t.Run(tool.Name, func(t *testing.T) {
+			if tool.Description == "" {
+				t.Error("missing description")
+			}
+			if tool.InputSchema.Type != "object" {
+				t.Errorf("expected object input schema, got %q", tool.InputSchema.Type)
+			}
+			if tool.Annotations.ReadOnlyHint == nil {
+				t.Error("missing read-only annotation")
+			}
+			for name, raw := range tool.InputSchema.Properties {
+				property, ok := raw.(map[string]any)
+				if !ok {
+					t.Fatalf("property %s has no schema", name)
+				}
+				if property["type"] == nil {
+					t.Errorf("property %s has no type", name)
+				}
+				if property["description"] == nil {
+					t.Errorf("property %s has no description", name)
+				}
+			}
+			for _, name := range tool.InputSchema.Required {
+				if _, ok := tool.InputSchema.Properties[name]; !ok {
+					t.Errorf("required argument %s is not declared", name)
+				}
+			}
+		})
+	}
+
+	findings := findingsTool()
+	for _, name := range []string{"severity", "min_severity"} {
+		enum, _ := findings.InputSchema.Properties[name].(map[string]any)["enum"].([]string)
+		if !slices.Equal(enum, types.ValidSeverities()) {
+			t.Errorf("%s enum = %v, want %v", name, enum, types.ValidSeverities())
+		}
+	}
+	if !slices.Contains(findingDetailTool().InputSchema.Required, "finding_id") {
+		t.Error("get_finding_detail must require finding_id")
+	}
+	if required := markFalsePositiveTool().InputSchema.Required; !slices.Contains(required, "finding_id") || !slices.Contains(required, "justification") {
+		t.Errorf("mark_finding_false_positive required = %v", required)
+	}
+}
+
+func TestRegisteredToolsMatchDefinitions(t *testing.T) {
+	s := newServer(&Config{}, &MockDefectDojoClient{})
+
+	c, err := client.NewInProcessClient(s.GetMCPServer())
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	ctx := context.Background()
+	if err := c.Start(ctx); err != nil {
+		t.Fatalf("failed to start client: %v", err)
+	}
+	if _, err := c.Initialize(ctx, mcp.InitializeRequest{}); err != nil {
+		t.Fatalf("failed to initialize: %v", err)
+	}
+
+	listed, err := c.ListTools(ctx, mcp.ListToolsRequest{})
+	if err != nil {
+		t.Fatalf("failed to list tools: %v", err)
+	}
+
+	registered := map[string]mcp.Tool{}
+	for _, tool := range listed.Tools {
+		registered[tool.Name] = tool
+	}
+	for _, definition := range ToolDefinitions() {
+		tool, ok := registered[definition.Name]
+		if !ok {
+			t.Errorf("tool %s is defined but not registered", definition.Name)
+			continue
+		}
+		if len(tool.InputSchema.Properties) != len(definition.InputSchema.Properties) {
+			t.Errorf("tool %s exposes %d arguments, definition has %d", definition.Name, len(tool.InputSchema.Properties), len(definition.InputSchema.Properties))
+		}
+	}
+}
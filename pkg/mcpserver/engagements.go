@@ -0,0 +1,239 @@
+package mcpserver
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/brduru/mcp-defect-dojo/internal/refcache"
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+// Engagement overview sizing
+const (
+	engagementPageSize     = 100 // Engagements per query page
+	maxOverviewEngagements = 500 // Active engagements one overview may cover
+	defaultUpcomingDays    = 30  // How far ahead upcoming engagements are listed by default
+	maxUpcomingDays        = 365
+)
+
+// Engagement statuses that end an engagement even while it is still active
+var closedEngagementStatuses = []string{"Completed", "Cancelled"}
+
+// engagementOverview is one engagement's entry in the overview
+type engagementOverview struct {
+	ID             int            `json:"id"`
+	Name           string         `json:"name"`
+	URL            string         `json:"url,omitempty"` // DefectDojo UI page of the engagement
+	ProductID      int            `json:"product_id"`
+	Product        string         `json:"product,omitempty"`
+	Status         string         `json:"status,omitempty"`
+	Type           string         `json:"engagement_type,omitempty"`
+	TargetStart    string         `json:"target_start,omitempty"`
+	TargetEnd      string         `json:"target_end,omitempty"`
+	Overdue        bool           `json:"overdue"` // In progress past its target end date
+	Lead           string         `json:"lead,omitempty"`
+	OpenFindings   int            `json:"open_findings"`
+	OpenBySeverity severityCounts `json:"open_by_severity"`
+	Error          string         `json:"error,omitempty"` // Why the findings could not be counted
+}
+
+// listActiveEngagements pages through the active engagements, with their leads
+func (s *Server) listActiveEngagements(ctx context.Context, product *int) ([]types.Engagement, map[int]types.User, int, error) {
+	active := true
+	filter := types.EngagementsFilter{Product: product, Active: &active, Limit: engagementPageSize, Prefetch: []string{types.PrefetchLead}}
+	var engagements []types.Engagement
+	leads := map[int]types.User{}
+	for {
+		if err := budgetFrom(ctx).spendPage(); err != nil {
+			return nil, nil, 0, err
+		}
+		page, err := s.ddClient.ListEngagements(ctx, filter)
+		if err != nil {
+			return nil, nil, 0, err
+		}
+		engagements = append(engagements, page.Results...)
+		if page.Prefetch != nil {
+			for id, user := range page.Prefetch.Leads {
+				leads[id] = user
+			}
+		}
+		filter.Offset += len(page.Results)
+		if page.Next == nil || len(page.Results) == 0 || filter.Offset >= maxOverviewEngagements {
+			return engagements, leads, page.Count, nil
+		}
+	}
+}
+
+// countEngagementFindings counts an engagement's open findings by severity
+func (s *Server) countEngagementFindings(ctx context.Context, entry *engagementOverview) {
+	active := true
+	for _, severity := range types.ValidSeverities() {
+		response, err := s.findingsPage(ctx, types.FindingsFilter{Engagement: &entry.ID, Active: &active, Severity: severity, Limit: 1})
+		if err != nil {
+			entry.Error = fmt.Sprintf("%s findings: %v", severity, err)
+			return
+		}
+		entry.OpenBySeverity.add(severity, response.Count)
+		entry.OpenFindings += response.Count
+	}
+}
+
+// engagementOverviewEntry describes one engagement; the product name comes
+// from the reference cache and the lead from the prefetched users.
+func (s *Server) engagementOverviewEntry(ctx context.Context, engagement types.Engagement, leads map[int]types.User, today string) engagementOverview {
+	entry := engagementOverview{
+		ID:          engagement.ID,
+		Name:        engagement.Name,
+		URL:         s.links.engagement(engagement.ID),
+		ProductID:   engagement.Product,
+		Status:      engagement.Status,
+		Type:        engagement.EngagementType,
+		TargetStart: engagement.TargetStart,
+		TargetEnd:   engagement.TargetEnd,
+		Overdue:     engagement.TargetEnd != "" && engagement.TargetEnd < today,
+	}
+	if lead, ok := leads[engagement.Lead]; ok {
+		entry.Lead = lead.DisplayName()
+	} else if engagement.Lead != 0 {
+		entry.Lead = fmt.Sprintf("user %d", engagement.Lead)
+	}
+	product, err := refcache.Get(s.refs, refKey(refProduct, engagement.Product), func() (*types.Product, error) {
+		return s.ddClient.GetProduct(ctx, engagement.Product)
+	})
+	if err == nil {
+		entry.Product = product.Name
+	}
+	s.countEngagementFindings(ctx, &entry)
+	return entry
+}
+
+// getEngagementOverview handles get_engagement_overview: the engagements in
+// progress and those starting within the next days, with their open findings
+func (s *Server) getEngagementOverview(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	days := request.GetInt("days_ahead", defaultUpcomingDays)
+	if days < 0 || days > maxUpcomingDays {
+		return nil, fmt.Errorf("invalid days_ahead %d: must be between 0 and %d", days, maxUpcomingDays)
+	}
+	var product *int
+	if id := request.GetInt("product", 0); id > 0 {
+		product = &id
+	}
+
+	now := time.Now().UTC()
+	today, horizon := now.Format(time.DateOnly), now.AddDate(0, 0, days).Format(time.DateOnly)
+	engagements, leads, total, err := s.listActiveEngagements(ctx, product)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving engagements: %w", err)
+	}
+
+	var selected []types.Engagement
+	for _, engagement := range engagements {
+		if slices.ContainsFunc(closedEngagementStatuses, func(status string) bool { return strings.EqualFold(status, engagement.Status) }) {
+			continue
+		}
+		if engagement.TargetStart > horizon {
+			continue
+		}
+		selected = append(selected, engagement)
+	}
+
+	entries := make([]engagementOverview, len(selected))
+	s.workers.run(ctx, operationEngagementStats, len(selected), func(i int) {
+		entries[i] = s.engagementOverviewEntry(ctx, selected[i], leads, today)
+	})
+
+	inProgress, upcoming := []engagementOverview{}, []engagementOverview{}
+	for _, entry := range entries {
+		if entry.TargetStart > today {
+			upcoming = append(upcoming, entry)
+		} else {
+			inProgress = append(inProgress, entry)
+		}
+	}
+
+	var notes []string
+	if total > len(engagements) {
+		notes = append(notes, fmt.Sprintf("Only the first %d of %d active engagements were considered.", len(engagements), total))
+	}
+
+	if s.outputFormat(request) == formatJSON {
+		output, err := marshalOutput(struct {
+			Date       string               `json:"date"`
+			DaysAhead  int                  `json:"days_ahead"`
+			InProgress []engagementOverview `json:"in_progress"`
+			Upcoming   []engagementOverview `json:"upcoming"`
+			Notes      []string             `json:"notes,omitempty"`
+		}{today, days, inProgress, upcoming, notes})
+		if err != nil {
+			return nil, err
+		}
+		return mcp.NewToolResultText(output), nil
+	}
+
+	var result strings.Builder
+	fmt.Fprintf(&result, "Engagements on %s: %d in progress, %d starting within %d days\n", today, len(inProgress), len(upcoming), days)
+	writeSection := func(title string, entries []engagementOverview) {
+		if len(entries) == 0 {
+			return
+		}
+		fmt.Fprintf(&result, "\n%s\n", title)
+		for _, entry := range entries {
+			result.WriteString(formatEngagementOverview(entry))
+		}
+	}
+	writeSection("In progress:", inProgress)
+	writeSection("Upcoming:", upcoming)
+	for _, note := range notes {
+		fmt.Fprintf(&result, "\n%s\n", note)
+	}
+	return mcp.NewToolResultText(result.String()), nil
+}
+
+// formatEngagementOverview renders one engagement as a few indented lines
+func formatEngagementOverview(entry engagementOverview) string {
+	result := fmt.Sprintf("\n- %s (ID: %d)", entry.Name, entry.ID)
+	if entry.Product != "" {
+		result += " — " + entry.Product
+	}
+	result += "\n"
+
+	var details []string
+	for _, detail := range []string{entry.Type, entry.Status} {
+		if detail != "" {
+			details = append(details, detail)
+		}
+	}
+	if entry.TargetStart != "" || entry.TargetEnd != "" {
+		dates := fmt.Sprintf("%s to %s", cmp.Or(entry.TargetStart, "?"), cmp.Or(entry.TargetEnd, "?"))
+		if entry.Overdue {
+			dates += " (overdue)"
+		}
+		details = append(details, dates)
+	}
+	if len(details) > 0 {
+		result += "  " + strings.Join(details, ", ") + "\n"
+	}
+	if entry.Lead != "" {
+		result += "  Lead: " + entry.Lead + "\n"
+	}
+
+	if entry.Error != "" {
+		result += "  Open findings: could not be counted: " + entry.Error + "\n"
+	} else {
+		result += fmt.Sprintf("  Open findings: %d", entry.OpenFindings)
+		if counts := entry.OpenBySeverity.String(); counts != "" {
+			result += " (" + counts + ")"
+		}
+		result += "\n"
+	}
+	if entry.URL != "" {
+		result += "  " + entry.URL + "\n"
+	}
+	return result
+}
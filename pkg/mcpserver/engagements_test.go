@@ -0,0 +1,83 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+func TestGetEngagementOverview(t *testing.T) {
+	day := func(offset int) string { return time.Now().UTC().AddDate(0, 0, offset).Format(time.DateOnly) }
+	mock := &MockDefectDojoClient{
+		ListEngagementsFunc: func(ctx context.Context, filter types.EngagementsFilter) (*types.EngagementsResponse, error) {
+			if filter.Active == nil || !*filter.Active {
+				t.Error("expected only active engagements to be listed")
+			}
+			return &types.EngagementsResponse{Count: 5, Results: []types.Engagement{
+				{ID: 1, Name: "Annual Pentest", Product: 1, Status: "In Progress", EngagementType: "Interactive", TargetStart: day(-60), TargetEnd: day(-5), Lead: 3, Active: true},
+				{ID: 2, Name: "CI Pipeline", Product: 2, Status: "In Progress", TargetStart: day(-100), TargetEnd: day(200), Lead: 9, Active: true},
+				{ID: 3, Name: "Red Team", Product: 1, Status: "Not Started", TargetStart: day(10), TargetEnd: day(20), Active: true},
+				{ID: 4, Name: "Next Year", Product: 1, Status: "Not Started", TargetStart: day(120), Active: true},
+				{ID: 5, Name: "Wrapped Up", Product: 1, Status: "Completed", TargetStart: day(-30), Active: true},
+			}, Prefetch: &types.Prefetched{Leads: map[int]types.User{3: {ID: 3, Username: "dana", FirstName: "Dana", LastName: "Whitfield"}}}}, nil
+		},
+		GetFindingsFunc: func(ctx context.Context, filter types.FindingsFilter) (*types.FindingsResponse, error) {
+			if filter.Engagement == nil || filter.Active == nil || !*filter.Active {
+				t.Errorf("expected open findings of one engagement to be counted, got %+v", filter)
+			}
+			if *filter.Engagement == 2 {
+				return nil, errors.New("timeout")
+			}
+			count := 0
+			switch filter.Severity {
+			case types.SeverityCritical:
+				count = 1
+			case types.SeverityLow:
+				count = 4
+			}
+			return &types.FindingsResponse{Count: count}, nil
+		},
+	}
+	s := newServer(&Config{}, mock)
+
+	result, err := callTool(t, s, "get_engagement_overview", map[string]any{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text := resultText(result)
+	for _, want := range []string{
+		"2 in progress, 1 starting within 30 days",
+		"- Annual Pentest (ID: 1) — Mock Product", "(overdue)", "Lead: Dana Whitfield (dana)", "Open findings: 5 (1 critical, 4 low)",
+		"Lead: user 9", "Open findings: could not be counted:",
+		"Upcoming:\n\n- Red Team (ID: 3)",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in:\n%s", want, text)
+		}
+	}
+	for _, unwanted := range []string{"Next Year", "Wrapped Up"} {
+		if strings.Contains(text, unwanted) {
+			t.Errorf("did not expect %q in:\n%s", unwanted, text)
+		}
+	}
+
+	result, err = callTool(t, s, "get_engagement_overview", map[string]any{"days_ahead": 365, "format": "json"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var output struct {
+		InProgress []engagementOverview `json:"in_progress"`
+		Upcoming   []engagementOverview `json:"upcoming"`
+	}
+	if err := json.Unmarshal([]byte(resultText(result)), &output); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(output.InProgress) != 2 || !output.InProgress[0].Overdue || output.InProgress[0].OpenBySeverity.Critical != 1 || len(output.Upcoming) != 2 {
+		t.Errorf("unexpected JSON output: %+v", output)
+	}
+}
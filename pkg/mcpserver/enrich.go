@@ -0,0 +1,143 @@
+package mcpserver
+
+import (
+	"context"
+
+	"github.com/brduru/mcp-defect-dojo/internal/refcache"
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+// findingContext names the product and engagement a finding's test belongs to
+type findingContext struct {
+	Product     string `json:"product,omitempty"`
+	Engagement  string `json:"engagement,omitempty"`
+	Test        string `json:"test,omitempty"`
+	TestType    string `json:"test_type,omitempty"`
+	Environment string `json:"environment,omitempty"` // Development environment the test ran in, e.g. Production
+}
+
+// String renders the context as "Product: X / Engagement: Y / Environment: Z",
+// or "" if nothing was resolved
+func (c findingContext) String() string {
+	var result string
+	if c.Product != "" {
+		result = "Product: " + c.Product
+	}
+	if c.Engagement != "" {
+		if result != "" {
+			result += " / "
+		}
+		result += "Engagement: " + c.Engagement
+	}
+	if c.Environment != "" {
+		if result != "" {
+			result += " / "
+		}
+		result += "Environment: " + c.Environment
+	}
+	return result
+}
+
+// resolveContexts resolves the test → engagement → product chain for every
+// distinct test in findings, keyed by test ID. Lookups go through the
+// reference cache, so findings sharing a test cost one request per distinct
+// object per cache TTL; tests prefetched with the findings (prefetched may be
+// nil) cost none, and findings carrying related fields need no lookups. Lookups that fail (missing
+// permissions, deleted objects) leave the remaining names empty rather than
+// failing the tool call, and are recorded in partial (which may be nil).
+func (s *Server) resolveContexts(ctx context.Context, findings []types.Finding, prefetched *types.Prefetched, partial *partialResults) map[int]findingContext {
+	contexts := map[int]findingContext{}
+	for _, finding := range findings {
+		if finding.Test == 0 {
+			continue
+		}
+		if _, done := contexts[finding.Test]; done {
+			continue
+		}
+		if names, ok := relatedContext(finding.RelatedFields); ok {
+			contexts[finding.Test] = names
+			continue
+		}
+		contexts[finding.Test] = s.resolveContext(ctx, finding.Test, prefetched, partial)
+	}
+	return contexts
+}
+
+// relatedContext reads the names DefectDojo nested in a finding's related
+// fields. It reports false unless the product and engagement are both there,
+// leaving the finding to the lookups.
+func relatedContext(related *types.RelatedFields) (findingContext, bool) {
+	if related == nil || related.Test == nil || related.Test.Engagement == nil || related.Test.Engagement.Product == nil {
+		return findingContext{}, false
+	}
+	test := related.Test
+	names := findingContext{
+		Product:    test.Engagement.Product.Name,
+		Engagement: test.Engagement.Name,
+		Test:       test.Title,
+	}
+	if test.TestType != nil {
+		names.TestType = test.TestType.Name
+	}
+	if test.Environment != nil {
+		names.Environment = test.Environment.Name
+	}
+	return names, true
+}
+
+// resolveContext resolves the names for a single test
+func (s *Server) resolveContext(ctx context.Context, testID int, prefetched *types.Prefetched, partial *partialResults) findingContext {
+	var result findingContext
+
+	test, err := refcache.Get(s.refs, refKey(refTest, testID), func() (*types.Test, error) {
+		if prefetched != nil {
+			if test, ok := prefetched.Tests[testID]; ok {
+				return &test, nil
+			}
+		}
+		return s.ddClient.GetTest(ctx, testID)
+	})
+	if err != nil {
+		partial.warnf("names of test %d unavailable: %v", testID, err)
+		return result
+	}
+	result.Test = test.Title
+
+	if test.TestType != 0 {
+		testType, err := refcache.Get(s.refs, refKey(refTestType, test.TestType), func() (*types.TestType, error) {
+			return s.ddClient.GetTestType(ctx, test.TestType)
+		})
+		if err != nil {
+			partial.warnf("scanner of test %d unavailable: %v", testID, err)
+		} else {
+			result.TestType = testType.Name
+		}
+	}
+	if test.Environment != 0 {
+		if name, err := s.environmentName(ctx, test.Environment); err != nil {
+			partial.warnf("environment of test %d unavailable: %v", testID, err)
+		} else {
+			result.Environment = name
+		}
+	}
+
+	engagement, err := refcache.Get(s.refs, refKey(refEngagement, test.Engagement), func() (*types.Engagement, error) {
+		return s.ddClient.GetEngagement(ctx, test.Engagement)
+	})
+	if err != nil {
+		partial.warnf("engagement %d unavailable: %v", test.Engagement, err)
+		return result
+	}
+	result.Engagement = engagement.Name
+
+	product, err := refcache.Get(s.refs, refKey(refProduct, engagement.Product), func() (*types.Product, error) {
+		return s.ddClient.GetProduct(ctx, engagement.Product)
+	})
+	if err != nil {
+		partial.warnf("product %d unavailable: %v", engagement.Product, err)
+		return result
+	}
+	result.Product = product.Name
+
+	return result
+}
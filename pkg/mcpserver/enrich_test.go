@@ -0,0 +1,220 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/brduru/mcp-defect-dojo/internal/defectdojo"
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+func TestResolveContextsCachesLookups(t *testing.T) {
+	calls := map[string]int{}
+	mock := &MockDefectDojoClient{
+		GetTestFunc: func(ctx context.Context, testID int) (*types.Test, error) {
+			calls["test"]++
+			if testID == 3 {
+				return nil, fmt.Errorf("forbidden")
+			}
+			return &types.Test{ID: testID, Title: "ZAP Scan", Engagement: 20}, nil
+		},
+		GetEngagementFunc: func(ctx context.Context, engagementID int) (*types.Engagement, error) {
+			calls["engagement"]++
+			return &types.Engagement{ID: engagementID, Name: "Q3 Pentest", Product: 7}, nil
+		},
+		GetProductFunc: func(ctx context.Context, productID int) (*types.Product, error) {
+			calls["product"]++
+			return &types.Product{ID: productID, Name: "Payments API"}, nil
+		},
+	}
+	s := newServer(&Config{}, mock)
+
+	findings := []types.Finding{{ID: 1, Test: 1}, {ID: 2, Test: 1}, {ID: 3, Test: 2}, {ID: 4, Test: 3}}
+	contexts := s.resolveContexts(context.Background(), findings, nil, nil)
+
+	if got := contexts[1].String(); got != "Product: Payments API / Engagement: Q3 Pentest" {
+		t.Errorf("unexpected context for test 1: %q", got)
+	}
+	if contexts[3] != (findingContext{}) {
+		t.Errorf("expected empty context when the test lookup fails, got %+v", contexts[3])
+	}
+	if calls["test"] != 3 || calls["engagement"] != 1 || calls["product"] != 1 {
+		t.Errorf("expected one lookup per distinct object, got %v", calls)
+	}
+
+	// A second page reuses the cache
+	s.resolveContexts(context.Background(), findings[:3], nil, nil)
+	if calls["test"] != 3 {
+		t.Errorf("expected cached tests on second resolve, got %d lookups", calls["test"])
+	}
+}
+
+func TestIncludeContextArgument(t *testing.T) {
+	s := newServer(&Config{}, &MockDefectDojoClient{})
+
+	result, err := callTool(t, s, "get_defectdojo_findings", map[string]any{"include_context": true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := resultText(result); !strings.Contains(text, "Product: Mock Product / Engagement: Mock Engagement") {
+		t.Errorf("expected product and engagement names, got:\n%s", text)
+	}
+
+	result, err = callTool(t, s, "get_finding_detail", map[string]any{"finding_id": 1, "include_context": true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := resultText(result); !strings.Contains(text, "Product: Mock Product\nEngagement: Mock Engagement\n") {
+		t.Errorf("expected names in finding detail, got:\n%s", text)
+	}
+
+	result, err = callTool(t, s, "get_defectdojo_findings", map[string]any{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := resultText(result); strings.Contains(text, "Mock Product") {
+		t.Errorf("expected no names without include_context, got:\n%s", text)
+	}
+}
+
+func TestIncludeContextPrefetchesTests(t *testing.T) {
+	var prefetch []string
+	testLookups := 0
+	mock := &MockDefectDojoClient{
+		GetFindingsFunc: func(ctx context.Context, filter types.FindingsFilter) (*types.FindingsResponse, error) {
+			prefetch = filter.Prefetch
+			return &types.FindingsResponse{
+				Count:    1,
+				Results:  []types.Finding{{ID: 1, Title: "SQL Injection", Severity: "High", Test: 42}},
+				Prefetch: &types.Prefetched{Tests: map[int]types.Test{42: {ID: 42, Title: "Nightly ZAP", Engagement: 20}}},
+			}, nil
+		},
+		GetFindingDetailPrefetchFunc: func(ctx context.Context, findingID int, names []string) (*types.FindingDetail, error) {
+			prefetch = names
+			return &types.FindingDetail{
+				Finding:  types.Finding{ID: findingID, Title: "SQL Injection", Severity: "High", Test: 43},
+				Prefetch: &types.Prefetched{Tests: map[int]types.Test{43: {ID: 43, Title: "Nightly ZAP", Engagement: 20}}},
+			}, nil
+		},
+		GetTestFunc: func(ctx context.Context, testID int) (*types.Test, error) {
+			testLookups++
+			return &types.Test{ID: testID, Engagement: 20}, nil
+		},
+	}
+	s := newServer(&Config{}, mock)
+
+	for _, call := range []struct {
+		tool string
+		args map[string]any
+	}{
+		{"get_defectdojo_findings", map[string]any{"include_context": true}},
+		{"get_finding_detail", map[string]any{"finding_id": 1, "include_context": true}},
+	} {
+		result, err := callTool(t, s, call.tool, call.args)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", call.tool, err)
+		}
+		if !strings.Contains(resultText(result), "Mock Engagement") {
+			t.Errorf("%s: expected resolved names, got:\n%s", call.tool, resultText(result))
+		}
+		if len(prefetch) != 1 || prefetch[0] != types.PrefetchTest {
+			t.Errorf("%s: prefetch = %v, want [test]", call.tool, prefetch)
+		}
+	}
+	if testLookups != 0 {
+		t.Errorf("expected prefetched tests to save %d test lookups", testLookups)
+	}
+}
+
+func TestIncludeContextWarnings(t *testing.T) {
+	mock := &MockDefectDojoClient{
+		GetEngagementFunc: func(ctx context.Context, engagementID int) (*types.Engagement, error) {
+			return nil, fmt.Errorf("500 Internal Server Error")
+		},
+	}
+	s := newServer(&Config{}, mock)
+
+	result, err := callTool(t, s, toolGetFindings, map[string]any{"include_context": true})
+	if err != nil {
+		t.Fatalf("expected the findings despite the failed lookup, got %v", err)
+	}
+	if text := resultText(result); !strings.Contains(text, "Partial results") || !strings.Contains(text, "unavailable: 500 Internal Server Error") {
+		t.Errorf("expected a warnings section, got:\n%s", text)
+	}
+
+	result, err = callTool(t, s, toolGetFindings, map[string]any{"include_context": true, "format": "json"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var page struct {
+		Results  []json.RawMessage `json:"results"`
+		Warnings []string          `json:"warnings"`
+	}
+	if err := json.Unmarshal([]byte(resultText(result)), &page); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(page.Results) == 0 || len(page.Warnings) != 1 {
+		t.Errorf("expected findings with one warning, got %d findings and %v", len(page.Results), page.Warnings)
+	}
+}
+
+func TestIncludeContextRelatedFields(t *testing.T) {
+	var requested []bool
+	related := &types.RelatedFields{
+		Test: &types.RelatedTest{
+			ID: 42, Title: "Nightly ZAP", TestType: &types.TestType{ID: 3, Name: "ZAP Scan"},
+			Engagement:  &types.RelatedEngagement{ID: 20, Name: "Q3 Pentest", Product: &types.RelatedProduct{ID: 1, Name: "Payments API"}},
+			Environment: &types.DevelopmentEnvironment{ID: 3, Name: "Production"},
+		},
+		Reporter: &types.RelatedUser{ID: 1, Username: "dana"},
+	}
+	supported := true
+	mock := &MockDefectDojoClient{
+		GetFindingsFunc: func(ctx context.Context, filter types.FindingsFilter) (*types.FindingsResponse, error) {
+			requested = append(requested, filter.RelatedFields)
+			finding := types.Finding{ID: 1, Title: "SQL Injection", Severity: "High", Test: 42, Reporter: 1}
+			if filter.RelatedFields {
+				finding.RelatedFields = related
+			}
+			return &types.FindingsResponse{Count: 1, Results: []types.Finding{finding}}, nil
+		},
+		GetTestFunc: func(ctx context.Context, testID int) (*types.Test, error) {
+			if supported {
+				t.Errorf("unexpected lookup of test %d", testID)
+			}
+			return &types.Test{ID: testID, Engagement: 20}, nil
+		},
+		SupportsFunc: func(ctx context.Context, feature defectdojo.Feature) error {
+			if feature == defectdojo.FeatureRelatedFields && !supported {
+				return &defectdojo.UnsupportedFeatureError{Feature: feature, Required: "2.0.0", Detected: "1.15.0"}
+			}
+			return nil
+		},
+	}
+	s := newServer(&Config{}, mock)
+
+	result, err := callTool(t, s, "get_defectdojo_findings", map[string]any{"include_context": true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text := resultText(result)
+	if !strings.Contains(text, "Product: Payments API / Engagement: Q3 Pentest / Environment: Production") || !strings.Contains(text, "Reporter: dana") {
+		t.Errorf("expected the related names, got:\n%s", text)
+	}
+
+	supported = false
+	result, err = callTool(t, s, "get_defectdojo_findings", map[string]any{"include_context": true, "limit": 5})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := resultText(result); !strings.Contains(text, "Product: Mock Product / Engagement: Mock Engagement") {
+		t.Errorf("expected looked up names on older releases, got:\n%s", text)
+	}
+	if !slices.Equal(requested, []bool{true, false}) {
+		t.Errorf("related_fields requested = %v, want [true false]", requested)
+	}
+}
@@ -0,0 +1,78 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/brduru/mcp-defect-dojo/internal/refcache"
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+// maxEnvironments bounds the development environments read; instances
+// rarely define more than a handful
+const maxEnvironments = 100
+
+// developmentEnvironments lists the instance's development environments
+// (Development, Staging, Production...), kept in the reference cache
+func (s *Server) developmentEnvironments(ctx context.Context) ([]types.DevelopmentEnvironment, error) {
+	return refcache.Get(s.refs, refEnvironment, func() ([]types.DevelopmentEnvironment, error) {
+		response, err := s.ddClient.ListDevelopmentEnvironments(ctx, maxEnvironments, 0)
+		if err != nil {
+			return nil, fmt.Errorf("error listing development environments: %w", err)
+		}
+		return response.Results, nil
+	})
+}
+
+// resolveEnvironment finds a development environment by ID or by
+// case-insensitive name
+func (s *Server) resolveEnvironment(ctx context.Context, name string) (*types.DevelopmentEnvironment, error) {
+	environments, err := s.developmentEnvironments(ctx)
+	if err != nil {
+		return nil, err
+	}
+	name = strings.TrimSpace(name)
+	id, _ := strconv.Atoi(name)
+	names := make([]string, 0, len(environments))
+	for _, environment := range environments {
+		if environment.ID == id || strings.EqualFold(environment.Name, name) {
+			return &environment, nil
+		}
+		names = append(names, environment.Name)
+	}
+	return nil, fmt.Errorf("unknown development environment %q: use one of %s", name, strings.Join(names, ", "))
+}
+
+// environmentName names a development environment by ID, or "" when it is
+// unknown
+func (s *Server) environmentName(ctx context.Context, id int) (string, error) {
+	environments, err := s.developmentEnvironments(ctx)
+	if err != nil {
+		return "", err
+	}
+	for _, environment := range environments {
+		if environment.ID == id {
+			return environment.Name, nil
+		}
+	}
+	return "", nil
+}
+
+// environmentFilter narrows a findings filter to the findings of tests run in
+// the development environment named by the environment argument, if any
+func (s *Server) environmentFilter(ctx context.Context, request mcp.CallToolRequest, filter *types.FindingsFilter) error {
+	name := request.GetString("environment", "")
+	if name == "" {
+		return nil
+	}
+	environment, err := s.resolveEnvironment(ctx, name)
+	if err != nil {
+		return fmt.Errorf("invalid environment: %w", err)
+	}
+	filter.Environment = &environment.ID
+	return nil
+}
@@ -0,0 +1,70 @@
+package mcpserver
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/brduru/mcp-defect-dojo/internal/defectdojo"
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+func TestEnvironmentFilter(t *testing.T) {
+	fixtures, err := defectdojo.NewFixtureClient("")
+	if err != nil {
+		t.Fatalf("NewFixtureClient() error = %v", err)
+	}
+	s := newServer(&Config{}, fixtures)
+
+	result, err := callTool(t, s, "get_defectdojo_findings", map[string]any{"environment": "production", "include_context": true, "sort_by": "id"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text := resultText(result)
+	if !strings.Contains(text, "Found 3 findings") || strings.Contains(text, "(ID: 3)") {
+		t.Errorf("expected only the active production findings 1, 2 and 5, got:\n%s", text)
+	}
+	if !strings.Contains(text, "Environment: Production") {
+		t.Errorf("expected the environment in the context, got:\n%s", text)
+	}
+
+	result, err = callTool(t, s, "get_defectdojo_findings", map[string]any{"environment": "2", "format": "json"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := resultText(result); !strings.Contains(text, `"id": 4,`) || strings.Contains(text, `"id": 1,`) {
+		t.Errorf("expected only the staging finding 4, got: %s", text)
+	}
+
+	_, err = callTool(t, s, "get_defectdojo_findings", map[string]any{"environment": "qa"})
+	if err == nil || !strings.Contains(err.Error(), `invalid environment: unknown development environment "qa": use one of Development, Staging, Production`) {
+		t.Errorf("expected an unknown environment to be reported, got %v", err)
+	}
+}
+
+func TestEnvironmentContext(t *testing.T) {
+	lists := 0
+	mock := &MockDefectDojoClient{
+		GetTestFunc: func(ctx context.Context, testID int) (*types.Test, error) {
+			return &types.Test{ID: testID, Title: "Mock Test", Engagement: 1, Environment: 2}, nil
+		},
+		ListDevelopmentEnvironmentsFunc: func(ctx context.Context, limit, offset int) (*types.DevelopmentEnvironmentsResponse, error) {
+			lists++
+			return &types.DevelopmentEnvironmentsResponse{Count: 1, Results: []types.DevelopmentEnvironment{{ID: 2, Name: "Staging"}}}, nil
+		},
+	}
+	s := newServer(&Config{}, mock)
+
+	for range 2 {
+		result, err := callTool(t, s, "get_finding_detail", map[string]any{"finding_id": 1, "include_context": true})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if text := resultText(result); !strings.Contains(text, "Environment: Staging\n") {
+			t.Errorf("expected the environment in finding detail, got:\n%s", text)
+		}
+	}
+	if lists != 1 {
+		t.Errorf("expected the environments to be listed once, got %d", lists)
+	}
+}
@@ -0,0 +1,205 @@
+package mcpserver
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// defaultEventBufferSize is how many webhook events are kept when not configured
+const defaultEventBufferSize = 100
+
+// maxWebhookBody bounds the size of an accepted webhook payload
+const maxWebhookBody = 1 << 20
+
+// eventNotification is the MCP notification method announcing a new event
+const eventNotification = "notifications/defectdojo/event"
+
+// Event is a DefectDojo webhook notification, such as a scan import adding
+// findings or an engagement being closed.
+type Event struct {
+	ID          int       `json:"id"`                    // Increasing sequence number, for since_id
+	Type        string    `json:"type"`                  // DefectDojo event, e.g. "scan_added", "engagement_closed"
+	Title       string    `json:"title,omitempty"`       // Notification title
+	Description string    `json:"description,omitempty"` // Notification text
+	URL         string    `json:"url,omitempty"`         // DefectDojo UI link
+	Product     string    `json:"product,omitempty"`     // Product name, when the event concerns one
+	Engagement  string    `json:"engagement,omitempty"`  // Engagement name, when the event concerns one
+	ReceivedAt  time.Time `json:"received_at"`
+}
+
+// webhookPayload is the subset of DefectDojo's webhook body kept on Event
+type webhookPayload struct {
+	Event       string `json:"event"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	URLUI       string `json:"url_ui"`
+	Product     struct {
+		Name string `json:"name"`
+	} `json:"product"`
+	Engagement struct {
+		Name string `json:"name"`
+	} `json:"engagement"`
+}
+
+// eventLog keeps the most recent events in arrival order
+type eventLog struct {
+	mu     sync.Mutex
+	size   int
+	nextID int
+	events []Event
+}
+
+func newEventLog(size int) *eventLog {
+	if size <= 0 {
+		size = defaultEventBufferSize
+	}
+	return &eventLog{size: size, nextID: 1}
+}
+
+// add assigns the event an ID and stores it, dropping the oldest beyond capacity
+func (l *eventLog) add(event Event) Event {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	event.ID = l.nextID
+	l.nextID++
+	l.events = append(l.events, event)
+	if len(l.events) > l.size {
+		l.events = l.events[len(l.events)-l.size:]
+	}
+	return event
+}
+
+// since returns up to limit events newer than sinceID, optionally of one type,
+// oldest first, plus the ID of the latest event received.
+func (l *eventLog) since(sinceID int, eventType string, limit int) ([]Event, int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var events []Event
+	for _, event := range l.events {
+		if event.ID <= sinceID || (eventType != "" && !strings.EqualFold(event.Type, eventType)) {
+			continue
+		}
+		if limit > 0 && len(events) == limit {
+			break
+		}
+		events = append(events, event)
+	}
+	return events, l.nextID - 1
+}
+
+// WebhookHandler returns an http.Handler receiving DefectDojo webhook
+// notifications on POST /webhook. Each notification is kept for
+// get_recent_events and announced to connected MCP clients as a
+// notifications/defectdojo/event notification.
+//
+// The event type comes from DefectDojo's X-DefectDojo-Event header. When
+// WebhookConfig.Secret is set, requests must carry it in the Authorization
+// header (optionally as "Bearer <secret>"), configured as the webhook's
+// custom header in DefectDojo.
+func (s *Server) WebhookHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /webhook", func(w http.ResponseWriter, r *http.Request) {
+		if secret := s.config.Webhook.Secret; secret != "" {
+			got := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if subtle.ConstantTimeCompare([]byte(got), []byte(secret)) != 1 {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookBody+1))
+		if err != nil || len(body) > maxWebhookBody {
+			http.Error(w, "unreadable or oversized body", http.StatusBadRequest)
+			return
+		}
+		var payload webhookPayload
+		if err := json.Unmarshal(body, &payload); err != nil {
+			http.Error(w, "invalid JSON: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		eventType := r.Header.Get("X-DefectDojo-Event")
+		if eventType == "" {
+			eventType = payload.Event
+		}
+		if eventType == "ping" {
+			// Sent by DefectDojo when the webhook is configured or tested
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		if eventType == "" {
+			eventType = "unknown"
+		}
+
+		event := s.events.add(Event{
+			Type:        eventType,
+			Title:       payload.Title,
+			Description: payload.Description,
+			URL:         payload.URLUI,
+			Product:     payload.Product.Name,
+			Engagement:  payload.Engagement.Name,
+			ReceivedAt:  time.Now().UTC(),
+		})
+		s.notifyEvent(event)
+		w.WriteHeader(http.StatusAccepted)
+	})
+	return mux
+}
+
+// notifyEvent pushes an event to every connected MCP client
+func (s *Server) notifyEvent(event Event) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("webhook: failed to encode event %d: %v", event.ID, err)
+		return
+	}
+	var params map[string]any
+	if err := json.Unmarshal(data, &params); err != nil {
+		log.Printf("webhook: failed to encode event %d: %v", event.ID, err)
+		return
+	}
+	s.mcpServer.SendNotificationToAllClients(eventNotification, params)
+}
+
+// getRecentEvents handles get_recent_events
+func (s *Server) getRecentEvents(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	sinceID := request.GetInt("since_id", 0)
+	events, latest := s.events.since(sinceID, request.GetString("type", ""), request.GetInt("limit", 20))
+
+	if latest == 0 {
+		return mcp.NewToolResultText("No DefectDojo events received yet (webhooks must be sent to this server's WEBHOOK_PORT)"), nil
+	}
+	if len(events) == 0 {
+		return mcp.NewToolResultText(fmt.Sprintf("No new events since id %d (latest id: %d)", sinceID, latest)), nil
+	}
+
+	result := fmt.Sprintf("%d events (pass since_id=%d to get only newer ones):\n", len(events), events[len(events)-1].ID)
+	for _, event := range events {
+		result += fmt.Sprintf("\n#%d [%s] %s %s\n", event.ID, event.Type, event.ReceivedAt.Format(time.RFC3339), s.sanitizedText(event.Title))
+		switch {
+		case event.Product != "" && event.Engagement != "":
+			result += fmt.Sprintf("  Product: %s / Engagement: %s\n", s.sanitizedText(event.Product), s.sanitizedText(event.Engagement))
+		case event.Product != "":
+			result += fmt.Sprintf("  Product: %s\n", s.sanitizedText(event.Product))
+		}
+		if event.Description != "" {
+			result += fmt.Sprintf("  %s\n", s.sanitizedText(strings.Join(strings.Fields(event.Description), " ")))
+		}
+		if event.URL != "" {
+			result += fmt.Sprintf("  %s\n", event.URL)
+		}
+	}
+	return mcp.NewToolResultText(result), nil
+}
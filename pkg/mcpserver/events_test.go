@@ -0,0 +1,161 @@
+package mcpserver
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const scanAddedPayload = `{
+	"description": "Event scan_added has occurred.",
+	"title": "Created/Updated 3 findings for Payments API: Q3 Pentest: ZAP Scan",
+	"url_ui": "https://dojo.example.com/test/100",
+	"product": {"id": 1, "name": "Payments API"},
+	"engagement": {"id": 10, "name": "Q3 Pentest"},
+	"findings": {"new": [{"id": 8, "title": "Open redirect", "severity": "Medium"}]}
+}`
+
+func postWebhook(t *testing.T, s *Server, event, authorization, body string) int {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(body))
+	if event != "" {
+		req.Header.Set("X-DefectDojo-Event", event)
+	}
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	rec := httptest.NewRecorder()
+	s.WebhookHandler().ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestWebhookEvents(t *testing.T) {
+	s := newServer(&Config{}, &MockDefectDojoClient{})
+
+	result, err := callTool(t, s, "get_recent_events", map[string]any{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := resultText(result); !strings.Contains(text, "No DefectDojo events received yet") {
+		t.Errorf("unexpected result: %s", text)
+	}
+
+	if code := postWebhook(t, s, "ping", "", `{}`); code != http.StatusNoContent {
+		t.Errorf("expected ping to be acknowledged, got %d", code)
+	}
+	if code := postWebhook(t, s, "scan_added", "", scanAddedPayload); code != http.StatusAccepted {
+		t.Fatalf("expected webhook to be accepted, got %d", code)
+	}
+	if code := postWebhook(t, s, "engagement_closed", "", `{"title": "Engagement closed: Q3 Pentest", "product": {"name": "Payments API"}}`); code != http.StatusAccepted {
+		t.Fatalf("expected webhook to be accepted, got %d", code)
+	}
+	if code := postWebhook(t, s, "scan_added", "", `not json`); code != http.StatusBadRequest {
+		t.Errorf("expected invalid JSON to be rejected, got %d", code)
+	}
+
+	result, err = callTool(t, s, "get_recent_events", map[string]any{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text := resultText(result)
+	for _, want := range []string{
+		"2 events (pass since_id=2",
+		"#1 [scan_added]",
+		"Created/Updated 3 findings for Payments API",
+		"Product: Payments API / Engagement: Q3 Pentest",
+		"https://dojo.example.com/test/100",
+		"#2 [engagement_closed]",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in:\n%s", want, text)
+		}
+	}
+
+	result, err = callTool(t, s, "get_recent_events", map[string]any{"since_id": 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := resultText(result); strings.Contains(text, "#1 ") || !strings.Contains(text, "#2 [engagement_closed]") {
+		t.Errorf("expected only newer events, got:\n%s", text)
+	}
+
+	result, err = callTool(t, s, "get_recent_events", map[string]any{"since_id": 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := resultText(result); !strings.Contains(text, "No new events since id 2") {
+		t.Errorf("unexpected result: %s", text)
+	}
+
+	result, err = callTool(t, s, "get_recent_events", map[string]any{"type": "scan_added"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := resultText(result); strings.Contains(text, "engagement_closed") {
+		t.Errorf("expected type filter to apply, got:\n%s", text)
+	}
+}
+
+func TestWebhookSecret(t *testing.T) {
+	s := newServer(&Config{Webhook: WebhookConfig{Secret: "s3cret"}}, &MockDefectDojoClient{})
+
+	if code := postWebhook(t, s, "scan_added", "", scanAddedPayload); code != http.StatusUnauthorized {
+		t.Errorf("expected missing secret to be rejected, got %d", code)
+	}
+	if code := postWebhook(t, s, "scan_added", "wrong", scanAddedPayload); code != http.StatusUnauthorized {
+		t.Errorf("expected wrong secret to be rejected, got %d", code)
+	}
+	if code := postWebhook(t, s, "scan_added", "Bearer s3cret", scanAddedPayload); code != http.StatusAccepted {
+		t.Errorf("expected bearer secret to be accepted, got %d", code)
+	}
+	if code := postWebhook(t, s, "scan_added", "s3cret", scanAddedPayload); code != http.StatusAccepted {
+		t.Errorf("expected raw secret to be accepted, got %d", code)
+	}
+}
+
+func TestEventLogCapacity(t *testing.T) {
+	events := newEventLog(2)
+	for range 3 {
+		events.add(Event{Type: "scan_added"})
+	}
+	kept, latest := events.since(0, "", 0)
+	if latest != 3 || len(kept) != 2 || kept[0].ID != 2 {
+		t.Errorf("expected the two newest of three events, got %+v (latest %d)", kept, latest)
+	}
+}
+
+// testSession is an MCP client session that collects notifications
+type testSession struct {
+	notifications chan mcp.JSONRPCNotification
+}
+
+func (t *testSession) Initialize()       {}
+func (t *testSession) Initialized() bool { return true }
+func (t *testSession) SessionID() string { return "test-session" }
+func (t *testSession) NotificationChannel() chan<- mcp.JSONRPCNotification {
+	return t.notifications
+}
+
+func TestWebhookNotifiesClients(t *testing.T) {
+	s := newServer(&Config{}, &MockDefectDojoClient{})
+	session := &testSession{notifications: make(chan mcp.JSONRPCNotification, 1)}
+	if err := s.GetMCPServer().RegisterSession(context.Background(), session); err != nil {
+		t.Fatalf("RegisterSession() error = %v", err)
+	}
+
+	postWebhook(t, s, "scan_added", "", scanAddedPayload)
+
+	select {
+	case notification := <-session.notifications:
+		fields := notification.Params.AdditionalFields
+		if notification.Method != eventNotification || fields["type"] != "scan_added" || fields["product"] != "Payments API" {
+			t.Errorf("unexpected notification: %s %v", notification.Method, fields)
+		}
+	default:
+		t.Fatal("expected an event notification")
+	}
+}
@@ -0,0 +1,223 @@
+package mcpserver
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+// Findings export resources
+const (
+	exportChunkTemplate    = "defectdojo://session/export/{name}/{chunk}"
+	exportPageSize         = 500   // Findings requested from DefectDojo at once
+	maxExportFindings      = 50000 // Findings one export may hold
+	defaultExportChunkSize = 1000  // Findings per chunk unless configured
+	maxExportChunkSize     = 5000  // Largest chunk a call or the configuration may ask for
+	maxExportsPerState     = 5     // Exports one session may keep
+)
+
+// findingsExport is a findings query exported in a session as numbered JSON
+// chunks, so no single message has to carry the whole result
+type findingsExport struct {
+	Name      string
+	SavedAt   time.Time
+	Query     map[string]any // Filter arguments of the query
+	Total     int            // Findings DefectDojo matched, which may exceed those exported
+	Exported  int
+	ChunkSize int
+	chunks    *spooledChunks // JSON array of each chunk's findings, in memory or spilled to disk
+}
+
+// exportChunk is the content of one export chunk resource
+type exportChunk struct {
+	Export    string          `json:"export"`
+	Chunk     int             `json:"chunk"` // 1-based
+	Chunks    int             `json:"chunks"`
+	SavedAt   time.Time       `json:"saved_at"`
+	Query     map[string]any  `json:"query"`
+	Total     int             `json:"total"`
+	Exported  int             `json:"exported"`
+	Findings  json.RawMessage `json:"findings"`
+	NextChunk string          `json:"next_chunk,omitempty"` // URI of the following chunk
+}
+
+// exportChunkURI is the resource URI of an export chunk, numbered from 1
+func exportChunkURI(name string, chunk int) string {
+	return strings.NewReplacer("{name}", name, "{chunk}", strconv.Itoa(chunk)).Replace(exportChunkTemplate)
+}
+
+// saveExport stores an export in the call's session, replacing one of the same name
+func (s *sessionStore) saveExport(ctx context.Context, export *findingsExport) error {
+	return s.update(ctx, func(state *sessionState) error {
+		if state.exports == nil {
+			state.exports = map[string]*findingsExport{}
+		}
+		if _, replaced := state.exports[export.Name]; !replaced && len(state.exports) >= maxExportsPerState {
+			return fmt.Errorf("this session already has %d exports; reuse one of their names: %s", len(state.exports), strings.Join(slices.Sorted(maps.Keys(state.exports)), ", "))
+		}
+		if replaced := state.exports[export.Name]; replaced != nil {
+			replaced.chunks.close()
+		}
+		state.exports[export.Name] = export
+		return nil
+	})
+}
+
+// export returns an export of the call's session
+func (s *sessionStore) export(ctx context.Context, name string) (*findingsExport, error) {
+	var export *findingsExport
+	var names []string
+	s.update(ctx, func(state *sessionState) error {
+		export = state.exports[name]
+		names = slices.Sorted(maps.Keys(state.exports))
+		return nil
+	})
+	if export == nil {
+		if len(names) == 0 {
+			return nil, fmt.Errorf("no export %q: this session has none, create one with export_findings", name)
+		}
+		return nil, fmt.Errorf("no export %q in this session (exports: %s)", name, strings.Join(names, ", "))
+	}
+	return export, nil
+}
+
+// exportFindings handles export_findings. Findings are fetched page by page
+// and encoded chunk by chunk as they arrive; the call returns a link to each
+// chunk resource instead of the findings themselves.
+func (s *Server) exportFindings(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	name, err := request.RequireString("name")
+	if err != nil {
+		return nil, fmt.Errorf("invalid name: %w", err)
+	}
+	if !savedResultName.MatchString(name) {
+		return nil, fmt.Errorf("invalid name %q: use up to 64 letters, digits, '.', '_' or '-'", name)
+	}
+	maxFindings := request.GetInt("max_findings", maxExportFindings)
+	if maxFindings < 1 || maxFindings > maxExportFindings {
+		return nil, fmt.Errorf("invalid max_findings %d: must be between 1 and %d", maxFindings, maxExportFindings)
+	}
+	chunkSize := request.GetInt("chunk_size", min(cmp.Or(s.config.Output.ExportChunkSize, defaultExportChunkSize), maxExportChunkSize))
+	if chunkSize < 1 || chunkSize > maxExportChunkSize {
+		return nil, fmt.Errorf("invalid chunk_size %d: must be between 1 and %d", chunkSize, maxExportChunkSize)
+	}
+
+	query, err := s.parseFindingsQuery(request)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.assigneeFilter(ctx, request, &query.filter); err != nil {
+		return nil, err
+	}
+	if err := s.environmentFilter(ctx, request, &query.filter); err != nil {
+		return nil, err
+	}
+
+	export := &findingsExport{Name: name, SavedAt: time.Now().UTC(), Query: map[string]any{}, ChunkSize: chunkSize, chunks: s.exports.spool()}
+	for _, argument := range savedResultFilters() {
+		if value, ok := request.GetArguments()[argument]; ok {
+			export.Query[argument] = value
+		}
+	}
+	pending := []jsonFinding{}
+	var bySeverity severityCounts
+	flush := func() error {
+		data, err := json.Marshal(pending)
+		if err != nil {
+			return fmt.Errorf("encoding export chunk: %w", err)
+		}
+		pending = pending[:0]
+		return export.chunks.add(data)
+	}
+	export.Total, err = s.walkFindingsQuery(ctx, query, maxFindings, exportPageSize, func(findings []types.Finding) error {
+		for _, finding := range findings {
+			pending = append(pending, newJSONFinding(finding, s.links, s.severity))
+			bySeverity.add(finding.Severity, 1)
+			export.Exported++
+			if len(pending) == chunkSize {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err == nil && (len(pending) > 0 || export.chunks.len() == 0) {
+		err = flush()
+	}
+	if err == nil {
+		err = s.sessions.saveExport(ctx, export)
+	}
+	if err != nil {
+		export.chunks.close()
+		return nil, err
+	}
+	chunks := export.chunks.len()
+
+	var summary strings.Builder
+	fmt.Fprintf(&summary, "Exported %d findings as %d chunks of up to %d findings: %s to %s\n", export.Exported, chunks, chunkSize, exportChunkURI(name, 1), exportChunkURI(name, chunks))
+	if counts := bySeverity.String(); counts != "" {
+		fmt.Fprintf(&summary, "By severity: %s\n", counts)
+	}
+	if export.Total > export.Exported {
+		fmt.Fprintf(&summary, "⚠️ The query matched %d findings; only the first %d were exported. Narrow the filters to export the rest.\n", export.Total, export.Exported)
+	}
+	if spilled := export.chunks.spilled(); spilled > 0 {
+		fmt.Fprintf(&summary, "%d chunks were written to disk to stay within the export memory budget; reading them is slightly slower.\n", spilled)
+	}
+	summary.WriteString("\nRead the chunks one at a time; each names the next in next_chunk. They last as long as this session.\n")
+
+	content := []mcp.Content{mcp.NewTextContent(summary.String())}
+	for i := range chunks {
+		content = append(content, mcp.NewResourceLink(exportChunkURI(name, i+1), fmt.Sprintf("%s chunk %d of %d", name, i+1, chunks), "", "application/json"))
+	}
+	return &mcp.CallToolResult{Content: content}, nil
+}
+
+// readExportChunk serves defectdojo://session/export/{name}/{chunk} from the reading session
+func (s *Server) readExportChunk(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	export, err := s.sessions.export(ctx, resourceArgument(request, "name"))
+	if err != nil {
+		return nil, err
+	}
+	chunks := export.chunks.len()
+	chunk, err := strconv.Atoi(resourceArgument(request, "chunk"))
+	if err != nil || chunk < 1 || chunk > chunks {
+		return nil, fmt.Errorf("invalid chunk %q: export %q has chunks 1 to %d", resourceArgument(request, "chunk"), export.Name, chunks)
+	}
+	findings, err := export.chunks.read(chunk - 1)
+	if err != nil {
+		return nil, err
+	}
+	output := exportChunk{
+		Export:   export.Name,
+		Chunk:    chunk,
+		Chunks:   chunks,
+		SavedAt:  export.SavedAt,
+		Query:    export.Query,
+		Total:    export.Total,
+		Exported: export.Exported,
+		Findings: findings,
+	}
+	if chunk < chunks {
+		output.NextChunk = exportChunkURI(export.Name, chunk+1)
+	}
+	text, err := marshalOutput(output)
+	if err != nil {
+		return nil, err
+	}
+	return []mcp.ResourceContents{mcp.TextResourceContents{
+		URI:      request.Params.URI,
+		MIMEType: "application/json",
+		Text:     text,
+	}}, nil
+}
@@ -0,0 +1,120 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+func TestExportFindings(t *testing.T) {
+	var limits []int
+	client := &MockDefectDojoClient{
+		GetFindingsFunc: func(ctx context.Context, filter types.FindingsFilter) (*types.FindingsResponse, error) {
+			limits = append(limits, filter.Limit)
+			return pagedFindings(2500)(ctx, filter)
+		},
+	}
+	base := serveStreamableHTTP(t, newServer(&Config{Output: OutputConfig{ExportChunkSize: 1000}}, client))
+	first := connectAs(t, base, "")
+	second := connectAs(t, base, "")
+	ctx := context.Background()
+
+	result, err := first.CallTool(ctx, mcp.CallToolRequest{Params: mcp.CallToolParams{Name: toolExportFindings, Arguments: map[string]any{"name": "all", "severity": "high"}}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text := resultText(result)
+	for _, want := range []string{"Exported 2500 findings as 3 chunks of up to 1000 findings", "defectdojo://session/export/all/1 to defectdojo://session/export/all/3", "By severity: 2500 high"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in:\n%s", want, text)
+		}
+	}
+	var links []string
+	for _, content := range result.Content {
+		if link, ok := content.(mcp.ResourceLink); ok {
+			links = append(links, link.URI)
+		}
+	}
+	if len(links) != 3 || links[2] != exportChunkURI("all", 3) {
+		t.Errorf("expected a link to each chunk, got %v", links)
+	}
+	if len(limits) != 5 || limits[0] != exportPageSize {
+		t.Errorf("expected five pages of %d, got %v", exportPageSize, limits)
+	}
+
+	readChunk := func(uri string) (exportChunk, []jsonFinding) {
+		t.Helper()
+		contents, err := first.ReadResource(ctx, mcp.ReadResourceRequest{Params: mcp.ReadResourceParams{URI: uri}})
+		if err != nil {
+			t.Fatalf("ReadResource(%s) error = %v", uri, err)
+		}
+		var chunk exportChunk
+		if err := json.Unmarshal([]byte(contents.Contents[0].(mcp.TextResourceContents).Text), &chunk); err != nil {
+			t.Fatalf("invalid JSON: %v", err)
+		}
+		var findings []jsonFinding
+		if err := json.Unmarshal(chunk.Findings, &findings); err != nil {
+			t.Fatalf("invalid findings: %v", err)
+		}
+		return chunk, findings
+	}
+	chunk, findings := readChunk(links[0])
+	if len(findings) != 1000 || findings[0].ID != 1 || chunk.NextChunk != links[1] || chunk.Chunks != 3 || chunk.Query["severity"] != "high" {
+		t.Errorf("unexpected first chunk: %d findings, next %q, %d chunks, query %v", len(findings), chunk.NextChunk, chunk.Chunks, chunk.Query)
+	}
+	chunk, findings = readChunk(links[2])
+	if len(findings) != 500 || findings[0].ID != 2001 || chunk.NextChunk != "" || chunk.Total != 2500 {
+		t.Errorf("unexpected last chunk: %d findings from %d, next %q", len(findings), findings[0].ID, chunk.NextChunk)
+	}
+
+	// Chunks belong to the exporting session and stay within the export
+	if _, err := second.ReadResource(ctx, mcp.ReadResourceRequest{Params: mcp.ReadResourceParams{URI: links[0]}}); err == nil {
+		t.Error("expected another session not to see the export")
+	}
+	if _, err := first.ReadResource(ctx, mcp.ReadResourceRequest{Params: mcp.ReadResourceParams{URI: exportChunkURI("all", 4)}}); err == nil || !strings.Contains(err.Error(), "has chunks 1 to 3") {
+		t.Errorf("expected an out-of-range chunk to be refused, got %v", err)
+	}
+
+	// A call's chunk size overrides the configured one; empty exports still have a chunk
+	text, err = callSessionTool(t, first, toolExportFindings, map[string]any{"name": "few", "chunk_size": 2, "max_findings": 5})
+	if err != nil || !strings.Contains(text, "Exported 5 findings as 3 chunks of up to 2 findings") || !strings.Contains(text, "only the first 5 were exported") {
+		t.Errorf("unexpected small export, %v:\n%s", err, text)
+	}
+	if _, err := callSessionTool(t, first, toolExportFindings, map[string]any{"name": "big", "chunk_size": maxExportChunkSize + 1}); err == nil {
+		t.Error("expected an oversized chunk to be refused")
+	}
+}
+
+func TestExportFindingsSpillToDisk(t *testing.T) {
+	s := newServer(&Config{}, &MockDefectDojoClient{GetFindingsFunc: pagedFindings(300)})
+	s.exports = newExportBuffer(t.TempDir(), 30000)
+	mcpClient := connectAs(t, serveStreamableHTTP(t, s), "")
+
+	text, err := callSessionTool(t, mcpClient, toolExportFindings, map[string]any{"name": "all", "chunk_size": 100})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(text, "Exported 300 findings as 3 chunks") || !strings.Contains(text, "chunks were written to disk") {
+		t.Errorf("expected chunks beyond the budget to spill, got:\n%s", text)
+	}
+	contents, err := mcpClient.ReadResource(context.Background(), mcp.ReadResourceRequest{Params: mcp.ReadResourceParams{URI: exportChunkURI("all", 3)}})
+	if err != nil {
+		t.Fatalf("ReadResource() error = %v", err)
+	}
+	var chunk struct {
+		Findings []struct {
+			ID int `json:"id"`
+		} `json:"findings"`
+	}
+	if err := json.Unmarshal([]byte(contents.Contents[0].(mcp.TextResourceContents).Text), &chunk); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(chunk.Findings) != 100 || chunk.Findings[0].ID != 201 {
+		t.Errorf("expected findings 201-300 read back from disk, got %d from %v", len(chunk.Findings), chunk.Findings[:1])
+	}
+}
@@ -0,0 +1,37 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/brduru/mcp-defect-dojo/internal/defectdojo"
+)
+
+// toolFeatures lists tools that depend on DefectDojo features missing from
+// older releases. Tools not listed work against every supported release.
+// Releases before vulnerability_ids report no CVE IDs, so finding details
+// would lack their CVEs and exploitation data, and prioritize_findings would
+// rank without EPSS and KEV.
+var toolFeatures = map[string][]defectdojo.Feature{
+	toolFindingDetail:      {defectdojo.FeatureVulnerabilityIDs},
+	toolPrioritizeFindings: {defectdojo.FeatureVulnerabilityIDs},
+}
+
+// featureGateMiddleware rejects calls to tools the connected DefectDojo release
+// cannot serve, with a clear "requires DefectDojo >= X" message instead of the
+// raw 404 the API would return.
+func featureGateMiddleware(ddClient defectdojo.Client) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			for _, feature := range toolFeatures[request.Params.Name] {
+				if err := ddClient.Supports(ctx, feature); err != nil {
+					return nil, fmt.Errorf("%s is unavailable: %w", request.Params.Name, err)
+				}
+			}
+			return next(ctx, request)
+		}
+	}
+}
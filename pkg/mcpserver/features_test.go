@@ -0,0 +1,60 @@
+package mcpserver
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/brduru/mcp-defect-dojo/internal/defectdojo"
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+func TestFeatureGateMiddleware(t *testing.T) {
+	var detailCalled bool
+	mock := &MockDefectDojoClient{
+		SupportsFunc: func(ctx context.Context, feature defectdojo.Feature) error {
+			if feature == defectdojo.FeatureVulnerabilityIDs {
+				return &defectdojo.UnsupportedFeatureError{Feature: feature, Required: "2.7.0", Detected: "2.6.0"}
+			}
+			return nil
+		},
+		GetFindingDetailFunc: func(ctx context.Context, findingID int) (*types.Finding, error) {
+			detailCalled = true
+			return &types.Finding{ID: findingID}, nil
+		},
+	}
+	s := newServer(&Config{}, mock)
+
+	for _, call := range []struct {
+		tool string
+		args map[string]any
+	}{
+		{toolFindingDetail, map[string]any{"finding_id": 1}},
+		{toolPrioritizeFindings, map[string]any{}},
+	} {
+		_, err := callTool(t, s, call.tool, call.args)
+		if err == nil || !strings.Contains(err.Error(), call.tool+" is unavailable: vulnerability_ids requires DefectDojo >= 2.7.0") {
+			t.Errorf("%s: expected the version requirement, got %v", call.tool, err)
+		}
+	}
+	if detailCalled {
+		t.Error("handler should not run for unsupported features")
+	}
+
+	// Ungated tools are unaffected
+	if _, err := callTool(t, s, toolGetFindings, map[string]any{}); err != nil {
+		t.Errorf("ungated tool failed: %v", err)
+	}
+}
+
+func TestToolFeaturesNameRegisteredTools(t *testing.T) {
+	registered := map[string]bool{}
+	for _, entry := range toolRegistry() {
+		registered[entry.definition().Name] = true
+	}
+	for tool := range toolFeatures {
+		if !registered[tool] {
+			t.Errorf("toolFeatures gates unknown tool %q", tool)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/url"
+	"strconv"
+
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+// getFindingsAtOrAbove retrieves findings whose severity is at least minSeverity.
+// DefectDojo filters on a single severity, so this issues one query per
+// qualifying severity (most severe first) and stitches the pages together.
+// Each query reports its bucket's total count, which lets filter.Offset and
+// filter.Limit be applied across the combined, severity-ordered result set.
+func (s *Server) getFindingsAtOrAbove(ctx context.Context, filter types.FindingsFilter, minSeverity string) (*types.FindingsResponse, error) {
+	severities := types.ValidSeverities()
+
+	combined := &types.FindingsResponse{Results: []types.Finding{}}
+	remaining, skip := filter.Limit, filter.Offset
+	for i := len(severities) - 1; i >= 0; i-- {
+		severity := severities[i]
+		if !types.SeverityAtOrAbove(severity, minSeverity) {
+			break
+		}
+
+		bucket := filter
+		bucket.Severity = severity
+		bucket.Offset = skip
+		bucket.Limit = max(remaining, 1) // still needed to learn the bucket's count
+
+		response, err := s.findingsPage(ctx, bucket)
+		if err != nil {
+			return nil, fmt.Errorf("%s findings: %w", severity, err)
+		}
+
+		combined.Count += response.Count
+		combined.Prefetch = mergePrefetched(combined.Prefetch, response.Prefetch)
+		if remaining > 0 {
+			take := response.Results[:min(len(response.Results), remaining)]
+			combined.Results = append(combined.Results, take...)
+			remaining -= len(take)
+		}
+		skip = max(0, skip-response.Count)
+	}
+
+	return combined, nil
+}
+
+// mergePrefetched adds the objects prefetched with another response to into
+func mergePrefetched(into, other *types.Prefetched) *types.Prefetched {
+	if other == nil {
+		return into
+	}
+	if into == nil {
+		into = &types.Prefetched{}
+	}
+	into.Tests = mergeByID(into.Tests, other.Tests)
+	into.Endpoints = mergeByID(into.Endpoints, other.Endpoints)
+	into.Notes = mergeByID(into.Notes, other.Notes)
+	return into
+}
+
+// mergeByID copies src into dst, allocating dst if needed
+func mergeByID[T any](dst, src map[int]T) map[int]T {
+	if len(src) == 0 {
+		return dst
+	}
+	if dst == nil {
+		dst = make(map[int]T, len(src))
+	}
+	maps.Copy(dst, src)
+	return dst
+}
+
+// pageCursor tells an agent whether more findings exist and where the next page starts
+type pageCursor struct {
+	Offset     int  `json:"offset"`                // Offset of the current page
+	Limit      int  `json:"limit"`                 // Page size that was requested
+	NextOffset *int `json:"next_offset,omitempty"` // Offset to request next, when HasMore
+	HasMore    bool `json:"has_more"`              // Whether findings remain after this page
+}
+
+// paginate computes the cursor for a findings page. DefectDojo's next URL is
+// authoritative when present; its offset parameter becomes next_offset.
+// Without one (e.g. pages stitched across severities), the total count decides.
+func paginate(response *types.FindingsResponse, offset, limit int) pageCursor {
+	page := pageCursor{Offset: offset, Limit: limit}
+	next := offset + len(response.Results)
+
+	if response.Next != nil && *response.Next != "" {
+		page.HasMore = true
+		if parsed, err := url.Parse(*response.Next); err == nil {
+			if n, err := strconv.Atoi(parsed.Query().Get("offset")); err == nil {
+				next = n
+			}
+		}
+	} else {
+		page.HasMore = len(response.Results) > 0 && next < response.Count
+	}
+
+	if page.HasMore {
+		page.NextOffset = &next
+	}
+	return page
+}
@@ -0,0 +1,130 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+// severityBuckets simulates DefectDojo's per-severity filtering and pagination
+func severityBuckets(counts map[string]int, queried *[]string) func(ctx context.Context, filter types.FindingsFilter) (*types.FindingsResponse, error) {
+	return func(ctx context.Context, filter types.FindingsFilter) (*types.FindingsResponse, error) {
+		*queried = append(*queried, filter.Severity)
+		total := counts[filter.Severity]
+		response := &types.FindingsResponse{Count: total}
+		for i := filter.Offset; i < total && len(response.Results) < filter.Limit; i++ {
+			response.Results = append(response.Results, types.Finding{ID: i, Title: fmt.Sprintf("%s-%d", filter.Severity, i), Severity: filter.Severity})
+		}
+		return response, nil
+	}
+}
+
+func TestGetFindingsAtOrAbove(t *testing.T) {
+	var queried []string
+	mock := &MockDefectDojoClient{
+		GetFindingsFunc: severityBuckets(map[string]int{"Critical": 2, "High": 3, "Medium": 10}, &queried),
+	}
+	s := newServer(&Config{}, mock)
+
+	titles := func(response *types.FindingsResponse) string {
+		var names []string
+		for _, f := range response.Results {
+			names = append(names, f.Title)
+		}
+		return strings.Join(names, ",")
+	}
+
+	tests := []struct {
+		name       string
+		limit      int
+		offset     int
+		wantTitles string
+	}{
+		{"first page spans buckets", 3, 0, "Critical-0,Critical-1,High-0"},
+		{"offset into second bucket", 2, 3, "High-1,High-2"},
+		{"offset past all results", 5, 10, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queried = nil
+			response, err := s.getFindingsAtOrAbove(context.Background(), types.FindingsFilter{Limit: tt.limit, Offset: tt.offset}, "High")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if response.Count != 5 {
+				t.Errorf("expected combined count 5, got %d", response.Count)
+			}
+			if got := titles(response); got != tt.wantTitles {
+				t.Errorf("results = %q, want %q", got, tt.wantTitles)
+			}
+			if strings.Join(queried, ",") != "Critical,High" {
+				t.Errorf("expected Critical and High queries only, got %v", queried)
+			}
+		})
+	}
+}
+
+func TestFindingsMinSeverityArgument(t *testing.T) {
+	var queried []string
+	mock := &MockDefectDojoClient{
+		GetFindingsFunc: severityBuckets(map[string]int{"Critical": 1, "High": 1}, &queried),
+	}
+	s := newServer(&Config{}, mock)
+
+	result, err := callTool(t, s, "get_defectdojo_findings", map[string]any{"min_severity": "High"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := resultText(result); !strings.Contains(text, "Found 2 findings") {
+		t.Errorf("expected combined results, got:\n%s", text)
+	}
+
+	for _, args := range []map[string]any{
+		{"min_severity": "High", "severity": "Low"},
+		{"min_severity": "Severe"},
+	} {
+		if _, err := callTool(t, s, "get_defectdojo_findings", args); err == nil {
+			t.Errorf("expected error for %v", args)
+		}
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	next := func(u string) *string { return &u }
+	tests := []struct {
+		name       string
+		response   *types.FindingsResponse
+		offset     int
+		limit      int
+		wantMore   bool
+		wantOffset int
+	}{
+		{"next URL offset", &types.FindingsResponse{Count: 50, Next: next("https://dd/api/v2/findings/?limit=10&offset=20"), Results: make([]types.Finding, 10)}, 10, 10, true, 20},
+		{"next URL without offset", &types.FindingsResponse{Count: 50, Next: next("https://dd/api/v2/findings/?page=2"), Results: make([]types.Finding, 10)}, 0, 10, true, 10},
+		{"last page", &types.FindingsResponse{Count: 25, Results: make([]types.Finding, 5)}, 20, 10, false, 0},
+		{"stitched page with more", &types.FindingsResponse{Count: 25, Results: make([]types.Finding, 10)}, 0, 10, true, 10},
+		{"empty page", &types.FindingsResponse{Count: 25}, 30, 10, false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page := paginate(tt.response, tt.offset, tt.limit)
+			if page.HasMore != tt.wantMore {
+				t.Fatalf("HasMore = %v, want %v", page.HasMore, tt.wantMore)
+			}
+			if !tt.wantMore {
+				if page.NextOffset != nil {
+					t.Errorf("expected no next_offset, got %d", *page.NextOffset)
+				}
+				return
+			}
+			if page.NextOffset == nil || *page.NextOffset != tt.wantOffset {
+				t.Errorf("NextOffset = %v, want %d", page.NextOffset, tt.wantOffset)
+			}
+		})
+	}
+}
@@ -0,0 +1,364 @@
+package mcpserver
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+// Detail levels for findings lists
+const (
+	detailSummary = "summary" // One line per finding
+	detailNormal  = "normal"  // Status, scoring, tags and a shortened description
+	detailFull    = "full"    // Everything in normal plus location, dates and SLA
+)
+
+// detailLevels returns the accepted detail_level values
+func detailLevels() []string {
+	return []string{detailSummary, detailNormal, detailFull}
+}
+
+// Output formats
+const (
+	formatText     = "text"     // Plain text, the default
+	formatMarkdown = "markdown" // Tables and sections for chat UIs that render Markdown
+	formatJSON     = "json"     // Raw data for agents that parse results
+)
+
+// outputFormats returns the accepted format values
+func outputFormats() []string {
+	return []string{formatText, formatMarkdown, formatJSON}
+}
+
+// formatOptions controls how much of a finding is rendered
+type formatOptions struct {
+	format              string // text, markdown or json ("" = text)
+	maxFieldChars       int    // Truncate long text sections to this many characters (0 = unlimited)
+	detailLevel         string // summary, normal or full for findings lists ("" = normal)
+	maxDescriptionChars int    // Truncate descriptions in findings lists (0 = unlimited)
+	groupRepeated       bool   // Collapse findings with the same title and component into one entry
+
+	contexts map[int]findingContext // Resolved product/engagement names by test ID (nil = not requested)
+	intel    map[string]CVEIntel    // EPSS and KEV data by CVE ID (nil = enrichment off)
+	links    webLinks               // DefectDojo UI URLs (zero = no links)
+	html     string                 // Handling of HTML in text sections: markdown, text or raw ("" = markdown)
+
+	severities severityScale // Organization severity labels (zero = DefectDojo's names)
+	text       catalog       // Translated labels and headings (nil = English)
+	warnings   []string      // Enrichment that failed, listed after the findings
+}
+
+// renderFindingsList renders a findings page and its pagination cursor in the requested output format
+func renderFindingsList(response *types.FindingsResponse, page pageCursor, opts formatOptions) (string, error) {
+	response = opts.convertFindingsHTML(response)
+	switch opts.format {
+	case formatJSON:
+		return jsonFindingsList(response, page, opts)
+	case formatMarkdown:
+		return markdownFindingsList(response, opts) + fmt.Sprintf("\n_%s_\n", formatPageCursor(page)) + formatWarnings(opts.warnings), nil
+	default:
+		return formatFindingsList(response, opts) + formatPageCursor(page) + "\n" + formatWarnings(opts.warnings), nil
+	}
+}
+
+// renderFindingDetail renders a single finding in the requested output format
+func renderFindingDetail(finding *types.Finding, opts formatOptions) (string, error) {
+	converted := opts.convertHTML(*finding)
+	finding = &converted
+	switch opts.format {
+	case formatJSON:
+		return jsonFindingDetail(finding, opts)
+	case formatMarkdown:
+		return markdownFindingDetail(finding, opts) + formatWarnings(opts.warnings), nil
+	default:
+		return formatFindingDetail(finding, opts) + formatWarnings(opts.warnings), nil
+	}
+}
+
+// formatPageCursor renders the pagination state on one line
+func formatPageCursor(page pageCursor) string {
+	if !page.HasMore {
+		return "has_more: false"
+	}
+	return fmt.Sprintf("has_more: true, next_offset: %d", *page.NextOffset)
+}
+
+// formatFindingsList renders a page of findings for the get_defectdojo_findings tool
+func formatFindingsList(response *types.FindingsResponse, opts formatOptions) string {
+	result := fmt.Sprintf(opts.text.t("Found %d findings (showing %d)")+":\n\n", response.Count, len(response.Results))
+	if opts.groupRepeated {
+		for i, group := range groupRepeatedFindings(response.Results) {
+			if len(group.IDs) > 1 {
+				result += formatFindingGroup(i+1, group, opts)
+			} else {
+				result += formatFindingSummary(i+1, &group.Finding, opts)
+			}
+			if opts.detailLevel != detailSummary {
+				result += "\n"
+			}
+		}
+		return result
+	}
+	for i, finding := range response.Results {
+		result += formatFindingSummary(i+1, &finding, opts)
+		if opts.detailLevel != detailSummary {
+			result += "\n"
+		}
+	}
+	return result
+}
+
+// formatFindingSummary renders one numbered entry of a findings list
+func formatFindingSummary(index int, finding *types.Finding, opts formatOptions) string {
+	result := fmt.Sprintf("%d. [%s] %s (ID: %d)\n", index, opts.severities.display(finding.Severity), finding.Title, finding.ID)
+	if names := opts.contexts[finding.Test].String(); names != "" {
+		result += fmt.Sprintf("   %s\n", names)
+	}
+	if link := opts.links.finding(finding.ID); link != "" {
+		result += fmt.Sprintf("   URL: %s\n", link)
+	}
+	if opts.detailLevel == detailSummary {
+		return result
+	}
+	result += fmt.Sprintf("   %s: %t, %s: %t, %s: %t\n", opts.text.t("Active"), finding.Active, opts.text.t("Verified"), finding.Verified, opts.text.t("False Positive"), finding.FalseP)
+	if flags := finding.StatusFlags(); len(flags) > 0 {
+		result += fmt.Sprintf("   %s: %s\n", opts.text.t("Status"), opts.text.join(flags))
+	}
+	if scoring := formatScoring(finding); scoring != "" {
+		result += fmt.Sprintf("   %s\n", scoring)
+	}
+	if len(finding.Tags) > 0 {
+		result += fmt.Sprintf("   %s: %s\n", opts.text.t("Tags"), strings.Join(finding.Tags, ", "))
+	}
+	if name := finding.ReporterName(); name != "" {
+		result += fmt.Sprintf("   %s: %s\n", opts.text.t("Reporter"), name)
+	}
+	if opts.detailLevel == detailFull {
+		extra := formatLocation(finding, opts.text) + formatDates(finding, opts.text) + formatSLA(finding, opts.text)
+		for _, line := range strings.Split(strings.TrimSuffix(extra, "\n"), "\n") {
+			if line != "" {
+				result += fmt.Sprintf("   %s\n", line)
+			}
+		}
+	}
+	if finding.Description != "" {
+		description := finding.Description
+		if opts.detailLevel != detailFull {
+			// Keep list entries on one line; full keeps the original layout
+			description = strings.Join(strings.Fields(description), " ")
+		}
+		result += fmt.Sprintf("   %s: %s\n", opts.text.t("Description"), truncateText(description, opts.maxDescriptionChars))
+	}
+	return result
+}
+
+// formatFindingDetail renders the full view of a single finding
+func formatFindingDetail(finding *types.Finding, opts formatOptions) string {
+	result := fmt.Sprintf(opts.text.t("Finding Details (ID: %d)")+":\n\n", finding.ID)
+	result += fmt.Sprintf("%s: %s\n", opts.text.t("Title"), finding.Title)
+	if link := opts.links.finding(finding.ID); link != "" {
+		result += fmt.Sprintf("URL: %s\n", link)
+	}
+	result += fmt.Sprintf("%s: %s\n", opts.text.t("Severity"), opts.severities.display(finding.Severity))
+	if finding.NumericalSeverity != "" {
+		result += fmt.Sprintf("%s: %s\n", opts.text.t("Numerical Severity"), finding.NumericalSeverity)
+	}
+	if finding.CVSSv3Score != nil {
+		result += fmt.Sprintf("%s: %.1f\n", opts.text.t("CVSS v3 Score"), *finding.CVSSv3Score)
+	}
+	if finding.CVSSv3 != "" {
+		result += fmt.Sprintf("%s: %s\n", opts.text.t("CVSS v3 Vector"), finding.CVSSv3)
+	}
+	if finding.CWE != 0 {
+		result += fmt.Sprintf("CWE: CWE-%d\n", finding.CWE)
+	}
+	if ids := finding.VulnerabilityIDList(); len(ids) > 0 {
+		result += fmt.Sprintf("%s: %s\n", opts.text.t("Vulnerability IDs"), strings.Join(ids, ", "))
+	}
+	result += formatExploitation(finding, opts.intel)
+	result += formatLocation(finding, opts.text)
+	result += fmt.Sprintf("%s: %t\n", opts.text.t("Active"), finding.Active)
+	result += fmt.Sprintf("%s: %t\n", opts.text.t("Verified"), finding.Verified)
+	result += fmt.Sprintf("%s: %t\n", opts.text.t("False Positive"), finding.FalseP)
+	if flags := finding.StatusFlags(); len(flags) > 0 {
+		result += fmt.Sprintf("%s: %s\n", opts.text.t("Status"), opts.text.join(flags))
+	}
+	result += formatTest(finding, opts)
+	if len(finding.Tags) > 0 {
+		result += fmt.Sprintf("%s: %s\n", opts.text.t("Tags"), strings.Join(finding.Tags, ", "))
+	}
+	if name := finding.ReporterName(); name != "" {
+		result += fmt.Sprintf("%s: %s\n", opts.text.t("Reporter"), name)
+	} else if finding.Reporter != 0 {
+		result += fmt.Sprintf("%s: "+opts.text.t("user %d")+"\n", opts.text.t("Reporter"), finding.Reporter)
+	}
+	if len(finding.Reviewers) > 0 {
+		result += fmt.Sprintf("%s: %s\n", opts.text.t("Assigned to"), formatUserIDs(finding.Reviewers))
+	}
+	result += formatDates(finding, opts.text)
+	result += formatSLA(finding, opts.text)
+	sections := []struct{ heading, text string }{
+		{"Description", finding.Description},
+		{"Impact", finding.Impact},
+		{"Mitigation", finding.Mitigation},
+		{"Steps to Reproduce", finding.StepsToReproduce},
+		{"References", finding.References},
+	}
+	for _, section := range sections {
+		if section.text != "" {
+			result += fmt.Sprintf("\n%s:\n%s\n", opts.text.t(section.heading), truncateText(section.text, opts.maxFieldChars))
+		}
+	}
+	return result
+}
+
+// truncateText shortens text to at most maxChars characters, noting how much was cut.
+// A non-positive maxChars disables truncation.
+func truncateText(text string, maxChars int) string {
+	if maxChars <= 0 {
+		return text
+	}
+	runes := []rune(text)
+	if len(runes) <= maxChars {
+		return text
+	}
+	return fmt.Sprintf("%s… [truncated %d characters]", string(runes[:maxChars]), len(runes)-maxChars)
+}
+
+// formatLocation renders where the vulnerability is: file, SAST object, component and endpoints
+func formatLocation(finding *types.Finding, text catalog) string {
+	var result string
+	if location := finding.Location(); location != "" {
+		result += fmt.Sprintf("%s: %s\n", text.t("File"), location)
+	}
+	if finding.SASTSourceObject != "" {
+		result += fmt.Sprintf("%s: %s\n", text.t("SAST Source Object"), finding.SASTSourceObject)
+	}
+	if finding.ComponentName != "" {
+		component := finding.ComponentName
+		if finding.ComponentVersion != "" {
+			component += " " + finding.ComponentVersion
+		}
+		result += fmt.Sprintf("%s: %s\n", text.t("Component"), component)
+	}
+	if len(finding.Endpoints) > 0 {
+		ids := make([]string, len(finding.Endpoints))
+		for i, id := range finding.Endpoints {
+			ids[i] = fmt.Sprintf("%d", id)
+		}
+		result += fmt.Sprintf("%s: %s\n", text.t("Endpoint IDs"), strings.Join(ids, ", "))
+	}
+	return result
+}
+
+// formatScoring renders CVSS, CWE and vulnerability IDs on one line, or "" if none are set
+func formatScoring(finding *types.Finding) string {
+	var parts []string
+	if finding.CVSSv3Score != nil {
+		parts = append(parts, fmt.Sprintf("CVSS: %.1f", *finding.CVSSv3Score))
+	}
+	if finding.CWE != 0 {
+		parts = append(parts, fmt.Sprintf("CWE-%d", finding.CWE))
+	}
+	if ids := finding.VulnerabilityIDList(); len(ids) > 0 {
+		parts = append(parts, strings.Join(ids, ", "))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatTest renders the finding's test and, when resolved, its product,
+// engagement and environment
+func formatTest(finding *types.Finding, opts formatOptions) string {
+	names := opts.contexts[finding.Test]
+	var result string
+	if names.Product != "" {
+		result += fmt.Sprintf("%s: %s\n", opts.text.t("Product"), names.Product)
+	}
+	if names.Engagement != "" {
+		result += fmt.Sprintf("%s: %s\n", opts.text.t("Engagement"), names.Engagement)
+	}
+	if names.Test != "" {
+		result += fmt.Sprintf("%s: %s (ID: %d)\n", opts.text.t("Test"), names.Test, finding.Test)
+	} else {
+		result += fmt.Sprintf("%s: %d\n", opts.text.t("Test ID"), finding.Test)
+	}
+	if names.TestType != "" {
+		result += fmt.Sprintf("%s: %s\n", opts.text.t("Scanner"), names.TestType)
+	}
+	if names.Environment != "" {
+		result += fmt.Sprintf("%s: %s\n", opts.text.t("Environment"), names.Environment)
+	}
+	return result
+}
+
+// formatDates renders discovery, lifecycle timestamps and age, or "" if none are known
+func formatDates(finding *types.Finding, text catalog) string {
+	var result string
+	if !finding.Date.IsZero() {
+		result += fmt.Sprintf("%s: %s\n", text.t("Discovered"), finding.Date.Format(time.DateOnly))
+	}
+	if !finding.Created.IsZero() {
+		result += fmt.Sprintf("%s: %s\n", text.t("Created"), finding.Created.Format(time.RFC3339))
+	}
+	if !finding.Modified.IsZero() {
+		result += fmt.Sprintf("%s: %s\n", text.t("Modified"), finding.Modified.Format(time.RFC3339))
+	}
+	if !finding.Mitigated.IsZero() {
+		result += fmt.Sprintf("%s: %s\n", text.t("Mitigated"), finding.Mitigated.Format(time.RFC3339))
+	}
+	if days, ok := ageDays(finding); ok {
+		result += fmt.Sprintf("%s: "+text.t("%d days")+"\n", text.t("Age"), days)
+	}
+	return result
+}
+
+// ageDays returns the finding's age in days as reported by DefectDojo,
+// or computed from its discovery date when the API omitted it
+func ageDays(finding *types.Finding) (int, bool) {
+	if finding.AgeDays != nil {
+		return *finding.AgeDays, true
+	}
+	if age := finding.Age(); age > 0 {
+		return int(age.Hours() / 24), true
+	}
+	return 0, false
+}
+
+// formatSLA renders the remediation deadline and how much time is left, or "" without an SLA
+func formatSLA(finding *types.Finding, text catalog) string {
+	var result string
+	if !finding.SLAExpirationDate.IsZero() {
+		result += fmt.Sprintf("%s: %s\n", text.t("SLA Expiration"), finding.SLAExpirationDate.Format(time.DateOnly))
+	}
+	if finding.SLADaysRemaining != nil {
+		if days := *finding.SLADaysRemaining; days < 0 {
+			result += fmt.Sprintf("%s: %d ("+text.t("overdue by %d days")+")\n", text.t("SLA Days Remaining"), days, -days)
+		} else {
+			result += fmt.Sprintf("%s: %d\n", text.t("SLA Days Remaining"), days)
+		}
+	}
+	return result
+}
+
+// formatHealthStatus renders the structured result of a DefectDojo health check
+func formatHealthStatus(status *types.HealthStatus) string {
+	yesNo := map[bool]string{true: "yes", false: "no"}
+
+	result := fmt.Sprintf("URL: %s\n", status.URL)
+	result += fmt.Sprintf("Reachable: %s", yesNo[status.Reachable])
+	if status.Latency > 0 {
+		result += fmt.Sprintf(" (latency %s)", status.Latency.Round(time.Millisecond))
+	}
+	result += "\n"
+	result += fmt.Sprintf("Authenticated: %s", yesNo[status.Authenticated])
+	if status.User != "" {
+		result += fmt.Sprintf(" (as %s)", status.User)
+	}
+	result += "\n"
+	if status.Version != "" {
+		result += fmt.Sprintf("DefectDojo Version: %s\n", status.Version)
+	}
+	return result
+}
@@ -0,0 +1,195 @@
+package mcpserver
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+func TestFormatFindingScoring(t *testing.T) {
+	score := 9.8
+	finding := &types.Finding{
+		ID:                10,
+		Title:             "Deserialization",
+		Severity:          "Critical",
+		CVSSv3:            "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",
+		CVSSv3Score:       &score,
+		CWE:               502,
+		VulnerabilityIDs:  []types.VulnerabilityID{{VulnerabilityID: "CVE-2024-0001"}},
+		NumericalSeverity: "S0",
+	}
+
+	detail := formatFindingDetail(finding, formatOptions{})
+	for _, want := range []string{"CVSS v3 Score: 9.8", "CVSS v3 Vector: CVSS:3.1/", "CWE: CWE-502", "Vulnerability IDs: CVE-2024-0001", "Numerical Severity: S0"} {
+		if !strings.Contains(detail, want) {
+			t.Errorf("detail missing %q:\n%s", want, detail)
+		}
+	}
+
+	summary := formatFindingSummary(1, finding, formatOptions{})
+	if !strings.Contains(summary, "CVSS: 9.8, CWE-502, CVE-2024-0001") {
+		t.Errorf("summary missing scoring line:\n%s", summary)
+	}
+
+	// Unscored findings don't render an empty scoring line
+	plain := formatFindingSummary(1, &types.Finding{ID: 1, Title: "Plain", Severity: "Low"}, formatOptions{})
+	if strings.Count(plain, "\n") != 2 {
+		t.Errorf("expected two lines for unscored finding, got:\n%s", plain)
+	}
+}
+
+func TestFormatFindingLocation(t *testing.T) {
+	line := 42
+	finding := &types.Finding{
+		ID:               11,
+		Title:            "Hardcoded secret",
+		Severity:         "High",
+		FilePath:         "src/config/db.go",
+		Line:             &line,
+		SASTSourceObject: "dbPassword",
+		ComponentName:    "lodash",
+		ComponentVersion: "4.17.20",
+		Endpoints:        []int{3, 8},
+	}
+
+	detail := formatFindingDetail(finding, formatOptions{})
+	for _, want := range []string{"File: src/config/db.go:42", "SAST Source Object: dbPassword", "Component: lodash 4.17.20", "Endpoint IDs: 3, 8"} {
+		if !strings.Contains(detail, want) {
+			t.Errorf("detail missing %q:\n%s", want, detail)
+		}
+	}
+
+	if got := formatLocation(&types.Finding{}, nil); got != "" {
+		t.Errorf("expected no location output, got %q", got)
+	}
+}
+
+func TestFormatFindingRemediation(t *testing.T) {
+	finding := &types.Finding{
+		ID:               12,
+		Title:            "SQL Injection",
+		Severity:         "Critical",
+		Impact:           "Full database compromise",
+		Mitigation:       "Use parameterized queries",
+		StepsToReproduce: "Send ' OR 1=1 -- in the username field",
+		References:       "https://owasp.org/www-community/attacks/SQL_Injection",
+	}
+
+	detail := formatFindingDetail(finding, formatOptions{})
+	for _, want := range []string{
+		"Impact:\nFull database compromise",
+		"Mitigation:\nUse parameterized queries",
+		"Steps to Reproduce:\nSend ' OR 1=1",
+		"References:\nhttps://owasp.org",
+	} {
+		if !strings.Contains(detail, want) {
+			t.Errorf("detail missing %q:\n%s", want, detail)
+		}
+	}
+
+	// Empty sections are omitted
+	plain := formatFindingDetail(&types.Finding{ID: 1, Title: "Plain", Severity: "Low"}, formatOptions{})
+	if strings.Contains(plain, "Mitigation:") || strings.Contains(plain, "Impact:") {
+		t.Errorf("expected no remediation sections, got:\n%s", plain)
+	}
+}
+
+func TestFormatFindingDetailTruncation(t *testing.T) {
+	finding := &types.Finding{
+		ID:         13,
+		Title:      "Verbose finding",
+		Severity:   "Medium",
+		Mitigation: strings.Repeat("x", 50),
+	}
+
+	detail := formatFindingDetail(finding, formatOptions{maxFieldChars: 10})
+	if !strings.Contains(detail, strings.Repeat("x", 10)+"… [truncated 40 characters]") {
+		t.Errorf("expected truncated mitigation, got:\n%s", detail)
+	}
+
+	full := formatFindingDetail(finding, formatOptions{})
+	if !strings.Contains(full, strings.Repeat("x", 50)) || strings.Contains(full, "truncated") {
+		t.Errorf("expected untruncated mitigation, got:\n%s", full)
+	}
+}
+
+func TestFormatFindingsListDetailLevels(t *testing.T) {
+	line := 7
+	response := &types.FindingsResponse{
+		Count: 1,
+		Results: []types.Finding{{
+			ID:          20,
+			Title:       "Noisy scanner output",
+			Severity:    "Low",
+			Active:      true,
+			FilePath:    "main.go",
+			Line:        &line,
+			Description: "first line\n\nsecond " + strings.Repeat("y", 40),
+		}},
+	}
+
+	summary := formatFindingsList(response, formatOptions{detailLevel: detailSummary})
+	if strings.Contains(summary, "Active:") || strings.Contains(summary, "Description") {
+		t.Errorf("summary should only list titles, got:\n%s", summary)
+	}
+
+	normal := formatFindingsList(response, formatOptions{detailLevel: detailNormal, maxDescriptionChars: 20})
+	if !strings.Contains(normal, "Description: first line second yy… [truncated 38 characters]") {
+		t.Errorf("expected single-line truncated description, got:\n%s", normal)
+	}
+	if strings.Contains(normal, "File:") {
+		t.Errorf("normal should not include location, got:\n%s", normal)
+	}
+
+	full := formatFindingsList(response, formatOptions{detailLevel: detailFull})
+	if !strings.Contains(full, "   File: main.go:7") || !strings.Contains(full, "first line\n\nsecond") {
+		t.Errorf("full should include location and the original description, got:\n%s", full)
+	}
+}
+
+func TestTruncateText(t *testing.T) {
+	tests := []struct {
+		text     string
+		maxChars int
+		want     string
+	}{
+		{"short", 10, "short"},
+		{"exactly10!", 10, "exactly10!"},
+		{"anything", 0, "anything"},
+		{"ação rápida", 4, "ação… [truncated 7 characters]"},
+	}
+
+	for _, tt := range tests {
+		if got := truncateText(tt.text, tt.maxChars); got != tt.want {
+			t.Errorf("truncateText(%q, %d) = %q, want %q", tt.text, tt.maxChars, got, tt.want)
+		}
+	}
+}
+
+func TestFormatFindingStatusAndSLA(t *testing.T) {
+	overdue, age := -3, 40
+	finding := &types.Finding{
+		ID:                14,
+		Title:             "Accepted risk",
+		Severity:          "Medium",
+		RiskAccepted:      true,
+		UnderReview:       true,
+		SLAExpirationDate: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+		SLADaysRemaining:  &overdue,
+		AgeDays:           &age,
+	}
+
+	detail := formatFindingDetail(finding, formatOptions{})
+	for _, want := range []string{"Status: Risk Accepted, Under Review", "SLA Expiration: 2024-05-01", "overdue by 3 days", "Age: 40 days"} {
+		if !strings.Contains(detail, want) {
+			t.Errorf("detail missing %q:\n%s", want, detail)
+		}
+	}
+
+	summary := formatFindingSummary(1, finding, formatOptions{})
+	if !strings.Contains(summary, "Status: Risk Accepted, Under Review") {
+		t.Errorf("summary missing status flags:\n%s", summary)
+	}
+}
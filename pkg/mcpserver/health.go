@@ -0,0 +1,201 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/brduru/mcp-defect-dojo/internal/config"
+	"github.com/brduru/mcp-defect-dojo/internal/defectdojo"
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+// defaultHealthCacheTTL bounds how often readiness probes reach DefectDojo
+const defaultHealthCacheTTL = 10 * time.Second
+
+// healthCache memoizes DefectDojo health checks so frequent orchestrator
+// probes don't translate into a DefectDojo request each time.
+type healthCache struct {
+	client defectdojo.Client
+	ttl    time.Duration
+
+	mu        sync.Mutex
+	checkedAt time.Time
+	healthy   bool
+	message   string
+}
+
+// check returns the cached health status, refreshing it when expired
+func (c *healthCache) check(ctx context.Context) (bool, string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.checkedAt.IsZero() && time.Since(c.checkedAt) < c.ttl {
+		return c.healthy, c.message
+	}
+
+	c.healthy, c.message = c.client.HealthCheck(ctx)
+	c.checkedAt = time.Now()
+	return c.healthy, c.message
+}
+
+// HealthHandler returns an http.Handler serving container orchestration probes:
+//
+//   - /healthz: liveness, always 200 while the process is serving requests
+//   - /readyz: readiness, 200 when DefectDojo is reachable and the API key is
+//     accepted, 503 otherwise
+//   - /metrics: tool call counts, errors and latency in the Prometheus text format
+//
+// Readiness results are cached (see ServerConfig.HealthCacheTTL) so that
+// aggressive probe intervals don't load the DefectDojo instance.
+func (s *Server) HealthHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("ok\n"))
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		healthy, message := s.health.check(r.Context())
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		if !healthy {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		} else {
+			w.WriteHeader(http.StatusOK)
+		}
+		w.Write([]byte(message + "\n"))
+	})
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		s.stats.writeMetrics(w)
+		s.writeTransferMetrics(w)
+		s.workers.writeMetrics(w)
+	})
+	return mux
+}
+
+// defaultInstance names the DefectDojo instance the server was configured
+// with, in the health check and log fields
+const defaultInstance = "default"
+
+// dojoInstance is a DefectDojo instance the health check probes
+type dojoInstance struct {
+	name   string
+	client defectdojo.Client
+	apiKey string // Token of a further instance, overriding the credential chosen for the call
+}
+
+// instanceHealth is the probe result of one instance
+type instanceHealth struct {
+	name   string
+	status *types.HealthStatus
+}
+
+// furtherInstances returns the clients of the further instances in cfg.
+// They are only probed in live mode, as offline, record and replay modes
+// must not reach other instances; invalid ones are ignored with a warning.
+func furtherInstances(cfg DefectDojoConfig) []dojoInstance {
+	if len(cfg.Instances) == 0 {
+		return nil
+	}
+	if cfg.Mode != "" && cfg.Mode != defectdojo.ModeLive {
+		log.Printf("⚠️  Further DefectDojo instances are only probed in live mode, ignoring %d", len(cfg.Instances))
+		return nil
+	}
+	instances := make([]config.InstanceConfig, 0, len(cfg.Instances))
+	for _, instance := range cfg.Instances {
+		instances = append(instances, config.InstanceConfig(instance))
+	}
+	if err := config.ValidateInstances(instances); err != nil {
+		log.Printf("⚠️  Further DefectDojo instances ignored: %v", err)
+		return nil
+	}
+
+	result := make([]dojoInstance, 0, len(cfg.Instances))
+	for _, instance := range cfg.Instances {
+		client := defectdojo.NewHTTPClient(&config.DefectDojoConfig{
+			BaseURL:        instance.BaseURL,
+			APIKey:         instance.APIKey,
+			APIVersion:     cfg.APIVersion,
+			RequestTimeout: cfg.RequestTimeout,
+			ErrorDetail:    cfg.ErrorDetail,
+		})
+		result = append(result, dojoInstance{name: instance.Name, client: client, apiKey: instance.APIKey})
+	}
+	return result
+}
+
+// probeInstances checks every instance concurrently, keeping their order
+func probeInstances(ctx context.Context, instances []dojoInstance) []instanceHealth {
+	results := make([]instanceHealth, len(instances))
+	var wg sync.WaitGroup
+	for i, instance := range instances {
+		wg.Go(func() {
+			ctx := ctx
+			if instance.apiKey != "" {
+				ctx = defectdojo.WithAPIKey(ctx, instance.apiKey)
+			}
+			results[i] = instanceHealth{name: instance.name, status: instance.client.CheckHealth(ctx)}
+		})
+	}
+	wg.Wait()
+	return results
+}
+
+// healthCheck handles defectdojo_health_check. Without further instances the
+// configured one is reported in detail; with them, every instance is probed
+// concurrently and summarized in one table. The call fails when any instance
+// is unhealthy.
+func (s *Server) healthCheck(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if len(s.instances) == 0 {
+		status := s.ddClient.CheckHealth(ctx)
+		if !status.Healthy() {
+			return nil, fmt.Errorf("DefectDojo Health Check failed: %s\n\n%s", status.Error, formatHealthStatus(status))
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("DefectDojo Health Check: ✅ HEALTHY\n\n%s", formatHealthStatus(status))), nil
+	}
+
+	instances := append([]dojoInstance{{name: defaultInstance, client: s.ddClient}}, s.instances...)
+	results := probeInstances(ctx, instances)
+	unhealthy := 0
+	for _, result := range results {
+		if !result.status.Healthy() {
+			unhealthy++
+		}
+	}
+	if unhealthy > 0 {
+		return nil, fmt.Errorf("DefectDojo Health Check failed for %d of %d instances\n\n%s", unhealthy, len(results), formatInstanceHealth(results))
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("DefectDojo Health Check: ✅ all %d instances HEALTHY\n\n%s", len(results), formatInstanceHealth(results))), nil
+}
+
+// formatInstanceHealth renders one table row per instance
+func formatInstanceHealth(results []instanceHealth) string {
+	result := "| Instance | URL | Status | Latency | Version | User |\n"
+	result += "|----------|-----|--------|--------:|---------|------|\n"
+	for _, instance := range results {
+		status := instance.status
+		state := "✅ healthy"
+		if !status.Healthy() {
+			state = "❌ " + markdownCell(status.Error)
+		}
+		latency := "-"
+		if status.Latency > 0 {
+			latency = status.Latency.Round(time.Millisecond).String()
+		}
+		cells := []string{instance.name, status.URL, state, latency, status.Version, status.User}
+		for i, cell := range cells {
+			if cell == "" {
+				cells[i] = "-"
+			}
+		}
+		result += "| " + strings.Join(cells, " | ") + " |\n"
+	}
+	return result
+}
@@ -0,0 +1,151 @@
+package mcpserver
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+func TestHealthHandler(t *testing.T) {
+	var calls int
+	healthy := true
+	mock := &MockDefectDojoClient{
+		HealthCheckFunc: func(ctx context.Context) (bool, string) {
+			calls++
+			if healthy {
+				return true, "connected"
+			}
+			return false, "DefectDojo responded with status 401"
+		},
+	}
+
+	s := newServer(&Config{Server: ServerConfig{HealthCacheTTL: time.Hour}}, mock)
+	handler := s.HealthHandler()
+
+	probe := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	t.Run("liveness does not contact DefectDojo", func(t *testing.T) {
+		if rec := probe("/healthz"); rec.Code != http.StatusOK {
+			t.Errorf("expected 200, got %d", rec.Code)
+		}
+		if calls != 0 {
+			t.Errorf("expected no health checks, got %d", calls)
+		}
+	})
+
+	t.Run("readiness is cached", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			if rec := probe("/readyz"); rec.Code != http.StatusOK {
+				t.Errorf("expected 200, got %d", rec.Code)
+			}
+		}
+		if calls != 1 {
+			t.Errorf("expected 1 health check, got %d", calls)
+		}
+	})
+
+	t.Run("unready when DefectDojo is unhealthy", func(t *testing.T) {
+		healthy = false
+		s.health.checkedAt = time.Time{} // expire cache
+		rec := probe("/readyz")
+		if rec.Code != http.StatusServiceUnavailable {
+			t.Errorf("expected 503, got %d", rec.Code)
+		}
+		if !strings.Contains(rec.Body.String(), "401") {
+			t.Errorf("expected failure reason in body, got %q", rec.Body.String())
+		}
+	})
+}
+
+func TestProbeInstancesConcurrently(t *testing.T) {
+	// Each probe waits for the other to start, so a sequential probe would time out
+	var started sync.WaitGroup
+	started.Add(2)
+	probe := func(status *types.HealthStatus) *MockDefectDojoClient {
+		return &MockDefectDojoClient{CheckHealthFunc: func(ctx context.Context) *types.HealthStatus {
+			started.Done()
+			done := make(chan struct{})
+			go func() { started.Wait(); close(done) }()
+			select {
+			case <-done:
+			case <-time.After(5 * time.Second):
+				t.Error("instances were probed one after another")
+			}
+			return status
+		}}
+	}
+
+	results := probeInstances(context.Background(), []dojoInstance{
+		{name: "prod", client: probe(&types.HealthStatus{URL: "https://dojo.example.com", Reachable: true, Authenticated: true, Version: "2.38.1", User: "bot", Latency: 42 * time.Millisecond})},
+		{name: "staging", client: probe(&types.HealthStatus{URL: "https://dojo-staging.example.com", Error: "connection refused"})},
+	})
+	if len(results) != 2 || results[0].name != "prod" || results[1].name != "staging" {
+		t.Fatalf("expected results in instance order, got %+v", results)
+	}
+
+	table := formatInstanceHealth(results)
+	for _, want := range []string{
+		"| prod | https://dojo.example.com | ✅ healthy | 42ms | 2.38.1 | bot |",
+		"| staging | https://dojo-staging.example.com | ❌ connection refused | - | - | - |",
+	} {
+		if !strings.Contains(table, want) {
+			t.Errorf("expected %q in:\n%s", want, table)
+		}
+	}
+}
+
+func TestHealthCheckInstances(t *testing.T) {
+	var mu sync.Mutex
+	var authorizations []string
+	staging := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		authorizations = append(authorizations, r.Header.Get("Authorization"))
+		mu.Unlock()
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer staging.Close()
+
+	mock := &MockDefectDojoClient{CheckHealthFunc: func(ctx context.Context) *types.HealthStatus {
+		return &types.HealthStatus{URL: "https://dojo.example.com", Reachable: true, Authenticated: true, User: "bot"}
+	}}
+	s := newServer(&Config{DefectDojo: DefectDojoConfig{
+		Credentials: []Credential{{Name: "payments", APIKey: "payments-key", Products: []int{1}}},
+		Instances:   []Instance{{Name: "staging", BaseURL: staging.URL, APIKey: "staging-key"}},
+	}}, mock)
+	defer s.Close()
+
+	_, err := callTool(t, s, toolHealthCheck, map[string]any{})
+	if err == nil {
+		t.Fatal("expected the unauthenticated staging instance to fail the health check")
+	}
+	for _, want := range []string{
+		"failed for 1 of 2 instances",
+		"| default | https://dojo.example.com | ✅ healthy |",
+		"| staging | " + staging.URL + " | ❌ ",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in:\n%v", want, err)
+		}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(authorizations) == 0 || slices.ContainsFunc(authorizations, func(header string) bool { return header != "Token staging-key" }) {
+		t.Errorf("expected the staging instance to be probed with its own token, got %q", authorizations)
+	}
+
+	offline := newServer(&Config{DefectDojo: DefectDojoConfig{Mode: "offline", Instances: []Instance{{Name: "staging", BaseURL: staging.URL, APIKey: "staging-key"}}}}, mock)
+	if result, err := callTool(t, offline, toolHealthCheck, map[string]any{}); err != nil || strings.Contains(resultText(result), "staging") {
+		t.Errorf("expected offline mode not to probe further instances, got %v %v", resultText(result), err)
+	}
+}
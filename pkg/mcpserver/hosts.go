@@ -0,0 +1,198 @@
+package mcpserver
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+// Findings by host sizing
+const (
+	endpointStatusPageSize = 250
+	maxEndpointStatuses    = 10000 // Endpoint statuses read per call
+	defaultHostLimit       = 25
+	maxHostLimit           = 500
+)
+
+// hostFindings is one host's entry in get_findings_by_host
+type hostFindings struct {
+	Host       string         `json:"host"`
+	Endpoints  []string       `json:"endpoints"` // Affected URLs on the host
+	Findings   int            `json:"findings"`  // Distinct active findings
+	BySeverity severityCounts `json:"by_severity"`
+	FindingIDs []int          `json:"finding_ids"` // Most severe first
+	Worst      *jsonFinding   `json:"worst_finding,omitempty"`
+}
+
+// hostAggregate collects one host's findings while the statuses are read
+type hostAggregate struct {
+	endpoints map[int]types.Endpoint
+	findings  map[int]types.Finding
+}
+
+// collectHostFindings pages through the open endpoint statuses and groups the
+// active findings by endpoint host. A finding affecting several URLs of one
+// host counts once for it. Statuses whose endpoint or finding was not
+// prefetched are counted in skipped.
+func (s *Server) collectHostFindings(ctx context.Context, product *int, minSeverity string) (hosts map[string]*hostAggregate, total, skipped int, err error) {
+	open := false
+	filter := types.EndpointStatusFilter{
+		Mitigated:     &open,
+		FalsePositive: &open,
+		OutOfScope:    &open,
+		RiskAccepted:  &open,
+		Limit:         endpointStatusPageSize,
+		Prefetch:      []string{types.PrefetchEndpoint, types.PrefetchFinding},
+	}
+	hosts = map[string]*hostAggregate{}
+	for filter.Offset < maxEndpointStatuses {
+		if err := budgetFrom(ctx).spendPage(); err != nil {
+			return nil, 0, 0, err
+		}
+		page, err := s.ddClient.ListEndpointStatuses(ctx, filter)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		total = page.Count
+		prefetch := page.Prefetch
+		if prefetch == nil {
+			prefetch = &types.EndpointStatusPrefetch{}
+		}
+		for _, status := range page.Results {
+			endpoint, hasEndpoint := prefetch.Endpoints[status.Endpoint]
+			finding, hasFinding := prefetch.Findings[status.Finding]
+			if !hasEndpoint || !hasFinding {
+				skipped++
+				continue
+			}
+			if !finding.Active || (product != nil && endpoint.Product != *product) {
+				continue
+			}
+			if minSeverity != "" && !types.SeverityAtOrAbove(finding.Severity, minSeverity) {
+				continue
+			}
+			host := strings.ToLower(cmp.Or(endpoint.Host, endpoint.String()))
+			aggregate := hosts[host]
+			if aggregate == nil {
+				aggregate = &hostAggregate{endpoints: map[int]types.Endpoint{}, findings: map[int]types.Finding{}}
+				hosts[host] = aggregate
+			}
+			aggregate.endpoints[endpoint.ID] = endpoint
+			aggregate.findings[finding.ID] = finding
+		}
+		filter.Offset += len(page.Results)
+		if page.Next == nil || len(page.Results) == 0 {
+			break
+		}
+	}
+	return hosts, total, skipped, nil
+}
+
+// summarizeHost turns a host's findings into its entry, most severe finding first
+func (s *Server) summarizeHost(host string, aggregate *hostAggregate) hostFindings {
+	findings := slices.SortedFunc(maps.Values(aggregate.findings), func(a, b types.Finding) int {
+		return cmp.Or(types.CompareSeverity(b.Severity, a.Severity), cmp.Compare(a.ID, b.ID))
+	})
+	entry := hostFindings{Host: host, Findings: len(findings), FindingIDs: []int{}}
+	for _, endpoint := range aggregate.endpoints {
+		entry.Endpoints = append(entry.Endpoints, endpoint.String())
+	}
+	slices.Sort(entry.Endpoints)
+	for _, finding := range findings {
+		entry.BySeverity.add(finding.Severity, 1)
+		entry.FindingIDs = append(entry.FindingIDs, finding.ID)
+	}
+	if len(findings) > 0 {
+		worst := newJSONFinding(findings[0], s.links, s.severity)
+		entry.Worst = &worst
+	}
+	return entry
+}
+
+// compareHosts orders hosts by their most severe findings, then by name
+func compareHosts(a, b hostFindings) int {
+	return cmp.Or(a.BySeverity.compare(b.BySeverity), strings.Compare(a.Host, b.Host))
+}
+
+// getFindingsByHost handles get_findings_by_host
+func (s *Server) getFindingsByHost(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	limit := request.GetInt("max_hosts", defaultHostLimit)
+	if limit < 1 || limit > maxHostLimit {
+		return nil, fmt.Errorf("invalid max_hosts %d: must be between 1 and %d", limit, maxHostLimit)
+	}
+	minSeverity, err := s.severityArgument(request, "min_severity")
+	if err != nil {
+		return nil, err
+	}
+	var product *int
+	if id := request.GetInt("product", 0); id > 0 {
+		product = &id
+	}
+
+	aggregates, total, skipped, err := s.collectHostFindings(ctx, product, minSeverity)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving endpoint statuses: %w", err)
+	}
+	hosts := make([]hostFindings, 0, len(aggregates))
+	for host, aggregate := range aggregates {
+		hosts = append(hosts, s.summarizeHost(host, aggregate))
+	}
+	slices.SortFunc(hosts, compareHosts)
+	shown := hosts[:min(limit, len(hosts))]
+
+	var notes []string
+	if len(hosts) > len(shown) {
+		notes = append(notes, fmt.Sprintf("Showing the %d most affected of %d hosts; raise max_hosts to see more.", len(shown), len(hosts)))
+	}
+	if total > maxEndpointStatuses {
+		notes = append(notes, fmt.Sprintf("Only the first %d of %d open endpoint statuses were read; narrow the query with product or min_severity.", maxEndpointStatuses, total))
+	}
+	if skipped > 0 {
+		notes = append(notes, fmt.Sprintf("%d endpoint statuses were skipped because DefectDojo did not return their endpoint or finding.", skipped))
+	}
+
+	if s.outputFormat(request) == formatJSON {
+		output, err := marshalOutput(struct {
+			Hosts      []hostFindings `json:"hosts"`
+			TotalHosts int            `json:"total_hosts"`
+			Notes      []string       `json:"notes,omitempty"`
+		}{shown, len(hosts), notes})
+		if err != nil {
+			return nil, err
+		}
+		return mcp.NewToolResultText(output), nil
+	}
+
+	if len(hosts) == 0 {
+		return mcp.NewToolResultText("No hosts with active findings\n" + strings.Join(notes, "\n")), nil
+	}
+	var result strings.Builder
+	fmt.Fprintf(&result, "%d hosts with active findings, most affected first\n", len(hosts))
+	for _, host := range shown {
+		fmt.Fprintf(&result, "\n%s: %d findings (%s)\n", host.Host, host.Findings, host.BySeverity)
+		fmt.Fprintf(&result, "  Endpoints (%d): %s\n", len(host.Endpoints), strings.Join(host.Endpoints, ", "))
+		if host.Worst != nil {
+			fmt.Fprintf(&result, "  Worst: [%s] %s (ID: %d)", s.severity.display(host.Worst.Severity), host.Worst.Title, host.Worst.ID)
+			if host.Worst.URL != "" {
+				fmt.Fprintf(&result, " — %s", host.Worst.URL)
+			}
+			result.WriteString("\n")
+		}
+		ids := make([]string, len(host.FindingIDs))
+		for i, id := range host.FindingIDs {
+			ids[i] = fmt.Sprintf("%d", id)
+		}
+		fmt.Fprintf(&result, "  Finding IDs: %s\n", strings.Join(ids, ", "))
+	}
+	for _, note := range notes {
+		fmt.Fprintf(&result, "\n%s\n", note)
+	}
+	return mcp.NewToolResultText(result.String()), nil
+}
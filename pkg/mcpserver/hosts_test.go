@@ -0,0 +1,96 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/brduru/mcp-defect-dojo/internal/defectdojo"
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+func TestGetFindingsByHost(t *testing.T) {
+	fixtures, err := defectdojo.NewFixtureClient("")
+	if err != nil {
+		t.Fatalf("NewFixtureClient() error = %v", err)
+	}
+	s := newServer(&Config{}, fixtures)
+
+	result, err := callTool(t, s, "get_findings_by_host", map[string]any{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text := resultText(result)
+	for _, want := range []string{
+		"2 hosts with active findings",
+		"pay.example.com: 2 findings (1 critical, 1 high)",
+		"Endpoints (2): https://pay.example.com/api/v1/payments/search, https://pay.example.com/checkout",
+		"Worst: [Critical] SQL Injection in /api/v1/payments/search (ID: 1)",
+		"portal.example.com: 1 findings (1 medium)",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in:\n%s", want, text)
+		}
+	}
+	if strings.Index(text, "pay.example.com") > strings.Index(text, "portal.example.com") {
+		t.Errorf("expected the most affected host first:\n%s", text)
+	}
+
+	result, err = callTool(t, s, "get_findings_by_host", map[string]any{"min_severity": "high", "max_hosts": 1, "format": "json"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var output struct {
+		Hosts      []hostFindings `json:"hosts"`
+		TotalHosts int            `json:"total_hosts"`
+	}
+	if err := json.Unmarshal([]byte(resultText(result)), &output); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if output.TotalHosts != 1 || len(output.Hosts) != 1 || output.Hosts[0].FindingIDs[0] != 1 || output.Hosts[0].BySeverity.High != 1 {
+		t.Errorf("expected only pay.example.com at High and above, got %+v", output)
+	}
+
+	result, err = callTool(t, s, "get_findings_by_host", map[string]any{"product": 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := resultText(result); !strings.Contains(text, "1 hosts") || strings.Contains(text, "pay.example.com") {
+		t.Errorf("expected only the product's hosts, got:\n%s", text)
+	}
+}
+
+func TestGetFindingsByHostPartialData(t *testing.T) {
+	mock := &MockDefectDojoClient{
+		ListEndpointStatusesFunc: func(ctx context.Context, filter types.EndpointStatusFilter) (*types.EndpointStatusesResponse, error) {
+			if filter.Mitigated == nil || *filter.Mitigated || filter.RiskAccepted == nil || *filter.RiskAccepted {
+				t.Errorf("expected only open statuses to be requested, got %+v", filter)
+			}
+			return &types.EndpointStatusesResponse{
+				Count: 3,
+				Results: []types.EndpointStatus{
+					{ID: 1, Endpoint: 1, Finding: 10},
+					{ID: 2, Endpoint: 2, Finding: 10},
+					{ID: 3, Endpoint: 3, Finding: 11},
+				},
+				Prefetch: &types.EndpointStatusPrefetch{
+					Endpoints: map[int]types.Endpoint{1: {ID: 1, Host: "DB01.corp"}, 2: {ID: 2, Host: "db01.corp", Path: "/admin"}},
+					Findings:  map[int]types.Finding{10: {ID: 10, Title: "Weak TLS", Severity: "Low", Active: true}},
+				},
+			}, nil
+		},
+	}
+	s := newServer(&Config{}, mock)
+
+	result, err := callTool(t, s, "get_findings_by_host", map[string]any{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text := resultText(result)
+	for _, want := range []string{"db01.corp: 1 findings (1 low)", "Endpoints (2)", "1 endpoint statuses were skipped"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in:\n%s", want, text)
+		}
+	}
+}
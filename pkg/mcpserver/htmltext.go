@@ -0,0 +1,307 @@
+package mcpserver
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+// Handling of HTML in finding text sections
+const (
+	htmlMarkdown = "markdown" // Convert to text with Markdown markup, the default
+	htmlText     = "text"     // Convert to plain text without markup
+	htmlRaw      = "raw"      // Leave the HTML as DefectDojo stores it
+)
+
+// htmlModes returns the accepted HTML handling values
+func htmlModes() []string {
+	return []string{htmlMarkdown, htmlText, htmlRaw}
+}
+
+var (
+	// htmlMarkup detects the formatting tags scanners write into descriptions.
+	// Text without them is left alone, so a payload quoted as evidence, such
+	// as <script>alert(1)</script>, is not mistaken for markup.
+	htmlMarkup = regexp.MustCompile(`(?i)<(p|div|span|br|hr|ul|ol|li|a|b|strong|em|i|u|code|pre|h[1-6]|table|thead|tbody|tr|td|th|blockquote|font|img)\b[^>]*>`)
+	// extraBlankLines collapses the blank lines left by nested blocks
+	extraBlankLines = regexp.MustCompile(`\n{3,}`)
+)
+
+// convertHTML converts the HTML in a finding's text sections for output,
+// returning a copy
+func (opts formatOptions) convertHTML(finding types.Finding) types.Finding {
+	if opts.html == htmlRaw {
+		return finding
+	}
+	for _, field := range []*string{
+		&finding.Description, &finding.Mitigation, &finding.Impact,
+		&finding.References, &finding.StepsToReproduce,
+	} {
+		*field = htmlToText(*field, opts.html != htmlText)
+	}
+	return finding
+}
+
+// convertFindingsHTML is convertHTML for a findings page
+func (opts formatOptions) convertFindingsHTML(response *types.FindingsResponse) *types.FindingsResponse {
+	if opts.html == htmlRaw {
+		return response
+	}
+	converted := *response
+	converted.Results = make([]types.Finding, len(response.Results))
+	for i := range response.Results {
+		converted.Results[i] = opts.convertHTML(response.Results[i])
+	}
+	return &converted
+}
+
+// htmlToText renders HTML as readable text: paragraphs, line breaks, lists,
+// headings and table rows become lines, and entities are decoded. With
+// markdown, emphasis, code and headings keep Markdown markup. Links show
+// their target after the text rather than as Markdown links, so the target
+// stays visible. Tags it does not know, such as script, are kept verbatim.
+// Text without formatting tags is returned unchanged.
+func htmlToText(text string, markdown bool) string {
+	if !htmlMarkup.MatchString(text) {
+		return text
+	}
+	r := htmlRenderer{markdown: markdown}
+	tokenizer := html.NewTokenizer(strings.NewReader(text))
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return r.String()
+		case html.TextToken:
+			r.text(string(tokenizer.Text()))
+		case html.StartTagToken, html.SelfClosingTagToken:
+			if !r.start(tokenizer.Token()) {
+				r.out = append(r.out, tokenizer.Raw()...)
+			}
+		case html.EndTagToken:
+			if !r.end(tokenizer.Token()) {
+				r.out = append(r.out, tokenizer.Raw()...)
+			}
+		default:
+			r.out = append(r.out, tokenizer.Raw()...)
+		}
+	}
+}
+
+// htmlRenderer accumulates the text of an HTML document
+type htmlRenderer struct {
+	markdown bool
+	out      []byte
+
+	pre   int        // Depth of <pre> elements, whose whitespace is kept
+	lists []int      // Open lists: -1 for bulleted, otherwise the last item number
+	links []htmlLink // Open links
+	cells int        // Cells started in the current table row
+}
+
+// htmlLink is an open <a> element
+type htmlLink struct {
+	href  string
+	start int // Position of the link text in the output
+}
+
+// text writes a text token, collapsing whitespace outside <pre>
+func (r *htmlRenderer) text(text string) {
+	if r.pre > 0 {
+		r.write(text)
+		return
+	}
+	collapsed := strings.Join(strings.Fields(text), " ")
+	if collapsed == "" {
+		if text != "" && !r.atLineStart() {
+			r.write(" ")
+		}
+		return
+	}
+	if isSpace(rune(text[0])) && !r.atLineStart() {
+		collapsed = " " + collapsed
+	}
+	if isSpace(rune(text[len(text)-1])) {
+		collapsed += " "
+	}
+	r.write(collapsed)
+}
+
+// start renders a start tag, reporting whether it is a known one
+func (r *htmlRenderer) start(token html.Token) bool {
+	switch name := token.Data; name {
+	case "p", "div", "blockquote", "table":
+		r.block()
+	case "br":
+		r.line()
+	case "hr":
+		r.block()
+		r.write("---")
+		r.block()
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		r.block()
+		if r.markdown {
+			r.write(strings.Repeat("#", int(name[1]-'0')) + " ")
+		}
+	case "ul":
+		r.line()
+		r.lists = append(r.lists, -1)
+	case "ol":
+		r.line()
+		r.lists = append(r.lists, 0)
+	case "li":
+		r.line()
+		r.write(strings.Repeat("  ", max(len(r.lists)-1, 0)))
+		if n := len(r.lists); n > 0 && r.lists[n-1] >= 0 {
+			r.lists[n-1]++
+			r.writef("%d. ", r.lists[n-1])
+		} else {
+			r.write("- ")
+		}
+	case "tr":
+		r.line()
+		r.cells = 0
+	case "td", "th":
+		if r.cells > 0 {
+			r.write(" | ")
+		}
+		r.cells++
+	case "b", "strong":
+		r.markup("**")
+	case "em", "i":
+		r.markup("_")
+	case "code":
+		if r.pre == 0 {
+			r.markup("`")
+		}
+	case "pre":
+		r.block()
+		r.markup("```\n")
+		r.pre++
+	case "a":
+		r.links = append(r.links, htmlLink{href: htmlAttribute(token, "href"), start: len(r.out)})
+	case "img":
+		if alt := htmlAttribute(token, "alt"); alt != "" {
+			r.writef("[image: %s]", alt)
+		} else {
+			r.write("[image]")
+		}
+	case "span", "font", "u", "thead", "tbody", "html", "body":
+	default:
+		return false
+	}
+	return true
+}
+
+// end renders an end tag, reporting whether it is a known one
+func (r *htmlRenderer) end(token html.Token) bool {
+	switch token.Data {
+	case "p", "div", "blockquote", "table", "h1", "h2", "h3", "h4", "h5", "h6":
+		r.block()
+	case "ul", "ol":
+		if len(r.lists) > 0 {
+			r.lists = r.lists[:len(r.lists)-1]
+		}
+		r.line()
+	case "li", "tr":
+		r.line()
+	case "b", "strong":
+		r.markup("**")
+	case "em", "i":
+		r.markup("_")
+	case "code":
+		if r.pre == 0 {
+			r.markup("`")
+		}
+	case "pre":
+		r.pre = max(r.pre-1, 0)
+		r.line()
+		r.markup("```")
+		r.block()
+	case "a":
+		if n := len(r.links); n > 0 {
+			open := r.links[n-1]
+			r.links = r.links[:n-1]
+			label := strings.TrimSpace(string(r.out[open.start:]))
+			if open.href != "" && open.href != label && !strings.HasPrefix(open.href, "#") {
+				r.writef(" <%s>", open.href)
+			}
+		}
+	case "span", "font", "u", "thead", "tbody", "td", "th", "html", "body":
+	default:
+		return false
+	}
+	return true
+}
+
+// write appends text to the output
+func (r *htmlRenderer) write(s string) {
+	r.out = append(r.out, s...)
+}
+
+// writef appends formatted text to the output
+func (r *htmlRenderer) writef(format string, args ...any) {
+	r.out = fmt.Appendf(r.out, format, args...)
+}
+
+// markup writes Markdown markup, when rendering Markdown
+func (r *htmlRenderer) markup(s string) {
+	if r.markdown {
+		r.write(s)
+	}
+}
+
+// line ends the current line, unless nothing was written on it
+func (r *htmlRenderer) line() {
+	if !r.atLineStart() {
+		r.trimSpace()
+		r.write("\n")
+	}
+}
+
+// block separates a block from what came before with a blank line
+func (r *htmlRenderer) block() {
+	r.line()
+	if n := len(r.out); n > 1 && r.out[n-2] != '\n' {
+		r.write("\n")
+	}
+}
+
+// atLineStart reports whether the output is empty or ends with a newline
+func (r *htmlRenderer) atLineStart() bool {
+	return len(r.out) == 0 || r.out[len(r.out)-1] == '\n'
+}
+
+// trimSpace drops spaces at the end of the output
+func (r *htmlRenderer) trimSpace() {
+	for len(r.out) > 0 && r.out[len(r.out)-1] == ' ' {
+		r.out = r.out[:len(r.out)-1]
+	}
+}
+
+// String returns the rendered text without extra blank lines
+func (r *htmlRenderer) String() string {
+	lines := strings.Split(string(r.out), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	return strings.TrimSpace(extraBlankLines.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+}
+
+// htmlAttribute returns an attribute of an HTML tag, or ""
+func htmlAttribute(token html.Token, name string) string {
+	for _, attr := range token.Attr {
+		if attr.Key == name {
+			return attr.Val
+		}
+	}
+	return ""
+}
+
+// isSpace reports whether r is HTML whitespace
+func isSpace(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\n' || r == '\r' || r == '\f'
+}
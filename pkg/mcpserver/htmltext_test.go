@@ -0,0 +1,101 @@
+package mcpserver
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+func TestHTMLToText(t *testing.T) {
+	tests := []struct {
+		name, html, markdown, text string
+	}{
+		{
+			name:     "not HTML",
+			html:     "Reflected XSS via <script>alert(1)</script> in q.\n\nSecond paragraph.",
+			markdown: "Reflected XSS via <script>alert(1)</script> in q.\n\nSecond paragraph.",
+		},
+		{
+			name:     "paragraphs and emphasis",
+			html:     "<div><p>The <b>session</b> cookie\n   lacks <em>HttpOnly</em>.</p><p>Set it &amp; retest.<br>Then close.</p></div>",
+			markdown: "The **session** cookie lacks _HttpOnly_.\n\nSet it & retest.\nThen close.",
+			text:     "The session cookie lacks HttpOnly.\n\nSet it & retest.\nThen close.",
+		},
+		{
+			name:     "lists and headings",
+			html:     "<h3>Fix</h3><ol><li>Upgrade <code>lodash</code></li><li>Redeploy<ul><li>staging</li><li>production</li></ul></li></ol>",
+			markdown: "### Fix\n\n1. Upgrade `lodash`\n2. Redeploy\n  - staging\n  - production",
+			text:     "Fix\n\n1. Upgrade lodash\n2. Redeploy\n  - staging\n  - production",
+		},
+		{
+			name:     "links and images",
+			html:     `<p>See <a href="https://nvd.nist.gov/vuln/detail/CVE-2021-44228">the advisory</a>, <a href="https://owasp.org">https://owasp.org</a> and <img src="x.png" alt="diagram"></p>`,
+			markdown: "See the advisory <https://nvd.nist.gov/vuln/detail/CVE-2021-44228>, https://owasp.org and [image: diagram]",
+		},
+		{
+			name:     "preformatted and tables",
+			html:     "<p>Request:</p><pre>GET /?q=&lt;x&gt; HTTP/1.1\n  Host: a</pre><table><tr><th>Param</th><th>Value</th></tr><tr><td>q</td><td>&lt;x&gt;</td></tr></table>",
+			markdown: "Request:\n\n```\nGET /?q=<x> HTTP/1.1\n  Host: a\n```\n\nParam | Value\nq | <x>",
+			text:     "Request:\n\nGET /?q=<x> HTTP/1.1\n  Host: a\n\nParam | Value\nq | <x>",
+		},
+		{
+			name:     "unknown tags kept",
+			html:     "<p>Payload: <script>steal()</script></p>",
+			markdown: "Payload: <script>steal()</script>",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := htmlToText(tt.html, true); got != tt.markdown {
+				t.Errorf("markdown:\n got %q\nwant %q", got, tt.markdown)
+			}
+			want := tt.text
+			if want == "" {
+				want = tt.markdown
+			}
+			if got := htmlToText(tt.html, false); got != want {
+				t.Errorf("text:\n got %q\nwant %q", got, want)
+			}
+		})
+	}
+}
+
+func TestHTMLContentOutput(t *testing.T) {
+	finding := types.Finding{
+		ID: 3, Title: "Missing HttpOnly", Severity: types.SeverityLow, Active: true,
+		Description: "<p>The <b>session</b> cookie lacks HttpOnly.</p>",
+		Mitigation:  "<ul><li>Set <code>HttpOnly</code></li></ul>",
+	}
+	mock := &MockDefectDojoClient{
+		GetFindingsFunc: func(ctx context.Context, filter types.FindingsFilter) (*types.FindingsResponse, error) {
+			return &types.FindingsResponse{Count: 1, Results: []types.Finding{finding}}, nil
+		},
+		GetFindingDetailPrefetchFunc: func(ctx context.Context, findingID int, prefetch []string) (*types.FindingDetail, error) {
+			return &types.FindingDetail{Finding: finding}, nil
+		},
+	}
+
+	s := newServer(&Config{}, mock)
+	result, err := callTool(t, s, toolGetFindings, nil)
+	if err != nil || !strings.Contains(resultText(result), "Description: The **session** cookie lacks HttpOnly.") {
+		t.Errorf("expected the description converted to Markdown, got %v:\n%s", err, resultText(result))
+	}
+	result, err = callTool(t, s, "get_finding_detail", map[string]any{"finding_id": 3, "format": "json"})
+	if err != nil || !strings.Contains(resultText(result), `"mitigation": "- Set `+"`HttpOnly`"+`"`) {
+		t.Errorf("expected the JSON mitigation converted, got %v:\n%s", err, resultText(result))
+	}
+
+	s = newServer(&Config{Output: OutputConfig{HTMLContent: htmlText}}, mock)
+	result, _ = callTool(t, s, "get_finding_detail", map[string]any{"finding_id": 3})
+	if text := resultText(result); !strings.Contains(text, "The session cookie lacks HttpOnly.") || !strings.Contains(text, "- Set HttpOnly") {
+		t.Errorf("expected plain text sections in:\n%s", text)
+	}
+
+	s = newServer(&Config{Output: OutputConfig{HTMLContent: htmlRaw}}, mock)
+	result, _ = callTool(t, s, "get_finding_detail", map[string]any{"finding_id": 3})
+	if !strings.Contains(resultText(result), "<p>The <b>session</b> cookie lacks HttpOnly.</p>") {
+		t.Errorf("expected the raw HTML in:\n%s", resultText(result))
+	}
+}
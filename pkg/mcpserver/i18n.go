@@ -0,0 +1,144 @@
+package mcpserver
+
+import "strings"
+
+// Output languages
+const (
+	languageEnglish    = "en" // The default
+	languagePortuguese = "pt"
+	languageSpanish    = "es"
+)
+
+// outputLanguages returns the accepted language values
+func outputLanguages() []string {
+	return []string{languageEnglish, languagePortuguese, languageSpanish}
+}
+
+// catalog translates the fixed labels and headings of findings output, keyed
+// by their English text. Finding data, severities and machine-read markers
+// such as has_more stay as they are. The zero value is English.
+type catalog map[string]string
+
+// t returns the translation of an English label, or the label itself when the
+// catalog has none
+func (c catalog) t(english string) string {
+	if translated, ok := c[english]; ok {
+		return translated
+	}
+	return english
+}
+
+// catalogs holds the translations of each output language but English
+var catalogs = map[string]catalog{
+	languagePortuguese: {
+		"Found %d findings (showing %d)": "%d achados encontrados (exibindo %d)",
+		"Finding Details (ID: %d)":       "Detalhes do achado (ID: %d)",
+		"Finding %d: %s":                 "Achado %d: %s",
+		"Title":                          "Título",
+		"Severity":                       "Severidade",
+		"Numerical Severity":             "Severidade numérica",
+		"CVSS v3 Score":                  "Pontuação CVSS v3",
+		"CVSS v3 Vector":                 "Vetor CVSS v3",
+		"Vulnerability IDs":              "IDs de vulnerabilidade",
+		"Scoring":                        "Pontuação",
+		"Status":                         "Situação",
+		"Tags":                           "Tags",
+		"Reporter":                       "Relator",
+		"user %d":                        "usuário %d",
+		"Assigned to":                    "Atribuído a",
+		"Product":                        "Produto",
+		"Engagement":                     "Engajamento",
+		"Product / Engagement":           "Produto / Engajamento",
+		"Test":                           "Teste",
+		"Test ID":                        "ID do teste",
+		"Scanner":                        "Scanner",
+		"Environment":                    "Ambiente",
+		"File":                           "Arquivo",
+		"SAST Source Object":             "Objeto de origem SAST",
+		"Component":                      "Componente",
+		"Endpoint IDs":                   "IDs de endpoint",
+		"Discovered":                     "Descoberto",
+		"Created":                        "Criado",
+		"Modified":                       "Modificado",
+		"Mitigated":                      "Mitigado",
+		"Age":                            "Idade",
+		"%d days":                        "%d dias",
+		"SLA Expiration":                 "Vencimento do SLA",
+		"SLA Days Remaining":             "Dias restantes do SLA",
+		"overdue by %d days":             "atrasado em %d dias",
+		"Description":                    "Descrição",
+		"Impact":                         "Impacto",
+		"Mitigation":                     "Mitigação",
+		"Steps to Reproduce":             "Passos para reproduzir",
+		"References":                     "Referências",
+		"Active":                         "Ativo",
+		"Inactive":                       "Inativo",
+		"Verified":                       "Verificado",
+		"False Positive":                 "Falso positivo",
+		"Risk Accepted":                  "Risco aceito",
+		"Out of Scope":                   "Fora do escopo",
+		"Under Review":                   "Em revisão",
+		"Duplicate":                      "Duplicado",
+		"%d occurrences":                 "%d ocorrências",
+	},
+	languageSpanish: {
+		"Found %d findings (showing %d)": "%d hallazgos encontrados (mostrando %d)",
+		"Finding Details (ID: %d)":       "Detalles del hallazgo (ID: %d)",
+		"Finding %d: %s":                 "Hallazgo %d: %s",
+		"Title":                          "Título",
+		"Severity":                       "Severidad",
+		"Numerical Severity":             "Severidad numérica",
+		"CVSS v3 Score":                  "Puntuación CVSS v3",
+		"CVSS v3 Vector":                 "Vector CVSS v3",
+		"Vulnerability IDs":              "IDs de vulnerabilidad",
+		"Scoring":                        "Puntuación",
+		"Status":                         "Estado",
+		"Tags":                           "Etiquetas",
+		"Reporter":                       "Informante",
+		"user %d":                        "usuario %d",
+		"Assigned to":                    "Asignado a",
+		"Product":                        "Producto",
+		"Engagement":                     "Compromiso",
+		"Product / Engagement":           "Producto / Compromiso",
+		"Test":                           "Prueba",
+		"Test ID":                        "ID de prueba",
+		"Scanner":                        "Escáner",
+		"Environment":                    "Entorno",
+		"File":                           "Archivo",
+		"SAST Source Object":             "Objeto de origen SAST",
+		"Component":                      "Componente",
+		"Endpoint IDs":                   "IDs de endpoint",
+		"Discovered":                     "Descubierto",
+		"Created":                        "Creado",
+		"Modified":                       "Modificado",
+		"Mitigated":                      "Mitigado",
+		"Age":                            "Antigüedad",
+		"%d days":                        "%d días",
+		"SLA Expiration":                 "Vencimiento del SLA",
+		"SLA Days Remaining":             "Días restantes del SLA",
+		"overdue by %d days":             "vencido hace %d días",
+		"Description":                    "Descripción",
+		"Impact":                         "Impacto",
+		"Mitigation":                     "Mitigación",
+		"Steps to Reproduce":             "Pasos para reproducir",
+		"References":                     "Referencias",
+		"Active":                         "Activo",
+		"Inactive":                       "Inactivo",
+		"Verified":                       "Verificado",
+		"False Positive":                 "Falso positivo",
+		"Risk Accepted":                  "Riesgo aceptado",
+		"Out of Scope":                   "Fuera de alcance",
+		"Under Review":                   "En revisión",
+		"Duplicate":                      "Duplicado",
+		"%d occurrences":                 "%d ocurrencias",
+	},
+}
+
+// join translates labels and joins them with commas
+func (c catalog) join(english []string) string {
+	translated := make([]string, len(english))
+	for i, label := range english {
+		translated[i] = c.t(label)
+	}
+	return strings.Join(translated, ", ")
+}
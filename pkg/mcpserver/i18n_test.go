@@ -0,0 +1,78 @@
+package mcpserver
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+func TestCatalogsTranslateEveryLabel(t *testing.T) {
+	for _, language := range outputLanguages() {
+		if language == languageEnglish {
+			continue
+		}
+		for english := range catalogs[languagePortuguese] {
+			if _, ok := catalogs[language][english]; !ok {
+				t.Errorf("%s: no translation of %q", language, english)
+			}
+		}
+		for english, translated := range catalogs[language] {
+			if strings.Count(english, "%") != strings.Count(translated, "%") {
+				t.Errorf("%s: %q and %q have different verbs", language, english, translated)
+			}
+		}
+	}
+}
+
+func TestTranslatedFindingOutput(t *testing.T) {
+	age := 12
+	response := &types.FindingsResponse{Count: 1, Results: []types.Finding{
+		{ID: 1, Title: "SQL injection", Severity: "Critical", Active: true, RiskAccepted: true, Description: "Unsanitized input", AgeDays: &age},
+	}}
+
+	list := formatFindingsList(response, formatOptions{text: catalogs[languagePortuguese]})
+	for _, want := range []string{"1 achados encontrados (exibindo 1):", "1. [Critical] SQL injection (ID: 1)", "Ativo: true, Verificado: false, Falso positivo: false", "Situação: Risco aceito", "Descrição: Unsanitized input"} {
+		if !strings.Contains(list, want) {
+			t.Errorf("list missing %q:\n%s", want, list)
+		}
+	}
+
+	table := markdownFindingsList(response, formatOptions{text: catalogs[languageSpanish]})
+	for _, want := range []string{"**1 hallazgos encontrados (mostrando 1)**", "| ID | Severidad | Título | Estado | Antigüedad |", "| Activo, Riesgo aceptado | 12d |"} {
+		if !strings.Contains(table, want) {
+			t.Errorf("table missing %q:\n%s", want, table)
+		}
+	}
+
+	detail := markdownFindingDetail(&response.Results[0], formatOptions{text: catalogs[languageSpanish]})
+	for _, want := range []string{"## Hallazgo 1: SQL injection", "- **Severidad:** Critical", "- **Antigüedad:** 12 días", "### Descripción"} {
+		if !strings.Contains(detail, want) {
+			t.Errorf("detail missing %q:\n%s", want, detail)
+		}
+	}
+}
+
+func TestLanguageArgument(t *testing.T) {
+	s := newServer(&Config{Output: OutputConfig{Language: languageSpanish}}, &MockDefectDojoClient{})
+
+	result, err := callTool(t, s, toolFindingDetail, map[string]any{"finding_id": 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := resultText(result); !strings.Contains(text, "Detalles del hallazgo (ID: 1):") || !strings.Contains(text, "Severidad: High") {
+		t.Errorf("expected the configured language, got:\n%s", text)
+	}
+
+	result, err = callTool(t, s, toolFindingDetail, map[string]any{"finding_id": 1, "language": "pt"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := resultText(result); !strings.Contains(text, "Detalhes do achado (ID: 1):") {
+		t.Errorf("expected the call's language to win, got:\n%s", text)
+	}
+
+	if _, err := callTool(t, s, toolGetFindings, map[string]any{"language": "fr"}); err == nil || !strings.Contains(err.Error(), "language must be one of en, pt, es") {
+		t.Errorf("expected an unsupported language to be rejected, got %v", err)
+	}
+}
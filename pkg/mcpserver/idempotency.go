@@ -0,0 +1,172 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/brduru/mcp-defect-dojo/internal/logfields"
+)
+
+// defaultIdempotencyWindow is how long a write result is replayed when not configured
+const defaultIdempotencyWindow = 2 * time.Minute
+
+// metaIdempotencyKey is the request _meta key through which clients name a
+// write explicitly, so a retry is recognized even if its arguments changed
+const metaIdempotencyKey = "idempotencyKey"
+
+// idempotencyCache remembers recent successful write calls so a retried call
+// returns the earlier result instead of writing again
+type idempotencyCache struct {
+	window time.Duration
+	now    func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*idempotentCall
+}
+
+// idempotentCall is a write call in flight or completed within the window
+type idempotentCall struct {
+	done     chan struct{} // Closed when the call completes
+	result   *mcp.CallToolResult
+	ok       bool      // Whether the call succeeded and may be replayed
+	finished time.Time // When the call completed
+	findings []int     // Findings the call writes to
+}
+
+func newIdempotencyCache(window time.Duration) *idempotencyCache {
+	return &idempotencyCache{window: window, now: time.Now, entries: map[string]*idempotentCall{}}
+}
+
+// idempotencyKey identifies a write call: the tool with the client's
+// idempotency key if it sent one, else the tool with its arguments
+func idempotencyKey(request mcp.CallToolRequest) (string, error) {
+	if meta := request.Params.Meta; meta != nil {
+		if key, ok := meta.AdditionalFields[metaIdempotencyKey].(string); ok && key != "" {
+			return request.Params.Name + "\x00key\x00" + key, nil
+		}
+	}
+	// encoding/json sorts map keys, so equal arguments encode equally
+	arguments, err := json.Marshal(request.GetArguments())
+	if err != nil {
+		return "", err
+	}
+	return request.Params.Name + "\x00args\x00" + string(arguments), nil
+}
+
+// begin returns the earlier call with the same key, waiting for it if it is
+// still running, or registers a new call that the caller must finish
+func (c *idempotencyCache) begin(ctx context.Context, key string, findings []int) (earlier *idempotentCall, call *idempotentCall, err error) {
+	for {
+		c.mu.Lock()
+		c.expire()
+		existing, found := c.entries[key]
+		if !found {
+			call := &idempotentCall{done: make(chan struct{}), findings: findings}
+			c.entries[key] = call
+			c.mu.Unlock()
+			return nil, call, nil
+		}
+		c.mu.Unlock()
+
+		select {
+		case <-existing.done:
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		}
+		if existing.ok {
+			return existing, nil, nil
+		}
+		// The earlier call failed and was forgotten: try again ourselves
+	}
+}
+
+// finish records the outcome of a call registered by begin. Failed calls are
+// forgotten at once so a retry can run. A successful call forgets the earlier
+// calls that wrote to any of its findings: repeating one of them after this
+// write, e.g. marking a finding false positive again after clearing the mark,
+// is a new change rather than a retry.
+func (c *idempotencyCache) finish(key string, call *idempotentCall, result *mcp.CallToolResult, ok bool) {
+	c.mu.Lock()
+	call.result, call.ok, call.finished = result, ok, c.now()
+	if !ok {
+		delete(c.entries, key)
+	} else if len(call.findings) > 0 {
+		for other, earlier := range c.entries {
+			if earlier == call {
+				continue
+			}
+			select {
+			case <-earlier.done:
+				if slices.ContainsFunc(earlier.findings, func(id int) bool { return slices.Contains(call.findings, id) }) {
+					delete(c.entries, other)
+				}
+			default:
+			}
+		}
+	}
+	c.mu.Unlock()
+	close(call.done)
+}
+
+// expire drops completed calls older than the window; callers hold c.mu
+func (c *idempotencyCache) expire() {
+	cutoff := c.now().Add(-c.window)
+	for key, call := range c.entries {
+		select {
+		case <-call.done:
+			if call.finished.Before(cutoff) {
+				delete(c.entries, key)
+			}
+		default:
+		}
+	}
+}
+
+// idempotencyMiddleware returns the earlier result when a write tool is called
+// again with the same arguments (or the same _meta idempotencyKey) within the
+// window, typically an agent retrying after a transport hiccup, so findings
+// are not patched twice and notes are not duplicated. A retry of a call still
+// running waits for it. Failed writes are never replayed, and another write to
+// the same finding ends the replay of earlier ones.
+func idempotencyMiddleware(cache *idempotencyCache) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if !writeTools[request.Params.Name] {
+				return next(ctx, request)
+			}
+			key, err := idempotencyKey(request)
+			if err != nil {
+				return next(ctx, request)
+			}
+
+			earlier, call, err := cache.begin(ctx, key, policyFindingIDs(request))
+			if err != nil {
+				return nil, err
+			}
+			if earlier != nil {
+				logfields.Printf(ctx, "Duplicate call within %s: returning the earlier result", cache.window)
+				return duplicateResult(earlier), nil
+			}
+
+			result, err := next(ctx, request)
+			cache.finish(key, call, result, err == nil && result != nil && !result.IsError)
+			return result, err
+		}
+	}
+}
+
+// duplicateResult repeats an earlier result with a note that nothing was written again
+func duplicateResult(earlier *idempotentCall) *mcp.CallToolResult {
+	result := *earlier.result
+	result.Content = append(append([]mcp.Content{}, earlier.result.Content...), mcp.NewTextContent(fmt.Sprintf(
+		"\nℹ️ Duplicate call: this is the result of the identical call made at %s; nothing was changed again.",
+		earlier.finished.UTC().Format(time.RFC3339))))
+	return &result
+}
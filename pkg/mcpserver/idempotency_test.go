@@ -0,0 +1,175 @@
+package mcpserver
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+func TestIdempotentWrites(t *testing.T) {
+	writes := 0
+	fail := false
+	mock := &MockDefectDojoClient{
+		MarkFalsePositiveFunc: func(ctx context.Context, findingID int, request types.FalsePositiveRequest) (*types.FalsePositiveResponse, error) {
+			writes++
+			if fail {
+				return nil, errors.New("connection reset")
+			}
+			return &types.FalsePositiveResponse{ID: findingID, FalseP: true, NoteID: writes}, nil
+		},
+	}
+	s := newServer(&Config{}, mock)
+	args := map[string]any{"finding_id": 1, "justification": "test fixture"}
+
+	if _, err := callTool(t, s, "mark_finding_false_positive", args); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	result, err := callTool(t, s, "mark_finding_false_positive", args)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if writes != 1 {
+		t.Errorf("expected the retry to be answered without writing, got %d writes", writes)
+	}
+	if text := resultText(result); !strings.Contains(text, "Duplicate call") {
+		t.Errorf("expected the duplicate to be flagged, got:\n%s", text)
+	}
+
+	if _, err := callTool(t, s, "mark_finding_false_positive", map[string]any{"finding_id": 2, "justification": "test fixture"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if writes != 2 {
+		t.Errorf("expected different arguments to write, got %d writes", writes)
+	}
+
+	// Failed writes are retried for real
+	fail = true
+	args = map[string]any{"finding_id": 3, "justification": "test fixture"}
+	callTool(t, s, "mark_finding_false_positive", args)
+	callTool(t, s, "mark_finding_false_positive", args)
+	if writes != 4 {
+		t.Errorf("expected failed writes not to be replayed, got %d writes", writes)
+	}
+}
+
+func TestIdempotencyAfterOtherWrite(t *testing.T) {
+	var marks []bool
+	mock := &MockDefectDojoClient{
+		MarkFalsePositiveFunc: func(ctx context.Context, findingID int, request types.FalsePositiveRequest) (*types.FalsePositiveResponse, error) {
+			marks = append(marks, request.IsFalsePositive)
+			return &types.FalsePositiveResponse{ID: findingID, FalseP: request.IsFalsePositive}, nil
+		},
+	}
+	s := newServer(&Config{}, mock)
+	mark := map[string]any{"finding_id": 1, "justification": "test fixture"}
+
+	for _, call := range []struct {
+		tool string
+		args map[string]any
+	}{
+		{"mark_finding_false_positive", mark},
+		{"clear_false_positive", map[string]any{"finding_id": 1, "justification": "Confirmed after all"}},
+		{"mark_finding_false_positive", mark},
+	} {
+		result, err := callTool(t, s, call.tool, call.args)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", call.tool, err)
+		}
+		if text := resultText(result); strings.Contains(text, "Duplicate call") {
+			t.Errorf("%s: expected a real write, got a replay:\n%s", call.tool, text)
+		}
+	}
+	if len(marks) != 3 || !marks[0] || marks[1] || !marks[2] {
+		t.Errorf("expected mark, clear and mark to reach DefectDojo, got %v", marks)
+	}
+
+	// Writes to other findings keep the replay
+	if _, err := callTool(t, s, "clear_false_positive", map[string]any{"finding_id": 2, "justification": "Confirmed after all"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result, _ := callTool(t, s, "mark_finding_false_positive", mark); !strings.Contains(resultText(result), "Duplicate call") {
+		t.Errorf("expected the retry to be replayed, got:\n%s", resultText(result))
+	}
+}
+
+func TestIdempotencyDisabled(t *testing.T) {
+	writes := 0
+	mock := &MockDefectDojoClient{
+		MarkFalsePositiveFunc: func(ctx context.Context, findingID int, request types.FalsePositiveRequest) (*types.FalsePositiveResponse, error) {
+			writes++
+			return &types.FalsePositiveResponse{ID: findingID, FalseP: true}, nil
+		},
+	}
+	s := newServer(&Config{Server: ServerConfig{IdempotencyWindow: -1}}, mock)
+	for range 2 {
+		callTool(t, s, "mark_finding_false_positive", map[string]any{"finding_id": 1, "justification": "test fixture"})
+	}
+	if writes != 2 {
+		t.Errorf("expected every call to write with the window disabled, got %d writes", writes)
+	}
+}
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	var writes atomic.Int32
+	release := make(chan struct{})
+	cache := newIdempotencyCache(time.Minute)
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+	handler := idempotencyMiddleware(cache)(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		writes.Add(1)
+		<-release
+		return mcp.NewToolResultText("marked"), nil
+	})
+	call := func(arguments map[string]any, key string) (*mcp.CallToolResult, error) {
+		request := mcp.CallToolRequest{}
+		request.Params.Name = toolMarkFalsePositive
+		request.Params.Arguments = arguments
+		if key != "" {
+			request.Params.Meta = &mcp.Meta{AdditionalFields: map[string]any{metaIdempotencyKey: key}}
+		}
+		return handler(context.Background(), request)
+	}
+
+	// A retry of a call still running waits for it
+	var wg sync.WaitGroup
+	for range 3 {
+		wg.Go(func() { call(map[string]any{"finding_id": 1}, "") })
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if got := writes.Load(); got != 1 {
+		t.Errorf("expected concurrent retries to share one write, got %d", got)
+	}
+
+	// Client keys identify retries even when the arguments differ
+	call(map[string]any{"finding_id": 2, "notes": "first try"}, "retry-42")
+	result, _ := call(map[string]any{"finding_id": 2, "notes": "second try"}, "retry-42")
+	if got := writes.Load(); got != 2 || !strings.Contains(resultText(result), "Duplicate call") {
+		t.Errorf("expected the idempotency key to match, got %d writes", got)
+	}
+
+	// The window expires
+	now = now.Add(2 * time.Minute)
+	call(map[string]any{"finding_id": 1}, "")
+	if got := writes.Load(); got != 3 {
+		t.Errorf("expected a write after the window, got %d writes", got)
+	}
+
+	// Read tools are never deduplicated
+	request := mcp.CallToolRequest{}
+	request.Params.Name = toolGetFindings
+	handler(context.Background(), request)
+	handler(context.Background(), request)
+	if got := writes.Load(); got != 5 {
+		t.Errorf("expected read tools to run every time, got %d calls", got)
+	}
+}
@@ -0,0 +1,287 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/brduru/mcp-defect-dojo/internal/refcache"
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+// Import summary sizing and anomaly thresholds
+const (
+	importHistorySize  = 10  // Earlier imports the last one is compared with
+	minImportBaseline  = 3   // Earlier imports needed before counts are judged
+	minAnomalyFindings = 10  // Counts and changes below this are never flagged
+	importCountRatio   = 5   // Reports this many times smaller or larger than usual are flagged
+	massClosureShare   = 0.5 // Closing more than this share of the test's findings is flagged
+	siblingTestsPage   = 100 // Tests of the engagement searched for the same scanner
+)
+
+// importCounts is what one import did to the findings of its test
+type importCounts struct {
+	Reported    int `json:"reported"` // Findings in the report: created, reactivated or untouched
+	Created     int `json:"created"`
+	Closed      int `json:"closed"`
+	Reactivated int `json:"reactivated"`
+	Untouched   int `json:"untouched"`
+}
+
+// importEntry is one import in get_import_summary
+type importEntry struct {
+	ID         int       `json:"id"`
+	Test       int       `json:"test"`
+	Type       string    `json:"type"` // "import" or "reimport"
+	ImportedAt time.Time `json:"imported_at"`
+	BuildID    string    `json:"build_id,omitempty"`
+	CommitHash string    `json:"commit_hash,omitempty"`
+	BranchTag  string    `json:"branch_tag,omitempty"`
+	Version    string    `json:"version,omitempty"`
+	importCounts
+}
+
+// importBaseline summarizes the reported counts of earlier imports
+type importBaseline struct {
+	Imports        int    `json:"imports"`
+	Source         string `json:"source"` // Which imports were compared with, e.g. "4 earlier imports of this test"
+	MedianReported int    `json:"median_reported"`
+	MinReported    int    `json:"min_reported"`
+	MaxReported    int    `json:"max_reported"`
+}
+
+// importSummary is the result of get_import_summary
+type importSummary struct {
+	Test       types.Test      `json:"test"`
+	Scanner    string          `json:"scanner,omitempty"`
+	URL        string          `json:"url,omitempty"` // DefectDojo UI page of the test
+	LastImport importEntry     `json:"last_import"`
+	Baseline   *importBaseline `json:"baseline,omitempty"`
+	History    []importEntry   `json:"history"` // The earlier imports compared with, newest first
+	Anomalies  []string        `json:"anomalies"`
+	Notes      []string        `json:"notes,omitempty"`
+	Warnings   []string        `json:"warnings,omitempty"` // Parts DefectDojo failed to provide
+}
+
+// newImportEntry counts the finding actions of one import
+func newImportEntry(testImport types.TestImport) importEntry {
+	return importEntry{
+		ID:         testImport.ID,
+		Test:       testImport.Test,
+		Type:       testImport.Type,
+		ImportedAt: testImport.Created,
+		BuildID:    testImport.BuildID,
+		CommitHash: testImport.CommitHash,
+		BranchTag:  testImport.BranchTag,
+		Version:    testImport.Version,
+		importCounts: importCounts{
+			Reported:    testImport.Reported(),
+			Created:     testImport.Count(types.ImportActionCreated),
+			Closed:      testImport.Count(types.ImportActionClosed),
+			Reactivated: testImport.Count(types.ImportActionReactivated),
+			Untouched:   testImport.Count(types.ImportActionUntouched),
+		},
+	}
+}
+
+// importHistory returns the earlier imports the last import of test is
+// compared with: the test's own, topped up with the latest import of other
+// tests of the same scanner in the engagement when the test was imported
+// into too rarely, e.g. by pipelines that create a new test per run. Other
+// tests that cannot be read are recorded in partial and left out.
+func (s *Server) importHistory(ctx context.Context, test *types.Test, earlier []types.TestImport, partial *partialResults) ([]importEntry, string) {
+	history := make([]importEntry, 0, len(earlier))
+	for _, testImport := range earlier {
+		history = append(history, newImportEntry(testImport))
+	}
+	source := fmt.Sprintf("%d earlier imports of this test", len(history))
+	if len(history) >= minImportBaseline || test.TestType == 0 {
+		return history, source
+	}
+
+	siblings, err := s.ddClient.ListTests(ctx, test.Engagement, siblingTestsPage, 0)
+	if err != nil {
+		partial.warnf("other tests of engagement %d unavailable: %v", test.Engagement, err)
+		return history, source
+	}
+	added := 0
+	for _, sibling := range slices.Backward(siblings.Results) {
+		if len(history) >= importHistorySize {
+			break
+		}
+		if sibling.ID == test.ID || sibling.TestType != test.TestType {
+			continue
+		}
+		imports, err := s.ddClient.ListTestImports(ctx, sibling.ID, 1, 0)
+		if err != nil {
+			partial.warnf("import history of test %d unavailable: %v", sibling.ID, err)
+			continue
+		}
+		if len(imports.Results) > 0 {
+			history = append(history, newImportEntry(imports.Results[0]))
+			added++
+		}
+	}
+	if added > 0 {
+		source = fmt.Sprintf("%s and the latest imports of %d other tests of the same scanner in the engagement", source, added)
+	}
+	return history, source
+}
+
+// newImportBaseline summarizes the reported counts of the history, or returns nil for none
+func newImportBaseline(history []importEntry, source string) *importBaseline {
+	if len(history) == 0 {
+		return nil
+	}
+	reported := make([]int, len(history))
+	for i, entry := range history {
+		reported[i] = entry.Reported
+	}
+	slices.Sort(reported)
+	median := reported[len(reported)/2]
+	if len(reported)%2 == 0 {
+		median = (reported[len(reported)/2-1] + median) / 2
+	}
+	return &importBaseline{Imports: len(history), Source: source, MedianReported: median, MinReported: reported[0], MaxReported: reported[len(reported)-1]}
+}
+
+// importAnomalies flags counts of the last import that suggest a broken scan:
+// an empty or much smaller report than usual, a much larger one, or a
+// reimport closing most of the test's findings
+func importAnomalies(last importEntry, baseline *importBaseline) []string {
+	anomalies := []string{}
+	if baseline != nil && baseline.Imports >= minImportBaseline {
+		median := baseline.MedianReported
+		switch {
+		case last.Reported == 0 && median >= minAnomalyFindings:
+			anomalies = append(anomalies, fmt.Sprintf("The report contained no findings, while earlier imports reported %d (median): the scan may have failed, timed out or scanned the wrong target", median))
+		case last.Reported*importCountRatio <= median && median >= minAnomalyFindings:
+			anomalies = append(anomalies, fmt.Sprintf("The report contained %d findings, far fewer than the median of %d of earlier imports: check that the scan covered the whole target", last.Reported, median))
+		case last.Reported >= median*importCountRatio && last.Reported-median >= minAnomalyFindings:
+			anomalies = append(anomalies, fmt.Sprintf("The report contained %d findings, far more than the median of %d of earlier imports: check for a changed scanner configuration or target", last.Reported, median))
+		}
+	}
+	if existing := last.Closed + last.Untouched; last.Closed >= minAnomalyFindings && float64(last.Closed) > massClosureShare*float64(existing) {
+		anomalies = append(anomalies, fmt.Sprintf("The import closed %d of the %d findings the test already had: a partial scan closes every finding it did not report", last.Closed, existing))
+	}
+	return anomalies
+}
+
+// getImportSummary handles get_import_summary
+func (s *Server) getImportSummary(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	testID, err := request.RequireInt("test_id")
+	if err != nil {
+		return nil, fmt.Errorf("invalid test_id: %w", err)
+	}
+	test, err := refcache.Get(s.refs, refKey(refTest, testID), func() (*types.Test, error) {
+		return s.ddClient.GetTest(ctx, testID)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving test %d: %w", testID, err)
+	}
+
+	imports, err := s.ddClient.ListTestImports(ctx, testID, importHistorySize+1, 0)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving import history of test %d: %w", testID, err)
+	}
+	if len(imports.Results) == 0 {
+		return nil, fmt.Errorf("test %d has no import history: DefectDojo records it only for scan imports while import history tracking is enabled", testID)
+	}
+	var partial partialResults
+	history, source := s.importHistory(ctx, test, imports.Results[1:], &partial)
+
+	summary := importSummary{
+		Test:       *test,
+		URL:        s.links.test(testID),
+		LastImport: newImportEntry(imports.Results[0]),
+		Baseline:   newImportBaseline(history, source),
+		History:    history,
+	}
+	summary.Anomalies = importAnomalies(summary.LastImport, summary.Baseline)
+	if test.TestType != 0 {
+		if testType, err := refcache.Get(s.refs, refKey(refTestType, test.TestType), func() (*types.TestType, error) {
+			return s.ddClient.GetTestType(ctx, test.TestType)
+		}); err != nil {
+			partial.warnf("scanner of test %d unavailable: %v", testID, err)
+		} else {
+			summary.Scanner = testType.Name
+		}
+	}
+	if len(history) < minImportBaseline {
+		summary.Notes = append(summary.Notes, fmt.Sprintf("Only %d earlier imports to compare with: too few to tell whether the finding counts are unusual.", len(history)))
+	}
+	summary.Warnings = partial.list()
+
+	if s.outputFormat(request) == formatJSON {
+		output, err := marshalOutput(summary)
+		if err != nil {
+			return nil, err
+		}
+		return mcp.NewToolResultText(output), nil
+	}
+	return mcp.NewToolResultText(formatImportSummary(summary)), nil
+}
+
+// formatImportSummary renders an import summary as text
+func formatImportSummary(summary importSummary) string {
+	var result strings.Builder
+	name := fmt.Sprintf("test %d", summary.Test.ID)
+	if summary.Test.Title != "" {
+		name += " (" + summary.Test.Title + ")"
+	}
+	if summary.Scanner != "" {
+		name += ", " + summary.Scanner
+	}
+	last := summary.LastImport
+	fmt.Fprintf(&result, "Last %s of %s: #%d on %s\n", last.Type, name, last.ID, last.ImportedAt.Format(time.RFC3339))
+	if origin := importOrigin(last); origin != "" {
+		fmt.Fprintf(&result, "From %s\n", origin)
+	}
+	fmt.Fprintf(&result, "\nFindings in the report: %d\n", last.Reported)
+	fmt.Fprintf(&result, "  Created: %d\n  Closed: %d\n  Reactivated: %d\n  Untouched: %d\n", last.Created, last.Closed, last.Reactivated, last.Untouched)
+
+	if baseline := summary.Baseline; baseline != nil {
+		fmt.Fprintf(&result, "\nBaseline: median of %d findings (%d to %d) over %s\n",
+			baseline.MedianReported, baseline.MinReported, baseline.MaxReported, baseline.Source)
+		for _, entry := range summary.History {
+			fmt.Fprintf(&result, "  #%d %s of test %d on %s: %d findings\n", entry.ID, entry.Type, entry.Test, entry.ImportedAt.Format(time.DateOnly), entry.Reported)
+		}
+	}
+
+	if len(summary.Anomalies) == 0 {
+		result.WriteString("\nNo anomalies detected\n")
+	} else {
+		result.WriteString("\n⚠️ Anomalies:\n")
+		for _, anomaly := range summary.Anomalies {
+			fmt.Fprintf(&result, "- %s\n", anomaly)
+		}
+	}
+	for _, note := range summary.Notes {
+		fmt.Fprintf(&result, "\n%s\n", note)
+	}
+	result.WriteString(formatWarnings(summary.Warnings))
+	if summary.URL != "" {
+		fmt.Fprintf(&result, "\n%s\n", summary.URL)
+	}
+	return result.String()
+}
+
+// importOrigin describes the build an import came from, e.g. "build 1057, branch main"
+func importOrigin(entry importEntry) string {
+	var parts []string
+	for _, part := range []struct{ label, value string }{
+		{"build", entry.BuildID},
+		{"commit", entry.CommitHash},
+		{"branch", entry.BranchTag},
+		{"version", entry.Version},
+	} {
+		if part.value != "" {
+			parts = append(parts, part.label+" "+part.value)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,147 @@
+package mcpserver
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/brduru/mcp-defect-dojo/internal/defectdojo"
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+// testImportOf builds an import reporting the given numbers of created, closed and untouched findings
+func testImportOf(id, test int, created, closed, untouched int) types.TestImport {
+	testImport := types.TestImport{ID: id, Test: test, Type: "reimport"}
+	for action, count := range map[string]int{types.ImportActionCreated: created, types.ImportActionClosed: closed, types.ImportActionUntouched: untouched} {
+		for range count {
+			testImport.Actions = append(testImport.Actions, types.ImportFindingAction{Finding: len(testImport.Actions) + 1, Action: action})
+		}
+	}
+	return testImport
+}
+
+func TestGetImportSummary(t *testing.T) {
+	fixtures, err := defectdojo.NewFixtureClient("")
+	if err != nil {
+		t.Fatalf("NewFixtureClient() error = %v", err)
+	}
+	s := newServer(&Config{}, fixtures)
+
+	result, err := callTool(t, s, "get_import_summary", map[string]any{"test_id": 100})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text := resultText(result)
+	for _, want := range []string{
+		"Last reimport of test 100 (External DAST), ZAP Scan: #4 on 2026-09-15T08:10:00Z",
+		"From build 1057, branch main",
+		"Findings in the report: 3\n  Created: 0\n  Closed: 1\n  Reactivated: 0\n  Untouched: 3",
+		"Baseline: median of 4 findings (4 to 4) over 1 earlier imports of this test",
+		"No anomalies detected",
+		"Only 1 earlier imports to compare with",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in output, got: %s", want, text)
+		}
+	}
+
+	if _, err := callTool(t, s, "get_import_summary", map[string]any{"test_id": 999}); err == nil || !strings.Contains(err.Error(), "error retrieving test 999") {
+		t.Errorf("expected a missing test to be reported, got %v", err)
+	}
+}
+
+func TestImportAnomalies(t *testing.T) {
+	histories := map[int][]types.TestImport{
+		// Nightly reimports of a scanner that usually reports hundreds of findings
+		1: {testImportOf(14, 1, 0, 0, 0), testImportOf(13, 1, 3, 0, 240), testImportOf(12, 1, 10, 2, 225), testImportOf(11, 1, 230, 0, 0)},
+		// A reimport closing most of the test's findings
+		2: {testImportOf(24, 2, 0, 180, 20), testImportOf(23, 2, 0, 0, 200), testImportOf(22, 2, 0, 0, 200), testImportOf(21, 2, 200, 0, 0)},
+		// A pipeline creating one test per run
+		5: {testImportOf(50, 5, 0, 0, 0)},
+		3: {testImportOf(30, 3, 80, 0, 0)},
+		4: {testImportOf(40, 4, 95, 0, 0)},
+		6: {},
+	}
+	mock := &MockDefectDojoClient{
+		GetTestFunc: func(ctx context.Context, testID int) (*types.Test, error) {
+			return &types.Test{ID: testID, Engagement: 9, TestType: 7}, nil
+		},
+		ListTestsFunc: func(ctx context.Context, engagementID, limit, offset int) (*types.TestsResponse, error) {
+			return &types.TestsResponse{Count: 4, Results: []types.Test{
+				{ID: 3, Engagement: 9, TestType: 7}, {ID: 4, Engagement: 9, TestType: 7}, {ID: 5, Engagement: 9, TestType: 7}, {ID: 6, Engagement: 9, TestType: 8},
+			}}, nil
+		},
+		ListTestImportsFunc: func(ctx context.Context, testID, limit, offset int) (*types.TestImportsResponse, error) {
+			imports := histories[testID]
+			return &types.TestImportsResponse{Count: len(imports), Results: imports[:min(limit, len(imports))]}, nil
+		},
+	}
+	s := newServer(&Config{}, mock)
+
+	tests := []struct {
+		name   string
+		testID int
+		want   []string
+	}{
+		{"empty report", 1, []string{"Findings in the report: 0", "The report contained no findings, while earlier imports reported 235 (median)"}},
+		{"mass closure", 2, []string{"The import closed 180 of the 200 findings the test already had"}},
+		{"sibling tests", 5, []string{"over 0 earlier imports of this test and the latest imports of 2 other tests of the same scanner", "#40 reimport of test 4", "Only 2 earlier imports"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := callTool(t, s, "get_import_summary", map[string]any{"test_id": tt.testID})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			text := resultText(result)
+			for _, want := range tt.want {
+				if !strings.Contains(text, want) {
+					t.Errorf("expected %q in output, got: %s", want, text)
+				}
+			}
+		})
+	}
+
+	result, err := callTool(t, s, "get_import_summary", map[string]any{"test_id": 1, "format": "json"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := resultText(result); !strings.Contains(text, `"median_reported": 235`) || !strings.Contains(text, `"reported": 0,`) {
+		t.Errorf("unexpected JSON output: %s", text)
+	}
+
+	if _, err := callTool(t, s, "get_import_summary", map[string]any{"test_id": 6}); err == nil || !strings.Contains(err.Error(), "test 6 has no import history") {
+		t.Errorf("expected a test without imports to be reported, got %v", err)
+	}
+}
+
+func TestImportAnomalyThresholds(t *testing.T) {
+	baseline := &importBaseline{Imports: 5, MedianReported: 40}
+	tests := []struct {
+		name string
+		last importEntry
+		want string
+	}{
+		{"usual", importEntry{importCounts: importCounts{Reported: 38, Untouched: 38}}, ""},
+		{"drop", importEntry{importCounts: importCounts{Reported: 6, Untouched: 6}}, "far fewer than the median of 40"},
+		{"spike", importEntry{importCounts: importCounts{Reported: 400, Created: 360, Untouched: 40}}, "far more than the median of 40"},
+		{"small closure", importEntry{importCounts: importCounts{Reported: 1, Closed: 5, Untouched: 1}}, "far fewer"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			anomalies := importAnomalies(tt.last, baseline)
+			if tt.want == "" {
+				if len(anomalies) != 0 {
+					t.Errorf("expected no anomalies, got %v", anomalies)
+				}
+				return
+			}
+			if len(anomalies) != 1 || !strings.Contains(anomalies[0], tt.want) {
+				t.Errorf("expected one anomaly containing %q, got %v", tt.want, anomalies)
+			}
+		})
+	}
+	if anomalies := importAnomalies(importEntry{}, &importBaseline{Imports: 2, MedianReported: 500}); len(anomalies) != 0 {
+		t.Errorf("expected too short a history not to be judged, got %v", anomalies)
+	}
+}
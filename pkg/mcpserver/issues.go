@@ -0,0 +1,257 @@
+package mcpserver
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+// Issue trackers supported by create_issue_from_finding
+const (
+	IssueTrackerGitHub = "github"
+	IssueTrackerGitLab = "gitlab"
+)
+
+// Issue tracker defaults
+const (
+	defaultGitHubAPIURL = "https://api.github.com"
+	defaultGitLabAPIURL = "https://gitlab.com/api/v4"
+	issueRequestTimeout = 30 * time.Second
+	issueSectionChars   = 10000 // Per text section; GitHub rejects bodies over 65536 characters
+	issueURLMetadata    = "issue_url"
+)
+
+// issueDraft is an issue to file for a finding
+type issueDraft struct {
+	Title  string
+	Body   string // Markdown
+	Labels []string
+}
+
+// issueTracker files issues in one GitHub repository or GitLab project
+type issueTracker interface {
+	name() string
+	createIssue(ctx context.Context, issue issueDraft) (string, error) // Returns the issue's web URL
+}
+
+// newIssueTracker returns the tracker selected by cfg.Provider, or nil when none is configured
+func newIssueTracker(cfg IssueTrackerConfig) (issueTracker, error) {
+	if cfg.Provider == "" {
+		return nil, nil
+	}
+	if cfg.Token == "" || cfg.Repository == "" {
+		return nil, fmt.Errorf("%s issues need a token and a repository", cfg.Provider)
+	}
+	client := &http.Client{Timeout: issueRequestTimeout}
+	switch cfg.Provider {
+	case IssueTrackerGitHub:
+		owner, repo, ok := strings.Cut(cfg.Repository, "/")
+		if !ok || owner == "" || repo == "" || strings.Contains(repo, "/") {
+			return nil, fmt.Errorf("GitHub repository %q must be owner/name", cfg.Repository)
+		}
+		return &githubTracker{
+			client:   client,
+			endpoint: fmt.Sprintf("%s/repos/%s/%s/issues", strings.TrimRight(cmp.Or(cfg.APIURL, defaultGitHubAPIURL), "/"), url.PathEscape(owner), url.PathEscape(repo)),
+			token:    cfg.Token,
+		}, nil
+	case IssueTrackerGitLab:
+		return &gitlabTracker{
+			client:   client,
+			endpoint: fmt.Sprintf("%s/projects/%s/issues", strings.TrimRight(cmp.Or(cfg.APIURL, defaultGitLabAPIURL), "/"), url.PathEscape(cfg.Repository)),
+			token:    cfg.Token,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown issue tracker %q (must be github or gitlab)", cfg.Provider)
+	}
+}
+
+// githubTracker files issues through the GitHub REST API
+type githubTracker struct {
+	client   *http.Client
+	endpoint string
+	token    string
+}
+
+func (g *githubTracker) name() string { return "GitHub" }
+
+func (g *githubTracker) createIssue(ctx context.Context, issue issueDraft) (string, error) {
+	payload := map[string]any{"title": issue.Title, "body": issue.Body}
+	if len(issue.Labels) > 0 {
+		payload["labels"] = issue.Labels
+	}
+	headers := map[string]string{
+		"Authorization":        "Bearer " + g.token,
+		"Accept":               "application/vnd.github+json",
+		"X-GitHub-Api-Version": "2022-11-28",
+	}
+	var created struct {
+		HTMLURL string `json:"html_url"`
+	}
+	if err := postIssue(ctx, g.client, g.endpoint, headers, payload, &created); err != nil {
+		return "", err
+	}
+	return created.HTMLURL, nil
+}
+
+// gitlabTracker files issues through the GitLab REST API
+type gitlabTracker struct {
+	client   *http.Client
+	endpoint string
+	token    string
+}
+
+func (g *gitlabTracker) name() string { return "GitLab" }
+
+func (g *gitlabTracker) createIssue(ctx context.Context, issue issueDraft) (string, error) {
+	payload := map[string]any{"title": issue.Title, "description": issue.Body}
+	if len(issue.Labels) > 0 {
+		payload["labels"] = strings.Join(issue.Labels, ",")
+	}
+	var created struct {
+		WebURL string `json:"web_url"`
+	}
+	if err := postIssue(ctx, g.client, g.endpoint, map[string]string{"PRIVATE-TOKEN": g.token}, payload, &created); err != nil {
+		return "", err
+	}
+	return created.WebURL, nil
+}
+
+// postIssue sends a JSON issue creation request and decodes the created issue
+func postIssue(ctx context.Context, client *http.Client, endpoint string, headers map[string]string, payload, out any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshaling issue: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(detail)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decoding created issue: %w", err)
+	}
+	return nil
+}
+
+// issueFromFinding drafts the issue filed for a finding
+func (s *Server) issueFromFinding(finding *types.Finding, findingContext findingContext, labels []string) issueDraft {
+	var body strings.Builder
+	fmt.Fprintf(&body, "**Severity:** %s\n", s.severity.display(finding.Severity))
+	if finding.CVSSv3Score != nil {
+		fmt.Fprintf(&body, "**CVSS v3:** %.1f\n", *finding.CVSSv3Score)
+	}
+	if ids := finding.VulnerabilityIDList(); len(ids) > 0 {
+		fmt.Fprintf(&body, "**Vulnerability IDs:** %s\n", strings.Join(ids, ", "))
+	}
+	if finding.CWE != 0 {
+		fmt.Fprintf(&body, "**CWE:** CWE-%d\n", finding.CWE)
+	}
+	if finding.ComponentName != "" {
+		fmt.Fprintf(&body, "**Component:** %s\n", strings.TrimSpace(finding.ComponentName+" "+finding.ComponentVersion))
+	}
+	if finding.FilePath != "" {
+		location := finding.FilePath
+		if finding.Line != nil {
+			location += fmt.Sprintf(":%d", *finding.Line)
+		}
+		fmt.Fprintf(&body, "**Location:** `%s`\n", location)
+	}
+	if findingContext.Product != "" {
+		fmt.Fprintf(&body, "**Context:** %s\n", findingContext)
+	}
+	if !finding.SLAExpirationDate.IsZero() {
+		fmt.Fprintf(&body, "**SLA deadline:** %s\n", finding.SLAExpirationDate.Format(time.DateOnly))
+	}
+	if link := s.links.finding(finding.ID); link != "" {
+		fmt.Fprintf(&body, "**DefectDojo:** [finding %d](%s)\n", finding.ID, link)
+	}
+
+	for _, section := range []struct{ heading, text string }{
+		{"Description", finding.Description},
+		{"Impact", finding.Impact},
+		{"Mitigation", finding.Mitigation},
+		{"Steps to reproduce", finding.StepsToReproduce},
+		{"References", finding.References},
+	} {
+		if text := strings.TrimSpace(section.text); text != "" {
+			fmt.Fprintf(&body, "\n## %s\n\n%s\n", section.heading, truncateText(text, issueSectionChars))
+		}
+	}
+	fmt.Fprintf(&body, "\n---\n_Filed from DefectDojo finding %d. Close the finding in DefectDojo once this issue is resolved._\n", finding.ID)
+
+	return issueDraft{
+		Title:  fmt.Sprintf("[%s] %s", s.severity.display(finding.Severity), finding.Title),
+		Body:   body.String(),
+		Labels: labels,
+	}
+}
+
+// createIssueFromFinding handles create_issue_from_finding. A finding that
+// already links to an issue is not filed again.
+func (s *Server) createIssueFromFinding(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if s.issues == nil {
+		return nil, fmt.Errorf("no issue tracker is configured (set ISSUE_TRACKER, ISSUE_TRACKER_TOKEN and ISSUE_TRACKER_REPOSITORY)")
+	}
+	findingID, err := request.RequireInt("finding_id")
+	if err != nil {
+		return nil, fmt.Errorf("invalid finding_id: %w", err)
+	}
+
+	metadata, err := s.ddClient.GetFindingMetadata(ctx, findingID)
+	if err != nil {
+		return nil, fmt.Errorf("error reading metadata of finding %d: %w", findingID, err)
+	}
+	if i := slices.IndexFunc(metadata, func(m types.FindingMetadata) bool { return m.Name == issueURLMetadata }); i >= 0 {
+		return mcp.NewToolResultText(fmt.Sprintf("Finding %d already has an issue: %s\nNo new issue was filed.\n", findingID, metadata[i].Value)), nil
+	}
+
+	finding, err := s.ddClient.GetFindingDetail(ctx, findingID)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving finding %d: %w", findingID, err)
+	}
+	labels := slices.Concat(s.config.IssueTracker.Labels, request.GetStringSlice("labels", nil))
+	slices.Sort(labels)
+	issue := s.issueFromFinding(finding, s.resolveContexts(ctx, []types.Finding{*finding}, nil, nil)[finding.Test], slices.Compact(labels))
+
+	issueURL, err := s.issues.createIssue(ctx, issue)
+	if err != nil {
+		return nil, fmt.Errorf("error creating %s issue for finding %d: %w", s.issues.name(), findingID, err)
+	}
+	stored, err := s.ddClient.AddFindingMetadata(ctx, findingID, types.FindingMetadata{Name: issueURLMetadata, Value: issueURL})
+	if err != nil {
+		return nil, fmt.Errorf("%s issue %s was created, but linking it to finding %d failed: %w", s.issues.name(), issueURL, findingID, err)
+	}
+
+	result := fmt.Sprintf("Created %s issue for finding %d: %s\n\n", s.issues.name(), findingID, issueURL)
+	result += fmt.Sprintf("Title: %s\n", s.sanitizedText(issue.Title))
+	if len(issue.Labels) > 0 {
+		result += fmt.Sprintf("Labels: %s\n", strings.Join(issue.Labels, ", "))
+	}
+	result += fmt.Sprintf("Recorded as finding metadata %s (ID: %d)\n", issueURLMetadata, stored.ID)
+	return mcp.NewToolResultText(result), nil
+}
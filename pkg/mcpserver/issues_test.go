@@ -0,0 +1,154 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/brduru/mcp-defect-dojo/internal/defectdojo"
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+func TestCreateIssueFromFindingGitHub(t *testing.T) {
+	var requests atomic.Int32
+	var payload struct {
+		Title  string   `json:"title"`
+		Body   string   `json:"body"`
+		Labels []string `json:"labels"`
+	}
+	github := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.Method != http.MethodPost || r.URL.Path != "/repos/acme/payments/issues" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "Bearer ghp-secret" {
+			t.Errorf("unexpected Authorization header %q", r.Header.Get("Authorization"))
+		}
+		json.NewDecoder(r.Body).Decode(&payload)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]any{"number": 17, "html_url": "https://github.com/acme/payments/issues/17"})
+	}))
+	defer github.Close()
+
+	fixtures, err := defectdojo.NewFixtureClient("")
+	if err != nil {
+		t.Fatalf("NewFixtureClient() error = %v", err)
+	}
+	s := newServer(&Config{
+		DefectDojo:   DefectDojoConfig{BaseURL: "https://dojo.example.com/"},
+		IssueTracker: IssueTrackerConfig{Provider: IssueTrackerGitHub, Token: "ghp-secret", Repository: "acme/payments", APIURL: github.URL, Labels: []string{"security"}},
+	}, fixtures)
+
+	result, err := callTool(t, s, "create_issue_from_finding", map[string]any{"finding_id": 4, "labels": []any{"triage", "security"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := resultText(result); !strings.Contains(text, "Created GitHub issue for finding 4: https://github.com/acme/payments/issues/17") || !strings.Contains(text, "Recorded as finding metadata issue_url") {
+		t.Errorf("unexpected result:\n%s", text)
+	}
+	if payload.Title != "[Medium] lodash: Prototype Pollution (CVE-2020-8203)" || !slices.Equal(payload.Labels, []string{"security", "triage"}) {
+		t.Errorf("unexpected issue %q with labels %q", payload.Title, payload.Labels)
+	}
+	for _, want := range []string{"**Severity:** Medium", "**Vulnerability IDs:** CVE-2020-8203", "**Context:** Product: Customer Portal", "[finding 4](https://dojo.example.com/finding/4)", "## Description"} {
+		if !strings.Contains(payload.Body, want) {
+			t.Errorf("expected %q in issue body:\n%s", want, payload.Body)
+		}
+	}
+
+	metadata, _ := fixtures.GetFindingMetadata(context.Background(), 4)
+	if len(metadata) != 1 || metadata[0].Value != "https://github.com/acme/payments/issues/17" {
+		t.Errorf("expected the issue URL stored on the finding, got %+v", metadata)
+	}
+
+	// A linked finding is not filed again
+	result, err = callTool(t, s, "create_issue_from_finding", map[string]any{"finding_id": 4})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(resultText(result), "already has an issue: https://github.com/acme/payments/issues/17") || requests.Load() != 1 {
+		t.Errorf("expected the existing issue to be returned, got %d requests:\n%s", requests.Load(), resultText(result))
+	}
+}
+
+func TestCreateIssueFromFindingGitLab(t *testing.T) {
+	var payload map[string]string
+	gitlab := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() != "/api/v4/projects/security%2Fpayments-api/issues" || r.Header.Get("PRIVATE-TOKEN") != "glpat-secret" {
+			t.Errorf("unexpected request %s with token %q", r.URL.EscapedPath(), r.Header.Get("PRIVATE-TOKEN"))
+		}
+		json.NewDecoder(r.Body).Decode(&payload)
+		json.NewEncoder(w).Encode(map[string]any{"iid": 3, "web_url": "https://gitlab.example.com/security/payments-api/-/issues/3"})
+	}))
+	defer gitlab.Close()
+
+	var stored types.FindingMetadata
+	mock := &MockDefectDojoClient{
+		AddFindingMetadataFunc: func(ctx context.Context, findingID int, metadata types.FindingMetadata) (*types.FindingMetadata, error) {
+			stored = metadata
+			metadata.ID = 8
+			return &metadata, nil
+		},
+	}
+	s := newServer(&Config{IssueTracker: IssueTrackerConfig{Provider: IssueTrackerGitLab, Token: "glpat-secret", Repository: "security/payments-api", APIURL: gitlab.URL + "/api/v4/", Labels: []string{"security", "defectdojo"}}}, mock)
+
+	result, err := callTool(t, s, "create_issue_from_finding", map[string]any{"finding_id": 123})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(resultText(result), "Created GitLab issue for finding 123") {
+		t.Errorf("unexpected result:\n%s", resultText(result))
+	}
+	if payload["labels"] != "defectdojo,security" || !strings.HasPrefix(payload["description"], "**Severity:** High") {
+		t.Errorf("unexpected GitLab payload %v", payload)
+	}
+	if stored.Name != issueURLMetadata || stored.Value != "https://gitlab.example.com/security/payments-api/-/issues/3" {
+		t.Errorf("unexpected metadata %+v", stored)
+	}
+}
+
+func TestCreateIssueFromFindingFailures(t *testing.T) {
+	if _, err := callTool(t, newServer(&Config{}, &MockDefectDojoClient{}), "create_issue_from_finding", map[string]any{"finding_id": 1}); err == nil || !strings.Contains(err.Error(), "ISSUE_TRACKER") {
+		t.Errorf("expected a configuration hint, got %v", err)
+	}
+
+	tracker := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message":"Bad credentials"}`, http.StatusUnauthorized)
+	}))
+	defer tracker.Close()
+	linked := false
+	mock := &MockDefectDojoClient{
+		AddFindingMetadataFunc: func(ctx context.Context, findingID int, metadata types.FindingMetadata) (*types.FindingMetadata, error) {
+			linked = true
+			return &metadata, nil
+		},
+	}
+	s := newServer(&Config{IssueTracker: IssueTrackerConfig{Provider: IssueTrackerGitHub, Token: "expired", Repository: "acme/payments", APIURL: tracker.URL}}, mock)
+	_, err := callTool(t, s, "create_issue_from_finding", map[string]any{"finding_id": 1})
+	if err == nil || !strings.Contains(err.Error(), "status 401") || linked {
+		t.Errorf("expected the tracker error without linking, got %v (linked: %v)", err, linked)
+	}
+}
+
+func TestNewIssueTracker(t *testing.T) {
+	if tracker, err := newIssueTracker(IssueTrackerConfig{}); tracker != nil || err != nil {
+		t.Errorf("expected no tracker by default, got %v, %v", tracker, err)
+	}
+	for name, cfg := range map[string]IssueTrackerConfig{
+		"missing token":       {Provider: IssueTrackerGitHub, Repository: "acme/payments"},
+		"bare GitHub repo":    {Provider: IssueTrackerGitHub, Token: "t", Repository: "payments"},
+		"nested GitHub repo":  {Provider: IssueTrackerGitHub, Token: "t", Repository: "acme/payments/api"},
+		"unknown provider":    {Provider: "jira", Token: "t", Repository: "SEC"},
+		"missing GitLab repo": {Provider: IssueTrackerGitLab, Token: "t"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if tracker, err := newIssueTracker(cfg); tracker != nil || err == nil {
+				t.Errorf("expected an error, got %v, %v", tracker, err)
+			}
+		})
+	}
+}
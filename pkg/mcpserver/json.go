@@ -0,0 +1,86 @@
+package mcpserver
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+// jsonFindingsPage is the JSON output of get_defectdojo_findings
+type jsonFindingsPage struct {
+	Count   int           `json:"count"`
+	Results []jsonFinding `json:"results"`
+	Cursor  pageCursor    `json:"cursor"`
+
+	Context  map[int]findingContext `json:"context,omitempty"`  // Product/engagement names by test ID, with include_context
+	Warnings []string               `json:"warnings,omitempty"` // Enrichment DefectDojo failed to provide
+}
+
+// jsonFinding is a finding with its DefectDojo UI link
+type jsonFinding struct {
+	types.Finding
+	SeverityLabel string `json:"severity_label,omitempty"` // Organization label of the severity, when configured
+	URL           string `json:"url,omitempty"`
+	Occurrences   int    `json:"occurrences,omitempty"` // With group_repeated, findings of the page with this title and component
+	FindingIDs    []int  `json:"finding_ids,omitempty"` // Their IDs, when there are several
+}
+
+// newJSONFinding attaches a finding's UI link and severity label
+func newJSONFinding(finding types.Finding, links webLinks, severities severityScale) jsonFinding {
+	return jsonFinding{Finding: finding, SeverityLabel: severities.label(finding.Severity), URL: links.finding(finding.ID)}
+}
+
+// jsonFindings attaches UI links and severity labels to findings
+func jsonFindings(findings []types.Finding, links webLinks, severities severityScale) []jsonFinding {
+	results := make([]jsonFinding, len(findings))
+	for i, finding := range findings {
+		results[i] = newJSONFinding(finding, links, severities)
+	}
+	return results
+}
+
+// jsonFindingDetailOutput is the JSON output of get_finding_detail
+type jsonFindingDetailOutput struct {
+	*types.Finding
+	SeverityLabel string          `json:"severity_label,omitempty"` // Organization label of the severity, when configured
+	URL           string          `json:"url,omitempty"`            // DefectDojo UI page
+	Context       *findingContext `json:"context,omitempty"`        // Product/engagement names, with include_context
+	Exploitation  []CVEIntel      `json:"exploitation,omitempty"`   // EPSS and KEV data, with CVE enrichment
+	Warnings      []string        `json:"warnings,omitempty"`       // Enrichment that failed
+}
+
+// jsonFindingsList renders a findings page with its pagination cursor as JSON
+func jsonFindingsList(response *types.FindingsResponse, page pageCursor, opts formatOptions) (string, error) {
+	results := jsonFindings(response.Results, opts.links, opts.severities)
+	if opts.groupRepeated {
+		groups := groupRepeatedFindings(response.Results)
+		results = make([]jsonFinding, len(groups))
+		for i, group := range groups {
+			results[i] = newJSONFinding(group.Finding, opts.links, opts.severities)
+			results[i].Occurrences = len(group.IDs)
+			if len(group.IDs) > 1 {
+				results[i].FindingIDs = group.IDs
+			}
+		}
+	}
+	return marshalOutput(jsonFindingsPage{Count: response.Count, Results: results, Cursor: page, Context: opts.contexts, Warnings: opts.warnings})
+}
+
+// jsonFindingDetail renders a single finding as JSON
+func jsonFindingDetail(finding *types.Finding, opts formatOptions) (string, error) {
+	output := jsonFindingDetailOutput{Finding: finding, SeverityLabel: opts.severities.label(finding.Severity), URL: opts.links.finding(finding.ID), Exploitation: findingIntel(finding, opts.intel), Warnings: opts.warnings}
+	if names, ok := opts.contexts[finding.Test]; ok {
+		output.Context = &names
+	}
+	return marshalOutput(output)
+}
+
+// marshalOutput encodes tool output as indented JSON
+func marshalOutput(value any) (string, error) {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("encoding JSON output: %w", err)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,47 @@
+package mcpserver
+
+import (
+	"cmp"
+	"fmt"
+
+	"github.com/brduru/mcp-defect-dojo/internal/config"
+)
+
+// webLinks builds DefectDojo web UI URLs for tool output. The zero value
+// produces no links.
+type webLinks struct {
+	base string // UI base URL without trailing slash ("" = links off)
+}
+
+// newWebLinks links to the UI base URL, or to the API base URL when the UI is not configured separately
+func newWebLinks(cfg DefectDojoConfig) webLinks {
+	return webLinks{base: config.NormalizeBaseURL(cmp.Or(cfg.UIBaseURL, cfg.BaseURL))}
+}
+
+// url returns the UI page of an object, e.g. {base}/finding/42, or "" without a base URL
+func (l webLinks) url(kind string, id int) string {
+	if l.base == "" || id <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("%s/%s/%d", l.base, kind, id)
+}
+
+// finding returns the UI page of a finding
+func (l webLinks) finding(id int) string {
+	return l.url("finding", id)
+}
+
+// product returns the UI page of a product
+func (l webLinks) product(id int) string {
+	return l.url("product", id)
+}
+
+// engagement returns the UI page of an engagement
+func (l webLinks) engagement(id int) string {
+	return l.url("engagement", id)
+}
+
+// test returns the UI page of a test
+func (l webLinks) test(id int) string {
+	return l.url("test", id)
+}
@@ -0,0 +1,97 @@
+package mcpserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/brduru/mcp-defect-dojo/internal/defectdojo"
+)
+
+func TestWebLinks(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		cfg  DefectDojoConfig
+		want string
+	}{
+		{"API base URL", DefectDojoConfig{BaseURL: "https://dojo.example.com/api/v2/"}, "https://dojo.example.com/finding/42"},
+		{"separate UI", DefectDojoConfig{BaseURL: "http://dojo-api.internal:8080", UIBaseURL: "dojo.example.com/defectdojo/"}, "https://dojo.example.com/defectdojo/finding/42"},
+		{"no base URL", DefectDojoConfig{}, ""},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newWebLinks(tt.cfg).finding(42); got != tt.want {
+				t.Errorf("finding(42) = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	links := newWebLinks(DefectDojoConfig{BaseURL: "https://dojo.example.com"})
+	if got := links.product(1); got != "https://dojo.example.com/product/1" {
+		t.Errorf("product(1) = %q", got)
+	}
+	if got := links.engagement(10); got != "https://dojo.example.com/engagement/10" {
+		t.Errorf("engagement(10) = %q", got)
+	}
+	if got := links.test(100); got != "https://dojo.example.com/test/100" {
+		t.Errorf("test(100) = %q", got)
+	}
+	if got := links.finding(0); got != "" {
+		t.Errorf("expected no link without an ID, got %q", got)
+	}
+}
+
+func TestFindingOutputsLink(t *testing.T) {
+	fixtures, err := defectdojo.NewFixtureClient("")
+	if err != nil {
+		t.Fatalf("NewFixtureClient() error = %v", err)
+	}
+	s := newServer(&Config{DefectDojo: DefectDojoConfig{BaseURL: "http://dojo-api:8080", UIBaseURL: "https://dojo.example.com"}}, fixtures)
+	const link = "https://dojo.example.com/finding/4"
+
+	for _, format := range []string{formatText, formatMarkdown} {
+		for tool, args := range map[string]map[string]any{
+			"get_finding_detail":      {"finding_id": 4, "format": format},
+			"get_defectdojo_findings": {"severity": "Medium", "format": format},
+		} {
+			result, err := callTool(t, s, tool, args)
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %v", tool, err)
+			}
+			if text := resultText(result); !strings.Contains(text, link) {
+				t.Errorf("%s (%s): expected %s in:\n%s", tool, format, link, text)
+			}
+		}
+	}
+
+	result, err := callTool(t, s, "get_finding_detail", map[string]any{"finding_id": 4, "format": formatJSON})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var detail struct {
+		ID  int    `json:"id"`
+		URL string `json:"url"`
+	}
+	if err := json.Unmarshal([]byte(resultText(result)), &detail); err != nil || detail.ID != 4 || detail.URL != link {
+		t.Errorf("unexpected JSON detail %+v (%v)", detail, err)
+	}
+
+	result, err = callTool(t, s, "get_defectdojo_findings", map[string]any{"severity": "Medium", "format": formatJSON})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var page struct {
+		Results []struct {
+			ID  int    `json:"id"`
+			URL string `json:"url"`
+		} `json:"results"`
+	}
+	if err := json.Unmarshal([]byte(resultText(result)), &page); err != nil || len(page.Results) == 0 {
+		t.Fatalf("unexpected JSON page %s (%v)", resultText(result), err)
+	}
+	for _, finding := range page.Results {
+		if finding.URL != fmt.Sprintf("https://dojo.example.com/finding/%d", finding.ID) {
+			t.Errorf("unexpected link %q for finding %d", finding.URL, finding.ID)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package mcpserver
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+// markdownFindingsList renders a page of findings as a compact Markdown table
+// When contexts is set, a Product / Engagement column is added; with links,
+// IDs link to the findings in DefectDojo.
+func markdownFindingsList(response *types.FindingsResponse, opts formatOptions) string {
+	contexts := opts.contexts
+	text := opts.text
+	result := fmt.Sprintf("**"+text.t("Found %d findings (showing %d)")+"**\n\n", response.Count, len(response.Results))
+	if len(response.Results) == 0 {
+		return result
+	}
+	if contexts != nil {
+		result += fmt.Sprintf("| ID | %s | %s | %s | %s | %s |\n", text.t("Severity"), text.t("Title"), text.t("Product / Engagement"), text.t("Status"), text.t("Age"))
+		result += "|---:|----------|-------|----------------------|--------|----:|\n"
+	} else {
+		result += fmt.Sprintf("| ID | %s | %s | %s | %s |\n", text.t("Severity"), text.t("Title"), text.t("Status"), text.t("Age"))
+		result += "|---:|----------|-------|--------|----:|\n"
+	}
+	groups := make([]findingGroup, len(response.Results))
+	for i, finding := range response.Results {
+		groups[i] = findingGroup{Finding: finding, IDs: []int{finding.ID}}
+	}
+	if opts.groupRepeated {
+		groups = groupRepeatedFindings(response.Results)
+	}
+	for _, group := range groups {
+		finding := group.Finding
+		age := "-"
+		if days, ok := ageDays(&finding); ok {
+			age = fmt.Sprintf("%dd", days)
+		}
+		title := markdownCell(finding.Title)
+		if len(group.IDs) > 1 {
+			title = markdownCell(group.groupTitle()) + fmt.Sprintf(" (%s)", fmt.Sprintf(text.t("%d occurrences"), len(group.IDs)))
+		}
+		if contexts != nil {
+			names := contexts[finding.Test]
+			cell := strings.Trim(names.Product+" / "+names.Engagement, " /")
+			if names.Environment != "" {
+				cell = strings.TrimSpace(cell + " (" + names.Environment + ")")
+			}
+			title += " | " + markdownCell(cell)
+		}
+		id := fmt.Sprintf("%d", finding.ID)
+		if link := opts.links.finding(finding.ID); link != "" {
+			id = fmt.Sprintf("[%d](%s)", finding.ID, link)
+		}
+		if len(group.IDs) > 1 {
+			id = formatIDs(group.IDs)
+		}
+		result += fmt.Sprintf("| %s | %s | %s | %s | %s |\n",
+			id, opts.severities.display(finding.Severity), title, text.join(findingStatus(&finding)), age)
+	}
+	return result
+}
+
+// markdownFindingDetail renders a single finding as Markdown: a heading,
+// a bullet list of fields and one section per long text field
+func markdownFindingDetail(finding *types.Finding, opts formatOptions) string {
+	text := opts.text
+	result := fmt.Sprintf("## "+text.t("Finding %d: %s")+"\n\n", finding.ID, finding.Title)
+
+	fields := fmt.Sprintf("%s: %s\n", text.t("Severity"), opts.severities.display(finding.Severity))
+	if link := opts.links.finding(finding.ID); link != "" {
+		fields += fmt.Sprintf("URL: %s\n", link)
+	}
+	if finding.NumericalSeverity != "" {
+		fields += fmt.Sprintf("%s: %s\n", text.t("Numerical Severity"), finding.NumericalSeverity)
+	}
+	fields += fmt.Sprintf("%s: %s\n", text.t("Status"), text.join(findingStatus(finding)))
+	if scoring := formatScoring(finding); scoring != "" {
+		fields += fmt.Sprintf("%s: %s\n", text.t("Scoring"), scoring)
+	}
+	if finding.CVSSv3 != "" {
+		fields += fmt.Sprintf("%s: `%s`\n", text.t("CVSS v3 Vector"), finding.CVSSv3)
+	}
+	fields += formatExploitation(finding, opts.intel)
+	fields += formatLocation(finding, text)
+	fields += formatTest(finding, opts)
+	if len(finding.Tags) > 0 {
+		fields += fmt.Sprintf("%s: %s\n", text.t("Tags"), strings.Join(finding.Tags, ", "))
+	}
+	if name := finding.ReporterName(); name != "" {
+		fields += fmt.Sprintf("%s: %s\n", text.t("Reporter"), name)
+	} else if finding.Reporter != 0 {
+		fields += fmt.Sprintf("%s: "+text.t("user %d")+"\n", text.t("Reporter"), finding.Reporter)
+	}
+	if len(finding.Reviewers) > 0 {
+		fields += fmt.Sprintf("%s: %s\n", text.t("Assigned to"), formatUserIDs(finding.Reviewers))
+	}
+	fields += formatDates(finding, text)
+	fields += formatSLA(finding, text)
+	result += markdownFields(fields)
+
+	sections := []struct{ heading, text string }{
+		{"Description", finding.Description},
+		{"Impact", finding.Impact},
+		{"Mitigation", finding.Mitigation},
+		{"Steps to Reproduce", finding.StepsToReproduce},
+		{"References", finding.References},
+	}
+	for _, section := range sections {
+		if section.text != "" {
+			result += fmt.Sprintf("\n### %s\n\n%s\n", text.t(section.heading), truncateText(section.text, opts.maxFieldChars))
+		}
+	}
+	return result
+}
+
+// findingStatus lists the state of a finding in words, open or closed first
+func findingStatus(finding *types.Finding) []string {
+	status := []string{"Inactive"}
+	if finding.Active {
+		status[0] = "Active"
+	}
+	if finding.Verified {
+		status = append(status, "Verified")
+	}
+	if finding.FalseP {
+		status = append(status, "False Positive")
+	}
+	return append(status, finding.StatusFlags()...)
+}
+
+// markdownFields turns "Key: value" lines into a bold-keyed bullet list
+func markdownFields(lines string) string {
+	var result string
+	for _, line := range strings.Split(strings.TrimSuffix(lines, "\n"), "\n") {
+		if key, value, ok := strings.Cut(line, ": "); ok {
+			result += fmt.Sprintf("- **%s:** %s\n", key, value)
+		} else if line != "" {
+			result += fmt.Sprintf("- %s\n", line)
+		}
+	}
+	return result
+}
+
+// markdownCell makes text safe for a single Markdown table cell
+func markdownCell(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	return strings.ReplaceAll(text, "|", `\|`)
+}
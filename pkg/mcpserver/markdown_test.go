@@ -0,0 +1,66 @@
+package mcpserver
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+func TestMarkdownFindingsList(t *testing.T) {
+	age := 12
+	response := &types.FindingsResponse{
+		Count: 2,
+		Results: []types.Finding{
+			{ID: 1, Title: "SQL | injection", Severity: "Critical", Active: true, Verified: true, AgeDays: &age},
+			{ID: 2, Title: "Old issue", Severity: "Low", RiskAccepted: true},
+		},
+	}
+
+	table := markdownFindingsList(response, formatOptions{})
+	for _, want := range []string{
+		"**Found 2 findings (showing 2)**",
+		"| ID | Severity | Title | Status | Age |",
+		`| 1 | Critical | SQL \| injection | Active, Verified | 12d |`,
+		"| 2 | Low | Old issue | Inactive, Risk Accepted | - |",
+	} {
+		if !strings.Contains(table, want) {
+			t.Errorf("table missing %q:\n%s", want, table)
+		}
+	}
+
+	empty := markdownFindingsList(&types.FindingsResponse{}, formatOptions{})
+	if strings.Contains(empty, "|") {
+		t.Errorf("expected no table for an empty page, got:\n%s", empty)
+	}
+}
+
+func TestMarkdownFindingDetail(t *testing.T) {
+	line := 42
+	finding := &types.Finding{
+		ID:          5,
+		Title:       "Hardcoded secret",
+		Severity:    "High",
+		Active:      true,
+		FilePath:    "src/db.go",
+		Line:        &line,
+		Test:        3,
+		Description: "Leak.",
+		Mitigation:  strings.Repeat("m", 20),
+	}
+
+	detail := markdownFindingDetail(finding, formatOptions{maxFieldChars: 5})
+	for _, want := range []string{
+		"## Finding 5: Hardcoded secret",
+		"- **Severity:** High",
+		"- **Status:** Active",
+		"- **File:** src/db.go:42",
+		"- **Test ID:** 3",
+		"### Description\n\nLeak.\n",
+		"### Mitigation\n\nmmmmm… [truncated 15 characters]",
+	} {
+		if !strings.Contains(detail, want) {
+			t.Errorf("detail missing %q:\n%s", want, detail)
+		}
+	}
+}
@@ -0,0 +1,274 @@
+package mcpserver
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/brduru/mcp-defect-dojo/internal/refcache"
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+// MTTR metrics sizing
+const (
+	defaultMTTRDays       = 90 // Days before end_date where the range starts by default
+	maxMTTRFindingsPages  = 20 // Mitigated findings pages read per product
+	mttrPercentile        = 90
+	maxMTTRRangeDays      = 3660
+	mttrFindingsOrdering  = "-mitigated" // Most recently mitigated first, so a capped product keeps the latest
+	mttrDaysRoundingScale = 10           // Days are reported to one decimal
+)
+
+// remediationStats summarizes remediation times in days
+type remediationStats struct {
+	Count      int     `json:"count"` // Mitigated findings measured
+	MeanDays   float64 `json:"mean_days"`
+	MedianDays float64 `json:"median_days"`
+	P90Days    float64 `json:"p90_days"` // 90% of the findings were remediated within this many days
+}
+
+// severityRemediation is the remediation time of one severity
+type severityRemediation struct {
+	Severity string `json:"severity"`
+	remediationStats
+}
+
+// mttrMetrics are the remediation times of a product or of all products
+type mttrMetrics struct {
+	Overall    remediationStats      `json:"overall"`
+	BySeverity []severityRemediation `json:"by_severity"` // Most severe first; severities without mitigated findings are left out
+}
+
+// productMTTR is one product's entry in get_mttr_metrics
+type productMTTR struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	URL  string `json:"url,omitempty"` // DefectDojo UI page of the product
+	mttrMetrics
+	Complete bool `json:"complete"` // False when only the latest mitigated findings were measured
+
+	samples map[string][]float64 // Remediation days per severity
+	err     error
+}
+
+// mttrReport is the output of get_mttr_metrics
+type mttrReport struct {
+	StartDate string        `json:"start_date"` // Findings mitigated from this date...
+	EndDate   string        `json:"end_date"`   // ...through this one
+	Scope     string        `json:"scope"`
+	Overall   mttrMetrics   `json:"overall"`
+	Products  []productMTTR `json:"products"` // Slowest to remediate first
+	Errors    []string      `json:"errors,omitempty"`
+	Notes     []string      `json:"notes,omitempty"`
+}
+
+// remediationDays is how long a mitigated finding stayed open, from its
+// creation in DefectDojo (or its discovery date) to its mitigation
+func remediationDays(finding *types.Finding) (float64, bool) {
+	opened := cmp.Or(finding.Created, finding.Date)
+	if finding.Mitigated.IsZero() || opened.IsZero() {
+		return 0, false
+	}
+	return max(finding.Mitigated.Sub(opened).Hours()/24, 0), true
+}
+
+// newRemediationStats computes the mean, median and 90th percentile (nearest
+// rank) of remediation times in days
+func newRemediationStats(days []float64) remediationStats {
+	if len(days) == 0 {
+		return remediationStats{}
+	}
+	sorted := slices.Sorted(slices.Values(days))
+	sum := 0.0
+	for _, d := range sorted {
+		sum += d
+	}
+	n := len(sorted)
+	median := sorted[n/2]
+	if n%2 == 0 {
+		median = (sorted[n/2-1] + sorted[n/2]) / 2
+	}
+	rank := int(math.Ceil(float64(n)*mttrPercentile/100)) - 1
+	return remediationStats{
+		Count:      n,
+		MeanDays:   roundDays(sum / float64(n)),
+		MedianDays: roundDays(median),
+		P90Days:    roundDays(sorted[rank]),
+	}
+}
+
+// roundDays rounds days to one decimal
+func roundDays(days float64) float64 {
+	return math.Round(days*mttrDaysRoundingScale) / mttrDaysRoundingScale
+}
+
+// newMTTRMetrics computes the metrics of remediation times per severity
+func newMTTRMetrics(samples map[string][]float64) mttrMetrics {
+	metrics := mttrMetrics{BySeverity: []severityRemediation{}}
+	var all []float64
+	for _, severity := range slices.Backward(types.ValidSeverities()) {
+		days := samples[severity]
+		if len(days) == 0 {
+			continue
+		}
+		all = append(all, days...)
+		metrics.BySeverity = append(metrics.BySeverity, severityRemediation{Severity: severity, remediationStats: newRemediationStats(days)})
+	}
+	metrics.Overall = newRemediationStats(all)
+	return metrics
+}
+
+// measureProduct reads the product's findings mitigated in the range and
+// collects their remediation times per severity
+func (s *Server) measureProduct(ctx context.Context, product types.Product, base types.FindingsFilter) productMTTR {
+	result := productMTTR{ID: product.ID, Name: product.Name, URL: s.links.product(product.ID), Complete: true, samples: map[string][]float64{}}
+	filter := base
+	filter.Product = &product.ID
+	filter.Limit, filter.Ordering = posturePageSize, mttrFindingsOrdering
+	for page := range maxMTTRFindingsPages {
+		filter.Offset = page * posturePageSize
+		response, err := s.findingsPage(ctx, filter)
+		if err != nil {
+			result.err = err
+			return result
+		}
+		for _, finding := range response.Results {
+			if days, ok := remediationDays(&finding); ok {
+				result.samples[finding.Severity] = append(result.samples[finding.Severity], days)
+			}
+		}
+		if response.Next == nil || len(response.Results) == 0 {
+			result.mttrMetrics = newMTTRMetrics(result.samples)
+			return result
+		}
+	}
+	result.Complete = false
+	result.mttrMetrics = newMTTRMetrics(result.samples)
+	return result
+}
+
+// measureMTTR measures the products on the worker pool and aggregates their
+// remediation times; the overall figures pool every finding rather than
+// averaging the products
+func (s *Server) measureMTTR(ctx context.Context, products []types.Product, base types.FindingsFilter) mttrReport {
+	results := make([]productMTTR, len(products))
+	s.workers.run(ctx, operationProductMTTR, len(products), func(i int) {
+		results[i] = s.measureProduct(ctx, products[i], base)
+	})
+
+	report := mttrReport{Products: []productMTTR{}}
+	all := map[string][]float64{}
+	incomplete := 0
+	for _, result := range results {
+		if result.err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("product %d (%s): %v", result.ID, result.Name, result.err))
+			continue
+		}
+		for severity, days := range result.samples {
+			all[severity] = append(all[severity], days...)
+		}
+		if !result.Complete {
+			incomplete++
+		}
+		if result.Overall.Count > 0 {
+			report.Products = append(report.Products, result)
+		}
+	}
+	report.Overall = newMTTRMetrics(all)
+	slices.SortStableFunc(report.Products, func(a, b productMTTR) int {
+		return cmp.Or(cmp.Compare(b.Overall.MeanDays, a.Overall.MeanDays), cmp.Compare(a.ID, b.ID))
+	})
+	if incomplete > 0 {
+		report.Notes = append(report.Notes, fmt.Sprintf("only the %d most recently mitigated findings were measured for %d products", maxMTTRFindingsPages*posturePageSize, incomplete))
+	}
+	return report
+}
+
+// mttrProducts returns the product to measure, or every product with any of the tags
+func (s *Server) mttrProducts(ctx context.Context, productID int, tags []string) ([]types.Product, string, []string, error) {
+	if productID > 0 {
+		product, err := refcache.Get(s.refs, refKey(refProduct, productID), func() (*types.Product, error) {
+			return s.ddClient.GetProduct(ctx, productID)
+		})
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("error retrieving product %d: %w", productID, err)
+		}
+		return []types.Product{*product}, "product " + product.Name, nil, nil
+	}
+
+	products, total, err := s.listProducts(ctx, tags)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("error listing products: %w", err)
+	}
+	if len(products) == 0 {
+		return nil, "", nil, fmt.Errorf("no products to measure (%d visible, none tagged %s)", total, strings.Join(tags, " or "))
+	}
+	scope := "all products"
+	if len(tags) > 0 {
+		scope = "products tagged " + strings.Join(tags, " or ")
+	}
+	var notes []string
+	if total > maxPostureProducts {
+		notes = append(notes, fmt.Sprintf("only the first %d of %d products were considered", maxPostureProducts, total))
+	}
+	return products, scope, notes, nil
+}
+
+// getMTTRMetrics handles get_mttr_metrics
+func (s *Server) getMTTRMetrics(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	end, err := dateArgument(request, "end_date", today)
+	if err != nil {
+		return nil, err
+	}
+	start, err := dateArgument(request, "start_date", end.AddDate(0, 0, -defaultMTTRDays))
+	if err != nil {
+		return nil, err
+	}
+	if end.Before(start) {
+		return nil, fmt.Errorf("invalid date range: end_date %s is before start_date %s", end.Format(time.DateOnly), start.Format(time.DateOnly))
+	}
+	if end.Sub(start) > maxMTTRRangeDays*24*time.Hour {
+		return nil, fmt.Errorf("invalid date range: at most %d days can be measured at once", maxMTTRRangeDays)
+	}
+	severity, err := s.severityArgument(request, "severity")
+	if err != nil {
+		return nil, err
+	}
+
+	products, scope, notes, err := s.mttrProducts(ctx, request.GetInt("product", 0), request.GetStringSlice("product_tags", nil))
+	if err != nil {
+		return nil, err
+	}
+
+	// Mitigation timestamps are compared with the dates' midnights, so the
+	// range ends before the day after end_date
+	mitigated := true
+	base := types.FindingsFilter{
+		IsMitigated:     &mitigated,
+		Severity:        severity,
+		MitigatedAfter:  start,
+		MitigatedBefore: end.AddDate(0, 0, 1),
+	}
+	report := s.measureMTTR(ctx, products, base)
+	if len(report.Errors) == len(products) {
+		return nil, fmt.Errorf("MTTR metrics failed for every product: %s", report.Errors[0])
+	}
+	report.StartDate, report.EndDate, report.Scope = start.Format(time.DateOnly), end.Format(time.DateOnly), scope
+	if severity != "" {
+		report.Scope += ", " + severity + " findings"
+	}
+	report.Notes = append(notes, report.Notes...)
+
+	output, err := marshalOutput(report)
+	if err != nil {
+		return nil, err
+	}
+	return mcp.NewToolResultStructured(report, output), nil
+}
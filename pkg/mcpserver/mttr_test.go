@@ -0,0 +1,97 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/brduru/mcp-defect-dojo/internal/defectdojo"
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+func TestRemediationStats(t *testing.T) {
+	stats := newRemediationStats([]float64{10, 2, 4, 30, 1, 3, 5, 7, 9, 100})
+	want := remediationStats{Count: 10, MeanDays: 17.1, MedianDays: 6, P90Days: 30}
+	if stats != want {
+		t.Errorf("newRemediationStats() = %+v, want %+v", stats, want)
+	}
+	if stats := newRemediationStats([]float64{2.25}); stats.MedianDays != 2.3 || stats.P90Days != 2.3 {
+		t.Errorf("expected a single sample rounded to one decimal, got %+v", stats)
+	}
+
+	metrics := newMTTRMetrics(map[string][]float64{types.SeverityLow: {10}, types.SeverityCritical: {2, 4}})
+	if len(metrics.BySeverity) != 2 || metrics.BySeverity[0].Severity != types.SeverityCritical || metrics.Overall.Count != 3 || metrics.Overall.MedianDays != 4 {
+		t.Errorf("unexpected metrics %+v", metrics)
+	}
+}
+
+func TestRemediationDays(t *testing.T) {
+	finding := types.Finding{
+		Date:      time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+		Created:   time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC),
+		Mitigated: time.Date(2026, 3, 5, 0, 0, 0, 0, time.UTC),
+	}
+	if days, ok := remediationDays(&finding); !ok || days != 2.5 {
+		t.Errorf("remediationDays() = %v, %v, want 2.5 from the creation timestamp", days, ok)
+	}
+	finding.Created = time.Time{}
+	if days, _ := remediationDays(&finding); days != 4 {
+		t.Errorf("remediationDays() = %v, want 4 from the discovery date", days)
+	}
+	if _, ok := remediationDays(&types.Finding{Date: finding.Date}); ok {
+		t.Error("expected no remediation time without a mitigation timestamp")
+	}
+}
+
+func TestGetMTTRMetrics(t *testing.T) {
+	fixtures, err := defectdojo.NewFixtureClient("")
+	if err != nil {
+		t.Fatalf("NewFixtureClient() error = %v", err)
+	}
+	s := newServer(&Config{}, fixtures)
+
+	result, err := callTool(t, s, toolMTTRMetrics, map[string]any{"start_date": "2026-07-01", "end_date": "2026-07-31"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var report mttrReport
+	if err := json.Unmarshal([]byte(resultText(result)), &report); err != nil {
+		t.Fatalf("invalid JSON output: %v", err)
+	}
+	if report.Overall.Overall.Count != 1 || report.Overall.Overall.MeanDays != 18 || len(report.Products) != 1 || report.Products[0].BySeverity[0].Severity != types.SeverityInfo {
+		t.Errorf("unexpected report: %+v", report)
+	}
+	if report.StartDate != "2026-07-01" || report.EndDate != "2026-07-31" || report.Scope != "all products" || !report.Products[0].Complete {
+		t.Errorf("unexpected report range or scope: %+v", report)
+	}
+	if result.StructuredContent == nil {
+		t.Error("expected the report as structured content")
+	}
+
+	// Mitigated after the range
+	result, _ = callTool(t, s, toolMTTRMetrics, map[string]any{"start_date": "2026-06-01", "end_date": "2026-07-19"})
+	if err := json.Unmarshal([]byte(resultText(result)), &report); err != nil || report.Overall.Overall.Count != 0 || len(report.Products) != 0 {
+		t.Errorf("expected nothing mitigated before July 20, got %+v (%v)", report, err)
+	}
+
+	if _, err := callTool(t, s, toolMTTRMetrics, map[string]any{"start_date": "2026-08-01", "end_date": "2026-07-01"}); err == nil {
+		t.Error("expected an error for a reversed date range")
+	}
+}
+
+func TestGetMTTRMetricsFailingProducts(t *testing.T) {
+	mock := &MockDefectDojoClient{
+		ListProductsFunc: func(ctx context.Context, limit, offset int) (*types.ProductsResponse, error) {
+			return &types.ProductsResponse{Count: 1, Results: []types.Product{{ID: 1, Name: "Payments API"}}}, nil
+		},
+		GetFindingsFunc: func(ctx context.Context, filter types.FindingsFilter) (*types.FindingsResponse, error) {
+			return nil, errors.New("boom")
+		},
+	}
+	s := newServer(&Config{}, mock)
+	if _, err := callTool(t, s, toolMTTRMetrics, nil); err == nil {
+		t.Error("expected an error when every product fails")
+	}
+}
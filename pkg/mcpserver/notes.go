@@ -0,0 +1,86 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Batch note sizing
+const (
+	maxNoteFindings = 100 // Distinct findings one call may add a note to
+)
+
+// noteOutcome is the result of adding the note to one finding
+type noteOutcome struct {
+	findingID int
+	noteID    int
+	err       error
+}
+
+// addNoteToFindings handles add_note_to_findings. The note is posted to every
+// finding concurrently; the call fails if any finding did not get it, and
+// says which ones did so the caller retries only the others.
+func (s *Server) addNoteToFindings(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	entry, err := request.RequireString("note")
+	if err != nil {
+		return nil, fmt.Errorf("invalid note: %w", err)
+	}
+	if strings.TrimSpace(entry) == "" {
+		return nil, fmt.Errorf("invalid note: must not be empty")
+	}
+
+	var ids []int
+	seen := map[int]bool{}
+	for _, id := range request.GetIntSlice("finding_ids", nil) {
+		if id < 1 {
+			return nil, fmt.Errorf("invalid finding_ids: %d is not a finding ID", id)
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("finding_ids or saved_result must name at least one finding")
+	}
+	if len(ids) > maxNoteFindings {
+		return nil, fmt.Errorf("too many findings (%d): at most %d can be annotated per call", len(ids), maxNoteFindings)
+	}
+
+	outcomes := make([]noteOutcome, len(ids))
+	s.workers.run(ctx, operationAddNotes, len(ids), func(i int) {
+		outcomes[i] = noteOutcome{findingID: ids[i]}
+		note, err := s.ddClient.AddFindingNote(ctx, ids[i], entry)
+		if err != nil {
+			outcomes[i].err = err
+			return
+		}
+		outcomes[i].noteID = note.ID
+	})
+
+	var result strings.Builder
+	var added, failed []string
+	for _, outcome := range outcomes {
+		if outcome.err != nil {
+			fmt.Fprintf(&result, "❌ Finding %d: %v\n", outcome.findingID, outcome.err)
+			failed = append(failed, fmt.Sprint(outcome.findingID))
+			continue
+		}
+		fmt.Fprintf(&result, "✅ Finding %d: note %d\n", outcome.findingID, outcome.noteID)
+		added = append(added, fmt.Sprint(outcome.findingID))
+	}
+
+	header := fmt.Sprintf("Added the note to %d of %d findings\n\n", len(added), len(ids))
+	if len(failed) > 0 {
+		message := "adding the note failed for findings " + strings.Join(failed, ", ")
+		if len(added) > 0 {
+			// Say what did go through so the caller does not add the note twice
+			message += fmt.Sprintf("; findings %s have it, do not add it to them again", strings.Join(added, ", "))
+		}
+		return nil, fmt.Errorf("%s\n\n%s%s", message, header, result.String())
+	}
+	return mcp.NewToolResultText(header + result.String()), nil
+}
@@ -0,0 +1,59 @@
+package mcpserver
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+func TestAddNoteToFindings(t *testing.T) {
+	var mu sync.Mutex
+	notes := map[int]string{}
+	mock := &MockDefectDojoClient{
+		AddFindingNoteFunc: func(ctx context.Context, findingID int, entry string) (*types.Note, error) {
+			if findingID == 999 {
+				return nil, errors.New("finding not found")
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			notes[findingID] = entry
+			return &types.Note{ID: 100 + findingID, Entry: entry}, nil
+		},
+	}
+	s := newServer(&Config{}, mock)
+
+	result, err := callTool(t, s, "add_note_to_findings", map[string]any{"finding_ids": []any{3, 1, 3, 2}, "note": "tracked in INC-1234"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(notes) != 3 || notes[3] != "tracked in INC-1234" {
+		t.Errorf("expected one note per distinct finding, got %v", notes)
+	}
+	text := resultText(result)
+	for _, want := range []string{"Added the note to 3 of 3 findings", "Finding 3: note 103", "Finding 2: note 102"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in:\n%s", want, text)
+		}
+	}
+	if strings.Index(text, "Finding 3") > strings.Index(text, "Finding 1") {
+		t.Errorf("expected findings in the requested order:\n%s", text)
+	}
+
+	_, err = callTool(t, s, "add_note_to_findings", map[string]any{"finding_ids": []any{4, 999}, "note": "tracked in INC-1234"})
+	if err == nil {
+		t.Fatal("expected a partial failure to be reported as an error")
+	}
+	for _, want := range []string{"failed for findings 999", "findings 4 have it", "Added the note to 1 of 2 findings", "❌ Finding 999: finding not found"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in:\n%v", want, err)
+		}
+	}
+
+	if _, err := callTool(t, s, "add_note_to_findings", map[string]any{"finding_ids": []any{1}, "note": "  "}); err == nil {
+		t.Error("expected a blank note to be rejected")
+	}
+}
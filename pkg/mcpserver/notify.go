@@ -0,0 +1,260 @@
+package mcpserver
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Notification webhook payload formats
+const (
+	NotifyFormatSlack = "slack" // Slack incoming webhook: {"text": message} (default)
+	NotifyFormatTeams = "teams" // Microsoft Teams incoming webhook: a MessageCard
+	NotifyFormatJSON  = "json"  // The Notification itself, for custom receivers
+)
+
+const notifyRequestTimeout = 10 * time.Second
+
+// defaultNotifyTemplate renders the message text when a webhook sets no template
+const defaultNotifyTemplate = "{{.Summary}}"
+
+// NotificationWebhook is a receiver of write notifications
+type NotificationWebhook struct {
+	URL             string   `json:"url"`
+	Format          string   `json:"format,omitempty"`           // NotifyFormatSlack (default), NotifyFormatTeams or NotifyFormatJSON
+	Template        string   `json:"template,omitempty"`         // Go text/template rendering the message from a Notification (default: "{{.Summary}}")
+	Tools           []string `json:"tools,omitempty"`            // Only notify about these write tools (default: all)
+	IncludeFailures bool     `json:"include_failures,omitempty"` // Also notify about failed and rejected writes
+}
+
+// Notification describes one write for notification templates. It carries
+// the audit record of the call plus a one-line human-readable Summary.
+type Notification struct {
+	AuditRecord
+	Summary string `json:"summary"` // E.g. "✅ alice marked finding 42 as false positive: test data"
+	Message string `json:"message"` // Rendered template text
+}
+
+// LoadNotificationWebhooks reads a JSON array of NotificationWebhook from path
+// and checks every entry, including its template.
+func LoadNotificationWebhooks(path string) ([]NotificationWebhook, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading notification webhooks: %w", err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	var webhooks []NotificationWebhook
+	if err := decoder.Decode(&webhooks); err != nil {
+		return nil, fmt.Errorf("parsing notification webhooks %s: %w", path, err)
+	}
+	if _, err := newNotifier(webhooks); err != nil {
+		return nil, fmt.Errorf("invalid notification webhooks in %s: %w", path, err)
+	}
+	return webhooks, nil
+}
+
+// notificationWebhooks returns the configured webhooks, loading FilePath
+// unless webhooks are given directly
+func notificationWebhooks(cfg NotificationConfig) ([]NotificationWebhook, error) {
+	if cfg.Webhooks != nil || cfg.FilePath == "" {
+		return cfg.Webhooks, nil
+	}
+	return LoadNotificationWebhooks(cfg.FilePath)
+}
+
+// notifyTarget is a checked webhook with its parsed template
+type notifyTarget struct {
+	NotificationWebhook
+	template *template.Template
+}
+
+// notifier delivers write notifications to webhooks in the background
+type notifier struct {
+	targets []notifyTarget
+	client  *http.Client
+	pending sync.WaitGroup
+}
+
+// newNotifier checks the webhooks; it returns nil when there are none
+func newNotifier(webhooks []NotificationWebhook) (*notifier, error) {
+	if len(webhooks) == 0 {
+		return nil, nil
+	}
+	n := &notifier{client: &http.Client{Timeout: notifyRequestTimeout}}
+	for i, webhook := range webhooks {
+		parsed, err := url.Parse(webhook.URL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return nil, fmt.Errorf("webhook %d: invalid URL %q", i+1, webhook.URL)
+		}
+		switch webhook.Format {
+		case "":
+			webhook.Format = NotifyFormatSlack
+		case NotifyFormatSlack, NotifyFormatTeams, NotifyFormatJSON:
+		default:
+			return nil, fmt.Errorf("webhook %d: unknown format %q (must be slack, teams or json)", i+1, webhook.Format)
+		}
+		for _, tool := range webhook.Tools {
+			if !writeTools[tool] {
+				return nil, fmt.Errorf("webhook %d: %q is not a write tool", i+1, tool)
+			}
+		}
+		tmpl, err := template.New(fmt.Sprintf("webhook %d", i+1)).Option("missingkey=zero").Parse(cmp.Or(webhook.Template, defaultNotifyTemplate))
+		if err != nil {
+			return nil, fmt.Errorf("webhook %d: %w", i+1, err)
+		}
+		n.targets = append(n.targets, notifyTarget{NotificationWebhook: webhook, template: tmpl})
+	}
+	return n, nil
+}
+
+// notify sends the record to every interested webhook without blocking the
+// tool call. Delivery failures are logged, never returned to the agent.
+func (n *notifier) notify(record AuditRecord) {
+	for _, target := range n.targets {
+		if !record.Success && !target.IncludeFailures {
+			continue
+		}
+		if len(target.Tools) > 0 && !slices.Contains(target.Tools, record.Tool) {
+			continue
+		}
+		n.pending.Go(func() {
+			if err := n.deliver(target, record); err != nil {
+				log.Printf("notify: failed to deliver %s notification [request_id=%s]: %v", record.Tool, record.RequestID, err)
+			}
+		})
+	}
+}
+
+// wait blocks until every notification sent so far was delivered or failed
+func (n *notifier) wait() {
+	n.pending.Wait()
+}
+
+// deliver renders and posts one notification
+func (n *notifier) deliver(target notifyTarget, record AuditRecord) error {
+	notification := Notification{AuditRecord: record, Summary: notificationSummary(record)}
+	var message strings.Builder
+	if err := target.template.Execute(&message, notification); err != nil {
+		return fmt.Errorf("rendering template: %w", err)
+	}
+	notification.Message = message.String()
+
+	var payload any
+	switch target.Format {
+	case NotifyFormatTeams:
+		color := "2EB67D"
+		if !record.Success {
+			color = "E01E5A"
+		}
+		payload = map[string]any{
+			"@type":      "MessageCard",
+			"@context":   "https://schema.org/extensions",
+			"summary":    notification.Summary,
+			"themeColor": color,
+			"text":       notification.Message,
+		}
+	case NotifyFormatJSON:
+		payload = notification
+	default:
+		payload = map[string]string{"text": notification.Message}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshaling payload: %w", err)
+	}
+
+	resp, err := n.client.Post(target.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("webhook answered %d: %s", resp.StatusCode, strings.TrimSpace(string(detail)))
+	}
+	return nil
+}
+
+// notificationSummary describes a write in one line, e.g.
+// "✅ alice marked finding 42 as false positive: test data"
+func notificationSummary(record AuditRecord) string {
+	if !record.Success {
+		target := ""
+		if record.FindingID != 0 {
+			target = fmt.Sprintf(" on finding %d", record.FindingID)
+		}
+		return fmt.Sprintf("❌ %s%s by %s failed: %s", record.Tool, target, cmp.Or(record.Caller, "an agent"), record.Error)
+	}
+	argument := func(name string) string {
+		value, _ := record.Arguments[name].(string)
+		return value
+	}
+
+	var action, detail string
+	switch record.Tool {
+	case toolMarkFalsePositive:
+		action, detail = fmt.Sprintf("marked finding %d as false positive", record.FindingID), argument("justification")
+	case toolClearFalsePositive:
+		action, detail = fmt.Sprintf("cleared the false positive flag on finding %d", record.FindingID), argument("justification")
+	case toolChangeSeverity:
+		action, detail = fmt.Sprintf("changed the severity of finding %d to %s", record.FindingID, argument("severity")), argument("justification")
+	case toolAssignFinding:
+		action = fmt.Sprintf("assigned finding %d to %s", record.FindingID, argument("username"))
+	case toolAddNote:
+		ids, _ := record.Arguments["finding_ids"].([]any)
+		action, detail = fmt.Sprintf("added a note to %d findings", len(ids)), argument("note")
+	case toolCreateProduct:
+		action = "created product " + argument("name")
+	case toolCreateEngagement:
+		action = fmt.Sprintf("created engagement %s in product %v", argument("name"), record.Arguments["product_id"])
+	case toolImportSARIF:
+		action = "imported a SARIF report"
+		if product := argument("product_name"); product != "" {
+			action += " into " + product
+		}
+	case toolCreateIssue:
+		action = fmt.Sprintf("filed an issue for finding %d", record.FindingID)
+	default:
+		action = "called " + record.Tool
+		if record.FindingID != 0 {
+			action += fmt.Sprintf(" on finding %d", record.FindingID)
+		}
+	}
+
+	summary := fmt.Sprintf("✅ %s %s", cmp.Or(record.Caller, "An agent"), action)
+	if detail != "" {
+		summary += ": " + detail
+	}
+	return summary
+}
+
+// notifyMiddleware reports every write tool call to the notifier once it has run.
+func notifyMiddleware(n *notifier) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if !writeTools[request.Params.Name] {
+				return next(ctx, request)
+			}
+
+			result, err := next(ctx, request)
+			n.notify(newAuditRecord(ctx, request, err))
+			return result, err
+		}
+	}
+}
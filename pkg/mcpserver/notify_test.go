@@ -0,0 +1,156 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+// webhookRecorder collects the JSON payloads posted to a test webhook
+type webhookRecorder struct {
+	mu       sync.Mutex
+	payloads []map[string]any
+}
+
+func (r *webhookRecorder) handler(w http.ResponseWriter, req *http.Request) {
+	var payload map[string]any
+	json.NewDecoder(req.Body).Decode(&payload)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.payloads = append(r.payloads, payload)
+}
+
+func (r *webhookRecorder) received() []map[string]any {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]map[string]any(nil), r.payloads...)
+}
+
+func TestWriteNotifications(t *testing.T) {
+	slack, teams, custom := &webhookRecorder{}, &webhookRecorder{}, &webhookRecorder{}
+	urls := map[*webhookRecorder]string{}
+	for _, recorder := range []*webhookRecorder{slack, teams, custom} {
+		server := httptest.NewServer(http.HandlerFunc(recorder.handler))
+		defer server.Close()
+		urls[recorder] = server.URL
+	}
+
+	mock := &MockDefectDojoClient{
+		MarkFalsePositiveFunc: func(ctx context.Context, findingID int, request types.FalsePositiveRequest) (*types.FalsePositiveResponse, error) {
+			if findingID == 13 {
+				return nil, errors.New("finding is locked")
+			}
+			return &types.FalsePositiveResponse{ID: findingID, FalseP: request.IsFalsePositive}, nil
+		},
+	}
+	s := newServer(&Config{Notification: NotificationConfig{Webhooks: []NotificationWebhook{
+		{URL: urls[slack]},
+		{URL: urls[teams], Format: NotifyFormatTeams, Tools: []string{toolClearFalsePositive}, IncludeFailures: true},
+		{URL: urls[custom], Format: NotifyFormatJSON, IncludeFailures: true, Template: "{{.Caller}} → {{.Tool}} #{{.FindingID}} ({{.Arguments.justification}})"},
+	}}}, mock)
+
+	ctx := WithCallerIdentity(context.Background(), "alice")
+	if _, err := callToolWithContext(t, ctx, s, "mark_finding_false_positive", map[string]any{"finding_id": 42, "justification": "test data"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := callTool(t, s, "mark_finding_false_positive", map[string]any{"finding_id": 13, "justification": "vendored"}); err == nil {
+		t.Fatal("expected the write to fail")
+	}
+	if _, err := callTool(t, s, "clear_false_positive", map[string]any{"finding_id": 7, "justification": "exploit confirmed"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := callTool(t, s, "get_finding_detail", map[string]any{"finding_id": 42}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	s.notifier.wait()
+
+	// Slack hears about successful writes only, with the default summary
+	got := slack.received()
+	if len(got) != 2 || !slices.ContainsFunc(got, func(payload map[string]any) bool {
+		return payload["text"] == "✅ alice marked finding 42 as false positive: test data"
+	}) {
+		t.Errorf("unexpected Slack payloads: %v", got)
+	}
+	// Teams only listens for clear_false_positive
+	if got := teams.received(); len(got) != 1 || got[0]["@type"] != "MessageCard" || got[0]["text"] != "✅ An agent cleared the false positive flag on finding 7: exploit confirmed" {
+		t.Errorf("unexpected Teams payloads: %v", got)
+	}
+	// The JSON receiver gets the whole notification, failures included
+	got = custom.received()
+	if len(got) != 3 {
+		t.Fatalf("expected 3 custom payloads, got %v", got)
+	}
+	messages := map[string]bool{}
+	for _, payload := range got {
+		messages[payload["message"].(string)] = true
+		if payload["summary"] == "" || payload["timestamp"] == nil {
+			t.Errorf("unexpected custom payload %v", payload)
+		}
+	}
+	if !messages["alice → mark_finding_false_positive #42 (test data)"] || !messages[" → mark_finding_false_positive #13 (vendored)"] {
+		t.Errorf("unexpected rendered messages %v", messages)
+	}
+}
+
+func TestNotificationSummary(t *testing.T) {
+	for _, tt := range []struct {
+		record AuditRecord
+		want   string
+	}{
+		{AuditRecord{Tool: toolClearFalsePositive, FindingID: 7, Success: true, Arguments: map[string]any{"justification": "exploit confirmed"}}, "✅ An agent cleared the false positive flag on finding 7: exploit confirmed"},
+		{AuditRecord{Tool: toolAssignFinding, FindingID: 7, Success: true, Arguments: map[string]any{"username": "dana"}}, "✅ An agent assigned finding 7 to dana"},
+		{AuditRecord{Tool: toolChangeSeverity, FindingID: 7, Success: true, Arguments: map[string]any{"severity": "Low", "justification": "internal only"}}, "✅ An agent changed the severity of finding 7 to Low: internal only"},
+		{AuditRecord{Tool: toolImportSARIF, Caller: "ci", Success: true, Arguments: map[string]any{"product_name": "Payments API"}}, "✅ ci imported a SARIF report into Payments API"},
+		{AuditRecord{Tool: toolCreateIssue, FindingID: 3, Success: true}, "✅ An agent filed an issue for finding 3"},
+		{AuditRecord{Tool: toolCreateEngagement, Arguments: map[string]any{"product_id": 3.0, "name": "CI scans"}, Success: true}, "✅ An agent created engagement CI scans in product 3"},
+		{AuditRecord{Tool: toolAddNote, Arguments: map[string]any{"finding_ids": []any{1.0, 2.0}, "note": "tracked in INC-1234"}, Success: true}, "✅ An agent added a note to 2 findings: tracked in INC-1234"},
+		{AuditRecord{Tool: toolMarkFalsePositive, FindingID: 9, Caller: "bob", Error: "write policy: Critical findings are protected"}, "❌ mark_finding_false_positive on finding 9 by bob failed: write policy: Critical findings are protected"},
+	} {
+		if got := notificationSummary(tt.record); got != tt.want {
+			t.Errorf("notificationSummary() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestLoadNotificationWebhooks(t *testing.T) {
+	write := func(content string) string {
+		path := filepath.Join(t.TempDir(), "webhooks.json")
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	webhooks, err := LoadNotificationWebhooks(write(`[{"url": "https://hooks.slack.com/services/T0/B0/x"}, {"url": "https://teams.example.com/hook", "format": "teams", "template": "{{.Summary}}"}]`))
+	if err != nil || len(webhooks) != 2 || webhooks[1].Format != NotifyFormatTeams {
+		t.Fatalf("LoadNotificationWebhooks() = %+v, %v", webhooks, err)
+	}
+
+	for name, content := range map[string]string{
+		"not an array":    `{"url": "https://hooks.slack.com/x"}`,
+		"unknown field":   `[{"url": "https://hooks.slack.com/x", "channel": "#sec"}]`,
+		"relative URL":    `[{"url": "/hook"}]`,
+		"unknown format":  `[{"url": "https://hooks.slack.com/x", "format": "discord"}]`,
+		"read tool":       `[{"url": "https://hooks.slack.com/x", "tools": ["get_finding_detail"]}]`,
+		"broken template": `[{"url": "https://hooks.slack.com/x", "template": "{{.Summary"}]`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := LoadNotificationWebhooks(write(content)); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+	if _, err := LoadNotificationWebhooks(filepath.Join(t.TempDir(), "missing.json")); err == nil || !strings.Contains(err.Error(), "reading") {
+		t.Errorf("expected a read error, got %v", err)
+	}
+}
@@ -0,0 +1,163 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/brduru/mcp-defect-dojo/internal/defectdojo"
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+// Onboarding defaults
+const (
+	productTypePageSize       = 100
+	maxProductTypes           = 1000 // Product types searched for a name
+	productTypesListedOnMiss  = 20   // Product type names suggested when a name does not match
+	defaultEngagementDays     = 7    // Engagement length when no target end is given
+	engagementTypeInteractive = "Interactive"
+	engagementTypeCICD        = "CI/CD"
+)
+
+// resolveProductType finds a product type by ID or case-insensitive name
+func resolveProductType(ctx context.Context, ddClient defectdojo.Client, value string) (*types.ProductType, error) {
+	id, _ := strconv.Atoi(value)
+	var names []string
+	for offset := 0; offset < maxProductTypes; offset += productTypePageSize {
+		response, err := ddClient.ListProductTypes(ctx, productTypePageSize, offset)
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving product types: %w", err)
+		}
+		for _, productType := range response.Results {
+			if productType.ID == id || strings.EqualFold(productType.Name, value) {
+				return &productType, nil
+			}
+			names = append(names, productType.Name)
+		}
+		if response.Next == nil || len(response.Results) == 0 {
+			break
+		}
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("unknown product type %q: DefectDojo has no product types, create one in DefectDojo first", value)
+	}
+	slices.Sort(names)
+	if len(names) > productTypesListedOnMiss {
+		names = append(names[:productTypesListedOnMiss], "...")
+	}
+	return nil, fmt.Errorf("unknown product type %q (available: %s)", value, strings.Join(names, ", "))
+}
+
+// createProduct handles create_product
+func (s *Server) createProduct(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	name, err := request.RequireString("name")
+	if err != nil {
+		return nil, fmt.Errorf("invalid name: %w", err)
+	}
+	description, err := request.RequireString("description")
+	if err != nil {
+		return nil, fmt.Errorf("invalid description: %w", err)
+	}
+	typeName, err := request.RequireString("product_type")
+	if err != nil {
+		return nil, fmt.Errorf("invalid product_type: %w", err)
+	}
+	productType, err := resolveProductType(ctx, s.ddClient, typeName)
+	if err != nil {
+		return nil, err
+	}
+
+	product, err := s.ddClient.CreateProduct(ctx, types.CreateProductRequest{
+		Name:        strings.TrimSpace(name),
+		Description: description,
+		ProductType: productType.ID,
+		Tags:        request.GetStringSlice("tags", nil),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error creating product %q: %w", name, err)
+	}
+
+	result := fmt.Sprintf("Created product %d: %s\n", product.ID, product.Name)
+	result += fmt.Sprintf("Product type: %s\n", productType.Name)
+	if len(product.Tags) > 0 {
+		result += fmt.Sprintf("Tags: %s\n", strings.Join(product.Tags, ", "))
+	}
+	if link := s.links.product(product.ID); link != "" {
+		result += link + "\n"
+	}
+	result += fmt.Sprintf("\nNext: create an engagement with create_engagement (product_id %d), then import scan results into it with import_sarif.\n", product.ID)
+	return mcp.NewToolResultText(result), nil
+}
+
+// createEngagement handles create_engagement. The target start defaults to
+// today and the end to a week later; the status follows from the start date.
+func (s *Server) createEngagement(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	productID, err := request.RequireInt("product_id")
+	if err != nil {
+		return nil, fmt.Errorf("invalid product_id: %w", err)
+	}
+	name, err := request.RequireString("name")
+	if err != nil {
+		return nil, fmt.Errorf("invalid name: %w", err)
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	start, err := dateArgument(request, "target_start", today)
+	if err != nil {
+		return nil, err
+	}
+	end, err := dateArgument(request, "target_end", start.AddDate(0, 0, defaultEngagementDays))
+	if err != nil {
+		return nil, err
+	}
+	if end.Before(start) {
+		return nil, fmt.Errorf("target_end %s is before target_start %s", end.Format(time.DateOnly), start.Format(time.DateOnly))
+	}
+	status := "In Progress"
+	if start.After(today) {
+		status = "Not Started"
+	}
+
+	product, err := s.ddClient.GetProduct(ctx, productID)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving product %d: %w", productID, err)
+	}
+	engagement, err := s.ddClient.CreateEngagement(ctx, types.CreateEngagementRequest{
+		Product:        productID,
+		Name:           strings.TrimSpace(name),
+		Description:    request.GetString("description", ""),
+		EngagementType: request.GetString("engagement_type", engagementTypeInteractive),
+		Status:         status,
+		TargetStart:    start.Format(time.DateOnly),
+		TargetEnd:      end.Format(time.DateOnly),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error creating engagement %q in product %d: %w", name, productID, err)
+	}
+
+	result := fmt.Sprintf("Created engagement %d: %s in product %s (ID: %d)\n", engagement.ID, engagement.Name, product.Name, productID)
+	result += fmt.Sprintf("%s, %s, %s to %s\n", engagement.EngagementType, engagement.Status, engagement.TargetStart, engagement.TargetEnd)
+	if link := s.links.engagement(engagement.ID); link != "" {
+		result += link + "\n"
+	}
+	result += fmt.Sprintf("\nNext: import scan results into it with import_sarif (engagement_id %d).\n", engagement.ID)
+	return mcp.NewToolResultText(result), nil
+}
+
+// dateArgument parses an optional YYYY-MM-DD argument, returning fallback when omitted
+func dateArgument(request mcp.CallToolRequest, name string, fallback time.Time) (time.Time, error) {
+	value := request.GetString(name, "")
+	if value == "" {
+		return fallback, nil
+	}
+	date, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s %q: expected a date like 2026-01-31", name, value)
+	}
+	return date, nil
+}
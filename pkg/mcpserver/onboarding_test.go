@@ -0,0 +1,93 @@
+package mcpserver
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brduru/mcp-defect-dojo/internal/defectdojo"
+)
+
+func TestProductOnboarding(t *testing.T) {
+	fixtures, err := defectdojo.NewFixtureClient("")
+	if err != nil {
+		t.Fatalf("NewFixtureClient() error = %v", err)
+	}
+	s := newServer(&Config{}, fixtures)
+
+	result, err := callTool(t, s, "create_product", map[string]any{
+		"name":         "Billing Service",
+		"product_type": "web applications",
+		"description":  "Invoices and payment reminders",
+		"tags":         []any{"team-billing"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text := resultText(result)
+	for _, want := range []string{"Created product 3: Billing Service", "Product type: Web Applications", "Tags: team-billing", "create_engagement (product_id 3)"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in:\n%s", want, text)
+		}
+	}
+
+	result, err = callTool(t, s, "create_engagement", map[string]any{"product_id": 3, "name": "CI scans", "engagement_type": "CI/CD"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	today := time.Now().UTC()
+	text = resultText(result)
+	for _, want := range []string{
+		"Created engagement 13: CI scans in product Billing Service (ID: 3)",
+		"CI/CD, In Progress, " + today.Format(time.DateOnly) + " to " + today.AddDate(0, 0, 7).Format(time.DateOnly),
+		"import_sarif (engagement_id 13)",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in:\n%s", want, text)
+		}
+	}
+	engagement, err := fixtures.GetEngagement(t.Context(), 13)
+	if err != nil || engagement.Product != 3 || !engagement.Active {
+		t.Errorf("expected an active engagement in the new product, got %+v, %v", engagement, err)
+	}
+
+	result, err = callTool(t, s, "create_engagement", map[string]any{"product_id": 3, "name": "Pentest", "target_start": "2099-03-01", "target_end": "2099-03-15"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := resultText(result); !strings.Contains(text, "Interactive, Not Started, 2099-03-01 to 2099-03-15") {
+		t.Errorf("expected a planned interactive engagement, got:\n%s", text)
+	}
+}
+
+func TestProductOnboardingErrors(t *testing.T) {
+	fixtures, err := defectdojo.NewFixtureClient("")
+	if err != nil {
+		t.Fatalf("NewFixtureClient() error = %v", err)
+	}
+	s := newServer(&Config{}, fixtures)
+	product := func(name, productType string) map[string]any {
+		return map[string]any{"name": name, "product_type": productType, "description": "test fixture"}
+	}
+
+	tests := []struct {
+		name string
+		tool string
+		args map[string]any
+		want string
+	}{
+		{"unknown product type", "create_product", product("New App", "Mainframes"), `unknown product type "Mainframes" (available: Internal Services, Web Applications)`},
+		{"product type by ID", "create_product", product("Payments API", "2"), "already exists"},
+		{"invalid start date", "create_engagement", map[string]any{"product_id": 1, "name": "CI", "target_start": "03/01/2026"}, `invalid target_start "03/01/2026"`},
+		{"end before start", "create_engagement", map[string]any{"product_id": 1, "name": "CI", "target_start": "2026-03-10", "target_end": "2026-03-01"}, "target_end 2026-03-01 is before target_start 2026-03-10"},
+		{"unknown product", "create_engagement", map[string]any{"product_id": 999, "name": "CI"}, "error retrieving product 999"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := callTool(t, s, tt.tool, tt.args)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,338 @@
+package mcpserver
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/brduru/mcp-defect-dojo/internal/requestid"
+)
+
+// Oversized tool results
+const (
+	fullResultTemplate     = "defectdojo://session/full-result/{id}"
+	defaultMaxResultBytes  = 100 << 10 // Results larger than this are summarized unless configured
+	summaryItems           = 10        // List items a summarized result keeps
+	maxFullResultsPerState = 10        // Full results one session keeps; older ones are dropped
+)
+
+var (
+	// textListItem starts a numbered entry of a text list, e.g. "3. [High] SQL Injection (ID: 42)"
+	textListItem = regexp.MustCompile(`^\d+\. (?:\[([^\]]+)\])?`)
+	// markdownTableDivider separates a markdown table's header from its rows
+	markdownTableDivider = regexp.MustCompile(`^\|[-:| ]+\|$`)
+)
+
+// fullResult is the complete text of a summarized tool result, kept in the
+// session and served as a resource
+type fullResult struct {
+	ID       string
+	Tool     string
+	SavedAt  time.Time
+	MIMEType string
+	Text     string
+}
+
+// resultSummary describes what a summarized result left out
+type resultSummary struct {
+	Note       string         `json:"note"`
+	Bytes      int            `json:"bytes"`                 // Size of the full result
+	Items      int            `json:"items,omitempty"`       // List items in the full result
+	Shown      int            `json:"shown,omitempty"`       // Of those, the items kept
+	BySeverity map[string]int `json:"by_severity,omitempty"` // List items by severity, when they have one
+	FullResult string         `json:"full_result"`           // Resource URI of the full result
+}
+
+// fullResultURI is the resource URI of a full result
+func fullResultURI(id string) string {
+	return strings.Replace(fullResultTemplate, "{id}", id, 1)
+}
+
+// saveFullResult keeps a full result in the call's session, dropping the
+// oldest once the session holds maxFullResultsPerState
+func (s *sessionStore) saveFullResult(ctx context.Context, result *fullResult) {
+	s.update(ctx, func(state *sessionState) error {
+		if len(state.fullResults) >= maxFullResultsPerState {
+			state.fullResults = slices.Delete(state.fullResults, 0, len(state.fullResults)-maxFullResultsPerState+1)
+		}
+		state.fullResults = append(state.fullResults, result)
+		return nil
+	})
+}
+
+// fullResult returns a full result of the call's session
+func (s *sessionStore) fullResult(ctx context.Context, id string) (*fullResult, error) {
+	var result *fullResult
+	s.update(ctx, func(state *sessionState) error {
+		if i := slices.IndexFunc(state.fullResults, func(r *fullResult) bool { return r.ID == id }); i >= 0 {
+			result = state.fullResults[i]
+		}
+		return nil
+	})
+	if result == nil {
+		return nil, fmt.Errorf("no full result %q in this session: only the last %d summarized results are kept, call the tool again", id, maxFullResultsPerState)
+	}
+	return result, nil
+}
+
+// readFullResult serves defectdojo://session/full-result/{id}
+func (s *Server) readFullResult(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	result, err := s.sessions.fullResult(ctx, resourceArgument(request, "id"))
+	if err != nil {
+		return nil, err
+	}
+	return []mcp.ResourceContents{mcp.TextResourceContents{URI: request.Params.URI, MIMEType: result.MIMEType, Text: result.Text}}, nil
+}
+
+// resultBytes is the size of a tool result's content as sent to the client
+func resultBytes(result *mcp.CallToolResult) int {
+	if result == nil {
+		return 0
+	}
+	size := 0
+	for _, content := range result.Content {
+		if text, ok := content.(mcp.TextContent); ok {
+			size += len(text.Text)
+			continue
+		}
+		encoded, _ := json.Marshal(content)
+		size += len(encoded)
+	}
+	if result.StructuredContent != nil {
+		encoded, _ := json.Marshal(result.StructuredContent)
+		size += len(encoded)
+	}
+	return size
+}
+
+// resultSizeMiddleware summarizes successful results larger than maxBytes,
+// which clients would otherwise cut off mid-finding. The largest text of the
+// result is replaced by its first list items with counts of the rest, and
+// the full text is kept in the session as a resource the summary links to.
+func resultSizeMiddleware(maxBytes int, sessions *sessionStore, stats *toolStats) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			result, err := next(ctx, request)
+			if err != nil || result == nil || result.IsError || resultBytes(result) <= maxBytes {
+				return result, err
+			}
+			largest := -1
+			for i, content := range result.Content {
+				if text, ok := content.(mcp.TextContent); ok && (largest < 0 || len(text.Text) > len(result.Content[largest].(mcp.TextContent).Text)) {
+					largest = i
+				}
+			}
+			if largest < 0 {
+				return result, nil
+			}
+
+			full := result.Content[largest].(mcp.TextContent).Text
+			saved := &fullResult{
+				ID:       cmp.Or(requestid.FromContext(ctx), requestid.New()),
+				Tool:     request.Params.Name,
+				SavedAt:  time.Now().UTC(),
+				MIMEType: "text/plain",
+				Text:     full,
+			}
+			if json.Valid([]byte(full)) {
+				saved.MIMEType = "application/json"
+			}
+			sessions.saveFullResult(ctx, saved)
+			stats.recordSummarized(request.Params.Name)
+
+			uri := fullResultURI(saved.ID)
+			summarized := *result
+			summarized.Content = slices.Clone(result.Content)
+			summarized.Content[largest] = mcp.NewTextContent(summarizeResultText(full, maxBytes, uri))
+			summarized.Content = append(summarized.Content, mcp.NewResourceLink(uri, "Full "+request.Params.Name+" result", fmt.Sprintf("The complete result, %s", formatBytes(len(full))), saved.MIMEType))
+			return &summarized, nil
+		}
+	}
+}
+
+// summarizeResultText shortens an oversized result text to fit maxBytes:
+// JSON keeps the first items of its largest list, text and markdown their
+// first list entries or table rows, and anything else its first lines. The
+// summary says what was left out and where the full result is.
+func summarizeResultText(text string, maxBytes int, uri string) string {
+	summary := resultSummary{Bytes: len(text), FullResult: uri}
+	if json.Valid([]byte(text)) {
+		if shortened, ok := summarizeJSON(text, maxBytes, &summary); ok {
+			return shortened
+		}
+	}
+
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	header, items, footer := splitListItems(lines)
+	var kept []string
+	if len(items) > 0 {
+		summary.Items = len(items)
+		summary.BySeverity = map[string]int{}
+		for _, item := range items {
+			if match := textListItem.FindStringSubmatch(item[0]); match != nil && match[1] != "" {
+				summary.BySeverity[match[1]]++
+			}
+		}
+		kept = slices.Clone(header)
+		for _, item := range items {
+			if summary.Shown == summaryItems || linesBytes(kept)+linesBytes(item)+linesBytes(footer) > maxBytes*3/4 {
+				break
+			}
+			kept = append(kept, item...)
+			summary.Shown++
+		}
+		kept = append(kept, footer...)
+	}
+	if summary.Shown == 0 {
+		// No list, or its first entry alone is too large: keep whole lines
+		kept = nil
+		for _, line := range lines {
+			if linesBytes(kept)+len(line)+1 > maxBytes*3/4 {
+				break
+			}
+			kept = append(kept, line)
+		}
+	}
+
+	summary.Note = summaryNote(summary)
+	note := "\n\n---\n" + summary.Note
+	if len(summary.BySeverity) > 0 {
+		var counts []string
+		for _, severity := range slices.Sorted(maps.Keys(summary.BySeverity)) {
+			counts = append(counts, fmt.Sprintf("%s: %d", severity, summary.BySeverity[severity]))
+		}
+		note += "\nEntries by severity: " + strings.Join(counts, ", ")
+	}
+	return strings.TrimRight(strings.Join(kept, "\n"), "\n") + note + "\n"
+}
+
+// summarizeJSON keeps the first items of the largest list of a JSON object or
+// array, adding the summary as a "summary" field
+func summarizeJSON(text string, maxBytes int, summary *resultSummary) (string, bool) {
+	var object map[string]json.RawMessage
+	var list []json.RawMessage
+	listField := ""
+	if err := json.Unmarshal([]byte(text), &object); err == nil {
+		for field, value := range object {
+			var items []json.RawMessage
+			if json.Unmarshal(value, &items) == nil && len(value) > len(object[listField]) {
+				list, listField = items, field
+			}
+		}
+		if listField == "" {
+			return "", false
+		}
+	} else if err := json.Unmarshal([]byte(text), &list); err != nil {
+		return "", false
+	} else {
+		object, listField = map[string]json.RawMessage{}, "items"
+	}
+
+	summary.Items = len(list)
+	summary.BySeverity = map[string]int{}
+	for _, item := range list {
+		var entry struct {
+			Severity string `json:"severity"`
+		}
+		if json.Unmarshal(item, &entry) == nil && entry.Severity != "" {
+			summary.BySeverity[entry.Severity]++
+		}
+	}
+	kept, size := []json.RawMessage{}, len(text)-len(object[listField])
+	for _, item := range list {
+		if len(kept) == summaryItems || size+len(item) > maxBytes*3/4 {
+			break
+		}
+		kept = append(kept, item)
+		size += len(item)
+	}
+	summary.Shown = len(kept)
+	summary.Note = summaryNote(*summary)
+
+	object[listField], _ = json.Marshal(kept)
+	object["summary"], _ = json.Marshal(summary)
+	output, err := json.MarshalIndent(object, "", "  ")
+	if err != nil {
+		return "", false
+	}
+	return string(output), true
+}
+
+// splitListItems splits result lines into the lines before the first list
+// entry, the entries with their continuation lines, and the lines after the
+// list. Entries are numbered text entries, continued by indented or blank
+// lines, or markdown table rows; no entries means no list.
+func splitListItems(lines []string) (header []string, items [][]string, footer []string) {
+	start := -1
+	isItem := textListItem.MatchString
+	continues := func(line string) bool { return strings.TrimSpace(line) == "" || strings.HasPrefix(line, " ") }
+	for i, line := range lines {
+		if textListItem.MatchString(line) {
+			start = i
+			break
+		}
+	}
+	if start < 0 {
+		for i, line := range lines {
+			if markdownTableDivider.MatchString(strings.TrimSpace(line)) && i+1 < len(lines) {
+				start = i + 1
+				isItem = func(line string) bool { return strings.HasPrefix(line, "|") }
+				continues = func(string) bool { return false }
+				break
+			}
+		}
+	}
+	if start < 0 {
+		return lines, nil, nil
+	}
+	end := start
+	for ; end < len(lines); end++ {
+		switch line := lines[end]; {
+		case isItem(line):
+			items = append(items, []string{line})
+		case continues(line) && len(items) > 0:
+			items[len(items)-1] = append(items[len(items)-1], line)
+		default:
+			return lines[:start], items, lines[end:]
+		}
+	}
+	return lines[:start], items, nil
+}
+
+// summaryNote tells the agent what a summary left out and how to get it
+func summaryNote(summary resultSummary) string {
+	note := fmt.Sprintf("Result summarized: the full result is %s, more than this server returns at once.", formatBytes(summary.Bytes))
+	if summary.Shown > 0 {
+		note += fmt.Sprintf(" Showing the first %d of %d entries.", summary.Shown, summary.Items)
+	}
+	return note + fmt.Sprintf(" Read the resource %s for all of it, or narrow the call with filters or a lower limit.", summary.FullResult)
+}
+
+// linesBytes is the size of lines joined by newlines
+func linesBytes(lines []string) int {
+	size := 0
+	for _, line := range lines {
+		size += len(line) + 1
+	}
+	return size
+}
+
+// formatBytes renders a size in bytes, KB or MB
+func formatBytes(n int) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.0f KB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d bytes", n)
+}
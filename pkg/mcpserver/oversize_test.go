@@ -0,0 +1,128 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestSummarizeResultText(t *testing.T) {
+	const uri = "defectdojo://session/full-result/abc"
+
+	var list strings.Builder
+	list.WriteString("Found 40 findings (showing 40):\n\n")
+	for i := 1; i <= 40; i++ {
+		severity := "High"
+		if i%4 == 0 {
+			severity = "Critical"
+		}
+		fmt.Fprintf(&list, "%d. [%s] Finding %d (ID: %d)\n   Active: true, Verified: false\n\n", i, severity, i, i)
+	}
+	list.WriteString("has_more: false\n")
+	text := summarizeResultText(list.String(), 4096, uri)
+	for _, want := range []string{
+		"Found 40 findings", "10. [High] Finding 10", "has_more: false",
+		"Showing the first 10 of 40 entries", "Read the resource " + uri, "Entries by severity: Critical: 10, High: 30",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "11. [") {
+		t.Errorf("expected only the first 10 entries in:\n%s", text)
+	}
+
+	// Table rows are entries; the lines after the table stay
+	var table strings.Builder
+	table.WriteString("**Findings**\n\n| ID | Severity |\n|---:|----------|\n")
+	for i := 1; i <= 200; i++ {
+		fmt.Fprintf(&table, "| %d | High |\n", i)
+	}
+	table.WriteString("\n_has_more: true_\n")
+	text = summarizeResultText(table.String(), 1024, uri)
+	if !strings.Contains(text, "| 10 | High |\n\n_has_more: true_") || strings.Contains(text, "| 11 |") || !strings.Contains(text, "first 10 of 200 entries") {
+		t.Errorf("unexpected table summary:\n%s", text)
+	}
+
+	// JSON keeps its other fields and shortens its largest list
+	findings := make([]map[string]any, 50)
+	for i := range findings {
+		findings[i] = map[string]any{"id": i + 1, "severity": "Medium", "description": strings.Repeat("x", 100)}
+	}
+	full, _ := json.Marshal(map[string]any{"count": 50, "results": findings, "cursor": map[string]any{"has_more": true}})
+	var output struct {
+		Count   int              `json:"count"`
+		Results []map[string]any `json:"results"`
+		Cursor  map[string]any   `json:"cursor"`
+		Summary resultSummary    `json:"summary"`
+	}
+	if err := json.Unmarshal([]byte(summarizeResultText(string(full), 2048, uri)), &output); err != nil {
+		t.Fatalf("invalid JSON summary: %v", err)
+	}
+	if output.Count != 50 || output.Cursor["has_more"] != true || len(output.Results) == 0 || len(output.Results) > summaryItems ||
+		output.Summary.Items != 50 || output.Summary.Shown != len(output.Results) || output.Summary.BySeverity["Medium"] != 50 || output.Summary.FullResult != uri {
+		t.Errorf("unexpected JSON summary %+v", output)
+	}
+
+	// Anything else keeps its first lines
+	text = summarizeResultText(strings.Repeat("line of text\n", 1000), 1024, uri)
+	if len(text) > 1024 || !strings.HasPrefix(text, "line of text\n") || !strings.Contains(text, "Result summarized: the full result is 13 KB") {
+		t.Errorf("unexpected plain summary (%d bytes):\n%s", len(text), text)
+	}
+}
+
+func TestResultSizeMiddleware(t *testing.T) {
+	s := newServer(&Config{Output: OutputConfig{MaxResultBytes: 4096}}, &MockDefectDojoClient{GetFindingsFunc: pagedFindings(250)})
+	base := serveStreamableHTTP(t, s)
+	first := connectAs(t, base, "")
+	second := connectAs(t, base, "")
+	ctx := context.Background()
+
+	result, err := first.CallTool(ctx, mcp.CallToolRequest{Params: mcp.CallToolParams{Name: toolGetFindings, Arguments: map[string]any{"limit": 100}}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := resultText(result); len(text) > 4096 || !strings.Contains(text, "Showing the first 10 of 100 entries") {
+		t.Errorf("expected a summary within 4 KiB, got %d bytes:\n%s", len(text), text)
+	}
+	var uri string
+	for _, content := range result.Content {
+		if link, ok := content.(mcp.ResourceLink); ok {
+			uri = link.URI
+		}
+	}
+	if !strings.HasPrefix(uri, "defectdojo://session/full-result/") {
+		t.Fatalf("expected a link to the full result, got %+v", result.Content)
+	}
+
+	contents, err := first.ReadResource(ctx, mcp.ReadResourceRequest{Params: mcp.ReadResourceParams{URI: uri}})
+	if err != nil {
+		t.Fatalf("ReadResource() error = %v", err)
+	}
+	if full := contents.Contents[0].(mcp.TextResourceContents).Text; !strings.Contains(full, "100. [High] Finding (ID: 100)") {
+		t.Errorf("expected the full result, got %d bytes", len(full))
+	}
+	if _, err := second.ReadResource(ctx, mcp.ReadResourceRequest{Params: mcp.ReadResourceParams{URI: uri}}); err == nil {
+		t.Error("expected another session not to see the full result")
+	}
+
+	// Small results pass through
+	result, err = first.CallTool(ctx, mcp.CallToolRequest{Params: mcp.CallToolParams{Name: toolGetFindings, Arguments: map[string]any{"limit": 5}}})
+	if err != nil || strings.Contains(resultText(result), "Result summarized") || len(result.Content) != 1 {
+		t.Errorf("expected a small result unchanged, got %+v (%v)", result, err)
+	}
+	if usage := s.stats.snapshot(); usage[0].Tool != toolGetFindings || usage[0].Summarized != 1 || usage[0].MaxResultBytes > 4096+1024 {
+		t.Errorf("unexpected statistics %+v", usage[0])
+	}
+
+	// A negative threshold turns summarizing off
+	s = newServer(&Config{Output: OutputConfig{MaxResultBytes: -1}}, &MockDefectDojoClient{GetFindingsFunc: pagedFindings(250)})
+	result, err = callTool(t, s, toolGetFindings, map[string]any{"limit": 100})
+	if err != nil || strings.Contains(resultText(result), "Result summarized") {
+		t.Errorf("expected the full result with summarizing off, got %v", err)
+	}
+}
@@ -0,0 +1,246 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+// Working set sizing
+const (
+	maxPinnedFindings  = 100            // Findings one session may keep pinned
+	sessionIdleTimeout = 24 * time.Hour // Sessions unused this long are forgotten
+)
+
+// sessionStore keeps per-MCP-session state in memory. Streamable HTTP
+// sessions end without telling the server, so state is forgotten once its
+// session has been idle for sessionIdleTimeout. Calls without a session, such
+// as those of in-process clients, share one entry.
+type sessionStore struct {
+	now func() time.Time
+
+	mu       sync.Mutex
+	sessions map[string]*sessionState
+}
+
+// sessionState is what the server remembers about one MCP session
+type sessionState struct {
+	pins        []pinnedFinding            // Working set, in pinning order
+	results     map[string]*savedResult    // Saved query results by name
+	exports     map[string]*findingsExport // Findings exports by name
+	fullResults []*fullResult              // Full texts of summarized results, oldest first
+	lastUsed    time.Time
+}
+
+// findingSummary identifies a finding in session state, as it was when stored
+type findingSummary struct {
+	ID       int    `json:"id"`
+	Title    string `json:"title"`
+	Severity string `json:"severity"`
+	Label    string `json:"severity_label,omitempty"` // Organization label of the severity, when configured
+	Active   bool   `json:"active"`
+	URL      string `json:"url,omitempty"`
+}
+
+// pinnedFinding is a finding of a session's working set
+type pinnedFinding struct {
+	Position int `json:"position"` // 1-based place in the working set
+	findingSummary
+	PinnedAt time.Time `json:"pinned_at"`
+}
+
+func newSessionStore() *sessionStore {
+	return &sessionStore{now: time.Now, sessions: map[string]*sessionState{}}
+}
+
+// sessionID returns the MCP session of a tool call, or "" without one
+func sessionID(ctx context.Context) string {
+	if session := server.ClientSessionFromContext(ctx); session != nil {
+		return session.SessionID()
+	}
+	return ""
+}
+
+// update runs fn on the state of the call's session, creating it if needed
+func (s *sessionStore) update(ctx context.Context, fn func(state *sessionState) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	for id, state := range s.sessions {
+		if now.Sub(state.lastUsed) > sessionIdleTimeout {
+			for _, export := range state.exports {
+				export.chunks.close()
+			}
+			delete(s.sessions, id)
+		}
+	}
+	id := sessionID(ctx)
+	state, ok := s.sessions[id]
+	if !ok {
+		state = &sessionState{}
+		s.sessions[id] = state
+	}
+	state.lastUsed = now
+	return fn(state)
+}
+
+// pinned returns a copy of the call's session working set, numbered from 1
+func (s *sessionStore) pinned(ctx context.Context) []pinnedFinding {
+	var pins []pinnedFinding
+	s.update(ctx, func(state *sessionState) error {
+		pins = slices.Clone(state.pins)
+		return nil
+	})
+	for i := range pins {
+		pins[i].Position = i + 1
+	}
+	return pins
+}
+
+// summarizeFinding keeps what session state shows of a finding
+func (s *Server) summarizeFinding(finding types.Finding) findingSummary {
+	return findingSummary{ID: finding.ID, Title: finding.Title, Severity: finding.Severity, Label: s.severity.label(finding.Severity), Active: finding.Active, URL: s.links.finding(finding.ID)}
+}
+
+// uniqueFindingIDs returns the finding_ids argument without repeats, in order
+func uniqueFindingIDs(request mcp.CallToolRequest) ([]int, error) {
+	var ids []int
+	for _, id := range request.GetIntSlice("finding_ids", nil) {
+		if id < 1 {
+			return nil, fmt.Errorf("invalid finding_ids: %d is not a finding ID", id)
+		}
+		if !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// pinFindings handles pin_findings. Every finding is looked up first, so a
+// mistyped ID pins nothing and positions stay predictable; findings already
+// pinned keep their position and get a fresh snapshot.
+func (s *Server) pinFindings(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ids, err := uniqueFindingIDs(request)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("finding_ids or saved_result must name at least one finding")
+	}
+
+	pins := make([]pinnedFinding, len(ids))
+	errs := make([]error, len(ids))
+	now := s.sessions.now().UTC()
+	s.workers.run(ctx, operationPinLookups, len(ids), func(i int) {
+		finding, err := s.ddClient.GetFindingDetail(ctx, ids[i])
+		if err != nil {
+			errs[i] = fmt.Errorf("finding %d: %w", ids[i], err)
+			return
+		}
+		pins[i] = pinnedFinding{findingSummary: s.summarizeFinding(s.sanitizedFinding(*finding)), PinnedAt: now}
+	})
+	var failed []string
+	for _, err := range errs {
+		if err != nil {
+			failed = append(failed, err.Error())
+		}
+	}
+	if len(failed) > 0 {
+		return nil, fmt.Errorf("nothing was pinned, these findings could not be read: %s", strings.Join(failed, "; "))
+	}
+
+	replace := request.GetBool("replace", false)
+	err = s.sessions.update(ctx, func(state *sessionState) error {
+		current := state.pins
+		if replace {
+			current = nil
+		}
+		updated := slices.Clone(current)
+		for _, pin := range pins {
+			if i := slices.IndexFunc(updated, func(p pinnedFinding) bool { return p.ID == pin.ID }); i >= 0 {
+				updated[i] = pin
+				continue
+			}
+			updated = append(updated, pin)
+		}
+		if len(updated) > maxPinnedFindings {
+			return fmt.Errorf("too many pinned findings (%d): a session may pin at most %d; unpin some with clear_pins or pass replace", len(updated), maxPinnedFindings)
+		}
+		state.pins = updated
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.renderPins(ctx, request, fmt.Sprintf("Pinned %d findings", len(ids)))
+}
+
+// getPinnedFindings handles get_pinned_findings
+func (s *Server) getPinnedFindings(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return s.renderPins(ctx, request, "")
+}
+
+// clearPins handles clear_pins: it unpins the given findings, or every
+// finding without finding_ids. The remaining pins are renumbered.
+func (s *Server) clearPins(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ids, err := uniqueFindingIDs(request)
+	if err != nil {
+		return nil, err
+	}
+	var removed int
+	s.sessions.update(ctx, func(state *sessionState) error {
+		before := len(state.pins)
+		if len(ids) == 0 {
+			state.pins = nil
+		} else {
+			state.pins = slices.DeleteFunc(state.pins, func(pin pinnedFinding) bool { return slices.Contains(ids, pin.ID) })
+		}
+		removed = before - len(state.pins)
+		return nil
+	})
+	return s.renderPins(ctx, request, fmt.Sprintf("Unpinned %d findings", removed))
+}
+
+// renderPins shows the working set of the call's session, after summary
+func (s *Server) renderPins(ctx context.Context, request mcp.CallToolRequest, summary string) (*mcp.CallToolResult, error) {
+	pins := s.sessions.pinned(ctx)
+	if s.outputFormat(request) == formatJSON {
+		output, err := marshalOutput(struct {
+			Pinned []pinnedFinding `json:"pinned"`
+		}{append([]pinnedFinding{}, pins...)})
+		if err != nil {
+			return nil, err
+		}
+		return mcp.NewToolResultText(output), nil
+	}
+
+	var result strings.Builder
+	if summary != "" {
+		result.WriteString(summary + "\n\n")
+	}
+	if len(pins) == 0 {
+		result.WriteString("No findings are pinned in this session; pin some with pin_findings\n")
+		return mcp.NewToolResultText(result.String()), nil
+	}
+	fmt.Fprintf(&result, "%d pinned findings, by position (as of when they were pinned):\n", len(pins))
+	for _, pin := range pins {
+		status := "inactive"
+		if pin.Active {
+			status = "active"
+		}
+		fmt.Fprintf(&result, "%d. [%s] %s (ID: %d, %s)", pin.Position, s.severity.display(pin.Severity), pin.Title, pin.ID, status)
+		if pin.URL != "" {
+			fmt.Fprintf(&result, " — %s", pin.URL)
+		}
+		result.WriteString("\n")
+	}
+	return mcp.NewToolResultText(result.String()), nil
+}
@@ -0,0 +1,97 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// callSessionTool calls a tool in mcpClient's session
+func callSessionTool(t *testing.T, mcpClient *client.Client, name string, args map[string]any) (string, error) {
+	t.Helper()
+	result, err := mcpClient.CallTool(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{Name: name, Arguments: args}})
+	if err != nil {
+		return "", err
+	}
+	return resultText(result), nil
+}
+
+func TestPinnedFindingsPerSession(t *testing.T) {
+	base := serveStreamableHTTP(t, newServer(&Config{}, &MockDefectDojoClient{}))
+	first := connectAs(t, base, "")
+	second := connectAs(t, base, "")
+
+	text, err := callSessionTool(t, first, toolPinFindings, map[string]any{"finding_ids": []int{3, 1, 3}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{"Pinned 2 findings", "1. [High] Test Finding 3 (ID: 3, active)", "2. [High] Test Finding 1 (ID: 1, active)"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in:\n%s", want, text)
+		}
+	}
+
+	// Re-pinning keeps a finding's position
+	if _, err := callSessionTool(t, first, toolPinFindings, map[string]any{"finding_ids": []int{2, 1}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text, _ = callSessionTool(t, first, toolGetPinned, map[string]any{"format": "json"})
+	var output struct {
+		Pinned []pinnedFinding `json:"pinned"`
+	}
+	if err := json.Unmarshal([]byte(text), &output); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, text)
+	}
+	var ids []int
+	for _, pin := range output.Pinned {
+		ids = append(ids, pin.ID)
+	}
+	if len(ids) != 3 || ids[0] != 3 || ids[1] != 1 || ids[2] != 2 || output.Pinned[2].Position != 3 {
+		t.Errorf("expected pins 3, 1, 2 in order, got %+v", output.Pinned)
+	}
+
+	// Sessions do not see each other's pins, and failed lookups pin nothing
+	if _, err := callSessionTool(t, second, toolPinFindings, map[string]any{"finding_ids": []int{5, 999}}); err == nil || !strings.Contains(err.Error(), "nothing was pinned") {
+		t.Errorf("expected the unknown finding to fail the call, got %v", err)
+	}
+	if text, _ := callSessionTool(t, second, toolGetPinned, nil); !strings.Contains(text, "No findings are pinned") {
+		t.Errorf("expected an empty working set, got:\n%s", text)
+	}
+
+	// Unpinning renumbers the rest
+	text, err = callSessionTool(t, first, toolClearPins, map[string]any{"finding_ids": []int{3}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(text, "Unpinned 1 findings") || !strings.Contains(text, "1. [High] Test Finding 1 (ID: 1") {
+		t.Errorf("unexpected clear_pins output:\n%s", text)
+	}
+	if text, _ := callSessionTool(t, first, toolClearPins, nil); !strings.Contains(text, "Unpinned 2 findings") {
+		t.Errorf("expected the remaining pins to be cleared, got:\n%s", text)
+	}
+}
+
+func TestSessionStoreExpiry(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := newSessionStore()
+	store.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	store.update(ctx, func(state *sessionState) error {
+		state.pins = []pinnedFinding{{findingSummary: findingSummary{ID: 7}}}
+		return nil
+	})
+	now = now.Add(sessionIdleTimeout)
+	if pins := store.pinned(ctx); len(pins) != 1 || pins[0].Position != 1 {
+		t.Errorf("expected the pin to survive until the timeout, got %+v", pins)
+	}
+	now = now.Add(sessionIdleTimeout + time.Second)
+	if pins := store.pinned(ctx); len(pins) != 0 {
+		t.Errorf("expected the idle session to be forgotten, got %+v", pins)
+	}
+}
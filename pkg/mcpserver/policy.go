@@ -0,0 +1,290 @@
+package mcpserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/brduru/mcp-defect-dojo/internal/defectdojo"
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+// WritePolicy holds the rules every write tool call must satisfy before it
+// reaches DefectDojo. The zero value allows everything.
+type WritePolicy struct {
+	// ProtectedSeverities lists severities that may not be marked false positive
+	ProtectedSeverities []string `json:"protected_severities,omitempty"`
+	// MaxBulkFindings limits how many findings one call may change (0 = unlimited)
+	MaxBulkFindings int `json:"max_bulk_findings,omitempty"`
+	// AllowedProductTags restricts writes to findings of products carrying
+	// one of these tags, e.g. ["sandbox"] (empty = all products)
+	AllowedProductTags []string `json:"allowed_product_tags,omitempty"`
+	// DowngradeApproval lists severities whose findings are only re-graded
+	// to a lower severity after a human approves, e.g. ["Critical"]. Such
+	// change_finding_severity calls are queued even outside approval mode.
+	DowngradeApproval []string `json:"downgrade_approval,omitempty"`
+
+	loadErr error // Set when the policy file was unusable; every write is then refused
+}
+
+// Policy rule names reported in PolicyError.Rule
+const (
+	ruleProtectedSeverities = "protected_severities"
+	ruleMaxBulkFindings     = "max_bulk_findings"
+	ruleAllowedProductTags  = "allowed_product_tags"
+	ruleDowngradeApproval   = "downgrade_approval"
+	rulePolicyUnavailable   = "policy_unavailable"
+)
+
+// PolicyError reports a write rejected by the WritePolicy, or a call outside
+// the caller's role or the per-product credentials. Its message is meant for
+// the agent: it names the rule so the agent can tell a policy decision from a
+// DefectDojo failure and stop retrying.
+type PolicyError struct {
+	Rule      string `json:"rule"`                 // Violated rule, e.g. "protected_severities"
+	Tool      string `json:"tool"`                 // Rejected tool call
+	FindingID int    `json:"finding_id,omitempty"` // Finding that violated the rule, if any
+	Reason    string `json:"reason"`               // Human-readable explanation
+}
+
+// Error implements error
+func (e *PolicyError) Error() string {
+	detail, _ := json.Marshal(e)
+	return fmt.Sprintf("policy violation: %s %s", e.Reason, detail)
+}
+
+// empty reports whether the policy has no rules
+func (p *WritePolicy) empty() bool {
+	return p == nil || (p.loadErr == nil && len(p.ProtectedSeverities) == 0 && p.MaxBulkFindings == 0 && len(p.AllowedProductTags) == 0 && len(p.DowngradeApproval) == 0)
+}
+
+// LoadWritePolicy reads a WritePolicy from a JSON file, e.g.
+//
+//	{"protected_severities": ["Critical"], "max_bulk_findings": 50, "allowed_product_tags": ["sandbox"], "downgrade_approval": ["Critical"]}
+//
+// Severities are normalized; unknown severities and negative limits are rejected.
+func LoadWritePolicy(path string) (*WritePolicy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading write policy: %w", err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	var policy WritePolicy
+	if err := decoder.Decode(&policy); err != nil {
+		return nil, fmt.Errorf("parsing write policy %s: %w", path, err)
+	}
+	if err := policy.normalize(); err != nil {
+		return nil, fmt.Errorf("invalid write policy in %s: %w", path, err)
+	}
+	return &policy, nil
+}
+
+// normalize canonicalizes severities and checks limits
+func (p *WritePolicy) normalize() error {
+	for rule, severities := range map[string][]string{ruleProtectedSeverities: p.ProtectedSeverities, ruleDowngradeApproval: p.DowngradeApproval} {
+		for i, value := range severities {
+			severity, ok := types.NormalizeSeverity(value)
+			if !ok {
+				return fmt.Errorf("%s: unknown severity %q (must be one of %s)", rule, value, strings.Join(types.ValidSeverities(), ", "))
+			}
+			severities[i] = severity
+		}
+	}
+	if p.MaxBulkFindings < 0 {
+		return fmt.Errorf("%s must not be negative", ruleMaxBulkFindings)
+	}
+	return nil
+}
+
+// holdsDowngrades reports whether some severity changes need human approval
+func (p *WritePolicy) holdsDowngrades() bool {
+	return p != nil && p.loadErr == nil && len(p.DowngradeApproval) > 0
+}
+
+// needsApproval applies downgrade_approval: a change_finding_severity call
+// lowering a finding whose current severity is listed is held for a human,
+// and the reason returned. The finding is fetched fresh; if it cannot be read
+// the call is refused.
+func (p *WritePolicy) needsApproval(ctx context.Context, ddClient defectdojo.Client, request mcp.CallToolRequest) (string, error) {
+	if request.Params.Name != toolChangeSeverity || !p.holdsDowngrades() {
+		return "", nil
+	}
+	severity, ok := types.NormalizeSeverity(request.GetString("severity", ""))
+	if !ok {
+		return "", nil // The handler reports the invalid severity
+	}
+	id := request.GetInt("finding_id", 0)
+	finding, err := ddClient.GetFindingDetail(ctx, id)
+	if err != nil {
+		return "", fmt.Errorf("policy check failed for finding %d: %w", id, err)
+	}
+	if !slices.Contains(p.DowngradeApproval, finding.Severity) || types.CompareSeverity(severity, finding.Severity) >= 0 {
+		return "", nil
+	}
+	return fmt.Sprintf("the write policy (%s) requires human approval to downgrade %s findings", ruleDowngradeApproval, finding.Severity), nil
+}
+
+// writePolicy returns the configured policy, loading Policy.FilePath when no
+// rules were set directly. A policy file that cannot be loaded denies every
+// write rather than silently allowing them.
+func writePolicy(cfg PolicyConfig) *WritePolicy {
+	if cfg.Rules != nil || cfg.FilePath == "" {
+		return cfg.Rules
+	}
+	policy, err := LoadWritePolicy(cfg.FilePath)
+	if err != nil {
+		log.Printf("⚠️  Write policy unusable, all writes will be rejected: %v", err)
+		return &WritePolicy{loadErr: err}
+	}
+	return policy
+}
+
+// policyMiddleware checks write tool calls against the policy before they run.
+// Findings are fetched fresh for every check so rules never act on stale data;
+// if a finding cannot be checked the write is refused.
+func policyMiddleware(policy *WritePolicy, ddClient defectdojo.Client) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if !writeTools[request.Params.Name] {
+				return next(ctx, request)
+			}
+			if err := policy.check(ctx, ddClient, request.Params.Name, policyFindingIDs(request)); err != nil {
+				return nil, err
+			}
+			if err := policy.checkImport(ctx, ddClient, request); err != nil {
+				return nil, err
+			}
+			if err := policy.checkCreate(ctx, ddClient, request); err != nil {
+				return nil, err
+			}
+			return next(ctx, request)
+		}
+	}
+}
+
+// policyFindingIDs returns the findings a write call targets: finding_id for
+// single-finding tools, finding_ids for bulk tools.
+func policyFindingIDs(request mcp.CallToolRequest) []int {
+	ids := request.GetIntSlice("finding_ids", nil)
+	if id := request.GetInt("finding_id", 0); id != 0 {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// check evaluates every rule for one write call
+func (p *WritePolicy) check(ctx context.Context, ddClient defectdojo.Client, tool string, findingIDs []int) error {
+	if p.loadErr != nil {
+		return &PolicyError{Rule: rulePolicyUnavailable, Tool: tool, Reason: "the write policy could not be loaded, so all writes are disabled"}
+	}
+	if p.MaxBulkFindings > 0 && len(findingIDs) > p.MaxBulkFindings {
+		return &PolicyError{Rule: ruleMaxBulkFindings, Tool: tool, Reason: fmt.Sprintf("a single call may change at most %d findings, got %d", p.MaxBulkFindings, len(findingIDs))}
+	}
+
+	checkSeverity := tool == toolMarkFalsePositive && len(p.ProtectedSeverities) > 0
+	if !checkSeverity && len(p.AllowedProductTags) == 0 {
+		return nil
+	}
+
+	for _, id := range findingIDs {
+		finding, err := ddClient.GetFindingDetail(ctx, id)
+		if err != nil {
+			return fmt.Errorf("policy check failed for finding %d: %w", id, err)
+		}
+		if checkSeverity && slices.Contains(p.ProtectedSeverities, finding.Severity) {
+			return &PolicyError{Rule: ruleProtectedSeverities, Tool: tool, FindingID: id, Reason: fmt.Sprintf("%s findings may not be marked false positive", finding.Severity)}
+		}
+		if len(p.AllowedProductTags) > 0 {
+			product, err := findingProduct(ctx, ddClient, finding)
+			if err != nil {
+				return fmt.Errorf("policy check failed for finding %d: %w", id, err)
+			}
+			if !p.allowsProduct(product) {
+				return &PolicyError{Rule: ruleAllowedProductTags, Tool: tool, FindingID: id, Reason: fmt.Sprintf("writes are only allowed on products tagged %s; product %q is not", strings.Join(p.AllowedProductTags, " or "), product.Name)}
+			}
+		}
+	}
+	return nil
+}
+
+// checkImport applies allowed_product_tags to scan imports. The target product
+// must be checked before anything is created, so imports addressed by product
+// name rather than engagement_id are refused while the rule is set.
+func (p *WritePolicy) checkImport(ctx context.Context, ddClient defectdojo.Client, request mcp.CallToolRequest) error {
+	tool := request.Params.Name
+	if tool != toolImportSARIF || len(p.AllowedProductTags) == 0 {
+		return nil
+	}
+	engagementID := request.GetInt("engagement_id", 0)
+	if engagementID == 0 {
+		return &PolicyError{Rule: ruleAllowedProductTags, Tool: tool, Reason: fmt.Sprintf("imports must target an engagement_id so the product can be checked for the %s tags", strings.Join(p.AllowedProductTags, " or "))}
+	}
+	engagement, err := ddClient.GetEngagement(ctx, engagementID)
+	if err != nil {
+		return fmt.Errorf("policy check failed for engagement %d: %w", engagementID, err)
+	}
+	return p.checkProduct(ctx, ddClient, tool, engagement.Product)
+}
+
+// checkCreate applies allowed_product_tags to the onboarding tools: a new
+// product must carry an allowed tag, and engagements may only be created in
+// allowed products.
+func (p *WritePolicy) checkCreate(ctx context.Context, ddClient defectdojo.Client, request mcp.CallToolRequest) error {
+	tool := request.Params.Name
+	if len(p.AllowedProductTags) == 0 {
+		return nil
+	}
+	switch tool {
+	case toolCreateProduct:
+		if !p.allowsProduct(&types.Product{Tags: request.GetStringSlice("tags", nil)}) {
+			return &PolicyError{Rule: ruleAllowedProductTags, Tool: tool, Reason: fmt.Sprintf("new products must be tagged %s", strings.Join(p.AllowedProductTags, " or "))}
+		}
+	case toolCreateEngagement:
+		return p.checkProduct(ctx, ddClient, tool, request.GetInt("product_id", 0))
+	}
+	return nil
+}
+
+// checkProduct returns a PolicyError if the product lacks the allowed tags
+func (p *WritePolicy) checkProduct(ctx context.Context, ddClient defectdojo.Client, tool string, productID int) error {
+	product, err := ddClient.GetProduct(ctx, productID)
+	if err != nil {
+		return fmt.Errorf("policy check failed for product %d: %w", productID, err)
+	}
+	if !p.allowsProduct(product) {
+		return &PolicyError{Rule: ruleAllowedProductTags, Tool: tool, Reason: fmt.Sprintf("writes are only allowed on products tagged %s; product %q is not", strings.Join(p.AllowedProductTags, " or "), product.Name)}
+	}
+	return nil
+}
+
+// allowsProduct reports whether a product carries one of the allowed tags
+func (p *WritePolicy) allowsProduct(product *types.Product) bool {
+	return slices.ContainsFunc(product.Tags, func(tag string) bool { return slices.Contains(p.AllowedProductTags, tag) })
+}
+
+// findingProduct resolves the product a finding belongs to through its test and engagement
+func findingProduct(ctx context.Context, ddClient defectdojo.Client, finding *types.Finding) (*types.Product, error) {
+	return testProduct(ctx, ddClient, finding.Test)
+}
+
+// testProduct resolves the product a test belongs to through its engagement
+func testProduct(ctx context.Context, ddClient defectdojo.Client, testID int) (*types.Product, error) {
+	test, err := ddClient.GetTest(ctx, testID)
+	if err != nil {
+		return nil, err
+	}
+	engagement, err := ddClient.GetEngagement(ctx, test.Engagement)
+	if err != nil {
+		return nil, err
+	}
+	return ddClient.GetProduct(ctx, engagement.Product)
+}
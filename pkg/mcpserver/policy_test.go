@@ -0,0 +1,171 @@
+package mcpserver
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/brduru/mcp-defect-dojo/internal/defectdojo"
+)
+
+func TestLoadWritePolicy(t *testing.T) {
+	dir := t.TempDir()
+	write := func(content string) string {
+		path := filepath.Join(dir, "policy.json")
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	policy, err := LoadWritePolicy(write(`{"protected_severities": ["critical"], "max_bulk_findings": 50, "allowed_product_tags": ["sandbox"], "downgrade_approval": ["high", "Critical"]}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(policy.ProtectedSeverities, []string{"Critical"}) || policy.MaxBulkFindings != 50 || !slices.Equal(policy.AllowedProductTags, []string{"sandbox"}) ||
+		!slices.Equal(policy.DowngradeApproval, []string{"High", "Critical"}) {
+		t.Errorf("unexpected policy: %+v", policy)
+	}
+
+	for name, content := range map[string]string{
+		"unknown severity":  `{"protected_severities": ["Urgent"]}`,
+		"unknown downgrade": `{"downgrade_approval": ["Severe"]}`,
+		"negative limit":    `{"max_bulk_findings": -1}`,
+		"unknown rule":      `{"deny_everything": true}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := LoadWritePolicy(write(content)); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestWritePolicyEnforcement(t *testing.T) {
+	newPolicyServer := func(t *testing.T, policy PolicyConfig, audit AuditLogger) *Server {
+		t.Helper()
+		fixtures, err := defectdojo.NewFixtureClient("")
+		if err != nil {
+			t.Fatalf("NewFixtureClient() error = %v", err)
+		}
+		return newServer(&Config{Policy: policy, Audit: AuditConfig{Logger: audit}}, fixtures)
+	}
+	markArgs := func(id int) map[string]any {
+		return map[string]any{"finding_id": id, "justification": "Test data"}
+	}
+
+	t.Run("protected severity", func(t *testing.T) {
+		var records []AuditRecord
+		audit := AuditLoggerFunc(func(ctx context.Context, record AuditRecord) error {
+			records = append(records, record)
+			return nil
+		})
+		s := newPolicyServer(t, PolicyConfig{Rules: &WritePolicy{ProtectedSeverities: []string{"Critical"}}}, audit)
+
+		_, err := callTool(t, s, "mark_finding_false_positive", markArgs(1))
+		if err == nil || !strings.Contains(err.Error(), `"rule":"protected_severities"`) || !strings.Contains(err.Error(), "Critical findings may not be marked false positive") {
+			t.Fatalf("expected a protected severity violation, got %v", err)
+		}
+		if len(records) != 1 || records[0].Success {
+			t.Errorf("expected the rejected write to be audited as a failure, got %+v", records)
+		}
+
+		if _, err := callTool(t, s, "mark_finding_false_positive", markArgs(2)); err != nil {
+			t.Errorf("expected High finding to be allowed, got %v", err)
+		}
+		if _, err := callTool(t, s, "clear_false_positive", markArgs(1)); err != nil {
+			t.Errorf("expected clearing a Critical false positive to be allowed, got %v", err)
+		}
+	})
+
+	t.Run("allowed product tags", func(t *testing.T) {
+		s := newPolicyServer(t, PolicyConfig{Rules: &WritePolicy{AllowedProductTags: []string{"sandbox"}}}, nil)
+
+		_, err := callTool(t, s, "clear_false_positive", markArgs(2))
+		if err == nil || !strings.Contains(err.Error(), `"rule":"allowed_product_tags"`) || !strings.Contains(err.Error(), `product "Payments API" is not`) {
+			t.Fatalf("expected an allowed product tags violation, got %v", err)
+		}
+		if _, err := callTool(t, s, "mark_finding_false_positive", markArgs(3)); err != nil {
+			t.Errorf("expected write on sandbox product to be allowed, got %v", err)
+		}
+	})
+
+	t.Run("allowed product tags on imports", func(t *testing.T) {
+		s := newPolicyServer(t, PolicyConfig{Rules: &WritePolicy{AllowedProductTags: []string{"sandbox"}}}, nil)
+		importArgs := func(args map[string]any) map[string]any {
+			args["sarif"] = sarifLog(sarifRunJSON("Semgrep", "error"))
+			return args
+		}
+
+		_, err := callTool(t, s, "import_sarif", importArgs(map[string]any{"engagement_id": 10}))
+		if err == nil || !strings.Contains(err.Error(), `product "Payments API" is not`) {
+			t.Errorf("expected an import into a production product to be refused, got %v", err)
+		}
+		_, err = callTool(t, s, "import_sarif", importArgs(map[string]any{"product_name": "Customer Portal", "engagement_name": "CI"}))
+		if err == nil || !strings.Contains(err.Error(), "imports must target an engagement_id") {
+			t.Errorf("expected an import by name to be refused, got %v", err)
+		}
+		// The offline fixtures cannot import, but the call gets past the policy
+		_, err = callTool(t, s, "import_sarif", importArgs(map[string]any{"engagement_id": 11}))
+		if err == nil || strings.Contains(err.Error(), "policy violation") {
+			t.Errorf("expected an import into a sandbox product to pass the policy, got %v", err)
+		}
+	})
+
+	t.Run("allowed product tags on onboarding", func(t *testing.T) {
+		s := newPolicyServer(t, PolicyConfig{Rules: &WritePolicy{AllowedProductTags: []string{"sandbox"}}}, nil)
+
+		_, err := callTool(t, s, "create_product", map[string]any{"name": "New App", "product_type": "1", "description": "test", "tags": []any{"team-a"}})
+		if err == nil || !strings.Contains(err.Error(), "new products must be tagged sandbox") {
+			t.Errorf("expected an untagged product to be refused, got %v", err)
+		}
+		if _, err := callTool(t, s, "create_product", map[string]any{"name": "New App", "product_type": "1", "description": "test", "tags": []any{"sandbox"}}); err != nil {
+			t.Errorf("expected a sandbox product to be allowed, got %v", err)
+		}
+		_, err = callTool(t, s, "create_engagement", map[string]any{"product_id": 1, "name": "CI"})
+		if err == nil || !strings.Contains(err.Error(), `product "Payments API" is not`) {
+			t.Errorf("expected an engagement in a production product to be refused, got %v", err)
+		}
+		if _, err := callTool(t, s, "create_engagement", map[string]any{"product_id": 2, "name": "CI"}); err != nil {
+			t.Errorf("expected an engagement in a sandbox product to be allowed, got %v", err)
+		}
+	})
+
+	t.Run("unchecked finding is refused", func(t *testing.T) {
+		s := newPolicyServer(t, PolicyConfig{Rules: &WritePolicy{ProtectedSeverities: []string{"Critical"}}}, nil)
+
+		_, err := callTool(t, s, "mark_finding_false_positive", markArgs(999))
+		if err == nil || !strings.Contains(err.Error(), "policy check failed for finding 999") {
+			t.Errorf("expected the policy check to fail closed, got %v", err)
+		}
+	})
+
+	t.Run("unusable policy file", func(t *testing.T) {
+		s := newPolicyServer(t, PolicyConfig{FilePath: filepath.Join(t.TempDir(), "missing.json")}, nil)
+
+		if _, err := callTool(t, s, "get_finding_detail", map[string]any{"finding_id": 1}); err != nil {
+			t.Errorf("expected reads to keep working, got %v", err)
+		}
+		_, err := callTool(t, s, "mark_finding_false_positive", markArgs(3))
+		if err == nil || !strings.Contains(err.Error(), `"rule":"policy_unavailable"`) {
+			t.Errorf("expected all writes to be refused, got %v", err)
+		}
+	})
+}
+
+func TestWritePolicyBulkLimit(t *testing.T) {
+	policy := &WritePolicy{MaxBulkFindings: 2}
+	err := policy.check(context.Background(), &MockDefectDojoClient{}, "bulk_tool", []int{1, 2, 3})
+
+	var policyErr *PolicyError
+	if !errors.As(err, &policyErr) || policyErr.Rule != ruleMaxBulkFindings {
+		t.Fatalf("expected a max_bulk_findings violation, got %v", err)
+	}
+	if err := policy.check(context.Background(), &MockDefectDojoClient{}, "bulk_tool", []int{1, 2}); err != nil {
+		t.Errorf("expected two findings to be allowed, got %v", err)
+	}
+}
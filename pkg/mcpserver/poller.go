@@ -0,0 +1,238 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/brduru/mcp-defect-dojo/internal/defectdojo"
+	"github.com/brduru/mcp-defect-dojo/internal/logfields"
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+// Poll sizing: one poll reads at most pollPageSize * maxPollPages findings
+const (
+	pollPageSize = 100
+	maxPollPages = 50
+)
+
+// maxDigestFindings bounds how many findings one digest lists per section
+const maxDigestFindings = 50
+
+// findingChange is a watched finding that appeared or changed since the last check
+type findingChange struct {
+	finding types.Finding
+	new     bool // First seen since the last check (otherwise changed)
+}
+
+// findingsPoller tracks the findings matched by one query and accumulates
+// what appeared or changed between digest calls. The first poll only records
+// a baseline, so existing findings are never reported as new.
+type findingsPoller struct {
+	query    findingsQuery
+	label    string // What is watched, for the digest header
+	interval time.Duration
+	run      func(ctx context.Context, query findingsQuery) (*types.FindingsResponse, error)
+
+	mu        sync.Mutex
+	seen      map[int]string // Finding ID -> fingerprint; nil until the baseline poll
+	changes   map[int]findingChange
+	lastPoll  time.Time
+	lastErr   error
+	checkedAt time.Time // Last digest call
+}
+
+// newPoller builds the poller for cfg. The watched findings are those of the
+// named saved query, or every active finding when no query is configured.
+func (s *Server) newPoller(cfg PollingConfig) (*findingsPoller, error) {
+	arguments, label := map[string]any{}, "active findings"
+	if cfg.Query != "" {
+		saved, ok := s.queries[cfg.Query]
+		if !ok {
+			return nil, fmt.Errorf("unknown saved query %q", cfg.Query)
+		}
+		arguments, label = maps.Clone(saved.Arguments), "saved query "+cfg.Query
+	}
+	arguments["limit"] = pollPageSize
+	delete(arguments, "offset")
+
+	var request mcp.CallToolRequest
+	request.Params.Name = toolGetFindings
+	request.Params.Arguments = arguments
+	query, err := s.parseFindingsQuery(request)
+	if err != nil {
+		return nil, err
+	}
+
+	// Poll with the credential of the query's product, as the query would run as a tool call
+	run := s.runFindingsQuery
+	if s.scopes != nil {
+		run = func(ctx context.Context, query findingsQuery) (*types.FindingsResponse, error) {
+			credential, err := s.scopes.choose(ctx, s.ddClient, request)
+			if err != nil {
+				return nil, err
+			}
+			return s.runFindingsQuery(defectdojo.WithAPIKey(ctx, credential.APIKey), query)
+		}
+	}
+	return &findingsPoller{
+		query:     query,
+		label:     label,
+		interval:  cfg.Interval,
+		run:       run,
+		changes:   map[int]findingChange{},
+		checkedAt: time.Now().UTC(),
+	}, nil
+}
+
+// fingerprint captures the finding state whose change is worth reporting
+func fingerprint(finding *types.Finding) string {
+	return fmt.Sprintf("%s|%s|%t|%t|%t|%t|%t|%t", finding.Modified.Format(time.RFC3339Nano), finding.Severity,
+		finding.Active, finding.Verified, finding.FalseP, finding.RiskAccepted, finding.IsMitigated, finding.Duplicate)
+}
+
+// poll reads every watched finding and records what is new or changed
+func (p *findingsPoller) poll(ctx context.Context) error {
+	current := map[int]types.Finding{}
+	query := p.query
+	for page := range maxPollPages {
+		query.filter.Offset = page * pollPageSize
+		response, err := p.run(ctx, query)
+		if err != nil {
+			p.mu.Lock()
+			p.lastErr = err
+			p.mu.Unlock()
+			return err
+		}
+		for _, finding := range response.Results {
+			current[finding.ID] = finding
+		}
+		if response.Next == nil {
+			break
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	seen := make(map[int]string, len(current))
+	for id, finding := range current {
+		seen[id] = fingerprint(&finding)
+		if p.seen == nil {
+			continue
+		}
+		previous, known := p.seen[id]
+		if known && previous == seen[id] {
+			continue
+		}
+		// A finding that appeared and then changed before the next check is still new
+		p.changes[id] = findingChange{finding: finding, new: !known || p.changes[id].new}
+	}
+	p.seen, p.lastPoll, p.lastErr = seen, time.Now().UTC(), nil
+	return nil
+}
+
+// take returns the accumulated changes, ordered by ID, and starts a new period
+func (p *findingsPoller) take() (changes []findingChange, since time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, id := range slices.Sorted(maps.Keys(p.changes)) {
+		changes = append(changes, p.changes[id])
+	}
+	since = p.checkedAt
+	p.changes = map[int]findingChange{}
+	p.checkedAt = time.Now().UTC()
+	return changes, since
+}
+
+// loop polls every interval until ctx is done
+func (p *findingsPoller) loop(ctx context.Context) {
+	ctx = logfields.With(ctx, logfields.Fields{Instance: defaultInstance})
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		if err := p.poll(ctx); err != nil && ctx.Err() == nil {
+			logfields.Printf(ctx, "⚠️  Findings poll failed: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// StartPolling runs the background findings poller until ctx is cancelled.
+// It does nothing unless PollingConfig.Interval is set; without it the
+// digest tool polls on demand instead.
+func (s *Server) StartPolling(ctx context.Context) {
+	if s.poller == nil || s.poller.interval <= 0 {
+		return
+	}
+	go s.poller.loop(ctx)
+}
+
+// getNewFindings handles get_new_findings_since_last_check
+func (s *Server) getNewFindings(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if s.poller == nil {
+		return nil, fmt.Errorf("findings polling is unavailable: check POLL_QUERY names a saved query")
+	}
+
+	p := s.poller
+	p.mu.Lock()
+	baseline, onDemand := p.seen == nil, p.interval <= 0
+	p.mu.Unlock()
+	if baseline || onDemand {
+		if err := p.poll(ctx); err != nil {
+			return nil, fmt.Errorf("error polling findings: %w", err)
+		}
+	}
+
+	changes, since := p.take()
+	p.mu.Lock()
+	watched, lastPoll, lastErr := len(p.seen), p.lastPoll, p.lastErr
+	p.mu.Unlock()
+
+	if baseline {
+		return mcp.NewToolResultText(fmt.Sprintf("Now watching %d findings (%s). Call again later to see what is new or changed since now.", watched, p.label)), nil
+	}
+
+	var added, changed []types.Finding
+	for _, change := range changes {
+		if change.new {
+			added = append(added, change.finding)
+		} else {
+			changed = append(changed, change.finding)
+		}
+	}
+
+	result := fmt.Sprintf("%d new and %d changed findings since %s (%s; %d watched, last poll %s)\n",
+		len(added), len(changed), since.Format(time.RFC3339), p.label, watched, lastPoll.Format(time.RFC3339))
+	if lastErr != nil {
+		result += fmt.Sprintf("⚠️ The latest poll failed, results may be stale: %v\n", lastErr)
+	}
+
+	opts := s.listFormatOptions(request)
+	for _, section := range []struct {
+		title    string
+		findings []types.Finding
+	}{{"New", added}, {"Changed", changed}} {
+		if len(section.findings) == 0 {
+			continue
+		}
+		result += fmt.Sprintf("\n%s:\n", section.title)
+		for i, finding := range section.findings[:min(len(section.findings), maxDigestFindings)] {
+			result += formatFindingSummary(i+1, &finding, opts)
+		}
+		if hidden := len(section.findings) - maxDigestFindings; hidden > 0 {
+			result += fmt.Sprintf("... and %d more\n", hidden)
+		}
+	}
+	return mcp.NewToolResultText(result), nil
+}
@@ -0,0 +1,136 @@
+package mcpserver
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+func TestNewFindingsDigest(t *testing.T) {
+	findings := []types.Finding{
+		{ID: 1, Title: "SQL Injection", Severity: "Critical", Active: true},
+		{ID: 2, Title: "Reflected XSS", Severity: "High", Active: true},
+	}
+	var pollErr error
+	var filters []types.FindingsFilter
+	mock := &MockDefectDojoClient{
+		GetFindingsFunc: func(ctx context.Context, filter types.FindingsFilter) (*types.FindingsResponse, error) {
+			filters = append(filters, filter)
+			if pollErr != nil {
+				return nil, pollErr
+			}
+			return &types.FindingsResponse{Count: len(findings), Results: append([]types.Finding(nil), findings...)}, nil
+		},
+	}
+	s := newServer(&Config{}, mock)
+
+	result, err := callTool(t, s, "get_new_findings_since_last_check", map[string]any{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := resultText(result); !strings.Contains(text, "Now watching 2 findings (active findings)") {
+		t.Errorf("expected the first call to set a baseline, got: %s", text)
+	}
+	if len(filters) != 1 || filters[0].Limit != pollPageSize || !filters[0].ActiveOnly {
+		t.Errorf("expected one page of active findings to be polled, got %+v", filters)
+	}
+
+	findings[1].Verified = true
+	findings[1].Modified = time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	findings = append(findings, types.Finding{ID: 3, Title: "Open redirect", Severity: "Medium", Active: true})
+
+	result, err = callTool(t, s, "get_new_findings_since_last_check", map[string]any{"detail_level": "summary"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text := resultText(result)
+	for _, want := range []string{"1 new and 1 changed findings since", "3 watched", "New:\n1. [Medium] Open redirect (ID: 3)", "Changed:\n1. [High] Reflected XSS (ID: 2)"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in digest:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "SQL Injection") {
+		t.Errorf("expected unchanged findings to be left out:\n%s", text)
+	}
+
+	result, err = callTool(t, s, "get_new_findings_since_last_check", map[string]any{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := resultText(result); !strings.Contains(text, "0 new and 0 changed findings") {
+		t.Errorf("expected the watermark to advance, got: %s", text)
+	}
+
+	pollErr = errors.New("connection refused")
+	if _, err := callTool(t, s, "get_new_findings_since_last_check", map[string]any{}); err == nil || !strings.Contains(err.Error(), "connection refused") {
+		t.Errorf("expected the poll error, got %v", err)
+	}
+}
+
+func TestFindingsPollerBackground(t *testing.T) {
+	findings := []types.Finding{{ID: 1, Title: "SQL Injection", Severity: "Critical", Active: true}}
+	polled := make(chan struct{}, 10)
+	mock := &MockDefectDojoClient{
+		GetFindingsFunc: func(ctx context.Context, filter types.FindingsFilter) (*types.FindingsResponse, error) {
+			defer func() { polled <- struct{}{} }()
+			return &types.FindingsResponse{Count: len(findings), Results: append([]types.Finding(nil), findings...)}, nil
+		},
+	}
+	s := newServer(&Config{Polling: PollingConfig{Interval: time.Hour}}, mock)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s.StartPolling(ctx)
+	select {
+	case <-polled:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected an initial background poll")
+	}
+
+	// The background poll set the baseline; this poll finds the new finding
+	findings = append(findings, types.Finding{ID: 2, Title: "Reflected XSS", Severity: "High", Active: true})
+	if err := s.poller.poll(ctx); err != nil {
+		t.Fatal(err)
+	}
+	result, err := callTool(t, s, "get_new_findings_since_last_check", map[string]any{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := resultText(result); !strings.Contains(text, "1 new and 0 changed") || !strings.Contains(text, "Reflected XSS") {
+		t.Errorf("expected the background poll results, got: %s", text)
+	}
+}
+
+func TestFindingsPollerSavedQuery(t *testing.T) {
+	var got types.FindingsFilter
+	mock := &MockDefectDojoClient{
+		GetFindingsFunc: func(ctx context.Context, filter types.FindingsFilter) (*types.FindingsResponse, error) {
+			got = filter
+			return &types.FindingsResponse{}, nil
+		},
+	}
+	s := newServer(&Config{
+		Queries: QueriesConfig{Saved: map[string]SavedQuery{"crit": {Arguments: map[string]any{"severity": "Critical", "product": 4, "limit": 5, "offset": 20}}}},
+		Polling: PollingConfig{Query: "crit"},
+	}, mock)
+
+	result, err := callTool(t, s, "get_new_findings_since_last_check", map[string]any{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := resultText(result); !strings.Contains(text, "saved query crit") {
+		t.Errorf("expected the saved query to be named, got: %s", text)
+	}
+	if got.Severity != "Critical" || got.Product == nil || *got.Product != 4 || got.Limit != pollPageSize || got.Offset != 0 {
+		t.Errorf("expected the saved query filter with poller paging, got %+v", got)
+	}
+
+	s = newServer(&Config{Polling: PollingConfig{Query: "missing"}}, mock)
+	if _, err := callTool(t, s, "get_new_findings_since_last_check", map[string]any{}); err == nil {
+		t.Error("expected an unknown polling query to be reported")
+	}
+}
@@ -0,0 +1,346 @@
+package mcpserver
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+// Posture summary sizing
+const (
+	posturePageSize         = 100 // Findings or products per query page
+	maxPostureProducts      = 500 // Products one summary may cover
+	maxPostureFindingsPages = 10  // Open findings pages scanned per product for SLA breaches
+	postureWeek             = 7   // Days in the reporting period
+	defaultPostureTopN      = 10  // Products listed in the summary by default
+	maxPostureTopN          = 100 // Products one summary may list
+)
+
+// severityCounts counts findings per DefectDojo severity
+type severityCounts struct {
+	Critical int `json:"critical"`
+	High     int `json:"high"`
+	Medium   int `json:"medium"`
+	Low      int `json:"low"`
+	Info     int `json:"info"`
+}
+
+// add counts one finding of the given severity
+func (c *severityCounts) add(severity string, n int) {
+	switch severity {
+	case types.SeverityCritical:
+		c.Critical += n
+	case types.SeverityHigh:
+		c.High += n
+	case types.SeverityMedium:
+		c.Medium += n
+	case types.SeverityLow:
+		c.Low += n
+	case types.SeverityInfo:
+		c.Info += n
+	}
+}
+
+// plus returns the sum of two counts
+func (c severityCounts) plus(other severityCounts) severityCounts {
+	return severityCounts{
+		Critical: c.Critical + other.Critical,
+		High:     c.High + other.High,
+		Medium:   c.Medium + other.Medium,
+		Low:      c.Low + other.Low,
+		Info:     c.Info + other.Info,
+	}
+}
+
+// compare orders counts by their most severe findings: more critical findings
+// sort first, then more high ones and so on
+func (c severityCounts) compare(other severityCounts) int {
+	return cmp.Or(
+		cmp.Compare(other.Critical, c.Critical),
+		cmp.Compare(other.High, c.High),
+		cmp.Compare(other.Medium, c.Medium),
+		cmp.Compare(other.Low, c.Low),
+		cmp.Compare(other.Info, c.Info),
+	)
+}
+
+// String lists the non-zero counts, most severe first, e.g. "1 critical, 3 low"
+func (c severityCounts) String() string {
+	var parts []string
+	for _, count := range []struct {
+		n    int
+		name string
+	}{{c.Critical, "critical"}, {c.High, "high"}, {c.Medium, "medium"}, {c.Low, "low"}, {c.Info, "info"}} {
+		if count.n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", count.n, count.name))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// sparkBars are the bars of a sparkline, lowest first
+var sparkBars = []rune("▁▂▃▄▅▆▇█")
+
+// severityHistogram is a compact severity distribution that chat clients can
+// render as is, without another call
+type severityHistogram struct {
+	Counts    []int  `json:"counts"`    // Critical, High, Medium, Low, Info
+	Percent   []int  `json:"percent"`   // Share of each severity in the total, rounded
+	Sparkline string `json:"sparkline"` // One bar per severity scaled to the largest count, "·" for none
+}
+
+// histogram returns the distribution of the counts
+func (c severityCounts) histogram() severityHistogram {
+	counts := []int{c.Critical, c.High, c.Medium, c.Low, c.Info}
+	total, largest := 0, 0
+	for _, n := range counts {
+		total += n
+		largest = max(largest, n)
+	}
+	histogram := severityHistogram{Counts: counts, Percent: make([]int, len(counts))}
+	var sparkline strings.Builder
+	for i, n := range counts {
+		if n == 0 {
+			sparkline.WriteRune('·')
+			continue
+		}
+		histogram.Percent[i] = (n*100 + total/2) / total
+		sparkline.WriteRune(sparkBars[(n*len(sparkBars)-1)/largest])
+	}
+	histogram.Sparkline = sparkline.String()
+	return histogram
+}
+
+// postureMetrics are the figures reported per product and for the portfolio
+type postureMetrics struct {
+	Open              int            `json:"open"`
+	OpenBySeverity    severityCounts `json:"open_by_severity"`
+	SLABreaches       int            `json:"sla_breaches"`
+	NewThisWeek       int            `json:"new_this_week"`       // Discovered in the period, in any state
+	MitigatedThisWeek int            `json:"mitigated_this_week"` // Mitigated in the period
+	OpenChange        int            `json:"open_change"`         // new_this_week - mitigated_this_week
+}
+
+// plus returns the sum of two sets of metrics
+func (m postureMetrics) plus(other postureMetrics) postureMetrics {
+	return postureMetrics{
+		Open:              m.Open + other.Open,
+		OpenBySeverity:    m.OpenBySeverity.plus(other.OpenBySeverity),
+		SLABreaches:       m.SLABreaches + other.SLABreaches,
+		NewThisWeek:       m.NewThisWeek + other.NewThisWeek,
+		MitigatedThisWeek: m.MitigatedThisWeek + other.MitigatedThisWeek,
+		OpenChange:        m.OpenChange + other.OpenChange,
+	}
+}
+
+// productPosture is one product's entry in the summary
+type productPosture struct {
+	ID   int      `json:"id"`
+	Name string   `json:"name"`
+	URL  string   `json:"url,omitempty"` // DefectDojo UI page of the product
+	Tags []string `json:"tags,omitempty"`
+	postureMetrics
+	SeverityHistogram   severityHistogram `json:"severity_histogram"` // Open findings by severity
+	OldestOpenDays      *int              `json:"oldest_open_days,omitempty"`
+	SLABreachesComplete bool              `json:"sla_breaches_complete"` // False when only the first open findings were scanned
+
+	err error
+}
+
+// postureSummary is the output of summarize_security_posture
+type postureSummary struct {
+	GeneratedAt time.Time         `json:"generated_at"`
+	PeriodStart time.Time         `json:"period_start"`
+	Scope       string            `json:"scope"`
+	Products    int               `json:"products"`
+	Totals      postureMetrics    `json:"totals"`
+	Histogram   severityHistogram `json:"severity_histogram"` // Open findings by severity across all products
+	TopProducts []productPosture  `json:"top_products"`       // Most exposed first: critical, high, then open findings
+	Quiet       int               `json:"quiet_products"`
+	Errors      []string          `json:"errors,omitempty"`
+	Notes       []string          `json:"notes,omitempty"`
+}
+
+// listProducts pages through every product, keeping those with any of the tags
+func (s *Server) listProducts(ctx context.Context, tags []string) ([]types.Product, int, error) {
+	var products []types.Product
+	offset, total := 0, 0
+	for {
+		if err := budgetFrom(ctx).spendPage(); err != nil {
+			return nil, 0, err
+		}
+		page, err := s.ddClient.ListProducts(ctx, posturePageSize, offset)
+		if err != nil {
+			return nil, 0, err
+		}
+		total = page.Count
+		for _, product := range page.Results {
+			if len(tags) == 0 || slices.ContainsFunc(product.Tags, func(tag string) bool { return slices.Contains(tags, tag) }) {
+				products = append(products, product)
+			}
+		}
+		offset += len(page.Results)
+		if page.Next == nil || len(page.Results) == 0 || offset >= maxPostureProducts {
+			return products, total, nil
+		}
+	}
+}
+
+// summarizeProduct computes one product's metrics. Open findings are scanned
+// for SLA breaches and age; severity counts come from the scan when it saw
+// every open finding, and from count-only queries otherwise.
+func (s *Server) summarizeProduct(ctx context.Context, product types.Product, now, periodStart time.Time) productPosture {
+	result := productPosture{ID: product.ID, Name: product.Name, URL: s.links.product(product.ID), Tags: product.Tags, SLABreachesComplete: true}
+	active := true
+	base := types.FindingsFilter{Product: &product.ID}
+
+	open := base
+	open.Active = &active
+	open.Limit = posturePageSize
+	scanned := 0
+	for page := range maxPostureFindingsPages {
+		open.Offset = page * posturePageSize
+		response, err := s.findingsPage(ctx, open)
+		if err != nil {
+			result.err = fmt.Errorf("open findings: %w", err)
+			return result
+		}
+		result.Open = response.Count
+		for _, finding := range response.Results {
+			result.OpenBySeverity.add(finding.Severity, 1)
+			if finding.IsOverSLA(now) {
+				result.SLABreaches++
+			}
+			if days, ok := ageDays(&finding); ok && (result.OldestOpenDays == nil || days > *result.OldestOpenDays) {
+				result.OldestOpenDays = &days
+			}
+		}
+		scanned += len(response.Results)
+		if response.Next == nil || len(response.Results) == 0 {
+			break
+		}
+	}
+
+	if scanned < result.Open {
+		result.SLABreachesComplete = false
+		result.OpenBySeverity = severityCounts{}
+		for _, severity := range types.ValidSeverities() {
+			count := open
+			count.Severity, count.Offset, count.Limit = severity, 0, 1
+			response, err := s.findingsPage(ctx, count)
+			if err != nil {
+				result.err = fmt.Errorf("%s findings: %w", severity, err)
+				return result
+			}
+			result.OpenBySeverity.add(severity, response.Count)
+		}
+	}
+
+	discovered := base
+	discovered.DiscoveredAfter, discovered.Limit = periodStart, 1
+	response, err := s.findingsPage(ctx, discovered)
+	if err != nil {
+		result.err = fmt.Errorf("new findings: %w", err)
+		return result
+	}
+	result.NewThisWeek = response.Count
+
+	mitigated := base
+	mitigated.MitigatedAfter, mitigated.Limit = periodStart, 1
+	response, err = s.findingsPage(ctx, mitigated)
+	if err != nil {
+		result.err = fmt.Errorf("mitigated findings: %w", err)
+		return result
+	}
+	result.MitigatedThisWeek = response.Count
+	result.OpenChange = result.NewThisWeek - result.MitigatedThisWeek
+	return result
+}
+
+// summarizePosture aggregates the products' metrics, summarizing them on the worker pool
+func (s *Server) summarizePosture(ctx context.Context, products []types.Product, now time.Time, topN int) postureSummary {
+	periodStart := now.AddDate(0, 0, -postureWeek).Truncate(24 * time.Hour)
+	summary := postureSummary{GeneratedAt: now, PeriodStart: periodStart, Products: len(products), TopProducts: []productPosture{}}
+
+	results := make([]productPosture, len(products))
+	s.workers.run(ctx, operationProductPosture, len(products), func(i int) {
+		results[i] = s.summarizeProduct(ctx, products[i], now, periodStart)
+	})
+
+	var reported []productPosture
+	incomplete := 0
+	for _, result := range results {
+		if result.err != nil {
+			summary.Errors = append(summary.Errors, fmt.Sprintf("product %d (%s): %v", result.ID, result.Name, result.err))
+			continue
+		}
+		summary.Totals = summary.Totals.plus(result.postureMetrics)
+		result.SeverityHistogram = result.OpenBySeverity.histogram()
+		if !result.SLABreachesComplete {
+			incomplete++
+		}
+		if result.Open == 0 && result.NewThisWeek == 0 && result.MitigatedThisWeek == 0 {
+			summary.Quiet++
+			continue
+		}
+		reported = append(reported, result)
+	}
+
+	slices.SortStableFunc(reported, func(a, b productPosture) int {
+		return cmp.Or(
+			cmp.Compare(b.OpenBySeverity.Critical, a.OpenBySeverity.Critical),
+			cmp.Compare(b.OpenBySeverity.High, a.OpenBySeverity.High),
+			cmp.Compare(b.Open, a.Open),
+			cmp.Compare(a.ID, b.ID),
+		)
+	})
+	if len(reported) > topN {
+		summary.Notes = append(summary.Notes, fmt.Sprintf("%d more products with findings are included in totals but not listed", len(reported)-topN))
+		reported = reported[:topN]
+	}
+	summary.TopProducts = append(summary.TopProducts, reported...)
+	summary.Histogram = summary.Totals.OpenBySeverity.histogram()
+	if incomplete > 0 {
+		summary.Notes = append(summary.Notes, fmt.Sprintf("SLA breaches are a lower bound for %d products with more than %d open findings", incomplete, maxPostureFindingsPages*posturePageSize))
+	}
+	return summary
+}
+
+// summarizeSecurityPosture handles summarize_security_posture
+func (s *Server) summarizeSecurityPosture(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	tags := request.GetStringSlice("product_tags", nil)
+	topN := min(request.GetInt("top_products", defaultPostureTopN), maxPostureTopN)
+
+	products, total, err := s.listProducts(ctx, tags)
+	if err != nil {
+		return nil, fmt.Errorf("error listing products: %w", err)
+	}
+	if len(products) == 0 {
+		return nil, fmt.Errorf("no products to summarize (%d visible, none tagged %s)", total, strings.Join(tags, " or "))
+	}
+
+	summary := s.summarizePosture(ctx, products, time.Now().UTC(), topN)
+	summary.Scope = "all products"
+	if len(tags) > 0 {
+		summary.Scope = "products tagged " + strings.Join(tags, " or ")
+	}
+	if total > maxPostureProducts {
+		summary.Notes = append(summary.Notes, fmt.Sprintf("only the first %d of %d products were considered", maxPostureProducts, total))
+	}
+	if len(summary.Errors) == len(products) {
+		return nil, fmt.Errorf("posture summary failed for every product: %s", summary.Errors[0])
+	}
+
+	output, err := marshalOutput(summary)
+	if err != nil {
+		return nil, err
+	}
+	return mcp.NewToolResultStructured(summary, output), nil
+}
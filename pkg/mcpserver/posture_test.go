@@ -0,0 +1,138 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brduru/mcp-defect-dojo/internal/defectdojo"
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+func TestSummarizePosture(t *testing.T) {
+	fixtures, err := defectdojo.NewFixtureClient("")
+	if err != nil {
+		t.Fatalf("NewFixtureClient() error = %v", err)
+	}
+	s := newServer(&Config{}, fixtures)
+	ctx := context.Background()
+
+	products, total, err := s.listProducts(ctx, nil)
+	if err != nil || total != 2 || len(products) != 2 {
+		t.Fatalf("listProducts() = %d of %d products, %v", len(products), total, err)
+	}
+
+	summary := s.summarizePosture(ctx, products, time.Date(2026, 8, 15, 9, 0, 0, 0, time.UTC), defaultPostureTopN)
+	want := postureMetrics{
+		Open:           5,
+		OpenBySeverity: severityCounts{Critical: 1, High: 2, Medium: 1, Low: 1},
+		SLABreaches:    1,
+		NewThisWeek:    3,
+		OpenChange:     3,
+	}
+	if summary.Totals != want {
+		t.Errorf("totals = %+v, want %+v", summary.Totals, want)
+	}
+	if !summary.PeriodStart.Equal(time.Date(2026, 8, 8, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("period start = %v", summary.PeriodStart)
+	}
+	if len(summary.TopProducts) != 2 || summary.TopProducts[0].Name != "Payments API" || summary.TopProducts[1].NewThisWeek != 3 {
+		t.Errorf("expected the product with a critical finding first, got %+v", summary.TopProducts)
+	}
+	if !summary.TopProducts[0].SLABreachesComplete || len(summary.Errors) > 0 || len(summary.Notes) > 0 {
+		t.Errorf("expected a complete summary, got %+v", summary)
+	}
+	if summary.Histogram.Sparkline != "▄█▄▄·" || summary.Histogram.Percent[1] != 40 || summary.TopProducts[0].SeverityHistogram.Counts[0] != 1 {
+		t.Errorf("unexpected severity histograms: overall %+v, first product %+v", summary.Histogram, summary.TopProducts[0].SeverityHistogram)
+	}
+
+	// Quiet products count in totals but are not listed
+	summary = s.summarizePosture(ctx, products, time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), 1)
+	if summary.Totals.NewThisWeek != 0 || len(summary.TopProducts) != 1 || len(summary.Notes) != 1 {
+		t.Errorf("expected one listed product and a note, got %+v", summary)
+	}
+}
+
+func TestSeverityHistogram(t *testing.T) {
+	histogram := severityCounts{Critical: 1, High: 100, Low: 49}.histogram()
+	if histogram.Sparkline != "▁█·▄·" {
+		t.Errorf("sparkline = %q", histogram.Sparkline)
+	}
+	if want := []int{1, 67, 0, 33, 0}; !slices.Equal(histogram.Percent, want) {
+		t.Errorf("percent = %v, want %v", histogram.Percent, want)
+	}
+	if empty := (severityCounts{}).histogram(); empty.Sparkline != "·····" || !slices.Equal(empty.Percent, []int{0, 0, 0, 0, 0}) {
+		t.Errorf("unexpected empty histogram %+v", empty)
+	}
+}
+
+func TestSummarizeSecurityPostureTool(t *testing.T) {
+	fixtures, err := defectdojo.NewFixtureClient("")
+	if err != nil {
+		t.Fatalf("NewFixtureClient() error = %v", err)
+	}
+	s := newServer(&Config{}, fixtures)
+
+	result, err := callTool(t, s, "summarize_security_posture", map[string]any{"product_tags": []any{"sandbox"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var summary postureSummary
+	if err := json.Unmarshal([]byte(resultText(result)), &summary); err != nil {
+		t.Fatalf("invalid JSON output: %v", err)
+	}
+	if summary.Products != 1 || summary.Scope != "products tagged sandbox" || summary.Totals.Open != 2 || summary.TopProducts[0].ID != 2 {
+		t.Errorf("unexpected summary: %+v", summary)
+	}
+	if structured, ok := result.StructuredContent.(map[string]any); !ok || structured["severity_histogram"].(map[string]any)["sparkline"] != "·██··" {
+		t.Errorf("expected the summary as structured content, got %#v", result.StructuredContent)
+	}
+
+	if _, err := callTool(t, s, "summarize_security_posture", map[string]any{"product_tags": []any{"retired"}}); err == nil {
+		t.Error("expected an error when no product matches")
+	}
+}
+
+func TestSummarizePostureLargeAndFailingProducts(t *testing.T) {
+	mock := &MockDefectDojoClient{
+		GetFindingsFunc: func(ctx context.Context, filter types.FindingsFilter) (*types.FindingsResponse, error) {
+			if *filter.Product == 2 {
+				return nil, errors.New("permission denied")
+			}
+			// Product 1 has more open findings than one summary scans
+			count := maxPostureFindingsPages*posturePageSize + 50
+			if filter.Severity != "" {
+				count = map[string]int{"Critical": 50, "High": 1000}[filter.Severity]
+			} else if filter.Active == nil {
+				count = 4
+			}
+			next := "next"
+			results := make([]types.Finding, min(filter.Limit, count))
+			for i := range results {
+				results[i] = types.Finding{Severity: "High"}
+			}
+			return &types.FindingsResponse{Count: count, Next: &next, Results: results}, nil
+		},
+	}
+	s := newServer(&Config{}, mock)
+	products := []types.Product{{ID: 1, Name: "Monolith"}, {ID: 2, Name: "Locked"}}
+
+	summary := s.summarizePosture(context.Background(), products, time.Now(), defaultPostureTopN)
+	if len(summary.Errors) != 1 || !strings.Contains(summary.Errors[0], "product 2 (Locked): open findings: permission denied") {
+		t.Errorf("expected the failing product to be reported, got %v", summary.Errors)
+	}
+	monolith := summary.TopProducts[0]
+	if monolith.Open != 1050 || monolith.OpenBySeverity != (severityCounts{Critical: 50, High: 1000}) || monolith.SLABreachesComplete {
+		t.Errorf("expected severity counts from count queries, got %+v", monolith)
+	}
+	if monolith.NewThisWeek != 4 || monolith.MitigatedThisWeek != 4 || monolith.OpenChange != 0 {
+		t.Errorf("unexpected weekly deltas: %+v", monolith.postureMetrics)
+	}
+	if len(summary.Notes) != 1 || !strings.Contains(summary.Notes[0], "lower bound for 1 products") {
+		t.Errorf("expected an incomplete SLA note, got %v", summary.Notes)
+	}
+}
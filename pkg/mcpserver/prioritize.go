@@ -0,0 +1,373 @@
+package mcpserver
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/brduru/mcp-defect-dojo/internal/refcache"
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+// Prioritization sizing and scales
+const (
+	prioritizePageSize      = 100 // Findings per query page
+	maxPrioritizedFindings  = 500 // Open findings scored per call
+	defaultPrioritizedShown = 10  // Ranked findings listed by default
+	maxPrioritizedShown     = 100 // Ranked findings one call may list
+	priorityAgeHorizonDays  = 365 // Findings this old get the full age weight
+	prioritySLAHorizonDays  = 30  // SLA pressure starts this many days before expiry
+	priorityMaxScore        = 100 // Scores are scaled to 0-100
+)
+
+// Priority factors, also the suffixes of the tool's *_weight arguments
+const (
+	factorSeverity    = "severity"
+	factorCVSS        = "cvss"
+	factorEPSS        = "epss"
+	factorAge         = "age"
+	factorSLA         = "sla"
+	factorCriticality = "criticality"
+)
+
+// priorityFactors returns the factors in the order they are reported
+func priorityFactors() []string {
+	return []string{factorSeverity, factorCVSS, factorEPSS, factorAge, factorSLA, factorCriticality}
+}
+
+// PriorityWeights sets how much each factor contributes to a finding's
+// remediation priority. Weights are relative: scores are scaled to 0-100 by
+// the sum of the weights that apply.
+type PriorityWeights struct {
+	Severity    float64 // DefectDojo severity, Info = 0 to Critical = 1
+	CVSS        float64 // CVSS v3 base score / 10
+	EPSS        float64 // Highest EPSS score of the finding's CVEs, 1 if in CISA KEV (only with CVE enrichment)
+	Age         float64 // Days open, reaching 1 after a year
+	SLA         float64 // Rises over the last 30 days before the SLA expires, 1 once overdue
+	Criticality float64 // 1 if the product carries a critical tag (only with critical tags)
+}
+
+// DefaultPriorityWeights returns the weights used when none are configured
+func DefaultPriorityWeights() PriorityWeights {
+	return PriorityWeights{Severity: 30, CVSS: 20, EPSS: 20, Age: 10, SLA: 10, Criticality: 10}
+}
+
+// weight returns the weight of one factor
+func (w PriorityWeights) weight(factor string) float64 {
+	switch factor {
+	case factorSeverity:
+		return w.Severity
+	case factorCVSS:
+		return w.CVSS
+	case factorEPSS:
+		return w.EPSS
+	case factorAge:
+		return w.Age
+	case factorSLA:
+		return w.SLA
+	case factorCriticality:
+		return w.Criticality
+	}
+	return 0
+}
+
+// set changes the weight of one factor
+func (w *PriorityWeights) set(factor string, value float64) {
+	switch factor {
+	case factorSeverity:
+		w.Severity = value
+	case factorCVSS:
+		w.CVSS = value
+	case factorEPSS:
+		w.EPSS = value
+	case factorAge:
+		w.Age = value
+	case factorSLA:
+		w.SLA = value
+	case factorCriticality:
+		w.Criticality = value
+	}
+}
+
+// priorityFactor is one factor's contribution to a finding's score
+type priorityFactor struct {
+	name   string
+	value  float64 // Factor value between 0 and 1
+	points float64 // Contribution to the 0-100 score
+	max    float64 // Points the factor contributes at value 1
+	note   string  // Raw input behind the value, e.g. "9.8" or "overdue 3d"
+}
+
+// rankedFinding is a scored finding with its breakdown
+type rankedFinding struct {
+	finding *types.Finding
+	product string
+	score   float64
+	factors []priorityFactor
+}
+
+// priorityScorer computes the scores of one prioritize_findings call
+type priorityScorer struct {
+	weights      PriorityWeights
+	applied      []string            // Factors that count towards scores
+	total        float64             // Sum of the applied weights
+	criticalTags []string            // Product tags that mark a critical product
+	intel        map[string]CVEIntel // Exploitation data by CVE ID (nil = enrichment off)
+}
+
+// newPriorityScorer leaves out EPSS without enrichment and criticality without
+// critical tags, so their weight is spread over the remaining factors
+func newPriorityScorer(weights PriorityWeights, enriched bool, criticalTags []string) (*priorityScorer, error) {
+	scorer := &priorityScorer{weights: weights, criticalTags: criticalTags}
+	for _, factor := range priorityFactors() {
+		if factor == factorEPSS && !enriched || factor == factorCriticality && len(criticalTags) == 0 {
+			continue
+		}
+		if weight := weights.weight(factor); weight > 0 {
+			scorer.applied = append(scorer.applied, factor)
+			scorer.total += weight
+		}
+	}
+	if scorer.total == 0 {
+		return nil, fmt.Errorf("all applicable priority weights are zero")
+	}
+	return scorer, nil
+}
+
+// score computes a finding's priority; product is nil when it is unknown
+func (p *priorityScorer) score(finding *types.Finding, product *types.Product) rankedFinding {
+	ranked := rankedFinding{finding: finding}
+	if product != nil {
+		ranked.product = product.Name
+	}
+	for _, name := range p.applied {
+		factor := priorityFactor{name: name, max: p.weights.weight(name) * priorityMaxScore / p.total}
+		factor.value, factor.note = p.factorValue(name, finding, product)
+		factor.points = factor.value * factor.max
+		ranked.score += factor.points
+		ranked.factors = append(ranked.factors, factor)
+	}
+	return ranked
+}
+
+// factorValue returns a factor's value between 0 and 1 and the input it was computed from
+func (p *priorityScorer) factorValue(name string, finding *types.Finding, product *types.Product) (float64, string) {
+	switch name {
+	case factorSeverity:
+		severities := types.ValidSeverities()
+		return float64(max(slices.Index(severities, finding.Severity), 0)) / float64(len(severities)-1), finding.Severity
+	case factorCVSS:
+		if finding.CVSSv3Score == nil {
+			return 0, "not scored"
+		}
+		return min(*finding.CVSSv3Score/10, 1), fmt.Sprintf("%.1f", *finding.CVSSv3Score)
+	case factorEPSS:
+		value, note := 0.0, "no data"
+		for _, data := range findingIntel(finding, p.intel) {
+			if data.KEV != nil {
+				return 1, data.CVE + " in CISA KEV"
+			}
+			if data.EPSS != nil && *data.EPSS >= value {
+				value, note = *data.EPSS, fmt.Sprintf("%s %.1f%%", data.CVE, *data.EPSS*100)
+			}
+		}
+		return value, note
+	case factorAge:
+		days, ok := ageDays(finding)
+		if !ok {
+			return 0, "unknown"
+		}
+		return min(float64(max(days, 0))/priorityAgeHorizonDays, 1), fmt.Sprintf("%dd", days)
+	case factorSLA:
+		if finding.SLADaysRemaining == nil {
+			return 0, "no SLA"
+		}
+		days := *finding.SLADaysRemaining
+		if days < 0 {
+			return 1, fmt.Sprintf("overdue %dd", -days)
+		}
+		return max(0, 1-float64(days)/prioritySLAHorizonDays), fmt.Sprintf("%dd left", days)
+	case factorCriticality:
+		if product == nil {
+			return 0, "product unknown"
+		}
+		if tag, ok := criticalTag(product, p.criticalTags); ok {
+			return 1, "tagged " + tag
+		}
+		return 0, "not critical"
+	}
+	return 0, ""
+}
+
+// criticalTag returns the first of the product's tags that marks it critical
+func criticalTag(product *types.Product, criticalTags []string) (string, bool) {
+	for _, tag := range product.Tags {
+		if slices.ContainsFunc(criticalTags, func(critical string) bool { return strings.EqualFold(tag, critical) }) {
+			return tag, true
+		}
+	}
+	return "", false
+}
+
+// testProduct resolves the product a test belongs to through the reference cache
+func (s *Server) testProduct(ctx context.Context, testID int) (*types.Product, error) {
+	test, err := refcache.Get(s.refs, refKey(refTest, testID), func() (*types.Test, error) {
+		return s.ddClient.GetTest(ctx, testID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	engagement, err := refcache.Get(s.refs, refKey(refEngagement, test.Engagement), func() (*types.Engagement, error) {
+		return s.ddClient.GetEngagement(ctx, test.Engagement)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return refcache.Get(s.refs, refKey(refProduct, engagement.Product), func() (*types.Product, error) {
+		return s.ddClient.GetProduct(ctx, engagement.Product)
+	})
+}
+
+// openFindings collects up to maxPrioritizedFindings open findings matching
+// filter, and reports how many matched in total
+func (s *Server) openFindings(ctx context.Context, filter types.FindingsFilter, minSeverity string) ([]types.Finding, int, error) {
+	active := true
+	filter.Active = &active
+	filter.Limit = prioritizePageSize
+
+	var findings []types.Finding
+	count := 0
+	for len(findings) < maxPrioritizedFindings {
+		filter.Offset = len(findings)
+		var response *types.FindingsResponse
+		var err error
+		if minSeverity != "" {
+			response, err = s.getFindingsAtOrAbove(ctx, filter, minSeverity)
+		} else {
+			response, err = s.findingsPage(ctx, filter)
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		count = response.Count
+		findings = append(findings, response.Results...)
+		if len(response.Results) == 0 || len(findings) >= count {
+			break
+		}
+	}
+	return findings[:min(len(findings), maxPrioritizedFindings)], count, nil
+}
+
+// prioritizeFindings handles prioritize_findings
+func (s *Server) prioritizeFindings(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	minSeverity, err := s.severityArgument(request, "min_severity")
+	if err != nil {
+		return nil, err
+	}
+	var filter types.FindingsFilter
+	if product := request.GetInt("product", 0); product != 0 {
+		filter.Product = &product
+	}
+	limit := min(request.GetInt("limit", defaultPrioritizedShown), maxPrioritizedShown)
+
+	weights := s.config.Priority.Weights
+	if weights == (PriorityWeights{}) {
+		weights = DefaultPriorityWeights()
+	}
+	for _, factor := range priorityFactors() {
+		if value, ok := request.GetArguments()[factor+"_weight"]; ok && value != nil {
+			weight, _ := toFloat(value)
+			weights.set(factor, weight)
+		}
+	}
+	criticalTags := request.GetStringSlice("critical_tags", s.config.Priority.CriticalTags)
+
+	scorer, err := newPriorityScorer(weights, s.intel != nil, criticalTags)
+	if err != nil {
+		return nil, err
+	}
+
+	findings, count, err := s.openFindings(ctx, filter, minSeverity)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving open findings: %w", err)
+	}
+
+	var warnings []string
+	if slices.Contains(scorer.applied, factorEPSS) {
+		scorer.intel, err = s.lookupCVEIntel(ctx, findings...)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("CVE enrichment incomplete: %v", err))
+		}
+	}
+
+	ranked := make([]rankedFinding, len(findings))
+	failedProducts := 0
+	for i := range findings {
+		product, err := s.testProduct(ctx, findings[i].Test)
+		if err != nil {
+			failedProducts++
+		}
+		ranked[i] = scorer.score(&findings[i], product)
+	}
+	if failedProducts > 0 && slices.Contains(scorer.applied, factorCriticality) {
+		warnings = append(warnings, fmt.Sprintf("product lookup failed for %d findings; they score no criticality", failedProducts))
+	}
+	slices.SortStableFunc(ranked, func(a, b rankedFinding) int {
+		return cmp.Or(
+			cmp.Compare(b.score, a.score),
+			types.CompareSeverity(b.finding.Severity, a.finding.Severity),
+			cmp.Compare(a.finding.ID, b.finding.ID),
+		)
+	})
+
+	return mcp.NewToolResultText(formatRankedFindings(ranked[:min(len(ranked), limit)], len(ranked), count, scorer, warnings, s.links, s.severity)), nil
+}
+
+// formatRankedFindings renders the top of the ranking with each score's breakdown
+func formatRankedFindings(ranked []rankedFinding, scored, count int, scorer *priorityScorer, warnings []string, links webLinks, severities severityScale) string {
+	result := fmt.Sprintf("Top %d of %d open findings by remediation priority", len(ranked), scored)
+	if scored < count {
+		result += fmt.Sprintf(" (only the first %d of %d were scored; narrow with product or min_severity)", scored, count)
+	}
+	result += "\n"
+
+	weights := make([]string, len(scorer.applied))
+	for i, factor := range scorer.applied {
+		weights[i] = fmt.Sprintf("%s %g", factor, scorer.weights.weight(factor))
+	}
+	result += fmt.Sprintf("Weights: %s\n", strings.Join(weights, ", "))
+	var skipped []string
+	for _, factor := range priorityFactors() {
+		if !slices.Contains(scorer.applied, factor) {
+			skipped = append(skipped, factor)
+		}
+	}
+	if len(skipped) > 0 {
+		result += fmt.Sprintf("Not scored: %s (zero weight, CVE enrichment off or no critical tags)\n", strings.Join(skipped, ", "))
+	}
+
+	for i, entry := range ranked {
+		finding := entry.finding
+		result += fmt.Sprintf("\n%d. [%s] %s (ID: %d) — score %.1f\n", i+1, severities.display(finding.Severity), finding.Title, finding.ID, entry.score)
+		if entry.product != "" {
+			result += fmt.Sprintf("   Product: %s\n", entry.product)
+		}
+		if link := links.finding(finding.ID); link != "" {
+			result += fmt.Sprintf("   URL: %s\n", link)
+		}
+		parts := make([]string, len(entry.factors))
+		for j, factor := range entry.factors {
+			parts[j] = fmt.Sprintf("%s %.1f/%.1f (%s)", factor.name, factor.points, factor.max, factor.note)
+		}
+		result += fmt.Sprintf("   %s\n", strings.Join(parts, ", "))
+	}
+
+	for _, warning := range warnings {
+		result += fmt.Sprintf("\n⚠️ %s\n", warning)
+	}
+	return result
+}
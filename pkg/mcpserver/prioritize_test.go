@@ -0,0 +1,126 @@
+package mcpserver
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/brduru/mcp-defect-dojo/internal/defectdojo"
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+// priorityMock serves findings from two products: test 1 belongs to a
+// production product, test 2 to a sandbox one
+func priorityMock(findings ...types.Finding) *MockDefectDojoClient {
+	return &MockDefectDojoClient{
+		GetFindingsFunc: func(ctx context.Context, filter types.FindingsFilter) (*types.FindingsResponse, error) {
+			if filter.Active == nil || !*filter.Active {
+				return nil, errors.New("expected only open findings to be requested")
+			}
+			page := findings[min(filter.Offset, len(findings)):]
+			return &types.FindingsResponse{Count: len(findings), Results: page[:min(len(page), filter.Limit)]}, nil
+		},
+		GetTestFunc: func(ctx context.Context, testID int) (*types.Test, error) {
+			return &types.Test{ID: testID, Engagement: testID * 10}, nil
+		},
+		GetEngagementFunc: func(ctx context.Context, engagementID int) (*types.Engagement, error) {
+			return &types.Engagement{ID: engagementID, Product: engagementID / 10}, nil
+		},
+		GetProductFunc: func(ctx context.Context, productID int) (*types.Product, error) {
+			if productID == 1 {
+				return &types.Product{ID: 1, Name: "Payments API", Tags: []string{"pci", "Production"}}, nil
+			}
+			return &types.Product{ID: productID, Name: "Sandbox", Tags: []string{"sandbox"}}, nil
+		},
+	}
+}
+
+func TestPrioritizeFindings(t *testing.T) {
+	cvss := func(score float64) *float64 { return &score }
+	days := func(n int) *int { return &n }
+	mock := priorityMock(
+		types.Finding{ID: 3, Title: "Verbose errors", Severity: "Low", Test: 2},
+		types.Finding{ID: 2, Title: "RCE in parser", Severity: "Critical", CVSSv3Score: cvss(9.8), AgeDays: days(0), Test: 2},
+		types.Finding{ID: 1, Title: "SQL injection", Severity: "High", CVSSv3Score: cvss(7.5), AgeDays: days(400), SLADaysRemaining: days(-3), Test: 1},
+	)
+	s := newServer(&Config{Priority: PriorityConfig{CriticalTags: []string{"production"}}}, mock)
+
+	result, err := callTool(t, s, "prioritize_findings", map[string]any{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text := resultText(result)
+	for _, want := range []string{
+		"Top 3 of 3 open findings by remediation priority",
+		"Weights: severity 30, cvss 20, age 10, sla 10, criticality 10",
+		"Not scored: epss",
+		"1. [High] SQL injection (ID: 1) — score 84.4\n   Product: Payments API\n",
+		"severity 28.1/37.5 (High), cvss 18.8/25.0 (7.5), age 12.5/12.5 (400d), sla 12.5/12.5 (overdue 3d), criticality 12.5/12.5 (tagged Production)",
+		"2. [Critical] RCE in parser (ID: 2) — score 62.0",
+		"3. [Low] Verbose errors (ID: 3) — score 9.4",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in:\n%s", want, text)
+		}
+	}
+
+	// Per-call weights override the configuration
+	result, err = callTool(t, s, "prioritize_findings", map[string]any{"limit": 1, "age_weight": 0, "sla_weight": 0, "criticality_weight": 0})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text = resultText(result)
+	if !strings.Contains(text, "Top 1 of 3") || !strings.Contains(text, "1. [Critical] RCE in parser (ID: 2) — score 99.2") || strings.Contains(text, "SQL injection") {
+		t.Errorf("expected severity and CVSS to decide, got:\n%s", text)
+	}
+
+	weights := map[string]any{}
+	for _, factor := range priorityFactors() {
+		weights[factor+"_weight"] = 0
+	}
+	if _, err := callTool(t, s, "prioritize_findings", weights); err == nil {
+		t.Error("expected all-zero weights to be rejected")
+	}
+}
+
+func TestPrioritizeFindingsWithEnrichment(t *testing.T) {
+	fixtures, err := defectdojo.NewFixtureClient("")
+	if err != nil {
+		t.Fatalf("NewFixtureClient() error = %v", err)
+	}
+	dataset, err := LoadCVEDataset(writeCVEDataset(t, map[string]string{kevFileName: testKEVCatalog}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := newServer(&Config{Enrichment: EnrichmentConfig{Mode: EnrichmentOffline, Dataset: dataset}}, fixtures)
+
+	// Only the KEV-listed Medium finding is moved up by exploitation data
+	result, err := callTool(t, s, "prioritize_findings", map[string]any{"severity_weight": 1, "cvss_weight": 0, "age_weight": 0, "sla_weight": 0, "epss_weight": 9})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text := resultText(result)
+	if !strings.Contains(text, "1. [Medium] lodash: Prototype Pollution (CVE-2020-8203) (ID: 4) — score 95.0") || !strings.Contains(text, "epss 90.0/90.0 (CVE-2020-8203 in CISA KEV)") {
+		t.Errorf("expected the KEV finding first, got:\n%s", text)
+	}
+	if !strings.Contains(text, "Top 5 of 5 open findings") {
+		t.Errorf("expected closed findings to be left out, got:\n%s", text)
+	}
+}
+
+func TestPriorityFactorValues(t *testing.T) {
+	scorer, err := newPriorityScorer(PriorityWeights{SLA: 1}, false, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for remaining, want := range map[int]float64{30: 0, 45: 0, 15: 0.5, 0: 1, -10: 1} {
+		value, _ := scorer.factorValue(factorSLA, &types.Finding{SLADaysRemaining: &remaining}, nil)
+		if value != want {
+			t.Errorf("SLA factor with %d days remaining = %g, want %g", remaining, value, want)
+		}
+	}
+	if value, note := scorer.factorValue(factorSLA, &types.Finding{}, nil); value != 0 || note != "no SLA" {
+		t.Errorf("SLA factor without SLA = %g (%s)", value, note)
+	}
+}
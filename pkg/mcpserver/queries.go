@@ -0,0 +1,124 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// SavedQuery is a named, operator-vetted findings query. Arguments use the
+// argument names of get_defectdojo_findings, e.g.
+//
+//	{"product": 1, "severity": "Critical", "active": true}
+type SavedQuery struct {
+	Description string         `json:"description"`
+	Arguments   map[string]any `json:"arguments"`
+}
+
+// savedQueryOverrides returns the get_defectdojo_findings arguments a caller
+// may set when running a saved query. Filters are deliberately excluded.
+func savedQueryOverrides() []string {
+	return []string{"limit", "offset", "detail_level", "max_description_chars", "group_repeated", "format", "language", "include_context"}
+}
+
+// LoadSavedQueries reads saved queries from a JSON file mapping query names
+// to SavedQuery objects. Every query's arguments are validated against the
+// get_defectdojo_findings schema so mistakes surface at startup rather than
+// when an agent first runs the query.
+func LoadSavedQueries(path string) (map[string]SavedQuery, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading saved queries: %w", err)
+	}
+	var queries map[string]SavedQuery
+	if err := json.Unmarshal(data, &queries); err != nil {
+		return nil, fmt.Errorf("parsing saved queries %s: %w", path, err)
+	}
+	if err := validateSavedQueries(queries); err != nil {
+		return nil, fmt.Errorf("invalid saved queries in %s: %w", path, err)
+	}
+	return queries, nil
+}
+
+// validateSavedQueries checks query names and arguments, reporting the first
+// problem in name order.
+func validateSavedQueries(queries map[string]SavedQuery) error {
+	schema := findingsTool().InputSchema
+	for _, name := range slices.Sorted(maps.Keys(queries)) {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("saved query names must not be empty")
+		}
+		if err := validateArguments(schema, queries[name].Arguments); err != nil {
+			return fmt.Errorf("query %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// savedQueries returns the configured queries, loading Queries.FilePath when
+// no queries were set directly. Loading happens once, at server construction.
+func savedQueries(cfg QueriesConfig) map[string]SavedQuery {
+	if cfg.Saved != nil || cfg.FilePath == "" {
+		return cfg.Saved
+	}
+	queries, err := LoadSavedQueries(cfg.FilePath)
+	if err != nil {
+		log.Printf("⚠️  Saved queries unavailable: %v", err)
+		return nil
+	}
+	return queries
+}
+
+// listSavedQueries handles list_saved_queries
+func (s *Server) listSavedQueries(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if len(s.queries) == 0 {
+		return mcp.NewToolResultText("No saved queries configured (set SAVED_QUERIES_FILE)"), nil
+	}
+
+	result := fmt.Sprintf("Saved queries (%d):\n", len(s.queries))
+	for _, name := range slices.Sorted(maps.Keys(s.queries)) {
+		query := s.queries[name]
+		result += fmt.Sprintf("\n- %s", name)
+		if query.Description != "" {
+			result += fmt.Sprintf(": %s", query.Description)
+		}
+		arguments, err := json.Marshal(query.Arguments)
+		if err != nil {
+			return nil, fmt.Errorf("error formatting saved query %q: %w", name, err)
+		}
+		result += fmt.Sprintf("\n  Filters: %s\n", arguments)
+	}
+	return mcp.NewToolResultText(result), nil
+}
+
+// runSavedQuery handles run_saved_query: the saved arguments, overlaid with
+// the caller's paging and output overrides, are run as get_defectdojo_findings.
+func (s *Server) runSavedQuery(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	name := request.GetString("name", "")
+	query, ok := s.queries[name]
+	if !ok {
+		if len(s.queries) == 0 {
+			return nil, fmt.Errorf("unknown saved query %q: no saved queries configured", name)
+		}
+		return nil, fmt.Errorf("unknown saved query %q (available: %s)", name, strings.Join(slices.Sorted(maps.Keys(s.queries)), ", "))
+	}
+
+	arguments := maps.Clone(query.Arguments)
+	if arguments == nil {
+		arguments = map[string]any{}
+	}
+	for _, override := range savedQueryOverrides() {
+		if value, ok := request.GetArguments()[override]; ok {
+			arguments[override] = value
+		}
+	}
+	request.Params.Arguments = arguments
+	return s.getFindings(ctx, request)
+}
@@ -0,0 +1,118 @@
+package mcpserver
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/brduru/mcp-defect-dojo/internal/defectdojo"
+)
+
+func TestLoadSavedQueries(t *testing.T) {
+	dir := t.TempDir()
+	write := func(content string) string {
+		path := filepath.Join(dir, "queries.json")
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	queries, err := LoadSavedQueries(write(`{
+		"crown-jewels-crit": {
+			"description": "Open critical findings on the payments product",
+			"arguments": {"product": 1, "severity": "Critical", "active": true}
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	query, ok := queries["crown-jewels-crit"]
+	if !ok || query.Description != "Open critical findings on the payments product" || query.Arguments["severity"] != "Critical" {
+		t.Errorf("unexpected queries: %+v", queries)
+	}
+
+	tests := map[string]string{
+		"unknown argument": `{"q": {"arguments": {"product_name": "Payments"}}}`,
+		"bad enum":         `{"q": {"arguments": {"severity": "urgent"}}}`,
+		"empty name":       `{" ": {"arguments": {}}}`,
+		"not json":         `product=1`,
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := LoadSavedQueries(write(content)); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+
+	if _, err := LoadSavedQueries(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
+
+func TestSavedQueryTools(t *testing.T) {
+	fixtures, err := defectdojo.NewFixtureClient("")
+	if err != nil {
+		t.Fatalf("NewFixtureClient() error = %v", err)
+	}
+	s := newServer(&Config{Queries: QueriesConfig{Saved: map[string]SavedQuery{
+		"payments-high": {
+			Description: "Open High and Critical findings on Payments API",
+			Arguments:   map[string]any{"product": 1, "min_severity": "High", "limit": 1},
+		},
+	}}}, fixtures)
+
+	result, err := callTool(t, s, "list_saved_queries", map[string]any{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text := resultText(result)
+	for _, want := range []string{"Saved queries (1)", "- payments-high: Open High and Critical findings on Payments API", `Filters: {"limit":1,"min_severity":"High","product":1}`} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in list output:\n%s", want, text)
+		}
+	}
+
+	result, err = callTool(t, s, "run_saved_query", map[string]any{"name": "payments-high"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text = resultText(result)
+	if !strings.Contains(text, "SQL Injection") || strings.Contains(text, "Cross-Site Scripting") || !strings.Contains(text, "next_offset: 1") {
+		t.Errorf("expected the first page of the saved query:\n%s", text)
+	}
+
+	result, err = callTool(t, s, "run_saved_query", map[string]any{"name": "payments-high", "offset": 1, "limit": 5})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text = resultText(result)
+	if !strings.Contains(text, "Cross-Site Scripting") || strings.Contains(text, "Hardcoded database password") || !strings.Contains(text, "has_more: false") {
+		t.Errorf("expected overridden paging to keep the saved filters:\n%s", text)
+	}
+
+	if _, err := callTool(t, s, "run_saved_query", map[string]any{"name": "payments-high", "severity": "Low"}); err == nil {
+		t.Error("expected filter arguments to be rejected")
+	}
+	_, err = callTool(t, s, "run_saved_query", map[string]any{"name": "everything"})
+	if err == nil || !strings.Contains(err.Error(), "available: payments-high") {
+		t.Errorf("expected unknown query error listing available queries, got %v", err)
+	}
+}
+
+func TestSavedQueryToolsWithoutQueries(t *testing.T) {
+	s := newServer(&Config{}, &MockDefectDojoClient{})
+
+	result, err := callTool(t, s, "list_saved_queries", map[string]any{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := resultText(result); !strings.Contains(text, "No saved queries configured") {
+		t.Errorf("unexpected result: %s", text)
+	}
+	if _, err := callTool(t, s, "run_saved_query", map[string]any{"name": "anything"}); err == nil {
+		t.Error("expected an error when no queries are configured")
+	}
+}
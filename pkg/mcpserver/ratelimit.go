@@ -0,0 +1,106 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// defaultRateLimitBurst is how many calls a session may make at once when
+// rate limiting is on and no burst is configured
+const defaultRateLimitBurst = 10
+
+// rateLimiter grants each MCP session a token bucket: it holds up to burst
+// calls and refills at perMinute calls a minute, so a session may call in
+// bursts but not sustain more than perMinute. Calls without a session, such
+// as those of in-process clients, share one bucket.
+type rateLimiter struct {
+	perMinute int
+	burst     int
+	now       func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+// tokenBucket is the call allowance of one session
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// rateLimited is the structured content of a call refused by the rate limit
+type rateLimited struct {
+	Error             string `json:"error"` // Always "rate_limited"
+	RetryAfterSeconds int    `json:"retry_after_seconds"`
+	LimitPerMinute    int    `json:"limit_per_minute"`
+	Burst             int    `json:"burst"`
+}
+
+func newRateLimiter(perMinute, burst int) *rateLimiter {
+	return &rateLimiter{perMinute: perMinute, burst: burst, now: time.Now, buckets: map[string]*tokenBucket{}}
+}
+
+// allow takes a call from the session's bucket, or reports how long until
+// the bucket holds one again
+func (l *rateLimiter) allow(session string) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	perSecond := float64(l.perMinute) / 60
+
+	// Full buckets are the same as no bucket: forget them now and then
+	if now.Sub(l.lastSweep) > time.Minute {
+		for id, bucket := range l.buckets {
+			if bucket.tokens+now.Sub(bucket.updated).Seconds()*perSecond >= float64(l.burst) {
+				delete(l.buckets, id)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	bucket, ok := l.buckets[session]
+	if !ok {
+		bucket = &tokenBucket{tokens: float64(l.burst), updated: now}
+		l.buckets[session] = bucket
+	}
+	bucket.tokens = min(float64(l.burst), bucket.tokens+now.Sub(bucket.updated).Seconds()*perSecond)
+	bucket.updated = now
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		return 0, true
+	}
+	return time.Duration((1 - bucket.tokens) / perSecond * float64(time.Second)), false
+}
+
+// rateLimitMiddleware refuses the calls of a session beyond its rate limit.
+// The refusal is always an error result, never a protocol error, so the
+// model reads it: its text asks the agent to slow down and when to retry,
+// and its structured content carries the same as rateLimited.
+func rateLimitMiddleware(limiter *rateLimiter) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			wait, ok := limiter.allow(sessionID(ctx))
+			if ok {
+				return next(ctx, request)
+			}
+			retryAfter := max(1, int(math.Ceil(wait.Seconds())))
+			text := fmt.Sprintf("Rate limit exceeded: this session may make %d tool calls per minute (bursts of %d). Slow down: wait %d seconds before calling %s or any other tool again, and batch work into fewer calls where tools allow it.",
+				limiter.perMinute, limiter.burst, retryAfter, request.Params.Name)
+			result := mcp.NewToolResultStructured(rateLimited{
+				Error:             "rate_limited",
+				RetryAfterSeconds: retryAfter,
+				LimitPerMinute:    limiter.perMinute,
+				Burst:             limiter.burst,
+			}, text)
+			result.IsError = true
+			return result, nil
+		}
+	}
+}
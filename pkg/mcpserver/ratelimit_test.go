@@ -0,0 +1,64 @@
+package mcpserver
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter := newRateLimiter(60, 3)
+	limiter.now = func() time.Time { return now }
+
+	for i := range 3 {
+		if _, ok := limiter.allow("a"); !ok {
+			t.Fatalf("call %d of the burst refused", i+1)
+		}
+	}
+	wait, ok := limiter.allow("a")
+	if ok || wait != time.Second {
+		t.Fatalf("expected a refusal for 1s, got %s, %v", wait, ok)
+	}
+	if _, ok := limiter.allow("b"); !ok {
+		t.Error("expected another session to have its own bucket")
+	}
+
+	now = now.Add(time.Second)
+	if _, ok := limiter.allow("a"); !ok {
+		t.Error("expected a call to be allowed once a token refilled")
+	}
+	if _, ok := limiter.allow("a"); ok {
+		t.Error("expected the refilled token to be used up")
+	}
+
+	now = now.Add(time.Hour)
+	limiter.allow("c")
+	if len(limiter.buckets) != 1 {
+		t.Errorf("expected full buckets to be forgotten, got %d", len(limiter.buckets))
+	}
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	s := newServer(&Config{Server: ServerConfig{RateLimit: 30, RateLimitBurst: 2}}, &MockDefectDojoClient{})
+	for range 2 {
+		if _, err := callTool(t, s, toolHealthCheck, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result, err := callTool(t, s, toolHealthCheck, nil)
+	if err != nil {
+		t.Fatalf("expected an error result, not a protocol error: %v", err)
+	}
+	if !result.IsError {
+		t.Fatalf("expected the call to be refused, got %s", resultText(result))
+	}
+	if text := resultText(result); !strings.Contains(text, "Slow down: wait 2 seconds") {
+		t.Errorf("unexpected refusal text %q", text)
+	}
+	structured, ok := result.StructuredContent.(map[string]any)
+	if !ok || structured["error"] != "rate_limited" || structured["retry_after_seconds"] != float64(2) {
+		t.Errorf("unexpected structured content %#v", result.StructuredContent)
+	}
+}
@@ -0,0 +1,223 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+// Engagement readiness sizing
+const (
+	maxReadinessTests    = 100 // Tests checked for imported scans
+	readinessListedIDs   = 20  // Offending finding IDs listed per check
+	readinessPassed      = "pass"
+	readinessFailed      = "fail"
+	readinessUnavailable = "unknown" // The check could not be run
+)
+
+// Readiness checks, in the order they are reported
+const (
+	checkHasTests              = "has_tests"
+	checkScansImported         = "scans_imported"
+	checkCriticalReviewed      = "critical_findings_reviewed"
+	checkFindingsDispositioned = "findings_dispositioned"
+)
+
+// readinessCheck is one item of the readiness checklist
+type readinessCheck struct {
+	Check      string `json:"check"`
+	Title      string `json:"title"`
+	Status     string `json:"status"` // pass, fail or unknown
+	Detail     string `json:"detail"`
+	TestIDs    []int  `json:"test_ids,omitempty"`    // Tests failing the check
+	FindingIDs []int  `json:"finding_ids,omitempty"` // Findings failing the check, most severe first
+}
+
+// engagementReadiness is the output of check_engagement_readiness
+type engagementReadiness struct {
+	Engagement types.Engagement `json:"engagement"`
+	URL        string           `json:"url,omitempty"`
+	Ready      bool             `json:"ready"` // Every check passed
+	Checks     []readinessCheck `json:"checks"`
+}
+
+// checkTests lists the engagement's tests and checks that it has any
+func (s *Server) checkTests(ctx context.Context, engagementID int) ([]types.Test, readinessCheck) {
+	check := readinessCheck{Check: checkHasTests, Title: "Has tests"}
+	if err := budgetFrom(ctx).spendPage(); err != nil {
+		check.Status, check.Detail = readinessUnavailable, err.Error()
+		return nil, check
+	}
+	page, err := s.ddClient.ListTests(ctx, engagementID, maxReadinessTests, 0)
+	if err != nil {
+		check.Status, check.Detail = readinessUnavailable, fmt.Sprintf("tests unavailable: %v", err)
+		return nil, check
+	}
+	if page.Count == 0 {
+		check.Status, check.Detail = readinessFailed, "the engagement has no tests; import scan results or add the manual tests performed"
+		return nil, check
+	}
+	check.Status, check.Detail = readinessPassed, fmt.Sprintf("%d tests", page.Count)
+	if page.Count > len(page.Results) {
+		check.Detail += fmt.Sprintf("; scans are checked for the first %d", len(page.Results))
+	}
+	return page.Results, check
+}
+
+// checkImports checks that a scan was imported into every test, reading the
+// tests' import histories on the worker pool
+func (s *Server) checkImports(ctx context.Context, tests []types.Test) readinessCheck {
+	check := readinessCheck{Check: checkScansImported, Title: "Scans imported into every test"}
+	imported := make([]bool, len(tests))
+	errs := make([]error, len(tests))
+	s.workers.run(ctx, operationReadinessImports, len(tests), func(i int) {
+		if errs[i] = budgetFrom(ctx).spendPage(); errs[i] != nil {
+			return
+		}
+		page, err := s.ddClient.ListTestImports(ctx, tests[i].ID, 1, 0)
+		errs[i] = err
+		imported[i] = err == nil && page.Count > 0
+	})
+
+	for i, test := range tests {
+		if errs[i] != nil {
+			check.Status, check.Detail = readinessUnavailable, fmt.Sprintf("import history of test %d unavailable: %v", test.ID, errs[i])
+			return check
+		}
+		if !imported[i] {
+			check.TestIDs = append(check.TestIDs, test.ID)
+		}
+	}
+	if len(check.TestIDs) > 0 {
+		check.Status = readinessFailed
+		check.Detail = fmt.Sprintf("%d of %d tests have no imported scan; they may be manual tests, or DefectDojo's import history tracking may be disabled", len(check.TestIDs), len(tests))
+		return check
+	}
+	check.Status, check.Detail = readinessPassed, fmt.Sprintf("every test has an imported scan (%d tests)", len(tests))
+	return check
+}
+
+// checkFindings counts the engagement's active findings matching filter;
+// check passes when there are none, and otherwise lists the first of them
+func (s *Server) checkFindings(ctx context.Context, check readinessCheck, filter types.FindingsFilter, failure, success string) readinessCheck {
+	filter.Ordering, filter.Limit = "numerical_severity,-date", readinessListedIDs
+	response, err := s.findingsPage(ctx, filter)
+	if err != nil {
+		check.Status, check.Detail = readinessUnavailable, fmt.Sprintf("findings unavailable: %v", err)
+		return check
+	}
+	if response.Count == 0 {
+		check.Status, check.Detail = readinessPassed, success
+		return check
+	}
+	check.Status, check.Detail = readinessFailed, fmt.Sprintf(failure, response.Count)
+	for _, finding := range response.Results {
+		check.FindingIDs = append(check.FindingIDs, finding.ID)
+	}
+	return check
+}
+
+// engagementReadiness runs the checklist. Only the engagement itself is
+// required; checks that cannot be run are reported as unknown.
+func (s *Server) engagementReadiness(ctx context.Context, engagementID int) (*engagementReadiness, error) {
+	engagement, err := s.ddClient.GetEngagement(ctx, engagementID)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving engagement %d: %w", engagementID, err)
+	}
+	readiness := &engagementReadiness{Engagement: *engagement, URL: s.links.engagement(engagementID)}
+
+	tests, check := s.checkTests(ctx, engagementID)
+	readiness.Checks = append(readiness.Checks, check)
+	if check.Status == readinessPassed {
+		readiness.Checks = append(readiness.Checks, s.checkImports(ctx, tests))
+	} else {
+		readiness.Checks = append(readiness.Checks, readinessCheck{Check: checkScansImported, Title: "Scans imported into every test", Status: check.Status, Detail: "no tests to check"})
+	}
+
+	// Findings still needing a decision are active and neither verified as
+	// real issues nor risk accepted; closing them as mitigated, false
+	// positive or out of scope makes them inactive
+	active, unverified, accepted := true, false, false
+	undecided := types.FindingsFilter{Engagement: &engagementID, Active: &active, Verified: &unverified, RiskAccepted: &accepted}
+	critical := undecided
+	critical.Severity = types.SeverityCritical
+	readiness.Checks = append(readiness.Checks,
+		s.checkFindings(ctx, readinessCheck{Check: checkCriticalReviewed, Title: "No unreviewed Critical findings"}, critical,
+			"%d active Critical findings are not verified yet", "every active Critical finding is verified"),
+		s.checkFindings(ctx, readinessCheck{Check: checkFindingsDispositioned, Title: "All findings dispositioned"}, undecided,
+			"%d active findings are neither verified, risk accepted, mitigated nor marked false positive", "every finding is verified, risk accepted, mitigated or closed"),
+	)
+
+	readiness.Ready = true
+	for _, check := range readiness.Checks {
+		readiness.Ready = readiness.Ready && check.Status == readinessPassed
+	}
+	return readiness, nil
+}
+
+// checkEngagementReadiness handles check_engagement_readiness
+func (s *Server) checkEngagementReadiness(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	engagementID, err := request.RequireInt("engagement_id")
+	if err != nil {
+		return nil, fmt.Errorf("invalid engagement_id: %w", err)
+	}
+	readiness, err := s.engagementReadiness(ctx, engagementID)
+	if err != nil {
+		return nil, err
+	}
+	if s.outputFormat(request) == formatJSON {
+		output, err := marshalOutput(readiness)
+		if err != nil {
+			return nil, err
+		}
+		return mcp.NewToolResultText(output), nil
+	}
+	return mcp.NewToolResultText(formatReadiness(readiness)), nil
+}
+
+// formatReadiness renders the checklist, one line per check with its outcome
+func formatReadiness(readiness *engagementReadiness) string {
+	engagement := readiness.Engagement
+	failed, unknown := 0, 0
+	for _, check := range readiness.Checks {
+		switch check.Status {
+		case readinessFailed:
+			failed++
+		case readinessUnavailable:
+			unknown++
+		}
+	}
+
+	var result strings.Builder
+	fmt.Fprintf(&result, "Engagement %s (ID: %d)", engagement.Name, engagement.ID)
+	if engagement.Status != "" {
+		fmt.Fprintf(&result, ", %s", engagement.Status)
+	}
+	switch {
+	case readiness.Ready:
+		result.WriteString(": ready to close\n")
+	case failed > 0:
+		fmt.Fprintf(&result, ": not ready to close, %d of %d checks failed\n", failed, len(readiness.Checks))
+	default:
+		fmt.Fprintf(&result, ": readiness unknown, %d checks could not be run\n", unknown)
+	}
+	if readiness.URL != "" {
+		result.WriteString(readiness.URL + "\n")
+	}
+
+	result.WriteString("\n")
+	for _, check := range readiness.Checks {
+		fmt.Fprintf(&result, "[%s] %s: %s\n", strings.ToUpper(check.Status), check.Title, check.Detail)
+		if len(check.TestIDs) > 0 {
+			fmt.Fprintf(&result, "  Test IDs: %s\n", formatIDs(check.TestIDs))
+		}
+		if len(check.FindingIDs) > 0 {
+			fmt.Fprintf(&result, "  Finding IDs: %s\n", formatIDs(check.FindingIDs))
+		}
+	}
+	return result.String()
+}
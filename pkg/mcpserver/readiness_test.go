@@ -0,0 +1,83 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/brduru/mcp-defect-dojo/internal/defectdojo"
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+func TestCheckEngagementReadiness(t *testing.T) {
+	fixtures, err := defectdojo.NewFixtureClient("")
+	if err != nil {
+		t.Fatalf("NewFixtureClient() error = %v", err)
+	}
+	s := newServer(&Config{}, fixtures)
+
+	result, err := callTool(t, s, toolEngagementReadiness, map[string]any{"engagement_id": 10})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text := resultText(result)
+	for _, want := range []string{
+		"Engagement Q3 Pentest (ID: 10), In Progress: not ready to close, 1 of 4 checks failed",
+		"[PASS] Has tests: 1 tests",
+		"[PASS] Scans imported into every test",
+		"[PASS] No unreviewed Critical findings",
+		"[FAIL] All findings dispositioned: 1 active findings",
+		"  Finding IDs: 2\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in:\n%s", want, text)
+		}
+	}
+
+	result, _ = callTool(t, s, toolEngagementReadiness, map[string]any{"engagement_id": 12, "format": "json"})
+	var readiness engagementReadiness
+	if err := json.Unmarshal([]byte(resultText(result)), &readiness); err != nil {
+		t.Fatalf("invalid JSON output: %v", err)
+	}
+	if readiness.Ready || len(readiness.Checks) != 4 || readiness.Checks[0].Status != readinessFailed || readiness.Checks[1].Status != readinessFailed || readiness.Checks[3].Status != readinessPassed {
+		t.Errorf("expected an engagement without tests to fail the test checks only, got %+v", readiness.Checks)
+	}
+
+	if _, err := callTool(t, s, toolEngagementReadiness, map[string]any{"engagement_id": 99}); err == nil {
+		t.Error("expected an error for an unknown engagement")
+	}
+}
+
+func TestCheckEngagementReadinessUnavailable(t *testing.T) {
+	mock := &MockDefectDojoClient{
+		GetEngagementFunc: func(ctx context.Context, engagementID int) (*types.Engagement, error) {
+			return &types.Engagement{ID: engagementID, Name: "Release 2.0"}, nil
+		},
+		ListTestsFunc: func(ctx context.Context, engagementID, limit, offset int) (*types.TestsResponse, error) {
+			return &types.TestsResponse{Count: 2, Results: []types.Test{{ID: 1}, {ID: 2}}}, nil
+		},
+		ListTestImportsFunc: func(ctx context.Context, testID, limit, offset int) (*types.TestImportsResponse, error) {
+			if testID == 2 {
+				return nil, errors.New("forbidden")
+			}
+			return &types.TestImportsResponse{Count: 1, Results: []types.TestImport{{ID: 1, Test: testID}}}, nil
+		},
+		GetFindingsFunc: func(ctx context.Context, filter types.FindingsFilter) (*types.FindingsResponse, error) {
+			return &types.FindingsResponse{Results: []types.Finding{}}, nil
+		},
+	}
+	s := newServer(&Config{}, mock)
+
+	readiness, err := s.engagementReadiness(context.Background(), 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if readiness.Ready || readiness.Checks[1].Status != readinessUnavailable || !strings.Contains(readiness.Checks[1].Detail, "test 2") {
+		t.Errorf("expected the imports check unknown, got %+v", readiness.Checks)
+	}
+	if text := formatReadiness(readiness); !strings.Contains(text, "readiness unknown, 1 checks could not be run") {
+		t.Errorf("unexpected text:\n%s", text)
+	}
+}
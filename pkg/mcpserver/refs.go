@@ -0,0 +1,46 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// defaultReferenceCacheTTL bounds how stale product, engagement, test and user data can get
+const defaultReferenceCacheTTL = 10 * time.Minute
+
+// Reference cache namespaces, also accepted by invalidate_reference_cache
+const (
+	refTest        = "test"
+	refTestType    = "test_type"
+	refEngagement  = "engagement"
+	refProduct     = "product"
+	refUser        = "user"        // Keyed by login name rather than ID
+	refEnvironment = "environment" // The whole list under one key
+)
+
+// referenceKinds returns the reference data namespaces that can be invalidated
+func referenceKinds() []string {
+	return []string{refTest, refTestType, refEngagement, refProduct, refUser, refEnvironment}
+}
+
+// refKey builds the reference cache key for one object
+func refKey(kind string, id int) string {
+	return fmt.Sprintf("%s:%d", kind, id)
+}
+
+// invalidateReferenceCache handles invalidate_reference_cache: it drops one
+// kind of cached reference data, or all of it, so renamed or moved objects
+// are picked up before the TTL expires.
+func (s *Server) invalidateReferenceCache(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	kind := request.GetString("kind", "")
+	removed := s.refs.Invalidate(kind)
+
+	scope := "all reference data"
+	if kind != "" {
+		scope = kind + " entries"
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Invalidated %s: %d cached entries removed (TTL %s)", scope, removed, s.refs.TTL())), nil
+}
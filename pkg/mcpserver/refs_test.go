@@ -0,0 +1,52 @@
+package mcpserver
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+func TestInvalidateReferenceCache(t *testing.T) {
+	productName := "Payments API"
+	mock := &MockDefectDojoClient{
+		GetProductFunc: func(ctx context.Context, productID int) (*types.Product, error) {
+			return &types.Product{ID: productID, Name: productName}, nil
+		},
+	}
+	s := newServer(&Config{}, mock)
+	findings := []types.Finding{{ID: 1, Test: 5}}
+
+	if got := s.resolveContexts(context.Background(), findings, nil, nil)[5].Product; got != "Payments API" {
+		t.Fatalf("expected initial product name, got %q", got)
+	}
+
+	productName = "Payments Platform"
+	if got := s.resolveContexts(context.Background(), findings, nil, nil)[5].Product; got != "Payments API" {
+		t.Fatalf("expected cached product name before invalidation, got %q", got)
+	}
+
+	result, err := callTool(t, s, "invalidate_reference_cache", map[string]any{"kind": "product"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := resultText(result); !strings.Contains(text, "Invalidated product entries: 1 cached entries removed") {
+		t.Errorf("unexpected result: %s", text)
+	}
+	if got := s.resolveContexts(context.Background(), findings, nil, nil)[5].Product; got != "Payments Platform" {
+		t.Errorf("expected refreshed product name, got %q", got)
+	}
+
+	result, err = callTool(t, s, "invalidate_reference_cache", map[string]any{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := resultText(result); !strings.Contains(text, "Invalidated all reference data: 3 cached entries removed") {
+		t.Errorf("unexpected result: %s", text)
+	}
+
+	if _, err := callTool(t, s, "invalidate_reference_cache", map[string]any{"kind": "finding"}); err == nil {
+		t.Error("expected unknown kind to be rejected")
+	}
+}
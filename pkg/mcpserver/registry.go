@@ -0,0 +1,96 @@
+package mcpserver
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// toolSpec declares one tool: the function building its definition (name,
+// description, annotations and argument schema), whether it changes
+// DefectDojo data, and the Server method handling its calls
+type toolSpec struct {
+	definition func() mcp.Tool
+	write      bool
+	handler    func(s *Server, ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error)
+}
+
+// toolRegistry is the single list of DefectDojo tools, in the order they are
+// registered and listed. Registration, ToolDefinitions, the read/write
+// classification behind read-only mode, roles, auditing and approvals, and
+// the `mcp-server tools` reference are all derived from it; adding a tool
+// means adding one entry here.
+func toolRegistry() []toolSpec {
+	return []toolSpec{
+		{definition: healthCheckTool, handler: (*Server).healthCheck},
+		{definition: findingsTool, handler: (*Server).getFindings},
+		{definition: findingDetailTool, handler: (*Server).getFindingDetail},
+		{definition: markFalsePositiveTool, write: true, handler: (*Server).markFalsePositive},
+		{definition: clearFalsePositiveTool, write: true, handler: (*Server).clearFalsePositive},
+		{definition: changeSeverityTool, write: true, handler: (*Server).changeFindingSeverity},
+		{definition: assignFindingTool, write: true, handler: (*Server).assignFinding},
+		{definition: addNoteTool, write: true, handler: (*Server).addNoteToFindings},
+		{definition: invalidateCacheTool, handler: (*Server).invalidateReferenceCache},
+		{definition: listSavedQueriesTool, handler: (*Server).listSavedQueries},
+		{definition: runSavedQueryTool, handler: (*Server).runSavedQuery},
+		{definition: listPendingActionsTool, handler: (*Server).listPendingActions},
+		{definition: recentEventsTool, handler: (*Server).getRecentEvents},
+		{definition: newFindingsTool, handler: (*Server).getNewFindings},
+		{definition: createProductTool, write: true, handler: (*Server).createProduct},
+		{definition: createEngagementTool, write: true, handler: (*Server).createEngagement},
+		{definition: importSARIFTool, write: true, handler: (*Server).importSARIF},
+		{definition: sbomComponentsTool, handler: (*Server).findSBOMComponentFindings},
+		{definition: prioritizeFindingsTool, handler: (*Server).prioritizeFindings},
+		{definition: summarizePostureTool, handler: (*Server).summarizeSecurityPosture},
+		{definition: mttrMetricsTool, handler: (*Server).getMTTRMetrics},
+		{definition: createIssueTool, write: true, handler: (*Server).createIssueFromFinding},
+		{definition: expiringRisksTool, handler: (*Server).getExpiringRiskAcceptances},
+		{definition: engagementOverviewTool, handler: (*Server).getEngagementOverview},
+		{definition: engagementReadinessTool, handler: (*Server).checkEngagementReadiness},
+		{definition: findingsByHostTool, handler: (*Server).getFindingsByHost},
+		{definition: staleFindingsTool, handler: (*Server).getStaleFindings},
+		{definition: importSummaryTool, handler: (*Server).getImportSummary},
+		{definition: systemInfoTool, handler: (*Server).getSystemInfo},
+		{definition: serverStatsTool, handler: (*Server).getServerStats},
+		{definition: pinFindingsTool, handler: (*Server).pinFindings},
+		{definition: pinnedFindingsTool, handler: (*Server).getPinnedFindings},
+		{definition: clearPinsTool, handler: (*Server).clearPins},
+		{definition: saveQueryResultTool, handler: (*Server).saveQueryResult},
+		{definition: exportFindingsTool, handler: (*Server).exportFindings},
+	}
+}
+
+// ToolDefinitions returns the name, description, annotations and full JSON
+// input schema of every DefectDojo tool. It is the single source of truth for
+// tool schemas: the server registers exactly these definitions, and other
+// MCP front-ends can use them to expose or validate the same tools.
+func ToolDefinitions() []mcp.Tool {
+	registry := toolRegistry()
+	tools := make([]mcp.Tool, len(registry))
+	for i, spec := range registry {
+		tools[i] = spec.definition()
+	}
+	return tools
+}
+
+// writeTools lists the tools that modify DefectDojo data.
+// These calls are subject to auditing.
+var writeTools = func() map[string]bool {
+	write := map[string]bool{}
+	for _, spec := range toolRegistry() {
+		if spec.write {
+			write[spec.definition().Name] = true
+		}
+	}
+	return write
+}()
+
+// addDefectDojoTools registers every tool of the registry with its handler
+func (s *Server) addDefectDojoTools() {
+	for _, spec := range toolRegistry() {
+		handler := spec.handler
+		s.addTool(spec.definition(), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return handler(s, ctx, request)
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package mcpserver
+
+import "testing"
+
+func TestToolRegistry(t *testing.T) {
+	seen := map[string]bool{}
+	for _, spec := range toolRegistry() {
+		tool := spec.definition()
+		if seen[tool.Name] {
+			t.Errorf("tool %s is registered twice", tool.Name)
+		}
+		seen[tool.Name] = true
+		if spec.handler == nil {
+			t.Errorf("tool %s has no handler", tool.Name)
+		}
+		// Tools changing only server state, such as pins, are neither read-only nor write tools
+		if readOnly := tool.Annotations.ReadOnlyHint; spec.write && (readOnly == nil || *readOnly) {
+			t.Errorf("write tool %s is annotated read-only", tool.Name)
+		}
+		if writeTools[tool.Name] != spec.write {
+			t.Errorf("tool %s: writeTools disagrees with write=%t", tool.Name, spec.write)
+		}
+	}
+
+	// Read-only mode drops exactly the write tools of the registry
+	s := newServer(&Config{Server: ServerConfig{ReadOnly: true}}, &MockDefectDojoClient{})
+	if got, want := len(s.Tools()), len(toolRegistry())-len(writeTools); got != want {
+		t.Errorf("expected %d tools in read-only mode, got %d", want, got)
+	}
+}
@@ -0,0 +1,66 @@
+package mcpserver
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+// findingGroup is a finding together with the findings of the same page that
+// repeat it: same title, same component. Scanners report one missing header
+// or vulnerable library once per endpoint or file, so a page can hold the
+// same issue dozens of times.
+type findingGroup struct {
+	types.Finding       // The most severe finding of the group, shown for all of it
+	IDs           []int // Every finding of the group, in page order
+}
+
+// groupRepeatedFindings groups findings with identical title and component,
+// in the order each group first appears
+func groupRepeatedFindings(findings []types.Finding) []findingGroup {
+	type key struct{ title, component, version string }
+	var groups []findingGroup
+	index := map[key]int{}
+	for _, finding := range findings {
+		k := key{finding.Title, finding.ComponentName, finding.ComponentVersion}
+		i, ok := index[k]
+		if !ok {
+			index[k] = len(groups)
+			groups = append(groups, findingGroup{Finding: finding, IDs: []int{finding.ID}})
+			continue
+		}
+		group := &groups[i]
+		group.IDs = append(group.IDs, finding.ID)
+		if types.CompareSeverity(finding.Severity, group.Severity) > 0 {
+			group.Finding = finding
+		}
+	}
+	return groups
+}
+
+// groupTitle is the title of a group with its component, which the group
+// shares, e.g. "Vulnerable library — lodash 4.17.15"
+func (g findingGroup) groupTitle() string {
+	component := strings.TrimSpace(g.ComponentName + " " + g.ComponentVersion)
+	if component == "" {
+		return g.Title
+	}
+	return g.Title + " — " + component
+}
+
+// formatIDs lists finding IDs separated by commas
+func formatIDs(ids []int) string {
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = fmt.Sprintf("%d", id)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatFindingGroup renders a repeated finding as one numbered line with
+// its occurrence count and IDs
+func formatFindingGroup(index int, group findingGroup, opts formatOptions) string {
+	return fmt.Sprintf("%d. [%s] %s ("+opts.text.t("%d occurrences")+", IDs: %s)\n",
+		index, opts.severities.display(group.Severity), group.groupTitle(), len(group.IDs), formatIDs(group.IDs))
+}
@@ -0,0 +1,77 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+func repeatedFindings() []types.Finding {
+	return []types.Finding{
+		{ID: 1, Title: "Missing CSP header", Severity: types.SeverityMedium, Active: true},
+		{ID: 2, Title: "Vulnerable library", ComponentName: "lodash", ComponentVersion: "4.17.15", Severity: types.SeverityHigh, Active: true},
+		{ID: 3, Title: "Missing CSP header", Severity: types.SeverityHigh, Active: true},
+		{ID: 4, Title: "Vulnerable library", ComponentName: "lodash", ComponentVersion: "4.17.21", Severity: types.SeverityLow, Active: true},
+		{ID: 5, Title: "Missing CSP header", Severity: types.SeverityLow, Active: true},
+	}
+}
+
+func TestGroupRepeatedFindings(t *testing.T) {
+	groups := groupRepeatedFindings(repeatedFindings())
+	if len(groups) != 3 {
+		t.Fatalf("expected 3 groups, got %d", len(groups))
+	}
+	if first := groups[0]; first.ID != 3 || first.Severity != types.SeverityHigh || formatIDs(first.IDs) != "1, 3, 5" {
+		t.Errorf("expected the CSP group represented by its High finding, got %+v", first)
+	}
+	if groups[1].groupTitle() != "Vulnerable library — lodash 4.17.15" || len(groups[2].IDs) != 1 {
+		t.Errorf("expected component versions kept apart, got %+v", groups[1:])
+	}
+}
+
+func TestGroupRepeatedOutput(t *testing.T) {
+	mock := &MockDefectDojoClient{
+		GetFindingsFunc: func(ctx context.Context, filter types.FindingsFilter) (*types.FindingsResponse, error) {
+			return &types.FindingsResponse{Count: 5, Results: repeatedFindings()}, nil
+		},
+	}
+	s := newServer(&Config{}, mock)
+
+	result, err := callTool(t, s, toolGetFindings, map[string]any{"group_repeated": true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text := resultText(result)
+	if !strings.Contains(text, "1. [High] Missing CSP header (3 occurrences, IDs: 1, 3, 5)") || !strings.Contains(text, "2. [High] Vulnerable library (ID: 2)") {
+		t.Errorf("expected grouped text output, got:\n%s", text)
+	}
+
+	result, _ = callTool(t, s, toolGetFindings, map[string]any{"group_repeated": true, "format": "markdown"})
+	if text := resultText(result); !strings.Contains(text, "| 1, 3, 5 |") || !strings.Contains(text, "Missing CSP header (3 occurrences)") {
+		t.Errorf("expected a grouped Markdown row, got:\n%s", text)
+	}
+
+	result, _ = callTool(t, s, toolGetFindings, map[string]any{"group_repeated": true, "format": "json"})
+	var page struct {
+		Results []struct {
+			ID          int   `json:"id"`
+			Occurrences int   `json:"occurrences"`
+			FindingIDs  []int `json:"finding_ids"`
+		} `json:"results"`
+	}
+	if err := json.Unmarshal([]byte(resultText(result)), &page); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(page.Results) != 3 || page.Results[0].ID != 3 || page.Results[0].Occurrences != 3 || len(page.Results[0].FindingIDs) != 3 || page.Results[1].FindingIDs != nil {
+		t.Errorf("expected grouped JSON results, got %+v", page.Results)
+	}
+
+	// Off by default
+	result, _ = callTool(t, s, toolGetFindings, nil)
+	if strings.Contains(resultText(result), "occurrences") {
+		t.Errorf("expected no grouping by default, got:\n%s", resultText(result))
+	}
+}
@@ -0,0 +1,307 @@
+package mcpserver
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/brduru/mcp-defect-dojo/internal/refcache"
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+// Engagement report resource
+const (
+	engagementReportTemplate = "defectdojo://engagement/{id}/report{?format}"
+	reportPageSize           = 100  // Tests or findings per query page
+	maxReportFindings        = 1000 // Findings one report may include
+	maxReportTests           = 500  // Tests one report may list
+)
+
+// engagementReport is the document served by the engagement report resource
+type engagementReport struct {
+	GeneratedAt time.Time        `json:"generated_at"`
+	Engagement  types.Engagement `json:"engagement"`
+	URL         string           `json:"url,omitempty"` // DefectDojo UI page of the engagement
+	Product     *types.Product   `json:"product,omitempty"`
+	ProductURL  string           `json:"product_url,omitempty"`
+	Tests       []reportTest     `json:"tests"`
+	Summary     reportSummary    `json:"summary"`
+	Findings    []jsonFinding    `json:"findings"` // Most severe first
+	Notes       []string         `json:"notes,omitempty"`
+	Warnings    []string         `json:"warnings,omitempty"` // Parts DefectDojo failed to provide
+}
+
+// reportTest is one test of the engagement with its scanner name
+type reportTest struct {
+	types.Test
+	TestTypeName string `json:"test_type_name,omitempty"`
+	Findings     int    `json:"findings"` // Findings of this test included in the report
+}
+
+// reportSummary counts the engagement's findings
+type reportSummary struct {
+	Findings       int               `json:"findings"` // All findings in the engagement
+	Included       int               `json:"included"` // Findings included in the report
+	Open           int               `json:"open"`     // Active findings among those included
+	OpenBySeverity severityCounts    `json:"open_by_severity"`
+	Histogram      severityHistogram `json:"severity_histogram"` // Open findings by severity
+}
+
+// addDefectDojoResources registers the MCP resources
+func (s *Server) addDefectDojoResources() {
+	s.mcpServer.AddResourceTemplate(
+		mcp.NewResourceTemplate(engagementReportTemplate, "Engagement report",
+			mcp.WithTemplateDescription("An engagement with its product, tests and all of its findings in one document. "+
+				"JSON by default; add ?format=markdown for a readable report."),
+			mcp.WithTemplateMIMEType("application/json"),
+		),
+		s.readEngagementReport,
+	)
+	s.mcpServer.AddResourceTemplate(
+		mcp.NewResourceTemplate(attackSurfaceTemplate, "Product attack surface",
+			mcp.WithTemplateDescription("A product's endpoints, detected technologies and open finding counts per endpoint, most exposed endpoints first. "+
+				"Useful context before planning a pentest. JSON by default; add ?format=markdown for a readable summary."),
+			mcp.WithTemplateMIMEType("application/json"),
+		),
+		s.readAttackSurface,
+	)
+	s.mcpServer.AddResourceTemplate(
+		mcp.NewResourceTemplate(savedResultTemplate, "Saved query result",
+			mcp.WithTemplateDescription("A findings query result saved with save_query_result in this session: the query, severity counts and the IDs and titles of the findings."),
+			mcp.WithTemplateMIMEType("application/json"),
+		),
+		s.readSavedResult,
+	)
+	s.mcpServer.AddResourceTemplate(
+		mcp.NewResourceTemplate(exportChunkTemplate, "Findings export chunk",
+			mcp.WithTemplateDescription("One chunk of a findings export created with export_findings in this session: the query, totals, the chunk's findings and the URI of the next chunk."),
+			mcp.WithTemplateMIMEType("application/json"),
+		),
+		s.readExportChunk,
+	)
+	s.mcpServer.AddResourceTemplate(
+		mcp.NewResourceTemplate(fullResultTemplate, "Full tool result",
+			mcp.WithTemplateDescription("The complete result of a tool call in this session that was too large to return and was summarized; the summary links here."),
+		),
+		s.readFullResult,
+	)
+}
+
+// resourceArgument returns a variable matched from a resource URI template, or "" when absent
+func resourceArgument(request mcp.ReadResourceRequest, name string) string {
+	switch value := request.Params.Arguments[name].(type) {
+	case string:
+		return value
+	case []string:
+		if len(value) > 0 {
+			return value[0]
+		}
+	}
+	return ""
+}
+
+// readEngagementReport serves defectdojo://engagement/{id}/report
+func (s *Server) readEngagementReport(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	engagementID, err := strconv.Atoi(resourceArgument(request, "id"))
+	if err != nil || engagementID <= 0 {
+		return nil, fmt.Errorf("invalid engagement ID in %s", request.Params.URI)
+	}
+	format := cmp.Or(resourceArgument(request, "format"), formatJSON)
+	if format != formatJSON && format != formatMarkdown {
+		return nil, fmt.Errorf("invalid format %q (must be json or markdown)", format)
+	}
+
+	ctx, err = s.productContext(ctx, request.Params.URI, fmt.Sprintf("engagement %d", engagementID), func(ctx context.Context) (*types.Product, error) {
+		engagement, err := s.ddClient.GetEngagement(ctx, engagementID)
+		if err != nil {
+			return nil, err
+		}
+		return s.ddClient.GetProduct(ctx, engagement.Product)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := s.engagementReport(ctx, engagementID, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	if format == formatMarkdown {
+		return []mcp.ResourceContents{mcp.TextResourceContents{
+			URI:      request.Params.URI,
+			MIMEType: "text/markdown",
+			Text:     markdownEngagementReport(report, formatOptions{format: formatMarkdown, links: s.links, html: s.config.Output.HTMLContent, severities: s.severity, text: catalogs[s.config.Output.Language]}),
+		}}, nil
+	}
+	opts := formatOptions{html: s.config.Output.HTMLContent}
+	for i := range report.Findings {
+		report.Findings[i].Finding = opts.convertHTML(report.Findings[i].Finding)
+	}
+	text, err := marshalOutput(report)
+	if err != nil {
+		return nil, err
+	}
+	return []mcp.ResourceContents{mcp.TextResourceContents{
+		URI:      request.Params.URI,
+		MIMEType: "application/json",
+		Text:     text,
+	}}, nil
+}
+
+// engagementReport gathers an engagement, its product, tests and findings.
+// Only the engagement itself is required; the parts that cannot be read are
+// left out and named in the report's warnings.
+func (s *Server) engagementReport(ctx context.Context, engagementID int, now time.Time) (*engagementReport, error) {
+	engagement, err := s.ddClient.GetEngagement(ctx, engagementID)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving engagement %d: %w", engagementID, err)
+	}
+	report := &engagementReport{GeneratedAt: now, Engagement: *engagement, URL: s.links.engagement(engagementID), Tests: []reportTest{}, Findings: []jsonFinding{}}
+	var partial partialResults
+
+	product, err := refcache.Get(s.refs, refKey(refProduct, engagement.Product), func() (*types.Product, error) {
+		return s.ddClient.GetProduct(ctx, engagement.Product)
+	})
+	if err != nil {
+		partial.warnf("product %d unavailable: %v", engagement.Product, err)
+	} else {
+		report.Product, report.ProductURL = product, s.links.product(product.ID)
+	}
+
+	for offset := 0; offset < maxReportTests; {
+		if budgetFrom(ctx).spendPage() != nil {
+			break // The call fails with the budget error
+		}
+		page, err := s.ddClient.ListTests(ctx, engagementID, reportPageSize, offset)
+		if err != nil {
+			partial.warnf("tests unavailable after the first %d: %v", offset, err)
+			break
+		}
+		for _, test := range page.Results {
+			entry := reportTest{Test: test}
+			if test.TestType != 0 {
+				testType, err := refcache.Get(s.refs, refKey(refTestType, test.TestType), func() (*types.TestType, error) {
+					return s.ddClient.GetTestType(ctx, test.TestType)
+				})
+				if err != nil {
+					partial.warnf("scanner of test %d unavailable: %v", test.ID, err)
+				} else {
+					entry.TestTypeName = testType.Name
+				}
+			}
+			report.Tests = append(report.Tests, entry)
+		}
+		offset += len(page.Results)
+		if page.Next == nil || len(page.Results) == 0 {
+			break
+		}
+		if offset >= maxReportTests {
+			report.Notes = append(report.Notes, fmt.Sprintf("Only the first %d of %d tests are listed.", offset, page.Count))
+		}
+	}
+
+	filter := types.FindingsFilter{Engagement: &engagementID, Ordering: "numerical_severity,-date", Limit: reportPageSize}
+	for filter.Offset < maxReportFindings {
+		response, err := s.findingsPage(ctx, filter)
+		if err != nil {
+			partial.warnf("findings unavailable after the first %d: %v; counts cover only those included", filter.Offset, err)
+			break
+		}
+		report.Summary.Findings = response.Count
+		report.Findings = append(report.Findings, jsonFindings(response.Results, s.links, s.severity)...)
+		filter.Offset += len(response.Results)
+		if response.Next == nil || len(response.Results) == 0 {
+			break
+		}
+	}
+
+	report.Summary.Included = len(report.Findings)
+	for _, finding := range report.Findings {
+		if finding.Active {
+			report.Summary.Open++
+			report.Summary.OpenBySeverity.add(finding.Severity, 1)
+		}
+		if i := slices.IndexFunc(report.Tests, func(test reportTest) bool { return test.ID == finding.Test }); i >= 0 {
+			report.Tests[i].Findings++
+		}
+	}
+	report.Summary.Histogram = report.Summary.OpenBySeverity.histogram()
+	if report.Summary.Included < report.Summary.Findings {
+		report.Notes = append(report.Notes, fmt.Sprintf("Only the %d most severe of %d findings are included; open counts cover those findings.",
+			report.Summary.Included, report.Summary.Findings))
+	}
+	report.Warnings = partial.list()
+	return report, nil
+}
+
+// markdownEngagementReport renders the report as one Markdown document
+func markdownEngagementReport(report *engagementReport, opts formatOptions) string {
+	var result strings.Builder
+	engagement := report.Engagement
+	fmt.Fprintf(&result, "# Engagement: %s (ID %d)\n\n", engagement.Name, engagement.ID)
+	if report.URL != "" {
+		fmt.Fprintf(&result, "- **URL:** %s\n", report.URL)
+	}
+	if report.Product != nil {
+		product := fmt.Sprintf("%s (ID %d)", report.Product.Name, report.Product.ID)
+		if report.ProductURL != "" {
+			product = fmt.Sprintf("[%s](%s)", product, report.ProductURL)
+		}
+		fmt.Fprintf(&result, "- **Product:** %s\n", product)
+	}
+	for _, field := range []struct{ name, value string }{
+		{"Status", engagement.Status},
+		{"Type", engagement.EngagementType},
+		{"Target", strings.Trim(engagement.TargetStart+" → "+engagement.TargetEnd, " →")},
+	} {
+		if field.value != "" {
+			fmt.Fprintf(&result, "- **%s:** %s\n", field.name, field.value)
+		}
+	}
+	fmt.Fprintf(&result, "- **Generated:** %s\n", report.GeneratedAt.Format(time.RFC3339))
+	if description := strings.TrimSpace(engagement.Description); description != "" {
+		fmt.Fprintf(&result, "\n%s\n", description)
+	}
+
+	fmt.Fprintf(&result, "\n## Tests (%d)\n\n", len(report.Tests))
+	if len(report.Tests) > 0 {
+		result.WriteString("| ID | Title | Scanner | Findings |\n")
+		result.WriteString("|---:|-------|---------|---------:|\n")
+		for _, test := range report.Tests {
+			fmt.Fprintf(&result, "| %d | %s | %s | %d |\n", test.ID, markdownCell(cmp.Or(test.Title, "-")), markdownCell(cmp.Or(test.TestTypeName, "-")), test.Findings)
+		}
+	}
+
+	summary := report.Summary
+	counts := summary.OpenBySeverity
+	result.WriteString("\n## Summary\n\n")
+	fmt.Fprintf(&result, "- **Findings:** %d (%d open)\n", summary.Findings, summary.Open)
+	fmt.Fprintf(&result, "- **Open by severity:** Critical %d, High %d, Medium %d, Low %d, Info %d `%s`\n",
+		counts.Critical, counts.High, counts.Medium, counts.Low, counts.Info, summary.Histogram.Sparkline)
+	for _, note := range report.Notes {
+		fmt.Fprintf(&result, "\n_%s_\n", note)
+	}
+	result.WriteString(formatWarnings(report.Warnings))
+
+	if len(report.Findings) == 0 {
+		return result.String()
+	}
+	findings := make([]types.Finding, len(report.Findings))
+	for i, finding := range report.Findings {
+		findings[i] = opts.convertHTML(finding.Finding)
+	}
+	result.WriteString("\n## Findings\n\n")
+	result.WriteString(markdownFindingsList(&types.FindingsResponse{Count: summary.Findings, Results: findings}, opts))
+	for _, finding := range findings {
+		result.WriteString("\n")
+		result.WriteString(markdownFindingDetail(&finding, opts))
+	}
+	return result.String()
+}
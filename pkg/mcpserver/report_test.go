@@ -0,0 +1,172 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/brduru/mcp-defect-dojo/internal/defectdojo"
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+// readResource reads a resource through an in-process MCP client and returns its text
+func readResource(t *testing.T, s *Server, uri string) (mcp.TextResourceContents, error) {
+	t.Helper()
+	ctx := context.Background()
+
+	mcpClient, err := client.NewInProcessClient(s.GetMCPServer())
+	if err != nil {
+		t.Fatalf("NewInProcessClient() error = %v", err)
+	}
+	defer mcpClient.Close()
+	if err := mcpClient.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if _, err := mcpClient.Initialize(ctx, mcp.InitializeRequest{}); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+
+	result, err := mcpClient.ReadResource(ctx, mcp.ReadResourceRequest{Params: mcp.ReadResourceParams{URI: uri}})
+	if err != nil {
+		return mcp.TextResourceContents{}, err
+	}
+	if len(result.Contents) != 1 {
+		t.Fatalf("expected one content, got %d", len(result.Contents))
+	}
+	text, ok := result.Contents[0].(mcp.TextResourceContents)
+	if !ok {
+		t.Fatalf("expected text contents, got %T", result.Contents[0])
+	}
+	return text, nil
+}
+
+func TestEngagementReport(t *testing.T) {
+	fixtures, err := defectdojo.NewFixtureClient("")
+	if err != nil {
+		t.Fatalf("NewFixtureClient() error = %v", err)
+	}
+	s := newServer(&Config{}, fixtures)
+
+	contents, err := readResource(t, s, "defectdojo://engagement/10/report")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if contents.MIMEType != "application/json" {
+		t.Errorf("unexpected MIME type %q", contents.MIMEType)
+	}
+	var report engagementReport
+	if err := json.Unmarshal([]byte(contents.Text), &report); err != nil {
+		t.Fatalf("invalid JSON report: %v\n%s", err, contents.Text)
+	}
+	if report.Engagement.Name != "Q3 Pentest" || report.Product == nil || report.Product.Name != "Payments API" {
+		t.Errorf("unexpected engagement %+v of product %+v", report.Engagement, report.Product)
+	}
+	if len(report.Tests) != 1 || report.Tests[0].ID != 100 || report.Tests[0].Findings != 4 {
+		t.Errorf("unexpected tests %+v", report.Tests)
+	}
+	var ids []int
+	for _, finding := range report.Findings {
+		ids = append(ids, finding.ID)
+	}
+	if len(ids) != 4 || report.Summary.Findings != 4 || report.Summary.Included != 4 || report.Findings[0].Severity != types.SeverityCritical {
+		t.Errorf("unexpected findings %v with summary %+v", ids, report.Summary)
+	}
+
+	contents, err = readResource(t, s, "defectdojo://engagement/10/report?format=markdown")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if contents.MIMEType != "text/markdown" {
+		t.Errorf("unexpected MIME type %q", contents.MIMEType)
+	}
+	for _, want := range []string{"# Engagement: Q3 Pentest (ID 10)", "**Product:** Payments API (ID 1)", "## Tests (1)", "## Summary", "## Findings", "## Finding 1:"} {
+		if !strings.Contains(contents.Text, want) {
+			t.Errorf("expected %q in Markdown report:\n%s", want, contents.Text)
+		}
+	}
+}
+
+func TestEngagementReportTruncation(t *testing.T) {
+	mock := &MockDefectDojoClient{
+		GetFindingsFunc: func(ctx context.Context, filter types.FindingsFilter) (*types.FindingsResponse, error) {
+			if filter.Engagement == nil || *filter.Engagement != 7 {
+				t.Errorf("expected an engagement filter, got %+v", filter)
+			}
+			next := "next"
+			results := make([]types.Finding, filter.Limit)
+			for i := range results {
+				results[i] = types.Finding{ID: filter.Offset + i + 1, Severity: types.SeverityLow, Active: true}
+			}
+			return &types.FindingsResponse{Count: 2500, Next: &next, Results: results}, nil
+		},
+	}
+	report, err := newServer(&Config{}, mock).engagementReport(context.Background(), 7, time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Summary.Included != maxReportFindings || report.Summary.Findings != 2500 || report.Summary.OpenBySeverity.Low != maxReportFindings {
+		t.Errorf("unexpected summary %+v", report.Summary)
+	}
+	if len(report.Notes) != 1 || !strings.Contains(report.Notes[0], "1000 most severe of 2500") {
+		t.Errorf("expected a truncation note, got %v", report.Notes)
+	}
+}
+
+func TestEngagementReportErrors(t *testing.T) {
+	mock := &MockDefectDojoClient{
+		GetEngagementFunc: func(ctx context.Context, engagementID int) (*types.Engagement, error) {
+			return nil, errors.New("not found")
+		},
+	}
+	s := newServer(&Config{}, mock)
+	for uri, want := range map[string]string{
+		"defectdojo://engagement/abc/report":           "invalid engagement ID",
+		"defectdojo://engagement/3/report?format=pdf":  "invalid format",
+		"defectdojo://engagement/3/report?format=json": "error retrieving engagement 3",
+	} {
+		if _, err := readResource(t, s, uri); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: expected %q, got %v", uri, want, err)
+		}
+	}
+}
+
+func TestEngagementReportPartial(t *testing.T) {
+	mock := &MockDefectDojoClient{
+		GetFindingsFunc: func(ctx context.Context, filter types.FindingsFilter) (*types.FindingsResponse, error) {
+			if filter.Offset > 0 {
+				return nil, errors.New("500 Internal Server Error")
+			}
+			next := "next"
+			return &types.FindingsResponse{Count: 150, Next: &next, Results: []types.Finding{{ID: 1, Severity: types.SeverityHigh, Active: true}}}, nil
+		},
+		GetProductFunc: func(ctx context.Context, productID int) (*types.Product, error) {
+			return nil, errors.New("forbidden")
+		},
+	}
+	s := newServer(&Config{}, mock)
+
+	report, err := s.engagementReport(context.Background(), 7, time.Now())
+	if err != nil {
+		t.Fatalf("expected a partial report, got %v", err)
+	}
+	if report.Product != nil || report.Summary.Included != 1 || report.Summary.Open != 1 {
+		t.Errorf("unexpected partial report: product %v, summary %+v", report.Product, report.Summary)
+	}
+	if len(report.Warnings) != 2 || !strings.Contains(report.Warnings[0], "product") || !strings.Contains(report.Warnings[1], "findings unavailable after the first 1: 500 Internal Server Error") {
+		t.Errorf("unexpected warnings %v", report.Warnings)
+	}
+
+	contents, err := readResource(t, s, "defectdojo://engagement/7/report?format=markdown")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(contents.Text, "⚠️ Partial results, some DefectDojo requests failed:\n- product") {
+		t.Errorf("expected the warnings in the Markdown report:\n%s", contents.Text)
+	}
+}
@@ -0,0 +1,54 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/brduru/mcp-defect-dojo/internal/logfields"
+	"github.com/brduru/mcp-defect-dojo/internal/requestid"
+)
+
+// RequestIDFromContext returns the correlation ID assigned to the current tool call.
+// It is available to AuditLogger implementations and other code running within a tool call.
+func RequestIDFromContext(ctx context.Context) string {
+	return requestid.FromContext(ctx)
+}
+
+// requestIDMiddleware assigns a request ID to every tool call, logs the call
+// outcome with it, and appends it to tool errors so agents can quote it back.
+// The same ID is sent to DefectDojo as the X-Request-ID header. The call's
+// context also carries the tool, session and instance for logfields.Printf,
+// so every log line of the call can be filtered by them.
+func requestIDMiddleware(debug bool) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			id := requestid.New()
+			ctx = requestid.WithID(ctx, id)
+			ctx = logfields.With(ctx, logfields.Fields{Tool: request.Params.Name, Session: sessionID(ctx), Instance: defaultInstance})
+			trace.SpanFromContext(ctx).SetAttributes(attribute.String("mcp.request_id", id))
+
+			start := time.Now()
+			result, err := next(ctx, request)
+			elapsed := time.Since(start).Round(time.Millisecond)
+
+			if err != nil {
+				logfields.Printf(ctx, "tool call failed in %s: %v", elapsed, err)
+				if strings.Contains(err.Error(), id) {
+					return nil, err // A sanitized DefectDojo error names the request already
+				}
+				return nil, fmt.Errorf("%w (request_id: %s)", err, id)
+			}
+			if debug {
+				logfields.Printf(ctx, "tool call completed in %s", elapsed)
+			}
+			return result, nil
+		}
+	}
+}
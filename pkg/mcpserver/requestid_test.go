@@ -0,0 +1,74 @@
+package mcpserver
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/brduru/mcp-defect-dojo/internal/logfields"
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	var handlerID string
+	var auditID string
+	mock := &MockDefectDojoClient{
+		GetFindingDetailFunc: func(ctx context.Context, findingID int) (*types.Finding, error) {
+			handlerID = RequestIDFromContext(ctx)
+			return nil, fmt.Errorf("boom")
+		},
+	}
+	logger := AuditLoggerFunc(func(ctx context.Context, record AuditRecord) error {
+		auditID = record.RequestID
+		return nil
+	})
+	s := newServer(&Config{Audit: AuditConfig{Logger: logger}}, mock)
+
+	_, err := callTool(t, s, "get_finding_detail", map[string]any{"finding_id": 1})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if handlerID == "" {
+		t.Fatal("expected request ID in handler context")
+	}
+	if !regexp.MustCompile(`request_id: ` + handlerID).MatchString(err.Error()) {
+		t.Errorf("expected error to carry request ID %s, got %v", handlerID, err)
+	}
+
+	if _, err := callTool(t, s, "mark_finding_false_positive", map[string]any{"finding_id": 1, "justification": "x"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if auditID == "" || auditID == handlerID {
+		t.Errorf("expected a fresh request ID in audit record, got %q", auditID)
+	}
+}
+
+func TestRequestLogFields(t *testing.T) {
+	var fields logfields.Fields
+	mock := &MockDefectDojoClient{
+		GetFindingDetailFunc: func(ctx context.Context, findingID int) (*types.Finding, error) {
+			fields = logfields.FromContext(ctx)
+			return nil, fmt.Errorf("boom")
+		},
+	}
+	s := newServer(&Config{}, mock)
+
+	var out bytes.Buffer
+	defer log.SetOutput(log.Writer())
+	log.SetOutput(&out)
+	if _, err := callTool(t, s, "get_finding_detail", map[string]any{"finding_id": 1}); err == nil {
+		t.Fatal("expected error")
+	}
+
+	if fields.Tool != "get_finding_detail" || fields.Instance != defaultInstance || fields.RequestID == "" {
+		t.Errorf("unexpected log fields in the handler: %+v", fields)
+	}
+	want := fmt.Sprintf("[tool=get_finding_detail instance=%s request_id=%s]", defaultInstance, fields.RequestID)
+	if !strings.Contains(out.String(), want) {
+		t.Errorf("expected the failure log to end with %s, got %q", want, out.String())
+	}
+}
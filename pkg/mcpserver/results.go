@@ -0,0 +1,224 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+// Saved query result resource
+const (
+	savedResultTemplate     = "defectdojo://session/result/{name}"
+	savedResultPageSize     = 100
+	maxSavedResultFindings  = 1000 // Findings one saved result may hold
+	maxSavedResultsPerState = 20   // Saved results one session may keep
+)
+
+// savedResultName restricts result names to what fits a resource URI unescaped
+var savedResultName = regexp.MustCompile(`^[A-Za-z0-9_.-]{1,64}$`)
+
+// savedResultTools accept a saved_result argument in place of finding_ids
+var savedResultTools = map[string]bool{
+	toolAddNote:     true,
+	toolPinFindings: true,
+}
+
+// savedResult is a findings query result saved in a session, served as a
+// resource and usable in place of finding IDs
+type savedResult struct {
+	Name       string           `json:"name"`
+	URI        string           `json:"uri"`
+	SavedAt    time.Time        `json:"saved_at"`
+	Query      map[string]any   `json:"query"` // Filter arguments of the query
+	Total      int              `json:"total"` // Findings DefectDojo matched, which may exceed those saved
+	BySeverity severityCounts   `json:"by_severity"`
+	FindingIDs []int            `json:"finding_ids"`
+	Findings   []findingSummary `json:"findings"`
+}
+
+// savedResultURI is the resource URI of a saved result
+func savedResultURI(name string) string {
+	return strings.Replace(savedResultTemplate, "{name}", name, 1)
+}
+
+// savedResultFilters are the get_defectdojo_findings arguments a saved result
+// may be filtered by
+func savedResultFilters() []string {
+	return []string{"active", "severity", "min_severity", "test", "product", "sort_by", "risk_accepted", "is_mitigated", "duplicate", "tags", "not_tags", "reporter", "assigned_to", "environment", "found_by"}
+}
+
+// saveResult stores a result in the call's session, replacing one of the same name
+func (s *sessionStore) saveResult(ctx context.Context, result *savedResult) error {
+	return s.update(ctx, func(state *sessionState) error {
+		if state.results == nil {
+			state.results = map[string]*savedResult{}
+		}
+		if _, replaced := state.results[result.Name]; !replaced && len(state.results) >= maxSavedResultsPerState {
+			return fmt.Errorf("this session already has %d saved results; reuse one of their names: %s", len(state.results), strings.Join(slices.Sorted(maps.Keys(state.results)), ", "))
+		}
+		state.results[result.Name] = result
+		return nil
+	})
+}
+
+// result returns a saved result of the call's session
+func (s *sessionStore) result(ctx context.Context, name string) (*savedResult, error) {
+	var result *savedResult
+	var names []string
+	s.update(ctx, func(state *sessionState) error {
+		result = state.results[name]
+		names = slices.Sorted(maps.Keys(state.results))
+		return nil
+	})
+	if result == nil {
+		if len(names) == 0 {
+			return nil, fmt.Errorf("no saved result %q: this session has none, save one with save_query_result", name)
+		}
+		return nil, fmt.Errorf("no saved result %q in this session (saved: %s)", name, strings.Join(names, ", "))
+	}
+	return result, nil
+}
+
+// saveQueryResult handles save_query_result. The findings are walked page by
+// page up to max_findings and only their IDs and a summary are kept.
+func (s *Server) saveQueryResult(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	name, err := request.RequireString("name")
+	if err != nil {
+		return nil, fmt.Errorf("invalid name: %w", err)
+	}
+	if !savedResultName.MatchString(name) {
+		return nil, fmt.Errorf("invalid name %q: use up to 64 letters, digits, '.', '_' or '-'", name)
+	}
+	maxFindings := request.GetInt("max_findings", maxSavedResultFindings)
+	if maxFindings < 1 || maxFindings > maxSavedResultFindings {
+		return nil, fmt.Errorf("invalid max_findings %d: must be between 1 and %d", maxFindings, maxSavedResultFindings)
+	}
+
+	query, err := s.parseFindingsQuery(request)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.assigneeFilter(ctx, request, &query.filter); err != nil {
+		return nil, err
+	}
+	if err := s.environmentFilter(ctx, request, &query.filter); err != nil {
+		return nil, err
+	}
+
+	result := &savedResult{Name: name, URI: savedResultURI(name), SavedAt: time.Now().UTC(), Query: map[string]any{}, FindingIDs: []int{}, Findings: []findingSummary{}}
+	for _, argument := range savedResultFilters() {
+		if value, ok := request.GetArguments()[argument]; ok {
+			result.Query[argument] = value
+		}
+	}
+	result.Total, err = s.walkFindingsQuery(ctx, query, maxFindings, savedResultPageSize, func(findings []types.Finding) error {
+		for _, finding := range findings {
+			result.FindingIDs = append(result.FindingIDs, finding.ID)
+			result.Findings = append(result.Findings, s.summarizeFinding(finding))
+			result.BySeverity.add(finding.Severity, 1)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.sessions.saveResult(ctx, result); err != nil {
+		return nil, err
+	}
+
+	var output strings.Builder
+	fmt.Fprintf(&output, "Saved %d findings as %s\n", len(result.FindingIDs), result.URI)
+	if counts := result.BySeverity.String(); counts != "" {
+		fmt.Fprintf(&output, "By severity: %s\n", counts)
+	}
+	if result.Total > len(result.FindingIDs) {
+		fmt.Fprintf(&output, "⚠️ The query matched %d findings; only the first %d were saved. Narrow the filters to save the rest.\n", result.Total, len(result.FindingIDs))
+	}
+	fmt.Fprintf(&output, "\nRead the resource for the finding IDs, or pass saved_result: %q to add_note_to_findings or pin_findings instead of finding_ids.\n", name)
+	return mcp.NewToolResultText(output.String()), nil
+}
+
+// walkFindingsQuery runs a findings query page by page from its first
+// finding, passing each page to visit until maxFindings were visited. It
+// returns how many findings DefectDojo matched.
+func (s *Server) walkFindingsQuery(ctx context.Context, query findingsQuery, maxFindings, pageSize int, visit func(findings []types.Finding) error) (int, error) {
+	var total, visited int
+	query.filter.Offset = 0
+	for visited < maxFindings {
+		query.filter.Limit = min(pageSize, maxFindings-visited)
+		page, err := s.runFindingsQuery(ctx, query)
+		if err != nil {
+			return 0, fmt.Errorf("error retrieving findings: %w", err)
+		}
+		total = page.Count
+		if err := visit(page.Results); err != nil {
+			return 0, err
+		}
+		visited += len(page.Results)
+		query.filter.Offset += len(page.Results)
+		if len(page.Results) == 0 || query.filter.Offset >= page.Count {
+			break
+		}
+	}
+	return total, nil
+}
+
+// readSavedResult serves defectdojo://session/result/{name} from the reading session
+func (s *Server) readSavedResult(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	result, err := s.sessions.result(ctx, resourceArgument(request, "name"))
+	if err != nil {
+		return nil, err
+	}
+	text, err := marshalOutput(result)
+	if err != nil {
+		return nil, err
+	}
+	return []mcp.ResourceContents{mcp.TextResourceContents{
+		URI:      request.Params.URI,
+		MIMEType: "application/json",
+		Text:     text,
+	}}, nil
+}
+
+// savedResultMiddleware replaces the saved_result argument of the tools that
+// accept one with the result's finding_ids. It runs before credentials,
+// policy, approvals and auditing, so they all see the findings affected.
+func savedResultMiddleware(sessions *sessionStore) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			name, ok := request.GetArguments()["saved_result"].(string)
+			if !ok || !savedResultTools[request.Params.Name] {
+				return next(ctx, request)
+			}
+			if _, both := request.GetArguments()["finding_ids"]; both {
+				return nil, fmt.Errorf("pass either finding_ids or saved_result, not both")
+			}
+			result, err := sessions.result(ctx, name)
+			if err != nil {
+				return nil, err
+			}
+			if len(result.FindingIDs) == 0 {
+				return nil, fmt.Errorf("saved result %q has no findings", name)
+			}
+			args := maps.Clone(request.GetArguments())
+			delete(args, "saved_result")
+			args["finding_ids"] = slices.Clone(result.FindingIDs)
+			request.Params.Arguments = args
+			return next(ctx, request)
+		}
+	}
+}
+
+// withSavedResultArgument adds the saved_result argument of the tools in savedResultTools
+func withSavedResultArgument() mcp.ToolOption {
+	return mcp.WithString("saved_result", mcp.MinLength(1), mcp.Description("Name of a result saved with save_query_result in this session, instead of finding_ids"))
+}
@@ -0,0 +1,114 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+// pagedFindings serves total High findings with IDs 1..total, page by page
+func pagedFindings(total int) func(ctx context.Context, filter types.FindingsFilter) (*types.FindingsResponse, error) {
+	return func(ctx context.Context, filter types.FindingsFilter) (*types.FindingsResponse, error) {
+		response := &types.FindingsResponse{Count: total}
+		for id := filter.Offset + 1; id <= min(total, filter.Offset+filter.Limit); id++ {
+			response.Results = append(response.Results, types.Finding{ID: id, Title: "Finding", Severity: types.SeverityHigh, Active: true})
+		}
+		return response, nil
+	}
+}
+
+func TestSaveQueryResult(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		noted  []int
+		limits []int
+	)
+	client := &MockDefectDojoClient{
+		GetFindingsFunc: func(ctx context.Context, filter types.FindingsFilter) (*types.FindingsResponse, error) {
+			mu.Lock()
+			limits = append(limits, filter.Limit)
+			mu.Unlock()
+			return pagedFindings(250)(ctx, filter)
+		},
+		AddFindingNoteFunc: func(ctx context.Context, findingID int, entry string) (*types.Note, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			noted = append(noted, findingID)
+			return &types.Note{ID: 1000 + findingID, Entry: entry}, nil
+		},
+	}
+	base := serveStreamableHTTP(t, newServer(&Config{}, client))
+	first := connectAs(t, base, "")
+	second := connectAs(t, base, "")
+	ctx := context.Background()
+
+	text, err := callSessionTool(t, first, toolSaveQueryResult, map[string]any{"name": "highs", "severity": "high", "max_findings": 150})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{"Saved 150 findings as defectdojo://session/result/highs", "By severity: 150 high", "matched 250 findings; only the first 150"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in:\n%s", want, text)
+		}
+	}
+	if !slices.Equal(limits, []int{100, 50}) {
+		t.Errorf("expected two pages of 100 and 50, got %v", limits)
+	}
+
+	// The result is a resource of the saving session only
+	contents, err := first.ReadResource(ctx, mcp.ReadResourceRequest{Params: mcp.ReadResourceParams{URI: savedResultURI("highs")}})
+	if err != nil {
+		t.Fatalf("ReadResource() error = %v", err)
+	}
+	var saved savedResult
+	if err := json.Unmarshal([]byte(contents.Contents[0].(mcp.TextResourceContents).Text), &saved); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(saved.FindingIDs) != 150 || saved.Total != 250 || saved.Query["severity"] != "high" {
+		t.Errorf("unexpected saved result: %d IDs, total %d, query %v", len(saved.FindingIDs), saved.Total, saved.Query)
+	}
+	if _, err := second.ReadResource(ctx, mcp.ReadResourceRequest{Params: mcp.ReadResourceParams{URI: savedResultURI("highs")}}); err == nil {
+		t.Error("expected another session not to see the result")
+	}
+
+	// Batch tools take the saved result instead of finding IDs
+	if _, err := callSessionTool(t, first, toolAddNote, map[string]any{"saved_result": "highs", "note": "tracked"}); err == nil || !strings.Contains(err.Error(), "too many findings (150)") {
+		t.Errorf("expected the note limit to apply to the saved findings, got %v", err)
+	}
+	if _, err := callSessionTool(t, first, toolSaveQueryResult, map[string]any{"name": "few", "max_findings": 3}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := callSessionTool(t, first, toolAddNote, map[string]any{"saved_result": "few", "note": "tracked in INC-1234"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	slices.Sort(noted)
+	if !slices.Equal(noted, []int{1, 2, 3}) {
+		t.Errorf("expected notes on findings 1-3, got %v", noted)
+	}
+	text, err = callSessionTool(t, first, toolPinFindings, map[string]any{"saved_result": "few"})
+	if err != nil || !strings.Contains(text, "Pinned 3 findings") {
+		t.Errorf("expected the saved findings to be pinned, got %v:\n%s", err, text)
+	}
+
+	for _, tt := range []struct {
+		args    map[string]any
+		wantErr string
+	}{
+		{map[string]any{"saved_result": "few", "finding_ids": []int{1}, "note": "x"}, "either finding_ids or saved_result"},
+		{map[string]any{"saved_result": "missing", "note": "x"}, `no saved result "missing" in this session (saved: few, highs)`},
+	} {
+		if _, err := callSessionTool(t, first, toolAddNote, tt.args); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+		}
+	}
+	if _, err := callSessionTool(t, first, toolSaveQueryResult, map[string]any{"name": "a/b"}); err == nil || !strings.Contains(err.Error(), "invalid name") {
+		t.Errorf("expected the name to be refused, got %v", err)
+	}
+}
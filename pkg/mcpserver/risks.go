@@ -0,0 +1,220 @@
+package mcpserver
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+// Risk acceptance review sizing
+const (
+	defaultRiskExpiryDays     = 30
+	maxRiskExpiryDays         = 365
+	riskAcceptancePageSize    = 100
+	maxRiskAcceptances        = 5000 // Acceptances read per call
+	maxRiskAcceptanceFindings = 200  // Accepted findings looked up per call
+)
+
+// expiringRisk is a risk acceptance due for review, with its findings
+type expiringRisk struct {
+	types.RiskAcceptance
+	ExpiresInDays int            `json:"expires_in_days"`                // Negative once expired
+	Findings      []jsonFinding  `json:"findings"`                       // Accepted findings that could be read
+	Unavailable   map[int]string `json:"unavailable_findings,omitempty"` // Why accepted findings could not be read, by ID
+}
+
+// listExpiringRiskAcceptances reads every risk acceptance and returns those
+// expiring before horizon, soonest first. Acceptances that already expired
+// are included when DefectDojo has not yet handled their expiry and
+// includeExpired is set. DefectDojo cannot filter by expiration date, so the
+// acceptances are filtered here.
+func (s *Server) listExpiringRiskAcceptances(ctx context.Context, now, horizon time.Time, includeExpired bool) ([]types.RiskAcceptance, error) {
+	var expiring []types.RiskAcceptance
+	for offset := 0; offset < maxRiskAcceptances; {
+		if err := budgetFrom(ctx).spendPage(); err != nil {
+			return nil, err
+		}
+		page, err := s.ddClient.ListRiskAcceptances(ctx, riskAcceptancePageSize, offset)
+		if err != nil {
+			return nil, err
+		}
+		for _, risk := range page.Results {
+			expires := risk.ExpirationDate
+			if expires.IsZero() || expires.After(horizon) {
+				continue
+			}
+			if !expires.After(now) && (!includeExpired || !risk.ExpirationDateHandled.IsZero()) {
+				continue
+			}
+			expiring = append(expiring, risk)
+		}
+		offset += len(page.Results)
+		if page.Next == nil || len(page.Results) == 0 {
+			break
+		}
+	}
+	slices.SortFunc(expiring, func(a, b types.RiskAcceptance) int {
+		return cmp.Or(a.ExpirationDate.Compare(b.ExpirationDate), cmp.Compare(a.ID, b.ID))
+	})
+	return expiring, nil
+}
+
+// lookupAcceptedFindings reads the findings of the acceptances concurrently,
+// at most maxRiskAcceptanceFindings of them. Findings that cannot be read are
+// returned with the error.
+func (s *Server) lookupAcceptedFindings(ctx context.Context, risks []types.RiskAcceptance) (map[int]types.Finding, map[int]error) {
+	var ids []int
+	for _, risk := range risks {
+		ids = append(ids, risk.AcceptedFindings...)
+	}
+	slices.Sort(ids)
+	ids = slices.Compact(ids)
+	ids = ids[:min(len(ids), maxRiskAcceptanceFindings)]
+
+	findings := map[int]types.Finding{}
+	failures := map[int]error{}
+	var mu sync.Mutex
+	s.workers.run(ctx, operationRiskFindings, len(ids), func(i int) {
+		finding, err := s.ddClient.GetFindingDetail(ctx, ids[i])
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			failures[ids[i]] = err
+			return
+		}
+		findings[ids[i]] = s.sanitizedFinding(*finding)
+	})
+	return findings, failures
+}
+
+// daysUntil counts the days from now until t: whole days started until then,
+// or whole days elapsed since, as a negative number, once t passed
+func daysUntil(now, t time.Time) int {
+	days := t.Sub(now).Hours() / 24
+	if days < 0 {
+		return int(math.Trunc(days))
+	}
+	return int(math.Ceil(days))
+}
+
+// getExpiringRiskAcceptances handles get_expiring_risk_acceptances
+func (s *Server) getExpiringRiskAcceptances(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	days := request.GetInt("days", defaultRiskExpiryDays)
+	if days < 1 || days > maxRiskExpiryDays {
+		return nil, fmt.Errorf("invalid days %d: must be between 1 and %d", days, maxRiskExpiryDays)
+	}
+	includeExpired := request.GetBool("include_expired", false)
+
+	now := time.Now().UTC()
+	risks, err := s.listExpiringRiskAcceptances(ctx, now, now.AddDate(0, 0, days), includeExpired)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving risk acceptances: %w", err)
+	}
+	found, failures := s.lookupAcceptedFindings(ctx, risks)
+
+	expiring := make([]expiringRisk, len(risks))
+	for i, risk := range risks {
+		expiring[i] = expiringRisk{RiskAcceptance: risk, ExpiresInDays: daysUntil(now, risk.ExpirationDate), Findings: []jsonFinding{}}
+		for _, id := range risk.AcceptedFindings {
+			if finding, ok := found[id]; ok {
+				expiring[i].Findings = append(expiring[i].Findings, newJSONFinding(finding, s.links, s.severity))
+			} else if err := failures[id]; err != nil {
+				if expiring[i].Unavailable == nil {
+					expiring[i].Unavailable = map[int]string{}
+				}
+				expiring[i].Unavailable[id] = err.Error()
+			}
+		}
+	}
+
+	if s.outputFormat(request) == formatJSON {
+		output, err := marshalOutput(struct {
+			Days            int            `json:"days"`
+			IncludeExpired  bool           `json:"include_expired"`
+			RiskAcceptances []expiringRisk `json:"risk_acceptances"`
+		}{days, includeExpired, expiring})
+		if err != nil {
+			return nil, err
+		}
+		return mcp.NewToolResultText(output), nil
+	}
+	return mcp.NewToolResultText(s.formatExpiringRisks(expiring, days, includeExpired)), nil
+}
+
+// formatExpiringRisks renders the acceptances due for review, soonest first
+func (s *Server) formatExpiringRisks(expiring []expiringRisk, days int, includeExpired bool) string {
+	scope := fmt.Sprintf("expire within %d days", days)
+	if includeExpired {
+		scope = fmt.Sprintf("have expired without being handled or expire within %d days", days)
+	}
+	if len(expiring) == 0 {
+		return fmt.Sprintf("No risk acceptances %s\n", scope)
+	}
+
+	var result strings.Builder
+	fmt.Fprintf(&result, "%d risk acceptances %s\n", len(expiring), scope)
+	for _, risk := range expiring {
+		when := fmt.Sprintf("expires %s (in %d days)", risk.ExpirationDate.Format(time.DateOnly), risk.ExpiresInDays)
+		if risk.ExpiresInDays < 0 {
+			when = fmt.Sprintf("expired %s (%d days ago)", risk.ExpirationDate.Format(time.DateOnly), -risk.ExpiresInDays)
+		} else if risk.ExpiresInDays == 0 {
+			when = fmt.Sprintf("expired %s (today)", risk.ExpirationDate.Format(time.DateOnly))
+		}
+		fmt.Fprintf(&result, "\nRisk acceptance %d: %s — %s\n", risk.ID, risk.Name, when)
+
+		var who []string
+		if risk.Decision != "" {
+			who = append(who, "Decision: "+risk.DecisionName())
+		}
+		if risk.AcceptedBy != "" {
+			who = append(who, "accepted by "+risk.AcceptedBy)
+		}
+		if risk.Owner != 0 {
+			who = append(who, fmt.Sprintf("owner: user %d", risk.Owner))
+		}
+		if len(who) > 0 {
+			fmt.Fprintf(&result, "  %s\n", strings.Join(who, "; "))
+		}
+		var onExpiry []string
+		if risk.ReactivateExpired {
+			onExpiry = append(onExpiry, "findings are reactivated")
+		}
+		if risk.RestartSLAExpired {
+			onExpiry = append(onExpiry, "their SLA restarts")
+		}
+		if len(onExpiry) > 0 {
+			fmt.Fprintf(&result, "  On expiry: %s\n", strings.Join(onExpiry, ", "))
+		}
+		if !risk.ExpirationDateWarned.IsZero() {
+			fmt.Fprintf(&result, "  Owner warned: %s\n", risk.ExpirationDateWarned.Format(time.DateOnly))
+		}
+
+		fmt.Fprintf(&result, "  Findings (%d):\n", len(risk.AcceptedFindings))
+		for _, id := range risk.AcceptedFindings {
+			i := slices.IndexFunc(risk.Findings, func(f jsonFinding) bool { return f.ID == id })
+			switch {
+			case i >= 0:
+				finding := risk.Findings[i]
+				fmt.Fprintf(&result, "    [%s] %s (ID: %d)", s.severity.display(finding.Severity), finding.Title, finding.ID)
+				if finding.URL != "" {
+					fmt.Fprintf(&result, " — %s", finding.URL)
+				}
+				result.WriteString("\n")
+			case risk.Unavailable[id] != "":
+				fmt.Fprintf(&result, "    ID %d: could not be read: %s\n", id, risk.Unavailable[id])
+			default:
+				fmt.Fprintf(&result, "    ID %d (not looked up: more than %d findings)\n", id, maxRiskAcceptanceFindings)
+			}
+		}
+	}
+	return result.String()
+}
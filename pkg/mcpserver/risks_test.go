@@ -0,0 +1,84 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+func TestGetExpiringRiskAcceptances(t *testing.T) {
+	now := time.Now().UTC()
+	next := "page-2"
+	mock := &MockDefectDojoClient{
+		ListRiskAcceptancesFunc: func(ctx context.Context, limit, offset int) (*types.RiskAcceptancesResponse, error) {
+			if offset == 0 {
+				return &types.RiskAcceptancesResponse{Count: 5, Next: &next, Results: []types.RiskAcceptance{
+					{ID: 1, Name: "Later", AcceptedFindings: []int{10}, ExpirationDate: now.AddDate(0, 0, 20)},
+					{ID: 2, Name: "Too far", AcceptedFindings: []int{11}, ExpirationDate: now.AddDate(0, 0, 90)},
+					{ID: 3, Name: "Never expires", AcceptedFindings: []int{12}},
+				}}, nil
+			}
+			return &types.RiskAcceptancesResponse{Count: 5, Results: []types.RiskAcceptance{
+				{ID: 4, Name: "Soon", AcceptedFindings: []int{10, 999}, Decision: "A", AcceptedBy: "Dana", Owner: 3, ReactivateExpired: true, ExpirationDate: now.AddDate(0, 0, 3)},
+				{ID: 5, Name: "Lapsed", AcceptedFindings: []int{13}, ExpirationDate: now.AddDate(0, 0, -2)},
+			}}, nil
+		},
+		GetFindingDetailFunc: func(ctx context.Context, findingID int) (*types.Finding, error) {
+			if findingID == 999 {
+				return nil, errors.New("finding not found")
+			}
+			return &types.Finding{ID: findingID, Title: "Weak TLS", Severity: "High"}, nil
+		},
+	}
+	s := newServer(&Config{}, mock)
+
+	result, err := callTool(t, s, "get_expiring_risk_acceptances", map[string]any{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text := resultText(result)
+	for _, want := range []string{
+		"2 risk acceptances expire within 30 days",
+		"Risk acceptance 4: Soon", "(in 3 days)", "Decision: Accept; accepted by Dana; owner: user 3", "On expiry: findings are reactivated",
+		"[High] Weak TLS (ID: 10)", "ID 999: could not be read: finding not found",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in:\n%s", want, text)
+		}
+	}
+	if strings.Index(text, "Risk acceptance 4") > strings.Index(text, "Risk acceptance 1") {
+		t.Errorf("expected the soonest expiry first:\n%s", text)
+	}
+	for _, unwanted := range []string{"Too far", "Never expires", "Lapsed"} {
+		if strings.Contains(text, unwanted) {
+			t.Errorf("did not expect %q in:\n%s", unwanted, text)
+		}
+	}
+
+	result, err = callTool(t, s, "get_expiring_risk_acceptances", map[string]any{"days": 7, "include_expired": true, "format": "json"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var output struct {
+		RiskAcceptances []struct {
+			ID            int   `json:"id"`
+			ExpiresInDays int   `json:"expires_in_days"`
+			Findings      []any `json:"findings"`
+		} `json:"risk_acceptances"`
+	}
+	if err := json.Unmarshal([]byte(resultText(result)), &output); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(output.RiskAcceptances) != 2 || output.RiskAcceptances[0].ID != 5 || output.RiskAcceptances[0].ExpiresInDays != -2 || output.RiskAcceptances[1].ID != 4 || len(output.RiskAcceptances[1].Findings) != 1 {
+		t.Errorf("unexpected JSON output: %+v", output)
+	}
+
+	if _, err := callTool(t, s, "get_expiring_risk_acceptances", map[string]any{"days": 0}); err == nil {
+		t.Error("expected days 0 to be rejected")
+	}
+}
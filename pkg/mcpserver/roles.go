@@ -0,0 +1,161 @@
+package mcpserver
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Built-in roles of HTTP transport clients
+const (
+	RoleViewer  = "viewer"  // Every read-only tool
+	RoleTriager = "triager" // Read-only tools and finding triage: false positives, severity, assignment, notes, issues
+	RoleAdmin   = "admin"   // Every tool, including onboarding and scan imports
+)
+
+// ruleRole is the PolicyError rule of tool calls the caller's role does not allow
+const ruleRole = "role"
+
+// triageTools are the write tools of RoleTriager
+var triageTools = []string{toolMarkFalsePositive, toolClearFalsePositive, toolChangeSeverity, toolAssignFinding, toolAddNote, toolCreateIssue}
+
+// accessControl maps the bearer tokens of HTTP clients to their identity
+// and the tools their role allows
+type accessControl struct {
+	tokens []AuthToken
+	roles  map[string]map[string]bool // Allowed tools by role
+}
+
+// callerRoleKey carries the role of an authenticated caller
+type callerRoleKey struct{}
+
+// newAccessControl checks the auth configuration against the known tools and
+// returns nil when no tokens are configured
+func newAccessControl(cfg AuthConfig) (*accessControl, error) {
+	if len(cfg.Tokens) == 0 {
+		return nil, nil
+	}
+
+	known := map[string]bool{}
+	for _, tool := range ToolDefinitions() {
+		known[tool.Name] = true
+	}
+	access := &accessControl{tokens: cfg.Tokens, roles: map[string]map[string]bool{
+		RoleViewer:  {},
+		RoleTriager: {},
+		RoleAdmin:   {},
+	}}
+	for tool := range known {
+		access.roles[RoleAdmin][tool] = true
+		if !writeTools[tool] || slices.Contains(triageTools, tool) {
+			access.roles[RoleTriager][tool] = true
+		}
+		if !writeTools[tool] {
+			access.roles[RoleViewer][tool] = true
+		}
+	}
+	for role, tools := range cfg.Roles {
+		allowed := map[string]bool{}
+		for _, tool := range tools {
+			if !known[tool] {
+				return nil, fmt.Errorf("role %q allows unknown tool %q", role, tool)
+			}
+			allowed[tool] = true
+		}
+		access.roles[role] = allowed
+	}
+
+	names := map[string]bool{}
+	secrets := map[string]bool{}
+	for i, token := range cfg.Tokens {
+		switch {
+		case token.Name == "":
+			return nil, fmt.Errorf("token %d has no name", i+1)
+		case token.Token == "":
+			return nil, fmt.Errorf("token %q has no token value", token.Name)
+		case names[token.Name]:
+			return nil, fmt.Errorf("token %q is defined twice", token.Name)
+		case secrets[token.Token]:
+			return nil, fmt.Errorf("token %q reuses the token value of another client", token.Name)
+		case access.roles[token.Role] == nil:
+			return nil, fmt.Errorf("token %q has unknown role %q", token.Name, token.Role)
+		}
+		names[token.Name] = true
+		secrets[token.Token] = true
+	}
+	return access, nil
+}
+
+// authenticate returns the client sending the request's bearer token
+func (a *accessControl) authenticate(r *http.Request) (AuthToken, bool) {
+	bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || bearer == "" {
+		return AuthToken{}, false
+	}
+	for _, token := range a.tokens {
+		if subtle.ConstantTimeCompare([]byte(bearer), []byte(token.Token)) == 1 {
+			return token, true
+		}
+	}
+	return AuthToken{}, false
+}
+
+// requireToken rejects HTTP requests without a known bearer token
+func (a *accessControl) requireToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := a.authenticate(r); !ok {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="mcp-defect-dojo"`)
+			http.Error(w, "missing or unknown bearer token", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// callerContext attaches the identity and role of the request's client to
+// the context tool calls run in
+func (a *accessControl) callerContext(ctx context.Context, r *http.Request) context.Context {
+	token, ok := a.authenticate(r)
+	if !ok {
+		return ctx
+	}
+	return context.WithValue(WithCallerIdentity(ctx, token.Name), callerRoleKey{}, token.Role)
+}
+
+// allows reports whether the caller's role may call tool. Callers without a
+// role (stdio, in-process calls and approved actions) may call every tool.
+func (a *accessControl) allows(ctx context.Context, tool string) bool {
+	role, ok := ctx.Value(callerRoleKey{}).(string)
+	return !ok || a.roles[role][tool]
+}
+
+// filterTools lists only the tools the caller's role allows
+func (a *accessControl) filterTools(ctx context.Context, tools []mcp.Tool) []mcp.Tool {
+	allowed := make([]mcp.Tool, 0, len(tools))
+	for _, tool := range tools {
+		if a.allows(ctx, tool.Name) {
+			allowed = append(allowed, tool)
+		}
+	}
+	return allowed
+}
+
+// roleMiddleware refuses tool calls the caller's role does not allow, for
+// clients calling tools they were not listed
+func roleMiddleware(access *accessControl) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if !access.allows(ctx, request.Params.Name) {
+				role, _ := ctx.Value(callerRoleKey{}).(string)
+				return nil, &PolicyError{Rule: ruleRole, Tool: request.Params.Name, Reason: fmt.Sprintf("role %q may not call %s", role, request.Params.Name)}
+			}
+			return next(ctx, request)
+		}
+	}
+}
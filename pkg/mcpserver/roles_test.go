@@ -0,0 +1,185 @@
+package mcpserver
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// agentAuth configures one client per built-in role and a custom reporter role
+var agentAuth = AuthConfig{
+	Tokens: []AuthToken{
+		{Name: "dashboard", Token: "viewer-token", Role: RoleViewer},
+		{Name: "triage-agent", Token: "triager-token", Role: RoleTriager},
+		{Name: "platform", Token: "admin-token", Role: RoleAdmin},
+		{Name: "weekly-report", Token: "reporter-token", Role: "reporter"},
+	},
+	Roles: map[string][]string{"reporter": {toolSummarizePosture, toolHealthCheck}},
+}
+
+// serveStreamableHTTP serves s over streamable HTTP and returns its base URL
+func serveStreamableHTTP(t *testing.T, s *Server) string {
+	t.Helper()
+	handler, err := s.transportHandler(TransportHTTP)
+	if err != nil {
+		t.Fatalf("transportHandler() error = %v", err)
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- serveHTTP(ctx, listener, handler) }()
+	t.Cleanup(func() {
+		cancel()
+		<-served
+	})
+	return "http://" + listener.Addr().String()
+}
+
+// connectAs starts an MCP session sending token
+func connectAs(t *testing.T, base, token string) *client.Client {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	mcpClient, err := client.NewStreamableHttpClient(base+"/mcp", transport.WithHTTPHeaders(map[string]string{"Authorization": "Bearer " + token}))
+	if err != nil {
+		t.Fatalf("NewStreamableHttpClient() error = %v", err)
+	}
+	t.Cleanup(func() { mcpClient.Close() })
+	if err := mcpClient.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if _, err := mcpClient.Initialize(ctx, mcp.InitializeRequest{}); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+	return mcpClient
+}
+
+func TestRoleToolExposure(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		callers []string
+	)
+	audit := AuditLoggerFunc(func(ctx context.Context, record AuditRecord) error {
+		mu.Lock()
+		defer mu.Unlock()
+		callers = append(callers, record.Caller)
+		return nil
+	})
+	s := newServer(&Config{Auth: agentAuth, Audit: AuditConfig{Logger: audit}}, &MockDefectDojoClient{})
+	base := serveStreamableHTTP(t, s)
+	ctx := context.Background()
+
+	for _, tt := range []struct {
+		token   string
+		include []string
+		exclude []string
+	}{
+		{"viewer-token", []string{toolGetFindings, toolServerStats}, []string{toolMarkFalsePositive, toolCreateProduct}},
+		{"triager-token", []string{toolGetFindings, toolMarkFalsePositive, toolAddNote}, []string{toolCreateProduct, toolImportSARIF}},
+		{"admin-token", []string{toolMarkFalsePositive, toolCreateProduct, toolImportSARIF}, nil},
+		{"reporter-token", []string{toolSummarizePosture, toolHealthCheck}, []string{toolGetFindings}},
+	} {
+		t.Run(tt.token, func(t *testing.T) {
+			tools, err := connectAs(t, base, tt.token).ListTools(ctx, mcp.ListToolsRequest{})
+			if err != nil {
+				t.Fatalf("ListTools() error = %v", err)
+			}
+			var names []string
+			for _, tool := range tools.Tools {
+				names = append(names, tool.Name)
+			}
+			for _, name := range tt.include {
+				if !slices.Contains(names, name) {
+					t.Errorf("expected %s to be listed, got %v", name, names)
+				}
+			}
+			for _, name := range tt.exclude {
+				if slices.Contains(names, name) {
+					t.Errorf("expected %s to be hidden, got %v", name, names)
+				}
+			}
+		})
+	}
+
+	// Hidden tools are refused at dispatch too
+	viewer := connectAs(t, base, "viewer-token")
+	_, err := viewer.CallTool(ctx, mcp.CallToolRequest{Params: mcp.CallToolParams{Name: toolMarkFalsePositive, Arguments: map[string]any{"finding_id": 1, "justification": "test data"}}})
+	if err == nil || !strings.Contains(err.Error(), `role "viewer" may not call mark_finding_false_positive`) {
+		t.Errorf("expected the viewer to be refused, got %v", err)
+	}
+
+	// Writes are audited as the token's client
+	triager := connectAs(t, base, "triager-token")
+	if _, err := triager.CallTool(ctx, mcp.CallToolRequest{Params: mcp.CallToolParams{Name: toolMarkFalsePositive, Arguments: map[string]any{"finding_id": 1, "justification": "test data"}}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	mu.Lock()
+	if !slices.Equal(callers, []string{"triage-agent"}) {
+		t.Errorf("expected one write audited as triage-agent, got %q", callers)
+	}
+	mu.Unlock()
+
+	// Stdio and in-process callers are trusted
+	if _, err := callTool(t, s, toolCreateProduct, map[string]any{"name": "New", "product_type": "1"}); err != nil && strings.Contains(err.Error(), "may not call") {
+		t.Errorf("expected in-process calls to bypass roles, got %v", err)
+	}
+}
+
+func TestRoleAuthentication(t *testing.T) {
+	base := serveStreamableHTTP(t, newServer(&Config{Auth: agentAuth}, &MockDefectDojoClient{}))
+
+	for _, header := range []string{"", "Bearer wrong-token", "Basic dmlld2VyLXRva2Vu"} {
+		request, _ := http.NewRequest(http.MethodPost, base+"/mcp", strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`))
+		if header != "" {
+			request.Header.Set("Authorization", header)
+		}
+		response, err := http.DefaultClient.Do(request)
+		if err != nil {
+			t.Fatalf("request error = %v", err)
+		}
+		response.Body.Close()
+		if response.StatusCode != http.StatusUnauthorized || response.Header.Get("WWW-Authenticate") == "" {
+			t.Errorf("%q: expected 401 with a challenge, got %d", header, response.StatusCode)
+		}
+	}
+}
+
+func TestAccessControlConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     AuthConfig
+		wantErr string
+	}{
+		{"unknown role", AuthConfig{Tokens: []AuthToken{{Name: "a", Token: "t", Role: "auditor"}}}, `token "a" has unknown role "auditor"`},
+		{"unknown tool", AuthConfig{Tokens: []AuthToken{{Name: "a", Token: "t", Role: "x"}}, Roles: map[string][]string{"x": {"delete_everything"}}}, `role "x" allows unknown tool "delete_everything"`},
+		{"missing token", AuthConfig{Tokens: []AuthToken{{Name: "a", Role: RoleViewer}}}, `token "a" has no token value`},
+		{"shared token", AuthConfig{Tokens: []AuthToken{{Name: "a", Token: "t", Role: RoleViewer}, {Name: "b", Token: "t", Role: RoleAdmin}}}, `token "b" reuses the token value`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := newAccessControl(tt.cfg); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	s := newServer(&Config{Auth: tests[0].cfg}, &MockDefectDojoClient{})
+	if _, err := s.transportHandler(TransportHTTP); err == nil || !strings.Contains(err.Error(), "invalid auth configuration") {
+		t.Errorf("expected the HTTP transport to refuse to start, got %v", err)
+	}
+	if access, err := newAccessControl(AuthConfig{}); access != nil || err != nil {
+		t.Errorf("expected no access control without tokens, got %v %v", access, err)
+	}
+}
@@ -0,0 +1,186 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+// sarifScanType is DefectDojo's parser name for SARIF reports
+const sarifScanType = "SARIF"
+
+// sarifVersion is the only SARIF version DefectDojo's parser accepts
+const sarifVersion = "2.1.0"
+
+// sarifRun is the part of a SARIF run needed to validate and describe it
+type sarifRun struct {
+	Tool struct {
+		Driver struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+		} `json:"driver"`
+	} `json:"tool"`
+	Results []struct {
+		Level string `json:"level"`
+	} `json:"results"`
+}
+
+// sarifReport is one run of a SARIF log, re-encoded as a standalone document
+type sarifReport struct {
+	run      sarifRun
+	document []byte
+}
+
+// splitSARIF validates a SARIF 2.1.0 log and splits it into one document per
+// run, so each scanner in a multi-run log becomes its own DefectDojo test.
+// Top-level properties other than runs are kept in every document.
+func splitSARIF(content string) ([]sarifReport, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(content), &fields); err != nil {
+		return nil, fmt.Errorf("not valid JSON: %w", err)
+	}
+	var version string
+	if err := json.Unmarshal(fields["version"], &version); err != nil || version != sarifVersion {
+		return nil, fmt.Errorf("unsupported SARIF version %s (must be %q)", fields["version"], sarifVersion)
+	}
+	var runs []json.RawMessage
+	if err := json.Unmarshal(fields["runs"], &runs); err != nil || len(runs) == 0 {
+		return nil, fmt.Errorf("a SARIF log needs a non-empty runs array")
+	}
+
+	reports := make([]sarifReport, len(runs))
+	for i, raw := range runs {
+		if err := json.Unmarshal(raw, &reports[i].run); err != nil {
+			return nil, fmt.Errorf("run %d: %w", i+1, err)
+		}
+		if reports[i].run.Tool.Driver.Name == "" {
+			return nil, fmt.Errorf("run %d: tool.driver.name is required", i+1)
+		}
+		fields["runs"] = json.RawMessage("[" + string(raw) + "]")
+		document, err := json.Marshal(fields)
+		if err != nil {
+			return nil, fmt.Errorf("run %d: %w", i+1, err)
+		}
+		reports[i].document = document
+	}
+	return reports, nil
+}
+
+// levelCounts summarizes a run's results by SARIF level, e.g. "3 error, 1 warning"
+func (r *sarifRun) levelCounts() string {
+	counts := map[string]int{}
+	for _, result := range r.Results {
+		level := result.Level
+		if level == "" {
+			level = "warning" // SARIF's default level
+		}
+		counts[level]++
+	}
+	var parts []string
+	for _, level := range []string{"error", "warning", "note", "none"} {
+		if counts[level] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[level], level))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// importSARIF handles import_sarif
+func (s *Server) importSARIF(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	content, err := request.RequireString("sarif")
+	if err != nil {
+		return nil, fmt.Errorf("invalid sarif: %w", err)
+	}
+	reports, err := splitSARIF(content)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SARIF: %w", err)
+	}
+
+	base := types.ImportScanRequest{
+		ScanType:          sarifScanType,
+		Engagement:        request.GetInt("engagement_id", 0),
+		ProductName:       request.GetString("product_name", ""),
+		EngagementName:    request.GetString("engagement_name", ""),
+		AutoCreateContext: request.GetBool("auto_create_context", false),
+		Active:            optionalBool(request, "active"),
+		Verified:          optionalBool(request, "verified"),
+	}
+	if base.Engagement == 0 && (base.ProductName == "" || base.EngagementName == "") {
+		return nil, fmt.Errorf("either engagement_id or both product_name and engagement_name are required")
+	}
+	if value := request.GetString("minimum_severity", ""); value != "" {
+		severity, ok := s.severity.normalize(value)
+		if !ok {
+			return nil, fmt.Errorf("invalid minimum_severity %q (must be one of %s)", value, strings.Join(s.severity.accepted(), ", "))
+		}
+		base.MinimumSeverity = severity
+	}
+	title := request.GetString("test_title", "")
+
+	var result strings.Builder
+	var failed []string
+	imported := 0
+	for i, report := range reports {
+		driver := report.run.Tool.Driver
+		name := strings.TrimSpace(driver.Name + " " + driver.Version)
+
+		importRequest := base
+		importRequest.TestTitle = title
+		if importRequest.TestTitle == "" {
+			importRequest.TestTitle = driver.Name
+		} else if len(reports) > 1 {
+			importRequest.TestTitle += " (" + driver.Name + ")"
+		}
+		importRequest.FileName = fmt.Sprintf("run-%d.sarif", i+1)
+		importRequest.File = report.document
+
+		fmt.Fprintf(&result, "\nRun %d: %s (%d results", i+1, name, len(report.run.Results))
+		if counts := report.run.levelCounts(); counts != "" {
+			fmt.Fprintf(&result, ": %s", counts)
+		}
+		result.WriteString(")\n")
+
+		response, err := s.ddClient.ImportScan(ctx, importRequest)
+		if err != nil {
+			fmt.Fprintf(&result, "  ❌ Import failed: %v\n", err)
+			failed = append(failed, fmt.Sprintf("run %d (%s)", i+1, driver.Name))
+			continue
+		}
+		imported++
+		result.WriteString(formatImportResponse(response))
+	}
+
+	header := fmt.Sprintf("Imported %d of %d SARIF runs into DefectDojo as %s tests\n", imported, len(reports), sarifScanType)
+	if len(failed) > 0 {
+		message := "SARIF import failed for " + strings.Join(failed, ", ")
+		if imported > 0 {
+			// Say what did go through so the caller does not import it twice
+			message += "; the other runs were imported, do not import them again"
+		}
+		return nil, fmt.Errorf("%s\n\n%s%s", message, header, result.String())
+	}
+	return mcp.NewToolResultText(header + result.String()), nil
+}
+
+// formatImportResponse describes the test created by one import and its statistics
+func formatImportResponse(response *types.ImportScanResponse) string {
+	result := fmt.Sprintf("  Test %d in engagement %d (product %d)\n", response.Test, response.Engagement, response.Product)
+	stats := response.Statistics
+	if stats.After != nil {
+		after := stats.After
+		result += fmt.Sprintf("  Findings: %d (Critical %d, High %d, Medium %d, Low %d, Info %d), %d active, %d duplicates\n",
+			after.Total.Total, after.Critical.Total, after.High.Total, after.Medium.Total, after.Low.Total, after.Info.Total,
+			after.Total.Active, after.Total.Duplicate)
+	}
+	if stats.Delta != nil {
+		delta := stats.Delta
+		result += fmt.Sprintf("  Changes: %d created, %d closed, %d reactivated, %d untouched\n",
+			delta.Created.Total.Total, delta.Closed.Total.Total, delta.Reactivated.Total.Total, delta.Untouched.Total.Total)
+	}
+	return result
+}
@@ -0,0 +1,140 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+// sarifRunJSON builds a SARIF run with one result per level
+func sarifRunJSON(tool string, levels ...string) string {
+	var results []string
+	for _, level := range levels {
+		results = append(results, fmt.Sprintf(`{"ruleId": "R1", "level": %q, "message": {"text": "issue"}}`, level))
+	}
+	return fmt.Sprintf(`{"tool": {"driver": {"name": %q, "version": "1.0"}}, "results": [%s]}`, tool, strings.Join(results, ", "))
+}
+
+// sarifLog wraps runs in a SARIF 2.1.0 log
+func sarifLog(runs ...string) string {
+	return fmt.Sprintf(`{"$schema": "https://json.schemastore.org/sarif-2.1.0.json", "version": "2.1.0", "runs": [%s]}`, strings.Join(runs, ", "))
+}
+
+func TestSplitSARIF(t *testing.T) {
+	reports, err := splitSARIF(sarifLog(sarifRunJSON("Semgrep", "error", "warning"), sarifRunJSON("Trivy")))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(reports) != 2 || reports[0].run.Tool.Driver.Name != "Semgrep" || reports[1].run.Tool.Driver.Name != "Trivy" {
+		t.Fatalf("expected one report per run, got %+v", reports)
+	}
+
+	var document struct {
+		Schema  string            `json:"$schema"`
+		Version string            `json:"version"`
+		Runs    []json.RawMessage `json:"runs"`
+	}
+	if err := json.Unmarshal(reports[1].document, &document); err != nil {
+		t.Fatalf("invalid run document: %v", err)
+	}
+	if document.Version != "2.1.0" || document.Schema == "" || len(document.Runs) != 1 {
+		t.Errorf("expected a standalone SARIF log for the run, got %s", reports[1].document)
+	}
+	if counts := reports[0].run.levelCounts(); counts != "1 error, 1 warning" {
+		t.Errorf("levelCounts() = %q", counts)
+	}
+
+	for name, content := range map[string]string{
+		"not JSON":      `<xml/>`,
+		"wrong version": `{"version": "2.0.0", "runs": [` + sarifRunJSON("Semgrep") + `]}`,
+		"no runs":       `{"version": "2.1.0", "runs": []}`,
+		"no tool name":  sarifLog(`{"tool": {"driver": {}}, "results": []}`),
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := splitSARIF(content); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestImportSARIF(t *testing.T) {
+	var requests []types.ImportScanRequest
+	mock := &MockDefectDojoClient{
+		ImportScanFunc: func(ctx context.Context, request types.ImportScanRequest) (*types.ImportScanResponse, error) {
+			requests = append(requests, request)
+			if strings.Contains(string(request.File), "Broken") {
+				return nil, errors.New("parser error")
+			}
+			after := &types.SeverityStatistics{High: types.StatusCounts{Total: 1}, Medium: types.StatusCounts{Total: 1}, Total: types.StatusCounts{Total: 2, Active: 2}}
+			return &types.ImportScanResponse{Test: 300 + len(requests), Engagement: 10, Product: 1, Statistics: types.ImportStatistics{After: after}}, nil
+		},
+	}
+	s := newServer(&Config{}, mock)
+
+	result, err := callTool(t, s, "import_sarif", map[string]any{
+		"sarif":            sarifLog(sarifRunJSON("Semgrep", "error", "warning"), sarifRunJSON("Trivy", "note")),
+		"engagement_id":    10,
+		"minimum_severity": "low",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text := resultText(result)
+	for _, want := range []string{
+		"Imported 2 of 2 SARIF runs",
+		"Run 1: Semgrep 1.0 (2 results: 1 error, 1 warning)",
+		"Test 301 in engagement 10 (product 1)",
+		"Findings: 2 (Critical 0, High 1, Medium 1, Low 0, Info 0), 2 active",
+		"Run 2: Trivy 1.0 (1 results: 1 note)",
+		"Test 302",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in:\n%s", want, text)
+		}
+	}
+	if len(requests) != 2 || requests[0].ScanType != "SARIF" || requests[0].TestTitle != "Semgrep" || requests[1].TestTitle != "Trivy" ||
+		requests[0].Engagement != 10 || requests[0].MinimumSeverity != "Low" {
+		t.Errorf("unexpected import requests: %+v", requests)
+	}
+	if strings.Contains(string(requests[0].File), "Trivy") {
+		t.Error("expected each run to be imported on its own")
+	}
+
+	t.Run("partial failure", func(t *testing.T) {
+		_, err := callTool(t, s, "import_sarif", map[string]any{
+			"sarif":           sarifLog(sarifRunJSON("Semgrep"), sarifRunJSON("Broken")),
+			"product_name":    "Payments API",
+			"engagement_name": "CI",
+			"test_title":      "Nightly",
+		})
+		if err == nil || !strings.Contains(err.Error(), "SARIF import failed for run 2 (Broken)") || !strings.Contains(err.Error(), "do not import them again") {
+			t.Errorf("expected the failed run to be reported, got %v", err)
+		}
+		if last := requests[len(requests)-1]; last.ProductName != "Payments API" || last.TestTitle != "Nightly (Broken)" {
+			t.Errorf("unexpected import request: %+v", last)
+		}
+	})
+
+	t.Run("missing target", func(t *testing.T) {
+		_, err := callTool(t, s, "import_sarif", map[string]any{"sarif": sarifLog(sarifRunJSON("Semgrep"))})
+		if err == nil || !strings.Contains(err.Error(), "engagement_id") {
+			t.Errorf("expected a missing target to be reported, got %v", err)
+		}
+	})
+
+	t.Run("invalid report", func(t *testing.T) {
+		count := len(requests)
+		if _, err := callTool(t, s, "import_sarif", map[string]any{"sarif": "{}", "engagement_id": 10}); err == nil {
+			t.Error("expected invalid SARIF to be rejected")
+		}
+		if len(requests) != count {
+			t.Error("expected nothing to be imported")
+		}
+	})
+}
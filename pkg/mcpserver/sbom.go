@@ -0,0 +1,289 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+// Component lookup sizing
+const (
+	componentPageSize         = 100  // Findings per query page
+	maxComponentPages         = 5    // Pages read per component name
+	maxSBOMComponents         = 1000 // Distinct components one call may look up
+	maxComponentFindingsShown = 10   // Findings listed per affected component
+)
+
+// sbomComponent is a dependency to cross-reference with DefectDojo findings
+type sbomComponent struct {
+	name    string   // Name as scanners usually report it, e.g. "lodash" or "log4j-core"
+	aliases []string // Other accepted spellings, e.g. "org.apache.logging.log4j:log4j-core"
+	version string   // Empty when any version should match
+	purl    string   // Package URL, when known
+}
+
+// label describes the component in tool output
+func (c *sbomComponent) label() string {
+	label := strings.TrimSpace(c.name + " " + c.version)
+	if c.purl != "" {
+		label += " (" + c.purl + ")"
+	}
+	return label
+}
+
+// matches reports whether a finding concerns this component. Versions are
+// only compared when both sides know them, ignoring a leading "v".
+func (c *sbomComponent) matches(finding *types.Finding) bool {
+	if !strings.EqualFold(finding.ComponentName, c.name) && !slices.ContainsFunc(c.aliases, func(alias string) bool { return strings.EqualFold(finding.ComponentName, alias) }) {
+		return false
+	}
+	if c.version == "" || finding.ComponentVersion == "" {
+		return true
+	}
+	return strings.TrimPrefix(finding.ComponentVersion, "v") == strings.TrimPrefix(c.version, "v")
+}
+
+// newSBOMComponent builds a component from a name, its namespace or group, and a version
+func newSBOMComponent(namespace, name, version, purl string) sbomComponent {
+	component := sbomComponent{name: name, version: version, purl: purl}
+	if namespace != "" {
+		component.aliases = []string{namespace + "/" + name, namespace + ":" + name}
+	}
+	return component
+}
+
+// parsePURL parses a package URL such as pkg:npm/%40angular/core@16.2.0 or
+// pkg:maven/org.apache.logging.log4j/log4j-core@2.14.1. Qualifiers and
+// subpaths are ignored.
+func parsePURL(purl string) (sbomComponent, error) {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(purl), "pkg:")
+	if !ok {
+		return sbomComponent{}, fmt.Errorf("invalid purl %q: must start with pkg:", purl)
+	}
+	rest, _, _ = strings.Cut(rest, "#")
+	rest, _, _ = strings.Cut(rest, "?")
+	rest = strings.TrimLeft(rest, "/")
+
+	var version string
+	if at := strings.LastIndex(rest, "@"); at > strings.LastIndex(rest, "/") {
+		rest, version = rest[:at], rest[at+1:]
+	}
+	segments := strings.Split(strings.Trim(rest, "/"), "/")
+	if len(segments) < 2 || segments[len(segments)-1] == "" {
+		return sbomComponent{}, fmt.Errorf("invalid purl %q: expected pkg:type/name", purl)
+	}
+	var err error
+	for i, segment := range segments {
+		if segments[i], err = url.PathUnescape(segment); err != nil {
+			return sbomComponent{}, fmt.Errorf("invalid purl %q: %w", purl, err)
+		}
+	}
+	if version, err = url.PathUnescape(version); err != nil {
+		return sbomComponent{}, fmt.Errorf("invalid purl %q: %w", purl, err)
+	}
+	name := segments[len(segments)-1]
+	namespace := strings.Join(segments[1:len(segments)-1], "/")
+	return newSBOMComponent(namespace, name, version, purl), nil
+}
+
+// cycloneDXComponent is the part of a CycloneDX component used for lookups
+type cycloneDXComponent struct {
+	Group      string               `json:"group"`
+	Name       string               `json:"name"`
+	Version    string               `json:"version"`
+	PURL       string               `json:"purl"`
+	Components []cycloneDXComponent `json:"components"`
+}
+
+// parseCycloneDX extracts the components of a CycloneDX JSON SBOM, including
+// nested ones. The purl is preferred; components without a usable purl fall
+// back to group, name and version.
+func parseCycloneDX(content string) ([]sbomComponent, error) {
+	var bom struct {
+		BOMFormat  string               `json:"bomFormat"`
+		Components []cycloneDXComponent `json:"components"`
+	}
+	if err := json.Unmarshal([]byte(content), &bom); err != nil {
+		return nil, fmt.Errorf("not valid JSON: %w", err)
+	}
+	if bom.BOMFormat != "CycloneDX" {
+		return nil, fmt.Errorf("bomFormat must be \"CycloneDX\", got %q", bom.BOMFormat)
+	}
+
+	var components []sbomComponent
+	var walk func([]cycloneDXComponent)
+	walk = func(list []cycloneDXComponent) {
+		for _, c := range list {
+			if component, err := parsePURL(c.PURL); err == nil {
+				components = append(components, component)
+			} else if c.Name != "" {
+				components = append(components, newSBOMComponent(c.Group, c.Name, c.Version, ""))
+			}
+			walk(c.Components)
+		}
+	}
+	walk(bom.Components)
+	return components, nil
+}
+
+// uniqueComponents drops repeated components, keeping the first occurrence
+func uniqueComponents(components []sbomComponent) []sbomComponent {
+	seen := map[string]bool{}
+	var unique []sbomComponent
+	for _, c := range components {
+		key := strings.ToLower(c.name + "\x00" + strings.Join(c.aliases, "\x00") + "\x00" + c.version)
+		if !seen[key] {
+			seen[key] = true
+			unique = append(unique, c)
+		}
+	}
+	return unique
+}
+
+// componentFindings holds the findings found for one component name
+type componentFindings struct {
+	findings []types.Finding
+	err      error
+}
+
+// lookupComponents queries DefectDojo once per distinct component name on the
+// worker pool, and returns the results by lowercased name. Every version and
+// alias of a name is answered from the same query.
+func (s *Server) lookupComponents(ctx context.Context, components []sbomComponent, base types.FindingsFilter) map[string]componentFindings {
+	var names []string
+	started := map[string]bool{}
+	for _, component := range components {
+		name := strings.ToLower(component.name)
+		if !started[name] {
+			started[name] = true
+			names = append(names, name)
+		}
+	}
+
+	results := map[string]componentFindings{}
+	var mu sync.Mutex
+	s.workers.run(ctx, operationComponentLookup, len(names), func(i int) {
+		filter := base
+		filter.ComponentName = names[i]
+		filter.Limit = componentPageSize
+		var result componentFindings
+		for page := range maxComponentPages {
+			filter.Offset = page * componentPageSize
+			response, err := s.findingsPage(ctx, filter)
+			if err != nil {
+				result.err = err
+				break
+			}
+			result.findings = append(result.findings, response.Results...)
+			if response.Next == nil {
+				break
+			}
+		}
+
+		mu.Lock()
+		results[names[i]] = result
+		mu.Unlock()
+	})
+	return results
+}
+
+// findSBOMComponentFindings handles find_sbom_component_findings
+func (s *Server) findSBOMComponentFindings(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var components []sbomComponent
+	if content := request.GetString("sbom", ""); content != "" {
+		parsed, err := parseCycloneDX(content)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CycloneDX SBOM: %w", err)
+		}
+		components = append(components, parsed...)
+	}
+	for _, purl := range request.GetStringSlice("purls", nil) {
+		component, err := parsePURL(purl)
+		if err != nil {
+			return nil, err
+		}
+		components = append(components, component)
+	}
+	components = uniqueComponents(components)
+	if len(components) == 0 {
+		return nil, fmt.Errorf("no components to look up: pass a CycloneDX sbom with components, or purls")
+	}
+	if len(components) > maxSBOMComponents {
+		return nil, fmt.Errorf("too many components (%d): at most %d can be looked up per call", len(components), maxSBOMComponents)
+	}
+
+	var base types.FindingsFilter
+	state := "open " // Qualifies "findings" in the output
+	if request.GetBool("include_closed", false) {
+		state = ""
+	} else {
+		active := true
+		base.Active = &active
+	}
+	if product := request.GetInt("product", 0); product != 0 {
+		base.Product = &product
+	}
+
+	results := s.lookupComponents(ctx, components, base)
+
+	var report strings.Builder
+	var failed []string
+	affected, total := 0, 0
+	for _, component := range components {
+		result := results[strings.ToLower(component.name)]
+		if result.err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", component.label(), result.err))
+			continue
+		}
+		var findings []types.Finding
+		for _, finding := range result.findings {
+			if component.matches(&finding) {
+				findings = append(findings, finding)
+			}
+		}
+		if len(findings) == 0 {
+			continue
+		}
+		affected++
+		total += len(findings)
+		slices.SortStableFunc(findings, func(a, b types.Finding) int {
+			return types.CompareSeverity(b.Severity, a.Severity)
+		})
+
+		fmt.Fprintf(&report, "\n- %s: %d %sfindings\n", component.label(), len(findings), state)
+		for _, finding := range findings[:min(len(findings), maxComponentFindingsShown)] {
+			fmt.Fprintf(&report, "  [%s] %s (ID: %d)", s.severity.display(finding.Severity), finding.Title, finding.ID)
+			if ids := finding.VulnerabilityIDList(); len(ids) > 0 {
+				fmt.Fprintf(&report, " %s", strings.Join(ids, ", "))
+			}
+			if finding.ComponentVersion != "" && component.version == "" {
+				fmt.Fprintf(&report, " in %s", finding.ComponentVersion)
+			}
+			if link := s.links.finding(finding.ID); link != "" {
+				fmt.Fprintf(&report, " — %s", link)
+			}
+			report.WriteString("\n")
+		}
+		if hidden := len(findings) - maxComponentFindingsShown; hidden > 0 {
+			fmt.Fprintf(&report, "  ... and %d more\n", hidden)
+		}
+	}
+
+	if len(failed) == len(components) {
+		return nil, fmt.Errorf("component lookup failed: %s", failed[0])
+	}
+	result := fmt.Sprintf("%d of %d SBOM components have %sfindings (%d findings)\n", affected, len(components), state, total)
+	result += report.String()
+	if len(failed) > 0 {
+		result += fmt.Sprintf("\n⚠️ Lookup failed for %d components, their findings are not included:\n- %s\n", len(failed), strings.Join(failed, "\n- "))
+	}
+	return mcp.NewToolResultText(result), nil
+}
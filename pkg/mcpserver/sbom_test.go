@@ -0,0 +1,156 @@
+package mcpserver
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/brduru/mcp-defect-dojo/internal/defectdojo"
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+func TestParsePURL(t *testing.T) {
+	tests := []struct {
+		purl    string
+		name    string
+		version string
+		aliases []string
+	}{
+		{"pkg:npm/lodash@4.17.15", "lodash", "4.17.15", nil},
+		{"pkg:npm/%40angular/core@16.2.0", "core", "16.2.0", []string{"@angular/core", "@angular:core"}},
+		{"pkg:maven/org.apache.logging.log4j/log4j-core@2.14.1?type=jar", "log4j-core", "2.14.1", []string{"org.apache.logging.log4j/log4j-core", "org.apache.logging.log4j:log4j-core"}},
+		{"pkg:golang/github.com/gin-gonic/gin@v1.9.0#sub", "gin", "v1.9.0", []string{"github.com/gin-gonic/gin", "github.com/gin-gonic:gin"}},
+		{"pkg:pypi/django", "django", "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.purl, func(t *testing.T) {
+			component, err := parsePURL(tt.purl)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if component.name != tt.name || component.version != tt.version || !slices.Equal(component.aliases, tt.aliases) {
+				t.Errorf("parsePURL() = %+v", component)
+			}
+		})
+	}
+
+	for _, purl := range []string{"lodash@4.17.15", "pkg:npm", "pkg:npm/", "pkg:npm/bad%zz@1"} {
+		if _, err := parsePURL(purl); err == nil {
+			t.Errorf("expected %q to be rejected", purl)
+		}
+	}
+}
+
+func TestParseCycloneDX(t *testing.T) {
+	components, err := parseCycloneDX(`{
+		"bomFormat": "CycloneDX",
+		"specVersion": "1.5",
+		"components": [
+			{"name": "lodash", "version": "4.17.15", "purl": "pkg:npm/lodash@4.17.15"},
+			{"group": "org.example", "name": "widget", "version": "2.0", "components": [
+				{"name": "left-pad", "version": "1.3.0"}
+			]}
+		]
+	}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var labels []string
+	for _, c := range components {
+		labels = append(labels, c.label())
+	}
+	want := []string{"lodash 4.17.15 (pkg:npm/lodash@4.17.15)", "widget 2.0", "left-pad 1.3.0"}
+	if !slices.Equal(labels, want) {
+		t.Errorf("components = %v, want %v", labels, want)
+	}
+
+	if _, err := parseCycloneDX(`{"bomFormat": "SPDX"}`); err == nil {
+		t.Error("expected a non-CycloneDX document to be rejected")
+	}
+}
+
+func TestFindSBOMComponentFindings(t *testing.T) {
+	fixtures, err := defectdojo.NewFixtureClient("")
+	if err != nil {
+		t.Fatalf("NewFixtureClient() error = %v", err)
+	}
+	s := newServer(&Config{}, fixtures)
+
+	result, err := callTool(t, s, "find_sbom_component_findings", map[string]any{
+		"sbom":  `{"bomFormat": "CycloneDX", "components": [{"name": "lodash", "version": "4.17.15", "purl": "pkg:npm/lodash@4.17.15"}, {"name": "express", "version": "4.18.2"}]}`,
+		"purls": []any{"pkg:npm/lodash@4.17.21", "pkg:npm/lodash@4.17.15"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text := resultText(result)
+	for _, want := range []string{
+		"1 of 3 SBOM components have open findings (1 findings)",
+		"- lodash 4.17.15 (pkg:npm/lodash@4.17.15): 1 open findings",
+		"[Medium] lodash: Prototype Pollution (CVE-2020-8203) (ID: 4) CVE-2020-8203",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "4.17.21") || strings.Contains(text, "express") {
+		t.Errorf("expected unaffected components to be left out:\n%s", text)
+	}
+
+	result, err = callTool(t, s, "find_sbom_component_findings", map[string]any{"purls": []any{"pkg:npm/lodash@4.17.15"}, "product": 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := resultText(result); !strings.Contains(text, "0 of 1 SBOM components") {
+		t.Errorf("expected the product scope to apply, got:\n%s", text)
+	}
+
+	if _, err := callTool(t, s, "find_sbom_component_findings", map[string]any{}); err == nil {
+		t.Error("expected a call without components to be rejected")
+	}
+}
+
+func TestLookupComponentsBatching(t *testing.T) {
+	var mu sync.Mutex
+	queried := map[string]int{}
+	mock := &MockDefectDojoClient{
+		GetFindingsFunc: func(ctx context.Context, filter types.FindingsFilter) (*types.FindingsResponse, error) {
+			mu.Lock()
+			queried[filter.ComponentName]++
+			mu.Unlock()
+			if filter.ComponentName == "broken" {
+				return nil, errors.New("timeout")
+			}
+			if filter.Active == nil || !*filter.Active {
+				t.Errorf("expected open findings to be queried, got %+v", filter)
+			}
+			return &types.FindingsResponse{Results: []types.Finding{
+				{ID: 1, Title: "Old log4j", Severity: "Critical", ComponentName: "org.apache.logging.log4j:log4j-core", ComponentVersion: "2.14.1"},
+			}}, nil
+		},
+	}
+	s := newServer(&Config{}, mock)
+
+	result, err := callTool(t, s, "find_sbom_component_findings", map[string]any{"purls": []any{
+		"pkg:maven/org.apache.logging.log4j/log4j-core@2.14.1",
+		"pkg:maven/org.apache.logging.log4j/log4j-core@2.17.1",
+		"pkg:npm/broken@1.0.0",
+	}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text := resultText(result)
+	if !strings.Contains(text, "1 of 3 SBOM components") || !strings.Contains(text, "log4j-core 2.14.1") || !strings.Contains(text, "Lookup failed for 1 components") {
+		t.Errorf("unexpected result:\n%s", text)
+	}
+	if queried["log4j-core"] != 1 || queried["broken"] != 1 {
+		t.Errorf("expected one query per component name, got %v", queried)
+	}
+
+	if _, err := callTool(t, s, "find_sbom_component_findings", map[string]any{"purls": []any{"pkg:npm/broken@1.0.0"}}); err == nil {
+		t.Error("expected an error when every lookup fails")
+	}
+}
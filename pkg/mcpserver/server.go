@@ -33,11 +33,12 @@
 //		log.Fatal(err)
 //	}
 //
-//	// Create in-process client
-//	client, err := client.NewInProcessClient(server.GetMCPServer())
+//	// Create an initialized in-process client
+//	client, err := server.NewInProcessClient(ctx)
 //	if err != nil {
 //		log.Fatal(err)
 //	}
+//	defer client.Close()
 //
 // ## Custom Configuration
 //
@@ -85,55 +86,272 @@
 package mcpserver
 
 import (
+	"cmp"
 	"context"
 	"fmt"
+	"io"
+	"log"
+	"slices"
+	"strings"
 	"time"
 
+	"github.com/mark3labs/mcp-go/client"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 
 	"github.com/brduru/mcp-defect-dojo/internal/config"
 	"github.com/brduru/mcp-defect-dojo/internal/defectdojo"
-	"github.com/brduru/mcp-defect-dojo/pkg/types"
+	"github.com/brduru/mcp-defect-dojo/internal/refcache"
+	"github.com/brduru/mcp-defect-dojo/pkg/dojoclient"
 )
 
 // Server represents an MCP DefectDojo server instance
 type Server struct {
+	config    *Config
 	mcpServer *server.MCPServer
 	ddClient  defectdojo.Client
+	health    *healthCache
+	refs      *refcache.Cache
+	queries   map[string]SavedQuery
+	approvals *approvalQueue // nil unless writes require approval
+	events    *eventLog
+	poller    *findingsPoller // nil if the polling query is unusable
+	intel     cveIntelSource  // nil unless CVE enrichment is on
+	issues    issueTracker    // nil unless an issue tracker is configured
+	notifier  *notifier       // nil unless notification webhooks are configured
+	links     webLinks        // DefectDojo UI URLs for tool output
+	severity  severityScale   // Organization severity labels (zero = DefectDojo's names)
+	stats     *toolStats
+	tools     []mcp.Tool        // Registered tools, in registration order
+	access    *accessControl    // nil unless HTTP transport clients authenticate
+	sessions  *sessionStore     // Per-MCP-session working sets and saved results
+	exports   *exportBuffer     // Memory budget and spill files of findings exports
+	workers   *workerPool       // Shared concurrency limit of fan-out operations
+	scopes    *credentialScopes // nil unless per-product credentials are configured
+	instances []dojoInstance    // Further instances the health check probes
+	authErr   error             // Set when the auth configuration is unusable; the HTTP transports then refuse to start
 }
 
 // Config represents the server configuration for the DefectDojo MCP server.
 // This structure contains all the necessary settings to connect to DefectDojo
 // and configure the MCP server behavior.
 type Config struct {
-	DefectDojo DefectDojoConfig // DefectDojo API connection settings
-	Server     ServerConfig     // MCP server metadata and behavior
-	Logging    LoggingConfig    // Logging configuration
+	DefectDojo   DefectDojoConfig   // DefectDojo API connection settings
+	Server       ServerConfig       // MCP server metadata and behavior
+	Logging      LoggingConfig      // Logging configuration
+	Audit        AuditConfig        // Audit trail for mutating operations
+	Output       OutputConfig       // Tool output formatting defaults
+	Queries      QueriesConfig      // Saved findings queries
+	Policy       PolicyConfig       // Rules checked before every write operation
+	Approval     ApprovalConfig     // Human approval of write operations
+	Webhook      WebhookConfig      // DefectDojo webhook notifications
+	Polling      PollingConfig      // Background polling for new and changed findings
+	Enrichment   EnrichmentConfig   // EPSS and CISA KEV data for findings with CVE IDs
+	Priority     PriorityConfig     // Remediation priority formula of prioritize_findings
+	IssueTracker IssueTrackerConfig // GitHub or GitLab project for create_issue_from_finding
+	Notification NotificationConfig // Webhooks told about every write
+	Auth         AuthConfig         // Clients of the HTTP transports and their roles
+	Budgets      BudgetsConfig      // Limits on what one tool call may read from DefectDojo
 }
 
 // DefectDojoConfig contains DefectDojo API configuration.
 // These settings control how the server connects to and interacts with DefectDojo.
 type DefectDojoConfig struct {
 	BaseURL        string        // DefectDojo instance URL (e.g., "https://defectdojo.company.com")
+	UIBaseURL      string        // Web UI URL for links in tool output, when it differs from BaseURL (empty = BaseURL)
 	APIKey         string        // DefectDojo API token for authentication
 	APIVersion     string        // DefectDojo API version to use (typically "v2")
 	RequestTimeout time.Duration // HTTP request timeout for DefectDojo API calls
+	Mode           string        // "live" (default), "offline" to serve fixture data, "record" or "replay" for recorded traffic
+	FixturesDir    string        // Offline mode fixture directory (default: built-in demo data)
+	CassetteDir    string        // Record/replay mode traffic directory
+	ErrorDetail    string        // "full" (default) returns DefectDojo error bodies in tool results, "sanitized" logs them and returns a summary with the request ID
+
+	// Credentials are API tokens used only for some products. When set, each
+	// tool call and resource read runs with the token of the products it
+	// names; calls on products of different credentials, or on products no
+	// credential covers, are refused. APIKey then only serves calls naming no
+	// product, including the findings poller when its query names none; leave
+	// it empty to refuse those too.
+	Credentials []Credential
+
+	// Instances are further DefectDojo instances, e.g. staging, that the
+	// health check probes concurrently with this one in live mode. Tools and
+	// resources only use this instance.
+	Instances []Instance
+}
+
+// Instance is a further DefectDojo instance the health check probes
+type Instance struct {
+	Name    string // Names the instance in the health check, e.g. "staging"
+	BaseURL string // DefectDojo instance URL
+	APIKey  string // DefectDojo API token of the instance
+}
+
+// Credential is a DefectDojo API token used for the products it covers: the
+// listed products and every product of the listed product types. A product
+// listed by ID uses its credential even when its type is listed by another.
+type Credential struct {
+	Name         string // Names the credential in errors and logs, e.g. "payments"
+	APIKey       string // DefectDojo API token, ideally limited to the same products
+	Products     []int  // Product IDs
+	ProductTypes []int  // Product type IDs
 }
 
 // ServerConfig contains MCP server configuration.
 // These settings define the server's identity and behavior in the MCP protocol.
 type ServerConfig struct {
-	Name         string // Server name as reported to MCP clients
-	Version      string // Server version for client compatibility
-	Instructions string // Optional instructions displayed to AI agents
+	Name           string        // Server name as reported to MCP clients
+	Version        string        // Server version for client compatibility
+	Instructions   string        // Optional instructions displayed to AI agents
+	HealthCacheTTL time.Duration // How long readiness probe results are reused (default: 10s)
+	MaxToolTimeout time.Duration // Upper bound for the per-call timeout_seconds argument and client deadline hints (default: 5m)
+	ReadOnly       bool          // Only register tools that do not change DefectDojo
+
+	ReferenceCacheTTL time.Duration // How long products, engagements, tests and test types are cached (default: 10m, negative disables)
+	IdempotencyWindow time.Duration // How long a repeated write call with the same arguments returns the earlier result (default: 2m, negative disables)
+	WorkerPoolSize    int           // DefectDojo requests fan-out operations run at once, across all calls (default: 16)
+	ToolErrors        string        // How failed tool calls are reported: "strict" as JSON-RPC errors (default), "lenient" as error tool results
+	RateLimit         int           // Tool calls each MCP session may make per minute (0 = unlimited)
+	RateLimitBurst    int           // Tool calls a session may make at once within RateLimit (default: 10)
+}
+
+// BudgetsConfig limits what one tool call may read from DefectDojo, so an
+// agent cannot run aggregation queries that load a shared instance. A call
+// exceeding its budget fails with a BudgetError.
+type BudgetsConfig struct {
+	Default ToolBudget            // Budget of every tool, and of the limits a tool's own budget leaves unset
+	Tools   map[string]ToolBudget // Budgets by tool name, e.g. "summarize_security_posture"
+}
+
+// ToolBudget is what one call of a tool may read from DefectDojo. Zero
+// limits are unlimited.
+type ToolBudget struct {
+	MaxPages       int // DefectDojo list requests, findings counts included
+	MaxFindings    int // Findings read from findings lists
+	MaxConcurrency int // DefectDojo requests the call runs at once in fan-out operations (at most the worker pool size)
 }
 
 // LoggingConfig contains logging configuration.
 // Controls how the server logs information for debugging and monitoring.
 type LoggingConfig struct {
-	Level  string // Log level: "debug", "info", "warn", "error"
-	Format string // Log format: "text", "json"
+	Level    string // Log level: "trace", "debug", "info", "warn", "error" ("trace" dumps DefectDojo traffic)
+	Format   string // Log format: "text", "json"
+	DumpFile string // Destination for trace-level traffic dumps (default: stderr)
+}
+
+// AuditConfig contains audit trail configuration.
+// Every write tool call produces an AuditRecord when either option is set.
+type AuditConfig struct {
+	FilePath string      // Append-only JSON lines file for audit records
+	Logger   AuditLogger // Custom audit sink (takes precedence over FilePath)
+}
+
+// OutputConfig controls the size of tool output returned to agents.
+type OutputConfig struct {
+	MaxFieldChars       int    // Default truncation for long finding text sections (0 = unlimited)
+	DetailLevel         string // Default findings list detail: "summary", "normal" or "full" (default: normal)
+	MaxDescriptionChars int    // Default truncation for descriptions in findings lists (0 = unlimited)
+	ListLimit           int    // Default number of findings per list page (default: 10)
+	MaxListLimit        int    // Largest limit argument a tool call may pass (default: 100)
+	Format              string // Default output format: "text", "markdown" or "json" (default: text)
+	Language            string // Language of output labels and headings: "en", "pt" or "es" (default: en)
+	ExportChunkSize     int    // Findings per export_findings chunk resource (default: 1000, at most 5000)
+	ExportMemoryBytes   int64  // Export chunks kept in memory across sessions before the rest spill to disk (default: 32 MiB)
+	ExportSpillDir      string // Directory of export spill files (default: the system temporary directory)
+	MaxResultBytes      int    // Results larger than this are summarized, with a link to the full result (0 = 100 KiB, negative = never)
+	SanitizeContent     bool   // Neutralize instructions embedded in finding text before agents read it
+	HTMLContent         string // HTML in finding text sections: "markdown" converts it to Markdown-style text, "text" to plain text, "raw" keeps it (default: markdown)
+
+	// SeverityLabels maps DefectDojo severities to the organization's own
+	// scale, e.g. {"Critical": "P1", "High": "P2"}. Tool output shows both
+	// names and severity filters accept either.
+	SeverityLabels map[string]string
+}
+
+// QueriesConfig contains the saved findings queries offered by run_saved_query.
+type QueriesConfig struct {
+	FilePath string                // JSON file of saved queries, see LoadSavedQueries
+	Saved    map[string]SavedQuery // Saved queries by name (takes precedence over FilePath)
+}
+
+// PolicyConfig contains the write policy enforced on mutating tool calls.
+type PolicyConfig struct {
+	FilePath string       // JSON file of policy rules, see LoadWritePolicy
+	Rules    *WritePolicy // Policy rules (takes precedence over FilePath)
+}
+
+// ApprovalConfig controls the human approval queue for write operations.
+// When Required is set, write tool calls are queued as PendingActions and only
+// applied through ApproveAction or ApprovalHandler.
+type ApprovalConfig struct {
+	Required bool   // Queue write tool calls until a human approves them
+	Token    string // Bearer token ApprovalHandler requires (empty = unauthenticated)
+}
+
+// WebhookConfig controls the DefectDojo webhook receiver, see WebhookHandler.
+type WebhookConfig struct {
+	Secret     string // Shared secret expected in the Authorization header (empty = unauthenticated)
+	BufferSize int    // Number of recent events kept for get_recent_events (default: 100)
+}
+
+// PollingConfig controls the findings poller behind get_new_findings_since_last_check.
+type PollingConfig struct {
+	Interval time.Duration // Time between background polls, see StartPolling (0 = poll when the tool is called)
+	Query    string        // Saved query selecting the watched findings (default: active findings)
+}
+
+// EnrichmentConfig controls the exploitation data added to findings with CVE IDs.
+// EPSS scores and CISA KEV listings appear in get_finding_detail and the
+// prioritization tools when Mode is EnrichmentLive or EnrichmentOffline.
+type EnrichmentConfig struct {
+	Mode     string        // "off" (default), "live" or "offline", see EnrichmentOff
+	DataDir  string        // Offline mode data directory, see LoadCVEDataset
+	Dataset  *CVEDataset   // Offline mode data (takes precedence over DataDir)
+	CacheTTL time.Duration // How long live lookups are cached (default: 24h, negative disables)
+	EPSSURL  string        // EPSS API endpoint (default: FIRST's public API)
+	KEVURL   string        // KEV catalog feed (default: CISA's public feed)
+}
+
+// PriorityConfig controls the remediation priority score of prioritize_findings.
+type PriorityConfig struct {
+	Weights      PriorityWeights // Factor weights (zero value = DefaultPriorityWeights)
+	CriticalTags []string        // Product tags marking business-critical products (none = criticality not scored)
+}
+
+// AuthConfig authenticates the clients of the http and sse transports and
+// limits each to the tools of its role, so one server can serve several
+// agent personas. The built-in roles are RoleViewer, RoleTriager and
+// RoleAdmin; Roles may replace them or add others. Stdio and in-process
+// callers are trusted and may call every tool.
+type AuthConfig struct {
+	Tokens []AuthToken         // Accepted bearer tokens (empty = unauthenticated)
+	Roles  map[string][]string // Tool names by role, replacing or adding to the built-in roles
+}
+
+// AuthToken is one client of the HTTP transports, sending
+// "Authorization: Bearer <Token>"
+type AuthToken struct {
+	Name  string // Caller identity in audit records, e.g. "triage-agent"
+	Token string // Bearer token the client sends
+	Role  string // Role deciding the tools the client may call
+}
+
+// NotificationConfig lists the webhooks, such as Slack or Microsoft Teams
+// incoming webhooks, that receive a message after every write tool call.
+type NotificationConfig struct {
+	FilePath string                // JSON file of webhooks, see LoadNotificationWebhooks
+	Webhooks []NotificationWebhook // Webhooks (takes precedence over FilePath)
+}
+
+// IssueTrackerConfig selects where create_issue_from_finding files issues.
+// The tool fails with a configuration hint while Provider is empty.
+type IssueTrackerConfig struct {
+	Provider   string   // IssueTrackerGitHub or IssueTrackerGitLab (empty = disabled)
+	Token      string   // API token allowed to create issues
+	Repository string   // GitHub owner/name, or GitLab project path or numeric ID
+	APIURL     string   // API base URL (default: api.github.com or gitlab.com/api/v4)
+	Labels     []string // Labels added to every issue
 }
 
 // NewServer creates a new MCP DefectDojo server with the provided configuration.
@@ -150,51 +368,397 @@ type LoggingConfig struct {
 //   - get_finding_detail: Get detailed information about a specific finding
 //   - mark_finding_false_positive: Mark findings as false positives with justification
 //   - defectdojo_health_check: Test DefectDojo API connectivity
+//
+// The DefectDojo client can only fail to be created in offline mode with
+// unusable fixtures. NewServer then logs the error and returns a server whose
+// tools and resources fail with it; use NewServerWithConfig to get the error
+// instead.
 func NewServer(cfg *Config) *Server {
 	// Use default config if nil is provided
 	if cfg == nil {
-		defaultCfg := config.DefaultConfig()
-		cfg = &Config{
-			DefectDojo: DefectDojoConfig{
-				BaseURL:        defaultCfg.DefectDojo.BaseURL,
-				APIKey:         defaultCfg.DefectDojo.APIKey,
-				APIVersion:     defaultCfg.DefectDojo.APIVersion,
-				RequestTimeout: defaultCfg.DefectDojo.RequestTimeout,
-			},
-			Server: ServerConfig{
-				Name:         defaultCfg.Server.Name,
-				Version:      defaultCfg.Server.Version,
-				Instructions: defaultCfg.Server.Instructions,
-			},
-			Logging: LoggingConfig{
-				Level:  defaultCfg.Logging.Level,
-				Format: defaultCfg.Logging.Format,
-			},
-		}
+		cfg = configFromInternal(config.DefaultConfig())
+	}
+	server, err := NewServerWithConfig(cfg)
+	if err != nil {
+		log.Printf("⚠️  DefectDojo unavailable, every call will fail: %v", err)
+		return newServer(cfg, defectdojo.UnavailableClient{Err: err})
+	}
+	return server
+}
+
+// NewServerWithConfig is NewServer returning an error when the DefectDojo
+// client cannot be created. Offline mode never falls back to other data:
+// fixtures that fail to load are an error.
+func NewServerWithConfig(cfg *Config) (*Server, error) {
+	// Use default config if nil is provided
+	if cfg == nil {
+		cfg = configFromInternal(config.DefaultConfig())
 	}
 
 	// Create DefectDojo client
-	ddClient := defectdojo.NewHTTPClient(&config.DefectDojoConfig{
+	ddClient, err := defectdojo.NewClient(&config.DefectDojoConfig{
 		BaseURL:        cfg.DefectDojo.BaseURL,
 		APIKey:         cfg.DefectDojo.APIKey,
 		APIVersion:     cfg.DefectDojo.APIVersion,
 		RequestTimeout: cfg.DefectDojo.RequestTimeout,
+		DumpTraffic:    cfg.Logging.Level == "trace",
+		DumpFile:       cfg.Logging.DumpFile,
+		Mode:           cfg.DefectDojo.Mode,
+		FixturesDir:    cfg.DefectDojo.FixturesDir,
+		CassetteDir:    cfg.DefectDojo.CassetteDir,
+		ErrorDetail:    cfg.DefectDojo.ErrorDetail,
 	})
+	if err != nil {
+		// Only offline mode can fail; never fall through to a live instance
+		return nil, fmt.Errorf("offline fixtures unusable: %w", err)
+	}
+
+	return newServer(cfg, ddClient), nil
+}
+
+// newServer wires the MCP server around an existing DefectDojo client.
+func newServer(cfg *Config, ddClient defectdojo.Client) *Server {
+	stats := newToolStats()
+	var opts []server.ServerOption
+	switch cfg.Server.ToolErrors {
+	case "", toolErrorsStrict:
+	case toolErrorsLenient:
+		opts = append(opts, server.WithToolHandlerMiddleware(lenientErrorMiddleware()))
+	default:
+		log.Printf("⚠️  Unknown tool error mode %q, reporting failed calls as protocol errors (supported: %s)", cfg.Server.ToolErrors, strings.Join(toolErrorModes(), ", "))
+	}
+	opts = append(opts,
+		server.WithToolCapabilities(true),
+		server.WithResourceCapabilities(false, false),
+		server.WithToolHandlerMiddleware(tracingMiddleware()),
+		server.WithToolHandlerMiddleware(statsMiddleware(stats)),
+		server.WithToolHandlerMiddleware(requestIDMiddleware(cfg.Logging.Level == "debug" || cfg.Logging.Level == "trace")),
+	)
+
+	// Summarize results clients would cut off, keeping the full ones in the session
+	sessions := newSessionStore()
+	if cfg.Output.MaxResultBytes >= 0 {
+		opts = append(opts, server.WithToolHandlerMiddleware(resultSizeMiddleware(cmp.Or(cfg.Output.MaxResultBytes, defaultMaxResultBytes), sessions, stats)))
+	}
+
+	// Stop runaway agent loops before their calls cost anything
+	if cfg.Server.RateLimit > 0 {
+		limiter := newRateLimiter(cfg.Server.RateLimit, cmp.Or(max(cfg.Server.RateLimitBurst, 0), defaultRateLimitBurst))
+		opts = append(opts, server.WithToolHandlerMiddleware(rateLimitMiddleware(limiter)))
+	}
+
+	// Bound what one call may read from DefectDojo
+	if budgets := newToolBudgets(cfg.Budgets); budgets != nil {
+		opts = append(opts, server.WithToolHandlerMiddleware(budgetMiddleware(budgets)))
+	}
+
+	// Limit authenticated HTTP clients to the tools of their role, both in
+	// tools/list and when a call is dispatched
+	access, authErr := newAccessControl(cfg.Auth)
+	if authErr != nil {
+		log.Printf("⚠️  HTTP client authentication unusable, the HTTP transports will not start: %v", authErr)
+	}
+	if access != nil {
+		opts = append(opts, server.WithToolFilter(access.filterTools), server.WithToolHandlerMiddleware(roleMiddleware(access)))
+	}
+
+	// Turn saved results into finding IDs before anything checks them
+	opts = append(opts, server.WithToolHandlerMiddleware(savedResultMiddleware(sessions)))
+
+	// Choose the per-product credential before anything reads DefectDojo
+	scopes := newCredentialScopes(cfg.DefectDojo)
+	if scopes != nil {
+		opts = append(opts, server.WithToolHandlerMiddleware(credentialMiddleware(scopes, ddClient)))
+	}
+	opts = append(opts, server.WithToolHandlerMiddleware(featureGateMiddleware(ddClient)))
+
+	maxTimeout := cfg.Server.MaxToolTimeout
+	if maxTimeout <= 0 {
+		maxTimeout = defaultMaxToolTimeout
+	}
+	opts = append(opts, server.WithToolHandlerMiddleware(timeoutMiddleware(maxTimeout)))
+
+	// Replay retried writes outside approvals, auditing and notifications, so a
+	// duplicate is neither queued, audited nor announced twice
+	idempotencyWindow := cfg.Server.IdempotencyWindow
+	if idempotencyWindow == 0 {
+		idempotencyWindow = defaultIdempotencyWindow
+	}
+	if idempotencyWindow > 0 {
+		opts = append(opts, server.WithToolHandlerMiddleware(idempotencyMiddleware(newIdempotencyCache(idempotencyWindow))))
+	}
+
+	// Hold writes for approval outside auditing and policy, which run when
+	// they are applied. Without approval mode, the policy may still hold some.
+	policy := writePolicy(cfg.Policy)
+	var approvals *approvalQueue
+	switch {
+	case cfg.Approval.Required:
+		approvals = newApprovalQueue()
+		opts = append(opts, server.WithToolHandlerMiddleware(approvalMiddleware(approvals, nil)))
+	case policy.holdsDowngrades():
+		approvals = newApprovalQueue()
+		opts = append(opts, server.WithToolHandlerMiddleware(approvalMiddleware(approvals, func(ctx context.Context, request mcp.CallToolRequest) (string, error) {
+			return policy.needsApproval(ctx, ddClient, request)
+		})))
+	}
+	// Approved writes run under the approver's context: choose again
+	if approvals != nil && scopes != nil {
+		opts = append(opts, server.WithToolHandlerMiddleware(credentialMiddleware(scopes, ddClient)))
+	}
+
+	// Audit every mutating tool call when an audit sink is configured
+	auditLogger := cfg.Audit.Logger
+	if auditLogger == nil && cfg.Audit.FilePath != "" {
+		auditLogger = NewFileAuditLogger(cfg.Audit.FilePath)
+	}
+	if auditLogger != nil {
+		opts = append(opts, server.WithToolHandlerMiddleware(auditMiddleware(auditLogger)))
+	}
+
+	// Tell webhooks about writes; inside auditing so both see the same outcome
+	webhooks, err := notificationWebhooks(cfg.Notification)
+	if err != nil {
+		log.Printf("⚠️  Write notifications disabled: %v", err)
+	}
+	notifier, err := newNotifier(webhooks)
+	if err != nil {
+		log.Printf("⚠️  Write notifications disabled: %v", err)
+	}
+	if notifier != nil {
+		opts = append(opts, server.WithToolHandlerMiddleware(notifyMiddleware(notifier)))
+	}
+
+	// Enforce the write policy inside auditing, so rejected writes are audited too
+	if !policy.empty() {
+		opts = append(opts, server.WithToolHandlerMiddleware(policyMiddleware(policy, ddClient)))
+	}
 
 	// Create MCP server using mcp-go
 	mcpServer := server.NewMCPServer(
 		cfg.Server.Name,
 		cfg.Server.Version,
-		server.WithToolCapabilities(true),
+		opts...,
 	)
 
-	// Add DefectDojo tools
-	addDefectDojoTools(mcpServer, ddClient)
+	healthTTL := cfg.Server.HealthCacheTTL
+	if healthTTL <= 0 {
+		healthTTL = defaultHealthCacheTTL
+	}
+	refTTL := cfg.Server.ReferenceCacheTTL
+	if refTTL == 0 {
+		refTTL = defaultReferenceCacheTTL
+	}
 
-	return &Server{
+	s := &Server{
+		config:    cfg,
 		mcpServer: mcpServer,
 		ddClient:  ddClient,
+		health:    &healthCache{client: ddClient, ttl: healthTTL},
+		refs:      refcache.New(refTTL),
+		queries:   savedQueries(cfg.Queries),
+		approvals: approvals,
+		notifier:  notifier,
+		links:     newWebLinks(cfg.DefectDojo),
+		stats:     stats,
+		events:    newEventLog(cfg.Webhook.BufferSize),
+		access:    access,
+		scopes:    scopes,
+		instances: furtherInstances(cfg.DefectDojo),
+		authErr:   authErr,
+		sessions:  sessions,
+		exports:   newExportBuffer(cfg.Output.ExportSpillDir, cmp.Or(cfg.Output.ExportMemoryBytes, defaultExportMemory)),
+		workers:   newWorkerPool(cmp.Or(max(cfg.Server.WorkerPoolSize, 0), defaultWorkerPoolSize)),
+	}
+
+	if mode := cfg.Output.HTMLContent; mode != "" && !slices.Contains(htmlModes(), mode) {
+		log.Printf("⚠️  Unknown HTML content handling %q, converting to Markdown (supported: %s)", mode, strings.Join(htmlModes(), ", "))
+	}
+	if language := cfg.Output.Language; language != "" && language != languageEnglish && catalogs[language] == nil {
+		log.Printf("⚠️  Unknown output language %q, using English (supported: %s)", language, strings.Join(outputLanguages(), ", "))
 	}
+
+	severities, err := newSeverityScale(cfg.Output.SeverityLabels)
+	if err != nil {
+		log.Printf("⚠️  Severity labels ignored: %v", err)
+	}
+	s.severity = severities
+
+	poller, err := s.newPoller(cfg.Polling)
+	if err != nil {
+		log.Printf("⚠️  Findings polling disabled: %v", err)
+	}
+	s.poller = poller
+
+	intel, err := newCVEIntelSource(cfg.Enrichment)
+	if err != nil {
+		log.Printf("⚠️  CVE enrichment disabled: %v", err)
+	}
+	s.intel = intel
+
+	issues, err := newIssueTracker(cfg.IssueTracker)
+	if err != nil {
+		log.Printf("⚠️  Issue hand-off disabled: %v", err)
+	}
+	s.issues = issues
+
+	// Add DefectDojo tools and resources
+	s.addDefectDojoTools()
+	s.addDefectDojoResources()
+
+	return s
+}
+
+// configFromInternal converts the internal configuration into the public Config format.
+func configFromInternal(cfg *config.Config) *Config {
+	return &Config{
+		DefectDojo: DefectDojoConfig{
+			BaseURL:        cfg.DefectDojo.BaseURL,
+			UIBaseURL:      cfg.DefectDojo.UIBaseURL,
+			APIKey:         cfg.DefectDojo.APIKey,
+			APIVersion:     cfg.DefectDojo.APIVersion,
+			RequestTimeout: cfg.DefectDojo.RequestTimeout,
+			Mode:           cfg.DefectDojo.Mode,
+			FixturesDir:    cfg.DefectDojo.FixturesDir,
+			CassetteDir:    cfg.DefectDojo.CassetteDir,
+			ErrorDetail:    cfg.DefectDojo.ErrorDetail,
+			Credentials:    credentialsFromInternal(cfg.DefectDojo.Credentials),
+			Instances:      instancesFromInternal(cfg.DefectDojo.Instances),
+		},
+		Server: ServerConfig{
+			Name:           cfg.Server.Name,
+			Version:        cfg.Server.Version,
+			Instructions:   cfg.Server.Instructions,
+			MaxToolTimeout: cfg.Server.MaxToolTimeout,
+			ReadOnly:       cfg.Server.ReadOnly,
+
+			ReferenceCacheTTL: cfg.Server.ReferenceCacheTTL,
+			IdempotencyWindow: cfg.Server.IdempotencyWindow,
+			WorkerPoolSize:    cfg.Server.WorkerPoolSize,
+			ToolErrors:        cfg.Server.ToolErrors,
+			RateLimit:         cfg.Server.RateLimit,
+			RateLimitBurst:    cfg.Server.RateLimitBurst,
+		},
+		Logging: LoggingConfig{
+			Level:    cfg.Logging.Level,
+			Format:   cfg.Logging.Format,
+			DumpFile: cfg.Logging.DumpFile,
+		},
+		Audit: AuditConfig{
+			FilePath: cfg.Audit.FilePath,
+		},
+		Output: OutputConfig{
+			MaxFieldChars:       cfg.Output.MaxFieldChars,
+			DetailLevel:         cfg.Output.DetailLevel,
+			MaxDescriptionChars: cfg.Output.MaxDescriptionChars,
+			ListLimit:           cfg.Output.ListLimit,
+			MaxListLimit:        cfg.Output.MaxListLimit,
+			Format:              cfg.Output.Format,
+			Language:            cfg.Output.Language,
+			ExportChunkSize:     cfg.Output.ExportChunkSize,
+			ExportMemoryBytes:   int64(cfg.Output.ExportMemoryMB) << 20,
+			ExportSpillDir:      cfg.Output.ExportSpillDir,
+			MaxResultBytes:      maxResultBytesFromInternal(cfg.Output.MaxResultKB),
+			SanitizeContent:     cfg.Output.SanitizeContent,
+			HTMLContent:         cfg.Output.HTMLContent,
+			SeverityLabels:      cfg.Output.SeverityLabels,
+		},
+		Queries: QueriesConfig{
+			FilePath: cfg.Queries.FilePath,
+		},
+		Policy: PolicyConfig{
+			FilePath: cfg.Policy.FilePath,
+		},
+		Approval: ApprovalConfig{
+			Required: cfg.Approval.Required,
+			Token:    cfg.Approval.Token,
+		},
+		Webhook: WebhookConfig{
+			Secret:     cfg.Webhook.Secret,
+			BufferSize: cfg.Webhook.BufferSize,
+		},
+		Polling: PollingConfig{
+			Interval: cfg.Polling.Interval,
+			Query:    cfg.Polling.Query,
+		},
+		Enrichment: EnrichmentConfig{
+			Mode:     cfg.Enrichment.Mode,
+			DataDir:  cfg.Enrichment.DataDir,
+			CacheTTL: cfg.Enrichment.CacheTTL,
+		},
+		Priority: PriorityConfig{
+			Weights:      PriorityWeights(cfg.Priority.Weights),
+			CriticalTags: cfg.Priority.CriticalTags,
+		},
+		IssueTracker: IssueTrackerConfig{
+			Provider:   cfg.Issues.Provider,
+			Token:      cfg.Issues.Token,
+			Repository: cfg.Issues.Repository,
+			APIURL:     cfg.Issues.APIURL,
+			Labels:     cfg.Issues.Labels,
+		},
+		Notification: notificationConfig(cfg.Notify),
+		Auth:         authFromInternal(cfg.Auth),
+		Budgets:      budgetsFromInternal(cfg.Budgets),
+	}
+}
+
+// credentialsFromInternal converts the configured per-product credentials
+func credentialsFromInternal(credentials []config.CredentialConfig) []Credential {
+	var result []Credential
+	for _, credential := range credentials {
+		result = append(result, Credential(credential))
+	}
+	return result
+}
+
+// instancesFromInternal converts the configured further DefectDojo instances
+func instancesFromInternal(instances []config.InstanceConfig) []Instance {
+	var result []Instance
+	for _, instance := range instances {
+		result = append(result, Instance(instance))
+	}
+	return result
+}
+
+// authFromInternal converts the configured HTTP clients and roles
+func authFromInternal(cfg config.AuthConfig) AuthConfig {
+	result := AuthConfig{Roles: cfg.Roles}
+	for _, token := range cfg.Tokens {
+		result.Tokens = append(result.Tokens, AuthToken(token))
+	}
+	return result
+}
+
+// budgetsFromInternal converts the configured tool call budgets
+func budgetsFromInternal(cfg config.BudgetsConfig) BudgetsConfig {
+	result := BudgetsConfig{Default: ToolBudget(cfg.Default), Tools: map[string]ToolBudget{}}
+	for tool, budget := range cfg.Tools {
+		result.Tools[tool] = ToolBudget(budget)
+	}
+	return result
+}
+
+// maxResultBytesFromInternal converts max_result_kb, where 0 turns summarizing off
+func maxResultBytesFromInternal(kb int) int {
+	if kb <= 0 {
+		return -1
+	}
+	return kb << 10
+}
+
+// notificationConfig adds the single NOTIFY_WEBHOOK_URL webhook to those of the webhooks file.
+func notificationConfig(cfg config.NotificationConfig) NotificationConfig {
+	result := NotificationConfig{FilePath: cfg.FilePath}
+	if cfg.WebhookURL == "" {
+		return result
+	}
+	webhooks, err := notificationWebhooks(result)
+	if err != nil {
+		log.Printf("⚠️  Notification webhooks file ignored: %v", err)
+	}
+	result.Webhooks = append(webhooks, NotificationWebhook{URL: cfg.WebhookURL, Format: cfg.WebhookFormat})
+	return result
 }
 
 // NewServerWithAPIKey creates a new MCP DefectDojo server using default configuration with API key override.
@@ -213,26 +777,7 @@ func NewServerWithAPIKey(apiKey string) (*Server, error) {
 	// Override API key
 	cfg.DefectDojo.APIKey = apiKey
 
-	// Convert to mcpserver.Config format
-	mcpConfig := &Config{
-		DefectDojo: DefectDojoConfig{
-			BaseURL:        cfg.DefectDojo.BaseURL,
-			APIKey:         cfg.DefectDojo.APIKey,
-			APIVersion:     cfg.DefectDojo.APIVersion,
-			RequestTimeout: cfg.DefectDojo.RequestTimeout,
-		},
-		Server: ServerConfig{
-			Name:         cfg.Server.Name,
-			Version:      cfg.Server.Version,
-			Instructions: cfg.Server.Instructions,
-		},
-		Logging: LoggingConfig{
-			Level:  cfg.Logging.Level,
-			Format: cfg.Logging.Format,
-		},
-	}
-
-	return NewServer(mcpConfig), nil
+	return NewServerWithConfig(configFromInternal(cfg))
 }
 
 // DefectDojoSettings contains DefectDojo connection settings for embedded usage
@@ -263,26 +808,7 @@ func NewServerWithSettings(settings DefectDojoSettings) (*Server, error) {
 		cfg.DefectDojo.APIVersion = settings.APIVersion
 	}
 
-	// Convert to mcpserver.Config format
-	mcpConfig := &Config{
-		DefectDojo: DefectDojoConfig{
-			BaseURL:        cfg.DefectDojo.BaseURL,
-			APIKey:         cfg.DefectDojo.APIKey,
-			APIVersion:     cfg.DefectDojo.APIVersion,
-			RequestTimeout: cfg.DefectDojo.RequestTimeout,
-		},
-		Server: ServerConfig{
-			Name:         cfg.Server.Name,
-			Version:      cfg.Server.Version,
-			Instructions: cfg.Server.Instructions,
-		},
-		Logging: LoggingConfig{
-			Level:  cfg.Logging.Level,
-			Format: cfg.Logging.Format,
-		},
-	}
-
-	return NewServer(mcpConfig), nil
+	return NewServerWithConfig(configFromInternal(cfg))
 }
 
 // Run starts the MCP server with stdio transport.
@@ -300,6 +826,39 @@ func (s *Server) Run(ctx context.Context) error {
 	return server.ServeStdio(s.mcpServer)
 }
 
+// SetTrafficDump enables or disables dumping of DefectDojo HTTP traffic at runtime.
+// Dumps contain method, URL, headers (credentials redacted), status and truncated
+// bodies, and are useful for diagnosing why a filter returns unexpected results.
+// It returns false if the underlying client does not support dumping.
+func (s *Server) SetTrafficDump(enabled bool) bool {
+	dumper, ok := s.ddClient.(interface{ SetTrafficDump(bool) })
+	if !ok {
+		return false
+	}
+	dumper.SetTrafficDump(enabled)
+	return true
+}
+
+// TrafficDumpEnabled reports whether DefectDojo traffic dumping is currently active.
+func (s *Server) TrafficDumpEnabled() bool {
+	dumper, ok := s.ddClient.(interface{ TrafficDumpEnabled() bool })
+	return ok && dumper.TrafficDumpEnabled()
+}
+
+// Close releases what the DefectDojo client holds open, such as the traffic
+// dump file. The server must not be used afterwards.
+func (s *Server) Close() error {
+	for _, instance := range s.instances {
+		if closer, ok := instance.client.(io.Closer); ok {
+			closer.Close()
+		}
+	}
+	if closer, ok := s.ddClient.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
 // GetMCPServer returns the underlying MCP server for in-process use.
 // This enables direct integration with MCP clients in the same process,
 // avoiding the overhead of network or stdio communication.
@@ -309,161 +868,53 @@ func (s *Server) Run(ctx context.Context) error {
 //
 // Use this method when you want to embed the DefectDojo MCP server
 // directly in your application for maximum performance and simplicity.
-// This is useful for creating in-process clients with client.NewInProcessClient().
+// NewInProcessClient wraps client.NewInProcessClient for the common case.
 func (s *Server) GetMCPServer() *server.MCPServer {
 	return s.mcpServer
 }
 
-// Available MCP Tools:
-//
-// The DefectDojo MCP server provides the following tools for AI agents:
-//
-// - defectdojo_health_check: Test connectivity to DefectDojo instance
-//   Returns the health status and version information
-//
-// - get_defectdojo_findings: Query vulnerability findings with filters
-//   Supports pagination, severity filtering, and active/inactive status
+// NewInProcessClient returns an MCP client connected to this server in the
+// same process, already started and initialized, so tools can be called
+// right away. The caller closes it when done.
 //
-// - get_finding_detail: Get comprehensive details for a specific finding
-//   Returns full vulnerability information including CVSS scores and descriptions
-//
-// - mark_finding_false_positive: Mark findings as false positives
-//   Requires justification and supports additional notes for audit trail
-
-// addDefectDojoTools registers all DefectDojo MCP tools with the server.
-// This function sets up the tool handlers and their JSON schemas for parameter validation.
-func addDefectDojoTools(s *server.MCPServer, ddClient defectdojo.Client) {
-	// Health check tool
-	healthTool := mcp.NewTool("defectdojo_health_check",
-		mcp.WithDescription("Check if DefectDojo instance is accessible and responsive"),
-	)
-	s.AddTool(healthTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		isHealthy, message := ddClient.HealthCheck(ctx)
-		if !isHealthy {
-			return nil, fmt.Errorf("DefectDojo Health Check failed: %s", message)
-		}
-		return mcp.NewToolResultText(fmt.Sprintf("DefectDojo Health Check: ✅ HEALTHY\n\n%s", message)), nil
-	})
-
-	// Get findings tool
-	findingsTool := mcp.NewTool("get_defectdojo_findings",
-		mcp.WithDescription("Retrieve vulnerability findings from DefectDojo instance with optional filtering"),
-		mcp.WithNumber("limit", mcp.Description("Number of findings to retrieve (default: 10)")),
-		mcp.WithNumber("offset", mcp.Description("Offset for pagination (default: 0)")),
-		mcp.WithBoolean("active_only", mcp.Description("Filter only active findings (default: true)")),
-		mcp.WithString("severity", mcp.Description("Filter by severity (Critical, High, Medium, Low, Info)")),
-		mcp.WithNumber("test", mcp.Description("Filter by test ID")),
-	)
-	s.AddTool(findingsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		// Parse parameters
-		filter := types.FindingsFilter{
-			Limit:      request.GetInt("limit", 10),
-			Offset:     request.GetInt("offset", 0),
-			ActiveOnly: request.GetBool("active_only", true),
-			Severity:   request.GetString("severity", ""),
-		}
-
-		if test := request.GetInt("test", 0); test != 0 {
-			filter.Test = &test
-		}
-
-		// Call DefectDojo API
-		response, err := ddClient.GetFindings(ctx, filter)
-		if err != nil {
-			return nil, fmt.Errorf("error retrieving findings: %w", err)
-		}
-
-		// Format response
-		result := fmt.Sprintf("Found %d findings (showing %d):\n\n", response.Count, len(response.Results))
-		for i, finding := range response.Results {
-			result += fmt.Sprintf("%d. [%s] %s (ID: %d)\n", i+1, finding.Severity, finding.Title, finding.ID)
-			result += fmt.Sprintf("   Active: %t, Verified: %t, False Positive: %t\n", finding.Active, finding.Verified, finding.FalseP)
-			if finding.Description != "" {
-				result += fmt.Sprintf("   Description: %s\n", finding.Description)
-			}
-			result += "\n"
-		}
-
-		return mcp.NewToolResultText(result), nil
-	})
-
-	// Get finding detail tool
-	detailTool := mcp.NewTool("get_finding_detail",
-		mcp.WithDescription("Get detailed information about a specific finding by ID"),
-		mcp.WithNumber("finding_id", mcp.Required(), mcp.Description("The ID of the finding to retrieve")),
-	)
-	s.AddTool(detailTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		findingID, err := request.RequireInt("finding_id")
-		if err != nil {
-			return nil, fmt.Errorf("invalid finding_id: %w", err)
-		}
-
-		finding, err := ddClient.GetFindingDetail(ctx, findingID)
-		if err != nil {
-			return nil, fmt.Errorf("error retrieving finding %d: %w", findingID, err)
-		}
-
-		result := fmt.Sprintf("Finding Details (ID: %d):\n\n", finding.ID)
-		result += fmt.Sprintf("Title: %s\n", finding.Title)
-		result += fmt.Sprintf("Severity: %s\n", finding.Severity)
-		result += fmt.Sprintf("Active: %t\n", finding.Active)
-		result += fmt.Sprintf("Verified: %t\n", finding.Verified)
-		result += fmt.Sprintf("False Positive: %t\n", finding.FalseP)
-		result += fmt.Sprintf("Test ID: %d\n", finding.Test)
-		if finding.Created != "" {
-			result += fmt.Sprintf("Created: %s\n", finding.Created)
-		}
-		if finding.Modified != "" {
-			result += fmt.Sprintf("Modified: %s\n", finding.Modified)
-		}
-		if finding.Description != "" {
-			result += fmt.Sprintf("\nDescription:\n%s\n", finding.Description)
-		}
-
-		return mcp.NewToolResultText(result), nil
-	})
-
-	// Mark false positive tool
-	falsePositiveTool := mcp.NewTool("mark_finding_false_positive",
-		mcp.WithDescription("Mark a finding as false positive with justification and optional notes/comments"),
-		mcp.WithNumber("finding_id", mcp.Required(), mcp.Description("The ID of the finding to mark as false positive")),
-		mcp.WithString("justification", mcp.Required(), mcp.Description("Justification for marking as false positive")),
-		mcp.WithString("notes", mcp.Description("Optional additional notes or comments")),
-	)
-	s.AddTool(falsePositiveTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		findingID, err := request.RequireInt("finding_id")
-		if err != nil {
-			return nil, fmt.Errorf("invalid finding_id: %w", err)
-		}
-
-		justification, err := request.RequireString("justification")
-		if err != nil {
-			return nil, fmt.Errorf("invalid justification: %w", err)
-		}
-
-		notes := request.GetString("notes", "")
-
-		fpRequest := types.FalsePositiveRequest{
-			IsFalsePositive: true,
-			Justification:   justification,
-			Notes:           notes,
-		}
-
-		response, err := ddClient.MarkFalsePositive(ctx, findingID, fpRequest)
-		if err != nil {
-			return nil, fmt.Errorf("error marking finding %d as false positive: %w", findingID, err)
-		}
+//	mcpClient, err := server.NewInProcessClient(ctx)
+//	if err != nil {
+//		return err
+//	}
+//	defer mcpClient.Close()
+//	result, err := mcpClient.CallTool(ctx, mcp.CallToolRequest{
+//		Params: mcp.CallToolParams{Name: "defectdojo_health_check"},
+//	})
+func (s *Server) NewInProcessClient(ctx context.Context) (*client.Client, error) {
+	mcpClient, err := client.NewInProcessClient(s.mcpServer)
+	if err != nil {
+		return nil, fmt.Errorf("creating in-process client: %w", err)
+	}
+	if err := mcpClient.Start(ctx); err != nil {
+		mcpClient.Close()
+		return nil, fmt.Errorf("starting in-process client: %w", err)
+	}
+	initRequest := mcp.InitializeRequest{}
+	initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	initRequest.Params.ClientInfo = mcp.Implementation{Name: "mcp-defect-dojo-in-process", Version: s.config.Server.Version}
+	if _, err := mcpClient.Initialize(ctx, initRequest); err != nil {
+		mcpClient.Close()
+		return nil, fmt.Errorf("initializing in-process client: %w", err)
+	}
+	return mcpClient, nil
+}
 
-		result := fmt.Sprintf("Successfully marked finding %d as false positive:\n\n", response.ID)
-		result += fmt.Sprintf("False Positive: %t\n", response.FalseP)
-		result += fmt.Sprintf("Justification: %s\n", response.Justification)
-		if response.Notes != "" {
-			result += fmt.Sprintf("Notes: %s\n", response.Notes)
-		}
-		if response.Message != "" {
-			result += fmt.Sprintf("Message: %s\n", response.Message)
-		}
+// DojoClient returns a typed DefectDojo client sharing this server's HTTP
+// client and configuration, for Go code in the same process that needs no
+// MCP messages. Its calls bypass the tool middleware: the write policy,
+// approvals, audit trail and notifications do not apply to them.
+func (s *Server) DojoClient() *dojoclient.Client {
+	return dojoclient.FromAPI(s.ddClient)
+}
 
-		return mcp.NewToolResultText(result), nil
-	})
+// Tools returns the tools this server registered, in registration order.
+// Unlike ToolDefinitions, it reflects the configuration: in read-only mode
+// the write tools are missing.
+func (s *Server) Tools() []mcp.Tool {
+	return slices.Clone(s.tools)
 }
@@ -3,18 +3,55 @@ package mcpserver
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/brduru/mcp-defect-dojo/internal/defectdojo"
 	"github.com/brduru/mcp-defect-dojo/pkg/types"
 )
 
 // MockDefectDojoClient implements the defectdojo.Client interface for testing
 type MockDefectDojoClient struct {
-	HealthCheckFunc       func(ctx context.Context) (bool, string)
-	GetFindingsFunc       func(ctx context.Context, filter types.FindingsFilter) (*types.FindingsResponse, error)
-	GetFindingDetailFunc  func(ctx context.Context, findingID int) (*types.Finding, error)
-	MarkFalsePositiveFunc func(ctx context.Context, findingID int, request types.FalsePositiveRequest) (*types.FalsePositiveResponse, error)
+	HealthCheckFunc                 func(ctx context.Context) (bool, string)
+	CheckHealthFunc                 func(ctx context.Context) *types.HealthStatus
+	GetFindingsFunc                 func(ctx context.Context, filter types.FindingsFilter) (*types.FindingsResponse, error)
+	GetFindingDetailFunc            func(ctx context.Context, findingID int) (*types.Finding, error)
+	GetFindingDetailPrefetchFunc    func(ctx context.Context, findingID int, prefetch []string) (*types.FindingDetail, error)
+	MarkFalsePositiveFunc           func(ctx context.Context, findingID int, request types.FalsePositiveRequest) (*types.FalsePositiveResponse, error)
+	ListUsersFunc                   func(ctx context.Context, username string, limit, offset int) (*types.UsersResponse, error)
+	AssignFindingFunc               func(ctx context.Context, findingID int, userIDs []int) (*types.Finding, error)
+	CloseFindingFunc                func(ctx context.Context, findingID int, request types.CloseFindingRequest) (*types.Finding, error)
+	ChangeSeverityFunc              func(ctx context.Context, findingID int, request types.SeverityChangeRequest) (*types.SeverityChangeResponse, error)
+	AddFindingNoteFunc              func(ctx context.Context, findingID int, entry string) (*types.Note, error)
+	GetFindingMetadataFunc          func(ctx context.Context, findingID int) ([]types.FindingMetadata, error)
+	AddFindingMetadataFunc          func(ctx context.Context, findingID int, metadata types.FindingMetadata) (*types.FindingMetadata, error)
+	ImportScanFunc                  func(ctx context.Context, request types.ImportScanRequest) (*types.ImportScanResponse, error)
+	GetTestFunc                     func(ctx context.Context, testID int) (*types.Test, error)
+	GetTestTypeFunc                 func(ctx context.Context, testTypeID int) (*types.TestType, error)
+	GetEngagementFunc               func(ctx context.Context, engagementID int) (*types.Engagement, error)
+	ListEngagementsFunc             func(ctx context.Context, filter types.EngagementsFilter) (*types.EngagementsResponse, error)
+	GetProductFunc                  func(ctx context.Context, productID int) (*types.Product, error)
+	ListProductsFunc                func(ctx context.Context, limit, offset int) (*types.ProductsResponse, error)
+	ListProductTypesFunc            func(ctx context.Context, limit, offset int) (*types.ProductTypesResponse, error)
+	CreateProductFunc               func(ctx context.Context, request types.CreateProductRequest) (*types.Product, error)
+	CreateEngagementFunc            func(ctx context.Context, request types.CreateEngagementRequest) (*types.Engagement, error)
+	ListTestsFunc                   func(ctx context.Context, engagementID, limit, offset int) (*types.TestsResponse, error)
+	ListTestImportsFunc             func(ctx context.Context, testID, limit, offset int) (*types.TestImportsResponse, error)
+	GetSystemSettingsFunc           func(ctx context.Context) (*types.SystemSettings, error)
+	ListSLAConfigurationsFunc       func(ctx context.Context, limit, offset int) (*types.SLAConfigurationsResponse, error)
+	ListDevelopmentEnvironmentsFunc func(ctx context.Context, limit, offset int) (*types.DevelopmentEnvironmentsResponse, error)
+	GetAnnouncementFunc             func(ctx context.Context) (*types.Announcement, error)
+	ListRiskAcceptancesFunc         func(ctx context.Context, limit, offset int) (*types.RiskAcceptancesResponse, error)
+	ListEndpointStatusesFunc        func(ctx context.Context, filter types.EndpointStatusFilter) (*types.EndpointStatusesResponse, error)
+	ListEndpointsFunc               func(ctx context.Context, productID, limit, offset int) (*types.EndpointsResponse, error)
+	ListTechnologiesFunc            func(ctx context.Context, productID, limit, offset int) (*types.TechnologiesResponse, error)
+	VersionValue                    string
+	SupportsFunc                    func(ctx context.Context, feature defectdojo.Feature) error
 }
 
 func (m *MockDefectDojoClient) HealthCheck(ctx context.Context) (bool, string) {
@@ -24,6 +61,18 @@ func (m *MockDefectDojoClient) HealthCheck(ctx context.Context) (bool, string) {
 	return true, "Mock DefectDojo is healthy"
 }
 
+func (m *MockDefectDojoClient) CheckHealth(ctx context.Context) *types.HealthStatus {
+	if m.CheckHealthFunc != nil {
+		return m.CheckHealthFunc(ctx)
+	}
+	healthy, message := m.HealthCheck(ctx)
+	status := &types.HealthStatus{URL: "http://mock", Reachable: healthy, Authenticated: healthy, Version: m.VersionValue}
+	if !healthy {
+		status.Error = message
+	}
+	return status
+}
+
 func (m *MockDefectDojoClient) GetFindings(ctx context.Context, filter types.FindingsFilter) (*types.FindingsResponse, error) {
 	if m.GetFindingsFunc != nil {
 		return m.GetFindingsFunc(ctx, filter)
@@ -68,11 +117,24 @@ func (m *MockDefectDojoClient) GetFindingDetail(ctx context.Context, findingID i
 		Verified:    false,
 		Description: fmt.Sprintf("Detailed description for finding %d", findingID),
 		Test:        100,
-		Created:     "2023-01-01T00:00:00Z",
-		Modified:    "2023-01-02T00:00:00Z",
+		Created:     time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+		Modified:    time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC),
 	}, nil
 }
 
+// GetFindingDetailPrefetch wraps GetFindingDetail without prefetched objects
+// unless GetFindingDetailPrefetchFunc is set
+func (m *MockDefectDojoClient) GetFindingDetailPrefetch(ctx context.Context, findingID int, prefetch []string) (*types.FindingDetail, error) {
+	if m.GetFindingDetailPrefetchFunc != nil {
+		return m.GetFindingDetailPrefetchFunc(ctx, findingID, prefetch)
+	}
+	finding, err := m.GetFindingDetail(ctx, findingID)
+	if err != nil {
+		return nil, err
+	}
+	return &types.FindingDetail{Finding: *finding}, nil
+}
+
 func (m *MockDefectDojoClient) MarkFalsePositive(ctx context.Context, findingID int, request types.FalsePositiveRequest) (*types.FalsePositiveResponse, error) {
 	if m.MarkFalsePositiveFunc != nil {
 		return m.MarkFalsePositiveFunc(ctx, findingID, request)
@@ -89,6 +151,265 @@ func (m *MockDefectDojoClient) MarkFalsePositive(ctx context.Context, findingID
 	}, nil
 }
 
+func (m *MockDefectDojoClient) ListUsers(ctx context.Context, username string, limit, offset int) (*types.UsersResponse, error) {
+	if m.ListUsersFunc != nil {
+		return m.ListUsersFunc(ctx, username, limit, offset)
+	}
+	if username != "" && username != "dana" {
+		return &types.UsersResponse{Results: []types.User{}}, nil
+	}
+	return &types.UsersResponse{Count: 1, Results: []types.User{{ID: 1, Username: "dana", FirstName: "Dana", LastName: "Whitfield", IsActive: true}}}, nil
+}
+
+func (m *MockDefectDojoClient) AssignFinding(ctx context.Context, findingID int, userIDs []int) (*types.Finding, error) {
+	if m.AssignFindingFunc != nil {
+		return m.AssignFindingFunc(ctx, findingID, userIDs)
+	}
+	finding, err := m.GetFindingDetail(ctx, findingID)
+	if err != nil {
+		return nil, err
+	}
+	finding.Reviewers = userIDs
+	return finding, nil
+}
+
+func (m *MockDefectDojoClient) CloseFinding(ctx context.Context, findingID int, request types.CloseFindingRequest) (*types.Finding, error) {
+	if m.CloseFindingFunc != nil {
+		return m.CloseFindingFunc(ctx, findingID, request)
+	}
+	finding, err := m.GetFindingDetail(ctx, findingID)
+	if err != nil {
+		return nil, err
+	}
+	finding.Active, finding.IsMitigated = false, true
+	return finding, nil
+}
+
+func (m *MockDefectDojoClient) ChangeSeverity(ctx context.Context, findingID int, request types.SeverityChangeRequest) (*types.SeverityChangeResponse, error) {
+	if m.ChangeSeverityFunc != nil {
+		return m.ChangeSeverityFunc(ctx, findingID, request)
+	}
+	if findingID == 999 {
+		return nil, fmt.Errorf("finding not found: %d", findingID)
+	}
+	return &types.SeverityChangeResponse{
+		ID:                findingID,
+		PreviousSeverity:  "High",
+		Severity:          request.Severity,
+		NumericalSeverity: types.NumericalSeverity(request.Severity),
+		Justification:     request.Justification,
+		NoteID:            findingID + 1000,
+	}, nil
+}
+
+func (m *MockDefectDojoClient) AddFindingNote(ctx context.Context, findingID int, entry string) (*types.Note, error) {
+	if m.AddFindingNoteFunc != nil {
+		return m.AddFindingNoteFunc(ctx, findingID, entry)
+	}
+	if findingID == 999 {
+		return nil, fmt.Errorf("finding not found: %d", findingID)
+	}
+	return &types.Note{ID: findingID + 1000, Entry: entry}, nil
+}
+
+func (m *MockDefectDojoClient) ImportScan(ctx context.Context, request types.ImportScanRequest) (*types.ImportScanResponse, error) {
+	if m.ImportScanFunc != nil {
+		return m.ImportScanFunc(ctx, request)
+	}
+	return &types.ImportScanResponse{Test: 200, Engagement: request.Engagement, Product: 1}, nil
+}
+
+func (m *MockDefectDojoClient) GetTest(ctx context.Context, testID int) (*types.Test, error) {
+	if m.GetTestFunc != nil {
+		return m.GetTestFunc(ctx, testID)
+	}
+	return &types.Test{ID: testID, Engagement: 10}, nil
+}
+
+func (m *MockDefectDojoClient) GetTestType(ctx context.Context, testTypeID int) (*types.TestType, error) {
+	if m.GetTestTypeFunc != nil {
+		return m.GetTestTypeFunc(ctx, testTypeID)
+	}
+	return &types.TestType{ID: testTypeID, Name: "Mock Scan"}, nil
+}
+
+func (m *MockDefectDojoClient) GetEngagement(ctx context.Context, engagementID int) (*types.Engagement, error) {
+	if m.GetEngagementFunc != nil {
+		return m.GetEngagementFunc(ctx, engagementID)
+	}
+	return &types.Engagement{ID: engagementID, Name: "Mock Engagement", Product: 1}, nil
+}
+
+func (m *MockDefectDojoClient) GetFindingMetadata(ctx context.Context, findingID int) ([]types.FindingMetadata, error) {
+	if m.GetFindingMetadataFunc != nil {
+		return m.GetFindingMetadataFunc(ctx, findingID)
+	}
+	return nil, nil
+}
+
+func (m *MockDefectDojoClient) AddFindingMetadata(ctx context.Context, findingID int, metadata types.FindingMetadata) (*types.FindingMetadata, error) {
+	if m.AddFindingMetadataFunc != nil {
+		return m.AddFindingMetadataFunc(ctx, findingID, metadata)
+	}
+	metadata.ID = 1
+	return &metadata, nil
+}
+
+func (m *MockDefectDojoClient) ListEngagements(ctx context.Context, filter types.EngagementsFilter) (*types.EngagementsResponse, error) {
+	if m.ListEngagementsFunc != nil {
+		return m.ListEngagementsFunc(ctx, filter)
+	}
+	return &types.EngagementsResponse{Results: []types.Engagement{}}, nil
+}
+
+func (m *MockDefectDojoClient) GetProduct(ctx context.Context, productID int) (*types.Product, error) {
+	if m.GetProductFunc != nil {
+		return m.GetProductFunc(ctx, productID)
+	}
+	return &types.Product{ID: productID, Name: "Mock Product"}, nil
+}
+
+func (m *MockDefectDojoClient) ListProducts(ctx context.Context, limit, offset int) (*types.ProductsResponse, error) {
+	if m.ListProductsFunc != nil {
+		return m.ListProductsFunc(ctx, limit, offset)
+	}
+	return &types.ProductsResponse{Count: 1, Results: []types.Product{{ID: 1, Name: "Mock Product"}}}, nil
+}
+
+func (m *MockDefectDojoClient) ListProductTypes(ctx context.Context, limit, offset int) (*types.ProductTypesResponse, error) {
+	if m.ListProductTypesFunc != nil {
+		return m.ListProductTypesFunc(ctx, limit, offset)
+	}
+	return &types.ProductTypesResponse{Count: 1, Results: []types.ProductType{{ID: 1, Name: "Mock Product Type"}}}, nil
+}
+
+func (m *MockDefectDojoClient) CreateProduct(ctx context.Context, request types.CreateProductRequest) (*types.Product, error) {
+	if m.CreateProductFunc != nil {
+		return m.CreateProductFunc(ctx, request)
+	}
+	return &types.Product{ID: 100, Name: request.Name, Description: request.Description, ProductType: request.ProductType, Tags: request.Tags}, nil
+}
+
+func (m *MockDefectDojoClient) CreateEngagement(ctx context.Context, request types.CreateEngagementRequest) (*types.Engagement, error) {
+	if m.CreateEngagementFunc != nil {
+		return m.CreateEngagementFunc(ctx, request)
+	}
+	return &types.Engagement{ID: 200, Name: request.Name, Product: request.Product, Status: request.Status, EngagementType: request.EngagementType, TargetStart: request.TargetStart, TargetEnd: request.TargetEnd, Active: true}, nil
+}
+
+func (m *MockDefectDojoClient) ListTests(ctx context.Context, engagementID, limit, offset int) (*types.TestsResponse, error) {
+	if m.ListTestsFunc != nil {
+		return m.ListTestsFunc(ctx, engagementID, limit, offset)
+	}
+	return &types.TestsResponse{Count: 1, Results: []types.Test{{ID: 1, Title: "Mock Test", Engagement: engagementID}}}, nil
+}
+
+func (m *MockDefectDojoClient) ListTestImports(ctx context.Context, testID, limit, offset int) (*types.TestImportsResponse, error) {
+	if m.ListTestImportsFunc != nil {
+		return m.ListTestImportsFunc(ctx, testID, limit, offset)
+	}
+	return &types.TestImportsResponse{Results: []types.TestImport{}}, nil
+}
+
+func (m *MockDefectDojoClient) GetSystemSettings(ctx context.Context) (*types.SystemSettings, error) {
+	if m.GetSystemSettingsFunc != nil {
+		return m.GetSystemSettingsFunc(ctx)
+	}
+	return &types.SystemSettings{EnableDeduplication: true, EnableFindingSLA: true}, nil
+}
+
+func (m *MockDefectDojoClient) ListSLAConfigurations(ctx context.Context, limit, offset int) (*types.SLAConfigurationsResponse, error) {
+	if m.ListSLAConfigurationsFunc != nil {
+		return m.ListSLAConfigurationsFunc(ctx, limit, offset)
+	}
+	return &types.SLAConfigurationsResponse{Count: 1, Results: []types.SLAConfiguration{{ID: 1, Name: "Default", Critical: 7, High: 30, Medium: 90, Low: 120}}}, nil
+}
+
+func (m *MockDefectDojoClient) ListDevelopmentEnvironments(ctx context.Context, limit, offset int) (*types.DevelopmentEnvironmentsResponse, error) {
+	if m.ListDevelopmentEnvironmentsFunc != nil {
+		return m.ListDevelopmentEnvironmentsFunc(ctx, limit, offset)
+	}
+	return &types.DevelopmentEnvironmentsResponse{Count: 3, Results: []types.DevelopmentEnvironment{{ID: 1, Name: "Development"}, {ID: 2, Name: "Staging"}, {ID: 3, Name: "Production"}}}, nil
+}
+
+func (m *MockDefectDojoClient) GetAnnouncement(ctx context.Context) (*types.Announcement, error) {
+	if m.GetAnnouncementFunc != nil {
+		return m.GetAnnouncementFunc(ctx)
+	}
+	return nil, nil
+}
+
+func (m *MockDefectDojoClient) ListRiskAcceptances(ctx context.Context, limit, offset int) (*types.RiskAcceptancesResponse, error) {
+	if m.ListRiskAcceptancesFunc != nil {
+		return m.ListRiskAcceptancesFunc(ctx, limit, offset)
+	}
+	return &types.RiskAcceptancesResponse{Results: []types.RiskAcceptance{}}, nil
+}
+
+func (m *MockDefectDojoClient) ListEndpointStatuses(ctx context.Context, filter types.EndpointStatusFilter) (*types.EndpointStatusesResponse, error) {
+	if m.ListEndpointStatusesFunc != nil {
+		return m.ListEndpointStatusesFunc(ctx, filter)
+	}
+	return &types.EndpointStatusesResponse{Results: []types.EndpointStatus{}}, nil
+}
+
+func (m *MockDefectDojoClient) ListEndpoints(ctx context.Context, productID, limit, offset int) (*types.EndpointsResponse, error) {
+	if m.ListEndpointsFunc != nil {
+		return m.ListEndpointsFunc(ctx, productID, limit, offset)
+	}
+	return &types.EndpointsResponse{Results: []types.Endpoint{}}, nil
+}
+
+func (m *MockDefectDojoClient) ListTechnologies(ctx context.Context, productID, limit, offset int) (*types.TechnologiesResponse, error) {
+	if m.ListTechnologiesFunc != nil {
+		return m.ListTechnologiesFunc(ctx, productID, limit, offset)
+	}
+	return &types.TechnologiesResponse{Results: []types.Technology{}}, nil
+}
+
+func (m *MockDefectDojoClient) Version(ctx context.Context) string {
+	return m.VersionValue
+}
+
+func (m *MockDefectDojoClient) Supports(ctx context.Context, feature defectdojo.Feature) error {
+	if m.SupportsFunc != nil {
+		return m.SupportsFunc(ctx, feature)
+	}
+	return nil
+}
+
+// callTool invokes a tool on the server through an in-process MCP client
+func callTool(t *testing.T, s *Server, name string, args map[string]any) (*mcp.CallToolResult, error) {
+	t.Helper()
+	return callToolWithContext(t, context.Background(), s, name, args)
+}
+
+// callToolWithContext is like callTool but passes ctx through to the tool handler
+func callToolWithContext(t *testing.T, ctx context.Context, s *Server, name string, args map[string]any) (*mcp.CallToolResult, error) {
+	t.Helper()
+
+	mcpClient, err := s.NewInProcessClient(ctx)
+	if err != nil {
+		t.Fatalf("NewInProcessClient() error = %v", err)
+	}
+	defer mcpClient.Close()
+
+	return mcpClient.CallTool(ctx, mcp.CallToolRequest{
+		Params: mcp.CallToolParams{Name: name, Arguments: args},
+	})
+}
+
+// resultText returns the concatenated text content of a tool result
+func resultText(result *mcp.CallToolResult) string {
+	var text string
+	for _, content := range result.Content {
+		if tc, ok := content.(mcp.TextContent); ok {
+			text += tc.Text
+		}
+	}
+	return text
+}
+
 // Test configuration creation and validation
 func TestNewServer(t *testing.T) {
 	tests := []struct {
@@ -253,6 +574,39 @@ func TestNewServerWithSettings(t *testing.T) {
 	}
 }
 
+// Test that unusable offline fixtures are reported instead of replaced by demo data
+func TestNewServerWithConfigBadFixtures(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "findings.json"), []byte("{not json"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	server, err := NewServerWithConfig(&Config{
+		DefectDojo: DefectDojoConfig{Mode: "offline", FixturesDir: dir},
+	})
+	if err == nil || server != nil {
+		t.Fatalf("NewServerWithConfig() = %v, %v, want an error", server != nil, err)
+	}
+	if !strings.Contains(err.Error(), "offline fixtures unusable") {
+		t.Errorf("error = %v, want it to mention the fixtures", err)
+	}
+}
+
+// Test that NewServer reports unusable offline fixtures through its tools instead of panicking
+func TestNewServerBadFixtures(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "findings.json"), []byte("{not json"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	server := NewServer(&Config{DefectDojo: DefectDojoConfig{Mode: "offline", FixturesDir: dir}})
+	for _, tool := range []string{"get_defectdojo_findings", "defectdojo_health_check"} {
+		if _, err := callTool(t, server, tool, map[string]any{}); err == nil || !strings.Contains(err.Error(), "offline fixtures unusable") {
+			t.Errorf("%s: expected the fixture error, got %v", tool, err)
+		}
+	}
+}
+
 // Test configuration validation
 func TestConfigValidation(t *testing.T) {
 	tests := []struct {
@@ -686,3 +1040,55 @@ func TestAPIVersionHandling(t *testing.T) {
 		})
 	}
 }
+
+func TestTools(t *testing.T) {
+	tools := newServer(&Config{}, &MockDefectDojoClient{}).Tools()
+	definitions := ToolDefinitions()
+	if len(tools) != len(definitions) || tools[0].Name != definitions[0].Name {
+		t.Errorf("expected the %d defined tools in order, got %d", len(definitions), len(tools))
+	}
+
+	readOnly := newServer(&Config{Server: ServerConfig{ReadOnly: true}}, &MockDefectDojoClient{}).Tools()
+	if len(readOnly) != len(definitions)-len(writeTools) {
+		t.Errorf("expected %d tools in read-only mode, got %d", len(definitions)-len(writeTools), len(readOnly))
+	}
+	for _, tool := range readOnly {
+		if writeTools[tool.Name] {
+			t.Errorf("write tool %s listed in read-only mode", tool.Name)
+		}
+	}
+}
+
+func TestNewInProcessClient(t *testing.T) {
+	s := newServer(&Config{Server: ServerConfig{Name: "test-server", Version: "1.0.0", ReadOnly: true}}, &MockDefectDojoClient{})
+	ctx := context.Background()
+	mcpClient, err := s.NewInProcessClient(ctx)
+	if err != nil {
+		t.Fatalf("NewInProcessClient() error = %v", err)
+	}
+	defer mcpClient.Close()
+
+	if !mcpClient.IsInitialized() || mcpClient.GetServerCapabilities().Tools == nil {
+		t.Error("expected an initialized client of a server with tools")
+	}
+	tools, err := mcpClient.ListTools(ctx, mcp.ListToolsRequest{})
+	if err != nil {
+		t.Fatalf("ListTools() error = %v", err)
+	}
+	if len(tools.Tools) != len(s.Tools()) {
+		t.Errorf("expected the %d registered tools, got %d", len(s.Tools()), len(tools.Tools))
+	}
+}
+
+func TestDojoClient(t *testing.T) {
+	var closed int
+	s := newServer(&Config{}, &MockDefectDojoClient{
+		CloseFindingFunc: func(ctx context.Context, findingID int, request types.CloseFindingRequest) (*types.Finding, error) {
+			closed = findingID
+			return &types.Finding{ID: findingID, IsMitigated: true}, nil
+		},
+	})
+	if _, err := s.DojoClient().CloseFinding(context.Background(), 12, "Fixed"); err != nil || closed != 12 {
+		t.Errorf("expected the facade to call the server's DefectDojo client, got %d, %v", closed, err)
+	}
+}
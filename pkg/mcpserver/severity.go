@@ -0,0 +1,65 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+// changeFindingSeverity handles change_finding_severity
+func (s *Server) changeFindingSeverity(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	findingID, err := request.RequireInt("finding_id")
+	if err != nil {
+		return nil, fmt.Errorf("invalid finding_id: %w", err)
+	}
+	if _, err := request.RequireString("severity"); err != nil {
+		return nil, fmt.Errorf("invalid severity: %w", err)
+	}
+	severity, err := s.severityArgument(request, "severity")
+	if err != nil {
+		return nil, err
+	}
+	justification, err := request.RequireString("justification")
+	if err != nil {
+		return nil, fmt.Errorf("invalid justification: %w", err)
+	}
+	if strings.TrimSpace(justification) == "" {
+		return nil, fmt.Errorf("invalid justification: explain why the severity changes")
+	}
+	ifUnmodifiedSince, err := optionalTimestamp(request, "if_unmodified_since")
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := s.ddClient.ChangeSeverity(ctx, findingID, types.SeverityChangeRequest{
+		Severity:          severity,
+		Justification:     justification,
+		IfUnmodifiedSince: ifUnmodifiedSince,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error changing severity of finding %d: %w", findingID, err)
+	}
+
+	result := fmt.Sprintf("Changed severity of finding %d from %s to %s", response.ID, s.severity.display(response.PreviousSeverity), s.severity.display(response.Severity))
+	switch types.CompareSeverity(response.Severity, response.PreviousSeverity) {
+	case -1:
+		result += " (downgrade)"
+	case 1:
+		result += " (upgrade)"
+	default:
+		result += " (unchanged)"
+	}
+	result += "\n\n"
+	result += fmt.Sprintf("Justification: %s\n", response.Justification)
+	if response.NoteID != 0 {
+		result += fmt.Sprintf("Recorded as note ID: %d\n", response.NoteID)
+	}
+	if link := s.links.finding(response.ID); link != "" {
+		result += link + "\n"
+	}
+	return mcp.NewToolResultText(result), nil
+}
@@ -0,0 +1,141 @@
+package mcpserver
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/brduru/mcp-defect-dojo/internal/defectdojo"
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+func TestChangeFindingSeverity(t *testing.T) {
+	fixtures, err := defectdojo.NewFixtureClient("")
+	if err != nil {
+		t.Fatalf("NewFixtureClient() error = %v", err)
+	}
+	s := newServer(&Config{}, fixtures)
+
+	result, err := callTool(t, s, "change_finding_severity", map[string]any{"finding_id": 2, "severity": "medium", "justification": "Only reachable by administrators"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text := resultText(result)
+	for _, want := range []string{"Changed severity of finding 2 from High to Medium (downgrade)", "Justification: Only reachable by administrators", "Recorded as note ID:"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in output, got: %s", want, text)
+		}
+	}
+
+	finding, err := fixtures.GetFindingDetailPrefetch(context.Background(), 2, []string{types.PrefetchNotes})
+	if err != nil {
+		t.Fatalf("GetFindingDetailPrefetch() error = %v", err)
+	}
+	if finding.Severity != "Medium" || finding.NumericalSeverity != "S2" {
+		t.Errorf("expected finding 2 to be Medium (S2), got %s (%s)", finding.Severity, finding.NumericalSeverity)
+	}
+	var noted bool
+	for _, note := range finding.Prefetch.Notes {
+		noted = noted || note.Entry == "Severity changed from High to Medium: Only reachable by administrators"
+	}
+	if !noted {
+		t.Errorf("expected the justification note, got %+v", finding.Prefetch.Notes)
+	}
+
+	result, err = callTool(t, s, "change_finding_severity", map[string]any{"finding_id": 5, "severity": "High", "justification": "Login page is public"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := resultText(result); !strings.Contains(text, "from Low to High (upgrade)") {
+		t.Errorf("expected an upgrade, got: %s", text)
+	}
+}
+
+func TestChangeFindingSeverityErrors(t *testing.T) {
+	s := newServer(&Config{}, &MockDefectDojoClient{
+		ChangeSeverityFunc: func(ctx context.Context, findingID int, request types.SeverityChangeRequest) (*types.SeverityChangeResponse, error) {
+			return nil, &defectdojo.ConflictError{FindingID: findingID}
+		},
+	})
+
+	tests := []struct {
+		name string
+		args map[string]any
+		want string
+	}{
+		{"unknown severity", map[string]any{"finding_id": 1, "severity": "Urgent", "justification": "x"}, `severity must be one of Info, Low, Medium, High, Critical, got "Urgent"`},
+		{"missing severity", map[string]any{"finding_id": 1, "justification": "x"}, `missing required argument "severity"`},
+		{"blank justification", map[string]any{"finding_id": 1, "severity": "Low", "justification": "  "}, "invalid justification"},
+		{"conflict", map[string]any{"finding_id": 1, "severity": "Low", "justification": "x"}, "error changing severity of finding 1: conflict"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := callTool(t, s, "change_finding_severity", tt.args)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestDowngradeApproval(t *testing.T) {
+	var changed []string
+	mock := &MockDefectDojoClient{
+		GetFindingDetailFunc: func(ctx context.Context, findingID int) (*types.Finding, error) {
+			if findingID == 999 {
+				return nil, errors.New("connection refused")
+			}
+			severity := map[int]string{1: "Critical", 2: "High"}[findingID]
+			return &types.Finding{ID: findingID, Severity: severity}, nil
+		},
+		ChangeSeverityFunc: func(ctx context.Context, findingID int, request types.SeverityChangeRequest) (*types.SeverityChangeResponse, error) {
+			changed = append(changed, request.Severity)
+			return &types.SeverityChangeResponse{ID: findingID, Severity: request.Severity}, nil
+		},
+	}
+	s := newServer(&Config{Policy: PolicyConfig{Rules: &WritePolicy{DowngradeApproval: []string{"Critical"}}}}, mock)
+	change := func(id int, severity string) (string, error) {
+		result, err := callTool(t, s, "change_finding_severity", map[string]any{"finding_id": id, "severity": severity, "justification": "Compensating control"})
+		if err != nil {
+			return "", err
+		}
+		return resultText(result), nil
+	}
+
+	// Upgrades and changes to unlisted severities apply right away
+	for _, call := range []struct {
+		id       int
+		severity string
+	}{{2, "Low"}, {1, "Critical"}} {
+		if text, err := change(call.id, call.severity); err != nil || !strings.Contains(text, "Changed severity") {
+			t.Errorf("expected finding %d to change to %s, got %q, %v", call.id, call.severity, text, err)
+		}
+	}
+
+	text, err := change(1, "Medium")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(text, "pending action #1 (change_finding_severity) because the write policy (downgrade_approval) requires human approval to downgrade Critical findings") {
+		t.Errorf("expected the downgrade to be queued, got: %s", text)
+	}
+	if len(changed) != 2 {
+		t.Fatalf("expected only the first two changes applied, got %v", changed)
+	}
+
+	action, err := s.ApproveAction(context.Background(), 1)
+	if err != nil || action.Status != ActionApproved {
+		t.Fatalf("ApproveAction() = %+v, %v", action, err)
+	}
+	if len(changed) != 3 || changed[2] != "Medium" {
+		t.Errorf("expected the approved downgrade to be applied, got %v", changed)
+	}
+
+	if _, err := change(999, "Low"); err == nil || !strings.Contains(err.Error(), "policy check failed for finding 999") {
+		t.Errorf("expected an unreadable finding to be refused, got %v", err)
+	}
+	if _, err := callTool(t, s, "mark_finding_false_positive", map[string]any{"finding_id": 1, "justification": "Test data"}); err != nil {
+		t.Errorf("expected other writes to bypass the queue, got %v", err)
+	}
+}
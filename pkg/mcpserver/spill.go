@@ -0,0 +1,153 @@
+package mcpserver
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// defaultExportMemory is the memory budget of export chunks unless configured
+const defaultExportMemory = 32 << 20
+
+// exportBuffer keeps encoded export chunks in memory up to a byte budget
+// shared by every session, and writes the chunks beyond it to temporary
+// files, so a memory-limited deployment can still export a large instance.
+type exportBuffer struct {
+	dir    string // Directory of spill files ("" = the system temporary directory)
+	budget int64  // Bytes of chunks kept in memory across all exports
+
+	mu   sync.Mutex
+	used int64
+}
+
+// spooledChunks are the chunks of one export, each either in memory or in
+// the export's spill file
+type spooledChunks struct {
+	buffer *exportBuffer
+
+	mu      sync.Mutex
+	chunks  []spooledChunk
+	file    *os.File // Created when the first chunk spills
+	written int64    // Bytes written to file
+	held    int64    // Bytes reserved from the buffer's budget
+	closed  bool
+}
+
+// spooledChunk is an in-memory chunk, or where a spilled chunk is in the file
+type spooledChunk struct {
+	data   []byte // nil when spilled
+	offset int64
+	length int64
+}
+
+func newExportBuffer(dir string, budget int64) *exportBuffer {
+	return &exportBuffer{dir: dir, budget: budget}
+}
+
+// reserve takes n bytes of the memory budget, reporting false when they do not fit
+func (b *exportBuffer) reserve(n int64) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.used+n > b.budget {
+		return false
+	}
+	b.used += n
+	return true
+}
+
+// release returns n bytes to the memory budget
+func (b *exportBuffer) release(n int64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.used -= n
+}
+
+// spool starts the chunks of a new export
+func (b *exportBuffer) spool() *spooledChunks {
+	return &spooledChunks{buffer: b}
+}
+
+// add appends a chunk, keeping it in memory while the budget allows
+func (c *spooledChunks) add(data []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return errors.New("export was discarded")
+	}
+	size := int64(len(data))
+	if c.buffer.reserve(size) {
+		c.held += size
+		c.chunks = append(c.chunks, spooledChunk{data: data, length: size})
+		return nil
+	}
+	if c.file == nil {
+		file, err := os.CreateTemp(c.buffer.dir, "mcp-defectdojo-export-*.json")
+		if err != nil {
+			return fmt.Errorf("spilling export to disk: %w", err)
+		}
+		c.file = file
+	}
+	if _, err := c.file.WriteAt(data, c.written); err != nil {
+		return fmt.Errorf("spilling export to disk: %w", err)
+	}
+	c.chunks = append(c.chunks, spooledChunk{offset: c.written, length: size})
+	c.written += size
+	return nil
+}
+
+// len returns the number of chunks
+func (c *spooledChunks) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.chunks)
+}
+
+// spilled returns the number of chunks kept on disk
+func (c *spooledChunks) spilled() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var n int
+	for _, chunk := range c.chunks {
+		if chunk.data == nil {
+			n++
+		}
+	}
+	return n
+}
+
+// read returns chunk i, counted from 0
+func (c *spooledChunks) read(i int) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil, errors.New("export was discarded")
+	}
+	chunk := c.chunks[i]
+	if chunk.data != nil {
+		return chunk.data, nil
+	}
+	data := make([]byte, chunk.length)
+	if _, err := c.file.ReadAt(data, chunk.offset); err != nil {
+		return nil, fmt.Errorf("reading spilled export chunk: %w", err)
+	}
+	return data, nil
+}
+
+// close frees the chunks' memory and removes their spill file. Closing
+// twice does nothing.
+func (c *spooledChunks) close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	c.chunks = nil
+	c.buffer.release(c.held)
+	c.held = 0
+	if c.file == nil {
+		return nil
+	}
+	return errors.Join(c.file.Close(), os.Remove(c.file.Name()))
+}
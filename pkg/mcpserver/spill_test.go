@@ -0,0 +1,56 @@
+package mcpserver
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSpooledChunks(t *testing.T) {
+	dir := t.TempDir()
+	buffer := newExportBuffer(dir, 10)
+	first, second := buffer.spool(), buffer.spool()
+
+	for _, chunk := range []string{"[1,2,3]", "[4,5,6]", "[7,8,9]"} {
+		if err := first.add([]byte(chunk)); err != nil {
+			t.Fatalf("add() error = %v", err)
+		}
+	}
+	if first.len() != 3 || first.spilled() != 2 {
+		t.Errorf("expected 1 chunk in memory and 2 on disk, got %d chunks, %d spilled", first.len(), first.spilled())
+	}
+	for i, want := range []string{"[1,2,3]", "[4,5,6]", "[7,8,9]"} {
+		if data, err := first.read(i); err != nil || string(data) != want {
+			t.Errorf("read(%d) = %q, %v, want %q", i, data, err, want)
+		}
+	}
+
+	// The budget is shared until an export is closed
+	second.add([]byte("[8]"))
+	if second.spilled() != 0 {
+		t.Error("expected the 3 bytes left in the budget to hold the chunk")
+	}
+	second.add([]byte("[9]"))
+	if second.spilled() != 1 {
+		t.Error("expected the exhausted budget to spill the chunk")
+	}
+	if err := first.close(); err != nil {
+		t.Fatalf("close() error = %v", err)
+	}
+	if files, _ := filepath.Glob(filepath.Join(dir, "*")); len(files) != 1 {
+		t.Errorf("expected only the second export's spill file to remain, got %v", files)
+	}
+	if _, err := first.read(0); err == nil {
+		t.Error("expected a closed export to be unreadable")
+	}
+	third := buffer.spool()
+	third.add([]byte("[1,2,3]"))
+	if third.spilled() != 0 {
+		t.Error("expected closing an export to return its memory to the budget")
+	}
+
+	second.close()
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("expected no spill files left, got %v", entries)
+	}
+}
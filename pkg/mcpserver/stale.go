@@ -0,0 +1,148 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+// Stale findings sizing
+const (
+	defaultStaleDays      = 30
+	maxStaleDays          = 3650
+	defaultStalePerBucket = 10
+	maxStalePerBucket     = 50
+	staleFindingsOrdering = "numerical_severity,date" // Most severe first, then oldest
+)
+
+// staleBucketEdges are the ages in days where the age buckets start
+var staleBucketEdges = []int{30, 60, 90, 180}
+
+// staleBucket is one age bucket of get_stale_findings
+type staleBucket struct {
+	Label    string        `json:"label"`              // e.g. "60-89 days" or "180+ days"
+	MinDays  int           `json:"min_days"`           // Youngest age in the bucket
+	MaxDays  int           `json:"max_days,omitempty"` // Oldest age in the bucket; absent for the last one
+	Count    int           `json:"count"`              // Active findings of this age
+	Findings []jsonFinding `json:"findings"`           // The most severe of them, oldest first within a severity
+}
+
+// staleBuckets splits ages of at least days into buckets at the standard
+// edges, e.g. 45 gives 45-59, 60-89, 90-179 and 180+
+func staleBuckets(days int) []staleBucket {
+	starts := []int{days}
+	for _, edge := range staleBucketEdges {
+		if edge > days {
+			starts = append(starts, edge)
+		}
+	}
+	buckets := make([]staleBucket, len(starts))
+	for i, start := range starts {
+		buckets[i] = staleBucket{MinDays: start, Label: fmt.Sprintf("%d+ days", start), Findings: []jsonFinding{}}
+		if i+1 < len(starts) {
+			buckets[i].MaxDays = starts[i+1] - 1
+			buckets[i].Label = fmt.Sprintf("%d-%d days", start, buckets[i].MaxDays)
+		}
+	}
+	return buckets
+}
+
+// fillStaleBucket counts the active findings whose age falls in the bucket and
+// lists the most severe of them. DefectDojo compares discovery dates
+// exclusively, so a finding discovered on today-MinDays is included.
+func (s *Server) fillStaleBucket(ctx context.Context, bucket *staleBucket, base types.FindingsFilter, today time.Time, limit int) error {
+	filter := base
+	filter.DiscoveredBefore = today.AddDate(0, 0, -bucket.MinDays+1)
+	if bucket.MaxDays > 0 {
+		filter.DiscoveredAfter = today.AddDate(0, 0, -bucket.MaxDays-1)
+	}
+	filter.Ordering, filter.Limit = staleFindingsOrdering, limit
+	response, err := s.findingsPage(ctx, filter)
+	if err != nil {
+		return err
+	}
+	bucket.Count = response.Count
+	bucket.Findings = append(bucket.Findings, jsonFindings(response.Results, s.links, s.severity)...)
+	return nil
+}
+
+// getStaleFindings handles get_stale_findings
+func (s *Server) getStaleFindings(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	days := request.GetInt("days", defaultStaleDays)
+	if days < 1 || days > maxStaleDays {
+		return nil, fmt.Errorf("invalid days %d: must be between 1 and %d", days, maxStaleDays)
+	}
+	limit := request.GetInt("max_per_bucket", defaultStalePerBucket)
+	if limit < 1 || limit > maxStalePerBucket {
+		return nil, fmt.Errorf("invalid max_per_bucket %d: must be between 1 and %d", limit, maxStalePerBucket)
+	}
+	severity, err := s.severityArgument(request, "severity")
+	if err != nil {
+		return nil, err
+	}
+	active := true
+	base := types.FindingsFilter{Active: &active, Severity: severity}
+	if id := request.GetInt("product", 0); id > 0 {
+		base.Product = &id
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	buckets := staleBuckets(days)
+	total := 0
+	for i := range buckets {
+		if err := s.fillStaleBucket(ctx, &buckets[i], base, today, limit); err != nil {
+			return nil, fmt.Errorf("error retrieving findings open %s: %w", buckets[i].Label, err)
+		}
+		total += buckets[i].Count
+	}
+
+	if s.outputFormat(request) == formatJSON {
+		output, err := marshalOutput(struct {
+			Days    int           `json:"days"`
+			AsOf    string        `json:"as_of"`
+			Total   int           `json:"total"`
+			Buckets []staleBucket `json:"buckets"`
+		}{days, today.Format(time.DateOnly), total, buckets})
+		if err != nil {
+			return nil, err
+		}
+		return mcp.NewToolResultText(output), nil
+	}
+	return mcp.NewToolResultText(s.formatStaleFindings(buckets, days, severity, total)), nil
+}
+
+// formatStaleFindings renders the buckets, oldest last, with their most severe findings
+func (s *Server) formatStaleFindings(buckets []staleBucket, days int, severity string, total int) string {
+	scope := "active findings"
+	if severity != "" {
+		scope = fmt.Sprintf("active %s findings", s.severity.display(severity))
+	}
+	if total == 0 {
+		return fmt.Sprintf("No %s open for %d days or more\n", scope, days)
+	}
+
+	var result strings.Builder
+	fmt.Fprintf(&result, "%d %s open for %d days or more\n", total, scope, days)
+	for _, bucket := range buckets {
+		fmt.Fprintf(&result, "\n%s: %d\n", bucket.Label, bucket.Count)
+		for _, finding := range bucket.Findings {
+			fmt.Fprintf(&result, "  - [%s] %s (ID: %d)", s.severity.display(finding.Severity), finding.Title, finding.ID)
+			if age, ok := ageDays(&finding.Finding); ok {
+				fmt.Fprintf(&result, ", open %d days", age)
+			}
+			if finding.URL != "" {
+				fmt.Fprintf(&result, " — %s", finding.URL)
+			}
+			result.WriteString("\n")
+		}
+		if more := bucket.Count - len(bucket.Findings); more > 0 {
+			fmt.Fprintf(&result, "  ... and %d more\n", more)
+		}
+	}
+	return result.String()
+}
@@ -0,0 +1,91 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+func TestStaleBuckets(t *testing.T) {
+	tests := []struct {
+		days int
+		want []string
+	}{
+		{30, []string{"30-59 days", "60-89 days", "90-179 days", "180+ days"}},
+		{45, []string{"45-59 days", "60-89 days", "90-179 days", "180+ days"}},
+		{7, []string{"7-29 days", "30-59 days", "60-89 days", "90-179 days", "180+ days"}},
+		{365, []string{"365+ days"}},
+	}
+	for _, tt := range tests {
+		buckets := staleBuckets(tt.days)
+		var labels []string
+		for _, bucket := range buckets {
+			labels = append(labels, bucket.Label)
+		}
+		if strings.Join(labels, ", ") != strings.Join(tt.want, ", ") {
+			t.Errorf("staleBuckets(%d) = %v, want %v", tt.days, labels, tt.want)
+		}
+	}
+}
+
+func TestGetStaleFindings(t *testing.T) {
+	var mu sync.Mutex
+	var filters []types.FindingsFilter
+	mock := &MockDefectDojoClient{
+		GetFindingsFunc: func(ctx context.Context, filter types.FindingsFilter) (*types.FindingsResponse, error) {
+			mu.Lock()
+			filters = append(filters, filter)
+			mu.Unlock()
+			if filter.DiscoveredAfter.IsZero() {
+				age := 400
+				return &types.FindingsResponse{Count: 12, Results: []types.Finding{{ID: 9, Title: "Default credentials", Severity: types.SeverityCritical, Active: true, AgeDays: &age}}}, nil
+			}
+			return &types.FindingsResponse{Count: 0, Results: []types.Finding{}}, nil
+		},
+	}
+	s := newServer(&Config{}, mock)
+
+	result, err := callTool(t, s, toolStaleFindings, map[string]any{"days": 60, "product": 3, "max_per_bucket": 5})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text := resultText(result)
+	for _, want := range []string{"12 active findings open for 60 days or more", "60-89 days: 0", "180+ days: 12", "[Critical] Default credentials (ID: 9), open 400 days", "... and 11 more"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in:\n%s", want, text)
+		}
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	if len(filters) != 3 {
+		t.Fatalf("expected one query per bucket, got %d", len(filters))
+	}
+	first := filters[0]
+	if !first.DiscoveredBefore.Equal(today.AddDate(0, 0, -59)) || !first.DiscoveredAfter.Equal(today.AddDate(0, 0, -90)) {
+		t.Errorf("60-89 days bucket queried discovered between %v and %v", first.DiscoveredAfter, first.DiscoveredBefore)
+	}
+	if *first.Product != 3 || !*first.Active || first.Limit != 5 || first.Ordering != staleFindingsOrdering {
+		t.Errorf("unexpected bucket filter %+v", first)
+	}
+
+	result, _ = callTool(t, s, toolStaleFindings, map[string]any{"format": "json"})
+	var output struct {
+		Total   int           `json:"total"`
+		Buckets []staleBucket `json:"buckets"`
+	}
+	if err := json.Unmarshal([]byte(resultText(result)), &output); err != nil {
+		t.Fatalf("invalid JSON output: %v", err)
+	}
+	if output.Total != 12 || len(output.Buckets) != 4 || output.Buckets[3].MinDays != 180 || output.Buckets[3].MaxDays != 0 || output.Buckets[0].MaxDays != 59 {
+		t.Errorf("unexpected JSON output %+v", output)
+	}
+
+	if _, err := callTool(t, s, toolStaleFindings, map[string]any{"days": 0}); err == nil {
+		t.Error("expected an error for days below 1")
+	}
+}
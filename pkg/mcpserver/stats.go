@@ -0,0 +1,260 @@
+package mcpserver
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/brduru/mcp-defect-dojo/internal/defectdojo"
+)
+
+// latencySamples is how many recent call latencies are kept per tool for the median
+const latencySamples = 512
+
+// toolStats counts tool calls in memory since the server started
+type toolStats struct {
+	started time.Time
+
+	mu    sync.Mutex
+	tools map[string]*toolUsage
+}
+
+// toolUsage is the running tally of one tool
+type toolUsage struct {
+	calls     int
+	errors    int
+	total     time.Duration   // Summed latency of every call
+	latencies []time.Duration // Ring of the most recent latencies
+	next      int             // Ring position of the next sample
+	bytes     int64           // Summed result size
+	maxBytes  int             // Largest result
+	summaries int             // Oversized results replaced by a summary
+}
+
+// toolUsageStats is one tool's entry in get_server_stats and /metrics
+type toolUsageStats struct {
+	Tool            string  `json:"tool"`
+	Calls           int     `json:"calls"`
+	Errors          int     `json:"errors"`
+	ErrorRate       float64 `json:"error_rate"`        // errors / calls
+	MedianLatencyMS float64 `json:"median_latency_ms"` // Over the most recent calls
+	TotalLatencyMS  float64 `json:"total_latency_ms"`
+	ResultBytes     int64   `json:"result_bytes"`     // Summed size of the results returned
+	MaxResultBytes  int     `json:"max_result_bytes"` // Largest result returned
+	Summarized      int     `json:"summarized"`       // Results summarized for being oversized
+}
+
+// newToolStats starts counting from now
+func newToolStats() *toolStats {
+	return &toolStats{started: time.Now(), tools: map[string]*toolUsage{}}
+}
+
+// usage returns the tally of a tool, starting it on first use; the caller holds s.mu
+func (s *toolStats) usage(tool string) *toolUsage {
+	usage, ok := s.tools[tool]
+	if !ok {
+		usage = &toolUsage{}
+		s.tools[tool] = usage
+	}
+	return usage
+}
+
+// record counts one completed tool call and the size of its result
+func (s *toolStats) record(tool string, latency time.Duration, size int, failed bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	usage := s.usage(tool)
+	usage.calls++
+	usage.bytes += int64(size)
+	usage.maxBytes = max(usage.maxBytes, size)
+	if failed {
+		usage.errors++
+	}
+	usage.total += latency
+	if len(usage.latencies) < latencySamples {
+		usage.latencies = append(usage.latencies, latency)
+	} else {
+		usage.latencies[usage.next] = latency
+		usage.next = (usage.next + 1) % latencySamples
+	}
+}
+
+// recordSummarized counts an oversized result replaced by a summary
+func (s *toolStats) recordSummarized(tool string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.usage(tool).summaries++
+}
+
+// snapshot returns the statistics of every called tool, most called first
+func (s *toolStats) snapshot() []toolUsageStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make([]toolUsageStats, 0, len(s.tools))
+	for tool, usage := range s.tools {
+		if usage.calls == 0 {
+			continue // Summarized, but the call itself is still being recorded
+		}
+		sorted := slices.Clone(usage.latencies)
+		slices.Sort(sorted)
+		median := sorted[len(sorted)/2]
+		if len(sorted)%2 == 0 {
+			median = (sorted[len(sorted)/2-1] + median) / 2
+		}
+		result = append(result, toolUsageStats{
+			Tool:            tool,
+			Calls:           usage.calls,
+			Errors:          usage.errors,
+			ErrorRate:       float64(usage.errors) / float64(usage.calls),
+			MedianLatencyMS: milliseconds(median),
+			TotalLatencyMS:  milliseconds(usage.total),
+			ResultBytes:     usage.bytes,
+			MaxResultBytes:  usage.maxBytes,
+			Summarized:      usage.summaries,
+		})
+	}
+	slices.SortFunc(result, func(a, b toolUsageStats) int {
+		return cmp.Or(cmp.Compare(b.Calls, a.Calls), strings.Compare(a.Tool, b.Tool))
+	})
+	return result
+}
+
+// writeMetrics writes the tool statistics in the Prometheus text exposition format
+func (s *toolStats) writeMetrics(w io.Writer) {
+	usage := s.snapshot()
+	metrics := []struct {
+		name, help, kind string
+		value            func(toolUsageStats) float64
+	}{
+		{"mcp_tool_calls_total", "Tool calls since the server started.", "counter", func(u toolUsageStats) float64 { return float64(u.Calls) }},
+		{"mcp_tool_errors_total", "Tool calls that failed since the server started.", "counter", func(u toolUsageStats) float64 { return float64(u.Errors) }},
+		{"mcp_tool_latency_seconds_total", "Summed tool call latency.", "counter", func(u toolUsageStats) float64 { return u.TotalLatencyMS / 1000 }},
+		{"mcp_tool_median_latency_seconds", "Median latency of recent tool calls.", "gauge", func(u toolUsageStats) float64 { return u.MedianLatencyMS / 1000 }},
+		{"mcp_tool_result_bytes_total", "Summed size of tool results returned.", "counter", func(u toolUsageStats) float64 { return float64(u.ResultBytes) }},
+		{"mcp_tool_max_result_bytes", "Largest tool result returned.", "gauge", func(u toolUsageStats) float64 { return float64(u.MaxResultBytes) }},
+		{"mcp_tool_summarized_results_total", "Oversized tool results replaced by a summary.", "counter", func(u toolUsageStats) float64 { return float64(u.Summarized) }},
+	}
+	for _, metric := range metrics {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", metric.name, metric.help, metric.name, metric.kind)
+		for _, tool := range usage {
+			fmt.Fprintf(w, "%s{tool=%q} %g\n", metric.name, tool.Tool, metric.value(tool))
+		}
+	}
+	fmt.Fprintf(w, "# HELP mcp_server_start_time_seconds Server start time since the Unix epoch.\n# TYPE mcp_server_start_time_seconds gauge\n")
+	fmt.Fprintf(w, "mcp_server_start_time_seconds %d\n", s.started.Unix())
+}
+
+// transferStats returns the DefectDojo client's response transfer
+// measurements, if the client keeps any
+func (s *Server) transferStats() (defectdojo.TransferStats, bool) {
+	measured, ok := s.ddClient.(interface {
+		TransferStats() defectdojo.TransferStats
+	})
+	if !ok {
+		return defectdojo.TransferStats{}, false
+	}
+	return measured.TransferStats(), true
+}
+
+// writeTransferMetrics writes the DefectDojo transfer measurements in the
+// Prometheus text exposition format
+func (s *Server) writeTransferMetrics(w io.Writer) {
+	transfer, ok := s.transferStats()
+	if !ok {
+		return
+	}
+	metrics := []struct {
+		name, help string
+		value      int64
+	}{
+		{"mcp_defectdojo_responses_total", "DefectDojo response bodies read.", transfer.Responses},
+		{"mcp_defectdojo_compressed_responses_total", "DefectDojo response bodies received gzip-encoded.", transfer.CompressedResponses},
+		{"mcp_defectdojo_response_wire_bytes_total", "DefectDojo response bytes received over the network.", transfer.WireBytes},
+		{"mcp_defectdojo_response_decoded_bytes_total", "DefectDojo response bytes after decompression.", transfer.DecodedBytes},
+	}
+	for _, metric := range metrics {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n%s %d\n", metric.name, metric.help, metric.name, metric.name, metric.value)
+	}
+}
+
+// milliseconds converts a duration to fractional milliseconds
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// statsMiddleware records every tool call's outcome, latency and result size.
+// A call fails when the handler returns an error or an error result.
+func statsMiddleware(stats *toolStats) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			start := time.Now()
+			result, err := next(ctx, request)
+			stats.record(request.Params.Name, time.Since(start), resultBytes(result), err != nil || (result != nil && result.IsError))
+			return result, err
+		}
+	}
+}
+
+// getServerStats handles get_server_stats
+func (s *Server) getServerStats(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	usage := s.stats.snapshot()
+	uptime := time.Since(s.stats.started).Round(time.Second)
+
+	var transfer *defectdojo.TransferStats
+	if measured, ok := s.transferStats(); ok {
+		transfer = &measured
+	}
+
+	workers := s.workers.snapshot()
+
+	if s.outputFormat(request) == formatJSON {
+		output, err := marshalOutput(struct {
+			Started        time.Time                 `json:"started"`
+			Uptime         string                    `json:"uptime"`
+			Tools          []toolUsageStats          `json:"tools"`
+			Transfer       *defectdojo.TransferStats `json:"defectdojo_transfer,omitempty"`
+			WorkerPoolSize int                       `json:"worker_pool_size"`
+			WorkerPool     []workerPoolStats         `json:"worker_pool"`
+		}{s.stats.started.UTC(), uptime.String(), usage, transfer, s.workers.size(), workers})
+		if err != nil {
+			return nil, err
+		}
+		return mcp.NewToolResultText(output), nil
+	}
+
+	calls := 0
+	for _, tool := range usage {
+		calls += tool.Calls
+	}
+	result := fmt.Sprintf("%d tool calls since %s (up %s)\n", calls, s.stats.started.UTC().Format(time.RFC3339), uptime)
+	for _, tool := range usage {
+		result += fmt.Sprintf("\n%s: %d calls, %d errors (%.1f%%), median %.1f ms, results up to %s",
+			tool.Tool, tool.Calls, tool.Errors, tool.ErrorRate*100, tool.MedianLatencyMS, formatBytes(tool.MaxResultBytes))
+		if tool.Summarized > 0 {
+			result += fmt.Sprintf(" (%d summarized)", tool.Summarized)
+		}
+		result += "\n"
+	}
+	if transfer != nil && transfer.Responses > 0 {
+		result += fmt.Sprintf("\nDefectDojo responses: %d (%d gzip-compressed), %.1f KiB received for %.1f KiB of data (%.1fx smaller)\n",
+			transfer.Responses, transfer.CompressedResponses, float64(transfer.WireBytes)/1024, float64(transfer.DecodedBytes)/1024, transfer.Ratio())
+	}
+	if len(workers) > 0 {
+		result += fmt.Sprintf("\nWorker pool (%d workers):\n", s.workers.size())
+		for _, operation := range workers {
+			result += fmt.Sprintf("%s: %d tasks, %d running, %d waiting, %.1f ms waited\n",
+				operation.Operation, operation.Tasks, operation.Running, operation.Waiting, operation.WaitTimeMS)
+		}
+	}
+	return mcp.NewToolResultText(result), nil
+}
@@ -0,0 +1,125 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brduru/mcp-defect-dojo/internal/defectdojo"
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+func TestToolStats(t *testing.T) {
+	stats := newToolStats()
+	for _, latency := range []int{30, 10, 20, 40} {
+		stats.record("get_finding_detail", time.Duration(latency)*time.Millisecond, latency*100, latency == 40)
+	}
+	stats.record("defectdojo_health_check", 5*time.Millisecond, 80, false)
+	stats.recordSummarized("get_finding_detail")
+
+	usage := stats.snapshot()
+	if len(usage) != 2 || usage[0].Tool != "get_finding_detail" {
+		t.Fatalf("expected the most called tool first, got %+v", usage)
+	}
+	if got := usage[0]; got.Calls != 4 || got.Errors != 1 || got.ErrorRate != 0.25 || got.MedianLatencyMS != 25 || got.TotalLatencyMS != 100 ||
+		got.ResultBytes != 10000 || got.MaxResultBytes != 4000 || got.Summarized != 1 {
+		t.Errorf("unexpected statistics %+v", got)
+	}
+
+	// Only the most recent latencies count towards the median
+	for range latencySamples {
+		stats.record("defectdojo_health_check", time.Second, 80, false)
+	}
+	if got := stats.snapshot()[0]; got.Tool != "defectdojo_health_check" || got.MedianLatencyMS != 1000 {
+		t.Errorf("unexpected statistics %+v", got)
+	}
+}
+
+func TestGetServerStats(t *testing.T) {
+	mock := &MockDefectDojoClient{
+		GetFindingDetailFunc: func(ctx context.Context, findingID int) (*types.Finding, error) {
+			return nil, errors.New("not found")
+		},
+	}
+	s := newServer(&Config{}, mock)
+	for range 2 {
+		callTool(t, s, "get_finding_detail", map[string]any{"finding_id": 1})
+	}
+	callTool(t, s, "get_finding_detail", map[string]any{"finding_id": "one"})
+	if _, err := callTool(t, s, "defectdojo_health_check", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	result, err := callTool(t, s, "get_server_stats", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text := resultText(result)
+	for _, want := range []string{"4 tool calls since", "get_finding_detail: 3 calls, 3 errors (100.0%)", "defectdojo_health_check: 1 calls, 0 errors (0.0%)"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in:\n%s", want, text)
+		}
+	}
+
+	result, err = callTool(t, s, "get_server_stats", map[string]any{"format": "json"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var output struct {
+		Tools []toolUsageStats `json:"tools"`
+	}
+	if err := json.Unmarshal([]byte(resultText(result)), &output); err != nil || len(output.Tools) != 3 || output.Tools[0].Tool != "get_finding_detail" {
+		t.Errorf("unexpected JSON stats %+v (%v)", output, err)
+	}
+
+	rec := httptest.NewRecorder()
+	s.HealthHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := rec.Body.String()
+	for _, want := range []string{
+		"# TYPE mcp_tool_calls_total counter",
+		`mcp_tool_calls_total{tool="get_finding_detail"} 3`,
+		`mcp_tool_errors_total{tool="get_finding_detail"} 3`,
+		`mcp_tool_calls_total{tool="get_server_stats"} 2`,
+		`mcp_tool_summarized_results_total{tool="get_finding_detail"} 0`,
+		"# TYPE mcp_tool_result_bytes_total counter",
+		"mcp_server_start_time_seconds ",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected %q in metrics:\n%s", want, body)
+		}
+	}
+}
+
+// transferClient is a mock DefectDojo client that measures its traffic
+type transferClient struct {
+	MockDefectDojoClient
+}
+
+func (c *transferClient) TransferStats() defectdojo.TransferStats {
+	return defectdojo.TransferStats{Responses: 4, CompressedResponses: 3, WireBytes: 10240, DecodedBytes: 92160}
+}
+
+func TestTransferStats(t *testing.T) {
+	s := newServer(&Config{}, &transferClient{})
+
+	result, err := callTool(t, s, "get_server_stats", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "DefectDojo responses: 4 (3 gzip-compressed), 10.0 KiB received for 90.0 KiB of data (9.0x smaller)"; !strings.Contains(resultText(result), want) {
+		t.Errorf("expected %q in:\n%s", want, resultText(result))
+	}
+
+	rec := httptest.NewRecorder()
+	s.HealthHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	for _, want := range []string{"mcp_defectdojo_response_wire_bytes_total 10240", "mcp_defectdojo_response_decoded_bytes_total 92160"} {
+		if !strings.Contains(rec.Body.String(), want) {
+			t.Errorf("expected %q in metrics:\n%s", want, rec.Body.String())
+		}
+	}
+}
@@ -0,0 +1,252 @@
+package mcpserver
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+// Attack surface resource
+const (
+	attackSurfaceTemplate = "defectdojo://product/{id}/attack-surface{?format}"
+	surfacePageSize       = 100
+	maxSurfaceEndpoints   = 1000 // Endpoints one summary may list
+	maxSurfaceTechs       = 200  // Technologies one summary may list
+	maxSurfaceFindings    = 2000 // Open findings counted per summary
+)
+
+// attackSurface is the document served by the attack surface resource
+type attackSurface struct {
+	GeneratedAt  time.Time          `json:"generated_at"`
+	Product      types.Product      `json:"product"`
+	URL          string             `json:"url,omitempty"` // DefectDojo UI page of the product
+	Summary      surfaceSummary     `json:"summary"`
+	Technologies []types.Technology `json:"technologies"`
+	Endpoints    []surfaceEndpoint  `json:"endpoints"` // Most exposed first
+	Notes        []string           `json:"notes,omitempty"`
+	Warnings     []string           `json:"warnings,omitempty"` // Parts DefectDojo failed to provide
+}
+
+// surfaceEndpoint is one endpoint of the product with its open findings
+type surfaceEndpoint struct {
+	types.Endpoint
+	Location       string         `json:"location"` // The endpoint as a URL
+	OpenFindings   int            `json:"open_findings"`
+	OpenBySeverity severityCounts `json:"open_by_severity"`
+	FindingIDs     []int          `json:"finding_ids"` // Most severe first
+}
+
+// surfaceSummary counts the product's endpoints and open findings
+type surfaceSummary struct {
+	Endpoints           int            `json:"endpoints"`
+	Hosts               int            `json:"hosts"`
+	ExposedEndpoints    int            `json:"exposed_endpoints"` // Endpoints with open findings
+	OpenFindings        int            `json:"open_findings"`
+	OpenBySeverity      severityCounts `json:"open_by_severity"`
+	OpenWithoutEndpoint int            `json:"open_without_endpoint"` // Open findings not tied to an endpoint, e.g. from code scanners
+}
+
+// readAttackSurface serves defectdojo://product/{id}/attack-surface
+func (s *Server) readAttackSurface(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	productID, err := strconv.Atoi(resourceArgument(request, "id"))
+	if err != nil || productID <= 0 {
+		return nil, fmt.Errorf("invalid product ID in %s", request.Params.URI)
+	}
+	format := cmp.Or(resourceArgument(request, "format"), formatJSON)
+	if format != formatJSON && format != formatMarkdown {
+		return nil, fmt.Errorf("invalid format %q (must be json or markdown)", format)
+	}
+
+	ctx, err = s.productContext(ctx, request.Params.URI, fmt.Sprintf("product %d", productID), func(ctx context.Context) (*types.Product, error) {
+		return s.ddClient.GetProduct(ctx, productID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	surface, err := s.attackSurface(ctx, productID, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	if format == formatMarkdown {
+		return []mcp.ResourceContents{mcp.TextResourceContents{
+			URI:      request.Params.URI,
+			MIMEType: "text/markdown",
+			Text:     markdownAttackSurface(surface),
+		}}, nil
+	}
+	text, err := marshalOutput(surface)
+	if err != nil {
+		return nil, err
+	}
+	return []mcp.ResourceContents{mcp.TextResourceContents{
+		URI:      request.Params.URI,
+		MIMEType: "application/json",
+		Text:     text,
+	}}, nil
+}
+
+// attackSurface gathers a product's endpoints and technologies and counts
+// its open findings per endpoint. Only the product itself is required; the
+// parts that cannot be read are left out and named in the warnings.
+func (s *Server) attackSurface(ctx context.Context, productID int, now time.Time) (*attackSurface, error) {
+	product, err := s.ddClient.GetProduct(ctx, productID)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving product %d: %w", productID, err)
+	}
+	surface := &attackSurface{GeneratedAt: now, Product: *product, URL: s.links.product(productID), Technologies: []types.Technology{}, Endpoints: []surfaceEndpoint{}}
+	var partial partialResults
+
+	for offset := 0; offset < maxSurfaceEndpoints; {
+		if budgetFrom(ctx).spendPage() != nil {
+			break // The call fails with the budget error
+		}
+		page, err := s.ddClient.ListEndpoints(ctx, productID, surfacePageSize, offset)
+		if err != nil {
+			partial.warnf("endpoints unavailable after the first %d: %v", offset, err)
+			break
+		}
+		for _, endpoint := range page.Results {
+			surface.Endpoints = append(surface.Endpoints, surfaceEndpoint{Endpoint: endpoint, Location: endpoint.String(), FindingIDs: []int{}})
+		}
+		offset += len(page.Results)
+		if page.Next == nil || len(page.Results) == 0 {
+			break
+		}
+		if offset >= maxSurfaceEndpoints {
+			surface.Notes = append(surface.Notes, fmt.Sprintf("Only the first %d of %d endpoints are listed.", offset, page.Count))
+		}
+	}
+
+	for offset := 0; offset < maxSurfaceTechs; {
+		if budgetFrom(ctx).spendPage() != nil {
+			break // The call fails with the budget error
+		}
+		page, err := s.ddClient.ListTechnologies(ctx, productID, surfacePageSize, offset)
+		if err != nil {
+			partial.warnf("technologies unavailable after the first %d: %v", offset, err)
+			break
+		}
+		surface.Technologies = append(surface.Technologies, page.Results...)
+		offset += len(page.Results)
+		if page.Next == nil || len(page.Results) == 0 {
+			break
+		}
+		if offset >= maxSurfaceTechs {
+			surface.Notes = append(surface.Notes, fmt.Sprintf("Only the first %d of %d technologies are listed.", offset, page.Count))
+		}
+	}
+
+	// Findings come most severe first, so each endpoint's IDs are in that order too
+	active := true
+	filter := types.FindingsFilter{Product: &productID, Active: &active, Ordering: "numerical_severity,-date", Limit: surfacePageSize}
+	for filter.Offset < maxSurfaceFindings {
+		response, err := s.findingsPage(ctx, filter)
+		if err != nil {
+			partial.warnf("open findings unavailable after the first %d: %v; counts cover only those read", filter.Offset, err)
+			break
+		}
+		for _, finding := range response.Results {
+			surface.Summary.OpenFindings++
+			surface.Summary.OpenBySeverity.add(finding.Severity, 1)
+			if len(finding.Endpoints) == 0 {
+				surface.Summary.OpenWithoutEndpoint++
+			}
+			for _, id := range finding.Endpoints {
+				if i := slices.IndexFunc(surface.Endpoints, func(e surfaceEndpoint) bool { return e.ID == id }); i >= 0 {
+					endpoint := &surface.Endpoints[i]
+					endpoint.OpenFindings++
+					endpoint.OpenBySeverity.add(finding.Severity, 1)
+					endpoint.FindingIDs = append(endpoint.FindingIDs, finding.ID)
+				}
+			}
+		}
+		filter.Offset += len(response.Results)
+		if response.Next == nil || len(response.Results) == 0 {
+			break
+		}
+		if filter.Offset >= maxSurfaceFindings {
+			surface.Notes = append(surface.Notes, fmt.Sprintf("Only the %d most severe of %d open findings are counted.", filter.Offset, response.Count))
+		}
+	}
+
+	slices.SortFunc(surface.Endpoints, func(a, b surfaceEndpoint) int {
+		return cmp.Or(a.OpenBySeverity.compare(b.OpenBySeverity), strings.Compare(a.Location, b.Location))
+	})
+	hosts := map[string]bool{}
+	for _, endpoint := range surface.Endpoints {
+		hosts[strings.ToLower(endpoint.Host)] = true
+		if endpoint.OpenFindings > 0 {
+			surface.Summary.ExposedEndpoints++
+		}
+	}
+	surface.Summary.Endpoints, surface.Summary.Hosts = len(surface.Endpoints), len(hosts)
+	surface.Warnings = partial.list()
+	return surface, nil
+}
+
+// markdownAttackSurface renders the attack surface as one Markdown document
+func markdownAttackSurface(surface *attackSurface) string {
+	var result strings.Builder
+	fmt.Fprintf(&result, "# Attack surface: %s (ID %d)\n\n", surface.Product.Name, surface.Product.ID)
+	if surface.URL != "" {
+		fmt.Fprintf(&result, "- **URL:** %s\n", surface.URL)
+	}
+	if len(surface.Product.Tags) > 0 {
+		fmt.Fprintf(&result, "- **Tags:** %s\n", strings.Join(surface.Product.Tags, ", "))
+	}
+	fmt.Fprintf(&result, "- **Generated:** %s\n", surface.GeneratedAt.Format(time.RFC3339))
+	if description := strings.TrimSpace(surface.Product.Description); description != "" {
+		fmt.Fprintf(&result, "\n%s\n", description)
+	}
+
+	summary := surface.Summary
+	counts := summary.OpenBySeverity
+	result.WriteString("\n## Summary\n\n")
+	fmt.Fprintf(&result, "- **Endpoints:** %d on %d hosts, %d with open findings\n", summary.Endpoints, summary.Hosts, summary.ExposedEndpoints)
+	fmt.Fprintf(&result, "- **Open findings:** %d (Critical %d, High %d, Medium %d, Low %d, Info %d)\n",
+		summary.OpenFindings, counts.Critical, counts.High, counts.Medium, counts.Low, counts.Info)
+	fmt.Fprintf(&result, "- **Open findings without an endpoint:** %d\n", summary.OpenWithoutEndpoint)
+	for _, note := range surface.Notes {
+		fmt.Fprintf(&result, "\n_%s_\n", note)
+	}
+	result.WriteString(formatWarnings(surface.Warnings))
+
+	fmt.Fprintf(&result, "\n## Technologies (%d)\n\n", len(surface.Technologies))
+	if len(surface.Technologies) > 0 {
+		result.WriteString("| Name | Version | Confidence |\n")
+		result.WriteString("|------|---------|-----------:|\n")
+		for _, tech := range surface.Technologies {
+			confidence := "-"
+			if tech.Confidence > 0 {
+				confidence = fmt.Sprintf("%d%%", tech.Confidence)
+			}
+			fmt.Fprintf(&result, "| %s | %s | %s |\n", markdownCell(tech.Name), markdownCell(cmp.Or(tech.Version, "-")), confidence)
+		}
+	}
+
+	fmt.Fprintf(&result, "\n## Endpoints (%d)\n\n", len(surface.Endpoints))
+	if len(surface.Endpoints) > 0 {
+		result.WriteString("| Endpoint | Open | Critical | High | Medium | Low | Info | Finding IDs |\n")
+		result.WriteString("|----------|-----:|---------:|-----:|-------:|----:|-----:|-------------|\n")
+		for _, endpoint := range surface.Endpoints {
+			ids := make([]string, len(endpoint.FindingIDs))
+			for i, id := range endpoint.FindingIDs {
+				ids[i] = fmt.Sprintf("%d", id)
+			}
+			c := endpoint.OpenBySeverity
+			fmt.Fprintf(&result, "| %s | %d | %d | %d | %d | %d | %d | %s |\n", markdownCell(endpoint.Location), endpoint.OpenFindings,
+				c.Critical, c.High, c.Medium, c.Low, c.Info, cmp.Or(strings.Join(ids, ", "), "-"))
+		}
+	}
+	return result.String()
+}
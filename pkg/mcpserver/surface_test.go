@@ -0,0 +1,86 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/brduru/mcp-defect-dojo/internal/defectdojo"
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+func TestAttackSurface(t *testing.T) {
+	fixtures, err := defectdojo.NewFixtureClient("")
+	if err != nil {
+		t.Fatalf("NewFixtureClient() error = %v", err)
+	}
+	s := newServer(&Config{}, fixtures)
+
+	contents, err := readResource(t, s, "defectdojo://product/1/attack-surface")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var surface attackSurface
+	if err := json.Unmarshal([]byte(contents.Text), &surface); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if surface.Product.Name != "Payments API" || len(surface.Technologies) != 2 || len(surface.Endpoints) != 2 {
+		t.Fatalf("unexpected attack surface %+v", surface)
+	}
+	first := surface.Endpoints[0]
+	if first.Location != "https://pay.example.com/api/v1/payments/search" || first.OpenBySeverity.Critical != 1 || first.FindingIDs[0] != 1 {
+		t.Errorf("expected the endpoint with the critical finding first, got %+v", first)
+	}
+	if summary := surface.Summary; summary.Hosts != 1 || summary.ExposedEndpoints != 2 || summary.OpenFindings != 3 || summary.OpenWithoutEndpoint != 0 {
+		t.Errorf("unexpected summary %+v", summary)
+	}
+
+	contents, err = readResource(t, s, "defectdojo://product/2/attack-surface?format=markdown")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"# Attack surface: Customer Portal (ID 2)",
+		"- **Endpoints:** 1 on 1 hosts, 1 with open findings",
+		"- **Open findings without an endpoint:** 1",
+		"| React | 18.2.0 | 100% |",
+		"| https://portal.example.com:8443/login | 1 | 0 | 0 | 1 | 0 | 0 | 4 |",
+	} {
+		if !strings.Contains(contents.Text, want) {
+			t.Errorf("expected %q in:\n%s", want, contents.Text)
+		}
+	}
+
+	if _, err := readResource(t, s, "defectdojo://product/999/attack-surface"); err == nil || !strings.Contains(err.Error(), "product 999") {
+		t.Errorf("expected a missing product to fail, got %v", err)
+	}
+	if _, err := readResource(t, s, "defectdojo://product/1/attack-surface?format=html"); err == nil {
+		t.Error("expected an unknown format to fail")
+	}
+}
+
+func TestAttackSurfaceEndpointError(t *testing.T) {
+	mock := &MockDefectDojoClient{
+		ListEndpointsFunc: func(ctx context.Context, productID, limit, offset int) (*types.EndpointsResponse, error) {
+			return nil, errors.New("forbidden")
+		},
+	}
+	s := newServer(&Config{}, mock)
+
+	contents, err := readResource(t, s, "defectdojo://product/1/attack-surface")
+	if err != nil {
+		t.Fatalf("expected the rest of the surface despite the endpoint error, got %v", err)
+	}
+	var surface attackSurface
+	if err := json.Unmarshal([]byte(contents.Text), &surface); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(surface.Warnings) != 1 || !strings.Contains(surface.Warnings[0], "endpoints unavailable") || !strings.Contains(surface.Warnings[0], "forbidden") {
+		t.Errorf("expected the endpoint error in the warnings, got %v", surface.Warnings)
+	}
+	if surface.Summary.OpenFindings == 0 {
+		t.Error("expected the open findings to be counted anyway")
+	}
+}
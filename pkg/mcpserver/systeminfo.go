@@ -0,0 +1,215 @@
+package mcpserver
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/brduru/mcp-defect-dojo/internal/defectdojo"
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+// slaConfigurationsPageSize bounds the SLA configurations one call lists
+const slaConfigurationsPageSize = 50
+
+// systemInfo is the result of get_defectdojo_system_info
+type systemInfo struct {
+	Version           string                   `json:"version,omitempty"`
+	Settings          *types.SystemSettings    `json:"settings,omitempty"` // Nil when the token may not read them
+	SLAConfigurations []types.SLAConfiguration `json:"sla_configurations"`
+	Announcement      *types.Announcement      `json:"announcement,omitempty"`
+	Guidance          []string                 `json:"guidance"` // What the configuration means for agents
+	Notes             []string                 `json:"notes,omitempty"`
+}
+
+// collectSystemInfo reads the settings, SLA configurations and announcement
+// concurrently. Parts that cannot be read are explained in the notes instead
+// of failing the call, since only superusers may read the system settings;
+// it fails only when nothing could be read.
+func (s *Server) collectSystemInfo(ctx context.Context) (*systemInfo, error) {
+	info := &systemInfo{SLAConfigurations: []types.SLAConfiguration{}}
+	var settingsErr, slaErr, announcementErr error
+	var slas *types.SLAConfigurationsResponse
+	var wg sync.WaitGroup
+	wg.Go(func() { info.Version = s.ddClient.Version(ctx) })
+	wg.Go(func() { info.Settings, settingsErr = s.ddClient.GetSystemSettings(ctx) })
+	wg.Go(func() { slas, slaErr = s.ddClient.ListSLAConfigurations(ctx, slaConfigurationsPageSize, 0) })
+	wg.Go(func() { info.Announcement, announcementErr = s.ddClient.GetAnnouncement(ctx) })
+	wg.Wait()
+
+	if settingsErr != nil {
+		info.Notes = append(info.Notes, "System settings unavailable: "+describeReadError(settingsErr, "only superusers may read them"))
+	}
+	if slaErr != nil {
+		info.Notes = append(info.Notes, "SLA configurations unavailable: "+describeReadError(slaErr, "the API token may not read them"))
+	} else {
+		info.SLAConfigurations = append(info.SLAConfigurations, slas.Results...)
+		if slas.Count > len(slas.Results) {
+			info.Notes = append(info.Notes, fmt.Sprintf("Only the first %d of %d SLA configurations are listed.", len(slas.Results), slas.Count))
+		}
+	}
+	if announcementErr != nil {
+		info.Notes = append(info.Notes, "Announcement unavailable: "+describeReadError(announcementErr, "the API token may not read it"))
+	}
+	if settingsErr != nil && slaErr != nil && announcementErr != nil {
+		return nil, fmt.Errorf("cannot read the DefectDojo configuration:\n%s", strings.Join(info.Notes, "\n"))
+	}
+	info.Guidance = systemGuidance(info)
+	return info, nil
+}
+
+// describeReadError explains a failed read, using forbidden for permission errors
+func describeReadError(err error, forbidden string) string {
+	var apiErr *defectdojo.APIError
+	if errors.As(err, &apiErr) && (apiErr.StatusCode == http.StatusForbidden || apiErr.StatusCode == http.StatusUnauthorized) {
+		return forbidden
+	}
+	return err.Error()
+}
+
+// systemGuidance spells out how the instance's configuration should change
+// an agent's advice
+func systemGuidance(info *systemInfo) []string {
+	guidance := []string{}
+	if settings := info.Settings; settings != nil {
+		if settings.EnableDeduplication {
+			guidance = append(guidance, "Deduplication is on: findings matching an existing one are marked duplicates, so triage the original finding rather than its duplicates.")
+		} else {
+			guidance = append(guidance, "Deduplication is off: the same issue can appear once per import, so check for similar findings before counting or triaging them.")
+		}
+		if settings.FalsePositiveHistory {
+			scope := "future imports"
+			if settings.RetroactiveFalsePositiveHistory {
+				scope = "existing and future findings"
+			}
+			guidance = append(guidance, fmt.Sprintf("False positive history is on: marking a finding as false positive also marks matching %s of the product, so be sure before marking.", scope))
+		}
+		if !settings.EnableFindingSLA {
+			guidance = append(guidance, "Finding SLAs are off: findings have no remediation deadlines, so do not report SLA breaches.")
+		}
+		if !settings.EnableFullRiskAcceptance {
+			guidance = append(guidance, "Full risk acceptance is off: risk acceptances have no owner or expiry date to follow up on.")
+		}
+		if disclaimer := cmp.Or(strings.TrimSpace(settings.DisclaimerNotes), strings.TrimSpace(settings.Disclaimer)); disclaimer != "" {
+			guidance = append(guidance, "Notes carry a disclaimer; keep notes consistent with it: "+disclaimer)
+		}
+	}
+	if len(info.SLAConfigurations) > 1 {
+		guidance = append(guidance, "Products use different SLA configurations: look up a product's configuration before quoting a deadline.")
+	}
+	if announcement := info.Announcement; announcement != nil && strings.TrimSpace(announcement.Message) != "" {
+		guidance = append(guidance, "An announcement is shown to all users; mention it when it affects the user's request.")
+	}
+	return guidance
+}
+
+// getSystemInfo handles get_defectdojo_system_info
+func (s *Server) getSystemInfo(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	info, err := s.collectSystemInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.outputFormat(request) == formatJSON {
+		output, err := marshalOutput(info)
+		if err != nil {
+			return nil, err
+		}
+		return mcp.NewToolResultText(output), nil
+	}
+	return mcp.NewToolResultText(formatSystemInfo(info)), nil
+}
+
+// formatSystemInfo renders the system info as text
+func formatSystemInfo(info *systemInfo) string {
+	var result strings.Builder
+	result.WriteString("DefectDojo system info")
+	if info.Version != "" {
+		fmt.Fprintf(&result, " (version %s)", info.Version)
+	}
+	result.WriteString("\n")
+
+	if settings := info.Settings; settings != nil {
+		result.WriteString("\nSettings:\n")
+		dedup := onOff(settings.EnableDeduplication)
+		if settings.EnableDeduplication && settings.DeleteDuplicates {
+			dedup += ", old duplicates deleted"
+			if settings.MaxDupes != nil {
+				dedup += fmt.Sprintf(" beyond %d per finding", *settings.MaxDupes)
+			}
+		}
+		fmt.Fprintf(&result, "  Deduplication: %s\n", dedup)
+		history := onOff(settings.FalsePositiveHistory)
+		if settings.FalsePositiveHistory && settings.RetroactiveFalsePositiveHistory {
+			history += ", retroactive"
+		}
+		fmt.Fprintf(&result, "  False positive history: %s\n", history)
+		fmt.Fprintf(&result, "  Finding SLAs: %s\n", onOff(settings.EnableFindingSLA))
+		acceptance := onOff(settings.EnableFullRiskAcceptance)
+		if settings.EnableFullRiskAcceptance && settings.RiskAcceptanceFormDefaultDays > 0 {
+			acceptance += fmt.Sprintf(", %d days by default", settings.RiskAcceptanceFormDefaultDays)
+		}
+		fmt.Fprintf(&result, "  Full risk acceptance: %s\n", acceptance)
+		fmt.Fprintf(&result, "  JIRA integration: %s\n", onOff(settings.EnableJIRA))
+		fmt.Fprintf(&result, "  Product tag inheritance: %s\n", onOff(settings.EnableProductTagInheritance))
+
+		for _, disclaimer := range []struct{ where, text string }{
+			{"Notes and reports", settings.Disclaimer},
+			{"Notes", settings.DisclaimerNotes},
+			{"Reports", settings.DisclaimerReports},
+			{"Notifications", settings.DisclaimerNotifications},
+		} {
+			if text := strings.TrimSpace(disclaimer.text); text != "" {
+				fmt.Fprintf(&result, "  Disclaimer (%s): %s\n", disclaimer.where, text)
+			}
+		}
+	}
+
+	if len(info.SLAConfigurations) > 0 {
+		result.WriteString("\nSLA configurations:\n")
+		for _, sla := range info.SLAConfigurations {
+			var deadlines []string
+			for _, severity := range []string{"Critical", "High", "Medium", "Low"} {
+				days, enforced := sla.Days(severity)
+				switch {
+				case enforced:
+					deadlines = append(deadlines, fmt.Sprintf("%s %dd", severity, days))
+				case days > 0:
+					deadlines = append(deadlines, fmt.Sprintf("%s %dd (not enforced)", severity, days))
+				default:
+					deadlines = append(deadlines, severity+" none")
+				}
+			}
+			fmt.Fprintf(&result, "  %s (ID %d): %s\n", sla.Name, sla.ID, strings.Join(deadlines, ", "))
+		}
+	}
+
+	if announcement := info.Announcement; announcement != nil && strings.TrimSpace(announcement.Message) != "" {
+		fmt.Fprintf(&result, "\nAnnouncement (%s): %s\n", cmp.Or(announcement.Style, "info"), strings.TrimSpace(announcement.Message))
+	}
+
+	if len(info.Guidance) > 0 {
+		result.WriteString("\nWhat this means for agents:\n")
+		for _, guidance := range info.Guidance {
+			fmt.Fprintf(&result, "- %s\n", guidance)
+		}
+	}
+	for _, note := range info.Notes {
+		fmt.Fprintf(&result, "\n%s\n", note)
+	}
+	return result.String()
+}
+
+// onOff renders a setting flag
+func onOff(enabled bool) string {
+	if enabled {
+		return "enabled"
+	}
+	return "disabled"
+}
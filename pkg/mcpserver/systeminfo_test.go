@@ -0,0 +1,94 @@
+package mcpserver
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/brduru/mcp-defect-dojo/internal/defectdojo"
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+func TestGetSystemInfo(t *testing.T) {
+	fixtures, err := defectdojo.NewFixtureClient("")
+	if err != nil {
+		t.Fatalf("NewFixtureClient() error = %v", err)
+	}
+	s := newServer(&Config{}, fixtures)
+
+	result, err := callTool(t, s, "get_defectdojo_system_info", map[string]any{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text := resultText(result)
+	for _, want := range []string{
+		"Deduplication: enabled\n",
+		"False positive history: enabled\n",
+		"Full risk acceptance: enabled, 180 days by default",
+		"Disclaimer (Notes): Notes are visible to auditors.",
+		"Default (ID 1): Critical 7d, High 30d, Medium 90d, Low 120d (not enforced)",
+		"Internet-facing (ID 2): Critical 3d",
+		"Announcement (warning): DefectDojo will be read-only on Saturday",
+		"- False positive history is on: marking a finding as false positive also marks matching future imports of the product",
+		"- Products use different SLA configurations",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in output, got: %s", want, text)
+		}
+	}
+
+	result, err = callTool(t, s, "get_defectdojo_system_info", map[string]any{"format": "json"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := resultText(result); !strings.Contains(text, `"enable_deduplication": true`) || !strings.Contains(text, `"style": "warning"`) {
+		t.Errorf("unexpected JSON output: %s", text)
+	}
+}
+
+func TestGetSystemInfoPartial(t *testing.T) {
+	forbidden := &defectdojo.APIError{StatusCode: http.StatusForbidden, Body: `{"detail":"You do not have permission to perform this action."}`}
+	mock := &MockDefectDojoClient{
+		GetSystemSettingsFunc: func(ctx context.Context) (*types.SystemSettings, error) {
+			return nil, forbidden
+		},
+	}
+	s := newServer(&Config{}, mock)
+
+	result, err := callTool(t, s, "get_defectdojo_system_info", map[string]any{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text := resultText(result)
+	if strings.Contains(text, "Settings:") || !strings.Contains(text, "System settings unavailable: only superusers may read them") {
+		t.Errorf("expected the settings to be reported unreadable, got: %s", text)
+	}
+	if !strings.Contains(text, "Default (ID 1): Critical 7d") {
+		t.Errorf("expected the SLA configurations despite the settings, got: %s", text)
+	}
+
+	mock.ListSLAConfigurationsFunc = func(ctx context.Context, limit, offset int) (*types.SLAConfigurationsResponse, error) {
+		return nil, errors.New("connection refused")
+	}
+	mock.GetAnnouncementFunc = func(ctx context.Context) (*types.Announcement, error) {
+		return nil, forbidden
+	}
+	if _, err := callTool(t, s, "get_defectdojo_system_info", map[string]any{"format": "text"}); err == nil || !strings.Contains(err.Error(), "SLA configurations unavailable: connection refused") {
+		t.Errorf("expected the call to fail when nothing is readable, got %v", err)
+	}
+}
+
+func TestSystemGuidance(t *testing.T) {
+	guidance := systemGuidance(&systemInfo{Settings: &types.SystemSettings{Disclaimer: "Internal use only"}})
+	text := strings.Join(guidance, "\n")
+	for _, want := range []string{"Deduplication is off", "Finding SLAs are off", "Full risk acceptance is off", "keep notes consistent with it: Internal use only"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in guidance, got: %s", want, text)
+		}
+	}
+	if guidance := systemGuidance(&systemInfo{}); len(guidance) != 0 {
+		t.Errorf("expected no guidance without settings, got %v", guidance)
+	}
+}
@@ -0,0 +1,126 @@
+package mcpserver
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+// severityScale translates between DefectDojo severities and an
+// organization's own labels, e.g. P1 for Critical. The zero value is
+// DefectDojo's own scale.
+type severityScale struct {
+	labels   map[string]string // Organization label by DefectDojo severity
+	severity map[string]string // DefectDojo severity by lowercase organization label
+}
+
+// newSeverityScale checks the configured labels: each must name a DefectDojo
+// severity, be unique and not be the name of another DefectDojo severity.
+// Severities without a label keep their DefectDojo name.
+func newSeverityScale(labels map[string]string) (severityScale, error) {
+	if len(labels) == 0 {
+		return severityScale{}, nil
+	}
+	scale := severityScale{labels: map[string]string{}, severity: map[string]string{}}
+	for _, key := range slices.Sorted(maps.Keys(labels)) {
+		severity, ok := types.NormalizeSeverity(key)
+		if !ok {
+			return severityScale{}, fmt.Errorf("unknown severity %q (must be one of %s)", key, strings.Join(types.ValidSeverities(), ", "))
+		}
+		label := strings.TrimSpace(labels[key])
+		switch other, taken := scale.severity[strings.ToLower(label)]; {
+		case label == "":
+			return severityScale{}, fmt.Errorf("severity %s has an empty label", severity)
+		case taken:
+			return severityScale{}, fmt.Errorf("label %q is given to both %s and %s", label, other, severity)
+		case scale.labels[severity] != "":
+			return severityScale{}, fmt.Errorf("severity %s is labelled twice", severity)
+		}
+		if named, ok := types.NormalizeSeverity(label); ok && named != severity {
+			return severityScale{}, fmt.Errorf("label %q of %s is the name of another DefectDojo severity", label, severity)
+		}
+		scale.labels[severity] = label
+		scale.severity[strings.ToLower(label)] = severity
+	}
+	return scale, nil
+}
+
+// label returns the organization label of a DefectDojo severity, or "" when
+// it has none
+func (s severityScale) label(severity string) string {
+	return s.labels[severity]
+}
+
+// display names a DefectDojo severity for tool output, e.g. "P1 (Critical)"
+func (s severityScale) display(severity string) string {
+	if label := s.label(severity); label != "" && label != severity {
+		return fmt.Sprintf("%s (%s)", label, severity)
+	}
+	return severity
+}
+
+// normalize returns the DefectDojo severity of an organization label or
+// DefectDojo severity name, matched case-insensitively
+func (s severityScale) normalize(value string) (string, bool) {
+	if severity, ok := s.severity[strings.ToLower(strings.TrimSpace(value))]; ok {
+		return severity, true
+	}
+	return types.NormalizeSeverity(value)
+}
+
+// accepted lists the DefectDojo severities followed by their organization labels
+func (s severityScale) accepted() []string {
+	values := types.ValidSeverities()
+	for _, severity := range types.ValidSeverities() {
+		if label := s.label(severity); label != "" && label != severity {
+			values = append(values, label)
+		}
+	}
+	return values
+}
+
+// String describes the mapping for tool descriptions, e.g. "P1 = Critical, P2 = High"
+func (s severityScale) String() string {
+	var pairs []string
+	for _, severity := range slices.Backward(types.ValidSeverities()) {
+		if label := s.label(severity); label != "" && label != severity {
+			pairs = append(pairs, fmt.Sprintf("%s = %s", label, severity))
+		}
+	}
+	return strings.Join(pairs, ", ")
+}
+
+// withSeverityLabels lets a tool's severity arguments accept the organization
+// labels too, so schema validation does not reject them
+func (s severityScale) withSeverityLabels(tool mcp.Tool) mcp.Tool {
+	mapping := s.String()
+	if mapping == "" {
+		return tool
+	}
+	var properties map[string]any
+	for name, raw := range tool.InputSchema.Properties {
+		property, ok := raw.(map[string]any)
+		if !ok {
+			continue
+		}
+		if enum, ok := property["enum"].([]string); !ok || !slices.Equal(enum, types.ValidSeverities()) {
+			continue
+		}
+		if properties == nil {
+			properties = maps.Clone(tool.InputSchema.Properties)
+		}
+		labelled := maps.Clone(property)
+		labelled["enum"] = s.accepted()
+		labelled["description"] = fmt.Sprintf("%v. Also accepts the organization's scale: %s", property["description"], mapping)
+		properties[name] = labelled
+	}
+	if properties != nil {
+		tool.InputSchema.Properties = properties
+	}
+	return tool
+}
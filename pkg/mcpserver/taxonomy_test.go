@@ -0,0 +1,101 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+func TestNewSeverityScale(t *testing.T) {
+	scale, err := newSeverityScale(map[string]string{"critical": "P1", "High": " P2 ", "Medium": "P3"})
+	if err != nil {
+		t.Fatalf("newSeverityScale() error = %v", err)
+	}
+	for _, tt := range []struct{ value, want string }{{"p1", "Critical"}, {"P2", "High"}, {"low", "Low"}, {"Critical", "Critical"}} {
+		if got, ok := scale.normalize(tt.value); !ok || got != tt.want {
+			t.Errorf("normalize(%q) = %q, %t, want %q", tt.value, got, ok, tt.want)
+		}
+	}
+	if _, ok := scale.normalize("P4"); ok {
+		t.Error("expected an unmapped label to be refused")
+	}
+	if got := scale.display("High"); got != "P2 (High)" {
+		t.Errorf("display(High) = %q", got)
+	}
+	if got := scale.display("Low"); got != "Low" {
+		t.Errorf("display(Low) = %q, want the DefectDojo name", got)
+	}
+	if got := scale.String(); got != "P1 = Critical, P2 = High, P3 = Medium" {
+		t.Errorf("String() = %q", got)
+	}
+
+	for _, tt := range []struct {
+		labels  map[string]string
+		wantErr string
+	}{
+		{map[string]string{"Severe": "P1"}, `unknown severity "Severe"`},
+		{map[string]string{"High": " "}, "severity High has an empty label"},
+		{map[string]string{"Critical": "P1", "High": "p1"}, `label "p1" is given to both Critical and High`},
+		{map[string]string{"High": "P2", "high": "P3"}, "severity High is labelled twice"},
+		{map[string]string{"Low": "Info"}, `label "Info" of Low is the name of another DefectDojo severity`},
+	} {
+		if _, err := newSeverityScale(tt.labels); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("newSeverityScale(%v) error = %v, want %q", tt.labels, err, tt.wantErr)
+		}
+	}
+}
+
+func TestSeverityLabelsInTools(t *testing.T) {
+	var filters []types.FindingsFilter
+	client := &MockDefectDojoClient{
+		GetFindingsFunc: func(ctx context.Context, filter types.FindingsFilter) (*types.FindingsResponse, error) {
+			filters = append(filters, filter)
+			return &types.FindingsResponse{Count: 1, Results: []types.Finding{{ID: 7, Title: "SQL injection", Severity: types.SeverityCritical, Active: true}}}, nil
+		},
+	}
+	s := newServer(&Config{Output: OutputConfig{SeverityLabels: map[string]string{"Critical": "P1", "High": "P2"}}}, client)
+
+	tool := s.tools[slices.IndexFunc(s.tools, func(tool mcp.Tool) bool { return tool.Name == toolGetFindings })]
+	severity := tool.InputSchema.Properties["severity"].(map[string]any)
+	if enum := severity["enum"].([]string); !slices.Contains(enum, "P1") || !slices.Contains(enum, "Critical") {
+		t.Errorf("expected the severity enum to accept labels, got %v", enum)
+	}
+
+	result, err := callTool(t, s, toolGetFindings, map[string]any{"severity": "p1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(filters) != 1 || filters[0].Severity != types.SeverityCritical {
+		t.Errorf("expected the P1 filter to query Critical findings, got %+v", filters)
+	}
+	if text := resultText(result); !strings.Contains(text, "1. [P1 (Critical)] SQL injection (ID: 7)") {
+		t.Errorf("expected the organization label in output, got:\n%s", text)
+	}
+
+	result, err = callTool(t, s, toolGetFindings, map[string]any{"min_severity": "P2", "format": "json"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var page struct {
+		Results []struct {
+			Severity      string `json:"severity"`
+			SeverityLabel string `json:"severity_label"`
+		} `json:"results"`
+	}
+	if err := json.Unmarshal([]byte(resultText(result)), &page); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(page.Results) == 0 || page.Results[0].SeverityLabel != "P1" || page.Results[0].Severity != types.SeverityCritical {
+		t.Errorf("expected the JSON severity and its label, got %+v", page.Results)
+	}
+
+	if _, err := callTool(t, s, toolGetFindings, map[string]any{"severity": "P5"}); err == nil {
+		t.Error("expected an unknown label to be rejected")
+	}
+}
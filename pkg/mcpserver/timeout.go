@@ -0,0 +1,113 @@
+package mcpserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// defaultMaxToolTimeout bounds the timeout_seconds argument when not configured
+const defaultMaxToolTimeout = 5 * time.Minute
+
+// timeoutArgument is the optional per-call deadline accepted by every tool
+const timeoutArgument = "timeout_seconds"
+
+// Request _meta keys through which MCP clients announce their own deadline
+const (
+	metaTimeoutMs = "timeoutMs" // Milliseconds the client waits for the result
+	metaDeadline  = "deadline"  // RFC 3339 time at which the client gives up
+)
+
+// metaDeadlineMargin is kept back from a client's deadline so the result
+// still reaches the client before it gives up
+const metaDeadlineMargin = time.Second
+
+// withTimeoutArgument declares the optional timeout_seconds tool parameter
+func withTimeoutArgument() mcp.ToolOption {
+	return mcp.WithNumber(timeoutArgument,
+		mcp.Description("Optional deadline for this call in seconds, e.g. for large queries (default: server request timeout)"),
+		mcp.Min(1),
+	)
+}
+
+// timeoutMiddleware applies the caller-requested timeout_seconds as the
+// deadline for the whole tool call. Requests above maxTimeout are rejected
+// rather than silently clamped, so the agent knows the limit it hit.
+//
+// Without timeout_seconds, a deadline the MCP client announces in the request
+// _meta ("timeoutMs" or "deadline") bounds the call instead, less a safety
+// margin and capped at maxTimeout. Without either, the DefectDojo client's
+// RequestTimeout applies to each request.
+func timeoutMiddleware(maxTimeout time.Duration) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if _, ok := request.GetArguments()[timeoutArgument]; !ok {
+				budget, ok := metaTimeout(request.Params.Meta, time.Now())
+				if !ok {
+					return next(ctx, request)
+				}
+				if budget <= 0 {
+					return nil, fmt.Errorf("%s not started: the client deadline has already passed", request.Params.Name)
+				}
+				timeout := min(budget-min(metaDeadlineMargin, budget/2), maxTimeout)
+				ctx, cancel := context.WithTimeout(ctx, timeout)
+				defer cancel()
+
+				result, err := next(ctx, request)
+				if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+					return nil, fmt.Errorf("%s timed out after %s, before the client deadline: %w", request.Params.Name, timeout.Round(time.Millisecond), err)
+				}
+				return result, err
+			}
+
+			seconds := request.GetFloat(timeoutArgument, 0)
+			timeout := time.Duration(seconds * float64(time.Second))
+			if timeout <= 0 {
+				return nil, fmt.Errorf("invalid %s: must be a positive number", timeoutArgument)
+			}
+			if timeout > maxTimeout {
+				return nil, fmt.Errorf("invalid %s: %g exceeds the server maximum of %g seconds",
+					timeoutArgument, seconds, maxTimeout.Seconds())
+			}
+
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			result, err := next(ctx, request)
+			if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return nil, fmt.Errorf("%s timed out after %g seconds: %w", request.Params.Name, seconds, err)
+			}
+			return result, err
+		}
+	}
+}
+
+// metaTimeout returns the time left until the deadline announced in the
+// request _meta, if any. A "timeoutMs" hint wins over a "deadline" one;
+// malformed hints are ignored.
+func metaTimeout(meta *mcp.Meta, now time.Time) (time.Duration, bool) {
+	if meta == nil {
+		return 0, false
+	}
+	switch ms := meta.AdditionalFields[metaTimeoutMs].(type) {
+	case float64:
+		if ms > 0 {
+			return time.Duration(ms * float64(time.Millisecond)), true
+		}
+	case string:
+		if parsed, err := strconv.ParseFloat(ms, 64); err == nil && parsed > 0 {
+			return time.Duration(parsed * float64(time.Millisecond)), true
+		}
+	}
+	if value, ok := meta.AdditionalFields[metaDeadline].(string); ok {
+		if deadline, err := time.Parse(time.RFC3339, value); err == nil {
+			return deadline.Sub(now), true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,119 @@
+package mcpserver
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestTimeoutMiddleware(t *testing.T) {
+	var deadline time.Time
+	var hasDeadline bool
+	mock := &MockDefectDojoClient{
+		GetFindingsFunc: func(ctx context.Context, filter types.FindingsFilter) (*types.FindingsResponse, error) {
+			deadline, hasDeadline = ctx.Deadline()
+			return &types.FindingsResponse{}, nil
+		},
+	}
+	s := newServer(&Config{Server: ServerConfig{MaxToolTimeout: time.Minute}}, mock)
+
+	t.Run("no override leaves client default", func(t *testing.T) {
+		if _, err := callTool(t, s, "get_defectdojo_findings", map[string]any{}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if hasDeadline {
+			t.Error("expected no tool-level deadline without timeout_seconds")
+		}
+	})
+
+	t.Run("override sets deadline", func(t *testing.T) {
+		start := time.Now()
+		if _, err := callTool(t, s, "get_defectdojo_findings", map[string]any{"timeout_seconds": 45}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !hasDeadline {
+			t.Fatal("expected deadline to be set")
+		}
+		if remaining := deadline.Sub(start); remaining < 44*time.Second || remaining > 46*time.Second {
+			t.Errorf("expected ~45s deadline, got %s", remaining)
+		}
+	})
+
+	for _, tc := range []struct {
+		name    string
+		value   any
+		wantErr string
+	}{
+		{"above maximum", 120, "exceeds the server maximum"},
+		{"non-positive", 0, "must be a positive number"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := callTool(t, s, "get_defectdojo_findings", map[string]any{"timeout_seconds": tc.value})
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestTimeoutMiddlewareMetaDeadline(t *testing.T) {
+	var remaining time.Duration
+	var hasDeadline bool
+	handler := timeoutMiddleware(time.Minute)(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var deadline time.Time
+		deadline, hasDeadline = ctx.Deadline()
+		remaining = time.Until(deadline)
+		return mcp.NewToolResultText("ok"), nil
+	})
+	call := func(arguments map[string]any, meta map[string]any) error {
+		request := mcp.CallToolRequest{}
+		request.Params.Name = "get_defectdojo_findings"
+		request.Params.Arguments = arguments
+		if meta != nil {
+			request.Params.Meta = &mcp.Meta{AdditionalFields: meta}
+		}
+		_, err := handler(context.Background(), request)
+		return err
+	}
+
+	tests := []struct {
+		name      string
+		arguments map[string]any
+		meta      map[string]any
+		want      time.Duration // Expected deadline, 0 for none
+	}{
+		{"no hint", nil, nil, 0},
+		{"timeoutMs less margin", nil, map[string]any{"timeoutMs": float64(20000)}, 19 * time.Second},
+		{"timeoutMs as string", nil, map[string]any{"timeoutMs": "20000"}, 19 * time.Second},
+		{"short budget keeps half", nil, map[string]any{"timeoutMs": float64(1000)}, 500 * time.Millisecond},
+		{"capped at maximum", nil, map[string]any{"timeoutMs": float64(10 * 60 * 1000)}, time.Minute},
+		{"RFC 3339 deadline", nil, map[string]any{"deadline": time.Now().Add(30 * time.Second).Format(time.RFC3339)}, 29 * time.Second},
+		{"malformed hint ignored", nil, map[string]any{"timeoutMs": "soon"}, 0},
+		{"timeout_seconds wins", map[string]any{"timeout_seconds": 5}, map[string]any{"timeoutMs": float64(20000)}, 5 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := call(tt.arguments, tt.meta); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.want == 0 {
+				if hasDeadline {
+					t.Errorf("expected no deadline, got %s", remaining)
+				}
+				return
+			}
+			if !hasDeadline || remaining > tt.want || remaining < tt.want-2*time.Second {
+				t.Errorf("deadline in %s, want ~%s", remaining, tt.want)
+			}
+		})
+	}
+
+	err := call(nil, map[string]any{"deadline": time.Now().Add(-time.Minute).Format(time.RFC3339)})
+	if err == nil || !strings.Contains(err.Error(), "client deadline has already passed") {
+		t.Errorf("expected a passed deadline to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,36 @@
+package mcpserver
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Tool error modes: how a failed tool call reaches the MCP client
+const (
+	toolErrorsStrict  = "strict"  // As a JSON-RPC error response (the default)
+	toolErrorsLenient = "lenient" // As a tool result flagged isError, which the model reads like any output
+)
+
+// toolErrorModes returns the accepted tool error modes
+func toolErrorModes() []string {
+	return []string{toolErrorsStrict, toolErrorsLenient}
+}
+
+// lenientErrorMiddleware turns every failed call, whether rejected by
+// validation, policy or DefectDojo, into an error tool result. Some clients
+// show protocol errors to the user and never to the model; an error result
+// lets the agent read what went wrong and correct its call. Added first, it
+// wraps every other middleware, so all tools fail the same way.
+func lenientErrorMiddleware() server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			result, err := next(ctx, request)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			return result, nil
+		}
+	}
+}
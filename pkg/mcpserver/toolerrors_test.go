@@ -0,0 +1,43 @@
+package mcpserver
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+func TestToolErrors(t *testing.T) {
+	mock := &MockDefectDojoClient{
+		GetFindingDetailFunc: func(ctx context.Context, findingID int) (*types.Finding, error) {
+			return nil, errors.New("connection refused")
+		},
+	}
+
+	strict := newServer(&Config{}, mock)
+	if _, err := callTool(t, strict, toolFindingDetail, map[string]any{"finding_id": 0}); err == nil || !strings.Contains(err.Error(), "finding_id must be at least 1") {
+		t.Errorf("expected a protocol error in strict mode, got %v", err)
+	}
+
+	lenient := newServer(&Config{Server: ServerConfig{ToolErrors: toolErrorsLenient}}, mock)
+	for _, tc := range []struct {
+		args map[string]any
+		want string
+	}{
+		{map[string]any{"finding_id": 0}, "finding_id must be at least 1"},
+		{map[string]any{"finding_id": 5}, "error retrieving finding 5"},
+	} {
+		result, err := callTool(t, lenient, toolFindingDetail, tc.args)
+		if err != nil {
+			t.Fatalf("expected an error result in lenient mode, got %v", err)
+		}
+		if text := resultText(result); !result.IsError || !strings.Contains(text, tc.want) || !strings.Contains(text, "request_id: ") {
+			t.Errorf("expected an error result with %q and the request ID, got %v %q", tc.want, result.IsError, text)
+		}
+	}
+	if usage := lenient.stats.snapshot(); len(usage) != 1 || usage[0].Errors != 2 {
+		t.Errorf("expected both failures counted, got %+v", usage)
+	}
+}
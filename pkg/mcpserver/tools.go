@@ -0,0 +1,450 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/brduru/mcp-defect-dojo/internal/defectdojo"
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+// Available MCP Tools:
+//
+// The DefectDojo MCP server provides the following tools for AI agents:
+//
+// - defectdojo_health_check: Test connectivity to DefectDojo instance
+//   Reports reachability, latency, the authenticated user and DefectDojo version
+//
+// - get_defectdojo_findings: Query vulnerability findings with filters
+//   Supports pagination, severity filtering, and active/inactive status
+//
+// - get_finding_detail: Get comprehensive details for a specific finding
+//   Returns full vulnerability information including CVSS scores and descriptions
+//
+// - mark_finding_false_positive: Mark findings as false positives
+//   Requires justification and supports additional notes for audit trail
+//
+// - clear_false_positive: Reverse a false positive decision
+//   Requires a reason, recorded as a note, and reactivates the finding by default
+//
+// - change_finding_severity: Re-grade a finding
+//   Requires a justification, recorded as a note; the write policy may hold downgrades for approval
+//
+// - assign_finding: Assign a finding to a DefectDojo user by login name
+//   Usernames are resolved through the users API and cached; "me" is the API token owner
+//
+// - add_note_to_findings: Add the same note to several findings at once
+//   Notes are posted concurrently; partial failures name the findings affected
+//
+// - invalidate_reference_cache: Drop cached product/engagement/test names
+//   Use after renaming or moving objects in DefectDojo
+//
+// - list_saved_queries: List the operator-defined named findings queries
+//
+// - run_saved_query: Run a named findings query
+//   Filters are fixed by the operator; paging and output options can be overridden
+//
+// - list_pending_actions: List write operations waiting for human approval
+//   Only meaningful in approval mode; humans decide through ApprovalHandler
+//
+// - get_recent_events: Read DefectDojo webhook notifications received by the server
+//   New events are also pushed to clients as notifications/defectdojo/event
+//
+// - get_new_findings_since_last_check: Digest of new and changed findings
+//   The server polls in the background and keeps the watermark between calls
+//
+// - create_product: Create a product, the first step of onboarding an application
+//   The product type is given by name; continue with create_engagement and import_sarif
+//
+// - create_engagement: Create an engagement in a product to import scans into
+//   Target dates default to today and a week later
+//
+// - import_sarif: Import a SARIF report, one DefectDojo test per run
+//   Reports per-run import statistics; agents need not know DefectDojo scan types
+//
+// - find_sbom_component_findings: Which SBOM components have open findings
+//   Accepts a CycloneDX SBOM or package URLs; components are queried concurrently
+//
+// - get_mttr_metrics: Mean, median and p90 time to remediate over a date range
+//   Per severity, per product and overall, from the mitigated findings' timestamps
+//
+// - create_issue_from_finding: File a GitHub or GitLab issue for a finding
+//   The issue URL is stored as finding metadata so a finding is filed only once
+//
+// - get_expiring_risk_acceptances: Risk acceptances expiring within N days
+//   Lists each acceptance's owner and accepted findings so they can be reviewed in time
+//
+// - get_engagement_overview: Engagements in progress and starting soon
+//   Shows leads, target dates and open finding counts, counted concurrently per engagement
+//
+// - check_engagement_readiness: Checklist to run before closing an engagement
+//   Tests, imported scans, unreviewed Critical findings and undecided findings
+//
+// - get_findings_by_host: Active findings grouped by endpoint host
+//   Per-host severity counts from the endpoint statuses, most affected hosts first
+//
+// - get_stale_findings: Active findings open for N days or more
+//   Counted server-side in 30/60/90/180+ day age buckets with the most severe of each
+//
+// - get_import_summary: What the last scan import into a test changed
+//   Compares with earlier imports and flags empty or unusually small reports
+//
+// - get_defectdojo_system_info: How the instance is configured
+//   Deduplication, SLAs, disclaimers and the announcement, with guidance for agents
+//
+// - pin_findings / get_pinned_findings / clear_pins: Per-session working set
+//   Agents refer to pinned findings by position; each MCP session has its own set
+//
+// - save_query_result: Save a findings query result under a name in the session
+//   Served as defectdojo://session/result/{name}; usable as saved_result by batch tools
+//
+// - export_findings: Export up to 50,000 findings of a query in chunks
+//   Returns links to defectdojo://session/export/{name}/{chunk} instead of the findings
+
+// getFindingDetail handles get_finding_detail
+func (s *Server) getFindingDetail(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	findingID, err := request.RequireInt("finding_id")
+	if err != nil {
+		return nil, fmt.Errorf("invalid finding_id: %w", err)
+	}
+
+	// With include_context, the test arrives with the finding instead of in a second request
+	includeContext := request.GetBool("include_context", false)
+	var prefetch []string
+	if includeContext {
+		prefetch = []string{types.PrefetchTest}
+	}
+	detail, err := s.ddClient.GetFindingDetailPrefetch(ctx, findingID, prefetch)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving finding %d: %w", findingID, err)
+	}
+	sanitized := s.sanitizedFinding(detail.Finding)
+	finding := &sanitized
+
+	opts := formatOptions{
+		format:        s.outputFormat(request),
+		maxFieldChars: request.GetInt("max_field_chars", s.config.Output.MaxFieldChars),
+		links:         s.links,
+		html:          s.config.Output.HTMLContent,
+		severities:    s.severity,
+		text:          s.outputText(request),
+	}
+	// Names and exploitation data are best effort: the finding is still worth returning without them
+	var partial partialResults
+	if includeContext {
+		opts.contexts = s.resolveContexts(ctx, []types.Finding{*finding}, detail.Prefetch, &partial)
+	}
+	intel, intelErr := s.lookupCVEIntel(ctx, *finding)
+	if intelErr != nil {
+		partial.warnf("CVE enrichment incomplete: %v", intelErr)
+	}
+	opts.intel = intel
+	opts.warnings = partial.list()
+
+	output, err := renderFindingDetail(finding, opts)
+	if err != nil {
+		return nil, err
+	}
+	return mcp.NewToolResultText(output), nil
+}
+
+// markFalsePositive handles mark_finding_false_positive
+func (s *Server) markFalsePositive(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	findingID, err := request.RequireInt("finding_id")
+	if err != nil {
+		return nil, fmt.Errorf("invalid finding_id: %w", err)
+	}
+
+	justification, err := request.RequireString("justification")
+	if err != nil {
+		return nil, fmt.Errorf("invalid justification: %w", err)
+	}
+
+	notes := request.GetString("notes", "")
+
+	verified := optionalBool(request, "verified")
+	if verified != nil && *verified {
+		return nil, fmt.Errorf("invalid verified: DefectDojo does not allow a false positive to be verified, omit it or pass false")
+	}
+
+	ifUnmodifiedSince, err := optionalTimestamp(request, "if_unmodified_since")
+	if err != nil {
+		return nil, err
+	}
+
+	fpRequest := types.FalsePositiveRequest{
+		IsFalsePositive:   true,
+		Justification:     justification,
+		Notes:             notes,
+		AlsoDeactivate:    request.GetBool("also_deactivate", false),
+		Verified:          verified,
+		IfUnmodifiedSince: ifUnmodifiedSince,
+	}
+
+	response, err := s.ddClient.MarkFalsePositive(ctx, findingID, fpRequest)
+	if err != nil {
+		return nil, fmt.Errorf("error marking finding %d as false positive: %w", findingID, err)
+	}
+
+	result := fmt.Sprintf("Successfully marked finding %d as false positive:\n\n", response.ID)
+	result += fmt.Sprintf("False Positive: %t\n", response.FalseP)
+	result += fmt.Sprintf("Active: %t\n", response.Active)
+	result += fmt.Sprintf("Verified: %t\n", response.Verified)
+	result += fmt.Sprintf("Justification: %s\n", response.Justification)
+	if response.Notes != "" {
+		result += fmt.Sprintf("Notes: %s\n", response.Notes)
+	}
+	if response.NoteID != 0 {
+		result += fmt.Sprintf("Recorded as note ID: %d\n", response.NoteID)
+	}
+	if response.Message != "" {
+		result += fmt.Sprintf("Message: %s\n", response.Message)
+	}
+
+	return mcp.NewToolResultText(result), nil
+}
+
+// clearFalsePositive handles clear_false_positive
+func (s *Server) clearFalsePositive(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	findingID, err := request.RequireInt("finding_id")
+	if err != nil {
+		return nil, fmt.Errorf("invalid finding_id: %w", err)
+	}
+
+	justification, err := request.RequireString("justification")
+	if err != nil {
+		return nil, fmt.Errorf("invalid justification: %w", err)
+	}
+
+	ifUnmodifiedSince, err := optionalTimestamp(request, "if_unmodified_since")
+	if err != nil {
+		return nil, err
+	}
+
+	fpRequest := types.FalsePositiveRequest{
+		IsFalsePositive:   false,
+		Justification:     justification,
+		Notes:             request.GetString("notes", ""),
+		Reactivate:        request.GetBool("reactivate", true),
+		Verified:          optionalBool(request, "verified"),
+		IfUnmodifiedSince: ifUnmodifiedSince,
+	}
+
+	response, err := s.ddClient.MarkFalsePositive(ctx, findingID, fpRequest)
+	if err != nil {
+		return nil, fmt.Errorf("error clearing false positive on finding %d: %w", findingID, err)
+	}
+
+	result := fmt.Sprintf("Successfully cleared false positive on finding %d:\n\n", response.ID)
+	result += fmt.Sprintf("False Positive: %t\n", response.FalseP)
+	result += fmt.Sprintf("Active: %t\n", response.Active)
+	result += fmt.Sprintf("Verified: %t\n", response.Verified)
+	result += fmt.Sprintf("Reason: %s\n", response.Justification)
+	if response.NoteID != 0 {
+		result += fmt.Sprintf("Recorded as note ID: %d\n", response.NoteID)
+	}
+
+	return mcp.NewToolResultText(result), nil
+}
+
+// getFindings handles get_defectdojo_findings. Saved queries run through it
+// too, so a vetted query behaves exactly like the equivalent direct call.
+func (s *Server) getFindings(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	query, err := s.parseFindingsQuery(request)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.assigneeFilter(ctx, request, &query.filter); err != nil {
+		return nil, err
+	}
+	if err := s.environmentFilter(ctx, request, &query.filter); err != nil {
+		return nil, err
+	}
+
+	// Instances supporting related_fields nest every name in the findings;
+	// the prefetched tests back the lookups of any name they leave out
+	includeContext := request.GetBool("include_context", false)
+	if includeContext {
+		query.filter.Prefetch = []string{types.PrefetchTest}
+		query.filter.RelatedFields = s.ddClient.Supports(ctx, defectdojo.FeatureRelatedFields) == nil
+	}
+	response, err := s.runFindingsQuery(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving findings: %w", err)
+	}
+
+	opts := s.listFormatOptions(request)
+	if includeContext {
+		var partial partialResults
+		opts.contexts = s.resolveContexts(ctx, response.Results, response.Prefetch, &partial)
+		opts.warnings = partial.list()
+	}
+
+	output, err := renderFindingsList(response, paginate(response, query.filter.Offset, query.filter.Limit), opts)
+	if err != nil {
+		return nil, err
+	}
+	return mcp.NewToolResultText(output), nil
+}
+
+// findingsQuery is the DefectDojo query described by get_defectdojo_findings arguments
+type findingsQuery struct {
+	filter      types.FindingsFilter
+	minSeverity string // Set instead of filter.Severity for at-or-above queries
+}
+
+// parseFindingsQuery builds the findings filter from get_defectdojo_findings arguments
+func (s *Server) parseFindingsQuery(request mcp.CallToolRequest) (findingsQuery, error) {
+	filter := types.FindingsFilter{
+		Limit:      request.GetInt("limit", s.listLimit()),
+		Offset:     request.GetInt("offset", 0),
+		Active:     optionalBool(request, "active"),
+		ActiveOnly: request.GetBool("active_only", true),
+	}
+
+	severity, err := s.severityArgument(request, "severity")
+	if err != nil {
+		return findingsQuery{}, err
+	}
+	filter.Severity = severity
+
+	if test := request.GetInt("test", 0); test != 0 {
+		filter.Test = &test
+	}
+	if product := request.GetInt("product", 0); product != 0 {
+		filter.Product = &product
+	}
+	filter.RiskAccepted = optionalBool(request, "risk_accepted")
+	filter.IsMitigated = optionalBool(request, "is_mitigated")
+	filter.Duplicate = optionalBool(request, "duplicate")
+	filter.Tags = request.GetStringSlice("tags", nil)
+	filter.NotTags = request.GetStringSlice("not_tags", nil)
+	filter.Reporter = request.GetIntSlice("reporter", nil)
+	filter.FoundBy = request.GetIntSlice("found_by", nil)
+
+	if sortBy := request.GetString("sort_by", ""); sortBy != "" {
+		if !types.IsValidOrdering(sortBy) {
+			return findingsQuery{}, fmt.Errorf("invalid sort_by %q: allowed fields are %s (prefix with '-' for descending)", sortBy, strings.Join(types.OrderingFields(), ", "))
+		}
+		filter.Ordering = sortBy
+	}
+
+	minSeverity, err := s.severityArgument(request, "min_severity")
+	if err != nil {
+		return findingsQuery{}, err
+	}
+	if minSeverity != "" && filter.Severity != "" {
+		return findingsQuery{}, fmt.Errorf("severity and min_severity cannot be combined")
+	}
+	return findingsQuery{filter: filter, minSeverity: minSeverity}, nil
+}
+
+// runFindingsQuery fetches one page of findings for a parsed query
+func (s *Server) runFindingsQuery(ctx context.Context, query findingsQuery) (*types.FindingsResponse, error) {
+	if query.minSeverity != "" {
+		return s.getFindingsAtOrAbove(ctx, query.filter, query.minSeverity)
+	}
+	return s.findingsPage(ctx, query.filter)
+}
+
+// Page sizes used when the configuration sets none
+const (
+	defaultListLimit    = 10  // Findings per list page when the caller passes no limit
+	defaultMaxListLimit = 100 // Largest limit a caller may pass
+)
+
+// listLimit returns the configured default page size for findings lists,
+// never more than the maximum
+func (s *Server) listLimit() int {
+	limit := defaultListLimit
+	if s.config.Output.ListLimit > 0 {
+		limit = s.config.Output.ListLimit
+	}
+	return min(limit, s.maxListLimit())
+}
+
+// maxListLimit returns the largest limit argument a tool call may pass
+func (s *Server) maxListLimit() int {
+	if s.config.Output.MaxListLimit > 0 {
+		return s.config.Output.MaxListLimit
+	}
+	return defaultMaxListLimit
+}
+
+// listFormatOptions resolves findings list verbosity from the call arguments,
+// falling back to the server's output configuration. Full detail defaults to
+// the per-field limit rather than the shorter list description limit.
+func (s *Server) listFormatOptions(request mcp.CallToolRequest) formatOptions {
+	level := strings.ToLower(request.GetString("detail_level", s.config.Output.DetailLevel))
+	if level == "" {
+		level = detailNormal
+	}
+	maxDescription := s.config.Output.MaxDescriptionChars
+	if level == detailFull {
+		maxDescription = s.config.Output.MaxFieldChars
+	}
+	return formatOptions{
+		format:              s.outputFormat(request),
+		detailLevel:         level,
+		maxDescriptionChars: request.GetInt("max_description_chars", maxDescription),
+		groupRepeated:       request.GetBool("group_repeated", false),
+		links:               s.links,
+		html:                s.config.Output.HTMLContent,
+		severities:          s.severity,
+		text:                s.outputText(request),
+	}
+}
+
+// outputFormat returns the requested output format, defaulting to the server setting
+func (s *Server) outputFormat(request mcp.CallToolRequest) string {
+	return strings.ToLower(request.GetString("format", s.config.Output.Format))
+}
+
+// outputText returns the labels and headings of the requested output
+// language, defaulting to the server setting
+func (s *Server) outputText(request mcp.CallToolRequest) catalog {
+	return catalogs[strings.ToLower(request.GetString("language", s.config.Output.Language))]
+}
+
+// severityArgument returns the named severity argument in DefectDojo's canonical
+// capitalization, or "" when omitted. Organization severity labels are accepted
+// too. Unrecognized values are rejected rather than silently dropped, so the
+// agent never receives unfiltered results by mistake.
+func (s *Server) severityArgument(request mcp.CallToolRequest, name string) (string, error) {
+	value := request.GetString(name, "")
+	if value == "" {
+		return "", nil
+	}
+	severity, ok := s.severity.normalize(value)
+	if !ok {
+		return "", fmt.Errorf("invalid %s %q: must be one of %s", name, value, strings.Join(s.severity.accepted(), ", "))
+	}
+	return severity, nil
+}
+
+// optionalBool returns the named boolean argument, or nil when the caller omitted it.
+func optionalBool(request mcp.CallToolRequest, name string) *bool {
+	if _, ok := request.GetArguments()[name]; !ok {
+		return nil
+	}
+	value := request.GetBool(name, false)
+	return &value
+}
+
+// optionalTimestamp parses the named timestamp argument, or returns the zero
+// time when the caller omitted it.
+func optionalTimestamp(request mcp.CallToolRequest, name string) (time.Time, error) {
+	value := request.GetString(name, "")
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := types.ParseTimestamp(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s: %w", name, err)
+	}
+	return t, nil
+}
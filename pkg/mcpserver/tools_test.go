@@ -0,0 +1,487 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brduru/mcp-defect-dojo/internal/defectdojo"
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+func TestOfflineModeServesFixtures(t *testing.T) {
+	s := NewServer(&Config{DefectDojo: DefectDojoConfig{Mode: "offline"}, Server: ServerConfig{Name: "test", Version: "1.0"}})
+
+	result, err := callTool(t, s, "get_defectdojo_findings", map[string]any{"severity": "Critical", "include_context": true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := resultText(result); !strings.Contains(text, "SQL Injection") || !strings.Contains(text, "Product: Payments API / Engagement: Q3 Pentest") {
+		t.Errorf("expected demo fixture findings, got:\n%s", text)
+	}
+
+	result, err = callTool(t, s, "defectdojo_health_check", map[string]any{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := resultText(result); !strings.Contains(text, "fixture://built-in demo data") {
+		t.Errorf("expected offline health status, got:\n%s", text)
+	}
+}
+
+func TestHealthCheckToolReportsStatus(t *testing.T) {
+	t.Run("healthy", func(t *testing.T) {
+		mock := &MockDefectDojoClient{
+			CheckHealthFunc: func(ctx context.Context) *types.HealthStatus {
+				return &types.HealthStatus{URL: "https://dd.example", Reachable: true, Authenticated: true, User: "bot", Version: "2.38.1", Latency: 42 * time.Millisecond}
+			},
+		}
+		result, err := callTool(t, newServer(&Config{}, mock), "defectdojo_health_check", map[string]any{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		text := resultText(result)
+		for _, want := range []string{"HEALTHY", "Reachable: yes (latency 42ms)", "Authenticated: yes (as bot)", "DefectDojo Version: 2.38.1"} {
+			if !strings.Contains(text, want) {
+				t.Errorf("expected %q in output, got:\n%s", want, text)
+			}
+		}
+	})
+
+	t.Run("token rejected", func(t *testing.T) {
+		mock := &MockDefectDojoClient{
+			CheckHealthFunc: func(ctx context.Context) *types.HealthStatus {
+				return &types.HealthStatus{URL: "https://dd.example", Reachable: true, Error: "Authentication failed: 401"}
+			},
+		}
+		_, err := callTool(t, newServer(&Config{}, mock), "defectdojo_health_check", map[string]any{})
+		if err == nil {
+			t.Fatal("expected an error for a rejected token")
+		}
+		if !strings.Contains(err.Error(), "Reachable: yes") || !strings.Contains(err.Error(), "Authenticated: no") {
+			t.Errorf("expected structured status in error, got %v", err)
+		}
+	})
+}
+
+func TestFindingDetailMaxFieldChars(t *testing.T) {
+	mock := &MockDefectDojoClient{
+		GetFindingDetailFunc: func(ctx context.Context, findingID int) (*types.Finding, error) {
+			return &types.Finding{ID: findingID, Title: "Verbose", Severity: "High", Mitigation: strings.Repeat("m", 30)}, nil
+		},
+	}
+	s := newServer(&Config{Output: OutputConfig{MaxFieldChars: 20}}, mock)
+
+	t.Run("server default applies", func(t *testing.T) {
+		result, err := callTool(t, s, "get_finding_detail", map[string]any{"finding_id": 1})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if text := resultText(result); !strings.Contains(text, "[truncated 10 characters]") {
+			t.Errorf("expected mitigation truncated to server default, got:\n%s", text)
+		}
+	})
+
+	t.Run("argument overrides default", func(t *testing.T) {
+		result, err := callTool(t, s, "get_finding_detail", map[string]any{"finding_id": 1, "max_field_chars": 0})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if text := resultText(result); strings.Contains(text, "truncated") || !strings.Contains(text, strings.Repeat("m", 30)) {
+			t.Errorf("expected full mitigation with max_field_chars=0, got:\n%s", text)
+		}
+	})
+}
+
+func TestFindingsOutputVerbosity(t *testing.T) {
+	var got types.FindingsFilter
+	mock := &MockDefectDojoClient{
+		GetFindingsFunc: func(ctx context.Context, filter types.FindingsFilter) (*types.FindingsResponse, error) {
+			got = filter
+			return &types.FindingsResponse{Count: 1, Results: []types.Finding{
+				{ID: 1, Title: "Verbose", Severity: "High", Description: strings.Repeat("d", 100)},
+			}}, nil
+		},
+	}
+	s := newServer(&Config{Output: OutputConfig{MaxDescriptionChars: 30, ListLimit: 25}}, mock)
+
+	result, err := callTool(t, s, "get_defectdojo_findings", map[string]any{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Limit != 25 {
+		t.Errorf("expected configured list limit 25, got %d", got.Limit)
+	}
+	if text := resultText(result); !strings.Contains(text, "[truncated 70 characters]") {
+		t.Errorf("expected description truncated to server default, got:\n%s", text)
+	}
+
+	result, err = callTool(t, s, "get_defectdojo_findings", map[string]any{"detail_level": "summary"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := resultText(result); strings.Contains(text, "Description") {
+		t.Errorf("expected summary without description, got:\n%s", text)
+	}
+
+	result, err = callTool(t, s, "get_defectdojo_findings", map[string]any{"max_description_chars": 0})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := resultText(result); strings.Contains(text, "truncated") {
+		t.Errorf("expected full description with max_description_chars=0, got:\n%s", text)
+	}
+
+	if _, err := callTool(t, s, "get_defectdojo_findings", map[string]any{"detail_level": "verbose"}); err == nil {
+		t.Error("expected unknown detail_level to be rejected")
+	}
+
+	if _, err := callTool(t, newServer(&Config{}, mock), "get_defectdojo_findings", map[string]any{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Limit != defaultListLimit {
+		t.Errorf("expected fallback list limit %d, got %d", defaultListLimit, got.Limit)
+	}
+}
+
+func TestFormatArgument(t *testing.T) {
+	mock := &MockDefectDojoClient{
+		GetFindingsFunc: func(ctx context.Context, filter types.FindingsFilter) (*types.FindingsResponse, error) {
+			return &types.FindingsResponse{Count: 1, Results: []types.Finding{{ID: 1, Title: "XSS", Severity: "High", Active: true}}}, nil
+		},
+		GetFindingDetailFunc: func(ctx context.Context, findingID int) (*types.Finding, error) {
+			return &types.Finding{ID: findingID, Title: "XSS", Severity: "High"}, nil
+		},
+	}
+
+	t.Run("argument selects markdown", func(t *testing.T) {
+		s := newServer(&Config{}, mock)
+		result, err := callTool(t, s, "get_defectdojo_findings", map[string]any{"format": "markdown"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if text := resultText(result); !strings.Contains(text, "| 1 | High | XSS | Active | - |") {
+			t.Errorf("expected markdown table, got:\n%s", text)
+		}
+
+		result, err = callTool(t, s, "get_finding_detail", map[string]any{"finding_id": 1, "format": "markdown"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if text := resultText(result); !strings.Contains(text, "## Finding 1: XSS") {
+			t.Errorf("expected markdown detail, got:\n%s", text)
+		}
+	})
+
+	t.Run("argument selects json", func(t *testing.T) {
+		result, err := callTool(t, newServer(&Config{}, mock), "get_finding_detail", map[string]any{"finding_id": 1, "format": "json"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var finding types.Finding
+		if err := json.Unmarshal([]byte(resultText(result)), &finding); err != nil || finding.Title != "XSS" {
+			t.Errorf("expected finding JSON, got %v:\n%s", err, resultText(result))
+		}
+	})
+
+	t.Run("server default and override", func(t *testing.T) {
+		s := newServer(&Config{Output: OutputConfig{Format: "markdown"}}, mock)
+		result, err := callTool(t, s, "get_defectdojo_findings", map[string]any{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if text := resultText(result); !strings.Contains(text, "| ID | Severity |") {
+			t.Errorf("expected markdown from server default, got:\n%s", text)
+		}
+
+		result, err = callTool(t, s, "get_defectdojo_findings", map[string]any{"format": "text"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if text := resultText(result); !strings.Contains(text, "1. [High] XSS (ID: 1)") {
+			t.Errorf("expected plain text when requested, got:\n%s", text)
+		}
+	})
+}
+
+func TestFindingsPaginationCursor(t *testing.T) {
+	next := "https://dd.example/api/v2/findings/?limit=2&offset=4"
+	mock := &MockDefectDojoClient{
+		GetFindingsFunc: func(ctx context.Context, filter types.FindingsFilter) (*types.FindingsResponse, error) {
+			return &types.FindingsResponse{Count: 9, Next: &next, Results: []types.Finding{{ID: 3}, {ID: 4}}}, nil
+		},
+	}
+	s := newServer(&Config{}, mock)
+	args := map[string]any{"limit": 2, "offset": 2}
+
+	result, err := callTool(t, s, "get_defectdojo_findings", args)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := resultText(result); !strings.Contains(text, "has_more: true, next_offset: 4") {
+		t.Errorf("expected cursor line in text output, got:\n%s", text)
+	}
+
+	args["format"] = "json"
+	result, err = callTool(t, s, "get_defectdojo_findings", args)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var page struct {
+		Count   int             `json:"count"`
+		Results []types.Finding `json:"results"`
+		Cursor  pageCursor      `json:"cursor"`
+	}
+	if err := json.Unmarshal([]byte(resultText(result)), &page); err != nil {
+		t.Fatalf("invalid JSON output: %v\n%s", err, resultText(result))
+	}
+	if page.Count != 9 || len(page.Results) != 2 || !page.Cursor.HasMore || page.Cursor.NextOffset == nil || *page.Cursor.NextOffset != 4 || page.Cursor.Offset != 2 {
+		t.Errorf("unexpected JSON page: %+v", page)
+	}
+}
+
+func TestFindingsStatusFilterArguments(t *testing.T) {
+	var got types.FindingsFilter
+	mock := &MockDefectDojoClient{
+		GetFindingsFunc: func(ctx context.Context, filter types.FindingsFilter) (*types.FindingsResponse, error) {
+			got = filter
+			return &types.FindingsResponse{}, nil
+		},
+	}
+	s := newServer(&Config{}, mock)
+
+	if _, err := callTool(t, s, "get_defectdojo_findings", map[string]any{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.RiskAccepted != nil || got.IsMitigated != nil || got.Duplicate != nil {
+		t.Errorf("expected omitted filters to stay nil, got %+v", got)
+	}
+
+	args := map[string]any{"risk_accepted": false, "is_mitigated": true, "duplicate": false}
+	if _, err := callTool(t, s, "get_defectdojo_findings", args); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.RiskAccepted == nil || *got.RiskAccepted || got.IsMitigated == nil || !*got.IsMitigated || got.Duplicate == nil || *got.Duplicate {
+		t.Errorf("filters not passed through: %+v", got)
+	}
+}
+
+func TestFindingsSortByArgument(t *testing.T) {
+	var got types.FindingsFilter
+	mock := &MockDefectDojoClient{
+		GetFindingsFunc: func(ctx context.Context, filter types.FindingsFilter) (*types.FindingsResponse, error) {
+			got = filter
+			return &types.FindingsResponse{}, nil
+		},
+	}
+	s := newServer(&Config{}, mock)
+
+	if _, err := callTool(t, s, "get_defectdojo_findings", map[string]any{"sort_by": "-date"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Ordering != "-date" {
+		t.Errorf("expected ordering -date, got %q", got.Ordering)
+	}
+
+	_, err := callTool(t, s, "get_defectdojo_findings", map[string]any{"sort_by": "secret_field"})
+	if err == nil || !strings.Contains(err.Error(), "allowed fields") {
+		t.Errorf("expected validation error listing allowed fields, got %v", err)
+	}
+}
+
+func TestFindingsActiveArgument(t *testing.T) {
+	var got types.FindingsFilter
+	mock := &MockDefectDojoClient{
+		GetFindingsFunc: func(ctx context.Context, filter types.FindingsFilter) (*types.FindingsResponse, error) {
+			got = filter
+			return &types.FindingsResponse{}, nil
+		},
+	}
+	s := newServer(&Config{}, mock)
+
+	tests := []struct {
+		name string
+		args map[string]any
+		want *bool
+	}{
+		{"default is active only", map[string]any{}, boolPtr(true)},
+		{"active_only false returns all", map[string]any{"active_only": false}, nil},
+		{"closed findings", map[string]any{"active": false}, boolPtr(false)},
+		{"active overrides active_only", map[string]any{"active": false, "active_only": true}, boolPtr(false)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := callTool(t, s, "get_defectdojo_findings", tt.args); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			active := got.ActiveFilter()
+			if (active == nil) != (tt.want == nil) || (active != nil && *active != *tt.want) {
+				t.Errorf("ActiveFilter() = %v, want %v", active, tt.want)
+			}
+		})
+	}
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+func TestFindingsTagArguments(t *testing.T) {
+	var got types.FindingsFilter
+	mock := &MockDefectDojoClient{
+		GetFindingsFunc: func(ctx context.Context, filter types.FindingsFilter) (*types.FindingsResponse, error) {
+			got = filter
+			return &types.FindingsResponse{Count: 1, Results: []types.Finding{{ID: 1, Title: "Tagged", Severity: "Low", Tags: []string{"triage"}}}}, nil
+		},
+	}
+	s := newServer(&Config{}, mock)
+
+	args := map[string]any{"tags": []any{"triage", "pci"}, "not_tags": []any{"wontfix"}, "reporter": []any{3}, "found_by": []any{12, 14}}
+	result, err := callTool(t, s, "get_defectdojo_findings", args)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if strings.Join(got.Tags, ",") != "triage,pci" || strings.Join(got.NotTags, ",") != "wontfix" {
+		t.Errorf("tags not passed through: %+v", got)
+	}
+	if len(got.Reporter) != 1 || got.Reporter[0] != 3 || len(got.FoundBy) != 2 || got.FoundBy[1] != 14 {
+		t.Errorf("reporter/found_by not passed through: %+v", got)
+	}
+	if text := resultText(result); !strings.Contains(text, "Tags: triage") {
+		t.Errorf("expected tags in output, got:\n%s", text)
+	}
+}
+
+func TestFindingsSeverityNormalization(t *testing.T) {
+	var got types.FindingsFilter
+	mock := &MockDefectDojoClient{
+		GetFindingsFunc: func(ctx context.Context, filter types.FindingsFilter) (*types.FindingsResponse, error) {
+			got = filter
+			return &types.FindingsResponse{}, nil
+		},
+	}
+	s := newServer(&Config{}, mock)
+
+	if _, err := callTool(t, s, "get_defectdojo_findings", map[string]any{"severity": "CRITICAL"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Severity != "Critical" {
+		t.Errorf("expected severity normalized to Critical, got %q", got.Severity)
+	}
+
+	if _, err := callTool(t, s, "get_defectdojo_findings", map[string]any{"min_severity": "high"}); err != nil {
+		t.Fatalf("unexpected error for lowercase min_severity: %v", err)
+	}
+
+	for _, name := range []string{"severity", "min_severity"} {
+		_, err := callTool(t, s, "get_defectdojo_findings", map[string]any{name: "urgent"})
+		if err == nil || !strings.Contains(err.Error(), "Info, Low, Medium, High, Critical") {
+			t.Errorf("expected %s error listing valid values, got %v", name, err)
+		}
+	}
+}
+
+func TestMarkFalsePositiveArguments(t *testing.T) {
+	var got types.FalsePositiveRequest
+	mock := &MockDefectDojoClient{
+		MarkFalsePositiveFunc: func(ctx context.Context, findingID int, request types.FalsePositiveRequest) (*types.FalsePositiveResponse, error) {
+			got = request
+			return &types.FalsePositiveResponse{ID: findingID, FalseP: true, Active: !request.AlsoDeactivate, Justification: request.Justification, NoteID: 42}, nil
+		},
+	}
+	s := newServer(&Config{}, mock)
+
+	result, err := callTool(t, s, "mark_finding_false_positive", map[string]any{"finding_id": 5, "justification": "test data"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.AlsoDeactivate || got.Verified != nil {
+		t.Errorf("expected active and verified untouched by default, got %+v", got)
+	}
+	text := resultText(result)
+	for _, want := range []string{"Active: true", "Recorded as note ID: 42"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("output missing %q:\n%s", want, text)
+		}
+	}
+
+	args := map[string]any{"finding_id": 5, "justification": "test data", "also_deactivate": true, "verified": false}
+	if _, err := callTool(t, s, "mark_finding_false_positive", args); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !got.AlsoDeactivate || got.Verified == nil || *got.Verified {
+		t.Errorf("expected also_deactivate=true and verified=false, got %+v", got)
+	}
+
+	got = types.FalsePositiveRequest{}
+	args["verified"] = true
+	if _, err := callTool(t, s, "mark_finding_false_positive", args); err == nil || !strings.Contains(err.Error(), "verified") {
+		t.Errorf("expected verified=true to be refused, got %v", err)
+	}
+	if got.Justification != "" {
+		t.Error("a refused call must not reach DefectDojo")
+	}
+}
+
+func TestClearFalsePositive(t *testing.T) {
+	var got types.FalsePositiveRequest
+	mock := &MockDefectDojoClient{
+		MarkFalsePositiveFunc: func(ctx context.Context, findingID int, request types.FalsePositiveRequest) (*types.FalsePositiveResponse, error) {
+			got = request
+			return &types.FalsePositiveResponse{ID: findingID, FalseP: request.IsFalsePositive, Active: request.Reactivate, Justification: request.Justification}, nil
+		},
+	}
+	var audited []AuditRecord
+	s := newServer(&Config{Audit: AuditConfig{Logger: AuditLoggerFunc(func(ctx context.Context, record AuditRecord) error {
+		audited = append(audited, record)
+		return nil
+	})}}, mock)
+
+	result, err := callTool(t, s, "clear_false_positive", map[string]any{"finding_id": 8, "justification": "exploit confirmed"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.IsFalsePositive || !got.Reactivate {
+		t.Errorf("expected clearing request with reactivation, got %+v", got)
+	}
+	if text := resultText(result); !strings.Contains(text, "False Positive: false") || !strings.Contains(text, "Active: true") {
+		t.Errorf("unexpected output:\n%s", text)
+	}
+	if len(audited) != 1 || audited[0].Tool != "clear_false_positive" || audited[0].FindingID != 8 {
+		t.Errorf("expected clear_false_positive to be audited, got %+v", audited)
+	}
+}
+
+func TestIfUnmodifiedSince(t *testing.T) {
+	fixtures, err := defectdojo.NewFixtureClient("")
+	if err != nil {
+		t.Fatalf("NewFixtureClient() error = %v", err)
+	}
+	s := newServer(&Config{}, fixtures)
+	readAt := time.Now().Add(-time.Minute).Format(time.RFC3339)
+
+	if _, err := callTool(t, s, "mark_finding_false_positive", map[string]any{
+		"finding_id": 2, "justification": "test code", "if_unmodified_since": readAt,
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The timestamp read before the first write is now stale
+	_, err = callTool(t, s, "clear_false_positive", map[string]any{
+		"finding_id": 2, "justification": "reachable after all", "if_unmodified_since": readAt,
+	})
+	if err == nil || !strings.Contains(err.Error(), "conflict: finding 2 was modified") {
+		t.Errorf("expected a conflict error, got %v", err)
+	}
+
+	_, err = callTool(t, s, "clear_false_positive", map[string]any{
+		"finding_id": 2, "justification": "reachable after all", "if_unmodified_since": "yesterday",
+	})
+	if err == nil || !strings.Contains(err.Error(), "invalid if_unmodified_since") {
+		t.Errorf("expected an invalid timestamp error, got %v", err)
+	}
+}
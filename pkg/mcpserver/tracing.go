@@ -0,0 +1,60 @@
+package mcpserver
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName identifies spans created by the MCP server
+const tracerName = "github.com/brduru/mcp-defect-dojo/pkg/mcpserver"
+
+// tracingMiddleware wraps every tool call in an OpenTelemetry span.
+// Spans are recorded through the global tracer provider, so embedders control
+// export by calling otel.SetTracerProvider; without one, tracing is a no-op.
+//
+// If the MCP client supplies W3C trace context ("traceparent"/"tracestate")
+// in the request _meta, the tool span joins the caller's trace. DefectDojo
+// HTTP requests made by the handler become child spans of the tool span.
+func tracingMiddleware() server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			ctx = otel.GetTextMapPropagator().Extract(ctx, metaCarrier(request.Params.Meta))
+
+			ctx, span := otel.Tracer(tracerName).Start(ctx, "tools/call "+request.Params.Name,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(attribute.String("mcp.tool.name", request.Params.Name)),
+			)
+			defer span.End()
+
+			result, err := next(ctx, request)
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			} else if result != nil && result.IsError {
+				span.SetStatus(codes.Error, "tool returned an error result")
+			}
+			return result, err
+		}
+	}
+}
+
+// metaCarrier exposes string fields of the MCP request _meta as a propagation carrier
+func metaCarrier(meta *mcp.Meta) propagation.MapCarrier {
+	carrier := propagation.MapCarrier{}
+	if meta == nil {
+		return carrier
+	}
+	for key, value := range meta.AdditionalFields {
+		if s, ok := value.(string); ok {
+			carrier[key] = s
+		}
+	}
+	return carrier
+}
@@ -0,0 +1,85 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+func TestTracingMiddleware(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	prevProvider, prevPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer func() {
+		otel.SetTracerProvider(prevProvider)
+		otel.SetTextMapPropagator(prevPropagator)
+	}()
+
+	var gotTraceparent string
+	dojo := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotTraceparent = r.Header.Get("traceparent")
+		json.NewEncoder(w).Encode(types.Finding{ID: 5, Title: "Traced"})
+	}))
+	defer dojo.Close()
+
+	s := NewServer(&Config{DefectDojo: DefectDojoConfig{BaseURL: dojo.URL, APIVersion: "v2"}})
+
+	mcpClient, err := client.NewInProcessClient(s.GetMCPServer())
+	if err != nil {
+		t.Fatalf("NewInProcessClient() error = %v", err)
+	}
+	defer mcpClient.Close()
+	ctx := context.Background()
+	if _, err := mcpClient.Initialize(ctx, mcp.InitializeRequest{}); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+
+	const parentTraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	_, err = mcpClient.CallTool(ctx, mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name:      "get_finding_detail",
+			Arguments: map[string]any{"finding_id": 5},
+			Meta: &mcp.Meta{AdditionalFields: map[string]any{
+				"traceparent": "00-" + parentTraceID + "-00f067aa0ba902b7-01",
+			}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("CallTool() error = %v", err)
+	}
+
+	// get_finding_detail is feature gated, so the DefectDojo version is
+	// detected before the finding is read
+	spans := recorder.Ended()
+	if len(spans) != 3 {
+		t.Fatalf("expected 3 spans (version probe + HTTP + tool), got %d", len(spans))
+	}
+	httpSpan, toolSpan := spans[1], spans[2]
+
+	if toolSpan.Name() != "tools/call get_finding_detail" {
+		t.Errorf("unexpected tool span name %q", toolSpan.Name())
+	}
+	if got := toolSpan.SpanContext().TraceID().String(); got != parentTraceID {
+		t.Errorf("tool span did not join caller trace: got %s", got)
+	}
+	if httpSpan.Parent().SpanID() != toolSpan.SpanContext().SpanID() {
+		t.Error("HTTP span is not a child of the tool span")
+	}
+	if gotTraceparent == "" {
+		t.Error("expected traceparent header on DefectDojo request")
+	}
+}
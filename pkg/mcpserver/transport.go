@@ -0,0 +1,93 @@
+package mcpserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Transports Serve can run the server over
+const (
+	TransportStdio = "stdio" // Standard input/output, for MCP clients that spawn the server
+	TransportHTTP  = "http"  // Streamable HTTP at /mcp
+	TransportSSE   = "sse"   // HTTP with server-sent events: events at /sse, messages at /message
+)
+
+// transportShutdownTimeout bounds how long open HTTP sessions may take to finish
+const transportShutdownTimeout = 10 * time.Second
+
+// Serve runs the MCP server over the given transport until ctx is cancelled
+// or the transport fails. The HTTP transports listen on addr (host:port);
+// stdio ignores it.
+func (s *Server) Serve(ctx context.Context, transport, addr string) error {
+	if transport == "" || transport == TransportStdio {
+		return s.Run(ctx)
+	}
+	handler, err := s.transportHandler(transport)
+	if err != nil {
+		return err
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("listening for %s transport: %w", transport, err)
+	}
+	return serveHTTP(ctx, listener, handler)
+}
+
+// transportHandler returns the HTTP handler of an HTTP-based transport.
+// With auth tokens configured, requests must carry a known bearer token, and
+// tool calls run as the token's client.
+func (s *Server) transportHandler(transport string) (http.Handler, error) {
+	if s.authErr != nil {
+		return nil, fmt.Errorf("invalid auth configuration: %w", s.authErr)
+	}
+	var handler http.Handler
+	switch transport {
+	case TransportHTTP:
+		var opts []server.StreamableHTTPOption
+		if s.access != nil {
+			opts = append(opts, server.WithHTTPContextFunc(s.access.callerContext))
+		}
+		mux := http.NewServeMux()
+		mux.Handle("/mcp", server.NewStreamableHTTPServer(s.mcpServer, opts...))
+		handler = mux
+	case TransportSSE:
+		var opts []server.SSEOption
+		if s.access != nil {
+			opts = append(opts, server.WithSSEContextFunc(s.access.callerContext))
+		}
+		handler = server.NewSSEServer(s.mcpServer, opts...)
+	default:
+		return nil, fmt.Errorf("unknown transport %q (must be stdio, http or sse)", transport)
+	}
+	if s.access != nil {
+		handler = s.access.requireToken(handler)
+	}
+	return handler, nil
+}
+
+// serveHTTP serves handler on listener until ctx is cancelled, then shuts down gracefully
+func serveHTTP(ctx context.Context, listener net.Listener, handler http.Handler) error {
+	httpServer := &http.Server{Handler: handler, ReadHeaderTimeout: 5 * time.Second}
+	done := make(chan error, 1)
+	go func() {
+		done <- httpServer.Serve(listener)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), transportShutdownTimeout)
+		defer cancel()
+		if err := httpServer.Shutdown(shutdownCtx); err != nil && !errors.Is(err, context.DeadlineExceeded) {
+			return err
+		}
+		return nil
+	}
+}
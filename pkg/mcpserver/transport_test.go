@@ -0,0 +1,83 @@
+package mcpserver
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestHTTPTransports(t *testing.T) {
+	for _, tt := range []struct {
+		transport string
+		newClient func(base string) (*client.Client, error)
+	}{
+		{TransportHTTP, func(base string) (*client.Client, error) { return client.NewStreamableHttpClient(base + "/mcp") }},
+		{TransportSSE, func(base string) (*client.Client, error) { return client.NewSSEMCPClient(base + "/sse") }},
+	} {
+		t.Run(tt.transport, func(t *testing.T) {
+			s := newServer(&Config{}, &MockDefectDojoClient{})
+			handler, err := s.transportHandler(tt.transport)
+			if err != nil {
+				t.Fatalf("transportHandler() error = %v", err)
+			}
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatalf("Listen() error = %v", err)
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			served := make(chan error, 1)
+			go func() { served <- serveHTTP(ctx, listener, handler) }()
+
+			mcpClient, err := tt.newClient("http://" + listener.Addr().String())
+			if err != nil {
+				t.Fatalf("new client error = %v", err)
+			}
+			defer mcpClient.Close()
+			if err := mcpClient.Start(ctx); err != nil {
+				t.Fatalf("Start() error = %v", err)
+			}
+			if _, err := mcpClient.Initialize(ctx, mcp.InitializeRequest{}); err != nil {
+				t.Fatalf("Initialize() error = %v", err)
+			}
+			tools, err := mcpClient.ListTools(ctx, mcp.ListToolsRequest{})
+			if err != nil {
+				t.Fatalf("ListTools() error = %v", err)
+			}
+			if len(tools.Tools) != len(ToolDefinitions()) {
+				t.Errorf("expected %d tools, got %d", len(ToolDefinitions()), len(tools.Tools))
+			}
+
+			mcpClient.Close()
+			cancel()
+			if err := <-served; err != nil {
+				t.Errorf("serveHTTP() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestServeUnknownTransport(t *testing.T) {
+	s := newServer(&Config{}, &MockDefectDojoClient{})
+	err := s.Serve(context.Background(), "websocket", "localhost:0")
+	if err == nil || !strings.Contains(err.Error(), `unknown transport "websocket"`) {
+		t.Errorf("expected an unknown transport error, got %v", err)
+	}
+}
+
+func TestReadOnlyOmitsWriteTools(t *testing.T) {
+	s := newServer(&Config{Server: ServerConfig{ReadOnly: true}}, &MockDefectDojoClient{})
+	for tool := range writeTools {
+		if _, err := callTool(t, s, tool, map[string]any{}); err == nil {
+			t.Errorf("write tool %s callable in read-only mode", tool)
+		}
+	}
+	if _, err := callTool(t, s, toolHealthCheck, map[string]any{}); err != nil {
+		t.Errorf("expected read tools to stay registered, got %v", err)
+	}
+}
@@ -0,0 +1,213 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"math"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// integer narrows a number parameter to whole numbers (JSON Schema "integer").
+// Use it for IDs, limits and offsets so fractional values are rejected
+// instead of being truncated.
+func integer() mcp.PropertyOption {
+	return func(schema map[string]any) {
+		schema["type"] = "integer"
+	}
+}
+
+// addTool registers a tool whose arguments are validated against its input
+// schema before the handler runs. Every DefectDojo tool goes through here so
+// bad input is rejected the same way everywhere instead of silently coerced.
+// In read-only mode, write tools are not registered at all.
+func (s *Server) addTool(tool mcp.Tool, handler server.ToolHandlerFunc) {
+	if s.config.Server.ReadOnly && writeTools[tool.Name] {
+		return
+	}
+	tool = s.severity.withSeverityLabels(s.withLimitCap(tool))
+	s.tools = append(s.tools, tool)
+	s.mcpServer.AddTool(tool, withArgumentValidation(tool, handler))
+}
+
+// withLimitCap sets the configured maximum on a tool's limit argument unless
+// the tool declares its own, so validation rejects oversized pages before
+// any DefectDojo request is made.
+func (s *Server) withLimitCap(tool mcp.Tool) mcp.Tool {
+	limit, ok := tool.InputSchema.Properties["limit"].(map[string]any)
+	if !ok || limit["maximum"] != nil {
+		return tool
+	}
+	capped := maps.Clone(limit)
+	capped["maximum"] = float64(s.maxListLimit())
+	tool.InputSchema.Properties = maps.Clone(tool.InputSchema.Properties)
+	tool.InputSchema.Properties["limit"] = capped
+	return tool
+}
+
+// withArgumentValidation wraps a tool handler with validateArguments.
+func withArgumentValidation(tool mcp.Tool, next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if err := validateArguments(tool.InputSchema, request.GetArguments()); err != nil {
+			return nil, fmt.Errorf("invalid arguments for %s: %w", tool.Name, err)
+		}
+		return next(ctx, request)
+	}
+}
+
+// validateArguments checks tool arguments against an input schema: unknown
+// names, missing required arguments, types, numeric ranges, string lengths
+// and enums. All problems are reported together, in argument name order.
+// A null value is treated as an omitted argument. Enums match
+// case-insensitively; handlers normalize to the canonical value.
+func validateArguments(schema mcp.ToolInputSchema, args map[string]any) error {
+	var problems []string
+
+	for _, name := range schema.Required {
+		if value, ok := args[name]; !ok || value == nil {
+			problems = append(problems, fmt.Sprintf("missing required argument %q", name))
+		}
+	}
+
+	names := make([]string, 0, len(args))
+	for name := range args {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		value := args[name]
+		property, known := schema.Properties[name].(map[string]any)
+		if !known {
+			problems = append(problems, fmt.Sprintf("unknown argument %q (accepted: %s)", name, strings.Join(propertyNames(schema), ", ")))
+			continue
+		}
+		if value == nil {
+			continue
+		}
+		if problem := validateValue(property, value); problem != "" {
+			problems = append(problems, fmt.Sprintf("%s %s", name, problem))
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// validateValue checks a single value against a property schema and returns
+// a description of the problem, or "" if the value is acceptable.
+func validateValue(property map[string]any, value any) string {
+	switch property["type"] {
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			return fmt.Sprintf("must be a string, got %s", jsonType(value))
+		}
+		if minLength, ok := schemaNumber(property, "minLength"); ok && float64(len([]rune(str))) < minLength {
+			return fmt.Sprintf("must be at least %g characters", minLength)
+		}
+		if maxLength, ok := schemaNumber(property, "maxLength"); ok && float64(len([]rune(str))) > maxLength {
+			return fmt.Sprintf("must be at most %g characters", maxLength)
+		}
+		if enum, ok := property["enum"].([]string); ok && !slices.ContainsFunc(enum, func(v string) bool { return strings.EqualFold(v, str) }) {
+			return fmt.Sprintf("must be one of %s, got %q", strings.Join(enum, ", "), str)
+		}
+
+	case "number", "integer":
+		number, ok := toFloat(value)
+		if !ok {
+			return fmt.Sprintf("must be a number, got %s", jsonType(value))
+		}
+		if property["type"] == "integer" && number != math.Trunc(number) {
+			return fmt.Sprintf("must be a whole number, got %g", number)
+		}
+		if minimum, ok := schemaNumber(property, "minimum"); ok && number < minimum {
+			return fmt.Sprintf("must be at least %g, got %g", minimum, number)
+		}
+		if maximum, ok := schemaNumber(property, "maximum"); ok && number > maximum {
+			return fmt.Sprintf("must be at most %g, got %g", maximum, number)
+		}
+
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fmt.Sprintf("must be a boolean, got %s", jsonType(value))
+		}
+
+	case "array":
+		items := reflect.ValueOf(value)
+		if items.Kind() != reflect.Slice {
+			return fmt.Sprintf("must be an array, got %s", jsonType(value))
+		}
+		itemSchema, _ := property["items"].(map[string]any)
+		for i := 0; i < items.Len(); i++ {
+			if itemSchema == nil {
+				break
+			}
+			if problem := validateValue(itemSchema, items.Index(i).Interface()); problem != "" {
+				return fmt.Sprintf("item %d %s", i, problem)
+			}
+		}
+	}
+	return ""
+}
+
+// propertyNames returns the schema's argument names in sorted order.
+func propertyNames(schema mcp.ToolInputSchema) []string {
+	names := make([]string, 0, len(schema.Properties))
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// schemaNumber reads a numeric schema keyword such as "minimum".
+func schemaNumber(property map[string]any, keyword string) (float64, bool) {
+	if value, ok := property[keyword]; ok {
+		return toFloat(value)
+	}
+	return 0, false
+}
+
+// toFloat converts any Go numeric value to float64. Arguments decoded from
+// JSON are float64, but in-process callers may pass ints.
+func toFloat(value any) (float64, bool) {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	default:
+		return 0, false
+	}
+}
+
+// jsonType names the JSON type of a value for error messages.
+func jsonType(value any) string {
+	if _, ok := toFloat(value); ok {
+		return "number"
+	}
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Map, reflect.Struct:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
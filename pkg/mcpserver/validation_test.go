@@ -0,0 +1,111 @@
+package mcpserver
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestValidateArguments(t *testing.T) {
+	tool := mcp.NewTool("example",
+		mcp.WithNumber("id", mcp.Required(), integer(), mcp.Min(1)),
+		mcp.WithNumber("ratio", mcp.Max(1)),
+		mcp.WithString("mode", mcp.Enum("fast", "slow")),
+		mcp.WithString("note", mcp.MinLength(1), mcp.MaxLength(5)),
+		mcp.WithBoolean("flag"),
+		mcp.WithArray("ids", mcp.WithNumberItems(integer())),
+	)
+
+	tests := []struct {
+		name    string
+		args    map[string]any
+		wantErr string
+	}{
+		{"valid", map[string]any{"id": 3, "ratio": 0.5, "mode": "fast", "note": "ok", "flag": true, "ids": []any{1.0, 2}}, ""},
+		{"json numbers", map[string]any{"id": 3.0}, ""},
+		{"null optional", map[string]any{"id": 1, "mode": nil}, ""},
+		{"missing required", map[string]any{}, `missing required argument "id"`},
+		{"unknown argument", map[string]any{"id": 1, "idd": 2}, `unknown argument "idd" (accepted: flag, id, ids, mode, note, ratio)`},
+		{"wrong type", map[string]any{"id": "3"}, "id must be a number, got string"},
+		{"fractional integer", map[string]any{"id": 1.5}, "id must be a whole number"},
+		{"below minimum", map[string]any{"id": -1}, "id must be at least 1, got -1"},
+		{"above maximum", map[string]any{"id": 1, "ratio": 2}, "ratio must be at most 1"},
+		{"enum", map[string]any{"id": 1, "mode": "medium"}, `mode must be one of fast, slow, got "medium"`},
+		{"too long", map[string]any{"id": 1, "note": "toolong"}, "note must be at most 5 characters"},
+		{"empty string", map[string]any{"id": 1, "note": ""}, "note must be at least 1 characters"},
+		{"boolean", map[string]any{"id": 1, "flag": "yes"}, "flag must be a boolean, got string"},
+		{"array", map[string]any{"id": 1, "ids": 4}, "ids must be an array, got number"},
+		{"array item", map[string]any{"id": 1, "ids": []any{1, "two"}}, "ids item 1 must be a number"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateArguments(tool.InputSchema, tt.args)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestToolArgumentValidation(t *testing.T) {
+	s := newServer(&Config{}, &MockDefectDojoClient{})
+
+	tests := []struct {
+		tool    string
+		args    map[string]any
+		wantErr string
+	}{
+		{"get_defectdojo_findings", map[string]any{"limit": -5}, "limit must be at least 1"},
+		{"get_defectdojo_findings", map[string]any{"offset": -1}, "offset must be at least 0"},
+		{"get_defectdojo_findings", map[string]any{"limit": "ten"}, "limit must be a number"},
+		{"get_defectdojo_findings", map[string]any{"severty": "High"}, `unknown argument "severty"`},
+		{"get_finding_detail", map[string]any{"finding_id": 0}, "finding_id must be at least 1"},
+		{"defectdojo_health_check", map[string]any{"verbose": true}, `unknown argument "verbose"`},
+		{"mark_finding_false_positive", map[string]any{"finding_id": 1, "justification": ""}, "justification must be at least 1 characters"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tool+"/"+tt.wantErr, func(t *testing.T) {
+			_, err := callTool(t, s, tt.tool, tt.args)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+			if err != nil && !strings.Contains(err.Error(), "invalid arguments for "+tt.tool) {
+				t.Errorf("expected error to name the tool, got %v", err)
+			}
+		})
+	}
+}
+
+func TestListLimitCap(t *testing.T) {
+	s := newServer(&Config{Output: OutputConfig{ListLimit: 80, MaxListLimit: 50}}, &MockDefectDojoClient{})
+
+	if _, err := callTool(t, s, "get_defectdojo_findings", map[string]any{"limit": 51}); err == nil || !strings.Contains(err.Error(), "limit must be at most 50") {
+		t.Errorf("expected the configured maximum to be enforced, got %v", err)
+	}
+	if got := s.listLimit(); got != 50 {
+		t.Errorf("listLimit() = %d, want the default page size clamped to 50", got)
+	}
+
+	for _, tool := range s.Tools() {
+		limit, ok := tool.InputSchema.Properties["limit"].(map[string]any)
+		if !ok {
+			continue
+		}
+		if tool.Name == "prioritize_findings" {
+			if limit["maximum"] != float64(maxPrioritizedShown) {
+				t.Errorf("prioritize_findings maximum = %v, want its own %d", limit["maximum"], maxPrioritizedShown)
+			}
+		} else if limit["maximum"] != float64(50) {
+			t.Errorf("%s limit maximum = %v, want 50", tool.Name, limit["maximum"])
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package mcpserver
+
+import (
+	"runtime"
+	"runtime/debug"
+	"slices"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// modulePath is this module's import path, used to find its version in the build info
+const modulePath = "github.com/brduru/mcp-defect-dojo"
+
+// Build metadata, set at build time:
+//
+//	go build -ldflags "-X github.com/brduru/mcp-defect-dojo/pkg/mcpserver.version=v1.2.3 ..."
+var (
+	version = ""
+	commit  = ""
+	date    = ""
+)
+
+// VersionInfo describes exactly what is deployed
+type VersionInfo struct {
+	Version                   string   `json:"version"`                     // Release version, e.g. "v1.2.3" ("dev" if unknown)
+	Commit                    string   `json:"commit"`                      // Source commit ("unknown" if not recorded)
+	Date                      string   `json:"date"`                        // Build or commit date ("unknown" if not recorded)
+	GoVersion                 string   `json:"go_version"`                  // Go toolchain that built the binary
+	MCPGoVersion              string   `json:"mcp_go_version"`              // Version of the mcp-go library
+	ProtocolVersion           string   `json:"protocol_version"`            // Latest MCP protocol version spoken
+	SupportedProtocolVersions []string `json:"supported_protocol_versions"` // Every MCP protocol version accepted from clients
+}
+
+// Version returns the release version of this module: the one stamped at
+// build time, else the module version recorded by the Go toolchain (e.g.
+// when embedded as a dependency), else "dev".
+func Version() string {
+	return BuildInfo().Version
+}
+
+// BuildInfo returns the version, commit and build date along with the Go,
+// mcp-go and MCP protocol versions, for embedders and fleet tooling.
+func BuildInfo() VersionInfo {
+	info := VersionInfo{
+		Version:                   version,
+		Commit:                    commit,
+		Date:                      date,
+		GoVersion:                 runtime.Version(),
+		ProtocolVersion:           mcp.LATEST_PROTOCOL_VERSION,
+		SupportedProtocolVersions: slices.Clone(mcp.ValidProtocolVersions),
+	}
+
+	if build, ok := debug.ReadBuildInfo(); ok {
+		modules := append([]*debug.Module{&build.Main}, build.Deps...)
+		for _, module := range modules {
+			switch module.Path {
+			case modulePath:
+				if info.Version == "" && module.Version != "" && module.Version != "(devel)" {
+					info.Version = module.Version
+				}
+			case "github.com/mark3labs/mcp-go":
+				info.MCPGoVersion = module.Version
+			}
+		}
+		// VCS settings describe the main module, which is someone else's when embedded
+		for _, setting := range build.Settings {
+			if build.Main.Path != modulePath {
+				break
+			}
+			switch setting.Key {
+			case "vcs.revision":
+				if info.Commit == "" {
+					info.Commit = setting.Value
+				}
+			case "vcs.time":
+				if info.Date == "" {
+					info.Date = setting.Value
+				}
+			}
+		}
+	}
+
+	if info.Version == "" {
+		info.Version = "dev"
+	}
+	if info.Commit == "" {
+		info.Commit = "unknown"
+	}
+	if info.Date == "" {
+		info.Date = "unknown"
+	}
+	if info.MCPGoVersion == "" {
+		info.MCPGoVersion = "unknown"
+	}
+	return info
+}
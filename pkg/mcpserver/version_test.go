@@ -0,0 +1,28 @@
+package mcpserver
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestBuildInfo(t *testing.T) {
+	info := BuildInfo()
+	if info.Version == "" || info.Commit == "" || info.Date == "" || info.GoVersion == "" || info.MCPGoVersion == "" {
+		t.Errorf("expected every field to be filled, got %+v", info)
+	}
+	if info.ProtocolVersion != mcp.LATEST_PROTOCOL_VERSION || !slices.Contains(info.SupportedProtocolVersions, "2024-11-05") {
+		t.Errorf("unexpected protocol versions %+v", info)
+	}
+	if Version() != info.Version {
+		t.Errorf("Version() = %q, want %q", Version(), info.Version)
+	}
+
+	old := version
+	version = "v9.9.9"
+	defer func() { version = old }()
+	if Version() != "v9.9.9" {
+		t.Errorf("expected the build-time version, got %q", Version())
+	}
+}
@@ -0,0 +1,56 @@
+package mcpserver
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// partialResults collects the parts of a tool call's answer that DefectDojo
+// failed to provide. Enrichment and aggregation requests record their
+// failures here and the call answers with everything else, since agents do
+// more with partial data and an explanation than with an error. Only the
+// request a call cannot answer without fails it. Methods are safe for
+// concurrent use and do nothing on a nil receiver.
+type partialResults struct {
+	mu       sync.Mutex
+	warnings []string
+}
+
+// warnf records a warning, once however often it recurs
+func (p *partialResults) warnf(format string, args ...any) {
+	if p == nil {
+		return
+	}
+	warning := fmt.Sprintf(format, args...)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !slices.Contains(p.warnings, warning) {
+		p.warnings = append(p.warnings, warning)
+	}
+}
+
+// list returns the warnings in the order recorded, or nil for none
+func (p *partialResults) list() []string {
+	if p == nil {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return slices.Clone(p.warnings)
+}
+
+// formatWarnings renders the warnings section of text and Markdown output,
+// or nothing for no warnings
+func formatWarnings(warnings []string) string {
+	if len(warnings) == 0 {
+		return ""
+	}
+	var result strings.Builder
+	result.WriteString("\n⚠️ Partial results, some DefectDojo requests failed:\n")
+	for _, warning := range warnings {
+		fmt.Fprintf(&result, "- %s\n", warning)
+	}
+	return result.String()
+}
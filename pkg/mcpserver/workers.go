@@ -0,0 +1,171 @@
+package mcpserver
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultWorkerPoolSize is how many fan-out tasks run at once unless configured
+const defaultWorkerPoolSize = 16
+
+// Worker pool operations, as reported in statistics
+const (
+	operationAddNotes         = "add_notes"         // Notes posted by add_note_to_findings
+	operationPinLookups       = "pin_lookups"       // Findings read by pin_findings
+	operationRiskFindings     = "risk_findings"     // Accepted findings read for expiring risk acceptances
+	operationEngagementStats  = "engagement_counts" // Open findings counted per engagement
+	operationProductPosture   = "product_posture"   // Products summarized for the security posture
+	operationProductMTTR      = "product_mttr"      // Products measured for remediation times
+	operationComponentLookup  = "component_lookups" // Findings queried per SBOM component
+	operationReadinessImports = "readiness_imports" // Import histories read per test for engagement readiness
+)
+
+// workerPool runs the tasks of the server's fan-out operations on a fixed
+// number of workers shared by every call, so one setting bounds the
+// DefectDojo requests they have in flight. Tasks must not run pool work of
+// their own: with every worker busy, they would wait for themselves.
+type workerPool struct {
+	slots chan struct{}
+
+	mu         sync.Mutex
+	operations map[string]*poolUsage
+}
+
+// poolUsage is the running tally of one operation's tasks
+type poolUsage struct {
+	tasks   int           // Completed tasks
+	running int           // Tasks holding a worker
+	waiting int           // Tasks waiting for a worker
+	wait    time.Duration // Summed time tasks waited for a worker
+}
+
+// workerPoolStats is one operation's entry in get_server_stats and /metrics
+type workerPoolStats struct {
+	Operation  string  `json:"operation"`
+	Tasks      int     `json:"tasks"`
+	Running    int     `json:"running"`
+	Waiting    int     `json:"waiting"`
+	WaitTimeMS float64 `json:"wait_time_ms"` // Summed time tasks waited for a worker
+}
+
+// newWorkerPool starts a pool of size workers
+func newWorkerPool(size int) *workerPool {
+	return &workerPool{slots: make(chan struct{}, size), operations: map[string]*poolUsage{}}
+}
+
+// size returns the number of workers
+func (p *workerPool) size() int {
+	return cap(p.slots)
+}
+
+// run calls task with every index below n, at most the pool's size at once
+// across all calls, and returns when they are done. Once ctx is canceled,
+// tasks still waiting run without a worker, so they fail fast on the
+// canceled context instead of queueing behind other calls. A call whose
+// budget limits its concurrency runs at most that many of its tasks at once.
+func (p *workerPool) run(ctx context.Context, operation string, n int, task func(i int)) {
+	var callSlots chan struct{}
+	if limit := budgetFrom(ctx).maxConcurrency(); limit > 0 {
+		callSlots = make(chan struct{}, limit)
+	}
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Go(func() {
+			p.update(operation, func(usage *poolUsage) { usage.waiting++ })
+			start := time.Now()
+			acquired, callAcquired := false, false
+			if callSlots != nil {
+				select {
+				case callSlots <- struct{}{}:
+					callAcquired = true
+				case <-ctx.Done():
+				}
+			}
+			select {
+			case p.slots <- struct{}{}:
+				acquired = true
+			case <-ctx.Done():
+			}
+			waited := time.Since(start)
+			p.update(operation, func(usage *poolUsage) {
+				usage.waiting--
+				usage.running++
+				usage.wait += waited
+			})
+			defer func() {
+				if acquired {
+					<-p.slots
+				}
+				if callAcquired {
+					<-callSlots
+				}
+				p.update(operation, func(usage *poolUsage) {
+					usage.running--
+					usage.tasks++
+				})
+			}()
+			task(i)
+		})
+	}
+	wg.Wait()
+}
+
+// update changes an operation's tally under the pool's lock
+func (p *workerPool) update(operation string, fn func(usage *poolUsage)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	usage, ok := p.operations[operation]
+	if !ok {
+		usage = &poolUsage{}
+		p.operations[operation] = usage
+	}
+	fn(usage)
+}
+
+// snapshot returns the tally of every operation that ran, busiest first
+func (p *workerPool) snapshot() []workerPoolStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	result := make([]workerPoolStats, 0, len(p.operations))
+	for operation, usage := range p.operations {
+		result = append(result, workerPoolStats{
+			Operation:  operation,
+			Tasks:      usage.tasks,
+			Running:    usage.running,
+			Waiting:    usage.waiting,
+			WaitTimeMS: milliseconds(usage.wait),
+		})
+	}
+	slices.SortFunc(result, func(a, b workerPoolStats) int {
+		return cmp.Or(cmp.Compare(b.Tasks, a.Tasks), strings.Compare(a.Operation, b.Operation))
+	})
+	return result
+}
+
+// writeMetrics writes the pool statistics in the Prometheus text exposition format
+func (p *workerPool) writeMetrics(w io.Writer) {
+	fmt.Fprintf(w, "# HELP mcp_worker_pool_size Workers shared by concurrent DefectDojo operations.\n# TYPE mcp_worker_pool_size gauge\n")
+	fmt.Fprintf(w, "mcp_worker_pool_size %d\n", p.size())
+	usage := p.snapshot()
+	metrics := []struct {
+		name, help, kind string
+		value            func(workerPoolStats) float64
+	}{
+		{"mcp_worker_pool_tasks_total", "Worker pool tasks completed since the server started.", "counter", func(u workerPoolStats) float64 { return float64(u.Tasks) }},
+		{"mcp_worker_pool_running_tasks", "Worker pool tasks holding a worker.", "gauge", func(u workerPoolStats) float64 { return float64(u.Running) }},
+		{"mcp_worker_pool_waiting_tasks", "Worker pool tasks waiting for a worker.", "gauge", func(u workerPoolStats) float64 { return float64(u.Waiting) }},
+		{"mcp_worker_pool_wait_seconds_total", "Summed time worker pool tasks waited for a worker.", "counter", func(u workerPoolStats) float64 { return u.WaitTimeMS / 1000 }},
+	}
+	for _, metric := range metrics {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", metric.name, metric.help, metric.name, metric.kind)
+		for _, operation := range usage {
+			fmt.Fprintf(w, "%s{operation=%q} %g\n", metric.name, operation.Operation, metric.value(operation))
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package mcpserver
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+func TestWorkerPool(t *testing.T) {
+	pool := newWorkerPool(3)
+
+	var running, peak atomic.Int32
+	var mu sync.Mutex
+	done := map[int]bool{}
+	task := func(i int) {
+		now := running.Add(1)
+		for {
+			if highest := peak.Load(); now <= highest || peak.CompareAndSwap(highest, now) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		running.Add(-1)
+		mu.Lock()
+		done[i] = true
+		mu.Unlock()
+	}
+
+	// Two calls share the pool's three workers
+	var wg sync.WaitGroup
+	wg.Go(func() { pool.run(context.Background(), "first", 10, task) })
+	wg.Go(func() { pool.run(context.Background(), "second", 10, func(i int) { task(i + 10) }) })
+	wg.Wait()
+
+	if got := peak.Load(); got > 3 {
+		t.Errorf("expected at most 3 tasks at once, got %d", got)
+	}
+	if len(done) != 20 {
+		t.Errorf("expected every task to run, %d did", len(done))
+	}
+	stats := pool.snapshot()
+	if len(stats) != 2 || stats[0].Operation != "first" || stats[0].Tasks != 10 || stats[1].Tasks != 10 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+	if stats[0].Running != 0 || stats[0].Waiting != 0 {
+		t.Errorf("expected no running or waiting tasks once done, got %+v", stats[0])
+	}
+	if stats[0].WaitTimeMS+stats[1].WaitTimeMS == 0 {
+		t.Error("expected tasks to have waited for a worker")
+	}
+}
+
+func TestWorkerPoolCanceled(t *testing.T) {
+	pool := newWorkerPool(1)
+	release := make(chan struct{})
+	started := make(chan struct{})
+	go pool.run(context.Background(), "busy", 1, func(int) {
+		close(started)
+		<-release
+	})
+	defer close(release)
+	<-started
+
+	// With the only worker busy, tasks of a canceled call still run, without one
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	finished := make(chan struct{})
+	go func() {
+		pool.run(ctx, "canceled", 3, func(int) {})
+		close(finished)
+	}()
+	select {
+	case <-finished:
+	case <-time.After(5 * time.Second):
+		t.Fatal("canceled call waited for a busy worker")
+	}
+}
+
+func TestWorkerPoolMetrics(t *testing.T) {
+	client := &MockDefectDojoClient{
+		AddFindingNoteFunc: func(ctx context.Context, findingID int, entry string) (*types.Note, error) {
+			return &types.Note{ID: findingID * 10}, nil
+		},
+	}
+	s := newServer(&Config{Server: ServerConfig{WorkerPoolSize: 2}}, client)
+
+	if _, err := callTool(t, s, toolAddNote, map[string]any{"finding_ids": []any{1, 2, 3}, "note": "Reviewed"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	result, err := callTool(t, s, toolServerStats, map[string]any{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := resultText(result); !strings.Contains(text, "Worker pool (2 workers):") || !strings.Contains(text, "add_notes: 3 tasks, 0 running, 0 waiting") {
+		t.Errorf("expected worker pool usage in the stats, got:\n%s", text)
+	}
+
+	var metrics strings.Builder
+	s.workers.writeMetrics(&metrics)
+	for _, want := range []string{"mcp_worker_pool_size 2", `mcp_worker_pool_tasks_total{operation="add_notes"} 3`, `mcp_worker_pool_waiting_tasks{operation="add_notes"} 0`} {
+		if !strings.Contains(metrics.String(), want) {
+			t.Errorf("metrics missing %q:\n%s", want, metrics.String())
+		}
+	}
+}
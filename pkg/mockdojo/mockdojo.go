@@ -0,0 +1,733 @@
+// Package mockdojo serves a fake DefectDojo v2 API for local development and
+// tests, so examples and downstream agents run without a DefectDojo stack.
+//
+// The server answers the endpoints this module's client uses: the API root,
+// findings (list with filters, detail, prefetch, PATCH of the false positive
+// flags, severity or reviewers, notes and metadata), tests, test import
+// history, test types, engagements (list with filters and lead prefetch,
+// detail, creation), products (list, detail, creation), product types,
+// users, risk acceptances, endpoints and technologies by product, endpoint
+// statuses (list with filters and prefetch), system settings, SLA
+// configurations, the announcement, the user profile, and the OpenAPI schema
+// version. Data comes from the built-in demo fixtures or a fixture directory
+// in the format of DEFECTDOJO_FIXTURES_DIR.
+// Writes change the in-memory copy only; scan import answers 501 Not
+// Implemented.
+//
+// Example:
+//
+//	dojo, err := mockdojo.New(mockdojo.Options{APIKey: "test-key"})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	ts := httptest.NewServer(dojo)
+//	defer ts.Close()
+//
+//	server, err := mcpserver.NewServerWithSettings(mcpserver.DefectDojoSettings{
+//		BaseURL: ts.URL,
+//		APIKey:  "test-key",
+//	})
+package mockdojo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/brduru/mcp-defect-dojo/internal/defectdojo"
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+// Version is the DefectDojo release the fake server reports
+const Version = "2.38.0"
+
+// Options configures a fake DefectDojo server
+type Options struct {
+	FixturesDir string // Fixture directory (empty = built-in demo data)
+	APIKey      string // Token required in the Authorization header (empty = any request is authenticated)
+}
+
+// Server is an http.Handler serving the fake DefectDojo API
+type Server struct {
+	apiKey   string
+	fixtures *defectdojo.FixtureClient
+	mux      *http.ServeMux
+
+	mu         sync.Mutex
+	nextNoteID int
+}
+
+// New loads the fixtures and returns a fake DefectDojo server
+func New(opts Options) (*Server, error) {
+	fixtures, err := defectdojo.NewFixtureClient(opts.FixturesDir)
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{apiKey: opts.APIKey, fixtures: fixtures, mux: http.NewServeMux(), nextNoteID: 1}
+
+	s.mux.HandleFunc("GET /api/v2/{$}", s.apiRoot)
+	s.mux.HandleFunc("GET /api/v2/oa3/schema/", s.schema)
+	s.mux.HandleFunc("GET /api/v2/user_profile/", s.userProfile)
+	s.mux.HandleFunc("GET /api/v2/findings/", s.listFindings)
+	s.mux.HandleFunc("GET /api/v2/findings/{id}/", s.getFinding)
+	s.mux.HandleFunc("PATCH /api/v2/findings/{id}/", s.patchFinding)
+	s.mux.HandleFunc("POST /api/v2/findings/{id}/notes/", s.addNote)
+	s.mux.HandleFunc("POST /api/v2/findings/{id}/close/", s.closeFinding)
+	s.mux.HandleFunc("GET /api/v2/findings/{id}/metadata/", s.getMetadata)
+	s.mux.HandleFunc("POST /api/v2/findings/{id}/metadata/", s.addMetadata)
+	s.mux.HandleFunc("GET /api/v2/tests/", s.listTests)
+	s.mux.HandleFunc("GET /api/v2/tests/{id}/", byID(fixtures.GetTest))
+	s.mux.HandleFunc("GET /api/v2/test_imports/", s.listTestImports)
+	s.mux.HandleFunc("GET /api/v2/test_types/{id}/", byID(fixtures.GetTestType))
+	s.mux.HandleFunc("GET /api/v2/engagements/", s.listEngagements)
+	s.mux.HandleFunc("POST /api/v2/engagements/", create(fixtures.CreateEngagement))
+	s.mux.HandleFunc("GET /api/v2/engagements/{id}/", byID(fixtures.GetEngagement))
+	s.mux.HandleFunc("GET /api/v2/products/", s.listProducts)
+	s.mux.HandleFunc("POST /api/v2/products/", create(fixtures.CreateProduct))
+	s.mux.HandleFunc("GET /api/v2/products/{id}/", byID(fixtures.GetProduct))
+	s.mux.HandleFunc("GET /api/v2/product_types/", s.listProductTypes)
+	s.mux.HandleFunc("GET /api/v2/users/", s.listUsers)
+	s.mux.HandleFunc("GET /api/v2/risk_acceptance/", s.listRiskAcceptances)
+	s.mux.HandleFunc("GET /api/v2/endpoint_status/", s.listEndpointStatuses)
+	s.mux.HandleFunc("GET /api/v2/endpoints/", s.listEndpoints)
+	s.mux.HandleFunc("GET /api/v2/technologies/", s.listTechnologies)
+	s.mux.HandleFunc("GET /api/v2/system_settings/", s.systemSettings)
+	s.mux.HandleFunc("GET /api/v2/development_environments/", s.listDevelopmentEnvironments)
+	s.mux.HandleFunc("GET /api/v2/sla_configurations/", s.listSLAConfigurations)
+	s.mux.HandleFunc("GET /api/v2/announcements/", s.announcements)
+	s.mux.HandleFunc("POST /api/v2/import-scan/", s.importScan)
+	return s, nil
+}
+
+// ServeHTTP authenticates the request and routes it to the fake API
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Date", time.Now().UTC().Format(http.TimeFormat))
+	if s.apiKey != "" && r.Header.Get("Authorization") != "Token "+s.apiKey {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"detail": "Invalid token."})
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// apiRoot lists the served endpoints like DefectDojo's browsable API root
+func (s *Server) apiRoot(w http.ResponseWriter, r *http.Request) {
+	root := map[string]string{}
+	for _, endpoint := range []string{"findings", "tests", "test_imports", "test_types", "development_environments", "engagements", "products", "product_types", "risk_acceptance", "endpoints", "endpoint_status", "technologies", "users", "user_profile", "system_settings", "sla_configurations", "announcements", "import-scan"} {
+		root[endpoint] = absoluteURL(r, "/api/v2/"+endpoint+"/")
+	}
+	writeJSON(w, http.StatusOK, root)
+}
+
+// schema reports the DefectDojo version as the OpenAPI schema does
+func (s *Server) schema(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{"openapi": "3.0.3", "info": map[string]string{"title": "Defect Dojo API v2", "version": "v" + Version}})
+}
+
+// userProfile reports a superuser so every write tool works
+func (s *Server) userProfile(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, types.UserProfile{User: types.User{ID: 1, Username: "mockdojo", IsSuperuser: true}})
+}
+
+// listFindings applies the finding filters the client sends
+func (s *Server) listFindings(w http.ResponseWriter, r *http.Request) {
+	filter, err := findingsFilter(r.URL.Query())
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"detail": err.Error()})
+		return
+	}
+	response, err := s.fixtures.GetFindings(r.Context(), filter)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	response.Next, response.Previous = pageLinks(r, response.Count, filter.Limit, filter.Offset)
+	writeJSON(w, http.StatusOK, response)
+}
+
+// getFinding serves a finding with the related objects named by the prefetch parameter
+func (s *Server) getFinding(w http.ResponseWriter, r *http.Request) {
+	var prefetch []string
+	if value := r.URL.Query().Get("prefetch"); value != "" {
+		prefetch = strings.Split(value, ",")
+	}
+	byID(func(ctx context.Context, id int) (*types.FindingDetail, error) {
+		return s.fixtures.GetFindingDetailPrefetch(ctx, id, prefetch)
+	})(w, r)
+}
+
+// patchFinding applies the false_p, active and verified changes
+// MarkFalsePositive sends, the severity change of ChangeSeverity, or the
+// reviewers AssignFinding sets
+func (s *Server) patchFinding(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	var patch struct {
+		FalseP    *bool   `json:"false_p"`
+		Active    *bool   `json:"active"`
+		Verified  *bool   `json:"verified"`
+		Severity  *string `json:"severity"`
+		Reviewers *[]int  `json:"reviewers"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"detail": "JSON parse error - " + err.Error()})
+		return
+	}
+	if patch.Reviewers != nil {
+		if _, err := s.fixtures.AssignFinding(r.Context(), id, *patch.Reviewers); err != nil {
+			writeError(w, err)
+			return
+		}
+		byID(s.fixtures.GetFindingDetail)(w, r)
+		return
+	}
+	if patch.Severity != nil {
+		if !types.IsValidSeverity(*patch.Severity) {
+			writeJSON(w, http.StatusBadRequest, map[string][]string{"severity": {fmt.Sprintf("%q is not a valid choice.", *patch.Severity)}})
+			return
+		}
+		// The justification arrives as a separate note
+		if _, err := s.fixtures.ChangeSeverity(r.Context(), id, types.SeverityChangeRequest{Severity: *patch.Severity}); err != nil {
+			writeError(w, err)
+			return
+		}
+		byID(s.fixtures.GetFindingDetail)(w, r)
+		return
+	}
+
+	current, err := s.fixtures.GetFindingDetail(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	request := types.FalsePositiveRequest{IsFalsePositive: current.FalseP, Verified: patch.Verified}
+	if patch.FalseP != nil {
+		request.IsFalsePositive = *patch.FalseP
+	}
+	if request.IsFalsePositive && patch.Verified != nil && *patch.Verified {
+		// DefectDojo's finding serializer refuses this combination
+		writeJSON(w, http.StatusBadRequest, map[string][]string{"non_field_errors": {"False positive findings cannot be verified."}})
+		return
+	}
+	if patch.Active != nil {
+		request.AlsoDeactivate = !*patch.Active
+		request.Reactivate = *patch.Active
+	}
+	if _, err := s.fixtures.MarkFalsePositive(r.Context(), id, request); err != nil {
+		writeError(w, err)
+		return
+	}
+	byID(s.fixtures.GetFindingDetail)(w, r)
+}
+
+// addNote accepts a finding note; notes are numbered but not stored
+func (s *Server) addNote(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	if _, err := s.fixtures.GetFindingDetail(r.Context(), id); err != nil {
+		writeError(w, err)
+		return
+	}
+	var note types.Note
+	if err := json.NewDecoder(r.Body).Decode(&note); err != nil || note.Entry == "" {
+		writeJSON(w, http.StatusBadRequest, map[string][]string{"entry": {"This field is required."}})
+		return
+	}
+
+	s.mu.Lock()
+	note.ID = s.nextNoteID
+	s.nextNoteID++
+	s.mu.Unlock()
+	note.Date = time.Now().UTC()
+	note.Author = &types.User{ID: 1, Username: "mockdojo"}
+	writeJSON(w, http.StatusCreated, note)
+}
+
+// closeFinding closes a finding as mitigated and answers with the close
+// fields, like DefectDojo's close action
+func (s *Server) closeFinding(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	var request struct {
+		IsMitigated *bool     `json:"is_mitigated"`
+		Mitigated   time.Time `json:"mitigated"`
+		Note        string    `json:"note"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"detail": "JSON parse error - " + err.Error()})
+		return
+	}
+	if request.IsMitigated == nil {
+		writeJSON(w, http.StatusBadRequest, map[string][]string{"is_mitigated": {"This field is required."}})
+		return
+	}
+	finding, err := s.fixtures.CloseFinding(r.Context(), id, types.CloseFindingRequest{Note: request.Note, Mitigated: request.Mitigated})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"is_mitigated": finding.IsMitigated, "mitigated": finding.Mitigated, "false_p": false, "out_of_scope": false, "duplicate": false})
+}
+
+func (s *Server) getMetadata(w http.ResponseWriter, r *http.Request) {
+	byID(s.fixtures.GetFindingMetadata)(w, r)
+}
+
+func (s *Server) addMetadata(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	var metadata types.FindingMetadata
+	if err := json.NewDecoder(r.Body).Decode(&metadata); err != nil || metadata.Name == "" {
+		writeJSON(w, http.StatusBadRequest, map[string][]string{"name": {"This field is required."}})
+		return
+	}
+	created, err := s.fixtures.AddFindingMetadata(r.Context(), id, metadata)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, created)
+}
+
+// listTests pages through an engagement's tests
+func (s *Server) listTests(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	engagement, _ := strconv.Atoi(query.Get("engagement"))
+	limit, offset := pagination(query)
+	response, err := s.fixtures.ListTests(r.Context(), engagement, limit, offset)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	response.Next, _ = pageLinks(r, response.Count, limit, offset)
+	writeJSON(w, http.StatusOK, response)
+}
+
+// listTestImports pages through a test's import history
+func (s *Server) listTestImports(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	test, _ := strconv.Atoi(query.Get("test"))
+	limit, offset := pagination(query)
+	response, err := s.fixtures.ListTestImports(r.Context(), test, limit, offset)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	response.Next, _ = pageLinks(r, response.Count, limit, offset)
+	writeJSON(w, http.StatusOK, response)
+}
+
+// systemSettings answers with the single settings object in a list, like DefectDojo
+func (s *Server) systemSettings(w http.ResponseWriter, r *http.Request) {
+	settings, err := s.fixtures.GetSystemSettings(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, types.SystemSettingsResponse{Count: 1, Results: []types.SystemSettings{*settings}})
+}
+
+// listDevelopmentEnvironments pages through the development environments
+func (s *Server) listDevelopmentEnvironments(w http.ResponseWriter, r *http.Request) {
+	limit, offset := pagination(r.URL.Query())
+	response, err := s.fixtures.ListDevelopmentEnvironments(r.Context(), limit, offset)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	response.Next, _ = pageLinks(r, response.Count, limit, offset)
+	writeJSON(w, http.StatusOK, response)
+}
+
+// listSLAConfigurations pages through the SLA configurations
+func (s *Server) listSLAConfigurations(w http.ResponseWriter, r *http.Request) {
+	limit, offset := pagination(r.URL.Query())
+	response, err := s.fixtures.ListSLAConfigurations(r.Context(), limit, offset)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	response.Next, _ = pageLinks(r, response.Count, limit, offset)
+	writeJSON(w, http.StatusOK, response)
+}
+
+// announcements lists the announcement, if any
+func (s *Server) announcements(w http.ResponseWriter, r *http.Request) {
+	announcement, err := s.fixtures.GetAnnouncement(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	response := types.AnnouncementsResponse{Results: []types.Announcement{}}
+	if announcement != nil {
+		response.Count, response.Results = 1, append(response.Results, *announcement)
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// listProducts pages through the products
+func (s *Server) listProducts(w http.ResponseWriter, r *http.Request) {
+	limit, offset := pagination(r.URL.Query())
+	response, err := s.fixtures.ListProducts(r.Context(), limit, offset)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	response.Next, _ = pageLinks(r, response.Count, limit, offset)
+	writeJSON(w, http.StatusOK, response)
+}
+
+// listEngagements serves the product, active and prefetch filters HTTPClient.ListEngagements sends
+func (s *Server) listEngagements(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	var filter types.EngagementsFilter
+	filter.Limit, filter.Offset = pagination(query)
+	if value := query.Get("product"); value != "" {
+		product, err := strconv.Atoi(value)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"detail": fmt.Sprintf("product: %q is not a number", value)})
+			return
+		}
+		filter.Product = &product
+	}
+	if value := query.Get("active"); value != "" {
+		active, err := strconv.ParseBool(value)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"detail": fmt.Sprintf("active: %q is not a boolean", value)})
+			return
+		}
+		filter.Active = &active
+	}
+	if value := query.Get("prefetch"); value != "" {
+		filter.Prefetch = strings.Split(value, ",")
+	}
+
+	response, err := s.fixtures.ListEngagements(r.Context(), filter)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	response.Next, _ = pageLinks(r, response.Count, filter.Limit, filter.Offset)
+	writeJSON(w, http.StatusOK, response)
+}
+
+// listEndpointStatuses pages through the endpoint statuses with the flag
+// filters and endpoint/finding prefetch
+func (s *Server) listEndpointStatuses(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	var filter types.EndpointStatusFilter
+	filter.Limit, filter.Offset = pagination(query)
+	for name, flag := range map[string]**bool{
+		"mitigated":      &filter.Mitigated,
+		"false_positive": &filter.FalsePositive,
+		"out_of_scope":   &filter.OutOfScope,
+		"risk_accepted":  &filter.RiskAccepted,
+	} {
+		if value := query.Get(name); value != "" {
+			parsed, err := strconv.ParseBool(value)
+			if err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"detail": fmt.Sprintf("%s: %q is not a boolean", name, value)})
+				return
+			}
+			*flag = &parsed
+		}
+	}
+	if value := query.Get("prefetch"); value != "" {
+		filter.Prefetch = strings.Split(value, ",")
+	}
+
+	response, err := s.fixtures.ListEndpointStatuses(r.Context(), filter)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	response.Next, _ = pageLinks(r, response.Count, filter.Limit, filter.Offset)
+	writeJSON(w, http.StatusOK, response)
+}
+
+// listEndpoints pages through the endpoints of the product query parameter
+func (s *Server) listEndpoints(w http.ResponseWriter, r *http.Request) {
+	product, ok := productParam(w, r)
+	if !ok {
+		return
+	}
+	limit, offset := pagination(r.URL.Query())
+	response, err := s.fixtures.ListEndpoints(r.Context(), product, limit, offset)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	response.Next, _ = pageLinks(r, response.Count, limit, offset)
+	writeJSON(w, http.StatusOK, response)
+}
+
+// listTechnologies pages through the technologies of the product query parameter
+func (s *Server) listTechnologies(w http.ResponseWriter, r *http.Request) {
+	product, ok := productParam(w, r)
+	if !ok {
+		return
+	}
+	limit, offset := pagination(r.URL.Query())
+	response, err := s.fixtures.ListTechnologies(r.Context(), product, limit, offset)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	response.Next, _ = pageLinks(r, response.Count, limit, offset)
+	writeJSON(w, http.StatusOK, response)
+}
+
+// productParam reads the required product query parameter, answering 400 when it is not a number
+func productParam(w http.ResponseWriter, r *http.Request) (int, bool) {
+	value := r.URL.Query().Get("product")
+	product, err := strconv.Atoi(value)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"detail": fmt.Sprintf("product: %q is not a number", value)})
+		return 0, false
+	}
+	return product, true
+}
+
+// listProductTypes pages through the product types
+func (s *Server) listProductTypes(w http.ResponseWriter, r *http.Request) {
+	limit, offset := pagination(r.URL.Query())
+	response, err := s.fixtures.ListProductTypes(r.Context(), limit, offset)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	response.Next, _ = pageLinks(r, response.Count, limit, offset)
+	writeJSON(w, http.StatusOK, response)
+}
+
+// listUsers pages through the users, filtered by exact login name
+func (s *Server) listUsers(w http.ResponseWriter, r *http.Request) {
+	limit, offset := pagination(r.URL.Query())
+	response, err := s.fixtures.ListUsers(r.Context(), r.URL.Query().Get("username"), limit, offset)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	response.Next, _ = pageLinks(r, response.Count, limit, offset)
+	writeJSON(w, http.StatusOK, response)
+}
+
+// listRiskAcceptances pages through the risk acceptances
+func (s *Server) listRiskAcceptances(w http.ResponseWriter, r *http.Request) {
+	limit, offset := pagination(r.URL.Query())
+	response, err := s.fixtures.ListRiskAcceptances(r.Context(), limit, offset)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	response.Next, _ = pageLinks(r, response.Count, limit, offset)
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (s *Server) importScan(w http.ResponseWriter, r *http.Request) {
+	_, err := s.fixtures.ImportScan(r.Context(), types.ImportScanRequest{})
+	writeError(w, err)
+}
+
+// create serves a fixture creation from a JSON request body, answering 201 Created
+func create[Req, T any](add func(ctx context.Context, request Req) (T, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var request Req
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"detail": "JSON parse error - " + err.Error()})
+			return
+		}
+		item, err := add(r.Context(), request)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusCreated, item)
+	}
+}
+
+// byID serves a fixture lookup by the {id} path segment
+func byID[T any](get func(ctx context.Context, id int) (T, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := pathID(w, r)
+		if !ok {
+			return
+		}
+		item, err := get(r.Context(), id)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, item)
+	}
+}
+
+// pathID parses the {id} path segment, answering 404 like DefectDojo when it is not a number
+func pathID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"detail": "Not found."})
+		return 0, false
+	}
+	return id, true
+}
+
+// findingsFilter parses the query parameters HTTPClient.GetFindings sends
+func findingsFilter(query url.Values) (types.FindingsFilter, error) {
+	var filter types.FindingsFilter
+	var errs []error
+	optionalBool := func(name string) *bool {
+		value := query.Get(name)
+		if value == "" {
+			return nil
+		}
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %q is not a boolean", name, value))
+			return nil
+		}
+		return &parsed
+	}
+	optionalInt := func(name string) *int {
+		value := query.Get(name)
+		if value == "" {
+			return nil
+		}
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %q is not a number", name, value))
+			return nil
+		}
+		return &parsed
+	}
+	ints := func(name string) []int {
+		var values []int
+		for _, value := range query[name] {
+			if parsed, err := strconv.Atoi(value); err == nil {
+				values = append(values, parsed)
+			} else {
+				errs = append(errs, fmt.Errorf("%s: %q is not a number", name, value))
+			}
+		}
+		return values
+	}
+	date := func(name string) time.Time {
+		value := query.Get(name)
+		if value == "" {
+			return time.Time{}
+		}
+		parsed, err := time.Parse(time.DateOnly, value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %q is not a date", name, value))
+		}
+		return parsed
+	}
+	list := func(name string) []string {
+		if value := query.Get(name); value != "" {
+			return strings.Split(value, ",")
+		}
+		return nil
+	}
+
+	filter.Limit, filter.Offset = pagination(query)
+	filter.Active = optionalBool("active")
+	filter.Severity = query.Get("severity")
+	filter.Verified = optionalBool("verified")
+	filter.Test = optionalInt("test")
+	filter.Product = optionalInt("test__engagement__product")
+	filter.Engagement = optionalInt("test__engagement")
+	filter.Environment = optionalInt("test__environment")
+	filter.ComponentName = query.Get("component_name")
+	filter.ComponentVersion = query.Get("component_version")
+	filter.Tags = list("tags")
+	filter.NotTags = list("not_tags")
+	filter.Reporter = ints("reporter")
+	filter.Reviewers = ints("reviewers")
+	filter.FoundBy = ints("found_by")
+	filter.Ordering = query.Get("ordering")
+	filter.RiskAccepted = optionalBool("risk_accepted")
+	filter.IsMitigated = optionalBool("is_mitigated")
+	filter.Duplicate = optionalBool("duplicate")
+	filter.DiscoveredAfter = date("discovered_after")
+	filter.DiscoveredBefore = date("discovered_before")
+	filter.MitigatedAfter = date("mitigated_after")
+	filter.MitigatedBefore = date("mitigated_before")
+	filter.Prefetch = list("prefetch")
+	if related := optionalBool("related_fields"); related != nil {
+		filter.RelatedFields = *related
+	}
+	return filter, errors.Join(errs...)
+}
+
+// pagination reads limit and offset; DefectDojo's default page size is 25
+func pagination(query url.Values) (limit, offset int) {
+	limit, err := strconv.Atoi(query.Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = 25
+	}
+	offset, _ = strconv.Atoi(query.Get("offset"))
+	return limit, max(offset, 0)
+}
+
+// pageLinks builds the next and previous URLs of a list response
+func pageLinks(r *http.Request, count, limit, offset int) (next, previous *string) {
+	link := func(offset int) *string {
+		query := r.URL.Query()
+		query.Set("limit", strconv.Itoa(limit))
+		query.Set("offset", strconv.Itoa(offset))
+		u := absoluteURL(r, r.URL.Path) + "?" + query.Encode()
+		return &u
+	}
+	if offset+limit < count {
+		next = link(offset + limit)
+	}
+	if offset > 0 {
+		previous = link(max(offset-limit, 0))
+	}
+	return next, previous
+}
+
+// absoluteURL resolves a path against the request's host
+func absoluteURL(r *http.Request, path string) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host + path
+}
+
+// writeError answers with the status of an *defectdojo.APIError, or 500
+func writeError(w http.ResponseWriter, err error) {
+	var apiErr *defectdojo.APIError
+	if errors.As(err, &apiErr) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(apiErr.StatusCode)
+		if strings.HasPrefix(apiErr.Body, "{") {
+			fmt.Fprint(w, apiErr.Body)
+		} else {
+			json.NewEncoder(w).Encode(map[string]string{"detail": apiErr.Body})
+		}
+		return
+	}
+	writeJSON(w, http.StatusInternalServerError, map[string]string{"detail": err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,220 @@
+package mockdojo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brduru/mcp-defect-dojo/internal/config"
+	"github.com/brduru/mcp-defect-dojo/internal/defectdojo"
+	"github.com/brduru/mcp-defect-dojo/pkg/types"
+)
+
+// newClient starts a fake DefectDojo and returns a real HTTP client for it
+func newClient(t *testing.T, apiKey string) *defectdojo.HTTPClient {
+	t.Helper()
+	dojo, err := New(Options{APIKey: "secret"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	ts := httptest.NewServer(dojo)
+	t.Cleanup(ts.Close)
+	return defectdojo.NewHTTPClient(&config.DefectDojoConfig{BaseURL: ts.URL, APIKey: apiKey, APIVersion: "v2", RequestTimeout: 5 * time.Second})
+}
+
+func TestFindings(t *testing.T) {
+	client := newClient(t, "secret")
+	ctx := context.Background()
+
+	active := true
+	page, err := client.GetFindings(ctx, types.FindingsFilter{Limit: 2, Active: &active, Ordering: "numerical_severity"})
+	if err != nil {
+		t.Fatalf("GetFindings() error = %v", err)
+	}
+	if len(page.Results) != 2 || page.Count <= 2 || page.Next == nil || page.Results[0].Severity != types.SeverityCritical {
+		t.Fatalf("unexpected page: count %d, next %v, results %+v", page.Count, page.Next, page.Results)
+	}
+
+	product := 1
+	high, err := client.GetFindings(ctx, types.FindingsFilter{Limit: 25, Severity: "High", Product: &product})
+	if err != nil {
+		t.Fatalf("GetFindings() error = %v", err)
+	}
+	for _, finding := range high.Results {
+		if finding.Severity != types.SeverityHigh {
+			t.Errorf("severity filter ignored: %+v", finding)
+		}
+	}
+
+	finding, err := client.GetFindingDetail(ctx, 2)
+	if err != nil || finding.ID != 2 {
+		t.Fatalf("GetFindingDetail() = %+v, %v", finding, err)
+	}
+	detail, err := client.GetFindingDetailPrefetch(ctx, 2, []string{types.PrefetchTest})
+	if err != nil || detail.Prefetch == nil || detail.Prefetch.Tests[detail.Test].ID != detail.Test {
+		t.Errorf("GetFindingDetailPrefetch() = %+v, %v", detail, err)
+	}
+	page, err = client.GetFindings(ctx, types.FindingsFilter{Limit: 2, Prefetch: []string{types.PrefetchTest}})
+	if err != nil || page.Prefetch == nil || len(page.Prefetch.Tests) == 0 {
+		t.Errorf("GetFindings() with prefetch = %+v, %v", page, err)
+	}
+
+	var apiErr *defectdojo.APIError
+	if _, err := client.GetFindingDetail(ctx, 9999); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404 for a missing finding, got %v", err)
+	}
+}
+
+func TestWrites(t *testing.T) {
+	client := newClient(t, "secret")
+	ctx := context.Background()
+
+	response, err := client.MarkFalsePositive(ctx, 2, types.FalsePositiveRequest{IsFalsePositive: true, AlsoDeactivate: true, Justification: "test code"})
+	if err != nil {
+		t.Fatalf("MarkFalsePositive() error = %v", err)
+	}
+	if !response.FalseP || response.Active || response.NoteID == 0 {
+		t.Errorf("unexpected response %+v", response)
+	}
+	if finding, _ := client.GetFindingDetail(ctx, 2); !finding.FalseP || finding.Active {
+		t.Errorf("write not visible: %+v", finding)
+	}
+
+	regraded, err := client.ChangeSeverity(ctx, 3, types.SeverityChangeRequest{Severity: "Critical", Justification: "production credentials"})
+	if err != nil || regraded.PreviousSeverity != "High" || regraded.NumericalSeverity != "S0" || regraded.NoteID == 0 {
+		t.Fatalf("ChangeSeverity() = %+v, %v", regraded, err)
+	}
+	if finding, _ := client.GetFindingDetail(ctx, 3); finding.Severity != "Critical" {
+		t.Errorf("severity change not visible: %+v", finding)
+	}
+
+	if _, err := client.AddFindingMetadata(ctx, 2, types.FindingMetadata{Name: "issue_url", Value: "https://example.com/1"}); err != nil {
+		t.Fatalf("AddFindingMetadata() error = %v", err)
+	}
+	metadata, err := client.GetFindingMetadata(ctx, 2)
+	if err != nil || len(metadata) != 1 || metadata[0].Value != "https://example.com/1" {
+		t.Errorf("GetFindingMetadata() = %+v, %v", metadata, err)
+	}
+
+	product, err := client.CreateProduct(ctx, types.CreateProductRequest{Name: "Billing Service", Description: "Invoices", ProductType: 1, Tags: []string{"sandbox"}})
+	if err != nil || product.ID != 3 || product.Tags[0] != "sandbox" {
+		t.Fatalf("CreateProduct() = %+v, %v", product, err)
+	}
+	engagement, err := client.CreateEngagement(ctx, types.CreateEngagementRequest{Product: product.ID, Name: "CI", Status: "In Progress", TargetStart: "2026-03-01", TargetEnd: "2026-03-08"})
+	if err != nil || engagement.Product != product.ID || !engagement.Active {
+		t.Fatalf("CreateEngagement() = %+v, %v", engagement, err)
+	}
+	if got, err := client.GetEngagement(ctx, engagement.ID); err != nil || got.Name != "CI" {
+		t.Errorf("created engagement not visible: %+v, %v", got, err)
+	}
+
+	users, err := client.ListUsers(ctx, "dana", 10, 0)
+	if err != nil || users.Count != 1 {
+		t.Fatalf("ListUsers() = %+v, %v", users, err)
+	}
+	if finding, err := client.AssignFinding(ctx, 5, []int{users.Results[0].ID}); err != nil || len(finding.Reviewers) != 1 {
+		t.Fatalf("AssignFinding() = %+v, %v", finding, err)
+	}
+	if assigned, err := client.GetFindings(ctx, types.FindingsFilter{Reviewers: []int{users.Results[0].ID}}); err != nil || assigned.Count != 1 {
+		t.Errorf("GetFindings() by reviewer = %+v, %v", assigned, err)
+	}
+	if closed, err := client.CloseFinding(ctx, 5, types.CloseFindingRequest{Note: "Fixed"}); err != nil || closed.Active || !closed.IsMitigated {
+		t.Fatalf("CloseFinding() = %+v, %v", closed, err)
+	}
+
+	var apiErr *defectdojo.APIError
+	if _, err := client.CreateProduct(ctx, types.CreateProductRequest{Name: "Billing Service", Description: "Invoices", ProductType: 1}); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400 for a taken product name, got %v", err)
+	}
+	if _, err := client.ChangeSeverity(ctx, 999, types.SeverityChangeRequest{Severity: "Low"}); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown finding, got %v", err)
+	}
+	if _, err := client.ImportScan(ctx, types.ImportScanRequest{Engagement: 10, ScanType: "SARIF", File: []byte("{}")}); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotImplemented {
+		t.Errorf("expected 501 for scan import, got %v", err)
+	}
+}
+
+func TestReferenceData(t *testing.T) {
+	client := newClient(t, "secret")
+	ctx := context.Background()
+
+	if engagement, err := client.GetEngagement(ctx, 10); err != nil || engagement.Name != "Q3 Pentest" {
+		t.Errorf("GetEngagement() = %+v, %v", engagement, err)
+	}
+	if tests, err := client.ListTests(ctx, 11, 1, 0); err != nil || tests.Count != 2 || len(tests.Results) != 1 || tests.Next == nil {
+		t.Errorf("ListTests() = %+v, %v", tests, err)
+	}
+	if imports, err := client.ListTestImports(ctx, 101, 1, 0); err != nil || imports.Count != 2 || imports.Next == nil || imports.Results[0].Count(types.ImportActionUntouched) != 1 {
+		t.Errorf("ListTestImports() = %+v, %v", imports, err)
+	}
+	if settings, err := client.GetSystemSettings(ctx); err != nil || !settings.EnableDeduplication || settings.DisclaimerNotes == "" {
+		t.Errorf("GetSystemSettings() = %+v, %v", settings, err)
+	}
+	if slas, err := client.ListSLAConfigurations(ctx, 1, 0); err != nil || slas.Count != 2 || slas.Next == nil || slas.Results[0].Critical != 7 {
+		t.Errorf("ListSLAConfigurations() = %+v, %v", slas, err)
+	}
+	if envs, err := client.ListDevelopmentEnvironments(ctx, 10, 0); err != nil || envs.Count != 3 || envs.Results[2].Name != "Production" {
+		t.Errorf("ListDevelopmentEnvironments() = %+v, %v", envs, err)
+	}
+	if announcement, err := client.GetAnnouncement(ctx); err != nil || announcement == nil || announcement.Style != "warning" {
+		t.Errorf("GetAnnouncement() = %+v, %v", announcement, err)
+	}
+	if products, err := client.ListProducts(ctx, 100, 0); err != nil || len(products.Results) == 0 || products.Next != nil {
+		t.Errorf("ListProducts() = %+v, %v", products, err)
+	}
+	active := true
+	engagements, err := client.ListEngagements(ctx, types.EngagementsFilter{Active: &active, Limit: 2, Prefetch: []string{types.PrefetchLead}})
+	if err != nil || engagements.Count != 3 || len(engagements.Results) != 2 || engagements.Next == nil || engagements.Results[0].ID != 11 {
+		t.Errorf("ListEngagements() = %+v, %v", engagements, err)
+	} else if engagements.Prefetch == nil || engagements.Prefetch.Leads[1].Username != "dana" {
+		t.Errorf("expected the leads to be prefetched, got %+v", engagements.Prefetch)
+	}
+	if productTypes, err := client.ListProductTypes(ctx, 1, 0); err != nil || productTypes.Count != 2 || productTypes.Results[0].Name != "Web Applications" || productTypes.Next == nil {
+		t.Errorf("ListProductTypes() = %+v, %v", productTypes, err)
+	}
+	open := false
+	statuses, err := client.ListEndpointStatuses(ctx, types.EndpointStatusFilter{FalsePositive: &open, Limit: 100, Prefetch: []string{types.PrefetchEndpoint}})
+	if err != nil || statuses.Count != 4 || statuses.Prefetch == nil || statuses.Prefetch.Endpoints[6].Host != "portal.example.com" {
+		t.Errorf("ListEndpointStatuses() = %+v, %v", statuses, err)
+	}
+	if endpoints, err := client.ListEndpoints(ctx, 1, 1, 0); err != nil || endpoints.Count != 2 || endpoints.Next == nil {
+		t.Errorf("ListEndpoints() = %+v, %v", endpoints, err)
+	}
+	if technologies, err := client.ListTechnologies(ctx, 1, 100, 0); err != nil || len(technologies.Results) != 2 || technologies.Results[0].Version != "1.25.3" {
+		t.Errorf("ListTechnologies() = %+v, %v", technologies, err)
+	}
+	if risks, err := client.ListRiskAcceptances(ctx, 100, 0); err != nil || len(risks.Results) != 1 || risks.Results[0].AcceptedFindings[0] != 5 || risks.Results[0].ExpirationDate.IsZero() {
+		t.Errorf("ListRiskAcceptances() = %+v, %v", risks, err)
+	}
+}
+
+func TestVerifiedFalsePositiveRefused(t *testing.T) {
+	dojo, err := New(Options{APIKey: "secret"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	request := httptest.NewRequest(http.MethodPatch, "/api/v2/findings/2/", strings.NewReader(`{"false_p": true, "verified": true}`))
+	request.Header.Set("Authorization", "Token secret")
+	request.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	dojo.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for a verified false positive, got %d: %s", recorder.Code, recorder.Body)
+	}
+}
+
+func TestSelfCheck(t *testing.T) {
+	report := newClient(t, "secret").SelfCheck(context.Background())
+	if !report.OK() || report.Version != Version {
+		t.Errorf("expected a passing self-check against %s:\n%s", Version, report)
+	}
+
+	report = newClient(t, "wrong").SelfCheck(context.Background())
+	if report.OK() {
+		t.Errorf("expected a wrong API key to fail:\n%s", report)
+	}
+}
@@ -0,0 +1,103 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// timestampLayouts are the formats DefectDojo uses for datetime and date fields,
+// tried in order.
+var timestampLayouts = []string{
+	time.RFC3339Nano,                      // 2024-01-02T15:04:05.123456Z
+	"2006-01-02T15:04:05.999999999",       // datetime without zone (treated as UTC)
+	"2006-01-02 15:04:05.999999999Z07:00", // space-separated datetime
+	time.DateOnly,                         // 2024-01-02 (date fields such as sla_expiration_date)
+}
+
+// ParseTimestamp parses a DefectDojo datetime or date string.
+// An empty string yields the zero time. Values without a zone are treated as UTC.
+func ParseTimestamp(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	for _, layout := range timestampLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized timestamp format %q", value)
+}
+
+// UnmarshalJSON decodes a finding, parsing its date fields with ParseTimestamp.
+func (f *Finding) UnmarshalJSON(data []byte) error {
+	type findingAlias Finding
+	aux := struct {
+		*findingAlias
+		Date              *string `json:"date"`
+		Created           *string `json:"created"`
+		Modified          *string `json:"modified"`
+		Mitigated         *string `json:"mitigated"`
+		SLAExpirationDate *string `json:"sla_expiration_date"`
+	}{findingAlias: (*findingAlias)(f)}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	for _, field := range []struct {
+		name string
+		raw  *string
+		dest *time.Time
+	}{
+		{"date", aux.Date, &f.Date},
+		{"created", aux.Created, &f.Created},
+		{"modified", aux.Modified, &f.Modified},
+		{"mitigated", aux.Mitigated, &f.Mitigated},
+		{"sla_expiration_date", aux.SLAExpirationDate, &f.SLAExpirationDate},
+	} {
+		if field.raw == nil {
+			*field.dest = time.Time{}
+			continue
+		}
+		t, err := ParseTimestamp(*field.raw)
+		if err != nil {
+			return fmt.Errorf("finding %s: %w", field.name, err)
+		}
+		*field.dest = t
+	}
+	return nil
+}
+
+// Discovered returns when the finding was first found: the discovery date,
+// falling back to the creation timestamp. It is zero when neither is known.
+func (f *Finding) Discovered() time.Time {
+	if !f.Date.IsZero() {
+		return f.Date
+	}
+	return f.Created
+}
+
+// Age returns how long ago the finding was discovered, or 0 if the discovery
+// time is unknown.
+func (f *Finding) Age() time.Duration {
+	return f.ageAt(time.Now())
+}
+
+func (f *Finding) ageAt(now time.Time) time.Duration {
+	discovered := f.Discovered()
+	if discovered.IsZero() {
+		return 0
+	}
+	return now.Sub(discovered)
+}
+
+// IsOverSLA reports whether an open finding has passed its remediation deadline at now.
+// The deadline is the end of the SLA expiration day. Findings without an SLA,
+// inactive findings and mitigated findings are never over SLA.
+func (f *Finding) IsOverSLA(now time.Time) bool {
+	if f.SLAExpirationDate.IsZero() || !f.Active || f.IsMitigated {
+		return false
+	}
+	return !now.Before(f.SLAExpirationDate.AddDate(0, 0, 1))
+}
@@ -0,0 +1,127 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// TestParseTimestamp tests the DefectDojo timestamp formats
+func TestParseTimestamp(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    time.Time
+		wantErr bool
+	}{
+		{"", time.Time{}, false},
+		{"2024-01-02T15:04:05Z", time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC), false},
+		{"2024-01-02T15:04:05.123456Z", time.Date(2024, 1, 2, 15, 4, 5, 123456000, time.UTC), false},
+		{"2024-01-02T17:04:05+02:00", time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC), false},
+		{"2024-01-02T15:04:05.5", time.Date(2024, 1, 2, 15, 4, 5, 500000000, time.UTC), false},
+		{"2024-01-02", time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), false},
+		{"yesterday", time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseTimestamp(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTimestamp(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("ParseTimestamp(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestFindingUnmarshalTimestamps tests date decoding, nulls and round-tripping
+func TestFindingUnmarshalTimestamps(t *testing.T) {
+	data := `{"id": 1, "date": "2024-01-10", "created": "2024-01-10T08:30:00.000123Z",
+		"modified": null, "mitigated": null, "sla_expiration_date": "2024-02-09"}`
+
+	var finding Finding
+	if err := json.Unmarshal([]byte(data), &finding); err != nil {
+		t.Fatalf("Failed to unmarshal: %v", err)
+	}
+	if finding.ID != 1 {
+		t.Errorf("expected regular fields to decode, got ID %d", finding.ID)
+	}
+	if !finding.Date.Equal(time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected date: %v", finding.Date)
+	}
+	if !finding.Created.Equal(time.Date(2024, 1, 10, 8, 30, 0, 123000, time.UTC)) {
+		t.Errorf("unexpected created: %v", finding.Created)
+	}
+	if !finding.Modified.IsZero() || !finding.Mitigated.IsZero() {
+		t.Errorf("expected null timestamps to be zero, got %v / %v", finding.Modified, finding.Mitigated)
+	}
+
+	// Zero timestamps are omitted and the rest survive a round trip
+	encoded, err := json.Marshal(finding)
+	if err != nil {
+		t.Fatalf("Failed to marshal: %v", err)
+	}
+	var raw map[string]any
+	if err := json.Unmarshal(encoded, &raw); err != nil {
+		t.Fatalf("Failed to decode marshaled finding: %v", err)
+	}
+	if _, ok := raw["mitigated"]; ok {
+		t.Errorf("expected zero mitigated to be omitted: %s", encoded)
+	}
+	var decoded Finding
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatalf("Failed to unmarshal round trip: %v", err)
+	}
+	if !decoded.SLAExpirationDate.Equal(finding.SLAExpirationDate) || !decoded.Created.Equal(finding.Created) {
+		t.Errorf("round trip changed timestamps: %+v", decoded)
+	}
+
+	if err := json.Unmarshal([]byte(`{"id": 2, "created": "not a date"}`), &finding); err == nil {
+		t.Error("expected error for malformed timestamp")
+	}
+}
+
+// TestFindingAge tests the discovery-time based age helper
+func TestFindingAge(t *testing.T) {
+	now := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	created := Finding{Created: time.Date(2024, 2, 20, 0, 0, 0, 0, time.UTC)}
+	if got := created.ageAt(now); got != 10*24*time.Hour {
+		t.Errorf("age from created = %v, want 240h", got)
+	}
+
+	dated := Finding{Date: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), Created: created.Created}
+	if got := dated.ageAt(now); got != 29*24*time.Hour {
+		t.Errorf("age prefers discovery date: got %v, want 696h", got)
+	}
+
+	if got := (&Finding{}).Age(); got != 0 {
+		t.Errorf("expected zero age for unknown discovery, got %v", got)
+	}
+}
+
+// TestFindingIsOverSLA tests SLA breach detection
+func TestFindingIsOverSLA(t *testing.T) {
+	sla := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		finding Finding
+		now     time.Time
+		want    bool
+	}{
+		{"no SLA", Finding{Active: true}, sla.AddDate(1, 0, 0), false},
+		{"on deadline day", Finding{Active: true, SLAExpirationDate: sla}, sla.Add(23 * time.Hour), false},
+		{"day after deadline", Finding{Active: true, SLAExpirationDate: sla}, sla.AddDate(0, 0, 1), true},
+		{"mitigated", Finding{Active: true, IsMitigated: true, SLAExpirationDate: sla}, sla.AddDate(0, 1, 0), false},
+		{"inactive", Finding{SLAExpirationDate: sla}, sla.AddDate(0, 1, 0), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.finding.IsOverSLA(tt.now); got != tt.want {
+				t.Errorf("IsOverSLA() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,5 +1,14 @@
 package types
 
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
 // Finding represents a DefectDojo finding/vulnerability with all core fields.
 // This structure mirrors the DefectDojo API response for individual findings.
 //
@@ -15,32 +24,181 @@ package types
 //		FalseP:      false,
 //	}
 type Finding struct {
-	ID          int    `json:"id"`                 // Unique finding identifier
-	Title       string `json:"title"`              // Finding title/summary
-	Severity    string `json:"severity"`           // Severity level (Critical, High, Medium, Low, Info)
-	Description string `json:"description"`        // Detailed finding description
-	Active      bool   `json:"active"`             // Whether the finding is currently active
-	Verified    bool   `json:"verified"`           // Whether the finding has been verified
-	FalseP      bool   `json:"false_p"`            // Whether marked as false positive
-	Test        int    `json:"test"`               // Associated test ID
-	Created     string `json:"created,omitempty"`  // Creation timestamp (ISO 8601)
-	Modified    string `json:"modified,omitempty"` // Last modification timestamp (ISO 8601)
+	ID          int      `json:"id"`                  // Unique finding identifier
+	Title       string   `json:"title"`               // Finding title/summary
+	Severity    string   `json:"severity"`            // Severity level (Critical, High, Medium, Low, Info)
+	Description string   `json:"description"`         // Detailed finding description
+	Active      bool     `json:"active"`              // Whether the finding is currently active
+	Verified    bool     `json:"verified"`            // Whether the finding has been verified
+	FalseP      bool     `json:"false_p"`             // Whether marked as false positive
+	Test        int      `json:"test"`                // Associated test ID
+	Tags        []string `json:"tags,omitempty"`      // Free-form labels, often used to drive triage queues
+	Reporter    int      `json:"reporter,omitempty"`  // User ID of the reporter
+	Reviewers   []int    `json:"reviewers,omitempty"` // User IDs the finding is assigned to for review and follow-up
+	Notes       []int    `json:"notes,omitempty"`     // IDs of the notes written on the finding
+
+	// Timestamps (zero when not reported). Decoding accepts DefectDojo's
+	// datetime and date-only formats, see ParseTimestamp.
+	Date     time.Time `json:"date,omitzero"`     // Discovery date
+	Created  time.Time `json:"created,omitzero"`  // Creation timestamp
+	Modified time.Time `json:"modified,omitzero"` // Last modification timestamp
+
+	// Scoring and classification
+	CVSSv3            string            `json:"cvssv3,omitempty"`             // CVSS v3 vector string
+	CVSSv3Score       *float64          `json:"cvssv3_score,omitempty"`       // CVSS v3 base score (nil if not scored)
+	CWE               int               `json:"cwe,omitempty"`                // CWE identifier (0 if unknown)
+	VulnerabilityIDs  []VulnerabilityID `json:"vulnerability_ids,omitempty"`  // Associated CVE/GHSA/... identifiers
+	NumericalSeverity string            `json:"numerical_severity,omitempty"` // DefectDojo sortable severity (S0 = Critical ... S4 = Info)
+
+	// Location
+	FilePath         string `json:"file_path,omitempty"`          // Source file containing the issue
+	Line             *int   `json:"line,omitempty"`               // Line number within FilePath (nil if unknown)
+	SASTSourceObject string `json:"sast_source_object,omitempty"` // Source object (function, variable) reported by SAST tools
+	ComponentName    string `json:"component_name,omitempty"`     // Vulnerable dependency/component name
+	ComponentVersion string `json:"component_version,omitempty"`  // Vulnerable dependency/component version
+	Endpoints        []int  `json:"endpoints,omitempty"`          // IDs of affected endpoints (hosts/URLs)
+
+	// Remediation guidance
+	Mitigation       string `json:"mitigation,omitempty"`         // How to fix the issue
+	Impact           string `json:"impact,omitempty"`             // Consequences if exploited
+	References       string `json:"references,omitempty"`         // Links and advisories
+	StepsToReproduce string `json:"steps_to_reproduce,omitempty"` // How to reproduce the issue
+
+	// Status and SLA
+	RiskAccepted      bool      `json:"risk_accepted"`                // Whether the risk has been formally accepted
+	OutOfScope        bool      `json:"out_of_scope"`                 // Whether the finding is outside the engagement scope
+	UnderReview       bool      `json:"under_review"`                 // Whether the finding awaits review
+	IsMitigated       bool      `json:"is_mitigated"`                 // Whether the issue has been fixed
+	Duplicate         bool      `json:"duplicate"`                    // Whether the finding duplicates another one
+	Mitigated         time.Time `json:"mitigated,omitzero"`           // Mitigation timestamp
+	SLAExpirationDate time.Time `json:"sla_expiration_date,omitzero"` // Remediation deadline (date, UTC midnight)
+	SLADaysRemaining  *int      `json:"sla_days_remaining,omitempty"` // Days until the SLA expires (negative = overdue, nil = no SLA)
+	AgeDays           *int      `json:"age,omitempty"`                // Days since discovery as computed by DefectDojo
+
+	// Display names of related objects, only set when requested with FindingsFilter.RelatedFields
+	RelatedFields *RelatedFields `json:"related_fields,omitempty"`
+}
+
+// RelatedFields holds the related objects DefectDojo nests in a finding when
+// asked for related_fields, so their names need no further requests. Objects
+// the instance does not return stay nil.
+type RelatedFields struct {
+	Test     *RelatedTest `json:"test,omitempty"`     // The finding's test, with its engagement and product
+	Reporter *RelatedUser `json:"reporter,omitempty"` // The reporter, when the instance includes it
+}
+
+// RelatedTest is a finding's test as nested in its related fields.
+type RelatedTest struct {
+	ID          int                     `json:"id"`                    // Unique test identifier
+	Title       string                  `json:"title,omitempty"`       // Optional test title
+	TestType    *TestType               `json:"test_type,omitempty"`   // Scanner that produced the test
+	Engagement  *RelatedEngagement      `json:"engagement,omitempty"`  // Engagement the test belongs to
+	Environment *DevelopmentEnvironment `json:"environment,omitempty"` // Development environment the test ran against
+}
+
+// RelatedEngagement is a test's engagement as nested in a finding's related fields.
+type RelatedEngagement struct {
+	ID      int             `json:"id"`                // Unique engagement identifier
+	Name    string          `json:"name"`              // Engagement name
+	Product *RelatedProduct `json:"product,omitempty"` // Product the engagement belongs to
+}
+
+// RelatedProduct is an engagement's product as nested in a finding's related fields.
+type RelatedProduct struct {
+	ID   int    `json:"id"`   // Unique product identifier
+	Name string `json:"name"` // Product name
+}
+
+// RelatedUser is a user as nested in a finding's related fields.
+type RelatedUser struct {
+	ID       int    `json:"id"`       // Unique user identifier
+	Username string `json:"username"` // Login name
+}
+
+// VulnerabilityID is an external vulnerability identifier attached to a finding,
+// such as a CVE or GHSA ID.
+type VulnerabilityID struct {
+	VulnerabilityID string `json:"vulnerability_id"` // Identifier, e.g. "CVE-2021-44228"
+}
+
+// ReporterName returns the reporter's login name from the related fields, or
+// an empty string when they were not requested or omit the reporter.
+func (f *Finding) ReporterName() string {
+	if f.RelatedFields == nil || f.RelatedFields.Reporter == nil {
+		return ""
+	}
+	return f.RelatedFields.Reporter.Username
 }
 
-// FalsePositiveRequest represents a request to mark a finding as false positive.
-// This structure is used when updating a finding's false positive status via the API.
+// Location returns "file:line" for code findings, or the file path alone when
+// no line is known. It returns an empty string for findings without a file.
+func (f *Finding) Location() string {
+	if f.FilePath == "" {
+		return ""
+	}
+	if f.Line != nil && *f.Line > 0 {
+		return fmt.Sprintf("%s:%d", f.FilePath, *f.Line)
+	}
+	return f.FilePath
+}
+
+// StatusFlags returns the names of the status flags set on the finding beyond
+// active/verified/false positive, e.g. ["Risk Accepted", "Mitigated"].
+func (f *Finding) StatusFlags() []string {
+	var flags []string
+	for _, flag := range []struct {
+		set  bool
+		name string
+	}{
+		{f.RiskAccepted, "Risk Accepted"},
+		{f.OutOfScope, "Out of Scope"},
+		{f.UnderReview, "Under Review"},
+		{f.IsMitigated, "Mitigated"},
+		{f.Duplicate, "Duplicate"},
+	} {
+		if flag.set {
+			flags = append(flags, flag.name)
+		}
+	}
+	return flags
+}
+
+// VulnerabilityIDList returns the finding's vulnerability identifiers as plain strings.
+func (f *Finding) VulnerabilityIDList() []string {
+	ids := make([]string, 0, len(f.VulnerabilityIDs))
+	for _, v := range f.VulnerabilityIDs {
+		if v.VulnerabilityID != "" {
+			ids = append(ids, v.VulnerabilityID)
+		}
+	}
+	return ids
+}
+
+// FalsePositiveRequest represents a request to mark a finding as false
+// positive, or to clear the mark when IsFalsePositive is false. The false
+// positive flag (and optionally active/verified) is updated on the finding;
+// the justification and notes are recorded as a finding note, since
+// DefectDojo findings have no justification field.
 //
 // Example:
 //
 //	request := &FalsePositiveRequest{
 //		IsFalsePositive: true,
 //		Justification:   "This is expected behavior in test environment",
-//		Notes:          "Confirmed with security team",
+//		Notes:           "Confirmed with security team",
+//		AlsoDeactivate:  true,
 //	}
 type FalsePositiveRequest struct {
-	IsFalsePositive bool   `json:"false_p"`                 // Whether to mark as false positive
-	Justification   string `json:"justification,omitempty"` // Reason for marking as false positive
-	Notes           string `json:"notes,omitempty"`         // Additional notes or comments
+	IsFalsePositive bool   `json:"false_p"`                   // Whether to mark as false positive
+	Justification   string `json:"justification,omitempty"`   // Reason for marking as false positive
+	Notes           string `json:"notes,omitempty"`           // Additional notes or comments
+	AlsoDeactivate  bool   `json:"also_deactivate,omitempty"` // When marking: also set active=false, closing the finding
+	Reactivate      bool   `json:"reactivate,omitempty"`      // When clearing (IsFalsePositive false): also set active=true
+	Verified        *bool  `json:"verified,omitempty"`        // Set the verified flag (nil = leave unchanged)
+
+	// Refuse the change if the finding was modified after this time, e.g. the
+	// Modified value read earlier (zero = no check). Compared to the second.
+	IfUnmodifiedSince time.Time `json:"if_unmodified_since,omitzero"`
 }
 
 // FalsePositiveResponse represents the response from marking a finding as false positive.
@@ -48,11 +206,336 @@ type FalsePositiveRequest struct {
 type FalsePositiveResponse struct {
 	ID            int    `json:"id"`                      // Finding ID that was updated
 	FalseP        bool   `json:"false_p"`                 // Updated false positive status
+	Active        bool   `json:"active"`                  // Updated active status
+	Verified      bool   `json:"verified"`                // Updated verified status
 	Justification string `json:"justification,omitempty"` // Applied justification
 	Notes         string `json:"notes,omitempty"`         // Applied notes
+	NoteID        int    `json:"note_id,omitempty"`       // ID of the note recording justification and notes
 	Message       string `json:"message,omitempty"`       // Optional response message from API
 }
 
+// SeverityChangeRequest re-grades a finding. The justification is recorded
+// on the finding as a note, so the change can be reviewed later.
+type SeverityChangeRequest struct {
+	Severity      string `json:"severity"`      // New severity (Critical, High, Medium, Low or Info)
+	Justification string `json:"justification"` // Why the severity changed
+
+	// Refuse the change if the finding was modified after this time (zero =
+	// no check). Compared to the second.
+	IfUnmodifiedSince time.Time `json:"if_unmodified_since,omitzero"`
+}
+
+// CloseFindingRequest closes a finding as mitigated, making it inactive.
+type CloseFindingRequest struct {
+	Note      string    `json:"note,omitempty"`     // Why the finding was closed, recorded as a finding note
+	Mitigated time.Time `json:"mitigated,omitzero"` // When the finding was fixed (zero = now)
+}
+
+// SeverityChangeResponse describes a finding after its severity changed.
+type SeverityChangeResponse struct {
+	ID                int    `json:"id"`                      // Finding ID that was updated
+	PreviousSeverity  string `json:"previous_severity"`       // Severity before the change
+	Severity          string `json:"severity"`                // Updated severity
+	NumericalSeverity string `json:"numerical_severity"`      // Updated sortable severity, e.g. "S1"
+	Justification     string `json:"justification,omitempty"` // Applied justification
+	NoteID            int    `json:"note_id,omitempty"`       // ID of the note recording the justification
+	Message           string `json:"message,omitempty"`       // Optional response message
+}
+
+// Note is a comment attached to a finding.
+type Note struct {
+	ID      int       `json:"id"`               // Unique note identifier
+	Entry   string    `json:"entry"`            // Note text
+	Private bool      `json:"private"`          // Whether the note is hidden from non-privileged users
+	Date    time.Time `json:"date,omitzero"`    // When the note was written
+	Author  *User     `json:"author,omitempty"` // Note author, if returned by the API
+}
+
+// FindingMetadata is a custom name/value pair attached to a finding, such as
+// the URL of an issue filed for it. Names are unique per finding.
+type FindingMetadata struct {
+	ID    int    `json:"id,omitempty"` // Unique metadata identifier
+	Name  string `json:"name"`         // Metadata key
+	Value string `json:"value"`        // Metadata value
+}
+
+// ImportScanRequest describes a scan report to import into DefectDojo as a new test.
+// The target is either Engagement, or ProductName and EngagementName (created
+// when AutoCreateContext is set).
+type ImportScanRequest struct {
+	ScanType          string // DefectDojo parser name, e.g. "SARIF"
+	Engagement        int    // Engagement to import into (0 = resolve by name)
+	ProductName       string // Product to import into when Engagement is 0
+	EngagementName    string // Engagement to import into when Engagement is 0
+	AutoCreateContext bool   // Create the named product and engagement if missing
+	TestTitle         string // Title of the created test (default: the scan type)
+	MinimumSeverity   string // Skip findings below this severity (default: Info)
+	Active            *bool  // Mark imported findings active (nil = DefectDojo default, true)
+	Verified          *bool  // Mark imported findings verified (nil = DefectDojo default, false)
+	FileName          string // Report file name sent to DefectDojo
+	File              []byte // Report content
+}
+
+// ImportScanResponse is DefectDojo's answer to a scan import.
+type ImportScanResponse struct {
+	Test       int              `json:"test_id"`       // Created test
+	Engagement int              `json:"engagement_id"` // Engagement the test was added to
+	Product    int              `json:"product_id"`    // Product of that engagement
+	Statistics ImportStatistics `json:"statistics"`    // Finding counts for the test
+}
+
+// ImportStatistics counts a test's findings by severity. After is always
+// reported; Before and Delta only when DefectDojo computed them.
+type ImportStatistics struct {
+	Before *SeverityStatistics `json:"before,omitempty"` // Counts before the import
+	Delta  *ImportDelta        `json:"delta,omitempty"`  // What the import changed
+	After  *SeverityStatistics `json:"after,omitempty"`  // Counts after the import
+}
+
+// ImportDelta breaks down the findings an import created, closed, reactivated or left untouched.
+type ImportDelta struct {
+	Created     SeverityStatistics `json:"created"`
+	Closed      SeverityStatistics `json:"closed"`
+	Reactivated SeverityStatistics `json:"reactivated"`
+	Untouched   SeverityStatistics `json:"untouched"`
+}
+
+// SeverityStatistics holds finding counts per severity, plus the overall total.
+type SeverityStatistics struct {
+	Info     StatusCounts `json:"info"`
+	Low      StatusCounts `json:"low"`
+	Medium   StatusCounts `json:"medium"`
+	High     StatusCounts `json:"high"`
+	Critical StatusCounts `json:"critical"`
+	Total    StatusCounts `json:"total"`
+}
+
+// StatusCounts counts findings by status; a finding can count in several statuses.
+type StatusCounts struct {
+	Active       int `json:"active"`
+	Verified     int `json:"verified"`
+	Duplicate    int `json:"duplicate"`
+	FalseP       int `json:"false_p"`
+	OutOfScope   int `json:"out_of_scope"`
+	IsMitigated  int `json:"is_mitigated"`
+	RiskAccepted int `json:"risk_accepted"`
+	Total        int `json:"total"`
+}
+
+// Actions DefectDojo records for each finding an import touched
+const (
+	ImportActionCreated     = "N"
+	ImportActionClosed      = "C"
+	ImportActionReactivated = "R"
+	ImportActionUntouched   = "U"
+)
+
+// TestImport is one entry of a test's import history: an import or reimport
+// of a scan report and what it did to each finding.
+type TestImport struct {
+	ID         int                   `json:"id"`                    // Unique import identifier
+	Test       int                   `json:"test"`                  // Test the report was imported into
+	Type       string                `json:"type"`                  // "import" or "reimport"
+	Created    time.Time             `json:"created"`               // When the import ran
+	Version    string                `json:"version,omitempty"`     // Version of the scanned code
+	BuildID    string                `json:"build_id,omitempty"`    // CI build that imported the report
+	CommitHash string                `json:"commit_hash,omitempty"` // Commit the report was produced for
+	BranchTag  string                `json:"branch_tag,omitempty"`  // Branch or tag the report was produced for
+	Actions    []ImportFindingAction `json:"test_import_finding_action_set"`
+}
+
+// ImportFindingAction records what an import did to one finding
+type ImportFindingAction struct {
+	Finding int    `json:"finding"` // Affected finding ID
+	Action  string `json:"action"`  // One of the ImportAction* codes
+}
+
+// Count returns how many findings the import recorded with this action
+func (i TestImport) Count(action string) int {
+	count := 0
+	for _, a := range i.Actions {
+		if a.Action == action {
+			count++
+		}
+	}
+	return count
+}
+
+// Reported returns how many findings the imported report contained: the ones
+// it created, reactivated or left untouched
+func (i TestImport) Reported() int {
+	return i.Count(ImportActionCreated) + i.Count(ImportActionReactivated) + i.Count(ImportActionUntouched)
+}
+
+// Test is a single scan or assessment within an engagement.
+type Test struct {
+	ID          int    `json:"id"`                    // Unique test identifier
+	Title       string `json:"title,omitempty"`       // Optional test title
+	Engagement  int    `json:"engagement"`            // Engagement the test belongs to
+	TestType    int    `json:"test_type"`             // Test type (scanner) ID
+	Environment int    `json:"environment,omitempty"` // Development environment the test ran against
+}
+
+// DevelopmentEnvironment is where a test ran, e.g. "Production" or "Staging".
+type DevelopmentEnvironment struct {
+	ID   int    `json:"id"`   // Unique development environment identifier
+	Name string `json:"name"` // Environment name
+}
+
+// DevelopmentEnvironmentsResponse is a page of DefectDojo development environments.
+type DevelopmentEnvironmentsResponse struct {
+	Count   int                      `json:"count"`   // Total number of development environments
+	Next    *string                  `json:"next"`    // URL for next page of results (nil if last page)
+	Results []DevelopmentEnvironment `json:"results"` // Development environments on this page
+}
+
+// TestType identifies the scanner or assessment method that produced a test.
+type TestType struct {
+	ID   int    `json:"id"`   // Unique test type identifier
+	Name string `json:"name"` // Scanner name, e.g. "ZAP Scan"
+}
+
+// Engagement is a time-boxed assessment of a product, such as a pentest or CI pipeline.
+type Engagement struct {
+	ID             int    `json:"id"`                        // Unique engagement identifier
+	Name           string `json:"name"`                      // Engagement name
+	Product        int    `json:"product"`                   // Product the engagement belongs to
+	Description    string `json:"description,omitempty"`     // Scope and goals of the engagement
+	Status         string `json:"status,omitempty"`          // E.g. "Not Started", "In Progress", "Completed"
+	EngagementType string `json:"engagement_type,omitempty"` // "Interactive" or "CI/CD"
+	TargetStart    string `json:"target_start,omitempty"`    // Planned start date (YYYY-MM-DD)
+	TargetEnd      string `json:"target_end,omitempty"`      // Planned end date (YYYY-MM-DD)
+	Lead           int    `json:"lead,omitempty"`            // User ID of the engagement lead
+	Active         bool   `json:"active"`                    // False once the engagement is closed
+}
+
+// EngagementsResponse is a page of DefectDojo engagements.
+type EngagementsResponse struct {
+	Count    int          `json:"count"`              // Total number of matching engagements
+	Next     *string      `json:"next"`               // URL for next page of results (nil if last page)
+	Results  []Engagement `json:"results"`            // Engagements on this page
+	Prefetch *Prefetched  `json:"prefetch,omitempty"` // Related objects requested with EngagementsFilter.Prefetch
+}
+
+// EngagementsFilter selects engagements for Client.ListEngagements. Results
+// are ordered by target start date.
+type EngagementsFilter struct {
+	Product  *int     // Only engagements of this product (nil = all products)
+	Active   *bool    // Filter by the active flag (nil = all)
+	Limit    int      // Maximum number of results to return
+	Offset   int      // Number of results to skip (for pagination)
+	Prefetch []string // Related objects to embed in the response (PrefetchLead)
+}
+
+// Product is an application or system tracked in DefectDojo.
+type Product struct {
+	ID          int      `json:"id"`                    // Unique product identifier
+	Name        string   `json:"name"`                  // Product name
+	Description string   `json:"description,omitempty"` // What the product is
+	ProductType int      `json:"prod_type,omitempty"`   // Product type the product belongs to
+	Tags        []string `json:"tags,omitempty"`        // Product tags (e.g. "sandbox")
+}
+
+// ProductType groups products, e.g. by business unit or kind of application.
+type ProductType struct {
+	ID          int    `json:"id"`                    // Unique product type identifier
+	Name        string `json:"name"`                  // Product type name
+	Description string `json:"description,omitempty"` // What the product type covers
+}
+
+// ProductTypesResponse is a page of DefectDojo product types.
+type ProductTypesResponse struct {
+	Count   int           `json:"count"`   // Total number of product types visible to the API token
+	Next    *string       `json:"next"`    // URL for next page of results (nil if last page)
+	Results []ProductType `json:"results"` // Product types on this page
+}
+
+// CreateProductRequest describes a product to create. DefectDojo requires a
+// unique name, a description and a product type.
+type CreateProductRequest struct {
+	Name        string   `json:"name"`           // Product name, unique in DefectDojo
+	Description string   `json:"description"`    // What the product is
+	ProductType int      `json:"prod_type"`      // Product type ID
+	Tags        []string `json:"tags,omitempty"` // Product tags
+}
+
+// CreateEngagementRequest describes an engagement to create in a product.
+type CreateEngagementRequest struct {
+	Product        int    `json:"product"`                   // Product the engagement belongs to
+	Name           string `json:"name"`                      // Engagement name
+	Description    string `json:"description,omitempty"`     // Scope and goals of the engagement
+	EngagementType string `json:"engagement_type,omitempty"` // "Interactive" or "CI/CD"
+	Status         string `json:"status,omitempty"`          // E.g. "Not Started", "In Progress"
+	TargetStart    string `json:"target_start"`              // Planned start date (YYYY-MM-DD)
+	TargetEnd      string `json:"target_end"`                // Planned end date (YYYY-MM-DD)
+}
+
+// ProductsResponse is a page of DefectDojo products.
+type ProductsResponse struct {
+	Count   int       `json:"count"`   // Total number of products visible to the API token
+	Next    *string   `json:"next"`    // URL for next page of results (nil if last page)
+	Results []Product `json:"results"` // Products on this page
+}
+
+// RiskAcceptance is a formal decision to accept the risk of some findings,
+// usually until an expiration date. When it expires DefectDojo can reactivate
+// the findings and restart their SLA.
+type RiskAcceptance struct {
+	ID                    int       `json:"id"`                               // Unique risk acceptance identifier
+	Name                  string    `json:"name"`                             // Descriptive name
+	AcceptedFindings      []int     `json:"accepted_findings"`                // IDs of the findings whose risk is accepted
+	Recommendation        string    `json:"recommendation,omitempty"`         // Security team recommendation, as a decision code
+	Decision              string    `json:"decision,omitempty"`               // Decision code: A, V, M, F or T (see DecisionName)
+	DecisionDetails       string    `json:"decision_details,omitempty"`       // Why the decision was made
+	AcceptedBy            string    `json:"accepted_by,omitempty"`            // Person who accepted the risk
+	Owner                 int       `json:"owner,omitempty"`                  // User ID of the acceptance owner
+	ExpirationDate        time.Time `json:"expiration_date,omitzero"`         // When the acceptance expires (zero = never)
+	ExpirationDateWarned  time.Time `json:"expiration_date_warned,omitzero"`  // When the owner was warned of the expiry
+	ExpirationDateHandled time.Time `json:"expiration_date_handled,omitzero"` // When DefectDojo processed the expiry
+	ReactivateExpired     bool      `json:"reactivate_expired"`               // Reactivate the findings on expiry
+	RestartSLAExpired     bool      `json:"restart_sla_expired"`              // Restart the findings' SLA on expiry
+	Created               time.Time `json:"created,omitzero"`                 // When the acceptance was created
+	Updated               time.Time `json:"updated,omitzero"`                 // When the acceptance was last changed
+}
+
+// DecisionName spells out the decision code, e.g. "Accept" for "A"
+func (r *RiskAcceptance) DecisionName() string {
+	switch r.Decision {
+	case "A":
+		return "Accept"
+	case "V":
+		return "Avoid"
+	case "M":
+		return "Mitigate"
+	case "F":
+		return "Fix"
+	case "T":
+		return "Transfer"
+	}
+	return r.Decision
+}
+
+// RiskAcceptancesResponse is a page of DefectDojo risk acceptances.
+type RiskAcceptancesResponse struct {
+	Count   int              `json:"count"`   // Total number of risk acceptances visible to the API token
+	Next    *string          `json:"next"`    // URL for next page of results (nil if last page)
+	Results []RiskAcceptance `json:"results"` // Risk acceptances on this page
+}
+
+// TestsResponse is a page of DefectDojo tests.
+type TestsResponse struct {
+	Count   int     `json:"count"`   // Total number of matching tests
+	Next    *string `json:"next"`    // URL for next page of results (nil if last page)
+	Results []Test  `json:"results"` // Tests on this page
+}
+
+// TestImportsResponse is a page of a test's import history.
+type TestImportsResponse struct {
+	Count   int          `json:"count"`   // Total number of matching imports
+	Next    *string      `json:"next"`    // URL for next page of results (nil if last page)
+	Results []TestImport `json:"results"` // Imports on this page
+}
+
 // FindingsResponse represents the paginated API response for findings list queries.
 // This follows DefectDojo's standard pagination format for bulk finding retrieval.
 //
@@ -64,10 +547,159 @@ type FalsePositiveResponse struct {
 //		fmt.Printf("Finding %d: %s\n", finding.ID, finding.Title)
 //	}
 type FindingsResponse struct {
-	Count    int       `json:"count"`    // Total number of findings matching the query
-	Next     *string   `json:"next"`     // URL for next page of results (nil if last page)
-	Previous *string   `json:"previous"` // URL for previous page of results (nil if first page)
-	Results  []Finding `json:"results"`  // Array of findings for current page
+	Count    int         `json:"count"`              // Total number of findings matching the query
+	Next     *string     `json:"next"`               // URL for next page of results (nil if last page)
+	Previous *string     `json:"previous"`           // URL for previous page of results (nil if first page)
+	Results  []Finding   `json:"results"`            // Array of findings for current page
+	Prefetch *Prefetched `json:"prefetch,omitempty"` // Related objects requested with FindingsFilter.Prefetch
+}
+
+// Related objects DefectDojo can embed in a findings response, for
+// FindingsFilter.Prefetch and Client.GetFindingDetailPrefetch
+const (
+	PrefetchTest      = "test"      // The finding's test
+	PrefetchEndpoints = "endpoints" // Affected endpoints
+	PrefetchNotes     = "notes"     // Notes written on the finding
+)
+
+// PrefetchLead prefetches an engagement's lead for EngagementsFilter.Prefetch
+const PrefetchLead = "lead"
+
+// Prefetched holds the related objects DefectDojo embedded in a response
+// through its prefetch query parameter, keyed by ID. Findings and engagements
+// reference them by ID (Finding.Test, Finding.Endpoints, Finding.Notes,
+// Engagement.Lead), so one response replaces a request per related object.
+type Prefetched struct {
+	Tests     map[int]Test     `json:"test,omitempty"`      // Tests by ID
+	Endpoints map[int]Endpoint `json:"endpoints,omitempty"` // Endpoints by ID
+	Notes     map[int]Note     `json:"notes,omitempty"`     // Notes by ID
+	Leads     map[int]User     `json:"lead,omitempty"`      // Engagement leads by user ID
+}
+
+// FindingDetail is a single finding with the related objects prefetched with it.
+type FindingDetail struct {
+	Finding
+	Prefetch *Prefetched `json:"prefetch,omitempty"` // Nil when nothing was prefetched
+}
+
+// UnmarshalJSON decodes the finding and its prefetched objects. Without it,
+// Finding's own UnmarshalJSON would be promoted and drop the prefetch.
+func (d *FindingDetail) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &d.Finding); err != nil {
+		return err
+	}
+	var aux struct {
+		Prefetch *Prefetched `json:"prefetch"`
+	}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	d.Prefetch = aux.Prefetch
+	return nil
+}
+
+// Endpoint is a host or URL affected by findings.
+type Endpoint struct {
+	ID       int    `json:"id"`                 // Unique endpoint identifier
+	Protocol string `json:"protocol,omitempty"` // URL scheme, e.g. "https"
+	Host     string `json:"host,omitempty"`     // Host name or IP address
+	Port     *int   `json:"port,omitempty"`     // Port (nil if not set)
+	Path     string `json:"path,omitempty"`     // URL path
+	Query    string `json:"query,omitempty"`    // URL query, without "?"
+	Product  int    `json:"product,omitempty"`  // Product the endpoint belongs to
+}
+
+// String renders the endpoint as a URL, e.g. "https://shop.example.com:8443/login"
+func (e Endpoint) String() string {
+	var result string
+	if e.Protocol != "" {
+		result = e.Protocol + "://"
+	}
+	result += e.Host
+	if e.Port != nil {
+		result += ":" + strconv.Itoa(*e.Port)
+	}
+	if e.Path != "" && !strings.HasPrefix(e.Path, "/") {
+		result += "/"
+	}
+	result += e.Path
+	if e.Query != "" {
+		result += "?" + e.Query
+	}
+	return result
+}
+
+// EndpointsResponse is a page of DefectDojo endpoints.
+type EndpointsResponse struct {
+	Count   int        `json:"count"`   // Total number of matching endpoints
+	Next    *string    `json:"next"`    // URL for next page of results (nil if last page)
+	Results []Endpoint `json:"results"` // Endpoints on this page
+}
+
+// Technology is a technology detected in a product, e.g. a web server or
+// framework reported by a scanner.
+type Technology struct {
+	ID         int    `json:"id"`                   // Unique technology identifier
+	Name       string `json:"name"`                 // Technology name, e.g. "nginx"
+	Version    string `json:"version,omitempty"`    // Detected version
+	Website    string `json:"website,omitempty"`    // Vendor or project website
+	Confidence int    `json:"confidence,omitempty"` // Detection confidence in percent
+	Product    int    `json:"product"`              // Product the technology was found in
+}
+
+// TechnologiesResponse is a page of DefectDojo technologies.
+type TechnologiesResponse struct {
+	Count   int          `json:"count"`   // Total number of matching technologies
+	Next    *string      `json:"next"`    // URL for next page of results (nil if last page)
+	Results []Technology `json:"results"` // Technologies on this page
+}
+
+// EndpointStatus links a finding to one endpoint it affects, with the state
+// of the finding on that endpoint.
+type EndpointStatus struct {
+	ID            int       `json:"id"`                     // Unique endpoint status identifier
+	Endpoint      int       `json:"endpoint"`               // Affected endpoint ID
+	Finding       int       `json:"finding"`                // Finding ID
+	Mitigated     bool      `json:"mitigated"`              // Whether the finding is fixed on this endpoint
+	FalsePositive bool      `json:"false_positive"`         // Whether the finding does not apply to this endpoint
+	OutOfScope    bool      `json:"out_of_scope"`           // Whether the endpoint is outside the scope
+	RiskAccepted  bool      `json:"risk_accepted"`          // Whether the risk on this endpoint was accepted
+	Date          time.Time `json:"date,omitzero"`          // When the finding was first seen on the endpoint
+	LastModified  time.Time `json:"last_modified,omitzero"` // Last change of the status
+}
+
+// Related objects DefectDojo can embed in an endpoint status response, for
+// EndpointStatusFilter.Prefetch
+const (
+	PrefetchEndpoint = "endpoint" // The affected endpoint
+	PrefetchFinding  = "finding"  // The finding
+)
+
+// EndpointStatusPrefetch holds the endpoints and findings embedded in an
+// endpoint status response, keyed by ID.
+type EndpointStatusPrefetch struct {
+	Endpoints map[int]Endpoint `json:"endpoint,omitempty"` // Endpoints by ID
+	Findings  map[int]Finding  `json:"finding,omitempty"`  // Findings by ID
+}
+
+// EndpointStatusesResponse is a page of DefectDojo endpoint statuses.
+type EndpointStatusesResponse struct {
+	Count    int                     `json:"count"`              // Total number of matching statuses
+	Next     *string                 `json:"next"`               // URL for next page of results (nil if last page)
+	Results  []EndpointStatus        `json:"results"`            // Endpoint statuses on this page
+	Prefetch *EndpointStatusPrefetch `json:"prefetch,omitempty"` // Related objects requested with EndpointStatusFilter.Prefetch
+}
+
+// EndpointStatusFilter selects endpoint statuses for Client.ListEndpointStatuses.
+// Results are ordered by ID.
+type EndpointStatusFilter struct {
+	Mitigated     *bool    // Filter by the mitigated flag (nil = all)
+	FalsePositive *bool    // Filter by the false positive flag (nil = all)
+	OutOfScope    *bool    // Filter by the out of scope flag (nil = all)
+	RiskAccepted  *bool    // Filter by the risk accepted flag (nil = all)
+	Limit         int      // Maximum number of results to return
+	Offset        int      // Number of results to skip (for pagination)
+	Prefetch      []string // Related objects to embed in the response (PrefetchEndpoint, PrefetchFinding)
 }
 
 // FindingsFilter contains filtering and pagination options for findings queries.
@@ -76,19 +708,59 @@ type FindingsResponse struct {
 // Example:
 //
 //	filter := &FindingsFilter{
-//		Limit:      50,              // Return up to 50 results
-//		ActiveOnly: true,            // Only active findings
-//		Severity:   "Critical",      // Only critical severity
-//		Verified:   &[]bool{true}[0], // Only verified findings
-//		Offset:     0,               // Start from beginning
+//		Limit:    50,               // Return up to 50 results
+//		Active:   &[]bool{false}[0], // Only closed (inactive) findings
+//		Severity: "Critical",       // Only critical severity
+//		Verified: &[]bool{true}[0],  // Only verified findings
+//		Offset:   0,                // Start from beginning
 //	}
 type FindingsFilter struct {
-	Limit      int    // Maximum number of results to return (default: 100)
-	ActiveOnly bool   // Filter to only active findings
-	Severity   string // Filter by severity level (Critical, High, Medium, Low, Info)
-	Verified   *bool  // Filter by verification status (nil = all, true = verified only, false = unverified only)
-	Test       *int   // Filter by specific test ID (nil = all tests)
-	Offset     int    // Number of results to skip for pagination
+	Limit       int    // Maximum number of results to return (default: 100)
+	Active      *bool  // Filter by active status (nil = all, true = active only, false = inactive only)
+	ActiveOnly  bool   // Deprecated: use Active. Equivalent to Active = true when Active is nil
+	Severity    string // Filter by severity level (Critical, High, Medium, Low, Info)
+	Verified    *bool  // Filter by verification status (nil = all, true = verified only, false = unverified only)
+	Test        *int   // Filter by specific test ID (nil = all tests)
+	Product     *int   // Filter by product ID (nil = all products)
+	Engagement  *int   // Filter by engagement ID (nil = all engagements)
+	Environment *int   // Filter by the development environment ID of the findings' tests (nil = all environments)
+	Offset      int    // Number of results to skip for pagination
+
+	Ordering string // Sort order, e.g. "-date" or "numerical_severity,-date" (empty = API default, see IsValidOrdering)
+
+	RiskAccepted *bool // Filter by risk acceptance (nil = all)
+	IsMitigated  *bool // Filter by mitigation status (nil = all)
+	Duplicate    *bool // Filter by duplicate status (nil = all)
+
+	ComponentName    string // Only findings whose component name contains this (case-insensitive)
+	ComponentVersion string // Only findings whose component version contains this (case-insensitive)
+
+	DiscoveredAfter  time.Time // Only findings discovered after this date (zero = any)
+	DiscoveredBefore time.Time // Only findings discovered before this date (zero = any)
+	MitigatedAfter   time.Time // Only findings mitigated after this date (zero = any)
+	MitigatedBefore  time.Time // Only findings mitigated before this date (zero = any)
+
+	Tags      []string // Only findings with any of these tags
+	NotTags   []string // Exclude findings with any of these tags
+	Reporter  []int    // Only findings reported by these user IDs
+	Reviewers []int    // Only findings assigned to any of these user IDs
+	FoundBy   []int    // Only findings found by these test type (scanner) IDs
+
+	Prefetch      []string // Related objects to embed in the response (PrefetchTest, PrefetchEndpoints, PrefetchNotes)
+	RelatedFields bool     // Nest the names of each finding's test, engagement and product in Finding.RelatedFields
+}
+
+// ActiveFilter returns the effective active filter, honoring the deprecated
+// ActiveOnly flag when Active is unset.
+func (f FindingsFilter) ActiveFilter() *bool {
+	if f.Active != nil {
+		return f.Active
+	}
+	if f.ActiveOnly {
+		active := true
+		return &active
+	}
+	return nil
 }
 
 // Severity level constants for DefectDojo findings.
@@ -152,3 +824,278 @@ func IsValidSeverity(severity string) bool {
 	}
 	return false
 }
+
+// NormalizeSeverity maps a severity level in any capitalization ("critical",
+// "HIGH") to DefectDojo's canonical form. Surrounding whitespace is ignored.
+// It returns false if the input does not name a valid severity level.
+//
+// Example:
+//
+//	severity, ok := NormalizeSeverity("high") // "High", true
+func NormalizeSeverity(severity string) (string, bool) {
+	severity = strings.TrimSpace(severity)
+	for _, valid := range ValidSeverities() {
+		if strings.EqualFold(severity, valid) {
+			return valid, true
+		}
+	}
+	return "", false
+}
+
+// SeverityRank returns the position of a severity level in ascending order of
+// criticality (Info = 0 ... Critical = 4), or -1 for an unknown level.
+//
+// Example:
+//
+//	if SeverityRank(finding.Severity) >= SeverityRank(SeverityHigh) {
+//		fmt.Println("High or Critical")
+//	}
+func SeverityRank(severity string) int {
+	for rank, valid := range ValidSeverities() {
+		if severity == valid {
+			return rank
+		}
+	}
+	return -1
+}
+
+// CompareSeverity compares two severity levels by criticality.
+// It returns -1 if a is less severe than b, 0 if they are equal and +1 if a is
+// more severe. Unknown levels sort below Info.
+func CompareSeverity(a, b string) int {
+	rankA, rankB := SeverityRank(a), SeverityRank(b)
+	switch {
+	case rankA < rankB:
+		return -1
+	case rankA > rankB:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// NumericalSeverity returns DefectDojo's sortable form of a severity level
+// (Critical = "S0" ... Info = "S4"), or "" for an unknown level.
+func NumericalSeverity(severity string) string {
+	rank := SeverityRank(severity)
+	if rank < 0 {
+		return ""
+	}
+	return fmt.Sprintf("S%d", SeverityRank(SeverityCritical)-rank)
+}
+
+// SeverityAtOrAbove reports whether severity is at least as critical as min.
+// It returns false if either level is unknown.
+//
+// Example:
+//
+//	SeverityAtOrAbove("Critical", "High") // true
+//	SeverityAtOrAbove("Medium", "High")   // false
+func SeverityAtOrAbove(severity, min string) bool {
+	rank, minRank := SeverityRank(severity), SeverityRank(min)
+	return rank >= 0 && minRank >= 0 && rank >= minRank
+}
+
+// OrderingFields returns the finding fields that can be used to sort query results.
+// Note that DefectDojo's numerical_severity sorts most severe first (S0 = Critical).
+func OrderingFields() []string {
+	return []string{
+		"id",
+		"title",
+		"date",
+		"created",
+		"mitigated",
+		"last_status_update",
+		"numerical_severity",
+		"cwe",
+		"active",
+		"verified",
+		"component_name",
+	}
+}
+
+// IsValidOrdering checks a findings ordering expression: one or more
+// comma-separated fields from OrderingFields, each optionally prefixed with
+// "-" for descending order.
+//
+// Example:
+//
+//	IsValidOrdering("-date")                   // true
+//	IsValidOrdering("numerical_severity,-date") // true
+//	IsValidOrdering("password")                 // false
+func IsValidOrdering(ordering string) bool {
+	if ordering == "" {
+		return false
+	}
+	for _, field := range strings.Split(ordering, ",") {
+		if !slices.Contains(OrderingFields(), strings.TrimPrefix(strings.TrimSpace(field), "-")) {
+			return false
+		}
+	}
+	return true
+}
+
+// HealthStatus describes DefectDojo availability as seen by the server.
+type HealthStatus struct {
+	URL           string        `json:"url"`               // DefectDojo base URL that was checked
+	Reachable     bool          `json:"reachable"`         // Whether the DefectDojo API answered
+	Authenticated bool          `json:"authenticated"`     // Whether the API token was accepted
+	User          string        `json:"user,omitempty"`    // Username owning the token, when authenticated
+	Version       string        `json:"version,omitempty"` // DefectDojo release, when detectable
+	Latency       time.Duration `json:"latency"`           // Round trip time of the reachability probe
+	Error         string        `json:"error,omitempty"`   // Why the instance is unhealthy, if it is
+}
+
+// Healthy reports whether DefectDojo is reachable and accepts the API token.
+func (h *HealthStatus) Healthy() bool {
+	return h.Reachable && h.Authenticated
+}
+
+// User represents a DefectDojo user account.
+type User struct {
+	ID          int    `json:"id"`                   // Unique user identifier
+	Username    string `json:"username"`             // Login name
+	FirstName   string `json:"first_name,omitempty"` // Given name
+	LastName    string `json:"last_name,omitempty"`  // Family name
+	Email       string `json:"email,omitempty"`      // Contact email
+	IsActive    bool   `json:"is_active"`            // Whether the account is enabled
+	IsSuperuser bool   `json:"is_superuser"`         // Whether the user bypasses role checks
+}
+
+// UsersResponse is a page of DefectDojo users.
+type UsersResponse struct {
+	Count   int     `json:"count"`   // Total number of matching users
+	Next    *string `json:"next"`    // URL for next page of results (nil if last page)
+	Results []User  `json:"results"` // Users on this page
+}
+
+// DisplayName returns the user's full name with the login name, e.g.
+// "Dana Whitfield (dana)", or just the login name when no name is set.
+func (u User) DisplayName() string {
+	name := strings.TrimSpace(u.FirstName + " " + u.LastName)
+	if name == "" {
+		return u.Username
+	}
+	if u.Username == "" {
+		return name
+	}
+	return fmt.Sprintf("%s (%s)", name, u.Username)
+}
+
+// GlobalRole represents a role granted to a user across all products.
+type GlobalRole struct {
+	ID   int  `json:"id"`   // Global role assignment identifier
+	Role *int `json:"role"` // Role ID (nil = no global role)
+}
+
+// ProductMember represents a user's role on a single product.
+type ProductMember struct {
+	ID      int `json:"id"`      // Membership identifier
+	Product int `json:"product"` // Product ID
+	Role    int `json:"role"`    // Role ID
+}
+
+// UserProfile is the authenticated user's profile as returned by /user_profile/.
+// It is used to determine which operations the configured API token may perform.
+type UserProfile struct {
+	User           User            `json:"user"`                     // The token owner
+	GlobalRole     *GlobalRole     `json:"global_role,omitempty"`    // Global role, if any
+	ProductMembers []ProductMember `json:"product_member,omitempty"` // Per-product roles
+}
+
+// DefectDojo role identifiers as used by global roles and product memberships.
+const (
+	RoleAPIImporter = 1 // May import scans only
+	RoleWriter      = 2 // May edit findings
+	RoleMaintainer  = 3 // May edit findings and product settings
+	RoleOwner       = 4 // Full control over the product
+	RoleReader      = 5 // Read-only access
+)
+
+// CanWriteRole reports whether the role permits editing findings.
+func CanWriteRole(role int) bool {
+	return role == RoleWriter || role == RoleMaintainer || role == RoleOwner
+}
+
+// SystemSettings holds the instance-wide DefectDojo settings that change how
+// findings are handled. Only superusers may read them.
+type SystemSettings struct {
+	EnableDeduplication             bool   `json:"enable_deduplication"`               // Whether new findings are matched against existing ones
+	DeleteDuplicates                bool   `json:"delete_duplicates"`                  // Whether old duplicates are deleted
+	MaxDupes                        *int   `json:"max_dupes"`                          // Duplicates kept per original when deleting
+	FalsePositiveHistory            bool   `json:"false_positive_history"`             // Whether new findings inherit earlier false positive decisions
+	RetroactiveFalsePositiveHistory bool   `json:"retroactive_false_positive_history"` // Whether a false positive decision also marks existing matches
+	EnableFindingSLA                bool   `json:"enable_finding_sla"`                 // Whether findings get remediation deadlines
+	EnableJIRA                      bool   `json:"enable_jira"`                        // Whether the JIRA integration is on
+	EnableFullRiskAcceptance        bool   `json:"enable_full_risk_acceptance"`        // Whether risk acceptances with owners and expiry are available
+	RiskAcceptanceFormDefaultDays   int    `json:"risk_acceptance_form_default_days"`  // Default risk acceptance duration
+	EnableProductTagInheritance     bool   `json:"enable_product_tag_inheritance"`     // Whether findings inherit product tags
+	Disclaimer                      string `json:"disclaimer,omitempty"`               // Legal text shown on notes and reports (older releases)
+	DisclaimerNotes                 string `json:"disclaimer_notes,omitempty"`         // Legal text shown where notes are written
+	DisclaimerReports               string `json:"disclaimer_reports,omitempty"`       // Legal text printed on reports
+	DisclaimerNotifications         string `json:"disclaimer_notifications,omitempty"` // Legal text appended to notifications
+}
+
+// SystemSettingsResponse is the single-entry list /system_settings/ returns.
+type SystemSettingsResponse struct {
+	Count   int              `json:"count"`
+	Results []SystemSettings `json:"results"`
+}
+
+// SLAConfiguration sets the remediation deadlines, in days per severity, of
+// the products using it.
+type SLAConfiguration struct {
+	ID              int    `json:"id"`                    // Unique SLA configuration identifier
+	Name            string `json:"name"`                  // Configuration name, e.g. "Default"
+	Description     string `json:"description,omitempty"` // What the configuration is for
+	Critical        int    `json:"critical"`              // Days to fix Critical findings
+	High            int    `json:"high"`                  // Days to fix High findings
+	Medium          int    `json:"medium"`                // Days to fix Medium findings
+	Low             int    `json:"low"`                   // Days to fix Low findings
+	EnforceCritical *bool  `json:"enforce_critical,omitempty"`
+	EnforceHigh     *bool  `json:"enforce_high,omitempty"`
+	EnforceMedium   *bool  `json:"enforce_medium,omitempty"`
+	EnforceLow      *bool  `json:"enforce_low,omitempty"`
+}
+
+// Days returns the deadline for a severity and whether it is enforced.
+// Releases without the enforce flags enforce every deadline.
+func (c SLAConfiguration) Days(severity string) (int, bool) {
+	var days int
+	var enforce *bool
+	normalized, _ := NormalizeSeverity(severity)
+	switch normalized {
+	case "Critical":
+		days, enforce = c.Critical, c.EnforceCritical
+	case "High":
+		days, enforce = c.High, c.EnforceHigh
+	case "Medium":
+		days, enforce = c.Medium, c.EnforceMedium
+	case "Low":
+		days, enforce = c.Low, c.EnforceLow
+	}
+	return days, days > 0 && (enforce == nil || *enforce)
+}
+
+// SLAConfigurationsResponse is a page of SLA configurations.
+type SLAConfigurationsResponse struct {
+	Count   int                `json:"count"`   // Total number of SLA configurations
+	Next    *string            `json:"next"`    // URL for next page of results (nil if last page)
+	Results []SLAConfiguration `json:"results"` // SLA configurations on this page
+}
+
+// Announcement is the banner DefectDojo shows to every user, e.g. a
+// maintenance window.
+type Announcement struct {
+	ID          int    `json:"id"`          // Unique announcement identifier
+	Message     string `json:"message"`     // Banner text, may contain HTML
+	Style       string `json:"style"`       // "info", "success", "warning" or "danger"
+	Dismissable bool   `json:"dismissable"` // Whether users may hide the banner
+}
+
+// AnnouncementsResponse is a page of announcements; DefectDojo keeps at most one.
+type AnnouncementsResponse struct {
+	Count   int            `json:"count"`
+	Next    *string        `json:"next"`
+	Results []Announcement `json:"results"`
+}
@@ -3,6 +3,7 @@ package types
 import (
 	"encoding/json"
 	"testing"
+	"time"
 )
 
 // TestFindingsFilter tests the FindingsFilter structure and its methods
@@ -56,6 +57,29 @@ func TestFindingsFilter(t *testing.T) {
 	}
 }
 
+// TestFindingsFilterActiveFilter tests the tri-state active filter and its legacy fallback
+func TestFindingsFilterActiveFilter(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter FindingsFilter
+		want   *bool
+	}{
+		{"unset", FindingsFilter{}, nil},
+		{"legacy active only", FindingsFilter{ActiveOnly: true}, boolPtr(true)},
+		{"inactive only", FindingsFilter{Active: boolPtr(false)}, boolPtr(false)},
+		{"active overrides legacy flag", FindingsFilter{Active: boolPtr(false), ActiveOnly: true}, boolPtr(false)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.filter.ActiveFilter()
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("ActiveFilter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 // TestFinding tests the Finding structure
 func TestFinding(t *testing.T) {
 	finding := Finding{
@@ -67,8 +91,8 @@ func TestFinding(t *testing.T) {
 		FalseP:      false,
 		Description: "A SQL injection vulnerability was found",
 		Test:        100,
-		Created:     "2023-01-01T00:00:00Z",
-		Modified:    "2023-01-02T00:00:00Z",
+		Created:     time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+		Modified:    time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC),
 	}
 
 	// Test JSON marshaling and unmarshaling
@@ -104,6 +128,100 @@ func TestFinding(t *testing.T) {
 	}
 }
 
+// TestFindingScoringFields tests decoding of CVSS, CWE and vulnerability ID fields
+func TestFindingScoringFields(t *testing.T) {
+	payload := `{
+		"id": 7,
+		"title": "Log4Shell",
+		"severity": "Critical",
+		"cvssv3": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:C/C:H/I:H/A:H",
+		"cvssv3_score": 10.0,
+		"cwe": 502,
+		"vulnerability_ids": [{"vulnerability_id": "CVE-2021-44228"}, {"vulnerability_id": "GHSA-jfh8-c2jp-5v3q"}],
+		"numerical_severity": "S0"
+	}`
+
+	var finding Finding
+	if err := json.Unmarshal([]byte(payload), &finding); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	if finding.CVSSv3Score == nil || *finding.CVSSv3Score != 10.0 {
+		t.Errorf("expected CVSS score 10.0, got %v", finding.CVSSv3Score)
+	}
+	if finding.CWE != 502 || finding.NumericalSeverity != "S0" {
+		t.Errorf("unexpected CWE/numerical severity: %d/%s", finding.CWE, finding.NumericalSeverity)
+	}
+	ids := finding.VulnerabilityIDList()
+	if len(ids) != 2 || ids[0] != "CVE-2021-44228" {
+		t.Errorf("unexpected vulnerability IDs: %v", ids)
+	}
+
+	// A null score stays nil so "not scored" is distinguishable from 0.0
+	var unscored Finding
+	if err := json.Unmarshal([]byte(`{"id": 1, "cvssv3_score": null}`), &unscored); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if unscored.CVSSv3Score != nil {
+		t.Errorf("expected nil score, got %v", *unscored.CVSSv3Score)
+	}
+}
+
+// TestFindingLocation tests the file:line location helper
+func TestFindingLocation(t *testing.T) {
+	tests := []struct {
+		name    string
+		finding Finding
+		want    string
+	}{
+		{"no file", Finding{}, ""},
+		{"file only", Finding{FilePath: "main.go"}, "main.go"},
+		{"file and line", Finding{FilePath: "main.go", Line: intPtr(12)}, "main.go:12"},
+		{"zero line", Finding{FilePath: "main.go", Line: intPtr(0)}, "main.go"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.finding.Location(); got != tt.want {
+				t.Errorf("Location() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestFindingStatusFields tests deserialization of status and SLA fields
+func TestFindingStatusFields(t *testing.T) {
+	data := `{"id": 7, "title": "Old bug", "severity": "High", "risk_accepted": true, "is_mitigated": true,
+		"duplicate": false, "mitigated": "2024-03-01T10:00:00Z", "sla_expiration_date": "2024-02-01",
+		"sla_days_remaining": -29, "age": 120}`
+
+	var finding Finding
+	if err := json.Unmarshal([]byte(data), &finding); err != nil {
+		t.Fatalf("Failed to unmarshal: %v", err)
+	}
+
+	if !finding.RiskAccepted || !finding.IsMitigated || finding.Duplicate {
+		t.Errorf("unexpected status flags: %+v", finding)
+	}
+	if !finding.Mitigated.Equal(time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)) || !finding.SLAExpirationDate.Equal(time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected dates: mitigated=%v sla=%v", finding.Mitigated, finding.SLAExpirationDate)
+	}
+	if finding.SLADaysRemaining == nil || *finding.SLADaysRemaining != -29 {
+		t.Errorf("expected sla_days_remaining -29, got %v", finding.SLADaysRemaining)
+	}
+	if finding.AgeDays == nil || *finding.AgeDays != 120 {
+		t.Errorf("expected age 120, got %v", finding.AgeDays)
+	}
+
+	flags := finding.StatusFlags()
+	if len(flags) != 2 || flags[0] != "Risk Accepted" || flags[1] != "Mitigated" {
+		t.Errorf("StatusFlags() = %v", flags)
+	}
+	if flags := (&Finding{}).StatusFlags(); len(flags) != 0 {
+		t.Errorf("expected no flags, got %v", flags)
+	}
+}
+
 // TestFindingsResponse tests the FindingsResponse structure
 func TestFindingsResponse(t *testing.T) {
 	response := FindingsResponse{
@@ -148,7 +266,116 @@ func TestFindingsResponse(t *testing.T) {
 	}
 }
 
+func TestEndpointString(t *testing.T) {
+	port := 8443
+	tests := []struct {
+		endpoint Endpoint
+		want     string
+	}{
+		{Endpoint{Protocol: "https", Host: "shop.example.com", Port: &port, Path: "/login", Query: "next=1"}, "https://shop.example.com:8443/login?next=1"},
+		{Endpoint{Protocol: "https", Host: "shop.example.com", Path: "login"}, "https://shop.example.com/login"},
+		{Endpoint{Host: "10.0.0.5"}, "10.0.0.5"},
+	}
+	for _, tt := range tests {
+		if got := tt.endpoint.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestFindingDetailPrefetch(t *testing.T) {
+	var detail FindingDetail
+	data := `{"id":3,"title":"Open redirect","endpoints":[4],"created":"2024-05-01T10:00:00Z",
+		"prefetch":{"endpoints":{"4":{"id":4,"protocol":"https","host":"shop.example.com","path":"/redirect"}}}}`
+	if err := json.Unmarshal([]byte(data), &detail); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if detail.ID != 3 || detail.Created.IsZero() {
+		t.Errorf("finding not decoded: %+v", detail.Finding)
+	}
+	if detail.Prefetch == nil || detail.Prefetch.Endpoints[4].String() != "https://shop.example.com/redirect" {
+		t.Errorf("prefetch not decoded: %+v", detail.Prefetch)
+	}
+
+	var plain FindingDetail
+	if err := json.Unmarshal([]byte(`{"id":3}`), &plain); err != nil || plain.Prefetch != nil {
+		t.Errorf("expected no prefetch, got %+v (%v)", plain.Prefetch, err)
+	}
+}
+
+func TestFindingRelatedFields(t *testing.T) {
+	var finding Finding
+	data := `{"id":3,"test":101,"reporter":2,"date":"2026-08-01",
+		"related_fields":{"test":{"id":101,"title":"Nightly SAST","test_type":{"id":7,"name":"Semgrep"},
+			"engagement":{"id":11,"name":"CI Pipeline","product":{"id":2,"name":"Customer Portal"}},
+			"environment":{"id":1,"name":"Development"}},
+			"reporter":{"id":2,"username":"ci-bot"}}}`
+	if err := json.Unmarshal([]byte(data), &finding); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if finding.Date.IsZero() || finding.RelatedFields == nil || finding.RelatedFields.Test == nil {
+		t.Fatalf("related fields not decoded: %+v", finding)
+	}
+	test := finding.RelatedFields.Test
+	if test.TestType.Name != "Semgrep" || test.Engagement.Product.Name != "Customer Portal" || test.Environment.Name != "Development" {
+		t.Errorf("unexpected related test %+v", test)
+	}
+	if name := finding.ReporterName(); name != "ci-bot" {
+		t.Errorf("ReporterName() = %q, want ci-bot", name)
+	}
+
+	var plain Finding
+	if err := json.Unmarshal([]byte(`{"id":3,"reporter":2}`), &plain); err != nil || plain.RelatedFields != nil || plain.ReporterName() != "" {
+		t.Errorf("expected no related fields, got %+v (%v)", plain.RelatedFields, err)
+	}
+}
+
 // TestFalsePositiveRequest tests the FalsePositiveRequest structure
+func TestRiskAcceptanceDecisionName(t *testing.T) {
+	for decision, want := range map[string]string{"A": "Accept", "V": "Avoid", "M": "Mitigate", "F": "Fix", "T": "Transfer", "X": "X"} {
+		risk := RiskAcceptance{Decision: decision}
+		if got := risk.DecisionName(); got != want {
+			t.Errorf("DecisionName(%q) = %q, want %q", decision, got, want)
+		}
+	}
+}
+
+func TestTestImportCounts(t *testing.T) {
+	var testImport TestImport
+	data := `{"id":4,"test":101,"type":"reimport","created":"2026-09-20T10:00:00Z","build_id":"1057",
+		"test_import_finding_action_set":[{"finding":3,"action":"U"},{"finding":6,"action":"C"},{"finding":8,"action":"N"},{"finding":9,"action":"R"}]}`
+	if err := json.Unmarshal([]byte(data), &testImport); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if testImport.BuildID != "1057" || testImport.Created.IsZero() {
+		t.Errorf("import not decoded: %+v", testImport)
+	}
+	for action, want := range map[string]int{ImportActionCreated: 1, ImportActionClosed: 1, ImportActionReactivated: 1, ImportActionUntouched: 1} {
+		if got := testImport.Count(action); got != want {
+			t.Errorf("Count(%q) = %d, want %d", action, got, want)
+		}
+	}
+	if got := testImport.Reported(); got != 3 {
+		t.Errorf("Reported() = %d, want 3", got)
+	}
+}
+
+func TestUserDisplayName(t *testing.T) {
+	tests := []struct {
+		user User
+		want string
+	}{
+		{User{Username: "dana", FirstName: "Dana", LastName: "Whitfield"}, "Dana Whitfield (dana)"},
+		{User{Username: "ci-bot"}, "ci-bot"},
+		{User{FirstName: "Dana"}, "Dana"},
+	}
+	for _, tt := range tests {
+		if got := tt.user.DisplayName(); got != tt.want {
+			t.Errorf("DisplayName() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
 func TestFalsePositiveRequest(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -304,8 +531,8 @@ func BenchmarkFindingMarshal(b *testing.B) {
 		Verified:    false,
 		Description: "This is a benchmark test finding with a longer description to test performance",
 		Test:        100,
-		Created:     "2023-01-01T00:00:00Z",
-		Modified:    "2023-01-02T00:00:00Z",
+		Created:     time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+		Modified:    time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC),
 	}
 
 	b.ResetTimer()
@@ -326,8 +553,8 @@ func BenchmarkFindingUnmarshal(b *testing.B) {
 		Verified:    false,
 		Description: "This is a benchmark test finding with a longer description to test performance",
 		Test:        100,
-		Created:     "2023-01-01T00:00:00Z",
-		Modified:    "2023-01-02T00:00:00Z",
+		Created:     time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+		Modified:    time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC),
 	}
 
 	data, err := json.Marshal(finding)
@@ -397,3 +624,121 @@ func TestValidSeverities(t *testing.T) {
 		}
 	}
 }
+
+// TestSeverityOrdering tests severity ranking and comparison helpers
+func TestSeverityOrdering(t *testing.T) {
+	for i, severity := range ValidSeverities() {
+		if got := SeverityRank(severity); got != i {
+			t.Errorf("SeverityRank(%q) = %d, want %d", severity, got, i)
+		}
+	}
+	if got := SeverityRank("Unknown"); got != -1 {
+		t.Errorf("SeverityRank(Unknown) = %d, want -1", got)
+	}
+
+	compareTests := []struct {
+		a, b string
+		want int
+	}{
+		{SeverityCritical, SeverityHigh, 1},
+		{SeverityLow, SeverityMedium, -1},
+		{SeverityInfo, SeverityInfo, 0},
+		{"Unknown", SeverityInfo, -1},
+	}
+	for _, tt := range compareTests {
+		if got := CompareSeverity(tt.a, tt.b); got != tt.want {
+			t.Errorf("CompareSeverity(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+
+	atOrAboveTests := []struct {
+		severity, min string
+		want          bool
+	}{
+		{SeverityCritical, SeverityHigh, true},
+		{SeverityHigh, SeverityHigh, true},
+		{SeverityMedium, SeverityHigh, false},
+		{SeverityInfo, SeverityInfo, true},
+		{"Unknown", SeverityInfo, false},
+		{SeverityCritical, "Unknown", false},
+	}
+	for _, tt := range atOrAboveTests {
+		if got := SeverityAtOrAbove(tt.severity, tt.min); got != tt.want {
+			t.Errorf("SeverityAtOrAbove(%q, %q) = %t, want %t", tt.severity, tt.min, got, tt.want)
+		}
+	}
+
+	for severity, want := range map[string]string{SeverityCritical: "S0", SeverityHigh: "S1", SeverityMedium: "S2", SeverityLow: "S3", SeverityInfo: "S4", "Unknown": ""} {
+		if got := NumericalSeverity(severity); got != want {
+			t.Errorf("NumericalSeverity(%q) = %q, want %q", severity, got, want)
+		}
+	}
+}
+
+// TestIsValidOrdering tests validation of findings sort expressions
+func TestIsValidOrdering(t *testing.T) {
+	tests := []struct {
+		ordering string
+		want     bool
+	}{
+		{"date", true},
+		{"-date", true},
+		{"numerical_severity,-date", true},
+		{"numerical_severity, -created", true},
+		{"", false},
+		{"password", false},
+		{"-date,", false},
+		{"--date", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsValidOrdering(tt.ordering); got != tt.want {
+			t.Errorf("IsValidOrdering(%q) = %t, want %t", tt.ordering, got, tt.want)
+		}
+	}
+}
+
+// TestNormalizeSeverity tests case-insensitive severity normalization
+func TestNormalizeSeverity(t *testing.T) {
+	tests := []struct {
+		input  string
+		want   string
+		wantOK bool
+	}{
+		{"Critical", "Critical", true},
+		{"critical", "Critical", true},
+		{"HIGH", "High", true},
+		{" medium ", "Medium", true},
+		{"info", "Info", true},
+		{"informational", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := NormalizeSeverity(tt.input)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("NormalizeSeverity(%q) = (%q, %t), want (%q, %t)", tt.input, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestSLAConfigurationDays(t *testing.T) {
+	enforced, relaxed := true, false
+	config := SLAConfiguration{Critical: 7, High: 30, Medium: 90, EnforceHigh: &enforced, EnforceMedium: &relaxed}
+	tests := []struct {
+		severity string
+		days     int
+		enforced bool
+	}{
+		{"critical", 7, true}, // No enforce flag: enforced
+		{"High", 30, true},
+		{"Medium", 90, false},
+		{"Low", 0, false},
+		{"Info", 0, false},
+	}
+	for _, tt := range tests {
+		if days, enforced := config.Days(tt.severity); days != tt.days || enforced != tt.enforced {
+			t.Errorf("Days(%q) = %d, %v, want %d, %v", tt.severity, days, enforced, tt.days, tt.enforced)
+		}
+	}
+}